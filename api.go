@@ -0,0 +1,129 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// apiPrefix is the path the current version of the API is served under.
+// Breaking changes go to a new version, see the compatibility policy in
+// the README.
+const apiPrefix = "/api/v1"
+
+// legacyAPIPrefix is the path the API was served under before it was
+// versioned, kept as a deprecated alias of apiPrefix.
+const legacyAPIPrefix = "/api"
+
+// legacyAPIDeprecated is when the unversioned API was deprecated, sent in
+// the Deprecation header of its responses.
+var legacyAPIDeprecated = time.Date(2026, 10, 14, 0, 0, 0, 0, time.UTC)
+
+// handleAPI registers handler for pattern, such as "GET /radar/items/{id}",
+// under apiPrefix, and under legacyAPIPrefix as a deprecated alias. Apps
+// mounted under basePath point to the successor of legacy paths with it.
+func handleAPI(mux *http.ServeMux, basePath, pattern string, handler http.Handler) {
+	method, path, ok := strings.Cut(pattern, " ")
+	if !ok {
+		method, path = "", pattern
+	}
+	with := func(prefix string) string {
+		return strings.TrimSpace(method + " " + prefix + path)
+	}
+	mux.Handle(with(apiPrefix), handler)
+	mux.Handle(with(legacyAPIPrefix), deprecatedAPIHandler(basePath, handler))
+}
+
+// deprecatedAPIHandler serves next with the headers marking an unversioned
+// API path as deprecated in favour of its apiPrefix successor.
+func deprecatedAPIHandler(basePath string, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		successor := basePath + apiPrefix + strings.TrimPrefix(r.URL.Path, legacyAPIPrefix)
+		if r.URL.RawQuery != "" {
+			successor += "?" + r.URL.RawQuery
+		}
+		w.Header().Set("Deprecation", fmt.Sprintf("@%d", legacyAPIDeprecated.Unix()))
+		w.Header().Add("Link", fmt.Sprintf("<%s>; rel=\"successor-version\"", successor))
+		next.ServeHTTP(w, r)
+	})
+}
+
+// withQuery returns a copy of r with query as its query parameters, so the
+// handlers of the API parse arguments given another way, such as those of
+// a GraphQL field or a gRPC request, like their own.
+func withQuery(r *http.Request, query url.Values) *http.Request {
+	r = r.Clone(r.Context())
+	r.URL.RawQuery = query.Encode()
+	return r
+}
+
+// isAPIPath reports whether path is under legacyAPIPrefix, and so under
+// apiPrefix too.
+func isAPIPath(path string) bool {
+	return path == legacyAPIPrefix || strings.HasPrefix(path, legacyAPIPrefix+"/")
+}
+
+// apiErrorsHandler serves next, turning the plain text errors of requests
+// to the API, such as those of handleError and the 404 and 405 responses of
+// the mux, into JSON like handleJSONError.
+func apiErrorsHandler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !isAPIPath(r.URL.Path) {
+			next.ServeHTTP(w, r)
+			return
+		}
+		jw := &jsonErrorWriter{ResponseWriter: w}
+		next.ServeHTTP(jw, r)
+		jw.close()
+	})
+}
+
+// jsonErrorWriter collects the body of a plain text error response, to
+// write it as JSON once the handler is done.
+type jsonErrorWriter struct {
+	http.ResponseWriter
+	wroteHeader bool
+	// message is set while the message of an error is collected.
+	message *bytes.Buffer
+}
+
+func (w *jsonErrorWriter) WriteHeader(code int) {
+	if w.wroteHeader {
+		return
+	}
+	w.wroteHeader = true
+	h := w.Header()
+	if ct := h.Get("Content-Type"); code >= http.StatusBadRequest && (ct == "" || strings.HasPrefix(ct, "text/plain")) {
+		h.Set("Content-Type", "application/json")
+		h.Set("X-Content-Type-Options", "nosniff")
+		h.Del("Content-Length")
+		w.message = new(bytes.Buffer)
+	}
+	w.ResponseWriter.WriteHeader(code)
+}
+
+func (w *jsonErrorWriter) Write(b []byte) (int, error) {
+	if !w.wroteHeader {
+		w.WriteHeader(http.StatusOK)
+	}
+	if w.message != nil {
+		return w.message.Write(b)
+	}
+	return w.ResponseWriter.Write(b)
+}
+
+// Unwrap lets http.ResponseController reach the underlying writer.
+func (w *jsonErrorWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// close writes the collected error message, if any, as JSON.
+func (w *jsonErrorWriter) close() {
+	if w.message != nil {
+		json.NewEncoder(w.ResponseWriter).Encode(errorResponse{Error: strings.TrimSpace(w.message.String())})
+	}
+}
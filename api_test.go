@@ -0,0 +1,106 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestVersionedAPI(t *testing.T) {
+	cfg := defaultConfig()
+	cfg.Server.BasePath = "/tech-radar"
+	cfg.Admin.Token = "s3cret"
+	cfg.Data.Path = writeFile(t, "radar.yaml", radarWith("Go", "Adopted"))
+	useConfig(t, cfg)
+	handler, err := setupRoutes(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rec := doRequest(t, handler, http.MethodGet, "/tech-radar/api/v1/radar/items/go")
+	if rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), `"label":"Go"`) {
+		t.Errorf("GET /api/v1/radar/items/go = %d %q", rec.Code, rec.Body.String())
+	}
+	if rec.Header().Get("Deprecation") != "" {
+		t.Errorf("GET /api/v1/radar/items/go: Deprecation %q, want none", rec.Header().Get("Deprecation"))
+	}
+
+	// The unversioned API still works, marked as deprecated.
+	legacy := doRequest(t, handler, http.MethodGet, "/tech-radar/api/radar/items/go")
+	if legacy.Code != http.StatusOK || legacy.Body.String() != rec.Body.String() {
+		t.Errorf("GET /api/radar/items/go = %d %q, want the same as /api/v1", legacy.Code, legacy.Body.String())
+	}
+	if got := legacy.Header().Get("Deprecation"); got != "@1791936000" {
+		t.Errorf("Deprecation = %q, want the date it was deprecated", got)
+	}
+	if got, want := legacy.Header().Get("Link"), `</tech-radar/api/v1/radar/items/go>; rel="successor-version"`; got != want {
+		t.Errorf("Link = %q, want %q", got, want)
+	}
+	// Handlers setting Link headers of their own keep the successor.
+	if links := doRequest(t, handler, http.MethodGet, "/tech-radar/api/radar/items").Header().Values("Link"); len(links) != 2 || !strings.Contains(links[0], "successor-version") || !strings.Contains(links[1], `rel="first"`) {
+		t.Errorf("GET /api/radar/items Link = %q, want the successor and the pages", links)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/tech-radar/api/v1/admin/duplicates", nil)
+	req.Header.Set("Authorization", "Bearer s3cret")
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Errorf("GET /api/v1/admin/duplicates = %d %q", rec.Code, rec.Body.String())
+	}
+	if rec := doRequest(t, handler, http.MethodGet, "/tech-radar/api/v1/admin/duplicates"); rec.Code != http.StatusUnauthorized {
+		t.Errorf("GET /api/v1/admin/duplicates without the token = %d, want 401", rec.Code)
+	}
+	if rec := doRequest(t, handler, http.MethodGet, "/tech-radar/api/v2/radar"); rec.Code != http.StatusNotFound {
+		t.Errorf("GET /api/v2/radar = %d, want 404", rec.Code)
+	}
+}
+
+func TestMethodRouting(t *testing.T) {
+	cfg := defaultConfig()
+	cfg.Admin.Token = "s3cret"
+	cfg.Data.Path = writeFile(t, "radar.yaml", radarWith("Go", "Adopted"))
+	useConfig(t, cfg)
+	handler, err := setupRoutes(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		method, target string
+		status         int
+		allow          string
+		// body is the JSON error of API paths.
+		body string
+	}{
+		{method: http.MethodPost, target: "/api/v1/radar", status: http.StatusMethodNotAllowed, allow: "GET, HEAD", body: `{"error":"Method Not Allowed"}`},
+		{method: http.MethodDelete, target: "/api/radar/items/go", status: http.StatusMethodNotAllowed, allow: "GET, HEAD", body: `{"error":"Method Not Allowed"}`},
+		{method: http.MethodGet, target: "/api/v1/import", status: http.StatusMethodNotAllowed, allow: "POST", body: `{"error":"Method Not Allowed"}`},
+		{method: http.MethodGet, target: "/api/v1/colors", status: http.StatusNotFound, body: `{"error":"404 page not found"}`},
+		{method: http.MethodPost, target: "/api/v2/radar", status: http.StatusNotFound, body: `{"error":"404 page not found"}`},
+		{method: http.MethodGet, target: "/api/v1/radar/items?ring=Hold", status: http.StatusBadRequest, body: `{"error":"Invalid ring \"Hold\", must be one of Adopted, In Discovery, Not Recommended"}`},
+		{method: http.MethodPost, target: "/", status: http.StatusMethodNotAllowed, allow: "GET, HEAD"},
+		{method: http.MethodPut, target: "/health", status: http.StatusMethodNotAllowed, allow: "GET, HEAD"},
+		{method: http.MethodGet, target: "/colors", status: http.StatusNotFound},
+		{method: http.MethodHead, target: "/", status: http.StatusOK},
+		{method: http.MethodHead, target: "/health", status: http.StatusOK},
+		{method: http.MethodHead, target: "/api/v1/radar/items/go", status: http.StatusOK},
+		{method: http.MethodHead, target: "/static/radar.js", status: http.StatusOK},
+	}
+	for _, tt := range tests {
+		rec := doRequest(t, handler, tt.method, tt.target)
+		if rec.Code != tt.status || rec.Header().Get("Allow") != tt.allow {
+			t.Errorf("%s %s = %d, Allow %q, want %d, Allow %q", tt.method, tt.target, rec.Code, rec.Header().Get("Allow"), tt.status, tt.allow)
+		}
+		if tt.body == "" {
+			if rec.Code >= http.StatusBadRequest && strings.HasPrefix(rec.Header().Get("Content-Type"), "application/json") {
+				t.Errorf("%s %s is JSON, want it left alone outside the API", tt.method, tt.target)
+			}
+			continue
+		}
+		if ct := rec.Header().Get("Content-Type"); ct != "application/json" || strings.TrimSpace(rec.Body.String()) != tt.body {
+			t.Errorf("%s %s = %q %s, want %s", tt.method, tt.target, ct, rec.Body.String(), tt.body)
+		}
+	}
+}
@@ -0,0 +1,95 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"slices"
+	"strconv"
+)
+
+// includeArchived reports whether the request asks for archived items with
+// ?includeArchived=true.
+func includeArchived(r *http.Request) (bool, error) {
+	value := r.URL.Query().Get("includeArchived")
+	if value == "" {
+		return false, nil
+	}
+	include, err := strconv.ParseBool(value)
+	if err != nil {
+		return false, &AppError{Code: http.StatusBadRequest, Message: fmt.Sprintf("Invalid includeArchived %q, must be true or false", value)}
+	}
+	return include, nil
+}
+
+// visibleItems returns the items that aren't archived.
+func visibleItems(items []RadarItem) []RadarItem {
+	return slices.DeleteFunc(slices.Clone(items), func(item RadarItem) bool { return item.Archived })
+}
+
+// archiveHandler returns the handler archiving, or with archived false
+// restoring, the item with the ID given by the path. It responds with the
+// item as saved.
+func archiveHandler(archived bool) http.Handler {
+	action := "archive"
+	if !archived {
+		action = "unarchive"
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		store := currentStore()
+		if store == nil {
+			handleError(w, &AppError{Code: http.StatusServiceUnavailable, Message: "Radar data not available yet"})
+			return
+		}
+		// Load from the store rather than the cache, so the save doesn't
+		// undo changes the cache hasn't seen yet.
+		data, err := loadStoreData(store)
+		if err != nil {
+			handleError(w, err)
+			return
+		}
+		id := r.PathValue("id")
+		i := slices.IndexFunc(data.Items, func(item RadarItem) bool { return item.ID == id })
+		if i < 0 {
+			handleError(w, &AppError{Code: http.StatusNotFound, Message: "Unknown item"})
+			return
+		}
+
+		if data.Items[i].Archived != archived {
+			data.Items = slices.Clone(data.Items)
+			data.Items[i].Archived = archived
+			ctx := withRequestAudit(r, AuditEvent{Action: action, Detail: id})
+			var errs ValidationErrors
+			err := store.Save(ctx, data)
+			if errors.Is(err, errReadOnly) {
+				handleError(w, &AppError{Code: http.StatusConflict, Message: "Archiving requires a store or data.path naming a single local data file", Err: err})
+				return
+			}
+			if errors.As(err, &errs) {
+				handleError(w, invalidDataError("Invalid radar data", errs))
+				return
+			}
+			if err != nil {
+				handleError(w, &AppError{Code: http.StatusInternalServerError, Message: "Failed to write radar data", Err: err})
+				return
+			}
+			if archived {
+				log.Printf("Archived item %s", id)
+			} else {
+				log.Printf("Unarchived item %s", id)
+			}
+			if data, err = loadStoreData(store); err != nil {
+				handleError(w, err)
+				return
+			}
+		}
+
+		item, _ := findItem(data.Items, id)
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(item); err != nil {
+			handleError(w, &AppError{Code: http.StatusInternalServerError, Message: "Failed to encode response", Err: err})
+		}
+	})
+}
@@ -0,0 +1,80 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestArchivedItems(t *testing.T) {
+	cfg := defaultConfig()
+	cfg.Admin.Token = "s3cret"
+	cfg.Data.Path = writeFile(t, "radar.yaml", radarWith("Go", "Adopted")+
+		"- Label: Perl\n  Quadrant: Tools\n  Ring: Not Recommended\n  Tags: [legacy]\n  Archived: true\n")
+	useConfig(t, cfg)
+	handler, err := setupRoutes(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	labels := func(target string) []string {
+		t.Helper()
+		rec := doRequest(t, handler, http.MethodGet, target)
+		var data RadarData
+		if err := json.NewDecoder(rec.Body).Decode(&data); err != nil {
+			t.Fatalf("GET %s: status %d: %v", target, rec.Code, err)
+		}
+		var labels []string
+		for _, item := range data.Items {
+			labels = append(labels, item.Label)
+		}
+		return labels
+	}
+
+	if got := labels("/api/radar"); strings.Join(got, ",") != "Go" {
+		t.Errorf("GET /api/radar labels = %q, want Go", got)
+	}
+	if got := labels("/api/radar?includeArchived=true"); strings.Join(got, ",") != "Go,Perl" {
+		t.Errorf("GET /api/radar?includeArchived=true labels = %q, want Go,Perl", got)
+	}
+	if rec := doRequest(t, handler, http.MethodGet, "/api/radar?includeArchived=maybe"); rec.Code != http.StatusBadRequest {
+		t.Errorf("includeArchived=maybe: status = %d, want 400", rec.Code)
+	}
+	if rec := doRequest(t, handler, http.MethodGet, "/api/radar/items/perl"); rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), `"archived":true`) {
+		t.Errorf("GET /api/radar/items/perl: status = %d: %s", rec.Code, rec.Body)
+	}
+	if rec := doRequest(t, handler, http.MethodGet, "/api/tags"); strings.Contains(rec.Body.String(), "legacy") {
+		t.Errorf("GET /api/tags = %s, want no tags of archived items", rec.Body)
+	}
+	if rec := doRequest(t, handler, http.MethodGet, "/api/stats"); !strings.Contains(rec.Body.String(), `"total":1,"archived":1`) {
+		t.Errorf("GET /api/stats = %s", rec.Body)
+	}
+
+	post := func(target string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, target, nil)
+		req.Header.Set("Authorization", "Bearer s3cret")
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		return rec
+	}
+	if rec := doRequest(t, handler, http.MethodPost, "/api/admin/items/go/archive"); rec.Code != http.StatusUnauthorized {
+		t.Errorf("without token: status = %d, want 401", rec.Code)
+	}
+	if rec := post("/api/admin/items/cobol/archive"); rec.Code != http.StatusNotFound {
+		t.Errorf("unknown item: status = %d, want 404", rec.Code)
+	}
+	if rec := post("/api/admin/items/go/archive"); rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), `"archived":true`) {
+		t.Errorf("archive: status = %d: %s", rec.Code, rec.Body)
+	}
+	if rec := post("/api/admin/items/perl/unarchive"); rec.Code != http.StatusOK || strings.Contains(rec.Body.String(), "archived") {
+		t.Errorf("unarchive: status = %d: %s", rec.Code, rec.Body)
+	}
+	if got := labels("/api/radar"); strings.Join(got, ",") != "Perl" {
+		t.Errorf("after archiving Go, GET /api/radar labels = %q, want Perl", got)
+	}
+	data, err := readRadarData(cfg.Data.Path)
+	if err != nil || !data.Items[0].Archived || data.Items[1].Archived {
+		t.Errorf("data file items = %+v, %v", data.Items, err)
+	}
+}
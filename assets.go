@@ -0,0 +1,61 @@
+package main
+
+import (
+	"embed"
+	"errors"
+	"io/fs"
+	"os"
+)
+
+// embeddedAssets holds the templates and static files compiled into the
+// binary, so the server runs without them on disk.
+//
+//go:embed templates static
+var embeddedAssets embed.FS
+
+// indexTemplate is the name of the main HTML template.
+const indexTemplate = "index.html"
+
+// embedTemplate is the name of the HTML template of the embeddable radar.
+const embedTemplate = "embed.html"
+
+// moderationTemplate is the name of the HTML template of the moderation
+// queue.
+const moderationTemplate = "moderation.html"
+
+// templatesFS returns the templates directory configured on disk, or the
+// embedded templates when none is configured.
+func (c Config) templatesFS() fs.FS {
+	return assetsFS(c.Data.Templates, "templates")
+}
+
+// templateFS returns the templates to parse the template called name from:
+// templatesFS, or the embedded templates if the configured directory has
+// no such template, so directories customizing only index.html keep
+// working.
+func (c Config) templateFS(name string) fs.FS {
+	fsys := c.templatesFS()
+	if _, err := fs.Stat(fsys, name); errors.Is(err, fs.ErrNotExist) {
+		return assetsFS("", "templates")
+	}
+	return fsys
+}
+
+// staticFS returns the static directory configured on disk, or the embedded
+// static assets when none is configured.
+func (c Config) staticFS() fs.FS {
+	return assetsFS(c.Data.Static, "static")
+}
+
+// assetsFS returns os.DirFS(dir) if dir is set, otherwise the embedded
+// directory of the given name.
+func assetsFS(dir, embedded string) fs.FS {
+	if dir != "" {
+		return os.DirFS(dir)
+	}
+	sub, err := fs.Sub(embeddedAssets, embedded)
+	if err != nil {
+		panic(err) // embedded is one of the directories named in go:embed
+	}
+	return sub
+}
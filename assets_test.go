@@ -0,0 +1,36 @@
+package main
+
+import (
+	"html/template"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestEmbeddedAssets(t *testing.T) {
+	cfg := defaultConfig()
+
+	if _, err := template.ParseFS(cfg.templatesFS(), indexTemplate); err != nil {
+		t.Errorf("embedded template: %v", err)
+	}
+	if _, err := fs.Stat(cfg.staticFS(), "radar.js"); err != nil {
+		t.Errorf("embedded static asset: %v", err)
+	}
+}
+
+func TestAssetOverrideDirectory(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "custom.css"), []byte("body{}"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	cfg := defaultConfig()
+	cfg.Data.Static = dir
+
+	if _, err := fs.Stat(cfg.staticFS(), "custom.css"); err != nil {
+		t.Errorf("override file: %v", err)
+	}
+	if _, err := fs.Stat(cfg.staticFS(), "radar.js"); err == nil {
+		t.Error("override directory fell back to an embedded file")
+	}
+}
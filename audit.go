@@ -0,0 +1,183 @@
+package main
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// sourceIP returns the address of the client of r, without its port.
+func sourceIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+// justificationHeader is the request header giving the justification of a
+// write, see checkRingMoves.
+const justificationHeader = "Radar-Justification"
+
+// withRequestAudit returns the context of r whose saves are recorded as
+// event, see withAuditEvent, made by the admin unless it names another
+// actor, from the client of r, and justified by the justificationHeader of
+// r unless event is.
+func withRequestAudit(r *http.Request, event AuditEvent) context.Context {
+	event.Actor = cmp.Or(event.Actor, "admin")
+	event.SourceIP = sourceIP(r)
+	event.Justification = cmp.Or(event.Justification, strings.TrimSpace(r.Header.Get(justificationHeader)))
+	return withAuditEvent(r.Context(), event)
+}
+
+// AuditEntry is an audit event with the changes its save made to items,
+// each with the item before and after it.
+type AuditEntry struct {
+	AuditEvent
+	Changes []ItemEdit `json:"changes"`
+}
+
+// auditFilter selects the entries of the audit log.
+type auditFilter struct {
+	// item is the ID of an item the entries changed, actor the principal
+	// they were made as, ignoring case.
+	item, actor string
+	// since and until bound the times of the entries, inclusive and to the
+	// second, if set.
+	since, until time.Time
+}
+
+// parseAuditTime parses the value of the query parameter name, an RFC 3339
+// time or a date, which stands for its start, or its end if end is set.
+func parseAuditTime(name, value string, end bool) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	t, err := time.Parse(time.DateOnly, value)
+	if err != nil {
+		return time.Time{}, &AppError{Code: http.StatusBadRequest, Message: fmt.Sprintf("Invalid %s %q, must be a date such as 2024-01-31 or a time such as 2024-01-31T12:00:00Z", name, value)}
+	}
+	if end {
+		t = t.AddDate(0, 0, 1).Add(-time.Nanosecond)
+	}
+	return t, nil
+}
+
+// parseAuditFilter reads the auditFilter of ?item=, ?actor=, ?since= and
+// ?until=.
+func parseAuditFilter(r *http.Request) (auditFilter, error) {
+	query := r.URL.Query()
+	f := auditFilter{item: query.Get("item"), actor: query.Get("actor")}
+	var err error
+	if value := query.Get("since"); value != "" {
+		if f.since, err = parseAuditTime("since", value, false); err != nil {
+			return auditFilter{}, err
+		}
+	}
+	if value := query.Get("until"); value != "" {
+		if f.until, err = parseAuditTime("until", value, true); err != nil {
+			return auditFilter{}, err
+		}
+	}
+	if !f.since.IsZero() && !f.until.IsZero() && f.until.Before(f.since) {
+		return auditFilter{}, &AppError{Code: http.StatusBadRequest, Message: "Invalid date range, until is before since"}
+	}
+	return f, nil
+}
+
+// itemID returns the ID of the item edit changed, that of its label for
+// items saved before they had IDs.
+func (edit ItemEdit) itemID() string {
+	for _, item := range []*RadarItem{edit.After, edit.Before} {
+		if item != nil && item.ID != "" {
+			return item.ID
+		}
+	}
+	return itemSlug(edit.Label)
+}
+
+// auditSecond formats t to the second, as the audit filter compares times:
+// the RFC 3339 text of stored times only sorts by time to the second.
+func auditSecond(t time.Time) string {
+	return t.UTC().Format("2006-01-02T15:04:05")
+}
+
+// matchesEvent reports whether f selects event by its actor and time; the
+// item is checked against the edits of its save.
+func (f auditFilter) matchesEvent(event AuditEvent) bool {
+	second := auditSecond(event.Time)
+	switch {
+	case f.actor != "" && !strings.EqualFold(event.Actor, f.actor),
+		!f.since.IsZero() && second < auditSecond(f.since),
+		!f.until.IsZero() && second > auditSecond(f.until):
+		return false
+	}
+	return true
+}
+
+// withChanges returns events with the changes of their saves among edits,
+// which are newest first.
+func withChanges(events []AuditEvent, edits []ItemEdit) []AuditEntry {
+	bySnapshot := make(map[int64][]ItemEdit)
+	// List the edits of a save in the order it made them.
+	for i := len(edits) - 1; i >= 0; i-- {
+		bySnapshot[edits[i].SnapshotID] = append(bySnapshot[edits[i].SnapshotID], edits[i])
+	}
+	entries := make([]AuditEntry, len(events))
+	for i, event := range events {
+		entries[i] = AuditEntry{AuditEvent: event, Changes: bySnapshot[event.SnapshotID]}
+		if event.SnapshotID == 0 || entries[i].Changes == nil {
+			entries[i].Changes = []ItemEdit{}
+		}
+	}
+	return entries
+}
+
+// auditPage is the response of GET /audit.
+type auditPage struct {
+	Entries []AuditEntry `json:"entries"`
+	Total   int          `json:"total"`
+	Limit   int          `json:"limit"`
+	Offset  int          `json:"offset"`
+}
+
+// auditHandler serves a page of the audit log of the database store, newest
+// first, narrowed by parseAuditFilter, with the total count of the entries
+// selected and Link headers to the other pages.
+func auditHandler(w http.ResponseWriter, r *http.Request) {
+	p, err := parsePage(r)
+	if err != nil {
+		handleError(w, err)
+		return
+	}
+	filter, err := parseAuditFilter(r)
+	if err != nil {
+		handleError(w, err)
+		return
+	}
+	store, ok := currentStore().(*databaseStore)
+	if !ok {
+		handleError(w, &AppError{Code: http.StatusConflict, Message: "The audit log requires a database store, see store.driver"})
+		return
+	}
+	entries, total, err := store.db.AuditLog(r.Context(), filter, p.limit, p.offset)
+	if err != nil {
+		handleError(w, &AppError{Code: http.StatusInternalServerError, Message: "Failed to read the audit log", Err: err})
+		return
+	}
+
+	body := auditPage{entries, total, p.limit, p.offset}
+	u := requestURL(r)
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Add("Link", p.links(u, total))
+	w.Header().Set("X-Total-Count", strconv.Itoa(total))
+	if err := json.NewEncoder(w).Encode(body); err != nil {
+		handleError(w, &AppError{Code: http.StatusInternalServerError, Message: "Failed to encode response", Err: err})
+	}
+}
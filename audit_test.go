@@ -0,0 +1,101 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestAuditLog(t *testing.T) {
+	for driver, dsn := range map[string]string{"sqlite": "file::memory:", "bbolt": "radar.db"} {
+		t.Run(driver, func(t *testing.T) {
+			if driver == "bbolt" {
+				dsn = filepath.Join(t.TempDir(), dsn)
+			}
+			db := openTestStore(t, driver, dsn)
+			// The seed is saved without an ID, like items saved before they had
+			// them, which the next save gives it.
+			seed := RadarData{Items: []RadarItem{{Label: "Go", Quadrant: "Tools", Ring: "Adopted"}}}
+			if err := db.Save(context.Background(), seed, AuditEvent{Time: time.Date(2024, 1, 1, 18, 0, 0, 0, time.UTC), Actor: "test", Action: "seed"}); err != nil {
+				t.Fatal(err)
+			}
+			cfg := defaultConfig()
+			cfg.Admin.Token = "s3cret"
+			useConfig(t, cfg)
+			useStore(t, &databaseStore{db: db})
+			handler, err := setupRoutes(cfg)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if rec := adminRequest(t, handler, http.MethodPost, "/api/v1/items", nil, `{"label": "Rust", "quadrant": "Tools", "ring": "In Discovery"}`); rec.Code != http.StatusCreated {
+				t.Fatalf("POST /api/v1/items = %d: %s", rec.Code, rec.Body)
+			}
+			if rec := adminRequest(t, handler, http.MethodDelete, "/api/v1/items/go", anyVersion, ""); rec.Code != http.StatusNoContent {
+				t.Fatalf("DELETE /api/v1/items/go = %d: %s", rec.Code, rec.Body)
+			}
+
+			audit := func(query string) auditPage {
+				t.Helper()
+				rec := adminRequest(t, handler, http.MethodGet, "/api/v1/audit"+query, nil, "")
+				var page auditPage
+				if err := json.Unmarshal(rec.Body.Bytes(), &page); err != nil || rec.Code != http.StatusOK {
+					t.Fatalf("GET /api/v1/audit%s = %d %s", query, rec.Code, rec.Body)
+				}
+				return page
+			}
+			actions := func(page auditPage) string {
+				var actions []string
+				for _, entry := range page.Entries {
+					actions = append(actions, entry.Action)
+				}
+				return strings.Join(actions, ",")
+			}
+
+			all := audit("")
+			if got := actions(all); got != "delete item,create item,seed" || all.Total != 3 {
+				t.Fatalf("audit log = %s, total %d", got, all.Total)
+			}
+			deleted := all.Entries[0]
+			if deleted.Actor != "admin" || deleted.SourceIP != "192.0.2.1" || len(deleted.Changes) != 1 ||
+				deleted.Changes[0].Before == nil || deleted.Changes[0].Before.ID != "go" || deleted.Changes[0].After != nil {
+				t.Errorf("delete entry = %+v", deleted)
+			}
+			if created := all.Entries[1]; len(created.Changes) != 2 || created.Changes[1].Action != editAdded || created.Changes[1].After.Ring != "In Discovery" {
+				t.Errorf("create entry = %+v", created)
+			}
+			for query, want := range map[string]string{
+				"?item=go":                            "delete item,create item,seed",
+				"?item=rust":                          "create item",
+				"?item=nope":                          "",
+				"?actor=ADMIN":                        "delete item,create item",
+				"?until=2024-01-01":                   "seed",
+				"?since=2024-01-02":                   "delete item,create item",
+				"?until=2024-01-01T18:00:00Z&item=go": "seed",
+				"?until=2024-01-01T17:59:59Z":         "",
+				"?item=go&limit=1&offset=1":           "create item",
+				"?actor=admin&offset=5":               "",
+			} {
+				if got := actions(audit(query)); got != want {
+					t.Errorf("GET /api/v1/audit%s = %q, want %q", query, got, want)
+				}
+			}
+
+			rec := adminRequest(t, handler, http.MethodGet, "/api/v1/audit?limit=1&offset=1", nil, "")
+			if !strings.Contains(rec.Header().Get("Link"), `rel="next"`) || rec.Header().Get("X-Total-Count") != "3" || !strings.Contains(rec.Body.String(), `"create item"`) {
+				t.Errorf("second page = %v %s", rec.Header(), rec.Body)
+			}
+			for _, query := range []string{"?since=yesterday", "?since=2024-02-01&until=2024-01-01", "?limit=0"} {
+				if rec := adminRequest(t, handler, http.MethodGet, "/api/v1/audit"+query, nil, ""); rec.Code != http.StatusBadRequest {
+					t.Errorf("GET /api/v1/audit%s = %d, want 400", query, rec.Code)
+				}
+			}
+			if rec := doRequest(t, handler, http.MethodGet, "/api/v1/audit"); rec.Code != http.StatusUnauthorized {
+				t.Errorf("GET /api/v1/audit without token = %d, want 401", rec.Code)
+			}
+		})
+	}
+}
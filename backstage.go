@@ -0,0 +1,160 @@
+package main
+
+import (
+	"cmp"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"slices"
+	"time"
+)
+
+// backstageRadar is the radar in the shape the TechRadar plugin of
+// Backstage loads, its TechRadarLoaderResponse.
+type backstageRadar struct {
+	Quadrants []backstageQuadrant `json:"quadrants"`
+	Rings     []backstageRing     `json:"rings"`
+	Entries   []backstageEntry    `json:"entries"`
+}
+
+// backstageQuadrant is a quadrant of a backstageRadar.
+type backstageQuadrant struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+// backstageRing is a ring of a backstageRadar.
+type backstageRing struct {
+	ID          string `json:"id"`
+	Name        string `json:"name"`
+	Color       string `json:"color"`
+	Description string `json:"description,omitempty"`
+}
+
+// backstageEntry is an item of a backstageRadar, in the quadrant with the
+// ID Quadrant.
+type backstageEntry struct {
+	ID          string                   `json:"id"`
+	Key         string                   `json:"key"`
+	Title       string                   `json:"title"`
+	Quadrant    string                   `json:"quadrant"`
+	URL         string                   `json:"url"`
+	Description string                   `json:"description,omitempty"`
+	Links       []backstageLink          `json:"links"`
+	Timeline    []backstageTimelineEntry `json:"timeline"`
+}
+
+// backstageLink is a link of a backstageEntry.
+type backstageLink struct {
+	URL   string `json:"url"`
+	Title string `json:"title"`
+}
+
+// backstageTimelineEntry is a ring an entry was placed in. Moved is 1 if it
+// moved to an inner ring, -1 to an outer one, and 0 otherwise.
+type backstageTimelineEntry struct {
+	Moved       int    `json:"moved"`
+	RingID      string `json:"ringId"`
+	Date        string `json:"date"`
+	Description string `json:"description,omitempty"`
+}
+
+// ringMove returns 1 if from is outside to in rings, which go from the
+// innermost outwards, -1 if it is inside, and 0 if they are the same.
+func ringMove(rings []Segment, from, to string) int {
+	index := func(name string) int {
+		return slices.IndexFunc(rings, func(s Segment) bool { return s.Name == name })
+	}
+	switch i, j := index(from), index(to); {
+	case j < i:
+		return 1
+	case j > i:
+		return -1
+	}
+	return 0
+}
+
+// backstageTimeline returns the timeline of item, newest first, from its
+// changes, oldest first: when it was added, moved and put back on the
+// radar. Without any, the timeline only has the ring it is in, since its
+// last update.
+func backstageTimeline(item RadarItem, changes []ItemChange, rings []Segment) []backstageTimelineEntry {
+	var timeline []backstageTimelineEntry
+	for _, change := range changes {
+		entry := backstageTimelineEntry{RingID: itemSlug(change.Item.Ring), Date: change.Time.UTC().Format(time.DateOnly)}
+		switch change.Event {
+		case changeCreated:
+			entry.Description = "Added to the radar"
+		case changeMoved:
+			entry.Moved = ringMove(rings, change.PreviousRing, change.Item.Ring)
+			entry.Description = "Moved from " + change.PreviousRing
+		case changeUnarchived:
+			entry.Description = "Put back on the radar"
+		default:
+			continue
+		}
+		timeline = append(timeline, entry)
+	}
+	if len(timeline) == 0 {
+		updated := item.LastUpdated
+		if updated.IsZero() {
+			updated = time.Now()
+		}
+		timeline = append(timeline, backstageTimelineEntry{RingID: itemSlug(item.Ring), Date: updated.UTC().Format(time.DateOnly)})
+	}
+	slices.Reverse(timeline)
+	return timeline
+}
+
+// backstageHandler serves the items selectItems selects as the radar of
+// the Backstage TechRadar plugin, with the quadrants and rings identified by
+// the slugs of their names and the timelines of the items from the change
+// feed when there is one.
+func backstageHandler(w http.ResponseWriter, r *http.Request) {
+	data, err := loadRadarData()
+	if err != nil {
+		handleError(w, err)
+		return
+	}
+	data = withSegments(data)
+	items, err := selectItems(r, data)
+	if err != nil {
+		handleError(w, err)
+		return
+	}
+	changesByID, err := itemChangesByID(r.Context())
+	if err != nil {
+		handleError(w, &AppError{Code: http.StatusInternalServerError, Message: "Failed to read radar changes", Err: err})
+		return
+	}
+
+	radar := backstageRadar{Quadrants: []backstageQuadrant{}, Rings: []backstageRing{}, Entries: []backstageEntry{}}
+	for _, quadrant := range data.Quadrants {
+		radar.Quadrants = append(radar.Quadrants, backstageQuadrant{ID: itemSlug(quadrant.Name), Name: quadrant.Name})
+	}
+	for i, ring := range data.Rings {
+		radar.Rings = append(radar.Rings, backstageRing{ID: itemSlug(ring.Name), Name: ring.Name, Color: segmentColor(data.Rings, i), Description: ring.Description})
+	}
+	page := requestOrigin(r) + currentConfig().Server.BasePath + "/#"
+	for _, item := range items {
+		entry := backstageEntry{
+			ID:          item.ID,
+			Key:         item.ID,
+			Title:       item.Label,
+			Quadrant:    itemSlug(item.Quadrant),
+			URL:         page + url.PathEscape(item.ID),
+			Description: item.Description,
+			Links:       []backstageLink{},
+			Timeline:    backstageTimeline(item, changesByID[item.ID], data.Rings),
+		}
+		for _, link := range item.Links {
+			entry.Links = append(entry.Links, backstageLink{URL: link.URL, Title: cmp.Or(link.Title, link.URL)})
+		}
+		radar.Entries = append(radar.Entries, entry)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(radar); err != nil {
+		handleError(w, &AppError{Code: http.StatusInternalServerError, Message: "Failed to encode response", Err: err})
+	}
+}
@@ -0,0 +1,68 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestBackstage(t *testing.T) {
+	repo, commit := gitRadarRepo(t)
+	commit(radarWith("Go", "Not Recommended"))
+	commit(radarWith("Go", "In Discovery"))
+	commit(radarWith("Go", "Adopted") + "- Label: Rust\n  Quadrant: Programming Languages & Frameworks\n  Ring: In Discovery\n  Links:\n  - URL: https://www.rust-lang.org\n")
+
+	cfg := defaultConfig()
+	cfg.Data.Path = filepath.Join(repo, "data", "radar.yaml")
+	useConfig(t, cfg)
+	handler, err := setupRoutes(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rec := doRequest(t, handler, http.MethodGet, "http://radar.example.com/api/v1/backstage/tech-radar")
+	var radar backstageRadar
+	if err := json.Unmarshal(rec.Body.Bytes(), &radar); rec.Code != http.StatusOK || err != nil {
+		t.Fatalf("GET /api/v1/backstage/tech-radar = %d %q", rec.Code, rec.Body.String())
+	}
+	if got := radar.Rings[1]; got != (backstageRing{ID: "in-discovery", Name: "In Discovery", Color: "#FFA500"}) {
+		t.Errorf("rings[1] = %+v", got)
+	}
+	if got := radar.Quadrants[2]; got.ID != "programming-languages-frameworks" {
+		t.Errorf("quadrants[2] = %+v", got)
+	}
+	if len(radar.Entries) != 2 {
+		t.Fatalf("entries = %+v", radar.Entries)
+	}
+
+	// Timelines are newest first, moving inwards counts as up.
+	var moves []int
+	var rings []string
+	for _, entry := range radar.Entries[0].Timeline {
+		moves, rings = append(moves, entry.Moved), append(rings, entry.RingID)
+	}
+	if !reflect.DeepEqual(moves, []int{1, 1, 0}) || !reflect.DeepEqual(rings, []string{"adopted", "in-discovery", "not-recommended"}) {
+		t.Errorf("timeline of Go = %+v", radar.Entries[0].Timeline)
+	}
+	rust := radar.Entries[1]
+	if rust.Key != "rust" || rust.Quadrant != "programming-languages-frameworks" || rust.URL != "http://radar.example.com/#rust" ||
+		!reflect.DeepEqual(rust.Links, []backstageLink{{URL: "https://www.rust-lang.org", Title: "https://www.rust-lang.org"}}) || len(rust.Timeline) != 1 {
+		t.Errorf("entry of Rust = %+v", rust)
+	}
+}
+
+func TestBackstageWithoutHistory(t *testing.T) {
+	cfg := defaultConfig()
+	cfg.Data.Path = writeFile(t, "radar.yaml", radarWith("Go", "Adopted"))
+	useConfig(t, cfg)
+	rec := doRequest(t, http.HandlerFunc(backstageHandler), http.MethodGet, "/api/backstage/tech-radar")
+	var radar backstageRadar
+	if err := json.Unmarshal(rec.Body.Bytes(), &radar); rec.Code != http.StatusOK || err != nil {
+		t.Fatalf("GET /api/backstage/tech-radar = %d %q", rec.Code, rec.Body.String())
+	}
+	if timeline := radar.Entries[0].Timeline; len(timeline) != 1 || timeline[0].RingID != "adopted" || timeline[0].Moved != 0 || timeline[0].Date == "" {
+		t.Errorf("timeline without history = %+v", timeline)
+	}
+}
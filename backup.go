@@ -0,0 +1,248 @@
+package main
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"math"
+	"mime"
+	"net/http"
+	"slices"
+	"time"
+)
+
+// Formats of GET /api/admin/backup.
+const (
+	backupYAML    = "yaml"
+	backupArchive = "tar.gz"
+)
+
+// maxRestoreSize limits the size of an uploaded backup, which may be an
+// archive of many snapshots.
+const maxRestoreSize = 64 << 20
+
+// backupTimeLayout formats the time a backup was taken in its file name.
+const backupTimeLayout = "20060102T150405Z"
+
+// backupDataFile is the name of the current radar data in a backup archive.
+const backupDataFile = "radar.yaml"
+
+// backupYAMLFile encodes data as a YAML data file starting with a comment
+// that says what it is.
+func backupYAMLFile(header string, data RadarData) ([]byte, error) {
+	content, err := encodeRadarData(backupDataFile, storedData(data))
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte("# "+header+"\n"), content...), nil
+}
+
+// writeBackup returns a backup of the radar data of store taken at now, in
+// format. An archive also holds every snapshot of a database store, oldest
+// first, as snapshots/<id>.yaml. The backup is encrypted if a key is
+// configured.
+func writeBackup(ctx context.Context, store Store, format string, now time.Time) ([]byte, error) {
+	data, err := loadStoreData(store)
+	if err != nil {
+		return nil, err
+	}
+	current, err := backupYAMLFile(fmt.Sprintf("Clean Tech Radar backup taken %s", now.Format(time.RFC3339)), data)
+	if err != nil {
+		return nil, err
+	}
+	if format == backupYAML {
+		return sealData(current)
+	}
+
+	var snapshots []Snapshot
+	if db, ok := store.(*databaseStore); ok {
+		// All of them: the store has no way to list them without a limit.
+		if snapshots, err = db.db.Snapshots(ctx, math.MaxInt32); err != nil {
+			return nil, err
+		}
+		slices.Reverse(snapshots)
+	}
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gz)
+	add := func(name string, modified time.Time, content []byte) error {
+		header := &tar.Header{Name: name, Mode: 0o600, Size: int64(len(content)), ModTime: modified}
+		if err := tw.WriteHeader(header); err != nil {
+			return err
+		}
+		_, err := tw.Write(content)
+		return err
+	}
+	if err := add(backupDataFile, now, current); err != nil {
+		return nil, err
+	}
+	for _, snapshot := range snapshots {
+		content, err := backupYAMLFile(fmt.Sprintf("Snapshot %d saved %s", snapshot.ID, snapshot.Time.UTC().Format(time.RFC3339)), snapshot.Data)
+		if err != nil {
+			return nil, err
+		}
+		if err := add(fmt.Sprintf("snapshots/%06d.yaml", snapshot.ID), snapshot.Time, content); err != nil {
+			return nil, err
+		}
+	}
+	if err := tw.Close(); err != nil {
+		return nil, err
+	}
+	if err := gz.Close(); err != nil {
+		return nil, err
+	}
+	return sealData(buf.Bytes())
+}
+
+// backupHandler serves a backup of the current radar data as a YAML data
+// file, or with ?format=tar.gz as an archive that also holds the snapshots
+// of a database store.
+func backupHandler(w http.ResponseWriter, r *http.Request) {
+	format := r.URL.Query().Get("format")
+	if format == "" {
+		format = backupYAML
+	}
+	contentType := map[string]string{backupYAML: "application/yaml", backupArchive: "application/gzip"}[format]
+	if contentType == "" {
+		handleError(w, &AppError{Code: http.StatusBadRequest, Message: fmt.Sprintf("Invalid format %q, must be %s or %s", format, backupYAML, backupArchive)})
+		return
+	}
+	store := currentStore()
+	if store == nil {
+		handleError(w, &AppError{Code: http.StatusServiceUnavailable, Message: "Radar data not available yet"})
+		return
+	}
+
+	now := time.Now().UTC().Truncate(time.Second)
+	content, err := writeBackup(r.Context(), store, format, now)
+	if _, ok := err.(*AppError); err != nil && !ok {
+		err = &AppError{Code: http.StatusInternalServerError, Message: "Failed to write backup", Err: err}
+	}
+	if err != nil {
+		handleError(w, err)
+		return
+	}
+	if isEncrypted(content) {
+		contentType = "application/octet-stream"
+	}
+	log.Printf("Backup downloaded by %s", r.RemoteAddr)
+
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="radar-backup-%s.%s"`, now.Format(backupTimeLayout), format))
+	w.Write(content)
+}
+
+// backupArchiveData returns the current radar data of a backup archive.
+func backupArchiveData(archive []byte) ([]byte, error) {
+	gz, err := gzip.NewReader(bytes.NewReader(archive))
+	if err != nil {
+		return nil, err
+	}
+	tr := tar.NewReader(gz)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			return nil, fmt.Errorf("archive has no %s", backupDataFile)
+		}
+		if err != nil {
+			return nil, err
+		}
+		if header.Name == backupDataFile {
+			return io.ReadAll(io.LimitReader(tr, maxRestoreSize))
+		}
+	}
+}
+
+// readBackup returns the radar data of a backup written by writeBackup,
+// decrypting it and taking it out of an archive as needed, with every
+// problem validateRadarContent finds.
+func readBackup(content []byte) (RadarData, error) {
+	content, err := openData(content)
+	if err != nil {
+		return RadarData{}, err
+	}
+	if bytes.HasPrefix(content, []byte{0x1f, 0x8b}) {
+		if content, err = backupArchiveData(content); err != nil {
+			return RadarData{}, err
+		}
+	}
+	if err := validateRadarContent(backupDataFile, content, newValidationScope()); err != nil {
+		return RadarData{}, err
+	}
+	data, _, err := upgradeRadarData(backupDataFile, content)
+	return data, err
+}
+
+// restoreHandler replaces the radar data with that of a backup downloaded
+// from backupHandler, uploaded as the request body or as the file field of
+// a form. The backup is validated before anything is replaced.
+func restoreHandler(w http.ResponseWriter, r *http.Request) {
+	r.Body = http.MaxBytesReader(w, r.Body, maxRestoreSize)
+	body, name := io.Reader(r.Body), "upload"
+	if mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type")); mediaType == "multipart/form-data" {
+		f, header, err := r.FormFile("file")
+		if err != nil {
+			handleError(w, restoreReadError(err))
+			return
+		}
+		defer f.Close()
+		body, name = f, header.Filename
+	}
+	content, err := io.ReadAll(body)
+	if err != nil {
+		handleError(w, restoreReadError(err))
+		return
+	}
+
+	var errs ValidationErrors
+	data, err := readBackup(content)
+	if errors.As(err, &errs) {
+		handleError(w, invalidDataError("Invalid backup", errs))
+		return
+	}
+	if err != nil {
+		handleError(w, &AppError{Code: http.StatusBadRequest, Message: "Invalid backup: " + err.Error(), Err: err})
+		return
+	}
+
+	store := currentStore()
+	if store == nil {
+		handleError(w, &AppError{Code: http.StatusServiceUnavailable, Message: "Radar data not available yet"})
+		return
+	}
+	detail := fmt.Sprintf("%s with %d items", name, len(data.Items))
+	ctx := withRestore(withRequestAudit(r, AuditEvent{Action: "restore", Detail: detail}))
+	err = store.Save(ctx, data)
+	if errors.Is(err, errReadOnly) {
+		handleError(w, &AppError{Code: http.StatusConflict, Message: "Restore requires a store or data.path naming a single local data file", Err: err})
+		return
+	}
+	if errors.As(err, &errs) {
+		handleError(w, invalidDataError("Invalid backup", errs))
+		return
+	}
+	if err != nil {
+		handleError(w, &AppError{Code: http.StatusInternalServerError, Message: "Failed to write radar data", Err: err})
+		return
+	}
+	log.Printf("Restored %s", detail)
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]int{"restored": len(data.Items)})
+}
+
+// restoreReadError converts an error reading an uploaded backup into an
+// AppError.
+func restoreReadError(err error) *AppError {
+	var maxErr *http.MaxBytesError
+	if errors.As(err, &maxErr) {
+		return &AppError{Code: http.StatusRequestEntityTooLarge, Message: "Backup too large", Err: err}
+	}
+	return &AppError{Code: http.StatusBadRequest, Message: "Invalid backup: " + err.Error(), Err: err}
+}
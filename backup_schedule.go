@@ -0,0 +1,247 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+)
+
+// backupTarget is a place scheduled backups are written to.
+type backupTarget interface {
+	// Put writes the backup called name.
+	Put(ctx context.Context, name string, content []byte) error
+	// List returns the names of the files in the target, which may include
+	// files other than backups.
+	List(ctx context.Context) ([]string, error)
+	// Delete removes the backup called name.
+	Delete(ctx context.Context, name string) error
+	// String describes the target in log messages.
+	String() string
+}
+
+// dirBackupTarget keeps backups in a local directory.
+type dirBackupTarget struct {
+	dir string
+}
+
+func (t dirBackupTarget) Put(ctx context.Context, name string, content []byte) error {
+	if err := os.MkdirAll(t.dir, 0o700); err != nil {
+		return err
+	}
+	return replaceFile(filepath.Join(t.dir, name), content, 0o600)
+}
+
+func (t dirBackupTarget) List(ctx context.Context) ([]string, error) {
+	entries, err := os.ReadDir(t.dir)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	names := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		if entry.Type().IsRegular() {
+			names = append(names, entry.Name())
+		}
+	}
+	return names, nil
+}
+
+func (t dirBackupTarget) Delete(ctx context.Context, name string) error {
+	return os.Remove(filepath.Join(t.dir, name))
+}
+
+func (t dirBackupTarget) String() string {
+	return t.dir
+}
+
+// s3BackupTarget keeps backups in an S3 bucket, under a key prefix.
+type s3BackupTarget struct {
+	client *s3Client
+	prefix string
+}
+
+func (t s3BackupTarget) Put(ctx context.Context, name string, content []byte) error {
+	return t.client.Put(ctx, t.prefix+name, content)
+}
+
+func (t s3BackupTarget) List(ctx context.Context) ([]string, error) {
+	keys, err := t.client.List(ctx, t.prefix)
+	if err != nil {
+		return nil, err
+	}
+	names := make([]string, 0, len(keys))
+	for _, key := range keys {
+		// Objects in "directories" below the prefix aren't ours.
+		if name := strings.TrimPrefix(key, t.prefix); !strings.Contains(name, "/") {
+			names = append(names, name)
+		}
+	}
+	return names, nil
+}
+
+func (t s3BackupTarget) Delete(ctx context.Context, name string) error {
+	return t.client.Delete(ctx, t.prefix+name)
+}
+
+func (t s3BackupTarget) String() string {
+	return "s3://" + t.client.bucket + "/" + t.prefix
+}
+
+// backupTargets returns the targets configured in cfg.
+func backupTargets(cfg BackupConfig) ([]backupTarget, error) {
+	var targets []backupTarget
+	if cfg.Dir != "" {
+		targets = append(targets, dirBackupTarget{dir: cfg.Dir})
+	}
+	if cfg.S3.Bucket != "" {
+		client, err := newS3Client(cfg.S3)
+		if err != nil {
+			return nil, err
+		}
+		targets = append(targets, s3BackupTarget{client: client, prefix: cfg.S3.Prefix})
+	}
+	return targets, nil
+}
+
+// backupFileName returns the name of a backup in format taken at t.
+func backupFileName(t time.Time, format string) string {
+	return "radar-backup-" + t.UTC().Format(backupTimeLayout) + "." + format
+}
+
+// backupFileTime returns the time the backup called name was taken, or
+// false if name isn't the name of a backup.
+func backupFileTime(name string) (time.Time, bool) {
+	rest, ok := strings.CutPrefix(name, "radar-backup-")
+	if !ok {
+		return time.Time{}, false
+	}
+	stamp, format, _ := strings.Cut(rest, ".")
+	if format != backupYAML && format != backupArchive {
+		return time.Time{}, false
+	}
+	t, err := time.Parse(backupTimeLayout, stamp)
+	return t, err == nil
+}
+
+// expiredBackups returns the backups among names that the retention rules
+// of cfg no longer keep: all but the newest cfg.Keep, and those older than
+// cfg.MaxAge at now. Files that aren't backups are never expired.
+func expiredBackups(names []string, cfg BackupConfig, now time.Time) []string {
+	type backup struct {
+		name  string
+		taken time.Time
+	}
+	var backups []backup
+	for _, name := range names {
+		if t, ok := backupFileTime(name); ok {
+			backups = append(backups, backup{name, t})
+		}
+	}
+	slices.SortFunc(backups, func(a, b backup) int { return b.taken.Compare(a.taken) })
+
+	var expired []string
+	for i, b := range backups {
+		if (cfg.Keep > 0 && i >= cfg.Keep) || (cfg.MaxAge > 0 && now.Sub(b.taken) > cfg.MaxAge) {
+			expired = append(expired, b.name)
+		}
+	}
+	return expired
+}
+
+// lastBackup returns when the newest backup in target was taken, or the
+// zero time if it holds none.
+func lastBackup(ctx context.Context, target backupTarget) (time.Time, error) {
+	names, err := target.List(ctx)
+	if err != nil {
+		return time.Time{}, err
+	}
+	var last time.Time
+	for _, name := range names {
+		if t, ok := backupFileTime(name); ok && t.After(last) {
+			last = t
+		}
+	}
+	return last, nil
+}
+
+// runBackup writes a backup of store taken at now to every target and
+// deletes the backups the retention rules no longer keep. It carries on
+// with the other targets when one fails, returning the first error.
+func runBackup(ctx context.Context, store Store, cfg BackupConfig, targets []backupTarget, now time.Time) error {
+	content, err := writeBackup(ctx, store, cfg.Format, now)
+	if err != nil {
+		return err
+	}
+	name := backupFileName(now, cfg.Format)
+	var first error
+	for _, target := range targets {
+		err := backupTo(ctx, target, name, content, cfg, now)
+		if err != nil {
+			err = fmt.Errorf("%s: %w", target, err)
+			if first == nil {
+				first = err
+			}
+			continue
+		}
+		slog.Info("Wrote backup", "target", target.String(), "name", name)
+	}
+	return first
+}
+
+// backupTo writes the backup called name to target and applies the
+// retention rules there.
+func backupTo(ctx context.Context, target backupTarget, name string, content []byte, cfg BackupConfig, now time.Time) error {
+	if err := target.Put(ctx, name, content); err != nil {
+		return err
+	}
+	names, err := target.List(ctx)
+	if err != nil {
+		return err
+	}
+	for _, expired := range expiredBackups(names, cfg, now) {
+		if err := target.Delete(ctx, expired); err != nil {
+			return err
+		}
+		slog.Info("Deleted expired backup", "target", target.String(), "name", expired)
+	}
+	return nil
+}
+
+// runScheduledBackups writes a backup to targets every cfg.Interval until
+// ctx is done. The first one is due an interval after the oldest of the
+// newest backups in the targets, so restarts don't add backups or put
+// them off.
+func runScheduledBackups(ctx context.Context, cfg BackupConfig, targets []backupTarget) {
+	var last time.Time
+	for i, target := range targets {
+		t, err := lastBackup(ctx, target)
+		if err != nil {
+			slog.Error("Listing backups failed", "target", target.String(), "err", err)
+		}
+		if i == 0 || t.Before(last) {
+			last = t
+		}
+	}
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(time.Until(last.Add(cfg.Interval))):
+		}
+		last = time.Now().UTC().Truncate(time.Second)
+		store := currentStore()
+		if store == nil {
+			continue
+		}
+		if err := runBackup(ctx, store, cfg, targets, last); err != nil {
+			slog.Error("Scheduled backup failed", "err", err)
+		}
+	}
+}
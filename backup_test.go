@@ -0,0 +1,207 @@
+package main
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// getBackup requests target from handler with the admin token s3cret.
+func getBackup(t *testing.T, handler http.Handler, target string) *httptest.ResponseRecorder {
+	t.Helper()
+	req := httptest.NewRequest(http.MethodGet, target, nil)
+	req.Header.Set("Authorization", "Bearer s3cret")
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestBackupEndpoint(t *testing.T) {
+	cfg := defaultConfig()
+	cfg.Admin.Token = "s3cret"
+	cfg.Data.Path = writeFile(t, "radar.yaml", radarWith("Go", "Adopted"))
+	useConfig(t, cfg)
+	handler, err := setupRoutes(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if rec := doRequest(t, handler, http.MethodGet, "/api/admin/backup"); rec.Code != http.StatusUnauthorized {
+		t.Errorf("without token: status = %d, want 401", rec.Code)
+	}
+	if rec := getBackup(t, handler, "/api/admin/backup?format=zip"); rec.Code != http.StatusBadRequest {
+		t.Errorf("format=zip: status = %d, want 400", rec.Code)
+	}
+
+	rec := getBackup(t, handler, "/api/admin/backup")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d: %s", rec.Code, rec.Body)
+	}
+	if got := rec.Header().Get("Content-Disposition"); !strings.HasPrefix(got, `attachment; filename="radar-backup-`) || !strings.HasSuffix(got, `.yaml"`) {
+		t.Errorf("Content-Disposition = %q", got)
+	}
+	if !strings.HasPrefix(rec.Body.String(), "# Clean Tech Radar backup taken ") {
+		t.Errorf("backup =\n%s\nwant it to start with a comment", rec.Body)
+	}
+	data, err := decodeRadarData("backup.yaml", rec.Body.Bytes())
+	if err != nil || len(data.Items) != 1 || data.Items[0].Label != "Go" || data.Items[0].Source != "" {
+		t.Errorf("backup items = %+v, %v", data.Items, err)
+	}
+
+	// With an encryption key, the backup is encrypted too.
+	useEncryption(t, testEncryptionKey)
+	rec = getBackup(t, handler, "/api/admin/backup")
+	if !isEncrypted(rec.Body.Bytes()) || rec.Header().Get("Content-Type") != "application/octet-stream" {
+		t.Errorf("backup = %q, Content-Type %q, want it encrypted", rec.Body, rec.Header().Get("Content-Type"))
+	}
+	if plain, err := openData(rec.Body.Bytes()); err != nil || !bytes.Contains(plain, []byte("Label: Go")) {
+		t.Errorf("decrypted backup = %q, %v", plain, err)
+	}
+}
+
+func TestBackupArchive(t *testing.T) {
+	ctx := context.Background()
+	db := openTestStore(t, "bbolt", filepath.Join(t.TempDir(), "radar.bolt"))
+	store := &databaseStore{db: db}
+	for _, ring := range []string{"Adopted", "In Discovery"} {
+		if err := store.Save(ctx, RadarData{Items: []RadarItem{{Label: "Go", Quadrant: "Tools", Ring: ring}}}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	useStore(t, store)
+	cfg := defaultConfig()
+	cfg.Admin.Token = "s3cret"
+	handler, err := setupRoutes(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rec := getBackup(t, handler, "/api/admin/backup?format=tar.gz")
+	if rec.Code != http.StatusOK || rec.Header().Get("Content-Type") != "application/gzip" {
+		t.Fatalf("status = %d, Content-Type %q: %s", rec.Code, rec.Header().Get("Content-Type"), rec.Body)
+	}
+	gz, err := gzip.NewReader(rec.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	files := map[string]string{}
+	var names []string
+	tr := tar.NewReader(gz)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		content, _ := io.ReadAll(tr)
+		names = append(names, header.Name)
+		files[header.Name] = string(content)
+	}
+	if want := []string{"radar.yaml", "snapshots/000001.yaml", "snapshots/000002.yaml"}; !reflect.DeepEqual(names, want) {
+		t.Fatalf("archive files = %q, want %q", names, want)
+	}
+	if !strings.Contains(files["radar.yaml"], "Ring: In Discovery") || !strings.Contains(files["snapshots/000001.yaml"], "Ring: Adopted") {
+		t.Errorf("archive files = %q", files)
+	}
+	if !strings.HasPrefix(files["snapshots/000001.yaml"], "# Snapshot 1 saved ") {
+		t.Errorf("snapshots/000001.yaml =\n%s", files["snapshots/000001.yaml"])
+	}
+}
+
+// postRestore uploads backup to handler's restore endpoint with the admin
+// token s3cret.
+func postRestore(t *testing.T, handler http.Handler, backup []byte) *httptest.ResponseRecorder {
+	t.Helper()
+	req := httptest.NewRequest(http.MethodPost, "/api/admin/restore", bytes.NewReader(backup))
+	req.Header.Set("Authorization", "Bearer s3cret")
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestRestoreEndpoint(t *testing.T) {
+	cfg := defaultConfig()
+	cfg.Admin.Token = "s3cret"
+	cfg.Data.Path = writeFile(t, "radar.yaml", "Rings:\n- Name: Adopted\n  MovesTo: [Adopted]\n- Name: Retired\n"+
+		"Items:\n- Label: Go\n  Quadrant: Tools\n  Ring: Retired\n  LastUpdated: 2024-01-01T00:00:00Z\n")
+	useConfig(t, cfg)
+	handler, err := setupRoutes(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	backup := getBackup(t, handler, "/api/admin/backup").Body.Bytes()
+	if err := currentStore().Save(context.Background(), RadarData{Items: []RadarItem{{Label: "Go", Quadrant: "Tools", Ring: "Adopted"}}}); err != nil {
+		t.Fatal(err)
+	}
+
+	rec := postRestore(t, handler, []byte("Items:\n- Label: Go\n  Quadrant: Gadgets\n  Ring: Adopted\n"))
+	var report validationReport
+	if err := json.NewDecoder(rec.Body).Decode(&report); err != nil || rec.Code != http.StatusBadRequest ||
+		len(report.Violations) != 1 || report.Violations[0].Line != 3 || report.Violations[0].Field != `item "Go".Quadrant` {
+		t.Errorf("invalid backup: status = %d: %+v, %v", rec.Code, report, err)
+	}
+	if rec := postRestore(t, handler, []byte("not: [a backup")); rec.Code != http.StatusBadRequest {
+		t.Errorf("garbage: status = %d, want 400", rec.Code)
+	}
+
+	// The backup is put back as it was, although Adopted items may not move
+	// to Retired and the item changed since.
+	rec = postRestore(t, handler, backup)
+	if rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), `"restored":1`) {
+		t.Fatalf("status = %d: %s", rec.Code, rec.Body)
+	}
+	data, err := readRadarData(cfg.Data.Path)
+	if err != nil || len(data.Items) != 1 || data.Items[0].Ring != "Retired" || data.Items[0].LastUpdated.Year() != 2024 {
+		t.Errorf("restored items = %+v, %v", data.Items, err)
+	}
+}
+
+func TestRestoreArchive(t *testing.T) {
+	ctx := context.Background()
+	db := openTestStore(t, "bbolt", filepath.Join(t.TempDir(), "radar.bolt"))
+	store := &databaseStore{db: db}
+	if err := store.Save(ctx, RadarData{Items: []RadarItem{{Label: "Go", Quadrant: "Tools", Ring: "Adopted"}}}); err != nil {
+		t.Fatal(err)
+	}
+	useStore(t, store)
+	useEncryption(t, testEncryptionKey)
+	cfg := defaultConfig()
+	cfg.Admin.Token = "s3cret"
+	handler, err := setupRoutes(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	backup := getBackup(t, handler, "/api/admin/backup?format=tar.gz").Body.Bytes()
+	if err := store.Save(ctx, RadarData{}); err != nil {
+		t.Fatal(err)
+	}
+
+	if rec := postRestore(t, handler, backup); rec.Code != http.StatusOK {
+		t.Fatalf("status = %d: %s", rec.Code, rec.Body)
+	}
+	data, err := store.Load(ctx)
+	if err != nil || len(data.Items) != 1 || data.Items[0].Label != "Go" {
+		t.Errorf("restored items = %+v, %v", data.Items, err)
+	}
+	events, err := db.AuditEvents(ctx, 1)
+	if err != nil || len(events) != 1 || events[0].Action != "restore" || !strings.Contains(events[0].Detail, "with 1 items") {
+		t.Errorf("AuditEvents() = %+v, %v", events, err)
+	}
+
+	// An encrypted backup can't be restored without the key.
+	useEncryption(t, "")
+	if rec := postRestore(t, handler, backup); rec.Code != http.StatusBadRequest || !strings.Contains(rec.Body.String(), "no encryption key") {
+		t.Errorf("without key: status = %d: %s", rec.Code, rec.Body)
+	}
+}
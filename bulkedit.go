@@ -0,0 +1,289 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// BulkEdit is the body of POST /items/bulk: the items to edit, by ID, and
+// the change made to every one of them.
+type BulkEdit struct {
+	IDs []string `json:"ids,omitempty"`
+	// Patch is a JSON Merge Patch applied to each item, see mergePatch.
+	Patch      json.RawMessage `json:"patch,omitempty"`
+	AddTags    Tags            `json:"addTags,omitempty"`
+	RemoveTags Tags            `json:"removeTags,omitempty"`
+	// ReplaceOwner replaces an owner of the items with another.
+	ReplaceOwner *OwnerReplacement `json:"replaceOwner,omitempty"`
+}
+
+// OwnerReplacement replaces the owners whose name, team or email address is
+// From, ignoring case, with To, or removes them without it.
+type OwnerReplacement struct {
+	From string `json:"from"`
+	To   *Owner `json:"to"`
+}
+
+// BulkEditResult is the response of POST /items/bulk: the number of items
+// selected and the items the edit changed, as saved.
+type BulkEditResult struct {
+	Matched int         `json:"matched"`
+	Changed int         `json:"changed"`
+	Items   []RadarItem `json:"items"`
+}
+
+// apply returns item with the edit made to it.
+func (e BulkEdit) apply(item RadarItem) (RadarItem, error) {
+	if e.Patch != nil {
+		patched, err := patchItem(item, mergePatchType, e.Patch)
+		if err != nil {
+			return RadarItem{}, err
+		}
+		if patched.ID != item.ID {
+			return RadarItem{}, &AppError{Code: http.StatusBadRequest, Message: "The ID of an item can't be patched"}
+		}
+		patched.Source = item.Source
+		item = patched
+	}
+	if len(e.AddTags) > 0 || len(e.RemoveTags) > 0 {
+		tags := slices.DeleteFunc(slices.Clone(item.Tags), func(tag string) bool { return slices.Contains(e.RemoveTags, tag) })
+		for _, tag := range e.AddTags {
+			if !slices.Contains(tags, tag) {
+				tags = append(tags, tag)
+			}
+		}
+		item.Tags = tags
+	}
+	if r := e.ReplaceOwner; r != nil && ownedBy(item, r.From) {
+		// The new owner takes the place of the first replaced one, unless
+		// the item already has it.
+		replaced := r.To == nil || slices.ContainsFunc(item.Owners, func(o Owner) bool { return !o.is(r.From) && strings.EqualFold(o.Name, r.To.Name) })
+		var owners Owners
+		for _, owner := range item.Owners {
+			if !owner.is(r.From) {
+				owners = append(owners, owner)
+			} else if !replaced {
+				owners = append(owners, *r.To)
+				replaced = true
+			}
+		}
+		item.Owners = owners
+	}
+	return item, nil
+}
+
+// bulkEditHandler makes the edit in the body to every item it lists by ID
+// and that the filters of GET /radar given as parameters select, in a
+// single save recorded as a single audit event, and responds with the
+// BulkEditResult. Either must select the items: there is no edit of every
+// item without parameters.
+func bulkEditHandler(w http.ResponseWriter, r *http.Request) {
+	body, err := readItemBody(w, r)
+	if err != nil {
+		handleError(w, err)
+		return
+	}
+	dec := json.NewDecoder(bytes.NewReader(body))
+	dec.DisallowUnknownFields()
+	var edit BulkEdit
+	if err := dec.Decode(&edit); err != nil {
+		handleError(w, &AppError{Code: http.StatusBadRequest, Message: "Invalid bulk edit: " + err.Error(), Err: err})
+		return
+	}
+	switch {
+	case edit.Patch != nil && !bytes.HasPrefix(bytes.TrimSpace(edit.Patch), []byte("{")):
+		handleError(w, &AppError{Code: http.StatusBadRequest, Message: "The patch of a bulk edit must be an object"})
+		return
+	case edit.Patch == nil && len(edit.AddTags) == 0 && len(edit.RemoveTags) == 0 && edit.ReplaceOwner == nil:
+		handleError(w, &AppError{Code: http.StatusBadRequest, Message: "A bulk edit requires a patch, addTags, removeTags or replaceOwner"})
+		return
+	case len(edit.IDs) == 0 && r.URL.RawQuery == "":
+		handleError(w, &AppError{Code: http.StatusBadRequest, Message: "Select the items to edit with ids or the parameters of GET /api/v1/radar"})
+		return
+	case edit.ReplaceOwner != nil && strings.TrimSpace(edit.ReplaceOwner.From) == "":
+		handleError(w, &AppError{Code: http.StatusBadRequest, Message: "replaceOwner requires the owner to replace as from"})
+		return
+	}
+
+	editMu.Lock()
+	defer editMu.Unlock()
+	store, data, err := editableData()
+	if err != nil {
+		handleError(w, err)
+		return
+	}
+	var selected []RadarItem
+	if len(edit.IDs) > 0 {
+		filter, err := parseRadarFilter(r.URL.Query(), withSegments(data))
+		if err != nil {
+			handleError(w, err)
+			return
+		}
+		for _, id := range edit.IDs {
+			item, ok := findItem(data.Items, id)
+			if !ok {
+				handleError(w, &AppError{Code: http.StatusNotFound, Message: fmt.Sprintf("Unknown item %s", id)})
+				return
+			}
+			if filter.matches(item) && !slices.ContainsFunc(selected, func(s RadarItem) bool { return s.ID == item.ID }) {
+				selected = append(selected, item)
+			}
+		}
+	} else if selected, err = selectItems(r, withSegments(data)); err != nil {
+		handleError(w, err)
+		return
+	}
+
+	data.Items = slices.Clone(data.Items)
+	var changed []string
+	for _, item := range selected {
+		edited, err := edit.apply(item)
+		if err != nil {
+			handleError(w, err)
+			return
+		}
+		if sameItem(edited, item) {
+			continue
+		}
+		i := slices.IndexFunc(data.Items, func(s RadarItem) bool { return s.ID == item.ID })
+		data.Items[i] = edited
+		changed = append(changed, item.ID)
+	}
+
+	result := BulkEditResult{Matched: len(selected), Changed: len(changed), Items: []RadarItem{}}
+	if len(changed) > 0 {
+		saved, err := saveEdit(r, store, data, AuditEvent{Action: "bulk edit", Detail: fmt.Sprintf("%d items (%s)", len(changed), strings.Join(changed, ", "))})
+		if err != nil {
+			handleError(w, err)
+			return
+		}
+		log.Printf("Edited %d items: %s", len(changed), strings.Join(changed, ", "))
+		for _, id := range changed {
+			item, _ := findItem(saved.Items, id)
+			result.Items = append(result.Items, item)
+		}
+	}
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(result); err != nil {
+		log.Printf("Failed to encode bulk edit result: %v", err)
+	}
+}
+
+// OwnerTransfer is the body of POST /admin/owners/transfer: the owner whose
+// items are transferred, by name, team or email address, ignoring case,
+// and the owner taking them over.
+type OwnerTransfer struct {
+	From string `json:"from"`
+	To   Owner  `json:"to"`
+}
+
+// TransferredItem is an item of an owner transfer, with its owners before
+// and after it.
+type TransferredItem struct {
+	ID     string `json:"id"`
+	Label  string `json:"label"`
+	Before Owners `json:"before"`
+	After  Owners `json:"after"`
+}
+
+// OwnerTransferResult is the response of POST /admin/owners/transfer: the
+// items transferred, or that would be with a dry run.
+type OwnerTransferResult struct {
+	DryRun      bool              `json:"dryRun"`
+	Transferred int               `json:"transferred"`
+	Items       []TransferredItem `json:"items"`
+}
+
+// ownerTransferHandler transfers every item owned by the owner of the
+// OwnerTransfer in the body, archived ones included, to its new owner,
+// like a bulk edit replacing the owner of all items, in a single save
+// recorded as a single audit event. It responds with the
+// OwnerTransferResult. With ?dryRun=true, the transfer is only checked and
+// previewed.
+func ownerTransferHandler(w http.ResponseWriter, r *http.Request) {
+	dryRun := false
+	if value := r.URL.Query().Get("dryRun"); value != "" {
+		var err error
+		if dryRun, err = strconv.ParseBool(value); err != nil {
+			handleError(w, &AppError{Code: http.StatusBadRequest, Message: fmt.Sprintf("Invalid dryRun %q, must be true or false", value)})
+			return
+		}
+	}
+	body, err := readItemBody(w, r)
+	if err != nil {
+		handleError(w, err)
+		return
+	}
+	dec := json.NewDecoder(bytes.NewReader(body))
+	dec.DisallowUnknownFields()
+	var transfer OwnerTransfer
+	if err := dec.Decode(&transfer); err != nil {
+		handleError(w, &AppError{Code: http.StatusBadRequest, Message: "Invalid owner transfer: " + err.Error(), Err: err})
+		return
+	}
+	switch {
+	case strings.TrimSpace(transfer.From) == "":
+		handleError(w, &AppError{Code: http.StatusBadRequest, Message: "An owner transfer requires the owner to transfer from as from"})
+		return
+	case strings.TrimSpace(transfer.To.Name) == "":
+		handleError(w, &AppError{Code: http.StatusBadRequest, Message: "An owner transfer requires the owner to transfer to, with a name, as to"})
+		return
+	}
+
+	editMu.Lock()
+	defer editMu.Unlock()
+	store, current, err := editableData()
+	if err != nil {
+		handleError(w, err)
+		return
+	}
+	edit := BulkEdit{ReplaceOwner: &OwnerReplacement{From: transfer.From, To: &transfer.To}}
+	data := current
+	data.Items = slices.Clone(data.Items)
+	result := OwnerTransferResult{DryRun: dryRun, Items: []TransferredItem{}}
+	var ids []string
+	for i, item := range data.Items {
+		// Replacing an owner doesn't fail.
+		edited, _ := edit.apply(item)
+		if sameItem(edited, item) {
+			continue
+		}
+		data.Items[i] = edited
+		ids = append(ids, item.ID)
+		result.Items = append(result.Items, TransferredItem{ID: item.ID, Label: item.Label, Before: item.Owners, After: edited.Owners})
+	}
+	result.Transferred = len(ids)
+
+	event := AuditEvent{Action: "transfer owner", Detail: fmt.Sprintf("%s to %s: %d items (%s)", transfer.From, transfer.To.Name, len(ids), strings.Join(ids, ", "))}
+	switch {
+	case len(ids) == 0:
+	case dryRun:
+		var errs ValidationErrors
+		_, err := prepareSave(withRequestAudit(r, event), data, current)
+		if errors.As(err, &errs) {
+			handleError(w, invalidDataError("Invalid radar data", errs))
+			return
+		}
+		if err != nil {
+			handleError(w, &AppError{Code: http.StatusInternalServerError, Message: "Failed to check radar data", Err: err})
+			return
+		}
+	default:
+		if _, err := saveEdit(r, store, data, event); err != nil {
+			handleError(w, err)
+			return
+		}
+		log.Printf("Transferred %d items from %s to %s: %s", len(ids), transfer.From, transfer.To.Name, strings.Join(ids, ", "))
+	}
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(result); err != nil {
+		log.Printf("Failed to encode owner transfer result: %v", err)
+	}
+}
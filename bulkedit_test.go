@@ -0,0 +1,182 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestBulkEdit(t *testing.T) {
+	db := openTestStore(t, "sqlite", "file::memory:")
+	seed := RadarData{Items: []RadarItem{
+		{Label: "Jenkins", Quadrant: "Tools", Ring: "Adopted", Tags: Tags{"ci", "deprecated"}, Owners: Owners{{Name: "Jane Doe"}, {Name: "Platform"}}},
+		{Label: "Perl", Quadrant: "Programming Languages & Frameworks", Ring: "In Discovery", Tags: Tags{"deprecated"}, Owners: Owners{{Name: "jane doe"}}},
+		{Label: "Go", Quadrant: "Programming Languages & Frameworks", Ring: "Adopted", Owners: Owners{{Name: "Platform"}}},
+	}}
+	if err := db.Save(context.Background(), seed, AuditEvent{Actor: "test", Action: "seed"}); err != nil {
+		t.Fatal(err)
+	}
+	cfg := defaultConfig()
+	cfg.Admin.Token = "s3cret"
+	useConfig(t, cfg)
+	useStore(t, &databaseStore{db: db})
+	handler, err := setupRoutes(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	post := func(target, body string) (int, BulkEditResult) {
+		t.Helper()
+		rec := adminRequest(t, handler, http.MethodPost, target, nil, body)
+		var result BulkEditResult
+		if rec.Code == http.StatusOK {
+			if err := json.Unmarshal(rec.Body.Bytes(), &result); err != nil {
+				t.Fatal(err)
+			}
+		}
+		return rec.Code, result
+	}
+	item := func(id string) RadarItem {
+		t.Helper()
+		data, err := loadRadarData()
+		if err != nil {
+			t.Fatal(err)
+		}
+		item, _ := findItem(data.Items, id)
+		return item
+	}
+
+	// Move everything tagged deprecated to Not Recommended, in one save.
+	code, result := post("/api/v1/items/bulk?tag=deprecated", `{"patch": {"ring": "Not Recommended"}, "removeTags": ["deprecated"], "addTags": ["legacy"]}`)
+	if code != http.StatusOK || result.Matched != 2 || result.Changed != 2 || len(result.Items) != 2 || result.Items[0].Ring != "Not Recommended" {
+		t.Fatalf("bulk move = %d %+v", code, result)
+	}
+	if jenkins := item("jenkins"); jenkins.Ring != "Not Recommended" || strings.Join(jenkins.Tags, ",") != "ci,legacy" || item("go").Ring != "Adopted" {
+		t.Errorf("items after bulk move = %+v, %+v", jenkins, item("go"))
+	}
+	events, err := db.AuditEvents(context.Background(), 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(events) != 2 || events[0].Action != "bulk edit" || events[0].Detail != "2 items (jenkins, perl)" || events[0].SourceIP != "192.0.2.1" {
+		t.Errorf("audit events = %+v", events)
+	}
+
+	// Reassign an owner, matched ignoring case, without duplicating one an
+	// item already has.
+	code, result = post("/api/v1/items/bulk?owner=Jane+Doe", `{"replaceOwner": {"from": "Jane Doe", "to": {"name": "Platform"}}}`)
+	if code != http.StatusOK || result.Matched != 2 || result.Changed != 2 {
+		t.Errorf("owner reassignment = %d %+v", code, result)
+	}
+	if owners := item("jenkins").Owners.names(); owners != "Platform" {
+		t.Errorf("jenkins owners = %q", owners)
+	}
+	if owners := item("perl").Owners.names(); owners != "Platform" {
+		t.Errorf("perl owners = %q", owners)
+	}
+
+	// IDs select items, narrowed by any filters; unchanged items aren't saved.
+	code, result = post("/api/v1/items/bulk?ring=Adopted", `{"ids": ["go", "perl"], "addTags": ["backend"]}`)
+	if code != http.StatusOK || result.Matched != 1 || result.Changed != 1 || result.Items[0].ID != "go" {
+		t.Errorf("bulk edit by ID = %d %+v", code, result)
+	}
+	if code, result := post("/api/v1/items/bulk", `{"ids": ["go"], "addTags": ["backend"]}`); code != http.StatusOK || result.Matched != 1 || result.Changed != 0 {
+		t.Errorf("bulk edit changing nothing = %d %+v", code, result)
+	}
+	if events, _ := db.AuditEvents(context.Background(), 10); len(events) != 4 {
+		t.Errorf("got %d audit events, want 4", len(events))
+	}
+
+	for _, tt := range []struct {
+		target, body string
+		want         int
+	}{
+		{"/api/v1/items/bulk", `{"addTags": ["x"]}`, http.StatusBadRequest},
+		{"/api/v1/items/bulk?tag=ci", `{}`, http.StatusBadRequest},
+		{"/api/v1/items/bulk?tag=ci", `{"patch": null}`, http.StatusBadRequest},
+		{"/api/v1/items/bulk?tag=ci", `{"patch": ["ring"]}`, http.StatusBadRequest},
+		{"/api/v1/items/bulk?tag=ci", `{"addTag": ["x"]}`, http.StatusBadRequest},
+		{"/api/v1/items/bulk?tag=ci", `{"replaceOwner": {"to": {"name": "X"}}}`, http.StatusBadRequest},
+		{"/api/v1/items/bulk?ring=Hold", `{"addTags": ["x"]}`, http.StatusBadRequest},
+		{"/api/v1/items/bulk", `{"ids": ["cobol"], "addTags": ["x"]}`, http.StatusNotFound},
+		// Either every item is saved or none is.
+		{"/api/v1/items/bulk?quadrant=Tools", `{"patch": {"label": "Go"}}`, http.StatusBadRequest},
+		{"/api/v1/items/bulk?includeArchived=true", `{"patch": {"ring": "Hold"}}`, http.StatusBadRequest},
+	} {
+		if code, _ := post(tt.target, tt.body); code != tt.want {
+			t.Errorf("POST %s %s = %d, want %d", tt.target, tt.body, code, tt.want)
+		}
+	}
+	if item("go").Ring != "Adopted" || item("jenkins").Label != "Jenkins" {
+		t.Errorf("rejected bulk edits changed items")
+	}
+}
+
+func TestOwnerTransfer(t *testing.T) {
+	db := openTestStore(t, "sqlite", "file::memory:")
+	seed := RadarData{Items: []RadarItem{
+		{Label: "Jenkins", Quadrant: "Tools", Ring: "Adopted", Owners: Owners{{Name: "Jane Doe"}, {Name: "Platform", Email: "platform@example.com"}}},
+		{Label: "Perl", Quadrant: "Tools", Ring: "In Discovery", Archived: true, Owners: Owners{{Name: "platform"}}},
+		{Label: "Go", Quadrant: "Tools", Ring: "Adopted", Owners: Owners{{Name: "Backend"}}},
+	}}
+	if err := db.Save(context.Background(), seed, AuditEvent{Actor: "test", Action: "seed"}); err != nil {
+		t.Fatal(err)
+	}
+	cfg := defaultConfig()
+	cfg.Admin.Token = "s3cret"
+	useConfig(t, cfg)
+	useStore(t, &databaseStore{db: db})
+	handler, err := setupRoutes(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	transfer := `{"from": "Platform", "to": {"name": "Developer Experience", "slack": "#devex"}}`
+	owners := func(id string) string {
+		t.Helper()
+		data, err := loadRadarData()
+		if err != nil {
+			t.Fatal(err)
+		}
+		item, _ := findItem(data.Items, id)
+		return item.Owners.names()
+	}
+
+	// The preview changes nothing.
+	rec := adminRequest(t, handler, http.MethodPost, "/api/v1/admin/owners/transfer?dryRun=true", nil, transfer)
+	var result OwnerTransferResult
+	if err := json.Unmarshal(rec.Body.Bytes(), &result); err != nil || rec.Code != http.StatusOK || !result.DryRun || result.Transferred != 2 {
+		t.Fatalf("transfer preview = %d %s", rec.Code, rec.Body)
+	}
+	if item := result.Items[0]; item.ID != "jenkins" || item.Before.names() != "Jane Doe, Platform" || item.After.names() != "Jane Doe, Developer Experience" {
+		t.Errorf("previewed item = %+v", item)
+	}
+	if got := owners("jenkins"); got != "Jane Doe, Platform" {
+		t.Errorf("owners after the preview = %s", got)
+	}
+
+	rec = adminRequest(t, handler, http.MethodPost, "/api/v1/admin/owners/transfer", nil, transfer)
+	if err := json.Unmarshal(rec.Body.Bytes(), &result); err != nil || rec.Code != http.StatusOK || result.DryRun || result.Transferred != 2 {
+		t.Fatalf("transfer = %d %s", rec.Code, rec.Body)
+	}
+	if got := owners("jenkins") + "; " + owners("perl") + "; " + owners("go"); got != "Jane Doe, Developer Experience; Developer Experience; Backend" {
+		t.Errorf("owners after the transfer = %s", got)
+	}
+	events, err := db.AuditEvents(context.Background(), 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if e := events[0]; e.Action != "transfer owner" || e.Detail != "Platform to Developer Experience: 2 items (jenkins, perl)" {
+		t.Errorf("audit event = %+v", e)
+	}
+
+	for name, body := range map[string]string{
+		"without from": `{"to": {"name": "Backend"}}`,
+		"without to":   `{"from": "Backend"}`,
+		"unknown":      `{"from": "Backend", "to": {"name": "Platform"}, "replace": true}`,
+	} {
+		if rec := adminRequest(t, handler, http.MethodPost, "/api/v1/admin/owners/transfer", nil, body); rec.Code != http.StatusBadRequest {
+			t.Errorf("transfer %s = %d, want 400", name, rec.Code)
+		}
+	}
+}
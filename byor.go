@@ -0,0 +1,280 @@
+package main
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// byorRings maps the rings of Thoughtworks' Build Your Own Radar onto this
+// radar's rings. Trial and assess both mean a technology is being evaluated.
+var byorRings = map[string]string{
+	"adopt":  "Adopted",
+	"trial":  "In Discovery",
+	"assess": "In Discovery",
+	"hold":   "Not Recommended",
+}
+
+// byorQuadrants maps Build Your Own Radar quadrant names onto this radar's
+// quadrants.
+var byorQuadrants = map[string]string{
+	"techniques":               "Techniques",
+	"platforms":                "Platforms",
+	"tools":                    "Tools",
+	"languages & frameworks":   "Programming Languages & Frameworks",
+	"languages and frameworks": "Programming Languages & Frameworks",
+	"languages-and-frameworks": "Programming Languages & Frameworks",
+}
+
+// byorRequiredColumns are the CSV columns every row must have a value for.
+var byorRequiredColumns = []string{"name", "ring", "quadrant"}
+
+// byorColumns are the columns of a Build Your Own Radar CSV file, and the
+// keys of its JSON entries, in the order they are exported.
+var byorColumns = []string{"name", "ring", "quadrant", "isNew", "description"}
+
+// byorEntry is a row of a Build Your Own Radar sheet. BYOR reads isNew as
+// the text TRUE or FALSE, in JSON too.
+type byorEntry struct {
+	Name        string `json:"name"`
+	Ring        string `json:"ring"`
+	Quadrant    string `json:"quadrant"`
+	IsNew       string `json:"isNew"`
+	Description string `json:"description"`
+}
+
+// byorChoice resolves a BYOR ring or quadrant name, accepting both this
+// radar's names and the BYOR aliases, case-insensitively.
+func byorChoice(value string, aliases map[string]string, allowed []string) (string, bool) {
+	key := strings.ToLower(strings.TrimSpace(value))
+	for _, name := range allowed {
+		if strings.ToLower(name) == key {
+			return name, true
+		}
+	}
+	name, ok := aliases[key]
+	return name, ok
+}
+
+// parseBYORCSV converts a CSV file in the Build Your Own Radar format, with
+// the columns name, ring, quadrant, isNew and description, into radar items.
+// Columns are matched by their header, in any order. BYOR marks new items
+// rather than moved ones; both are highlighted the same way, so isNew sets
+// Moved. Every invalid row is reported as a ValidationError.
+func parseBYORCSV(file string, r io.Reader) ([]RadarItem, error) {
+	items, _, err := parseBYORRows(file, r)
+	if err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+// parseBYORRows is parseBYORCSV returning the line of every item as well,
+// and the items of every row along with the ValidationErrors of the
+// invalid ones.
+func parseBYORRows(file string, r io.Reader) ([]RadarItem, []int, error) {
+	cr := csv.NewReader(r)
+	cr.TrimLeadingSpace = true
+	header, err := cr.Read()
+	if errors.Is(err, io.EOF) {
+		return nil, nil, fmt.Errorf("%s: empty CSV file", file)
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		name = strings.TrimPrefix(name, "\ufeff")
+		columns[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	var errs ValidationErrors
+	for _, name := range byorRequiredColumns {
+		if _, ok := columns[name]; !ok {
+			errs = append(errs, ValidationError{File: file, Line: 1, Rule: ruleRequired, Message: fmt.Sprintf("missing column %q", name)})
+		}
+	}
+	if len(errs) > 0 {
+		return nil, nil, errs
+	}
+
+	var items []RadarItem
+	var lines []int
+	seen := make(map[string]int)
+	for {
+		record, err := cr.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+		line, _ := cr.FieldPos(0)
+		get := func(column string) string {
+			if i, ok := columns[strings.ToLower(column)]; ok && i < len(record) {
+				return strings.TrimSpace(record[i])
+			}
+			return ""
+		}
+		report := func(field, rule, format string, args ...any) {
+			errs = append(errs, ValidationError{File: file, Line: line, Field: field, Rule: rule, Message: fmt.Sprintf(format, args...)})
+		}
+
+		item := RadarItem{Label: get("name"), Description: get("description")}
+		name := fmt.Sprintf("row %d", line)
+		if item.Label == "" {
+			report(name+".name", ruleRequired, "missing")
+		} else {
+			name = fmt.Sprintf("item %q", item.Label)
+			if first, ok := seen[labelKey(item.Label)]; ok {
+				report(name+".name", ruleUnique, "duplicate name, first defined on line %d", first)
+			} else {
+				seen[labelKey(item.Label)] = line
+			}
+		}
+
+		var ok bool
+		if item.Ring, ok = byorChoice(get("ring"), byorRings, segmentNames(defaultRings)); !ok {
+			report(name+".ring", ruleAllowedValue, "unknown ring %q, must be one of adopt, trial, assess, hold", get("ring"))
+		}
+		if item.Quadrant, ok = byorChoice(get("quadrant"), byorQuadrants, segmentNames(defaultQuadrants)); !ok {
+			report(name+".quadrant", ruleAllowedValue, "unknown quadrant %q, must be one of techniques, platforms, tools, languages & frameworks", get("quadrant"))
+		}
+		if isNew := get("isNew"); isNew != "" {
+			moved, err := strconv.ParseBool(strings.ToLower(isNew))
+			if err != nil {
+				report(name+".isNew", ruleSchema, "invalid value %q, expected true or false", isNew)
+			}
+			item.Moved = moved
+		}
+		items = append(items, item)
+		lines = append(lines, line)
+	}
+
+	if len(errs) > 0 {
+		return items, lines, errs
+	}
+	return items, lines, nil
+}
+
+// importBYORCSV converts a Build Your Own Radar CSV file into radar data
+// dated the current month.
+func importBYORCSV(file string, r io.Reader) (RadarData, error) {
+	items, err := parseBYORCSV(file, r)
+	if err != nil {
+		return RadarData{}, err
+	}
+	return RadarData{LastModified: time.Now().Format("January 2006"), Items: items}, nil
+}
+
+// runImportCSV implements the import-csv command, which converts a Build
+// Your Own Radar CSV file into radar data written to stdout as YAML, or to
+// the file given by -o in the format its extension selects.
+func runImportCSV(args []string, stdin io.Reader, stdout io.Writer) error {
+	fs := flag.NewFlagSet("import-csv", flag.ContinueOnError)
+	out := fs.String("o", "", "write the radar data to this file instead of stdout; the extension selects YAML, JSON or TOML")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: clean-tech-radar import-csv [-o file] <radar.csv | ->")
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		fs.Usage()
+		return errors.New("expected one CSV file, or - to read from stdin")
+	}
+
+	file, in := fs.Arg(0), stdin
+	if file != "-" {
+		f, err := os.Open(file)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		in = f
+	}
+	data, err := importBYORCSV(file, in)
+	if err != nil {
+		return err
+	}
+
+	if *out == "" {
+		content, err := encodeRadarData("", data)
+		if err != nil {
+			return err
+		}
+		_, err = stdout.Write(content)
+		return err
+	}
+	if err := writeRadarData(*out, data); err != nil {
+		return err
+	}
+	log.Printf("Imported %d items from %s into %s", len(data.Items), file, *out)
+	return nil
+}
+
+// byorEntries returns items as rows of a Build Your Own Radar sheet, with
+// this radar's ring and quadrant names, which the import accepts back.
+func byorEntries(items []RadarItem) []byorEntry {
+	entries := []byorEntry{}
+	for _, item := range items {
+		entries = append(entries, byorEntry{
+			Name:        item.Label,
+			Ring:        item.Ring,
+			Quadrant:    item.Quadrant,
+			IsNew:       strings.ToUpper(strconv.FormatBool(item.Moved)),
+			Description: item.Description,
+		})
+	}
+	return entries
+}
+
+// byorExportHandler returns the handler serving the items selectItems
+// selects as a Build Your Own Radar sheet in format, formatJSON or
+// formatCSV, with serveConditional.
+func byorExportHandler(format string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data, err := loadRadarData()
+		if err != nil {
+			handleError(w, err)
+			return
+		}
+		items, err := selectItems(r, withSegments(data))
+		if err != nil {
+			handleError(w, err)
+			return
+		}
+
+		var buf bytes.Buffer
+		contentType := "application/json"
+		if format == formatCSV {
+			contentType = "text/csv; charset=utf-8"
+			cw := csv.NewWriter(&buf)
+			cw.Write(byorColumns)
+			for _, entry := range byorEntries(items) {
+				cw.Write([]string{entry.Name, entry.Ring, entry.Quadrant, entry.IsNew, entry.Description})
+			}
+			cw.Flush()
+			err = cw.Error()
+		} else {
+			err = json.NewEncoder(&buf).Encode(byorEntries(items))
+		}
+		if err != nil {
+			handleError(w, &AppError{Code: http.StatusInternalServerError, Message: "Failed to encode response", Err: err})
+			return
+		}
+		w.Header().Set("Content-Type", contentType)
+		serveConditional(w, r, buf.Bytes(), dataModTime(r.Context()))
+	})
+}
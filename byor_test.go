@@ -0,0 +1,138 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseBYORCSV(t *testing.T) {
+	csv := "name,ring,quadrant,isNew,description\n" +
+		"Kubernetes,adopt,platforms,FALSE,Container orchestration.\n" +
+		"Go,Trial,Languages & Frameworks,TRUE,\"Fast, simple.\"\n" +
+		"Jenkins,hold,tools,,\n"
+
+	items, err := parseBYORCSV("radar.csv", strings.NewReader(csv))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []RadarItem{
+		{Label: "Kubernetes", Quadrant: "Platforms", Ring: "Adopted", Description: "Container orchestration."},
+		{Label: "Go", Quadrant: "Programming Languages & Frameworks", Ring: "In Discovery", Moved: true, Description: "Fast, simple."},
+		{Label: "Jenkins", Quadrant: "Tools", Ring: "Not Recommended"},
+	}
+	if !reflect.DeepEqual(items, want) {
+		t.Errorf("parseBYORCSV() = %+v, want %+v", items, want)
+	}
+}
+
+func TestParseBYORCSVErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		csv  string
+		want []string
+	}{
+		{
+			name: "missing columns",
+			csv:  "name,description\nGo,\n",
+			want: []string{`radar.csv:1: missing column "ring"`, `radar.csv:1: missing column "quadrant"`},
+		},
+		{
+			name: "invalid rows",
+			csv:  "quadrant,ring,name,isNew\ntools,adopt,Go,yes\ngadgets,maybe,go,\ntools,adopt,,\n",
+			want: []string{
+				`radar.csv:2: item "Go".isNew: invalid value "yes", expected true or false`,
+				`radar.csv:3: item "go".name: duplicate name, first defined on line 2`,
+				`radar.csv:3: item "go".ring: unknown ring "maybe"`,
+				`radar.csv:3: item "go".quadrant: unknown quadrant "gadgets"`,
+				`radar.csv:4: row 4.name: missing`,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := parseBYORCSV("radar.csv", strings.NewReader(tt.csv))
+			var errs ValidationErrors
+			if !errors.As(err, &errs) || len(errs) != len(tt.want) {
+				t.Fatalf("parseBYORCSV() error = %v, want %d errors", err, len(tt.want))
+			}
+			for i, want := range tt.want {
+				if got := errs[i].Error(); !strings.HasPrefix(got, want) {
+					t.Errorf("error %d = %q, want prefix %q", i, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestRunImportCSV(t *testing.T) {
+	csv := "name,ring,quadrant,isNew,description\nGo,adopt,tools,false,\n"
+	out := filepath.Join(t.TempDir(), "radar.toml")
+
+	if err := runImportCSV([]string{"-o", out, "-"}, strings.NewReader(csv), nil); err != nil {
+		t.Fatal(err)
+	}
+	if err := validateRadarData(out); err != nil {
+		t.Fatalf("imported data is invalid: %v", err)
+	}
+
+	var stdout bytes.Buffer
+	if err := runImportCSV([]string{"-"}, strings.NewReader(csv), &stdout); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(stdout.String(), "- Label: Go\n") {
+		t.Errorf("stdout = %q, want YAML radar data", stdout.String())
+	}
+}
+
+func TestBYORExport(t *testing.T) {
+	cfg := defaultConfig()
+	cfg.Data.Path = writeFile(t, "radar.yaml", `Items:
+- Label: Go
+  Quadrant: Programming Languages & Frameworks
+  Ring: Adopted
+  Moved: true
+  Description: Fast, "simple".
+- Label: Jenkins
+  Quadrant: Tools
+  Ring: Not Recommended
+`)
+	useConfig(t, cfg)
+	handler, err := setupRoutes(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rec := doRequest(t, handler, http.MethodGet, "/api/v1/export/byor.json?quadrant=Tools")
+	var entries []byorEntry
+	if err := json.Unmarshal(rec.Body.Bytes(), &entries); rec.Code != http.StatusOK || err != nil {
+		t.Fatalf("GET /api/v1/export/byor.json = %d %q", rec.Code, rec.Body.String())
+	}
+	if want := []byorEntry{{Name: "Jenkins", Ring: "Not Recommended", Quadrant: "Tools", IsNew: "FALSE"}}; !reflect.DeepEqual(entries, want) {
+		t.Errorf("entries = %+v, want %+v", entries, want)
+	}
+
+	rec = doRequest(t, handler, http.MethodGet, "/api/export/byor.csv")
+	if rec.Code != http.StatusOK || rec.Header().Get("Content-Type") != "text/csv; charset=utf-8" {
+		t.Fatalf("GET /api/export/byor.csv = %d %q", rec.Code, rec.Header().Get("Content-Type"))
+	}
+	want := "name,ring,quadrant,isNew,description\n" +
+		"Go,Adopted,Programming Languages & Frameworks,TRUE,\"Fast, \"\"simple\"\".\"\n" +
+		"Jenkins,Not Recommended,Tools,FALSE,\n"
+	if rec.Body.String() != want {
+		t.Errorf("CSV = %q, want %q", rec.Body.String(), want)
+	}
+	// The export imports back unchanged.
+	items, err := parseBYORCSV("radar.csv", rec.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if items[0].Quadrant != "Programming Languages & Frameworks" || !items[0].Moved || items[1].Ring != "Not Recommended" {
+		t.Errorf("imported export = %+v", items)
+	}
+}
@@ -0,0 +1,152 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// activeCache is the Redis cache of the parsed radar data when cache.redisURL
+// is set. It is opened at startup and never changes.
+var activeCache atomic.Pointer[radarCache]
+
+// radarCache caches the parsed radar data in Redis, where every instance
+// sharing the server finds it, and in memory. Both copies expire after the
+// TTL. Dropping the data publishes on an invalidation channel, so every
+// instance drops its in-memory copy too.
+type radarCache struct {
+	client  *redis.Client
+	key     string
+	channel string
+	ttl     time.Duration
+	cancel  context.CancelFunc
+
+	mu      sync.Mutex
+	local   *RadarData
+	expires time.Time
+}
+
+// openRadarCache connects to the Redis server of cfg and starts listening
+// for invalidations. An unreachable server is only logged: the client
+// reconnects by itself, and until then data is loaded without the cache.
+func openRadarCache(cfg CacheConfig) (*radarCache, error) {
+	opts, err := redis.ParseURL(cfg.RedisURL)
+	if err != nil {
+		return nil, err
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	c := &radarCache{
+		client:  redis.NewClient(opts),
+		key:     cfg.Prefix + "data",
+		channel: cfg.Prefix + "invalidate",
+		ttl:     cfg.TTL,
+		cancel:  cancel,
+	}
+	if err := c.client.Ping(ctx).Err(); err != nil {
+		slog.Warn("Redis cache unreachable, loading radar data without it", "err", err)
+	}
+	pubsub := c.client.Subscribe(ctx, c.channel)
+	go c.listen(ctx, pubsub)
+	return c, nil
+}
+
+// listen drops the in-memory copy whenever an instance publishes an
+// invalidation, until ctx is cancelled.
+func (c *radarCache) listen(ctx context.Context, pubsub *redis.PubSub) {
+	defer pubsub.Close()
+	messages := pubsub.Channel()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case _, ok := <-messages:
+			if !ok {
+				return
+			}
+			c.dropLocal()
+		}
+	}
+}
+
+// dropLocal forgets the in-memory copy.
+func (c *radarCache) dropLocal() {
+	c.mu.Lock()
+	c.local = nil
+	c.mu.Unlock()
+}
+
+// load returns the cached radar data, or calls fetch and caches its result.
+// Redis errors are logged and fall back to fetch.
+func (c *radarCache) load(ctx context.Context, fetch func() (RadarData, error)) (RadarData, error) {
+	c.mu.Lock()
+	if c.local != nil && time.Now().Before(c.expires) {
+		data := *c.local
+		c.mu.Unlock()
+		return data, nil
+	}
+	c.mu.Unlock()
+
+	value, err := c.client.Get(ctx, c.key).Bytes()
+	if err == nil {
+		var data RadarData
+		if err := json.Unmarshal(value, &data); err == nil {
+			c.keepLocal(data)
+			return data, nil
+		}
+		slog.Warn("Ignoring invalid radar data in the Redis cache", "err", err)
+	} else if !errors.Is(err, redis.Nil) {
+		slog.Warn("Failed to read the Redis cache", "err", err)
+	}
+
+	data, err := fetch()
+	if err != nil {
+		return RadarData{}, err
+	}
+	if value, err := json.Marshal(data); err == nil {
+		if err := c.client.Set(ctx, c.key, value, c.ttl).Err(); err != nil {
+			slog.Warn("Failed to write the Redis cache", "err", err)
+		}
+	}
+	c.keepLocal(data)
+	return data, nil
+}
+
+// keepLocal stores data as the in-memory copy for the TTL.
+func (c *radarCache) keepLocal(data RadarData) {
+	c.mu.Lock()
+	c.local, c.expires = &data, time.Now().Add(c.ttl)
+	c.mu.Unlock()
+}
+
+// invalidate drops the cached radar data on every instance after it
+// changed.
+func (c *radarCache) invalidate(ctx context.Context) {
+	c.dropLocal()
+	if err := c.client.Del(ctx, c.key).Err(); err != nil {
+		slog.Warn("Failed to invalidate the Redis cache", "err", err)
+		return
+	}
+	if err := c.client.Publish(ctx, c.channel, "data").Err(); err != nil {
+		slog.Warn("Failed to notify other instances of new radar data", "err", err)
+	}
+}
+
+// Close stops listening for invalidations and disconnects.
+func (c *radarCache) Close() error {
+	c.cancel()
+	return c.client.Close()
+}
+
+// invalidateRadarCache drops the cached radar data, if it is cached, after
+// the data changed.
+func invalidateRadarCache(ctx context.Context) {
+	if c := activeCache.Load(); c != nil {
+		c.invalidate(ctx)
+	}
+}
@@ -0,0 +1,106 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+)
+
+// openTestCache opens a cache on server for the duration of the test.
+func openTestCache(t *testing.T, server *miniredis.Miniredis) *radarCache {
+	t.Helper()
+	cfg := defaultConfig().Cache
+	cfg.RedisURL = "redis://" + server.Addr()
+	c, err := openRadarCache(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { c.Close() })
+	return c
+}
+
+func TestRadarCacheSharedBetweenInstances(t *testing.T) {
+	server := miniredis.RunT(t)
+	ctx := context.Background()
+	first, second := openTestCache(t, server), openTestCache(t, server)
+
+	loads := 0
+	fetch := func() (RadarData, error) {
+		loads++
+		return RadarData{LastModified: "May 2024", Items: []RadarItem{{Label: "Go"}}}, nil
+	}
+	for _, c := range []*radarCache{first, first, second} {
+		data, err := c.load(ctx, fetch)
+		if err != nil || data.Items[0].Label != "Go" {
+			t.Fatalf("load() = %+v, %v", data, err)
+		}
+	}
+	if loads != 1 {
+		t.Errorf("data loaded %d times, want once", loads)
+	}
+	if ttl := server.TTL(first.key); ttl != 5*time.Minute {
+		t.Errorf("TTL = %s, want 5m", ttl)
+	}
+
+	// Invalidating on one instance drops the in-memory copy of the other.
+	first.invalidate(ctx)
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		second.mu.Lock()
+		dropped := second.local == nil
+		second.mu.Unlock()
+		if dropped {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("second instance kept its copy after invalidation")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if _, err := second.load(ctx, fetch); err != nil || loads != 2 {
+		t.Errorf("after invalidation load() err = %v, loads = %d, want 2", err, loads)
+	}
+}
+
+func TestRadarCacheFallsBackWithoutRedis(t *testing.T) {
+	server := miniredis.RunT(t)
+	c := openTestCache(t, server)
+	server.Close()
+
+	data, err := c.load(context.Background(), func() (RadarData, error) {
+		return RadarData{LastModified: "May 2024"}, nil
+	})
+	if err != nil || data.LastModified != "May 2024" {
+		t.Errorf("load() = %+v, %v", data, err)
+	}
+
+	c.dropLocal()
+	want := errors.New("broken")
+	if _, err := c.load(context.Background(), func() (RadarData, error) { return RadarData{}, want }); !errors.Is(err, want) {
+		t.Errorf("load() error = %v, want %v", err, want)
+	}
+}
+
+func TestLoadRadarDataFromCache(t *testing.T) {
+	server := miniredis.RunT(t)
+	c := openTestCache(t, server)
+	activeCache.Store(c)
+	t.Cleanup(func() { activeCache.Store(nil) })
+	cfg := defaultConfig()
+	cfg.Data.Path = writeFile(t, "radar.yaml", radarWith("Go", "Adopted"))
+	useConfig(t, cfg)
+
+	if _, err := loadRadarData(); err != nil {
+		t.Fatal(err)
+	}
+	if !server.Exists(c.key) {
+		t.Error("loadRadarData() did not cache the data in Redis")
+	}
+	invalidateRadarCache(context.Background())
+	if server.Exists(c.key) {
+		t.Error("invalidateRadarCache() kept the cached data")
+	}
+}
@@ -0,0 +1,115 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// icsDate is the layout of dates in iCalendar, RFC 5545.
+const icsDate = "20060102"
+
+// icsLineLength is the length in bytes lines of iCalendar are folded at.
+const icsLineLength = 75
+
+// icsEscaper escapes the characters of text values of iCalendar.
+var icsEscaper = strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\r\n", `\n`, "\n", `\n`)
+
+// reviewEvent is an all-day event of the review calendar.
+type reviewEvent struct {
+	uid, summary, description, url string
+	date                           time.Time
+}
+
+// writeICSLine writes a content line of iCalendar to b, folded into lines of
+// at most icsLineLength bytes without splitting characters.
+func writeICSLine(b *strings.Builder, line string) {
+	for len(line) > icsLineLength {
+		n := icsLineLength
+		for !utf8.RuneStart(line[n]) {
+			n--
+		}
+		b.WriteString(line[:n] + "\r\n ")
+		line = line[n:]
+	}
+	b.WriteString(line + "\r\n")
+}
+
+// reviewCalendar returns the iCalendar document of events, with a DTSTAMP of
+// now.
+func reviewCalendar(events []reviewEvent, now time.Time) string {
+	var b strings.Builder
+	for _, line := range []string{"BEGIN:VCALENDAR", "VERSION:2.0", "PRODID:-//Clean Tech Radar//Reviews//EN", "CALSCALE:GREGORIAN", "METHOD:PUBLISH", "X-WR-CALNAME:Tech radar reviews"} {
+		writeICSLine(&b, line)
+	}
+	for _, event := range events {
+		writeICSLine(&b, "BEGIN:VEVENT")
+		writeICSLine(&b, "UID:"+event.uid)
+		writeICSLine(&b, "DTSTAMP:"+now.UTC().Format("20060102T150405Z"))
+		writeICSLine(&b, "DTSTART;VALUE=DATE:"+event.date.Format(icsDate))
+		writeICSLine(&b, "DTEND;VALUE=DATE:"+event.date.AddDate(0, 0, 1).Format(icsDate))
+		writeICSLine(&b, "SUMMARY:"+icsEscaper.Replace(event.summary))
+		if event.description != "" {
+			writeICSLine(&b, "DESCRIPTION:"+icsEscaper.Replace(event.description))
+		}
+		writeICSLine(&b, "URL:"+event.url)
+		writeICSLine(&b, "TRANSP:TRANSPARENT")
+		writeICSLine(&b, "END:VEVENT")
+	}
+	writeICSLine(&b, "END:VCALENDAR")
+	return b.String()
+}
+
+// calendarHandler serves an iCalendar feed of the review dates of the
+// radar and of the items selectItems selects, as all-day events, so teams
+// can subscribe to the review sessions and re-assessment deadlines of the
+// items they own with ?owner= or the other filters of the API.
+func calendarHandler(w http.ResponseWriter, r *http.Request) {
+	data, err := loadRadarData()
+	if err != nil {
+		handleError(w, err)
+		return
+	}
+	data = withSegments(data)
+	items, err := selectItems(r, data)
+	if err != nil {
+		handleError(w, err)
+		return
+	}
+
+	host := (&url.URL{Host: r.Host}).Hostname()
+	site := requestOrigin(r) + currentConfig().Server.BasePath + "/"
+	var events []reviewEvent
+	// Dates were validated with the data.
+	if date, err := time.Parse(time.DateOnly, data.ReviewDate); err == nil {
+		events = append(events, reviewEvent{uid: "radar-review@" + host, summary: "Tech radar review", url: site, date: date})
+	}
+	for _, item := range items {
+		date, err := time.Parse(time.DateOnly, item.ReviewDate)
+		if err != nil {
+			continue
+		}
+		description := fmt.Sprintf("%s in %s.", item.Ring, item.Quadrant)
+		if names := item.Owners.names(); names != "" {
+			description += " Owned by " + names + "."
+		}
+		if item.Description != "" {
+			description += "\n\n" + item.Description
+		}
+		events = append(events, reviewEvent{
+			uid:         "review-" + item.ID + "@" + host,
+			summary:     fmt.Sprintf("Review %s (%s)", item.Label, item.Ring),
+			description: description,
+			url:         site + "#" + url.PathEscape(item.ID),
+			date:        date,
+		})
+	}
+	sort.SliceStable(events, func(i, j int) bool { return events[i].date.Before(events[j].date) })
+
+	w.Header().Set("Content-Type", "text/calendar; charset=utf-8")
+	w.Write([]byte(reviewCalendar(events, time.Now())))
+}
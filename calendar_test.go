@@ -0,0 +1,99 @@
+package main
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCalendar(t *testing.T) {
+	cfg := defaultConfig()
+	cfg.Data.Path = writeFile(t, "radar.yaml", `ReviewDate: "2025-06-02"
+Items:
+- Label: Go
+  Quadrant: Tools
+  Ring: Adopted
+  ReviewDate: "2025-03-31"
+  Owners:
+  - Name: Platform Team
+  Description: Fast, simple; and "boring".
+- Label: Rust
+  Quadrant: Tools
+  Ring: In Discovery
+- Label: Perl
+  Quadrant: Tools
+  Ring: Not Recommended
+  ReviewDate: "2025-01-15"
+  Owners:
+  - Name: Legacy Team
+`)
+	useConfig(t, cfg)
+	handler, err := setupRoutes(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rec := doRequest(t, handler, http.MethodGet, "http://radar.example.com/calendar.ics")
+	if rec.Code != http.StatusOK || rec.Header().Get("Content-Type") != "text/calendar; charset=utf-8" {
+		t.Fatalf("GET /calendar.ics = %d %q", rec.Code, rec.Header().Get("Content-Type"))
+	}
+	body := rec.Body.String()
+	if !strings.HasPrefix(body, "BEGIN:VCALENDAR\r\nVERSION:2.0\r\n") || !strings.HasSuffix(body, "END:VCALENDAR\r\n") {
+		t.Errorf("GET /calendar.ics = %q", body)
+	}
+	// Events are in date order, and items without a review date are left
+	// out.
+	var summaries []string
+	for _, line := range strings.Split(body, "\r\n") {
+		if summary, ok := strings.CutPrefix(line, "SUMMARY:"); ok {
+			summaries = append(summaries, summary)
+		}
+	}
+	if got, want := strings.Join(summaries, "|"), "Review Perl (Not Recommended)|Review Go (Adopted)|Tech radar review"; got != want {
+		t.Errorf("summaries = %q, want %q", got, want)
+	}
+	for _, want := range []string{
+		"UID:review-go@radar.example.com\r\n",
+		"DTSTART;VALUE=DATE:20250331\r\nDTEND;VALUE=DATE:20250401\r\n",
+		`DESCRIPTION:Adopted in Tools. Owned by Platform Team.\n\nFast\, simple\; and "boring".` + "\r\n",
+		"URL:http://radar.example.com/#go\r\n",
+		"UID:radar-review@radar.example.com\r\n",
+	} {
+		if !strings.Contains(strings.ReplaceAll(body, "\r\n ", ""), want) {
+			t.Errorf("GET /calendar.ics doesn't contain %q", want)
+		}
+	}
+
+	// Teams subscribe to the reviews of their items.
+	body = doRequest(t, handler, http.MethodGet, "/calendar.ics?owner=Legacy+Team").Body.String()
+	if strings.Contains(body, "Review Go") || !strings.Contains(body, "Review Perl") {
+		t.Errorf("GET /calendar.ics?owner=Legacy+Team = %q", body)
+	}
+	if rec := doRequest(t, handler, http.MethodGet, "/calendar.ics?ring=Hold"); rec.Code != http.StatusBadRequest {
+		t.Errorf("GET /calendar.ics?ring=Hold = %d, want 400", rec.Code)
+	}
+}
+
+func TestReviewCalendarFoldsLines(t *testing.T) {
+	summary := strings.Repeat("é", 60)
+	ics := reviewCalendar([]reviewEvent{{uid: "x", summary: summary, url: "/", date: time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)}}, time.Now())
+	for _, line := range strings.Split(ics, "\r\n") {
+		if len(line) > icsLineLength {
+			t.Errorf("line of %d bytes: %q", len(line), line)
+		}
+	}
+	if unfolded := strings.ReplaceAll(ics, "\r\n ", ""); !strings.Contains(unfolded, "SUMMARY:"+summary+"\r\n") {
+		t.Errorf("unfolded calendar = %q", unfolded)
+	}
+}
+
+func TestValidateReviewDates(t *testing.T) {
+	path := writeFile(t, "radar.yaml", "ReviewDate: next week\n"+radarWith("Go", "Adopted")+"  ReviewDate: 2025-02-30\n")
+	err := validateRadarData(path)
+	for _, want := range []string{`ReviewDate: invalid date "next week"`, `item "Go".ReviewDate: invalid date "2025-02-30"`} {
+		if err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("validateRadarData() = %v, want %s", err, want)
+		}
+	}
+}
@@ -0,0 +1,264 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"reflect"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Change feed event kinds.
+const (
+	changeCreated    = "created"
+	changeUpdated    = "updated"
+	changeMoved      = "moved"
+	changeArchived   = "archived"
+	changeUnarchived = "unarchived"
+	changeRemoved    = "removed"
+)
+
+// ItemChange is an event of the change feed: an item created, updated,
+// moved to another ring, archived, unarchived or removed by a commit of the
+// data files or a save to the store. Item is the item after the change,
+// which downstream systems can store as is, and is left out of removals.
+// Actor is who made it: the author of the commit, or the actor of the
+// audit event of the save.
+type ItemChange struct {
+	Event        string     `json:"event"`
+	ID           string     `json:"id"`
+	Label        string     `json:"label"`
+	Time         time.Time  `json:"time"`
+	Actor        string     `json:"actor,omitempty"`
+	Commit       string     `json:"commit,omitempty"`
+	Snapshot     int64      `json:"snapshot,omitempty"`
+	PreviousRing string     `json:"previousRing,omitempty"`
+	Item         *RadarItem `json:"item,omitempty"`
+}
+
+// changesCache holds the change feed computed for a data path at a commit,
+// like historyCache.
+var changesCache struct {
+	sync.Mutex
+	key     string
+	changes []ItemChange
+}
+
+// itemChanges returns the changes from the items of previous to those of
+// current, keyed by ID, as events like at, ordered by label. An item that
+// changed in several ways gets the one event that says the most: archived
+// or unarchived, then moved, then updated.
+func itemChanges(previous, current map[string]RadarItem, at ItemChange) []ItemChange {
+	var changes []ItemChange
+	record := func(event string, item RadarItem, before *RadarItem) {
+		change := at
+		change.Event, change.ID, change.Label = event, item.ID, item.Label
+		if event == changeMoved {
+			change.PreviousRing = before.Ring
+		}
+		if event != changeRemoved {
+			change.Item = &item
+		}
+		changes = append(changes, change)
+	}
+	for id, item := range current {
+		item.Source = ""
+		before, existed := previous[id]
+		before.Source = ""
+		switch {
+		case !existed:
+			record(changeCreated, item, nil)
+		case before.Archived != item.Archived && item.Archived:
+			record(changeArchived, item, &before)
+		case before.Archived != item.Archived:
+			record(changeUnarchived, item, &before)
+		case before.Ring != item.Ring:
+			record(changeMoved, item, &before)
+		case !reflect.DeepEqual(before, item):
+			record(changeUpdated, item, &before)
+		}
+	}
+	for id, item := range previous {
+		if _, ok := current[id]; !ok {
+			record(changeRemoved, item, nil)
+		}
+	}
+	sort.Slice(changes, func(i, j int) bool {
+		if a, b := labelKey(changes[i].Label), labelKey(changes[j].Label); a != b {
+			return a < b
+		}
+		return changes[i].ID < changes[j].ID
+	})
+	return changes
+}
+
+// itemsByID returns the items of data, keyed by ID.
+func itemsByID(data RadarData) (map[string]RadarItem, error) {
+	data, err := withItemIDs(data, nil)
+	if err != nil {
+		return nil, err
+	}
+	items := make(map[string]RadarItem, len(data.Items))
+	for _, item := range data.Items {
+		items[item.ID] = item
+	}
+	return items, nil
+}
+
+// loadRadarChanges returns every change to the radar, oldest first: those
+// saved to the store, or else those committed to the data files, or
+// errNoHistory if they are not tracked by Git.
+func loadRadarChanges(ctx context.Context) ([]ItemChange, error) {
+	if s, ok := currentStore().(*databaseStore); ok {
+		return storeChanges(ctx, s.db)
+	}
+	top, head, files, err := gitDataRepo(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	changesCache.Lock()
+	defer changesCache.Unlock()
+	key := head + "\x00" + currentConfig().Data.dataPath()
+	if changesCache.key == key {
+		return changesCache.changes, nil
+	}
+	var changes []ItemChange
+	previous := make(map[string]RadarItem)
+	err = walkRadarCommits(ctx, top, files, func(commit, author string, date time.Time, current map[string]RadarItem) {
+		changes = append(changes, itemChanges(previous, current, ItemChange{Time: date, Actor: author, Commit: shortCommit(commit)})...)
+		previous = current
+	})
+	if err != nil {
+		return nil, err
+	}
+	changesCache.key, changesCache.changes = key, changes
+	return changes, nil
+}
+
+// itemChangesByID returns the changes of loadRadarChanges by item ID, none
+// if they are not tracked.
+func itemChangesByID(ctx context.Context) (map[string][]ItemChange, error) {
+	changes, err := loadRadarChanges(ctx)
+	if err != nil && !errors.Is(err, errNoHistory) {
+		return nil, err
+	}
+	byID := make(map[string][]ItemChange)
+	for _, change := range changes {
+		byID[change.ID] = append(byID[change.ID], change)
+	}
+	return byID, nil
+}
+
+// storeChanges returns the changes between the snapshots of db, oldest
+// first, made by the actors of their audit events.
+func storeChanges(ctx context.Context, db Database) ([]ItemChange, error) {
+	snapshots, err := db.Snapshots(ctx, math.MaxInt32)
+	if err != nil {
+		return nil, err
+	}
+	events, err := db.AuditEvents(ctx, math.MaxInt32)
+	if err != nil {
+		return nil, err
+	}
+	actors := make(map[int64]string, len(events))
+	for _, event := range events {
+		actors[event.SnapshotID] = event.Actor
+	}
+	var changes []ItemChange
+	previous := make(map[string]RadarItem)
+	for i := len(snapshots) - 1; i >= 0; i-- {
+		current, err := itemsByID(snapshots[i].Data)
+		if err != nil {
+			return nil, fmt.Errorf("snapshot %d: %w", snapshots[i].ID, err)
+		}
+		changes = append(changes, itemChanges(previous, current, ItemChange{Time: snapshots[i].Time, Actor: actors[snapshots[i].ID], Snapshot: snapshots[i].ID})...)
+		previous = current
+	}
+	return changes, nil
+}
+
+// changesHandler serves the changes to the radar made since ?since=, an
+// RFC 3339 time, or all of them without it, oldest first. Changes made at
+// since itself are included, so clients passing the time of the latest
+// change they have seen get it again rather than miss those made in the
+// same second.
+func changesHandler(w http.ResponseWriter, r *http.Request) {
+	var since time.Time
+	if value := r.URL.Query().Get("since"); value != "" {
+		var err error
+		if since, err = time.Parse(time.RFC3339, value); err != nil {
+			handleError(w, &AppError{Code: http.StatusBadRequest, Message: fmt.Sprintf("Invalid since %q, must be a time such as 2024-01-01T00:00:00Z", value)})
+			return
+		}
+	}
+	all, err := loadRadarChanges(r.Context())
+	if errors.Is(err, errNoHistory) {
+		handleError(w, &AppError{Code: http.StatusNotFound, Message: "The change feed requires a store or the data files to be in a Git repository"})
+		return
+	}
+	if err != nil {
+		handleError(w, &AppError{Code: http.StatusInternalServerError, Message: "Failed to read radar changes", Err: err})
+		return
+	}
+
+	changes := []ItemChange{}
+	for _, change := range all {
+		if !change.Time.Before(since) {
+			changes = append(changes, change)
+		}
+	}
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(map[string][]ItemChange{"changes": changes}); err != nil {
+		handleError(w, &AppError{Code: http.StatusInternalServerError, Message: "Failed to encode response", Err: err})
+	}
+}
+
+// ItemChanges lists the changes to one item, oldest first.
+type ItemChanges struct {
+	ID      string       `json:"id"`
+	Label   string       `json:"label"`
+	Changes []ItemChange `json:"changes"`
+}
+
+// itemHistoryHandler serves the changes to the item with the ID given by
+// the path, from the snapshots of the store or the Git history of the data
+// files, with who made them. Items removed since are still found by their
+// changes.
+func itemHistoryHandler(w http.ResponseWriter, r *http.Request) {
+	all, err := loadRadarChanges(r.Context())
+	if errors.Is(err, errNoHistory) {
+		handleError(w, &AppError{Code: http.StatusNotFound, Message: "Item history requires a store or the data files to be in a Git repository"})
+		return
+	}
+	if err != nil {
+		handleError(w, &AppError{Code: http.StatusInternalServerError, Message: "Failed to read radar changes", Err: err})
+		return
+	}
+
+	history := ItemChanges{ID: r.PathValue("id"), Changes: []ItemChange{}}
+	for _, change := range all {
+		if change.ID == history.ID {
+			history.Label = change.Label
+			history.Changes = append(history.Changes, change)
+		}
+	}
+	if data, err := loadRadarData(); err == nil {
+		if item, ok := findItem(data.Items, history.ID); ok {
+			history.Label = item.Label
+		}
+	}
+	if history.Label == "" {
+		handleError(w, &AppError{Code: http.StatusNotFound, Message: "Unknown item"})
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(history); err != nil {
+		handleError(w, &AppError{Code: http.StatusInternalServerError, Message: "Failed to encode response", Err: err})
+	}
+}
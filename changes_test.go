@@ -0,0 +1,175 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// changeEvents summarizes changes as "event:id" strings.
+func changeEvents(changes []ItemChange) []string {
+	events := make([]string, len(changes))
+	for i, c := range changes {
+		events[i] = c.Event + ":" + c.ID
+	}
+	return events
+}
+
+// getChanges requests target from handler and returns the changes served.
+func getChanges(t *testing.T, handler http.Handler, target string) []ItemChange {
+	t.Helper()
+	rec := doRequest(t, handler, http.MethodGet, target)
+	var body struct{ Changes []ItemChange }
+	if err := json.Unmarshal(rec.Body.Bytes(), &body); rec.Code != http.StatusOK || err != nil || body.Changes == nil {
+		t.Fatalf("GET %s = %d %q", target, rec.Code, rec.Body.String())
+	}
+	return body.Changes
+}
+
+func TestItemChanges(t *testing.T) {
+	previous := map[string]RadarItem{
+		"go":    {ID: "go", Label: "Go", Ring: "In Discovery"},
+		"rust":  {ID: "rust", Label: "Rust", Ring: "Adopted", Source: "a.yaml"},
+		"perl":  {ID: "perl", Label: "Perl", Ring: "Adopted"},
+		"zig":   {ID: "zig", Label: "Zig", Ring: "In Discovery"},
+		"cobol": {ID: "cobol", Label: "COBOL", Ring: "Not Recommended", Archived: true},
+		"java":  {ID: "java", Label: "Java", Ring: "Adopted"},
+	}
+	current := map[string]RadarItem{
+		"go":     {ID: "go", Label: "Go", Ring: "Adopted"},
+		"rust":   {ID: "rust", Label: "Rust", Ring: "Adopted", Source: "b.yaml"},
+		"perl":   {ID: "perl", Label: "Perl", Ring: "Not Recommended", Archived: true},
+		"cobol":  {ID: "cobol", Label: "COBOL", Ring: "Not Recommended"},
+		"java":   {ID: "java", Label: "Java", Ring: "Adopted", Description: "Still here."},
+		"kotlin": {ID: "kotlin", Label: "Kotlin", Ring: "In Discovery"},
+	}
+	at := ItemChange{Time: time.Unix(1700000000, 0), Commit: "abc1234"}
+	changes := itemChanges(previous, current, at)
+	// Moving a file doesn't change its items, and archiving says more than
+	// moving.
+	want := []string{"unarchived:cobol", "moved:go", "updated:java", "created:kotlin", "archived:perl", "removed:zig"}
+	if got := changeEvents(changes); !reflect.DeepEqual(got, want) {
+		t.Fatalf("itemChanges = %v, want %v", got, want)
+	}
+	if moved := changes[1]; moved.PreviousRing != "In Discovery" || moved.Item.Ring != "Adopted" || moved.Commit != "abc1234" || !moved.Time.Equal(at.Time) {
+		t.Errorf("moved change = %+v", moved)
+	}
+	if removed := changes[5]; removed.Item != nil || removed.Label != "Zig" {
+		t.Errorf("removed change = %+v, want Zig without the item", removed)
+	}
+}
+
+func TestGitChanges(t *testing.T) {
+	repo, commit := gitRadarRepo(t)
+	commit(radarWith("Go", "In Discovery"))
+	commit(radarWith("Go", "Adopted") + "- Label: Rust\n  Quadrant: Tools\n  Ring: In Discovery\n")
+	commit(radarWith("Rust", "In Discovery"))
+
+	cfg := defaultConfig()
+	cfg.Data.Path = filepath.Join(repo, "data", "radar.yaml")
+	useConfig(t, cfg)
+	handler, err := setupRoutes(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	changes := getChanges(t, handler, "/api/v1/changes")
+	if got, want := changeEvents(changes), []string{"created:go", "moved:go", "created:rust", "removed:go"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("GET /api/v1/changes = %v, want %v", got, want)
+	}
+	if len(changes[0].Commit) != 7 || changes[0].Time.IsZero() || changes[0].Item.Quadrant != "Tools" || changes[0].Actor != "Radar" {
+		t.Errorf("created change = %+v", changes[0])
+	}
+
+	// Changes at the time asked for are included.
+	since := changes[len(changes)-1].Time
+	if got := getChanges(t, handler, "/api/v1/changes?since="+since.Format(time.RFC3339)); len(got) == 0 || got[len(got)-1].Event != changeRemoved {
+		t.Errorf("changes since the latest = %v, want it included", changeEvents(got))
+	}
+	if got := getChanges(t, handler, "/api/v1/changes?since="+since.Add(time.Second).Format(time.RFC3339)); len(got) != 0 {
+		t.Errorf("changes since after the latest = %v, want none", changeEvents(got))
+	}
+	if rec := doRequest(t, handler, http.MethodGet, "/api/v1/changes?since=yesterday"); rec.Code != http.StatusBadRequest {
+		t.Errorf("GET /api/v1/changes?since=yesterday = %d, want 400", rec.Code)
+	}
+}
+
+func TestGitChangesOutsideGit(t *testing.T) {
+	cfg := defaultConfig()
+	cfg.Data.Path = writeFile(t, "radar.yaml", radarWith("Go", "Adopted"))
+	useConfig(t, cfg)
+	if rec := doRequest(t, http.HandlerFunc(changesHandler), http.MethodGet, "/api/changes"); rec.Code != http.StatusNotFound {
+		t.Errorf("GET /api/changes outside Git = %d, want 404", rec.Code)
+	}
+}
+
+func TestStoreChanges(t *testing.T) {
+	db := openTestStore(t, "sqlite", filepath.Join(t.TempDir(), "radar.db"))
+	store := &databaseStore{db: db}
+	useStore(t, store)
+	ctx := context.Background()
+	for _, items := range [][]RadarItem{
+		{{Label: "Go", Quadrant: "Tools", Ring: "Adopted"}},
+		{{Label: "Go", Quadrant: "Tools", Ring: "Adopted", Archived: true}, {Label: "Rust", Quadrant: "Tools", Ring: "In Discovery"}},
+	} {
+		if err := store.Save(ctx, RadarData{Items: items}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	changes := getChanges(t, http.HandlerFunc(changesHandler), "/api/changes")
+	if got, want := changeEvents(changes), []string{"created:go", "archived:go", "created:rust"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("GET /api/changes = %v, want %v", got, want)
+	}
+	if changes[0].Snapshot == 0 || changes[1].Snapshot <= changes[0].Snapshot || changes[1].Commit != "" {
+		t.Errorf("changes = %+v, want them by snapshot", changes)
+	}
+}
+
+func TestItemHistory(t *testing.T) {
+	db := openTestStore(t, "sqlite", filepath.Join(t.TempDir(), "radar.db"))
+	store := &databaseStore{db: db}
+	cfg := defaultConfig()
+	useConfig(t, cfg)
+	useStore(t, store)
+	handler, err := setupRoutes(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, items := range [][]RadarItem{
+		{{Label: "Go", Quadrant: "Tools", Ring: "In Discovery"}, {Label: "Zig", Quadrant: "Tools", Ring: "In Discovery"}},
+		{{Label: "Go", Quadrant: "Tools", Ring: "Adopted"}, {Label: "Zig", Quadrant: "Tools", Ring: "In Discovery"}},
+		{{Label: "Go", Quadrant: "Tools", Ring: "Adopted", Description: "Fast."}},
+	} {
+		ctx := withAuditEvent(context.Background(), AuditEvent{Actor: []string{"admin", "Jane", "Joe"}[i], Action: "save"})
+		if err := store.Save(ctx, RadarData{Items: items}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	rec := doRequest(t, handler, http.MethodGet, "/api/v1/items/go/history")
+	var history ItemChanges
+	if err := json.Unmarshal(rec.Body.Bytes(), &history); err != nil || rec.Code != http.StatusOK {
+		t.Fatalf("GET /api/v1/items/go/history = %d %s", rec.Code, rec.Body)
+	}
+	if got, want := changeEvents(history.Changes), []string{"created:go", "moved:go", "updated:go"}; !reflect.DeepEqual(got, want) || history.Label != "Go" {
+		t.Fatalf("history = %+v, want %v", history, want)
+	}
+	for i, actor := range []string{"admin", "Jane", "Joe"} {
+		if c := history.Changes[i]; c.Actor != actor || c.Snapshot == 0 || c.Time.IsZero() {
+			t.Errorf("change %d = %+v, want it made by %s", i, c, actor)
+		}
+	}
+	// Removed items keep their history.
+	rec = doRequest(t, handler, http.MethodGet, "/api/v1/items/zig/history")
+	if err := json.Unmarshal(rec.Body.Bytes(), &history); err != nil || rec.Code != http.StatusOK || !reflect.DeepEqual(changeEvents(history.Changes), []string{"created:zig", "removed:zig"}) {
+		t.Errorf("GET /api/v1/items/zig/history = %d %s", rec.Code, rec.Body)
+	}
+	if rec := doRequest(t, handler, http.MethodGet, "/api/v1/items/rust/history"); rec.Code != http.StatusNotFound {
+		t.Errorf("history of an unknown item = %d, want 404", rec.Code)
+	}
+}
@@ -0,0 +1,313 @@
+package main
+
+import (
+	"bytes"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+)
+
+// maxCommentLength is the most characters the body of a comment may have.
+const maxCommentLength = 10000
+
+// commentMu serializes changes to comments, so that a reply isn't saved to
+// a comment deleted at the same time.
+var commentMu sync.Mutex
+
+// Comment is a comment on a radar item, such as on why it is on hold, or a
+// reply to another comment on it.
+type Comment struct {
+	ID     int64  `json:"id"`
+	ItemID string `json:"itemId"`
+	// ReplyTo is the ID of the comment it replies to, if any.
+	ReplyTo int64  `json:"replyTo,omitempty"`
+	Author  string `json:"author"`
+	// Body is Markdown, rendered by the clients showing it. It is empty once
+	// the comment is deleted.
+	Body    string    `json:"body"`
+	Created time.Time `json:"created"`
+	// Deleted is when the comment was deleted, and DeletedBy who by: the
+	// author or a reviewer. Deleted comments are kept so that their replies
+	// still have a thread.
+	Deleted   *time.Time `json:"deleted,omitempty"`
+	DeletedBy string     `json:"deletedBy,omitempty"`
+	// Reviewed is when a reviewer approved the comment, and ReviewedBy who.
+	// Comments are shown as soon as they are posted, but stay in the
+	// moderation queue until a reviewer approves or deletes them.
+	Reviewed   *time.Time `json:"reviewed,omitempty"`
+	ReviewedBy string     `json:"reviewedBy,omitempty"`
+	// TokenHash is the SHA-256 hash of the token that deletes the comment,
+	// kept by the Database but never shown.
+	TokenHash string `json:"-"`
+}
+
+// CommentInput is the body of a comment posted.
+type CommentInput struct {
+	Author  string `json:"author"`
+	Body    string `json:"body"`
+	ReplyTo int64  `json:"replyTo,omitempty"`
+}
+
+// CommentReceipt is the response of a posted comment: the comment and the
+// token that deletes it, which is shown only once.
+type CommentReceipt struct {
+	Comment
+	Token string `json:"token"`
+}
+
+// shown returns c as it is shown, without the body once it is deleted.
+func (c Comment) shown() Comment {
+	if c.Deleted != nil {
+		c.Body = ""
+	}
+	return c
+}
+
+// commentDB returns the Database keeping comments, see proposalDB.
+func commentDB() (Database, error) {
+	if s, ok := currentStore().(*databaseStore); ok {
+		return s.db, nil
+	}
+	return nil, &AppError{Code: http.StatusConflict, Message: "Comments require a database store, see store.driver"}
+}
+
+// pathItemID returns the ID of the item given by the path of r, and an
+// error if there is none.
+func pathItemID(r *http.Request) (string, error) {
+	data, err := loadRadarData()
+	if err != nil {
+		return "", err
+	}
+	item, ok := findItem(data.Items, r.PathValue("id"))
+	if !ok {
+		return "", &AppError{Code: http.StatusNotFound, Message: "Unknown item"}
+	}
+	return item.ID, nil
+}
+
+// itemComments returns the database keeping comments, the ID of the item
+// given by the path of r and the comments on it, oldest first.
+func itemComments(r *http.Request) (Database, string, []Comment, error) {
+	db, err := commentDB()
+	if err != nil {
+		return nil, "", nil, err
+	}
+	itemID, err := pathItemID(r)
+	if err != nil {
+		return nil, "", nil, err
+	}
+	comments, err := db.Comments(r.Context(), itemID)
+	if err != nil {
+		return nil, "", nil, &AppError{Code: http.StatusInternalServerError, Message: "Failed to read comments", Err: err}
+	}
+	return db, itemID, comments, nil
+}
+
+// findComment returns the index in comments of the comment with the ID
+// given by the path of r.
+func findComment(comments []Comment, r *http.Request) (int, error) {
+	id, err := strconv.ParseInt(r.PathValue("comment"), 10, 64)
+	i := slices.IndexFunc(comments, func(c Comment) bool { return c.ID == id })
+	if err != nil || i < 0 {
+		return 0, &AppError{Code: http.StatusNotFound, Message: "Unknown comment"}
+	}
+	return i, nil
+}
+
+// decodeComment decodes the CommentInput in the body of r into a comment
+// on the item with the ID itemID, replying to one of comments if any.
+func decodeComment(w http.ResponseWriter, r *http.Request, itemID string, comments []Comment) (Comment, error) {
+	body, err := readItemBody(w, r)
+	if err != nil {
+		return Comment{}, err
+	}
+	var input CommentInput
+	dec := json.NewDecoder(bytes.NewReader(body))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&input); err != nil {
+		return Comment{}, &AppError{Code: http.StatusBadRequest, Message: "Invalid comment: " + err.Error(), Err: err}
+	}
+	if _, err := dec.Token(); !errors.Is(err, io.EOF) {
+		return Comment{}, &AppError{Code: http.StatusBadRequest, Message: "Invalid comment: more than one JSON value"}
+	}
+	c := Comment{ItemID: itemID, ReplyTo: input.ReplyTo, Author: strings.TrimSpace(input.Author), Body: strings.TrimSpace(input.Body)}
+	switch {
+	case c.Author == "":
+		return Comment{}, &AppError{Code: http.StatusBadRequest, Message: "A comment requires an author"}
+	case c.Body == "":
+		return Comment{}, &AppError{Code: http.StatusBadRequest, Message: "A comment requires a body"}
+	case utf8.RuneCountInString(c.Body) > maxCommentLength:
+		return Comment{}, &AppError{Code: http.StatusBadRequest, Message: fmt.Sprintf("A comment may have at most %d characters", maxCommentLength)}
+	case c.ReplyTo != 0 && !slices.ContainsFunc(comments, func(other Comment) bool { return other.ID == c.ReplyTo }):
+		return Comment{}, &AppError{Code: http.StatusBadRequest, Message: fmt.Sprintf("Unknown comment %d on item %s to reply to", c.ReplyTo, itemID)}
+	}
+	return c, nil
+}
+
+// commentDeleter returns who deletes c with r: "author" if r carries, as an
+// "Authorization: Bearer" header, the token of c, or the name of the
+// reviewer signed in, see reviewerOf. Anyone else gets an error.
+func commentDeleter(w http.ResponseWriter, r *http.Request, c Comment) (string, error) {
+	if token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer "); ok && subtle.ConstantTimeCompare([]byte(tokenHash(token)), []byte(c.TokenHash)) == 1 {
+		return "author", nil
+	}
+	if reviewer, ok := reviewerOf(r); ok {
+		return reviewer.Name, nil
+	}
+	w.Header().Set("WWW-Authenticate", `Bearer realm="clean-tech-radar"`)
+	return "", &AppError{Code: http.StatusUnauthorized, Message: "Deleting a comment requires its token or a reviewer token"}
+}
+
+// writeComment responds with v, a comment or comments, and status code.
+func writeComment(w http.ResponseWriter, code int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		log.Printf("Failed to encode comment: %v", err)
+	}
+}
+
+// listCommentsHandler lists the comments on the item with the ID given by
+// the path, oldest first, with deleted ones still in their place.
+func listCommentsHandler(w http.ResponseWriter, r *http.Request) {
+	_, _, comments, err := itemComments(r)
+	if err != nil {
+		handleError(w, err)
+		return
+	}
+	shown := []Comment{}
+	for _, c := range comments {
+		shown = append(shown, c.shown())
+	}
+	writeComment(w, http.StatusOK, struct {
+		Comments []Comment `json:"comments"`
+	}{shown})
+}
+
+// commentHandler responds with the comment with the ID given by the path.
+func commentHandler(w http.ResponseWriter, r *http.Request) {
+	_, _, comments, err := itemComments(r)
+	if err != nil {
+		handleError(w, err)
+		return
+	}
+	i, err := findComment(comments, r)
+	if err != nil {
+		handleError(w, err)
+		return
+	}
+	writeComment(w, http.StatusOK, comments[i].shown())
+}
+
+// createCommentHandler posts the comment in the body on the item with the
+// ID given by the path, which anyone may, and responds with its
+// CommentReceipt and URL as the Location.
+func createCommentHandler(w http.ResponseWriter, r *http.Request) {
+	commentMu.Lock()
+	defer commentMu.Unlock()
+	db, itemID, comments, err := itemComments(r)
+	if err != nil {
+		handleError(w, err)
+		return
+	}
+	c, err := decodeComment(w, r, itemID, comments)
+	if err != nil {
+		handleError(w, err)
+		return
+	}
+	token, hash := proposalToken()
+	c.TokenHash, c.Created = hash, time.Now().UTC().Truncate(time.Second)
+	if c, err = db.SaveComment(r.Context(), c); err != nil {
+		handleError(w, &AppError{Code: http.StatusInternalServerError, Message: "Failed to save comment", Err: err})
+		return
+	}
+	log.Printf("Comment %d on item %s posted by %s from %s", c.ID, c.ItemID, c.Author, r.RemoteAddr)
+	w.Header().Set("Location", apiURL(fmt.Sprintf("/items/%s/comments/%d", c.ItemID, c.ID)))
+	writeComment(w, http.StatusCreated, CommentReceipt{Comment: c, Token: token})
+}
+
+// deleteCommentHandler deletes the comment with the ID given by the path,
+// with its token or a reviewer token. The comment is kept, without its body,
+// so that the thread it is in stays whole.
+func deleteCommentHandler(w http.ResponseWriter, r *http.Request) {
+	commentMu.Lock()
+	defer commentMu.Unlock()
+	db, _, comments, err := itemComments(r)
+	if err != nil {
+		handleError(w, err)
+		return
+	}
+	i, err := findComment(comments, r)
+	if err != nil {
+		handleError(w, err)
+		return
+	}
+	c := comments[i]
+	by, err := commentDeleter(w, r, c)
+	if err != nil {
+		handleError(w, err)
+		return
+	}
+	if c.Deleted != nil {
+		handleError(w, &AppError{Code: http.StatusConflict, Message: fmt.Sprintf("Comment %d is deleted", c.ID)})
+		return
+	}
+	now := time.Now().UTC().Truncate(time.Second)
+	c.Deleted, c.DeletedBy = &now, by
+	if _, err := db.SaveComment(r.Context(), c); err != nil {
+		handleError(w, &AppError{Code: http.StatusInternalServerError, Message: "Failed to save comment", Err: err})
+		return
+	}
+	log.Printf("Comment %d on item %s deleted by %s from %s", c.ID, c.ItemID, by, r.RemoteAddr)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// approveCommentHandler approves the comment with the ID given by the path,
+// with a reviewer token, taking it out of the moderation queue, and
+// responds with it.
+func approveCommentHandler(w http.ResponseWriter, r *http.Request) {
+	reviewer, err := signedInReviewer(w, r)
+	if err != nil {
+		handleError(w, err)
+		return
+	}
+	commentMu.Lock()
+	defer commentMu.Unlock()
+	db, _, comments, err := itemComments(r)
+	if err != nil {
+		handleError(w, err)
+		return
+	}
+	i, err := findComment(comments, r)
+	if err != nil {
+		handleError(w, err)
+		return
+	}
+	c := comments[i]
+	switch {
+	case c.Deleted != nil:
+		handleError(w, &AppError{Code: http.StatusConflict, Message: fmt.Sprintf("Comment %d is deleted", c.ID)})
+		return
+	case c.Reviewed != nil:
+		handleError(w, &AppError{Code: http.StatusConflict, Message: fmt.Sprintf("Comment %d is approved", c.ID)})
+		return
+	}
+	now := time.Now().UTC().Truncate(time.Second)
+	c.Reviewed, c.ReviewedBy = &now, reviewer.Name
+	if c, err = db.SaveComment(r.Context(), c); err != nil {
+		handleError(w, &AppError{Code: http.StatusInternalServerError, Message: "Failed to save comment", Err: err})
+		return
+	}
+	log.Printf("Comment %d on item %s approved by %s", c.ID, c.ItemID, reviewer.Name)
+	writeComment(w, http.StatusOK, c)
+}
@@ -0,0 +1,166 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCommentStores(t *testing.T) {
+	for _, driver := range []string{"sqlite", "bbolt"} {
+		t.Run(driver, func(t *testing.T) {
+			db := openTestStore(t, driver, filepath.Join(t.TempDir(), "radar.db"))
+			ctx := context.Background()
+			created := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+			first, err := db.SaveComment(ctx, Comment{ItemID: "go", Author: "Jane", Body: "Why *hold*?", Created: created, TokenHash: "abc"})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if _, err := db.SaveComment(ctx, Comment{ItemID: "rust", Author: "Joe", Body: "Yes", Created: created}); err != nil {
+				t.Fatal(err)
+			}
+			reply, err := db.SaveComment(ctx, Comment{ItemID: "go", ReplyTo: first.ID, Author: "Joe", Body: "Licensing.", Created: created})
+			if err != nil {
+				t.Fatal(err)
+			}
+			first.Deleted, first.DeletedBy = &created, "author"
+			if _, err := db.SaveComment(ctx, first); err != nil {
+				t.Fatal(err)
+			}
+			if _, err := db.SaveComment(ctx, Comment{ID: 99, ItemID: "go"}); err == nil {
+				t.Error("saving an unknown comment succeeded")
+			}
+
+			comments, err := db.Comments(ctx, "go")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(comments) != 2 || comments[0].ID != first.ID || comments[0].Deleted == nil || comments[0].TokenHash != "abc" || comments[0].Body != "Why *hold*?" {
+				t.Fatalf("comments = %+v", comments)
+			}
+			if comments[1].ID != reply.ID || comments[1].ReplyTo != first.ID || !comments[1].Created.Equal(created) {
+				t.Errorf("reply = %+v", comments[1])
+			}
+			if all, err := db.Comments(ctx, ""); err != nil || len(all) != 3 || all[1].ItemID != "rust" {
+				t.Errorf("Comments(\"\") = %+v, %v, want the comments on every item", all, err)
+			}
+		})
+	}
+}
+
+func TestComments(t *testing.T) {
+	db := openTestStore(t, "sqlite", "file::memory:")
+	seed := RadarData{Items: []RadarItem{{ID: "go", Label: "Go", Quadrant: "Tools", Ring: "Hold"}}}
+	if err := db.Save(context.Background(), seed, AuditEvent{Actor: "test", Action: "seed"}); err != nil {
+		t.Fatal(err)
+	}
+	cfg := defaultConfig()
+	cfg.Admin.Token = "s3cret"
+	cfg.Review.Reviewers = []ReviewerConfig{{Name: "Jane", Token: "jane-token"}}
+	useConfig(t, cfg)
+	useStore(t, &databaseStore{db: db})
+	handler, err := setupRoutes(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	send := func(method, target, token, body string) *httptest.ResponseRecorder {
+		t.Helper()
+		req := httptest.NewRequest(method, target, strings.NewReader(body))
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		return rec
+	}
+	post := func(body string) CommentReceipt {
+		t.Helper()
+		rec := send(http.MethodPost, "/api/v1/items/go/comments", "", body)
+		var receipt CommentReceipt
+		if err := json.Unmarshal(rec.Body.Bytes(), &receipt); err != nil || rec.Code != http.StatusCreated {
+			t.Fatalf("POST /api/v1/items/go/comments = %d %s", rec.Code, rec.Body)
+		}
+		return receipt
+	}
+
+	first := post(`{"author": "Joe", "body": "Why is this on **hold**?"}`)
+	if first.Token == "" || first.ItemID != "go" || first.Created.IsZero() {
+		t.Errorf("receipt = %+v", first)
+	}
+	reply := post(`{"author": "Ann", "body": "Its licence.", "replyTo": ` + strconv.FormatInt(first.ID, 10) + `}`)
+	third := post(`{"author": "Bob", "body": "Off topic."}`)
+
+	for body, want := range map[string]int{
+		`{"author": "Joe"}`: http.StatusBadRequest,
+		`{"author": "Joe", "body": "` + strings.Repeat("x", maxCommentLength+1) + `"}`: http.StatusBadRequest,
+		`{"author": "Joe", "body": "Hi", "replyTo": 99}`:                               http.StatusBadRequest,
+		`{"author": "Joe", "body": "Hi", "mood": "happy"}`:                             http.StatusBadRequest,
+	} {
+		if rec := send(http.MethodPost, "/api/v1/items/go/comments", "", body); rec.Code != want {
+			t.Errorf("POST %.40s = %d, want %d", body, rec.Code, want)
+		}
+	}
+	if rec := send(http.MethodPost, "/api/v1/items/rust/comments", "", `{"author": "Joe", "body": "Hi"}`); rec.Code != http.StatusNotFound {
+		t.Errorf("comment on an unknown item = %d", rec.Code)
+	}
+
+	// Comments are deleted by their author or a reviewer, and nobody else.
+	firstURL := "/api/v1/items/go/comments/" + strconv.FormatInt(first.ID, 10)
+	if rec := send(http.MethodDelete, firstURL, reply.Token, ""); rec.Code != http.StatusUnauthorized {
+		t.Errorf("DELETE with another comment's token = %d", rec.Code)
+	}
+	if rec := send(http.MethodDelete, firstURL, first.Token, ""); rec.Code != http.StatusNoContent {
+		t.Errorf("DELETE by the author = %d %s", rec.Code, rec.Body)
+	}
+	if rec := send(http.MethodDelete, firstURL, first.Token, ""); rec.Code != http.StatusConflict {
+		t.Errorf("DELETE of a deleted comment = %d", rec.Code)
+	}
+	if rec := send(http.MethodDelete, "/api/v1/items/go/comments/"+strconv.FormatInt(third.ID, 10), "jane-token", ""); rec.Code != http.StatusNoContent {
+		t.Errorf("DELETE by a reviewer = %d %s", rec.Code, rec.Body)
+	}
+
+	rec := send(http.MethodGet, "/api/v1/items/go/comments", "", "")
+	var list struct {
+		Comments []Comment `json:"comments"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &list); err != nil || rec.Code != http.StatusOK {
+		t.Fatalf("GET /api/v1/items/go/comments = %d %s", rec.Code, rec.Body)
+	}
+	if len(list.Comments) != 3 {
+		t.Fatalf("comments = %+v", list.Comments)
+	}
+	if c := list.Comments[0]; c.Body != "" || c.Deleted == nil || c.DeletedBy != "author" {
+		t.Errorf("deleted comment = %+v", c)
+	}
+	if c := list.Comments[1]; c.Body != "Its licence." || c.ReplyTo != first.ID || c.Author != "Ann" {
+		t.Errorf("reply = %+v", c)
+	}
+	if c := list.Comments[2]; c.DeletedBy != "Jane" {
+		t.Errorf("comment deleted by a reviewer = %+v", c)
+	}
+	if strings.Contains(rec.Body.String(), "token") {
+		t.Errorf("comments show their token: %s", rec.Body)
+	}
+	if rec := send(http.MethodGet, "/api/v1/items/go/comments/"+strconv.FormatInt(reply.ID, 10), "", ""); rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), "Its licence.") {
+		t.Errorf("GET reply = %d %s", rec.Code, rec.Body)
+	}
+}
+
+func TestCommentsRequireDatabase(t *testing.T) {
+	cfg := defaultConfig()
+	useConfig(t, cfg)
+	useStore(t, newFileStore(writeFile(t, "radar.yaml", radarWith("Go", "Hold"))))
+	handler, err := setupRoutes(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rec := doRequest(t, handler, http.MethodGet, "/api/v1/items/go/comments"); rec.Code != http.StatusConflict {
+		t.Errorf("GET /api/v1/items/go/comments = %d, want 409", rec.Code)
+	}
+}
@@ -0,0 +1,207 @@
+package main
+
+import (
+	"compress/gzip"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/andybalholm/brotli"
+)
+
+// minCompressSize is the smallest response worth compressing, when its
+// length is known up front.
+const minCompressSize = 1024
+
+// compressibleTypes are the content type prefixes of responses that are
+// compressed. Images other than SVG and archives are compressed already.
+var compressibleTypes = []string{
+	"text/",
+	"application/json",
+	"application/x-ndjson",
+	"application/javascript",
+	"application/yaml",
+	"application/xml",
+	"application/atom+xml",
+	"image/svg+xml",
+}
+
+// encoder is a compressing writer of a pool of encoders.
+type encoder interface {
+	io.WriteCloser
+	Flush() error
+	Reset(w io.Writer)
+}
+
+// encoders reuse the writers of each content coding, which are costly to
+// allocate. Brotli compresses at level 5, which is about as fast as gzip's
+// default and smaller.
+var encoders = map[string]*sync.Pool{
+	"br":   {New: func() any { return brotli.NewWriterLevel(nil, 5) }},
+	"gzip": {New: func() any { return gzip.NewWriter(nil) }},
+}
+
+// acceptedEncoding returns the content coding of the Accept-Encoding header
+// of r to compress responses with: br or gzip, whichever has the higher
+// quality, br if they tie, or "" if the client accepts neither.
+func acceptedEncoding(r *http.Request) string {
+	quality := map[string]float64{}
+	for _, part := range strings.Split(strings.Join(r.Header.Values("Accept-Encoding"), ","), ",") {
+		coding, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		coding = strings.ToLower(strings.TrimSpace(coding))
+		if coding != "br" && coding != "gzip" && coding != "*" {
+			continue
+		}
+		q := 1.0
+		if name, value, ok := strings.Cut(strings.TrimSpace(params), "="); ok && strings.TrimSpace(name) == "q" {
+			if v, err := strconv.ParseFloat(strings.TrimSpace(value), 64); err == nil {
+				q = v
+			}
+		}
+		quality[coding] = q
+	}
+	best, bestQ := "", 0.0
+	for _, coding := range []string{"br", "gzip"} {
+		q, ok := quality[coding]
+		if !ok {
+			q, ok = quality["*"]
+		}
+		if ok && q > bestQ {
+			best, bestQ = coding, q
+		}
+	}
+	return best
+}
+
+// encodedETag returns the ETag of the representation of a response with
+// etag in the content coding encoding, which must differ from it as the
+// bytes do.
+func encodedETag(etag, encoding string) string {
+	if strings.HasSuffix(etag, `"`) {
+		return strings.TrimSuffix(etag, `"`) + "-" + encoding + `"`
+	}
+	return etag
+}
+
+// decodedETags returns the If-Match or If-None-Match header match with the
+// suffixes of encodedETag removed, and whether there were any.
+func decodedETags(match string) (string, bool) {
+	decoded := match
+	for encoding := range encoders {
+		decoded = strings.ReplaceAll(decoded, "-"+encoding+`"`, `"`)
+	}
+	return decoded, decoded != match
+}
+
+// compressResponseWriter compresses the body of a response in encoding if,
+// once its headers are written, it turns out to be worth it.
+type compressResponseWriter struct {
+	http.ResponseWriter
+	encoding    string
+	enc         encoder
+	wroteHeader bool
+	// encodedMatch is set when the client has a compressed copy to
+	// revalidate.
+	encodedMatch bool
+}
+
+func (w *compressResponseWriter) WriteHeader(code int) {
+	if w.wroteHeader {
+		return
+	}
+	w.wroteHeader = true
+	h := w.Header()
+	length, err := strconv.Atoi(h.Get("Content-Length"))
+	small := err == nil && length < minCompressSize
+	compressible := false
+	for _, prefix := range compressibleTypes {
+		if strings.HasPrefix(h.Get("Content-Type"), prefix) {
+			compressible = true
+		}
+	}
+	// Partial content is a range of the uncompressed bytes.
+	if code == http.StatusOK && compressible && !small && h.Get("Content-Encoding") == "" {
+		h.Set("Content-Encoding", w.encoding)
+		h.Del("Content-Length")
+		h.Del("Accept-Ranges")
+		if etag := h.Get("ETag"); etag != "" {
+			h.Set("ETag", encodedETag(etag, w.encoding))
+		}
+		w.enc = encoders[w.encoding].Get().(encoder)
+		w.enc.Reset(w.ResponseWriter)
+	} else if code == http.StatusNotModified && w.encodedMatch {
+		if etag := h.Get("ETag"); etag != "" {
+			h.Set("ETag", encodedETag(etag, w.encoding))
+		}
+	}
+	w.ResponseWriter.WriteHeader(code)
+}
+
+func (w *compressResponseWriter) Write(b []byte) (int, error) {
+	if !w.wroteHeader {
+		if w.Header().Get("Content-Type") == "" {
+			w.Header().Set("Content-Type", http.DetectContentType(b))
+		}
+		w.WriteHeader(http.StatusOK)
+	}
+	if w.enc != nil {
+		return w.enc.Write(b)
+	}
+	return w.ResponseWriter.Write(b)
+}
+
+// Flush sends what has been compressed so far, for streamed responses.
+func (w *compressResponseWriter) Flush() {
+	if !w.wroteHeader {
+		w.WriteHeader(http.StatusOK)
+	}
+	if w.enc != nil {
+		w.enc.Flush()
+	}
+	http.NewResponseController(w.ResponseWriter).Flush()
+}
+
+// Unwrap lets http.ResponseController reach the underlying writer.
+func (w *compressResponseWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// close finishes the compressed body, if any.
+func (w *compressResponseWriter) close() {
+	if w.enc != nil {
+		w.enc.Close()
+		w.enc.Reset(nil)
+		encoders[w.encoding].Put(w.enc)
+		w.enc = nil
+	}
+}
+
+// compressMiddleware compresses text responses of clients accepting brotli
+// or gzip, as acceptedEncoding picks. ETags of compressed responses get a
+// -br or -gzip suffix, which is removed from the If-None-Match and If-Match
+// headers of requests so handlers see their own ETags.
+func compressMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Add("Vary", "Accept-Encoding")
+		// A write may come from a client that read the item compressed.
+		if match, ok := decodedETags(r.Header.Get("If-Match")); ok {
+			r = r.Clone(r.Context())
+			r.Header.Set("If-Match", match)
+		}
+		encoding := acceptedEncoding(r)
+		if encoding == "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+		cw := &compressResponseWriter{ResponseWriter: w, encoding: encoding}
+		if match, ok := decodedETags(r.Header.Get("If-None-Match")); ok {
+			cw.encodedMatch = true
+			r = r.Clone(r.Context())
+			r.Header.Set("If-None-Match", match)
+		}
+		defer cw.close()
+		next.ServeHTTP(cw, r)
+	})
+}
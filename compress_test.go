@@ -0,0 +1,125 @@
+package main
+
+import (
+	"compress/gzip"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/andybalholm/brotli"
+)
+
+func TestAcceptedEncoding(t *testing.T) {
+	for header, want := range map[string]string{
+		"":                       "",
+		"gzip":                   "gzip",
+		"deflate, gzip;q=0.5":    "gzip",
+		"br, GZIP":               "br",
+		"gzip, br;q=0.8":         "gzip",
+		"br;q=0, gzip":           "gzip",
+		"gzip;q=0":               "",
+		"*":                      "br",
+		"*;q=0.5, gzip":          "gzip",
+		"identity":               "",
+		"gzip;q=0.5, br;q=0.500": "br",
+	} {
+		r := httptest.NewRequest(http.MethodGet, "/", nil)
+		r.Header.Set("Accept-Encoding", header)
+		if got := acceptedEncoding(r); got != want {
+			t.Errorf("acceptedEncoding(%q) = %q, want %q", header, got, want)
+		}
+	}
+}
+
+func TestCompressMiddleware(t *testing.T) {
+	var items strings.Builder
+	items.WriteString("Items:\n")
+	for _, label := range strings.Fields("Go Rust Zig Perl Python Ruby Java Kotlin Scala Swift Elixir Erlang Haskell OCaml Lua Julia") {
+		items.WriteString("- Label: " + label + "\n  Quadrant: Tools\n  Ring: Adopted\n  Description: A programming language that is used here.\n")
+	}
+	cfg := defaultConfig()
+	cfg.Data.Path = writeFile(t, "radar.yaml", items.String())
+	useConfig(t, cfg)
+	handler, err := setupRoutes(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	get := func(target string, header ...string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, target, nil)
+		for i := 0; i+1 < len(header); i += 2 {
+			req.Header.Set(header[i], header[i+1])
+		}
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		return rec
+	}
+
+	plain := get("/api/radar")
+	if plain.Header().Get("Content-Encoding") != "" || !strings.Contains(plain.Header().Get("Vary"), "Accept-Encoding") {
+		t.Errorf("GET /api/radar without Accept-Encoding: Content-Encoding %q, Vary %q", plain.Header().Get("Content-Encoding"), plain.Header().Values("Vary"))
+	}
+	rec := get("/api/radar", "Accept-Encoding", "gzip")
+	if rec.Header().Get("Content-Encoding") != "gzip" || rec.Header().Get("Content-Length") != "" {
+		t.Fatalf("GET /api/radar with gzip: Content-Encoding %q, Content-Length %q", rec.Header().Get("Content-Encoding"), rec.Header().Get("Content-Length"))
+	}
+	zr, err := gzip.NewReader(rec.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, err := io.ReadAll(zr)
+	if err != nil || string(body) != plain.Body.String() {
+		t.Errorf("gunzipped body = %q, %v, want %q", body, err, plain.Body.String())
+	}
+
+	// The compressed response has an ETag of its own, which revalidates.
+	etag := rec.Header().Get("ETag")
+	if want := encodedETag(plain.Header().Get("ETag"), "gzip"); etag != want || !strings.HasSuffix(etag, `-gzip"`) {
+		t.Errorf("ETag = %q, want %q", etag, want)
+	}
+	if rec := get("/api/radar", "Accept-Encoding", "gzip", "If-None-Match", etag); rec.Code != http.StatusNotModified || rec.Header().Get("ETag") != etag {
+		t.Errorf("GET /api/radar If-None-Match %s = %d, ETag %q, want 304", etag, rec.Code, rec.Header().Get("ETag"))
+	}
+	if rec := get("/api/radar", "If-None-Match", plain.Header().Get("ETag")); rec.Code != http.StatusNotModified {
+		t.Errorf("GET /api/radar If-None-Match without gzip = %d, want 304", rec.Code)
+	}
+
+	// Brotli is preferred, with the same rules.
+	br := get("/api/radar", "Accept-Encoding", "gzip, deflate, br")
+	if br.Header().Get("Content-Encoding") != "br" || br.Header().Get("Content-Length") != "" || br.Header().Get("ETag") != encodedETag(plain.Header().Get("ETag"), "br") {
+		t.Fatalf("GET /api/radar with br: Content-Encoding %q, Content-Length %q, ETag %q", br.Header().Get("Content-Encoding"), br.Header().Get("Content-Length"), br.Header().Get("ETag"))
+	}
+	if body, err := io.ReadAll(brotli.NewReader(br.Body)); err != nil || string(body) != plain.Body.String() {
+		t.Errorf("decompressed body = %q, %v, want %q", body, err, plain.Body.String())
+	}
+	if rec := get("/api/radar", "Accept-Encoding", "br", "If-None-Match", br.Header().Get("ETag")); rec.Code != http.StatusNotModified || rec.Header().Get("ETag") != br.Header().Get("ETag") {
+		t.Errorf("GET /api/radar with br If-None-Match = %d, ETag %q, want 304", rec.Code, rec.Header().Get("ETag"))
+	}
+	if rec := get("/health", "Accept-Encoding", "br"); rec.Header().Get("Content-Encoding") != "" {
+		t.Errorf("GET /health with br: Content-Encoding %q, want it uncompressed", rec.Header().Get("Content-Encoding"))
+	}
+
+	// Small responses, partial content and static files are left alone or
+	// compressed as their type calls for.
+	if rec := get("/health", "Accept-Encoding", "gzip"); rec.Header().Get("Content-Encoding") != "" || rec.Body.String() != "OK" {
+		t.Errorf("GET /health with gzip = %q, Content-Encoding %q, want it uncompressed", rec.Body.String(), rec.Header().Get("Content-Encoding"))
+	}
+	if rec := get("/api/radar", "Accept-Encoding", "gzip", "Range", "bytes=0-9"); rec.Code != http.StatusPartialContent || rec.Header().Get("Content-Encoding") != "" {
+		t.Errorf("GET /api/radar Range with gzip = %d, Content-Encoding %q, want uncompressed 206", rec.Code, rec.Header().Get("Content-Encoding"))
+	}
+	if rec := get("/static/radar.js", "Accept-Encoding", "gzip"); rec.Header().Get("Content-Encoding") != "gzip" {
+		t.Errorf("GET /static/radar.js with gzip: Content-Encoding %q, want gzip", rec.Header().Get("Content-Encoding"))
+	}
+	if rec := get("/", "Accept-Encoding", "gzip"); rec.Header().Get("Content-Encoding") != "gzip" {
+		t.Errorf("GET / with gzip: Content-Encoding %q, want gzip", rec.Header().Get("Content-Encoding"))
+	}
+
+	cfg.Server.Compress = false
+	if handler, err = setupRoutes(cfg); err != nil {
+		t.Fatal(err)
+	}
+	if rec := get("/api/radar", "Accept-Encoding", "gzip"); rec.Header().Get("Content-Encoding") != "" {
+		t.Errorf("GET /api/radar with compression disabled: Content-Encoding %q", rec.Header().Get("Content-Encoding"))
+	}
+}
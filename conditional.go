@@ -0,0 +1,77 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"os"
+	"time"
+)
+
+// Cache-Control values. API responses may be kept but are revalidated on
+// every use, which their ETag makes cheap. Static assets aren't
+// fingerprinted, so they are only reused for a short while.
+const (
+	apiCacheControl    = "no-cache"
+	staticCacheControl = "public, max-age=3600"
+)
+
+// setCacheControl sets the Cache-Control header of a response unless it is
+// already set, as noCacheMiddleware does in development mode.
+func setCacheControl(w http.ResponseWriter, value string) {
+	if w.Header().Get("Cache-Control") == "" {
+		w.Header().Set("Cache-Control", value)
+	}
+}
+
+// cacheControlHandler serves next with the Cache-Control header value.
+func cacheControlHandler(value string, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		setCacheControl(w, value)
+		next.ServeHTTP(w, r)
+	})
+}
+
+// contentETag returns a strong ETag for content. Responses are encoded
+// deterministically from the parsed data and the request, so the ETag
+// only changes with them.
+func contentETag(content []byte) string {
+	sum := sha256.Sum256(content)
+	return `"` + hex.EncodeToString(sum[:16]) + `"`
+}
+
+// dataModTime returns when the radar data last changed: the newest
+// modification time of the data files of a file store, or the time of the
+// latest snapshot of a database store. It is zero when unknown, as for
+// remote data.
+func dataModTime(ctx context.Context) time.Time {
+	var modified time.Time
+	switch s := currentStore().(type) {
+	case *fileStore:
+		files, err := dataFiles(s.path)
+		if err != nil {
+			return time.Time{}
+		}
+		for _, file := range files {
+			if info, err := os.Stat(file); err == nil && info.ModTime().After(modified) {
+				modified = info.ModTime()
+			}
+		}
+	case *databaseStore:
+		if snapshots, err := s.db.Snapshots(ctx, 1); err == nil && len(snapshots) > 0 {
+			modified = snapshots[0].Time
+		}
+	}
+	return modified
+}
+
+// serveConditional writes content with its ETag and modified as its
+// Last-Modified time, answering If-None-Match and If-Modified-Since with
+// 304 Not Modified when the client's copy is current.
+func serveConditional(w http.ResponseWriter, r *http.Request, content []byte, modified time.Time) {
+	w.Header().Set("ETag", contentETag(content))
+	setCacheControl(w, apiCacheControl)
+	http.ServeContent(w, r, "", modified, bytes.NewReader(content))
+}
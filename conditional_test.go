@@ -0,0 +1,98 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+)
+
+func TestConditionalRadarRequests(t *testing.T) {
+	cfg := defaultConfig()
+	cfg.Data.Path = writeFile(t, "radar.yaml", radarWith("Go", "Adopted"))
+	modified := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	if err := os.Chtimes(cfg.Data.Path, modified, modified); err != nil {
+		t.Fatal(err)
+	}
+	useConfig(t, cfg)
+	handler, err := setupRoutes(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	get := func(target string, header ...string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, target, nil)
+		for i := 0; i+1 < len(header); i += 2 {
+			req.Header.Set(header[i], header[i+1])
+		}
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		return rec
+	}
+
+	rec := get("/api/radar")
+	etag := rec.Header().Get("ETag")
+	if rec.Code != http.StatusOK || len(etag) != 34 || etag[0] != '"' {
+		t.Fatalf("GET /api/radar = %d, ETag %q, want a strong ETag", rec.Code, etag)
+	}
+	if got := rec.Header().Get("Last-Modified"); got != modified.Format(http.TimeFormat) {
+		t.Errorf("Last-Modified = %q, want the data file's modification time", got)
+	}
+	if got := rec.Header().Get("Cache-Control"); got != apiCacheControl {
+		t.Errorf("Cache-Control = %q, want %q", got, apiCacheControl)
+	}
+	if again := get("/api/radar").Header().Get("ETag"); again != etag {
+		t.Errorf("ETag = %q, then %q, want it to stay the same", etag, again)
+	}
+	for _, other := range []string{"/api/radar?format=yaml", "/api/radar?fields=label"} {
+		if got := get(other).Header().Get("ETag"); got == etag {
+			t.Errorf("GET %s ETag = %q, want it to differ from that of GET /api/radar", other, got)
+		}
+	}
+
+	tests := []struct {
+		name   string
+		header []string
+		code   int
+	}{
+		{"matching ETag", []string{"If-None-Match", etag}, http.StatusNotModified},
+		{"one of several ETags", []string{"If-None-Match", `"other", ` + etag}, http.StatusNotModified},
+		{"other ETag", []string{"If-None-Match", `"other"`}, http.StatusOK},
+		{"not modified since", []string{"If-Modified-Since", modified.Format(http.TimeFormat)}, http.StatusNotModified},
+		{"modified since", []string{"If-Modified-Since", modified.Add(-time.Hour).Format(http.TimeFormat)}, http.StatusOK},
+		// If-None-Match wins over If-Modified-Since.
+		{"other ETag, not modified since", []string{"If-None-Match", `"other"`, "If-Modified-Since", modified.Format(http.TimeFormat)}, http.StatusOK},
+	}
+	for _, tt := range tests {
+		rec := get("/api/radar", tt.header...)
+		if rec.Code != tt.code {
+			t.Errorf("%s: GET /api/radar = %d, want %d", tt.name, rec.Code, tt.code)
+		}
+		if tt.code == http.StatusNotModified && (rec.Body.Len() != 0 || rec.Header().Get("ETag") != etag) {
+			t.Errorf("%s: 304 with body %q and ETag %q", tt.name, rec.Body.String(), rec.Header().Get("ETag"))
+		}
+	}
+
+	// Changing the data changes the ETag and Last-Modified.
+	if err := os.WriteFile(cfg.Data.Path, []byte(radarWith("Go", "In Discovery")), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	useConfig(t, cfg)
+	if handler, err = setupRoutes(cfg); err != nil {
+		t.Fatal(err)
+	}
+	if rec := get("/api/radar", "If-None-Match", etag); rec.Code != http.StatusOK || rec.Header().Get("ETag") == etag {
+		t.Errorf("GET /api/radar after a change = %d, ETag %q, want 200 and a new ETag", rec.Code, rec.Header().Get("ETag"))
+	}
+
+	if got := get("/static/radar.js").Header().Get("Cache-Control"); got != staticCacheControl {
+		t.Errorf("GET /static/radar.js Cache-Control = %q, want %q", got, staticCacheControl)
+	}
+	cfg.Dev = true
+	if handler, err = setupRoutes(cfg); err != nil {
+		t.Fatal(err)
+	}
+	if got := get("/api/radar").Header().Get("Cache-Control"); got != "no-store" {
+		t.Errorf("GET /api/radar in development mode Cache-Control = %q, want no-store", got)
+	}
+}
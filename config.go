@@ -0,0 +1,1155 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"io/fs"
+	"log/slog"
+	"net"
+	"net/mail"
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"gopkg.in/yaml.v3"
+)
+
+// Config holds the runtime configuration of the server.
+type Config struct {
+	// Path is the config file the configuration was loaded from, if any.
+	Path string `yaml:"-"`
+	// EnvFile is the dotenv file environment variables are read from.
+	EnvFile string `yaml:"-"`
+	// Check validates the configuration and radar data, then exits.
+	Check bool `yaml:"-"`
+	// Dev enables development mode: templates are re-parsed on every
+	// request and responses are not cached.
+	Dev bool `yaml:"dev"`
+
+	Server     ServerConfig     `yaml:"server"`
+	Data       DataConfig       `yaml:"data"`
+	Logging    LoggingConfig    `yaml:"logging"`
+	Features   FeatureConfig    `yaml:"features"`
+	Admin      AdminConfig      `yaml:"admin"`
+	Store      StoreConfig      `yaml:"store"`
+	Cache      CacheConfig      `yaml:"cache"`
+	Radar      RadarConfig      `yaml:"radar"`
+	Encryption EncryptionConfig `yaml:"encryption"`
+	Backup     BackupConfig     `yaml:"backup"`
+	Review     ReviewConfig     `yaml:"review"`
+	Sunset     SunsetConfig     `yaml:"sunset"`
+	Reminders  ReminderConfig   `yaml:"reminders"`
+	// Notifications are where notifications, such as of sunsets, go.
+	Notifications NotificationConfig `yaml:"notifications"`
+}
+
+// ServerConfig configures the HTTP listener.
+type ServerConfig struct {
+	Listen            string        `yaml:"listen"`
+	ReadTimeout       time.Duration `yaml:"readTimeout"`
+	ReadHeaderTimeout time.Duration `yaml:"readHeaderTimeout"`
+	WriteTimeout      time.Duration `yaml:"writeTimeout"`
+	IdleTimeout       time.Duration `yaml:"idleTimeout"`
+	RequestTimeout    time.Duration `yaml:"requestTimeout"`
+	ShutdownTimeout   time.Duration `yaml:"shutdownTimeout"`
+	TLS               TLSConfig     `yaml:"tls"`
+	// BasePath mounts every route under a path prefix such as /tech-radar.
+	BasePath string `yaml:"basePath"`
+	// TrustProxy honors X-Forwarded-* headers from a fronting reverse proxy.
+	TrustProxy bool `yaml:"trustProxy"`
+	// Compress compresses text responses with brotli or gzip for clients
+	// that accept it.
+	Compress bool `yaml:"compress"`
+	// GRPCListen, when set, serves the gRPC API of radar.proto on this
+	// address, over TLS when HTTPS is configured.
+	GRPCListen string     `yaml:"grpcListen"`
+	CORS       CORSConfig `yaml:"cors"`
+	// FrameAncestors are the origins, such as https://acme.atlassian.net,
+	// of the pages that may embed /embed in a frame, or * for any. Other
+	// pages can't frame the radar.
+	FrameAncestors []string `yaml:"frameAncestors"`
+	// UserHeader is the header, such as X-Forwarded-User, in which the
+	// reverse proxy passes the name of the user it signed in, who votes as
+	// that name. It requires TrustProxy.
+	UserHeader string `yaml:"userHeader"`
+}
+
+// CORSConfig lets pages on other origins call the API from the browser.
+type CORSConfig struct {
+	// AllowedOrigins are the origins, such as https://dashboard.example.com,
+	// whose scripts may call the API, or * for any. CORS is off without
+	// any.
+	AllowedOrigins []string `yaml:"allowedOrigins"`
+	AllowedMethods []string `yaml:"allowedMethods"`
+	AllowedHeaders []string `yaml:"allowedHeaders"`
+	// MaxAge is how long browsers may cache the answer to a preflight
+	// request.
+	MaxAge time.Duration `yaml:"maxAge"`
+}
+
+// TLSConfig enables serving HTTPS directly, either from a certificate and
+// key file or with certificates obtained automatically through ACME.
+type TLSConfig struct {
+	Cert string     `yaml:"cert"`
+	Key  string     `yaml:"key"`
+	ACME ACMEConfig `yaml:"acme"`
+	// RedirectListen, when set, starts a plain HTTP listener on this address
+	// that redirects every request to HTTPS.
+	RedirectListen string `yaml:"redirectListen"`
+}
+
+// ACMEConfig configures automatic certificates from Let's Encrypt.
+type ACMEConfig struct {
+	Domains  []string `yaml:"domains"`
+	CacheDir string   `yaml:"cacheDir"`
+	Email    string   `yaml:"email"`
+}
+
+// enabled reports whether HTTPS is configured.
+func (t TLSConfig) enabled() bool {
+	return t.Cert != "" || t.Key != "" || t.ACME.enabled()
+}
+
+// enabled reports whether certificates are obtained through ACME.
+func (a ACMEConfig) enabled() bool {
+	return len(a.Domains) > 0
+}
+
+// DataConfig configures where radar data and web assets are read from. The
+// templates and static assets are embedded in the binary; setting Templates
+// or Static serves them from that directory instead.
+type DataConfig struct {
+	Path string `yaml:"path"`
+	// PollInterval is how often data is re-fetched when Path is a URL.
+	PollInterval time.Duration `yaml:"pollInterval"`
+	Templates    string        `yaml:"templates"`
+	Static       string        `yaml:"static"`
+	// Git, when its URL is set, reads Path from a checkout of a Git
+	// repository instead of the local filesystem.
+	Git GitConfig `yaml:"git"`
+	// Commit, when enabled, commits every write to the data file to the
+	// Git repository it is in.
+	Commit CommitConfig `yaml:"commit"`
+}
+
+// CommitConfig configures the commits made for writes to a data file in a
+// Git working tree. Each is authored by who made the write: the reviewer
+// with their email address, or AdminAuthor for the admin token, such as
+// "Radar Admin <radar@example.com>".
+type CommitConfig struct {
+	Enabled     bool   `yaml:"enabled"`
+	AdminAuthor string `yaml:"adminAuthor"`
+}
+
+// GitConfig configures a Git repository as the data source. The branch is
+// cloned into Dir and updated every poll interval or on a webhook ping.
+type GitConfig struct {
+	URL    string `yaml:"url"`
+	Branch string `yaml:"branch"`
+	Dir    string `yaml:"dir"`
+	// WebhookSecret enables POST /api/git/webhook, which triggers an
+	// immediate update. Requests must carry a GitHub signature made with
+	// it, or it as a GitLab token.
+	WebhookSecret string `yaml:"webhookSecret" secret:"true"`
+	// PullRequests, when its Repo is set, turns writes to Path, which the
+	// checkout can't take, into pull requests against Branch.
+	PullRequests PullRequestConfig `yaml:"pullRequests"`
+}
+
+// PullRequestConfig configures the GitHub repository writes open pull
+// requests against, as "owner/name", and the token opening them, which
+// needs write access to its contents and pull requests.
+type PullRequestConfig struct {
+	Repo   string `yaml:"repo"`
+	Token  string `yaml:"token" secret:"true"`
+	APIURL string `yaml:"apiURL"`
+}
+
+// enabled reports whether writes open pull requests.
+func (p PullRequestConfig) enabled() bool {
+	return p.Repo != ""
+}
+
+// enabled reports whether data is read from a Git repository.
+func (g GitConfig) enabled() bool {
+	return g.URL != ""
+}
+
+// LoggingConfig configures log output.
+type LoggingConfig struct {
+	Level     string `yaml:"level"`
+	Format    string `yaml:"format"`
+	AccessLog bool   `yaml:"accessLog"`
+}
+
+// FeatureConfig toggles optional parts of the server.
+type FeatureConfig struct {
+	UI  bool `yaml:"ui"`
+	API bool `yaml:"api"`
+}
+
+// AdminConfig configures the endpoints that modify radar data.
+type AdminConfig struct {
+	// Token is the bearer token required by admin endpoints such as
+	// /api/import. Admin endpoints are disabled while it is empty.
+	Token string `yaml:"token" secret:"true"`
+}
+
+// ReviewConfig configures the review of proposals by an architecture board.
+type ReviewConfig struct {
+	// Reviewers may move proposals through review. Their tokens are
+	// secret, so the list is never logged.
+	Reviewers []ReviewerConfig `yaml:"reviewers" secret:"true"`
+	// RequiredRoles are the roles a proposal needs the approval of a
+	// reviewer holding each of before it is approved. Without any, one
+	// approval is enough.
+	RequiredRoles []string `yaml:"requiredRoles"`
+}
+
+// SunsetConfig configures the job checking the SunsetDate of items.
+type SunsetConfig struct {
+	// Interval is the time between checks; zero disables them.
+	Interval time.Duration `yaml:"interval"`
+	// Notice is how long before its sunset an item is upcoming.
+	Notice time.Duration `yaml:"notice"`
+	// MoveTo is the ring items past their sunset are moved to, such as
+	// Hold; empty leaves them in their ring.
+	MoveTo string `yaml:"moveTo"`
+}
+
+// enabled reports whether sunsets are checked.
+func (s SunsetConfig) enabled() bool {
+	return s.Interval > 0
+}
+
+// ReminderConfig configures the job reminding owners of the ReviewDate of
+// their items.
+type ReminderConfig struct {
+	// Interval is the time between checks; zero disables them.
+	Interval time.Duration `yaml:"interval"`
+	// Notice is how long before its review date owners are reminded of an
+	// item.
+	Notice time.Duration `yaml:"notice"`
+}
+
+// enabled reports whether review reminders are sent.
+func (r ReminderConfig) enabled() bool {
+	return r.Interval > 0
+}
+
+// NotificationConfig configures where notifications are sent. The URLs
+// usually carry a token, so they are secret.
+type NotificationConfig struct {
+	// Webhook is a URL notifications are posted to as JSON.
+	Webhook string `yaml:"webhook" secret:"true"`
+	// Slack is the URL of a Slack incoming webhook.
+	Slack string `yaml:"slack" secret:"true"`
+	// Email sends the notifications addressed to people, such as review
+	// reminders to the owners of items, as email.
+	Email EmailConfig `yaml:"email"`
+}
+
+// EmailConfig configures the SMTP server notifications are emailed through.
+type EmailConfig struct {
+	// SMTP is the host:port of the server, such as smtp.example.com:587;
+	// empty sends no email. STARTTLS is used when the server offers it.
+	SMTP string `yaml:"smtp"`
+	// From is the address the email is sent from.
+	From string `yaml:"from"`
+	// Username and Password sign in to the server, if it requires it.
+	Username string `yaml:"username"`
+	Password string `yaml:"password" secret:"true"`
+}
+
+// ReviewerConfig is a reviewer of proposals, who signs in with Token as the
+// bearer token.
+type ReviewerConfig struct {
+	Name  string   `yaml:"name"`
+	Email string   `yaml:"email"`
+	Token string   `yaml:"token"`
+	Roles []string `yaml:"roles"`
+}
+
+// reviewEnabled reports whether anyone may review proposals: the reviewers, and
+// the admin, who holds every role.
+func (c Config) reviewEnabled() bool {
+	return len(c.Review.Reviewers) > 0 || c.Admin.Token != ""
+}
+
+// StoreConfig selects a database that persists radar data, snapshots, edits
+// and audit events instead of serving the data files directly. On first run
+// the store is seeded from the data files.
+type StoreConfig struct {
+	// Driver is the store implementation, or empty to serve the data files.
+	Driver string `yaml:"driver"`
+	// DSN locates the database, such as a file path for SQLite or a
+	// postgres:// URL.
+	DSN string `yaml:"dsn" secret:"true"`
+	// MaxOpenConns, MaxIdleConns and ConnMaxLifetime size the connection
+	// pool of a database server. SQLite always uses a single connection.
+	MaxOpenConns    int           `yaml:"maxOpenConns"`
+	MaxIdleConns    int           `yaml:"maxIdleConns"`
+	ConnMaxLifetime time.Duration `yaml:"connMaxLifetime"`
+}
+
+// enabled reports whether radar data is kept in a store.
+func (s StoreConfig) enabled() bool {
+	return s.Driver != ""
+}
+
+// CacheConfig configures caching the parsed radar data in Redis, shared by
+// every instance using the same server.
+type CacheConfig struct {
+	// RedisURL is the redis:// or rediss:// URL of the server, or empty to
+	// load the data on every request.
+	RedisURL string `yaml:"redisURL" secret:"true"`
+	// TTL is how long cached data is served before it is loaded again.
+	// Imports and Git updates invalidate it immediately.
+	TTL time.Duration `yaml:"ttl"`
+	// Prefix is prepended to the Redis keys and channel, so several radars
+	// can share a server.
+	Prefix string `yaml:"prefix"`
+}
+
+// enabled reports whether radar data is cached in Redis.
+func (c CacheConfig) enabled() bool {
+	return c.RedisURL != ""
+}
+
+// RadarConfig configures the layout of radars whose data doesn't declare
+// its own.
+type RadarConfig struct {
+	// Rings replace the default rings, from the innermost outwards.
+	Rings []RingConfig `yaml:"rings"`
+}
+
+// EncryptionConfig configures encrypting the data files and the bbolt store
+// at rest. Other stores are left to the database to encrypt.
+type EncryptionConfig struct {
+	// Key is a 256-bit AES key, as 64 hex digits or in base64. Data is
+	// stored in plain text when it is empty.
+	Key string `yaml:"key" secret:"true"`
+}
+
+// BackupConfig configures scheduled backups of the radar data, in the
+// format of GET /api/admin/backup.
+type BackupConfig struct {
+	// Interval is the time between backups; zero disables them.
+	Interval time.Duration `yaml:"interval"`
+	// Format is yaml or tar.gz.
+	Format string `yaml:"format"`
+	// Dir is a directory to write backups to.
+	Dir string `yaml:"dir"`
+	// S3 is a bucket to write backups to.
+	S3 S3Config `yaml:"s3"`
+	// Keep is how many backups each target keeps, and MaxAge how long;
+	// zero keeps them regardless.
+	Keep   int           `yaml:"keep"`
+	MaxAge time.Duration `yaml:"maxAge"`
+}
+
+// enabled reports whether scheduled backups are configured.
+func (b BackupConfig) enabled() bool {
+	return b.Interval > 0
+}
+
+// S3Config names an S3 bucket and the credentials to write to it with.
+type S3Config struct {
+	Bucket string `yaml:"bucket"`
+	// Prefix is prepended to the object keys, such as "radar/".
+	Prefix string `yaml:"prefix"`
+	Region string `yaml:"region"`
+	// Endpoint is the URL of an S3-compatible service such as MinIO; AWS
+	// is used when it is empty.
+	Endpoint        string `yaml:"endpoint"`
+	AccessKeyID     string `yaml:"accessKeyID"`
+	SecretAccessKey string `yaml:"secretAccessKey" secret:"true"`
+}
+
+// defaultConfig returns the configuration used when nothing is overridden.
+func defaultConfig() Config {
+	return Config{
+		Server: ServerConfig{
+			Listen:            ":8080",
+			ReadTimeout:       15 * time.Second,
+			ReadHeaderTimeout: 5 * time.Second,
+			WriteTimeout:      60 * time.Second,
+			IdleTimeout:       120 * time.Second,
+			ShutdownTimeout:   15 * time.Second,
+			Compress:          true,
+			CORS: CORSConfig{
+				AllowedMethods: []string{"GET", "HEAD"},
+				AllowedHeaders: []string{"Accept", "Content-Type"},
+				MaxAge:         10 * time.Minute,
+			},
+			FrameAncestors: []string{"*"},
+			TLS: TLSConfig{
+				ACME: ACMEConfig{CacheDir: "acme-cache"},
+			},
+		},
+		Data: DataConfig{
+			Path:         "data/radar.yaml",
+			PollInterval: time.Minute,
+			Git:          GitConfig{Branch: "main", Dir: "git-checkout", PullRequests: PullRequestConfig{APIURL: "https://api.github.com"}},
+		},
+		Logging: LoggingConfig{
+			Level:  "info",
+			Format: "text",
+		},
+		Features: FeatureConfig{
+			UI:  true,
+			API: true,
+		},
+		Store: StoreConfig{
+			MaxOpenConns:    10,
+			MaxIdleConns:    5,
+			ConnMaxLifetime: 30 * time.Minute,
+		},
+		Cache: CacheConfig{
+			TTL:    5 * time.Minute,
+			Prefix: "clean-tech-radar:",
+		},
+		Backup: BackupConfig{
+			Format: backupArchive,
+			Keep:   7,
+			S3:     S3Config{Region: "us-east-1"},
+		},
+		Sunset:    SunsetConfig{Notice: 30 * 24 * time.Hour},
+		Reminders: ReminderConfig{Notice: 14 * 24 * time.Hour},
+	}
+}
+
+// envVar binds a RADAR_* environment variable to the config field it sets.
+type envVar struct {
+	name string
+	set  func(*Config, string) error
+}
+
+// envVars lists the supported environment variables in the order they are
+// applied. When two variables set the same field the later one wins, so
+// RADAR_LISTEN overrides the RADAR_PORT shorthand.
+var envVars = []envVar{
+	{"RADAR_PORT", func(c *Config, v string) error { c.Server.Listen = portAddr(v); return nil }},
+	{"RADAR_LISTEN", func(c *Config, v string) error { c.Server.Listen = v; return nil }},
+	{"RADAR_READ_TIMEOUT", durationEnv(func(c *Config) *time.Duration { return &c.Server.ReadTimeout })},
+	{"RADAR_READ_HEADER_TIMEOUT", durationEnv(func(c *Config) *time.Duration { return &c.Server.ReadHeaderTimeout })},
+	{"RADAR_WRITE_TIMEOUT", durationEnv(func(c *Config) *time.Duration { return &c.Server.WriteTimeout })},
+	{"RADAR_IDLE_TIMEOUT", durationEnv(func(c *Config) *time.Duration { return &c.Server.IdleTimeout })},
+	{"RADAR_REQUEST_TIMEOUT", durationEnv(func(c *Config) *time.Duration { return &c.Server.RequestTimeout })},
+	{"RADAR_SHUTDOWN_TIMEOUT", durationEnv(func(c *Config) *time.Duration { return &c.Server.ShutdownTimeout })},
+	{"RADAR_TLS_CERT", func(c *Config, v string) error { c.Server.TLS.Cert = v; return nil }},
+	{"RADAR_TLS_KEY", func(c *Config, v string) error { c.Server.TLS.Key = v; return nil }},
+	{"RADAR_ACME_DOMAIN", func(c *Config, v string) error { c.Server.TLS.ACME.Domains = splitList(v); return nil }},
+	{"RADAR_ACME_CACHE", func(c *Config, v string) error { c.Server.TLS.ACME.CacheDir = v; return nil }},
+	{"RADAR_ACME_EMAIL", func(c *Config, v string) error { c.Server.TLS.ACME.Email = v; return nil }},
+	{"RADAR_TLS_REDIRECT", func(c *Config, v string) error { c.Server.TLS.RedirectListen = v; return nil }},
+	{"RADAR_GRPC_LISTEN", func(c *Config, v string) error { c.Server.GRPCListen = v; return nil }},
+	{"RADAR_CORS_ORIGINS", func(c *Config, v string) error { c.Server.CORS.AllowedOrigins = splitList(v); return nil }},
+	{"RADAR_CORS_METHODS", func(c *Config, v string) error { c.Server.CORS.AllowedMethods = splitList(v); return nil }},
+	{"RADAR_CORS_HEADERS", func(c *Config, v string) error { c.Server.CORS.AllowedHeaders = splitList(v); return nil }},
+	{"RADAR_CORS_MAX_AGE", durationEnv(func(c *Config) *time.Duration { return &c.Server.CORS.MaxAge })},
+	{"RADAR_FRAME_ANCESTORS", func(c *Config, v string) error { c.Server.FrameAncestors = splitList(v); return nil }},
+	{"RADAR_BASE_PATH", func(c *Config, v string) error { c.Server.BasePath = v; return nil }},
+	{"RADAR_TRUST_PROXY", boolEnv(func(c *Config) *bool { return &c.Server.TrustProxy })},
+	{"RADAR_USER_HEADER", func(c *Config, v string) error { c.Server.UserHeader = v; return nil }},
+	{"RADAR_COMPRESS", boolEnv(func(c *Config) *bool { return &c.Server.Compress })},
+	{"RADAR_DEV", boolEnv(func(c *Config) *bool { return &c.Dev })},
+	{"RADAR_DATA_PATH", func(c *Config, v string) error { c.Data.Path = v; return nil }},
+	{"RADAR_DATA_POLL_INTERVAL", durationEnv(func(c *Config) *time.Duration { return &c.Data.PollInterval })},
+	{"RADAR_GIT_URL", func(c *Config, v string) error { c.Data.Git.URL = v; return nil }},
+	{"RADAR_GIT_BRANCH", func(c *Config, v string) error { c.Data.Git.Branch = v; return nil }},
+	{"RADAR_GIT_DIR", func(c *Config, v string) error { c.Data.Git.Dir = v; return nil }},
+	{"RADAR_GIT_WEBHOOK_SECRET", func(c *Config, v string) error { c.Data.Git.WebhookSecret = v; return nil }},
+	{"RADAR_GIT_PR_REPO", func(c *Config, v string) error { c.Data.Git.PullRequests.Repo = v; return nil }},
+	{"RADAR_GIT_PR_TOKEN", func(c *Config, v string) error { c.Data.Git.PullRequests.Token = v; return nil }},
+	{"RADAR_GIT_PR_API_URL", func(c *Config, v string) error { c.Data.Git.PullRequests.APIURL = v; return nil }},
+	{"RADAR_DATA_COMMIT", boolEnv(func(c *Config) *bool { return &c.Data.Commit.Enabled })},
+	{"RADAR_DATA_COMMIT_ADMIN_AUTHOR", func(c *Config, v string) error { c.Data.Commit.AdminAuthor = v; return nil }},
+	{"RADAR_TEMPLATES_PATH", func(c *Config, v string) error { c.Data.Templates = v; return nil }},
+	{"RADAR_STATIC_PATH", func(c *Config, v string) error { c.Data.Static = v; return nil }},
+	{"RADAR_LOG_LEVEL", func(c *Config, v string) error { c.Logging.Level = v; return nil }},
+	{"RADAR_LOG_FORMAT", func(c *Config, v string) error { c.Logging.Format = v; return nil }},
+	{"RADAR_ADMIN_TOKEN", func(c *Config, v string) error { c.Admin.Token = v; return nil }},
+	{"RADAR_REVIEW_REQUIRED_ROLES", func(c *Config, v string) error { c.Review.RequiredRoles = splitList(v); return nil }},
+	{"RADAR_STORE_DRIVER", func(c *Config, v string) error { c.Store.Driver = v; return nil }},
+	{"RADAR_STORE_DSN", func(c *Config, v string) error { c.Store.DSN = v; return nil }},
+	{"RADAR_STORE_MAX_OPEN_CONNS", intEnv(func(c *Config) *int { return &c.Store.MaxOpenConns })},
+	{"RADAR_STORE_MAX_IDLE_CONNS", intEnv(func(c *Config) *int { return &c.Store.MaxIdleConns })},
+	{"RADAR_STORE_CONN_MAX_LIFETIME", durationEnv(func(c *Config) *time.Duration { return &c.Store.ConnMaxLifetime })},
+	{"RADAR_REDIS_URL", func(c *Config, v string) error { c.Cache.RedisURL = v; return nil }},
+	{"RADAR_CACHE_TTL", durationEnv(func(c *Config) *time.Duration { return &c.Cache.TTL })},
+	{"RADAR_CACHE_PREFIX", func(c *Config, v string) error { c.Cache.Prefix = v; return nil }},
+	{"RADAR_ENCRYPTION_KEY", func(c *Config, v string) error { c.Encryption.Key = v; return nil }},
+	{"RADAR_BACKUP_INTERVAL", durationEnv(func(c *Config) *time.Duration { return &c.Backup.Interval })},
+	{"RADAR_BACKUP_DIR", func(c *Config, v string) error { c.Backup.Dir = v; return nil }},
+	{"RADAR_BACKUP_S3_BUCKET", func(c *Config, v string) error { c.Backup.S3.Bucket = v; return nil }},
+	{"RADAR_BACKUP_S3_ACCESS_KEY_ID", func(c *Config, v string) error { c.Backup.S3.AccessKeyID = v; return nil }},
+	{"RADAR_BACKUP_S3_SECRET_ACCESS_KEY", func(c *Config, v string) error { c.Backup.S3.SecretAccessKey = v; return nil }},
+	{"RADAR_SUNSET_INTERVAL", durationEnv(func(c *Config) *time.Duration { return &c.Sunset.Interval })},
+	{"RADAR_SUNSET_MOVE_TO", func(c *Config, v string) error { c.Sunset.MoveTo = v; return nil }},
+	{"RADAR_REMINDER_INTERVAL", durationEnv(func(c *Config) *time.Duration { return &c.Reminders.Interval })},
+	{"RADAR_REMINDER_NOTICE", durationEnv(func(c *Config) *time.Duration { return &c.Reminders.Notice })},
+	{"RADAR_NOTIFY_WEBHOOK", func(c *Config, v string) error { c.Notifications.Webhook = v; return nil }},
+	{"RADAR_NOTIFY_SLACK", func(c *Config, v string) error { c.Notifications.Slack = v; return nil }},
+	{"RADAR_SMTP_ADDR", func(c *Config, v string) error { c.Notifications.Email.SMTP = v; return nil }},
+	{"RADAR_SMTP_FROM", func(c *Config, v string) error { c.Notifications.Email.From = v; return nil }},
+	{"RADAR_SMTP_USERNAME", func(c *Config, v string) error { c.Notifications.Email.Username = v; return nil }},
+	{"RADAR_SMTP_PASSWORD", func(c *Config, v string) error { c.Notifications.Email.Password = v; return nil }},
+}
+
+// boolEnv returns an envVars setter that parses a bool into the field
+// returned by field.
+func boolEnv(field func(*Config) *bool) func(*Config, string) error {
+	return func(c *Config, v string) error {
+		b, err := strconv.ParseBool(v)
+		if err != nil {
+			return err
+		}
+		*field(c) = b
+		return nil
+	}
+}
+
+// intEnv returns an envVars setter that parses an int into the field
+// returned by field.
+func intEnv(field func(*Config) *int) func(*Config, string) error {
+	return func(c *Config, v string) error {
+		n, err := strconv.Atoi(v)
+		if err != nil {
+			return err
+		}
+		*field(c) = n
+		return nil
+	}
+}
+
+// durationEnv returns an envVars setter that parses a time.Duration into
+// the field returned by field.
+func durationEnv(field func(*Config) *time.Duration) func(*Config, string) error {
+	return func(c *Config, v string) error {
+		d, err := time.ParseDuration(v)
+		if err != nil {
+			return err
+		}
+		*field(c) = d
+		return nil
+	}
+}
+
+// newFlagSet returns the command-line flags, bound to the fields of cfg.
+func newFlagSet(cfg *Config) *flag.FlagSet {
+	fs := flag.NewFlagSet("clean-tech-radar", flag.ContinueOnError)
+	fs.StringVar(&cfg.Path, "config", cfg.Path, "path to a YAML config file (env RADAR_CONFIG)")
+	fs.StringVar(&cfg.EnvFile, "env-file", cfg.EnvFile, "path to a dotenv file (env RADAR_ENV_FILE)")
+	fs.BoolVar(&cfg.Check, "check", cfg.Check, "validate the configuration and radar data, then exit")
+	fs.StringVar(&cfg.Server.Listen, "listen", cfg.Server.Listen, "address to listen on, host:port or unix:///path/to.sock (env RADAR_LISTEN)")
+	fs.Func("port", "port to listen on; shorthand for -listen :PORT (env RADAR_PORT)", func(v string) error {
+		cfg.Server.Listen = portAddr(v)
+		return nil
+	})
+	fs.DurationVar(&cfg.Server.ShutdownTimeout, "shutdown-timeout", cfg.Server.ShutdownTimeout, "how long to wait for in-flight requests on shutdown (env RADAR_SHUTDOWN_TIMEOUT)")
+	fs.StringVar(&cfg.Server.TLS.Cert, "tls-cert", cfg.Server.TLS.Cert, "TLS certificate file; enables HTTPS (env RADAR_TLS_CERT)")
+	fs.StringVar(&cfg.Server.TLS.Key, "tls-key", cfg.Server.TLS.Key, "TLS private key file (env RADAR_TLS_KEY)")
+	fs.Func("acme-domain", "comma-separated domains to obtain Let's Encrypt certificates for; enables HTTPS (env RADAR_ACME_DOMAIN)", func(v string) error {
+		cfg.Server.TLS.ACME.Domains = splitList(v)
+		return nil
+	})
+	fs.StringVar(&cfg.Server.TLS.ACME.CacheDir, "acme-cache", cfg.Server.TLS.ACME.CacheDir, "directory where issued certificates are cached (env RADAR_ACME_CACHE)")
+	fs.StringVar(&cfg.Server.TLS.ACME.Email, "acme-email", cfg.Server.TLS.ACME.Email, "contact email for the ACME account (env RADAR_ACME_EMAIL)")
+	fs.StringVar(&cfg.Server.TLS.RedirectListen, "tls-redirect", cfg.Server.TLS.RedirectListen, "address of an HTTP listener that redirects to HTTPS, e.g. :80 (env RADAR_TLS_REDIRECT)")
+	fs.StringVar(&cfg.Server.GRPCListen, "grpc-listen", cfg.Server.GRPCListen, "address to serve the gRPC API on, e.g. :9090 (env RADAR_GRPC_LISTEN)")
+	fs.Func("cors-origins", "comma-separated origins allowed to call the API from the browser, or * for any (env RADAR_CORS_ORIGINS)", func(v string) error {
+		cfg.Server.CORS.AllowedOrigins = splitList(v)
+		return nil
+	})
+	fs.StringVar(&cfg.Server.BasePath, "base-path", cfg.Server.BasePath, "path prefix to mount all routes under, e.g. /tech-radar (env RADAR_BASE_PATH)")
+	fs.BoolVar(&cfg.Server.TrustProxy, "trust-proxy", cfg.Server.TrustProxy, "honor X-Forwarded-* headers from a reverse proxy (env RADAR_TRUST_PROXY)")
+	fs.BoolVar(&cfg.Dev, "dev", cfg.Dev, "development mode: re-parse templates on every request and disable caching (env RADAR_DEV)")
+	fs.StringVar(&cfg.Data.Path, "data", cfg.Data.Path, "radar data file (YAML, JSON or TOML), directory of data files, glob pattern, or HTTP(S) URL (env RADAR_DATA_PATH)")
+	fs.StringVar(&cfg.Data.Templates, "templates", cfg.Data.Templates, "directory of HTML templates overriding the embedded ones (env RADAR_TEMPLATES_PATH)")
+	fs.StringVar(&cfg.Data.Static, "static", cfg.Data.Static, "directory of static assets overriding the embedded ones (env RADAR_STATIC_PATH)")
+	return fs
+}
+
+// loadConfig builds the configuration from defaults, the config file,
+// environment variables (including those from the dotenv file) and
+// command-line flags, in increasing order of precedence.
+func loadConfig(args []string) (Config, error) {
+	// A first pass over the flags reports usage errors and tells us which
+	// files to read before anything else is applied.
+	probe := defaultConfig()
+	probe.Path = os.Getenv("RADAR_CONFIG")
+	probe.EnvFile = os.Getenv("RADAR_ENV_FILE")
+	if err := newFlagSet(&probe).Parse(args); err != nil {
+		return Config{}, err
+	}
+
+	env, err := loadEnvironment(probe.EnvFile)
+	if err != nil {
+		return Config{}, err
+	}
+	if probe.Path == "" {
+		probe.Path, _ = env.lookup("RADAR_CONFIG")
+	}
+
+	cfg := defaultConfig()
+	if probe.Path != "" {
+		if err := cfg.readFile(probe.Path); err != nil {
+			return Config{}, err
+		}
+	}
+
+	for _, v := range envVars {
+		if value, ok := env.lookup(v.name); ok && value != "" {
+			if err := v.set(&cfg, value); err != nil {
+				return Config{}, fmt.Errorf("invalid %s: %w", v.name, err)
+			}
+		}
+	}
+
+	flags := newFlagSet(&cfg)
+	flags.SetOutput(io.Discard)
+	if err := flags.Parse(args); err != nil {
+		return Config{}, err
+	}
+	cfg.Path = probe.Path
+	cfg.EnvFile = probe.EnvFile
+	cfg.Server.BasePath = normalizeBasePath(cfg.Server.BasePath)
+	if cfg.Dev {
+		cfg.Data.Templates, cfg.Data.Static = devAssetDir(cfg.Data.Templates, "templates"), devAssetDir(cfg.Data.Static, "static")
+	}
+
+	if err := cfg.validate(); err != nil {
+		return Config{}, err
+	}
+	return cfg, nil
+}
+
+// loadEnvironment reads the dotenv file at path and any secret files named
+// by RADAR_*_FILE variables. When no path is given the default .env is used
+// if it exists; an explicitly configured file must exist.
+func loadEnvironment(path string) (environment, error) {
+	explicit := path != ""
+	if !explicit {
+		path = defaultEnvFile
+	}
+
+	var env environment
+	values, err := readEnvFile(path)
+	switch {
+	case err == nil:
+		env.dotenv = values
+	case explicit || !errors.Is(err, fs.ErrNotExist):
+		return environment{}, fmt.Errorf("reading env file: %w", err)
+	}
+
+	if err := env.readSecretFiles(); err != nil {
+		return environment{}, err
+	}
+	return env, nil
+}
+
+// readFile overlays the settings of a YAML config file onto c. Unknown keys
+// are rejected so that typos don't go unnoticed.
+func (c *Config) readFile(path string) error {
+	file, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("reading config file: %w", err)
+	}
+
+	dec := yaml.NewDecoder(bytes.NewReader(file))
+	dec.KnownFields(true)
+	if err := dec.Decode(c); err != nil && !errors.Is(err, io.EOF) {
+		return fmt.Errorf("parsing config file %s: %w", path, err)
+	}
+	return nil
+}
+
+// validate checks that the configuration is usable before the server starts.
+func (c Config) validate() error {
+	var errs []error
+
+	if _, _, err := parseListenAddr(c.Server.Listen); err != nil {
+		errs = append(errs, err)
+	}
+	for name, d := range map[string]time.Duration{
+		"server.readTimeout":       c.Server.ReadTimeout,
+		"server.readHeaderTimeout": c.Server.ReadHeaderTimeout,
+		"server.writeTimeout":      c.Server.WriteTimeout,
+		"server.idleTimeout":       c.Server.IdleTimeout,
+		"server.requestTimeout":    c.Server.RequestTimeout,
+	} {
+		if d < 0 {
+			errs = append(errs, fmt.Errorf("%s must not be negative", name))
+		}
+	}
+	if c.Server.WriteTimeout > 0 && c.Server.RequestTimeout > c.Server.WriteTimeout {
+		errs = append(errs, fmt.Errorf("server.requestTimeout (%s) must not exceed server.writeTimeout (%s)", c.Server.RequestTimeout, c.Server.WriteTimeout))
+	}
+	if c.Server.ShutdownTimeout <= 0 {
+		errs = append(errs, fmt.Errorf("server.shutdownTimeout must be positive"))
+	}
+	if tls := c.Server.TLS; tls.enabled() {
+		if tls.ACME.enabled() {
+			if tls.Cert != "" || tls.Key != "" {
+				errs = append(errs, fmt.Errorf("server.tls.acme cannot be combined with server.tls.cert and server.tls.key"))
+			}
+			if tls.ACME.CacheDir == "" {
+				errs = append(errs, fmt.Errorf("server.tls.acme.cacheDir must be set"))
+			}
+			for _, domain := range tls.ACME.Domains {
+				if domain == "" || strings.ContainsAny(domain, "/: ") {
+					errs = append(errs, fmt.Errorf("invalid ACME domain %q", domain))
+				}
+			}
+		} else if tls.Cert == "" || tls.Key == "" {
+			errs = append(errs, fmt.Errorf("server.tls.cert and server.tls.key must be set together"))
+		} else {
+			if err := checkPath(tls.Cert, false); err != nil {
+				errs = append(errs, fmt.Errorf("TLS certificate: %w", err))
+			}
+			if err := checkPath(tls.Key, false); err != nil {
+				errs = append(errs, fmt.Errorf("TLS key: %w", err))
+			}
+		}
+		if tls.RedirectListen != "" {
+			if _, _, err := parseListenAddr(tls.RedirectListen); err != nil {
+				errs = append(errs, fmt.Errorf("TLS redirect: %w", err))
+			}
+		}
+	} else if c.Server.TLS.RedirectListen != "" {
+		errs = append(errs, fmt.Errorf("server.tls.redirectListen requires a TLS certificate"))
+	}
+	if c.Server.GRPCListen != "" {
+		if _, _, err := parseListenAddr(c.Server.GRPCListen); err != nil {
+			errs = append(errs, fmt.Errorf("gRPC: %w", err))
+		}
+	}
+	errs = append(errs, c.Server.CORS.validate()...)
+	for _, ancestor := range c.Server.FrameAncestors {
+		if !validFrameAncestor(ancestor) {
+			errs = append(errs, fmt.Errorf("invalid frame ancestor %q: must be * or look like https://acme.atlassian.net or https://*.example.com", ancestor))
+		}
+	}
+	if c.Server.UserHeader != "" && !c.Server.TrustProxy {
+		errs = append(errs, fmt.Errorf("server.userHeader requires server.trustProxy, as anyone could send it otherwise"))
+	}
+	if git := c.Data.Git; git.enabled() {
+		if git.Branch == "" || git.Dir == "" {
+			errs = append(errs, fmt.Errorf("data.git.branch and data.git.dir must be set when data.git.url is"))
+		}
+		if isRemoteDataPath(c.Data.Path) || filepath.IsAbs(c.Data.Path) {
+			errs = append(errs, fmt.Errorf("data.path %q must be relative to the Git repository", c.Data.Path))
+		}
+		if c.Data.PollInterval <= 0 {
+			errs = append(errs, fmt.Errorf("data.pollInterval must be positive, got %s", c.Data.PollInterval))
+		}
+		if _, err := exec.LookPath("git"); err != nil {
+			errs = append(errs, fmt.Errorf("data.git.url requires the git command: %w", err))
+		}
+		if prs := git.PullRequests; prs.enabled() {
+			if owner, name, ok := strings.Cut(prs.Repo, "/"); !ok || owner == "" || name == "" || strings.Contains(name, "/") {
+				errs = append(errs, fmt.Errorf("invalid data.git.pullRequests.repo %q, must look like acme/tech-radar", prs.Repo))
+			}
+			if prs.Token == "" {
+				errs = append(errs, fmt.Errorf("data.git.pullRequests.token must be set when data.git.pullRequests.repo is"))
+			}
+			if u, err := url.Parse(prs.APIURL); err != nil || (u.Scheme != "https" && u.Scheme != "http") || u.Host == "" {
+				errs = append(errs, fmt.Errorf("data.git.pullRequests.apiURL %q is not a valid URL", prs.APIURL))
+			}
+			if !isSingleDataFile(c.Data.dataPath()) {
+				errs = append(errs, fmt.Errorf("data.git.pullRequests requires data.path to name a single data file"))
+			}
+		}
+	} else if c.Data.Git.PullRequests.enabled() {
+		errs = append(errs, fmt.Errorf("data.git.pullRequests.repo requires data.git.url"))
+	} else if isRemoteDataPath(c.Data.Path) {
+		if u, err := url.Parse(c.Data.Path); err != nil || u.Host == "" {
+			errs = append(errs, fmt.Errorf("data.path %q is not a valid URL", c.Data.Path))
+		}
+		if c.Data.PollInterval <= 0 {
+			errs = append(errs, fmt.Errorf("data.pollInterval must be positive, got %s", c.Data.PollInterval))
+		}
+	} else if _, err := dataFiles(c.Data.Path); err != nil && !c.Store.enabled() {
+		// With a store, the data files are only read to seed it.
+		errs = append(errs, fmt.Errorf("data files: %w", err))
+	}
+	if c.Data.Commit.Enabled {
+		switch {
+		case c.Data.Git.enabled():
+			errs = append(errs, fmt.Errorf("data.commit cannot be combined with data.git.url, whose checkout is read-only"))
+		case c.Store.enabled():
+			errs = append(errs, fmt.Errorf("data.commit requires the data file to be written to, not store.driver"))
+		case !isSingleDataFile(c.Data.Path):
+			errs = append(errs, fmt.Errorf("data.commit requires data.path to name a single local data file"))
+		}
+		if _, err := exec.LookPath("git"); err != nil {
+			errs = append(errs, fmt.Errorf("data.commit requires the git command: %w", err))
+		}
+	}
+	if author := c.Data.Commit.AdminAuthor; author != "" {
+		if _, err := mail.ParseAddress(author); err != nil {
+			errs = append(errs, fmt.Errorf("invalid data.commit.adminAuthor %q, must look like Radar Admin <radar@example.com>", author))
+		}
+	}
+	errs = append(errs, checkRingConfig(c.Radar.Rings)...)
+	if c.Encryption.Key != "" {
+		if _, err := parseEncryptionKey(c.Encryption.Key); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	errs = append(errs, c.Backup.validate()...)
+	errs = append(errs, c.Review.validate(c.Admin.Token)...)
+	errs = append(errs, c.Sunset.validate()...)
+	errs = append(errs, c.Reminders.validate()...)
+	errs = append(errs, c.Notifications.validate()...)
+	if c.Store.enabled() {
+		if _, ok := storeDrivers[c.Store.Driver]; !ok {
+			errs = append(errs, fmt.Errorf("invalid store driver %q, must be one of %s", c.Store.Driver, strings.Join(storeDriverNames(), ", ")))
+		}
+		if c.Store.DSN == "" {
+			errs = append(errs, fmt.Errorf("store.dsn must be set when store.driver is"))
+		}
+		if c.Store.MaxOpenConns < 1 {
+			errs = append(errs, fmt.Errorf("store.maxOpenConns must be at least 1, got %d", c.Store.MaxOpenConns))
+		}
+		if c.Store.MaxIdleConns < 0 || c.Store.ConnMaxLifetime < 0 {
+			errs = append(errs, fmt.Errorf("store.maxIdleConns and store.connMaxLifetime must not be negative"))
+		}
+	}
+	if c.Cache.enabled() {
+		// The parse error would include the URL and its password.
+		if _, err := redis.ParseURL(c.Cache.RedisURL); err != nil {
+			errs = append(errs, fmt.Errorf("cache.redisURL is not a valid redis:// or rediss:// URL"))
+		}
+		if c.Cache.TTL <= 0 {
+			errs = append(errs, fmt.Errorf("cache.ttl must be positive, got %s", c.Cache.TTL))
+		}
+	}
+	if c.Features.UI && !c.Features.API {
+		errs = append(errs, fmt.Errorf("features.ui requires features.api, the UI loads its data from /api/radar"))
+	}
+	if c.Features.UI && c.Data.Templates != "" {
+		if err := checkPath(filepath.Join(c.Data.Templates, indexTemplate), false); err != nil {
+			errs = append(errs, fmt.Errorf("templates: %w", err))
+		}
+	}
+	if c.Features.UI && c.Data.Static != "" {
+		if err := checkPath(c.Data.Static, true); err != nil {
+			errs = append(errs, fmt.Errorf("static directory: %w", err))
+		}
+	}
+	if p := c.Server.BasePath; p != "" && (!strings.HasPrefix(p, "/") || strings.HasSuffix(p, "/") || strings.ContainsAny(p, "?#% ")) {
+		errs = append(errs, fmt.Errorf("invalid base path %q: must look like /tech-radar", p))
+	}
+	if _, err := parseLogLevel(c.Logging.Level); err != nil {
+		errs = append(errs, err)
+	}
+	if c.Logging.Format != "text" && c.Logging.Format != "json" {
+		errs = append(errs, fmt.Errorf("invalid log format %q: must be text or json", c.Logging.Format))
+	}
+
+	return errors.Join(errs...)
+}
+
+// settings flattens the configuration into "key=value" pairs, using the
+// config file key names, in declaration order.
+func (c Config) settings() []setting {
+	var out []setting
+	flattenValue("", reflect.ValueOf(c), &out)
+	return out
+}
+
+// setting is a single flattened configuration value.
+type setting struct {
+	Key   string
+	Value string
+	// Secret marks values such as tokens that must not be logged.
+	Secret bool
+}
+
+// String returns the value for logging, redacting secrets.
+func (s setting) String() string {
+	if s.Secret && s.Value != "" {
+		return "[redacted]"
+	}
+	return s.Value
+}
+
+func flattenValue(prefix string, v reflect.Value, out *[]setting) {
+	if v.Kind() != reflect.Struct {
+		*out = append(*out, setting{Key: prefix, Value: fmt.Sprint(v.Interface())})
+		return
+	}
+
+	for i := 0; i < v.NumField(); i++ {
+		field := v.Type().Field(i)
+		name := strings.Split(field.Tag.Get("yaml"), ",")[0]
+		if name == "-" || !field.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = strings.ToLower(field.Name)
+		}
+		if prefix != "" {
+			name = prefix + "." + name
+		}
+		flattenValue(name, v.Field(i), out)
+		if field.Tag.Get("secret") == "true" {
+			(*out)[len(*out)-1].Secret = true
+		}
+	}
+}
+
+// summary returns the effective configuration as a single line for logging.
+func (c Config) summary() string {
+	var parts []string
+	for _, s := range c.settings() {
+		parts = append(parts, s.Key+"="+s.String())
+	}
+	return strings.Join(parts, " ")
+}
+
+// logger returns a logger writing to stderr with the configured options.
+func (c Config) logger() *slog.Logger {
+	level, _ := parseLogLevel(c.Logging.Level)
+	opts := &slog.HandlerOptions{Level: level}
+	if c.Logging.Format == "json" {
+		return slog.New(slog.NewJSONHandler(os.Stderr, opts))
+	}
+	return slog.New(slog.NewTextHandler(os.Stderr, opts))
+}
+
+// parseLogLevel converts a level name such as "debug" into a slog.Level.
+func parseLogLevel(name string) (slog.Level, error) {
+	var level slog.Level
+	if err := level.UnmarshalText([]byte(name)); err != nil {
+		return 0, fmt.Errorf("invalid log level %q: must be debug, info, warn or error", name)
+	}
+	return level, nil
+}
+
+// devAssetDir returns dir, or in development mode the source directory of
+// the embedded assets when dir is unset and it exists in the working
+// directory, so that edits are picked up without rebuilding.
+func devAssetDir(dir, source string) string {
+	if dir != "" {
+		return dir
+	}
+	if info, err := os.Stat(source); err == nil && info.IsDir() {
+		return source
+	}
+	return ""
+}
+
+// normalizeBasePath adds a leading slash to a base path and removes any
+// trailing ones, so "tech-radar/" becomes "/tech-radar" and "/" becomes "".
+func normalizeBasePath(p string) string {
+	p = strings.TrimRight(p, "/")
+	if p != "" && !strings.HasPrefix(p, "/") {
+		p = "/" + p
+	}
+	return p
+}
+
+// splitList splits a comma-separated value, trimming spaces and dropping
+// empty entries.
+func splitList(v string) []string {
+	var out []string
+	for _, item := range strings.Split(v, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			out = append(out, item)
+		}
+	}
+	return out
+}
+
+// portAddr turns a bare port such as "8080" into a listen address.
+func portAddr(port string) string {
+	return ":" + strings.TrimPrefix(port, ":")
+}
+
+// checkPath verifies that path exists and is a directory or a regular file.
+func checkPath(path string, wantDir bool) error {
+	info, err := os.Stat(path)
+	if err != nil {
+		return err
+	}
+	if wantDir && !info.IsDir() {
+		return fmt.Errorf("%s is not a directory", path)
+	}
+	if !wantDir && info.IsDir() {
+		return fmt.Errorf("%s is a directory", path)
+	}
+	return nil
+}
+
+// validate reports the problems of the CORS settings.
+func (c CORSConfig) validate() []error {
+	var errs []error
+	for _, origin := range c.AllowedOrigins {
+		if origin == "*" {
+			continue
+		}
+		u, err := url.Parse(origin)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" || u.Path != "" || u.RawQuery != "" || u.Fragment != "" || u.User != nil {
+			errs = append(errs, fmt.Errorf("invalid CORS origin %q: must be * or look like https://dashboard.example.com", origin))
+		}
+	}
+	for _, method := range c.AllowedMethods {
+		if method == "" || strings.ContainsAny(method, ", ") || method != strings.ToUpper(method) {
+			errs = append(errs, fmt.Errorf("invalid CORS method %q: must be an uppercase method such as GET", method))
+		}
+	}
+	for _, header := range c.AllowedHeaders {
+		if header == "" || strings.ContainsAny(header, ", :") {
+			errs = append(errs, fmt.Errorf("invalid CORS header %q", header))
+		}
+	}
+	if c.MaxAge < 0 {
+		errs = append(errs, fmt.Errorf("server.cors.maxAge must not be negative"))
+	}
+	return errs
+}
+
+// validFrameAncestor reports whether ancestor is *, or an origin whose
+// host may start with a *. wildcard, as CSP frame-ancestors sources are.
+func validFrameAncestor(ancestor string) bool {
+	if ancestor == "*" {
+		return true
+	}
+	u, err := url.Parse(strings.Replace(ancestor, "://*.", "://wildcard.", 1))
+	return err == nil && (u.Scheme == "http" || u.Scheme == "https") && u.Host != "" && u.Path == "" && u.RawQuery == "" && u.Fragment == "" && u.User == nil
+}
+
+// validate reports the problems of the backup settings.
+func (b BackupConfig) validate() []error {
+	var errs []error
+	switch {
+	case b.Interval < 0:
+		errs = append(errs, fmt.Errorf("backup.interval must not be negative, got %s", b.Interval))
+	case b.Interval == 0 && (b.Dir != "" || b.S3.Bucket != ""):
+		errs = append(errs, errors.New("backup.interval must be set when backup.dir or backup.s3.bucket is"))
+	case b.Interval > 0 && b.Dir == "" && b.S3.Bucket == "":
+		errs = append(errs, errors.New("backup.interval requires backup.dir or backup.s3.bucket"))
+	}
+	if b.Format != backupYAML && b.Format != backupArchive {
+		errs = append(errs, fmt.Errorf("invalid backup.format %q, must be %s or %s", b.Format, backupYAML, backupArchive))
+	}
+	if b.Keep < 0 {
+		errs = append(errs, fmt.Errorf("backup.keep must not be negative, got %d", b.Keep))
+	}
+	if b.MaxAge < 0 {
+		errs = append(errs, fmt.Errorf("backup.maxAge must not be negative, got %s", b.MaxAge))
+	}
+	if b.S3.Bucket != "" {
+		if b.S3.Region == "" {
+			errs = append(errs, errors.New("backup.s3.region must be set"))
+		}
+		if b.S3.AccessKeyID == "" || b.S3.SecretAccessKey == "" {
+			errs = append(errs, errors.New("backup.s3.accessKeyID and backup.s3.secretAccessKey must be set"))
+		}
+		if b.S3.Endpoint != "" {
+			if u, err := url.Parse(b.S3.Endpoint); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+				errs = append(errs, fmt.Errorf("backup.s3.endpoint %q is not a valid http(s) URL", b.S3.Endpoint))
+			}
+		}
+	}
+	return errs
+}
+
+// validate reports the problems of the review settings, given the admin
+// token, which no reviewer may share.
+func (r ReviewConfig) validate(adminToken string) []error {
+	var errs []error
+	names := make(map[string]bool, len(r.Reviewers))
+	tokens := make(map[string]bool, len(r.Reviewers))
+	roles := make(map[string]bool)
+	for i, reviewer := range r.Reviewers {
+		switch {
+		case strings.TrimSpace(reviewer.Name) == "":
+			errs = append(errs, fmt.Errorf("review.reviewers[%d].name must be set", i))
+		case names[strings.ToLower(reviewer.Name)]:
+			errs = append(errs, fmt.Errorf("review.reviewers[%d]: duplicate reviewer %q", i, reviewer.Name))
+		}
+		names[strings.ToLower(reviewer.Name)] = true
+		// The error names neither tokens nor whom they belong to.
+		switch {
+		case reviewer.Token == "":
+			errs = append(errs, fmt.Errorf("review.reviewers[%d].token must be set", i))
+		case tokens[reviewer.Token] || reviewer.Token == adminToken:
+			errs = append(errs, fmt.Errorf("review.reviewers[%d].token is already used", i))
+		}
+		tokens[reviewer.Token] = true
+		if reviewer.Email != "" {
+			if addr, err := mail.ParseAddress(reviewer.Email); err != nil || addr.Address != reviewer.Email {
+				errs = append(errs, fmt.Errorf("review.reviewers[%d]: invalid email %q", i, reviewer.Email))
+			}
+		}
+		for _, role := range reviewer.Roles {
+			roles[role] = true
+		}
+	}
+	for _, role := range r.RequiredRoles {
+		if !roles[role] && len(r.Reviewers) > 0 {
+			errs = append(errs, fmt.Errorf("review.requiredRoles: no reviewer has the role %q", role))
+		}
+	}
+	return errs
+}
+
+// validate reports the problems of the sunset settings.
+func (s SunsetConfig) validate() []error {
+	var errs []error
+	if s.Interval < 0 {
+		errs = append(errs, fmt.Errorf("sunset.interval must not be negative, got %s", s.Interval))
+	}
+	if s.Notice < 0 {
+		errs = append(errs, fmt.Errorf("sunset.notice must not be negative, got %s", s.Notice))
+	}
+	if s.MoveTo != "" && !s.enabled() {
+		errs = append(errs, errors.New("sunset.moveTo requires sunset.interval"))
+	}
+	return errs
+}
+
+// validate reports the problems of the review reminder settings.
+func (r ReminderConfig) validate() []error {
+	var errs []error
+	if r.Interval < 0 {
+		errs = append(errs, fmt.Errorf("reminders.interval must not be negative, got %s", r.Interval))
+	}
+	if r.Notice < 0 {
+		errs = append(errs, fmt.Errorf("reminders.notice must not be negative, got %s", r.Notice))
+	}
+	return errs
+}
+
+// validate reports the problems of the notification settings.
+func (n NotificationConfig) validate() []error {
+	var errs []error
+	for _, setting := range [][2]string{{"notifications.webhook", n.Webhook}, {"notifications.slack", n.Slack}} {
+		if u, err := url.Parse(setting[1]); setting[1] != "" && (err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "") {
+			// The URL may hold a token, so it isn't shown.
+			errs = append(errs, fmt.Errorf("%s is not a valid http(s) URL", setting[0]))
+		}
+	}
+	if e := n.Email; e.SMTP != "" {
+		if _, _, err := net.SplitHostPort(e.SMTP); err != nil {
+			errs = append(errs, fmt.Errorf("notifications.email.smtp must be a host:port, got %q", e.SMTP))
+		}
+		if _, err := mail.ParseAddress(e.From); err != nil {
+			errs = append(errs, fmt.Errorf("notifications.email.from must be an email address, got %q", e.From))
+		}
+	}
+	return errs
+}
@@ -0,0 +1,311 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// clearRadarEnv unsets every RADAR_* variable for the duration of the test
+// so that the developer's environment doesn't leak into config tests.
+func clearRadarEnv(t *testing.T) {
+	t.Helper()
+	for _, kv := range os.Environ() {
+		name, _, _ := strings.Cut(kv, "=")
+		if strings.HasPrefix(name, "RADAR_") {
+			t.Setenv(name, "")
+			os.Unsetenv(name)
+		}
+	}
+}
+
+// writeFile writes content to name inside a temporary directory and returns
+// its path.
+func writeFile(t *testing.T, name, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoadConfigPrecedence(t *testing.T) {
+	configFile := "server:\n  listen: \":7001\"\n  shutdownTimeout: 3s\nlogging:\n  level: warn\n"
+
+	tests := []struct {
+		name       string
+		config     string
+		dotenv     string
+		env        map[string]string
+		args       []string
+		wantListen string
+		wantLevel  string
+	}{
+		{name: "defaults", wantListen: ":8080", wantLevel: "info"},
+		{name: "config file", config: configFile, wantListen: ":7001", wantLevel: "warn"},
+		{
+			name:       "dotenv over file",
+			config:     configFile,
+			dotenv:     "RADAR_PORT=7002\n",
+			wantListen: ":7002", wantLevel: "warn",
+		},
+		{
+			name:       "env over dotenv",
+			config:     configFile,
+			dotenv:     "RADAR_PORT=7002\n",
+			env:        map[string]string{"RADAR_PORT": "7003"},
+			wantListen: ":7003", wantLevel: "warn",
+		},
+		{
+			name:       "flag over env",
+			config:     configFile,
+			env:        map[string]string{"RADAR_PORT": "7003", "RADAR_LOG_LEVEL": "debug"},
+			args:       []string{"-port", "7004"},
+			wantListen: ":7004", wantLevel: "debug",
+		},
+		{
+			name:       "listen env over port env",
+			env:        map[string]string{"RADAR_LISTEN": "127.0.0.1:7005", "RADAR_PORT": "7006"},
+			wantListen: "127.0.0.1:7005", wantLevel: "info",
+		},
+		{
+			name:       "last flag wins",
+			args:       []string{"-listen", ":7007", "-port", "7008"},
+			wantListen: ":7008", wantLevel: "info",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clearRadarEnv(t)
+			for k, v := range tt.env {
+				t.Setenv(k, v)
+			}
+			args := tt.args
+			if tt.config != "" {
+				args = append([]string{"-config", writeFile(t, "config.yaml", tt.config)}, args...)
+			}
+			if tt.dotenv != "" {
+				args = append([]string{"-env-file", writeFile(t, ".env", tt.dotenv)}, args...)
+			}
+
+			cfg, err := loadConfig(args)
+			if err != nil {
+				t.Fatalf("loadConfig() error = %v", err)
+			}
+			if cfg.Server.Listen != tt.wantListen {
+				t.Errorf("Server.Listen = %q, want %q", cfg.Server.Listen, tt.wantListen)
+			}
+			if cfg.Logging.Level != tt.wantLevel {
+				t.Errorf("Logging.Level = %q, want %q", cfg.Logging.Level, tt.wantLevel)
+			}
+		})
+	}
+}
+
+func TestLoadConfigErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		args    []string
+		env     map[string]string
+		wantErr string
+	}{
+		{name: "unknown flag", args: []string{"-nope"}, wantErr: "flag provided but not defined"},
+		{name: "missing config file", args: []string{"-config", "does-not-exist.yaml"}, wantErr: "reading config file"},
+		{name: "missing env file", args: []string{"-env-file", "does-not-exist.env"}, wantErr: "reading env file"},
+		{name: "bad duration env", env: map[string]string{"RADAR_REQUEST_TIMEOUT": "soon"}, wantErr: "invalid RADAR_REQUEST_TIMEOUT"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clearRadarEnv(t)
+			for k, v := range tt.env {
+				t.Setenv(k, v)
+			}
+			_, err := loadConfig(tt.args)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("loadConfig() error = %v, want it to contain %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestSplitList(t *testing.T) {
+	got := splitList(" a.example.com, ,b.example.com ,")
+	want := []string{"a.example.com", "b.example.com"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("splitList() = %q, want %q", got, want)
+	}
+}
+
+func TestNormalizeBasePath(t *testing.T) {
+	for in, want := range map[string]string{
+		"":             "",
+		"/":            "",
+		"tech-radar":   "/tech-radar",
+		"/tech-radar/": "/tech-radar",
+		"/a/b//":       "/a/b",
+	} {
+		if got := normalizeBasePath(in); got != want {
+			t.Errorf("normalizeBasePath(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestReadFileRejectsUnknownKeys(t *testing.T) {
+	cfg := defaultConfig()
+	err := cfg.readFile(writeFile(t, "config.yaml", "server:\n  lisen: \":1\"\n"))
+	if err == nil || !strings.Contains(err.Error(), "lisen") {
+		t.Fatalf("readFile() error = %v, want unknown field error", err)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		modify  func(*Config)
+		wantErr string
+	}{
+		{name: "defaults", modify: func(*Config) {}},
+		{name: "bad listen", modify: func(c *Config) { c.Server.Listen = "8080" }, wantErr: "invalid listen address"},
+		{name: "port out of range", modify: func(c *Config) { c.Server.Listen = ":70000" }, wantErr: "invalid port"},
+		{name: "negative timeout", modify: func(c *Config) { c.Server.IdleTimeout = -time.Second }, wantErr: "server.idleTimeout must not be negative"},
+		{name: "zero shutdown timeout", modify: func(c *Config) { c.Server.ShutdownTimeout = 0 }, wantErr: "server.shutdownTimeout must be positive"},
+		{
+			name: "request timeout over write timeout",
+			modify: func(c *Config) {
+				c.Server.WriteTimeout = 10 * time.Second
+				c.Server.RequestTimeout = 20 * time.Second
+			},
+			wantErr: "server.requestTimeout (20s) must not exceed server.writeTimeout (10s)",
+		},
+		{name: "write timeout alone", modify: func(c *Config) { c.Server.WriteTimeout = 10 * time.Second }},
+		{name: "tls cert without key", modify: func(c *Config) { c.Server.TLS.Cert = "cert.pem" }, wantErr: "must be set together"},
+		{name: "acme", modify: func(c *Config) { c.Server.TLS.ACME.Domains = []string{"radar.example.com"} }},
+		{
+			name: "acme with cert files",
+			modify: func(c *Config) {
+				c.Server.TLS.ACME.Domains = []string{"radar.example.com"}
+				c.Server.TLS.Cert, c.Server.TLS.Key = "cert.pem", "key.pem"
+			},
+			wantErr: "cannot be combined",
+		},
+		{
+			name: "acme without cache",
+			modify: func(c *Config) {
+				c.Server.TLS.ACME.Domains = []string{"radar.example.com"}
+				c.Server.TLS.ACME.CacheDir = ""
+			},
+			wantErr: "cacheDir must be set",
+		},
+		{name: "acme bad domain", modify: func(c *Config) { c.Server.TLS.ACME.Domains = []string{"https://x"} }, wantErr: "invalid ACME domain"},
+		{name: "redirect without tls", modify: func(c *Config) { c.Server.TLS.RedirectListen = ":80" }, wantErr: "requires a TLS certificate"},
+		{name: "grpc", modify: func(c *Config) { c.Server.GRPCListen = ":9090" }},
+		{name: "grpc bad address", modify: func(c *Config) { c.Server.GRPCListen = "9090" }, wantErr: "gRPC: invalid listen address"},
+		{name: "cors", modify: func(c *Config) {
+			c.Server.CORS.AllowedOrigins = []string{"https://dashboard.example.com", "http://localhost:3000"}
+		}},
+		{name: "cors any origin", modify: func(c *Config) { c.Server.CORS.AllowedOrigins = []string{"*"} }},
+		{name: "cors origin with path", modify: func(c *Config) { c.Server.CORS.AllowedOrigins = []string{"https://example.com/"} }, wantErr: "invalid CORS origin"},
+		{name: "cors lowercase method", modify: func(c *Config) { c.Server.CORS.AllowedMethods = []string{"get"} }, wantErr: "invalid CORS method"},
+		{name: "frame ancestors", modify: func(c *Config) {
+			c.Server.FrameAncestors = []string{"https://acme.atlassian.net", "https://*.backstage.example.com"}
+		}},
+		{name: "frame ancestor with path", modify: func(c *Config) { c.Server.FrameAncestors = []string{"https://acme.atlassian.net/wiki"} }, wantErr: "invalid frame ancestor"},
+		{name: "user header", modify: func(c *Config) { c.Server.TrustProxy, c.Server.UserHeader = true, "X-Forwarded-User" }},
+		{name: "user header without proxy", modify: func(c *Config) { c.Server.UserHeader = "X-Forwarded-User" }, wantErr: "server.userHeader requires server.trustProxy"},
+		{name: "cors negative max age", modify: func(c *Config) { c.Server.CORS.MaxAge = -time.Second }, wantErr: "server.cors.maxAge must not be negative"},
+		{name: "missing data file", modify: func(c *Config) { c.Data.Path = "missing.yaml" }, wantErr: "data files"},
+		{name: "data url", modify: func(c *Config) { c.Data.Path = "https://example.com/radar.yaml" }},
+		{name: "data url without host", modify: func(c *Config) { c.Data.Path = "https:///radar.yaml" }, wantErr: "not a valid URL"},
+		{name: "data url without poll interval", modify: func(c *Config) { c.Data.Path, c.Data.PollInterval = "https://example.com/radar.yaml", 0 }, wantErr: "pollInterval must be positive"},
+		{name: "postgres store", modify: func(c *Config) { c.Store.Driver, c.Store.DSN = "postgres", "postgres://radar@db/radar" }},
+		{name: "unknown store driver", modify: func(c *Config) { c.Store.Driver, c.Store.DSN = "mysql", "radar" }, wantErr: "must be one of bbolt, postgres, sqlite"},
+		{name: "store without connections", modify: func(c *Config) {
+			c.Store.Driver, c.Store.DSN, c.Store.MaxOpenConns = "postgres", "postgres://db/radar", 0
+		}, wantErr: "store.maxOpenConns must be at least 1"},
+		{name: "static is a file", modify: func(c *Config) { c.Data.Static = "main.go" }, wantErr: "is not a directory"},
+		{name: "ui without api", modify: func(c *Config) { c.Features.API = false }, wantErr: "features.ui requires features.api"},
+		{name: "missing templates", modify: func(c *Config) { c.Data.Templates = "missing" }, wantErr: "templates"},
+		{name: "api only", modify: func(c *Config) { c.Features.UI, c.Data.Static = false, "missing" }},
+		{name: "base path", modify: func(c *Config) { c.Server.BasePath = "/tech-radar" }},
+		{name: "base path with query", modify: func(c *Config) { c.Server.BasePath = "/radar?x" }, wantErr: "invalid base path"},
+		{name: "bad log level", modify: func(c *Config) { c.Logging.Level = "loud" }, wantErr: "invalid log level"},
+		{name: "bad log format", modify: func(c *Config) { c.Logging.Format = "xml" }, wantErr: "invalid log format"},
+		{name: "rings", modify: func(c *Config) {
+			c.Radar.Rings = []RingConfig{{Name: "Adopt", Color: "#0a0", MovesTo: []string{"Retire"}}, {Name: "Retire"}}
+		}},
+		{name: "ring without name", modify: func(c *Config) { c.Radar.Rings = []RingConfig{{Color: "#0a0"}} }, wantErr: "radar.rings[0].name must be set"},
+		{name: "ring moving to unknown ring", modify: func(c *Config) {
+			c.Radar.Rings = []RingConfig{{Name: "Adopt", MovesTo: []string{"Hold"}}}
+		}, wantErr: `radar.rings[0].movesTo: unknown ring "Hold"`},
+		{name: "encryption key", modify: func(c *Config) { c.Encryption.Key = strings.Repeat("ab", 32) }},
+		{name: "short encryption key", modify: func(c *Config) { c.Encryption.Key = "secret" }, wantErr: "encryption.key must be 32 bytes"},
+		{name: "backups", modify: func(c *Config) {
+			c.Backup.Interval, c.Backup.Dir = 24*time.Hour, "backups"
+			c.Backup.S3 = S3Config{Bucket: "radar", Region: "eu-west-1", Endpoint: "http://minio:9000", AccessKeyID: "key", SecretAccessKey: "secret"}
+		}},
+		{name: "backups without target", modify: func(c *Config) { c.Backup.Interval = time.Hour }, wantErr: "backup.interval requires backup.dir or backup.s3.bucket"},
+		{name: "sunsets", modify: func(c *Config) {
+			c.Sunset.Interval, c.Sunset.MoveTo = time.Hour, "Not Recommended"
+			c.Notifications = NotificationConfig{Webhook: "https://hooks.example.com/radar", Slack: "https://hooks.slack.com/services/T0/B0/x"}
+		}},
+		{name: "sunset move without checks", modify: func(c *Config) { c.Sunset.MoveTo = "Hold" }, wantErr: "sunset.moveTo requires sunset.interval"},
+		{name: "review reminders", modify: func(c *Config) {
+			c.Reminders.Interval = 24 * time.Hour
+			c.Notifications.Email = EmailConfig{SMTP: "smtp.example.com:587", From: "Tech Radar <radar@example.com>", Username: "radar", Password: "secret"}
+		}},
+		{name: "negative reminder notice", modify: func(c *Config) { c.Reminders.Notice = -time.Hour }, wantErr: "reminders.notice must not be negative"},
+		{name: "smtp without port", modify: func(c *Config) {
+			c.Notifications.Email = EmailConfig{SMTP: "smtp.example.com", From: "radar@example.com"}
+		}, wantErr: "notifications.email.smtp must be a host:port"},
+		{name: "smtp without sender", modify: func(c *Config) { c.Notifications.Email.SMTP = "smtp.example.com:25" }, wantErr: "notifications.email.from must be an email address"},
+		{name: "bad notification webhook", modify: func(c *Config) { c.Notifications.Slack = "hooks.slack.com/s3cret" }, wantErr: "notifications.slack is not a valid http(s) URL"},
+		{name: "backup dir without interval", modify: func(c *Config) { c.Backup.Dir = "backups" }, wantErr: "backup.interval must be set"},
+		{name: "bad backup format", modify: func(c *Config) { c.Backup.Format = "zip" }, wantErr: `invalid backup.format "zip"`},
+		{name: "reviewers", modify: func(c *Config) {
+			c.Review.Reviewers = []ReviewerConfig{{Name: "Jane", Token: "jane-token", Roles: []string{"architect"}}}
+			c.Review.RequiredRoles = []string{"architect"}
+		}},
+		{name: "reviewer without token", modify: func(c *Config) { c.Review.Reviewers = []ReviewerConfig{{Name: "Jane"}} }, wantErr: "review.reviewers[0].token must be set"},
+		{name: "reviewer with admin token", modify: func(c *Config) {
+			c.Admin.Token = "s3cret"
+			c.Review.Reviewers = []ReviewerConfig{{Name: "Jane", Token: "s3cret"}}
+		}, wantErr: "review.reviewers[0].token is already used"},
+		{name: "reviewer with invalid email", modify: func(c *Config) {
+			c.Review.Reviewers = []ReviewerConfig{{Name: "Jane", Email: "Jane <jane@example.com>", Token: "a"}}
+		}, wantErr: `review.reviewers[0]: invalid email "Jane <jane@example.com>"`},
+		{name: "commit with store", modify: func(c *Config) {
+			c.Data.Commit.Enabled = true
+			c.Store = StoreConfig{Driver: "sqlite", DSN: "radar.db", MaxOpenConns: 1}
+		}, wantErr: "data.commit requires the data file to be written to, not store.driver"},
+		{name: "pull requests without git", modify: func(c *Config) { c.Data.Git.PullRequests.Repo = "acme/radar" }, wantErr: "data.git.pullRequests.repo requires data.git.url"},
+		{name: "invalid commit admin author", modify: func(c *Config) { c.Data.Commit.AdminAuthor = "Radar Admin" }, wantErr: `invalid data.commit.adminAuthor "Radar Admin"`},
+		{name: "duplicate reviewer", modify: func(c *Config) {
+			c.Review.Reviewers = []ReviewerConfig{{Name: "Jane", Token: "a"}, {Name: "jane", Token: "b"}}
+		}, wantErr: `review.reviewers[1]: duplicate reviewer "jane"`},
+		{name: "required role nobody has", modify: func(c *Config) {
+			c.Review.Reviewers = []ReviewerConfig{{Name: "Jane", Token: "a", Roles: []string{"architect"}}}
+			c.Review.RequiredRoles = []string{"security"}
+		}, wantErr: `no reviewer has the role "security"`},
+		{name: "s3 without credentials", modify: func(c *Config) { c.Backup.Interval, c.Backup.S3.Bucket = time.Hour, "radar" }, wantErr: "backup.s3.accessKeyID and backup.s3.secretAccessKey must be set"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := defaultConfig()
+			tt.modify(&cfg)
+			err := cfg.validate()
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("validate() error = %v, want nil", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("validate() error = %v, want it to contain %q", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,70 @@
+package main
+
+import (
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// corsExposedHeaders are the response headers of the API, besides the
+// CORS-safelisted ones, that scripts on other origins may read.
+var corsExposedHeaders = []string{"ETag", "Link", "X-Total-Count", "Deprecation"}
+
+// enabled reports whether requests from other origins are allowed.
+func (c CORSConfig) enabled() bool {
+	return len(c.AllowedOrigins) > 0
+}
+
+// allowsOrigin reports whether scripts on origin may call the API.
+func (c CORSConfig) allowsOrigin(origin string) bool {
+	return slices.Contains(c.AllowedOrigins, "*") || slices.Contains(c.AllowedOrigins, origin)
+}
+
+// corsHandler serves next, letting scripts on the allowed origins of cfg
+// call the API and the GraphQL endpoint. Preflight requests are answered
+// here, without reaching next.
+func corsHandler(cfg CORSConfig, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !isAPIPath(r.URL.Path) && r.URL.Path != "/graphql" {
+			next.ServeHTTP(w, r)
+			return
+		}
+		origin := r.Header.Get("Origin")
+		preflight := r.Method == http.MethodOptions && r.Header.Get("Access-Control-Request-Method") != ""
+		// Responses differ by origin unless every origin is allowed.
+		if !slices.Contains(cfg.AllowedOrigins, "*") {
+			w.Header().Add("Vary", "Origin")
+		}
+		allowed := origin != "" && cfg.allowsOrigin(origin)
+		if allowed {
+			if slices.Contains(cfg.AllowedOrigins, "*") {
+				w.Header().Set("Access-Control-Allow-Origin", "*")
+			} else {
+				w.Header().Set("Access-Control-Allow-Origin", origin)
+			}
+		}
+		if !preflight {
+			if allowed {
+				w.Header().Set("Access-Control-Expose-Headers", strings.Join(corsExposedHeaders, ", "))
+			}
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		// Without the CORS headers, the browser refuses the request.
+		w.Header().Add("Vary", "Access-Control-Request-Method, Access-Control-Request-Headers")
+		if allowed && slices.Contains(cfg.AllowedMethods, r.Header.Get("Access-Control-Request-Method")) {
+			w.Header().Set("Access-Control-Allow-Methods", strings.Join(cfg.AllowedMethods, ", "))
+			if len(cfg.AllowedHeaders) > 0 {
+				w.Header().Set("Access-Control-Allow-Headers", strings.Join(cfg.AllowedHeaders, ", "))
+			}
+			if cfg.MaxAge > 0 {
+				w.Header().Set("Access-Control-Max-Age", strconv.Itoa(int(cfg.MaxAge.Seconds())))
+			}
+		} else {
+			w.Header().Del("Access-Control-Allow-Origin")
+		}
+		w.WriteHeader(http.StatusNoContent)
+	})
+}
@@ -0,0 +1,112 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+)
+
+func TestCORS(t *testing.T) {
+	cfg := defaultConfig()
+	cfg.Server.BasePath = "/tech-radar"
+	cfg.Server.CORS.AllowedOrigins = []string{"https://dashboard.example.com"}
+	cfg.Data.Path = writeFile(t, "radar.yaml", radarWith("Go", "Adopted"))
+	useConfig(t, cfg)
+	handler, err := setupRoutes(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	request := func(method, target, origin string, headers ...string) *httptest.ResponseRecorder {
+		t.Helper()
+		req := httptest.NewRequest(method, target, nil)
+		if origin != "" {
+			req.Header.Set("Origin", origin)
+		}
+		for i := 0; i < len(headers); i += 2 {
+			req.Header.Set(headers[i], headers[i+1])
+		}
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		return rec
+	}
+
+	rec := request(http.MethodGet, "/tech-radar/api/v1/radar/items", "https://dashboard.example.com")
+	if rec.Code != http.StatusOK || rec.Header().Get("Access-Control-Allow-Origin") != "https://dashboard.example.com" {
+		t.Errorf("GET from an allowed origin = %d, Access-Control-Allow-Origin %q", rec.Code, rec.Header().Get("Access-Control-Allow-Origin"))
+	}
+	if got := rec.Header().Get("Access-Control-Expose-Headers"); got != "ETag, Link, X-Total-Count, Deprecation" {
+		t.Errorf("Access-Control-Expose-Headers = %q", got)
+	}
+	if vary := rec.Header().Values("Vary"); !slices.Contains(vary, "Origin") {
+		t.Errorf("Vary = %q, want Origin", vary)
+	}
+
+	// Other origins, and requests to the page, get no CORS headers.
+	for _, tt := range []struct{ target, origin string }{
+		{"/tech-radar/api/v1/radar", "https://evil.example.com"},
+		{"/tech-radar/", "https://dashboard.example.com"},
+	} {
+		if rec := request(http.MethodGet, tt.target, tt.origin); rec.Code != http.StatusOK || rec.Header().Get("Access-Control-Allow-Origin") != "" {
+			t.Errorf("GET %s from %s = %d, Access-Control-Allow-Origin %q, want none", tt.target, tt.origin, rec.Code, rec.Header().Get("Access-Control-Allow-Origin"))
+		}
+	}
+
+	rec = request(http.MethodOptions, "/tech-radar/api/v1/radar", "https://dashboard.example.com", "Access-Control-Request-Method", "GET", "Access-Control-Request-Headers", "accept")
+	for name, want := range map[string]string{
+		"Access-Control-Allow-Origin":  "https://dashboard.example.com",
+		"Access-Control-Allow-Methods": "GET, HEAD",
+		"Access-Control-Allow-Headers": "Accept, Content-Type",
+		"Access-Control-Max-Age":       "600",
+	} {
+		if got := rec.Header().Get(name); got != want {
+			t.Errorf("preflight %s = %q, want %q", name, got, want)
+		}
+	}
+	if rec.Code != http.StatusNoContent {
+		t.Errorf("preflight = %d, want 204", rec.Code)
+	}
+
+	// Preflights for methods or origins not allowed are refused by leaving
+	// the headers out.
+	for _, tt := range []struct{ method, origin string }{{"DELETE", "https://dashboard.example.com"}, {"GET", "https://evil.example.com"}} {
+		rec := request(http.MethodOptions, "/tech-radar/api/v1/radar", tt.origin, "Access-Control-Request-Method", tt.method)
+		if rec.Code != http.StatusNoContent || rec.Header().Get("Access-Control-Allow-Origin") != "" || rec.Header().Get("Access-Control-Allow-Methods") != "" {
+			t.Errorf("preflight of %s from %s = %d %v, want no CORS headers", tt.method, tt.origin, rec.Code, rec.Header())
+		}
+	}
+
+	// Plain OPTIONS requests aren't preflights.
+	if rec := request(http.MethodOptions, "/tech-radar/api/v1/radar", "https://dashboard.example.com"); rec.Code != http.StatusMethodNotAllowed {
+		t.Errorf("OPTIONS without Access-Control-Request-Method = %d, want 405", rec.Code)
+	}
+}
+
+func TestCORSAnyOrigin(t *testing.T) {
+	cfg := defaultConfig()
+	cfg.Server.CORS.AllowedOrigins = []string{"*"}
+	cfg.Data.Path = writeFile(t, "radar.yaml", radarWith("Go", "Adopted"))
+	useConfig(t, cfg)
+	handler, err := setupRoutes(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/radar", nil)
+	req.Header.Set("Origin", "https://anywhere.example.com")
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	if rec.Header().Get("Access-Control-Allow-Origin") != "*" || slices.Contains(rec.Header().Values("Vary"), "Origin") {
+		t.Errorf("GET with any origin allowed = Access-Control-Allow-Origin %q, Vary %q", rec.Header().Get("Access-Control-Allow-Origin"), rec.Header().Values("Vary"))
+	}
+
+	// CORS is off without origins.
+	cfg.Server.CORS.AllowedOrigins = nil
+	if handler, err = setupRoutes(cfg); err != nil {
+		t.Fatal(err)
+	}
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	if rec.Header().Get("Access-Control-Allow-Origin") != "" {
+		t.Errorf("GET without CORS = Access-Control-Allow-Origin %q", rec.Header().Get("Access-Control-Allow-Origin"))
+	}
+}
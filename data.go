@@ -0,0 +1,461 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v3"
+)
+
+// RadarData represents the complete radar data structure.
+type RadarData struct {
+	// Version is the data format version; files without one are version 1.
+	Version      int    `yaml:"Version,omitempty" json:"version,omitempty" toml:"Version,omitzero"`
+	LastModified string `yaml:"LastModified" json:"lastModified"`
+	// Quadrants and Rings define where items may be placed, in order;
+	// data without them uses defaultQuadrants and defaultRings.
+	Quadrants []Segment `yaml:"Quadrants,omitempty" json:"quadrants,omitempty" toml:"Quadrants,omitempty"`
+	Rings     []Segment `yaml:"Rings,omitempty" json:"rings,omitempty" toml:"Rings,omitempty"`
+	// ReviewDate is the date, as YYYY-MM-DD, of the next review session of
+	// the whole radar.
+	ReviewDate string      `yaml:"ReviewDate,omitempty" json:"reviewDate,omitempty" toml:"ReviewDate,omitempty"`
+	Items      []RadarItem `yaml:"Items" json:"items"`
+}
+
+// RadarItem represents a technology item in the radar.
+type RadarItem struct {
+	// ID identifies the item in the API. Items without one in their data
+	// file get the slug of their label.
+	ID          string `yaml:"ID,omitempty" json:"id" toml:"ID,omitempty"`
+	Label       string `yaml:"Label" json:"label"`
+	Quadrant    string `yaml:"Quadrant" json:"quadrant"`
+	Ring        string `yaml:"Ring" json:"ring"`
+	Moved       bool   `yaml:"Moved" json:"moved"`
+	Description string `yaml:"Description" json:"description"`
+	Owners      Owners `yaml:"Owners,omitempty" json:"owners" toml:"Owners,omitempty"`
+	Tags        Tags   `yaml:"Tags,omitempty" json:"tags" toml:"Tags,omitempty"`
+	Links       Links  `yaml:"Links,omitempty" json:"links" toml:"Links,omitempty"`
+	// LastUpdated is when the item last changed, as recorded by saves
+	// through the API or found in the Git history of its data file.
+	LastUpdated time.Time `yaml:"LastUpdated,omitempty" json:"lastUpdated,omitzero" toml:"LastUpdated,omitempty"`
+	// Archived items are kept but left off the radar, see visibleItems.
+	Archived bool `yaml:"Archived,omitempty" json:"archived,omitempty" toml:"Archived,omitempty"`
+	// MergedFrom lists the IDs of the items merged into this one, whose
+	// history is shown with it.
+	MergedFrom ItemIDs `yaml:"MergedFrom,omitempty" json:"mergedFrom,omitempty" toml:"MergedFrom,omitempty"`
+	// ReviewDate is the date, as YYYY-MM-DD, by which the item is due to be
+	// re-assessed.
+	ReviewDate string `yaml:"ReviewDate,omitempty" json:"reviewDate,omitempty" toml:"ReviewDate,omitempty"`
+	// Direction is where an item marked as Moved went, movedUp or
+	// movedDown, and PreviousRing where from, see stampMoved.
+	Direction    string `yaml:"Direction,omitempty" json:"direction,omitempty" toml:"Direction,omitempty"`
+	PreviousRing string `yaml:"PreviousRing,omitempty" json:"previousRing,omitempty" toml:"PreviousRing,omitempty"`
+	// SunsetDate is the date, as YYYY-MM-DD, the item is to be retired on,
+	// see checkSunsets.
+	SunsetDate string `yaml:"SunsetDate,omitempty" json:"sunsetDate,omitempty" toml:"SunsetDate,omitempty"`
+	// Relations link the item to others by their ID, such as to the item it
+	// replaces.
+	Relations Relations `yaml:"Relations,omitempty" json:"relations" toml:"Relations,omitempty"`
+
+	// Source is the data file the item was loaded from.
+	Source string `yaml:"-" json:"-" toml:"-"`
+}
+
+// loadRadarData returns the radar data of the active Store, through the
+// cache if one is configured.
+func loadRadarData() (RadarData, error) {
+	store := currentStore()
+	if store == nil {
+		return RadarData{}, &AppError{Code: http.StatusServiceUnavailable, Message: "Radar data not available yet"}
+	}
+	load := func() (RadarData, error) { return loadStoreData(store) }
+	// Remote data is already kept in memory by its store.
+	if _, remote := store.(*remoteStore); !remote {
+		if c := activeCache.Load(); c != nil {
+			return c.load(context.Background(), load)
+		}
+	}
+	return load()
+}
+
+// loadStoreData loads the radar data from store. Errors other than
+// AppErrors, such as database errors, are reported as a 500.
+func loadStoreData(store Store) (RadarData, error) {
+	data, err := store.Load(context.Background())
+	if _, ok := err.(*AppError); err != nil && !ok {
+		return RadarData{}, &AppError{Code: http.StatusInternalServerError, Message: "Failed to load radar data", Err: err}
+	}
+	return data, err
+}
+
+// dataFiles resolves a data path into the files to load. The path may name
+// a single file, a directory whose data files of every supported format are
+// all loaded, or a glob pattern such as data/*.yaml. Files are returned in
+// lexical order.
+func dataFiles(path string) ([]string, error) {
+	var files []string
+	if strings.ContainsAny(path, "*?[") {
+		matches, err := filepath.Glob(path)
+		if err != nil {
+			return nil, fmt.Errorf("invalid data path pattern %q: %w", path, err)
+		}
+		files = matches
+	} else {
+		info, err := os.Stat(path)
+		if err != nil {
+			return nil, err
+		}
+		if !info.IsDir() {
+			return []string{path}, nil
+		}
+		for _, pattern := range []string{"*.yaml", "*.yml", "*.json", "*.toml"} {
+			matches, _ := filepath.Glob(filepath.Join(path, pattern))
+			files = append(files, matches...)
+		}
+	}
+
+	sort.Strings(files)
+	if len(files) == 0 {
+		return nil, fmt.Errorf("no radar data files found at %s", path)
+	}
+	return files, nil
+}
+
+// isSingleDataFile reports whether path names one local data file rather
+// than a directory, glob pattern or URL, so it can be written to.
+func isSingleDataFile(path string) bool {
+	if isRemoteDataPath(path) || strings.ContainsAny(path, "*?[") {
+		return false
+	}
+	info, err := os.Stat(path)
+	return errors.Is(err, os.ErrNotExist) || err == nil && !info.IsDir()
+}
+
+// readRadarData loads every data file at path and merges them into one
+// RadarData. The LastModified of the most recently modified file wins, and
+// the earliest ReviewDate of the files is the next review. Labels
+// must be unique across files, and files declaring quadrants or rings must
+// agree on them; conflicts are reported with the files involved.
+func readRadarData(path string) (RadarData, error) {
+	files, err := dataFiles(path)
+	if err != nil {
+		return RadarData{}, &AppError{Code: http.StatusInternalServerError, Message: "Failed to read radar data", Err: err}
+	}
+
+	var merged RadarData
+	var newest time.Time
+	var duplicates ValidationErrors
+	var segments segmentDeclarations
+	sources := make(map[string]string)
+	for _, file := range files {
+		raw, err := os.ReadFile(file)
+		if err != nil {
+			return RadarData{}, &AppError{Code: http.StatusInternalServerError, Message: "Failed to read radar data", Err: err}
+		}
+		content, err := openData(raw)
+		if err != nil {
+			return RadarData{}, &AppError{Code: http.StatusInternalServerError, Message: "Failed to read radar data", Err: fmt.Errorf("%s: %w", file, err)}
+		}
+		data, applied, err := upgradeRadarData(file, content)
+		if err != nil {
+			return RadarData{}, &AppError{Code: http.StatusInternalServerError, Message: "Failed to parse radar data", Err: err}
+		}
+		logSchemaMigrations(file, applied)
+		// git blame can't see the lines of an encrypted file.
+		if !isEncrypted(raw) {
+			blameLastUpdated(file, content, data.Items)
+		}
+
+		if info, err := os.Stat(file); err == nil && data.LastModified != "" && !info.ModTime().Before(newest) {
+			merged.LastModified = data.LastModified
+			newest = info.ModTime()
+		}
+		if data.ReviewDate != "" && (merged.ReviewDate == "" || data.ReviewDate < merged.ReviewDate) {
+			merged.ReviewDate = data.ReviewDate
+		}
+		duplicates = append(duplicates, segments.add(file, data)...)
+		for _, item := range data.Items {
+			key := labelKey(item.Label)
+			if first, ok := sources[key]; ok {
+				duplicates = append(duplicates, ValidationError{
+					File:    file,
+					Field:   fmt.Sprintf("item %q", item.Label),
+					Rule:    ruleUnique,
+					Message: "duplicate label, also defined in " + first,
+				})
+				continue
+			}
+			sources[key] = file
+			item.Source = file
+			merged.Items = append(merged.Items, item)
+		}
+	}
+
+	if len(duplicates) > 0 {
+		return RadarData{}, &AppError{Code: http.StatusInternalServerError, Message: "Conflicting radar data", Err: duplicates}
+	}
+	merged.Quadrants, merged.Rings = segments.quadrants, segments.rings
+	merged, err = withItemIDs(merged, nil)
+	if err != nil {
+		return RadarData{}, &AppError{Code: http.StatusInternalServerError, Message: "Conflicting radar data", Err: err}
+	}
+	return merged, nil
+}
+
+// Data file formats, chosen by file extension.
+const (
+	formatYAML = "yaml"
+	formatJSON = "json"
+	formatTOML = "toml"
+)
+
+// dataFormat returns the format of a data file based on its extension.
+// Files with an unrecognized extension are read as YAML.
+func dataFormat(file string) string {
+	switch strings.ToLower(filepath.Ext(file)) {
+	case ".json":
+		return formatJSON
+	case ".toml":
+		return formatTOML
+	default:
+		return formatYAML
+	}
+}
+
+// decodeRadarData decodes radar data in any supported format version,
+// migrating older versions to the latest one.
+func decodeRadarData(file string, content []byte) (RadarData, error) {
+	data, _, err := upgradeRadarData(file, content)
+	return data, err
+}
+
+// decodeRadarContent decodes radar data strictly in the format given by the
+// file's extension: unknown fields and values of the wrong type are reported
+// as ValidationErrors instead of being silently dropped. Syntax errors are
+// returned as is.
+func decodeRadarContent(file string, content []byte) (RadarData, error) {
+	switch dataFormat(file) {
+	case formatTOML:
+		return decodeTOMLRadarData(file, content)
+	case formatJSON:
+		// JSON is a subset of YAML, so once the syntax has been checked the
+		// YAML decoder handles it with accurate line numbers.
+		if err := checkJSONSyntax(content); err != nil {
+			return RadarData{}, err
+		}
+	}
+	return decodeYAMLRadarData(file, content)
+}
+
+// checkJSONSyntax reports a JSON syntax error with its line number, so
+// content that is valid YAML but not JSON is rejected. Empty content is
+// accepted, as it is for YAML.
+func checkJSONSyntax(content []byte) error {
+	if len(bytes.TrimSpace(content)) == 0 {
+		return nil
+	}
+	var v any
+	err := json.Unmarshal(content, &v)
+	var syntaxErr *json.SyntaxError
+	if errors.As(err, &syntaxErr) {
+		line := 1 + bytes.Count(content[:syntaxErr.Offset], []byte("\n"))
+		return fmt.Errorf("line %d: %w", line, err)
+	}
+	return err
+}
+
+// decodeYAMLRadarData decodes YAML radar data, reporting type errors with
+// their line numbers.
+func decodeYAMLRadarData(file string, content []byte) (RadarData, error) {
+	var data RadarData
+	dec := yaml.NewDecoder(bytes.NewReader(content))
+	dec.KnownFields(true)
+	err := dec.Decode(&data)
+	if errors.Is(err, io.EOF) {
+		return RadarData{}, nil
+	}
+
+	var typeErr *yaml.TypeError
+	if errors.As(err, &typeErr) {
+		errs := make(ValidationErrors, 0, len(typeErr.Errors))
+		for _, msg := range typeErr.Errors {
+			errs = append(errs, yamlTypeError(file, msg))
+		}
+		return RadarData{}, errs
+	}
+	return data, err
+}
+
+// encodeRadarData encodes radar data in the format given by the file's
+// extension, using the same keys the decoders accept.
+func encodeRadarData(file string, data RadarData) ([]byte, error) {
+	switch dataFormat(file) {
+	case formatTOML:
+		var buf bytes.Buffer
+		if err := toml.NewEncoder(&buf).Encode(data); err != nil {
+			return nil, err
+		}
+		return buf.Bytes(), nil
+	case formatJSON:
+		// The json tags name the API fields, so go through YAML to get the
+		// data file keys.
+		content, err := yaml.Marshal(data)
+		if err != nil {
+			return nil, err
+		}
+		var raw any
+		if err := yaml.Unmarshal(content, &raw); err != nil {
+			return nil, err
+		}
+		content, err = json.MarshalIndent(raw, "", "  ")
+		if err != nil {
+			return nil, err
+		}
+		return append(content, '\n'), nil
+	default:
+		var buf bytes.Buffer
+		enc := yaml.NewEncoder(&buf)
+		enc.SetIndent(2)
+		if err := enc.Encode(data); err != nil {
+			return nil, err
+		}
+		return buf.Bytes(), enc.Close()
+	}
+}
+
+// writeRadarData replaces the data file at path with data, see
+// radarFileContent.
+func writeRadarData(path string, data RadarData) error {
+	content, err := radarFileContent(path, data)
+	if err != nil {
+		return err
+	}
+	return replaceFile(path, content, 0o644)
+}
+
+// radarFileContent returns the content of the data file at path holding
+// data, encoded in the file's format and encrypted if a key is configured.
+// An existing YAML file keeps its comments and key order, see
+// mergeYAMLRadarData.
+func radarFileContent(path string, data RadarData) ([]byte, error) {
+	content, err := encodeRadarData(path, data)
+	if err != nil {
+		return nil, err
+	}
+	if existing, err := readDataFile(path); err == nil && dataFormat(path) == formatYAML && len(bytes.TrimSpace(existing)) > 0 {
+		merged, err := mergeYAMLRadarData(existing, data)
+		if err != nil {
+			// Rewrite a file that can't be merged from scratch.
+			slog.Warn("Failed to keep the layout of the data file", "path", path, "err", err)
+		} else {
+			content = merged
+		}
+	}
+	return sealData(content)
+}
+
+// replaceFile replaces the file at path with content, keeping its
+// permissions, or creates it with mode. The content is written to a temporary file first and
+// renamed into place, so readers never see a partial file.
+func replaceFile(path string, content []byte, mode os.FileMode) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if info, err := os.Stat(path); err == nil {
+		mode = info.Mode().Perm()
+	}
+	if _, err := tmp.Write(content); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Chmod(mode); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// tomlErrorPosition matches the position prefix of BurntSushi/toml decode
+// errors.
+var tomlErrorPosition = regexp.MustCompile(`^toml: line (\d+) \(last key "([^"]*)"\): (.*)$`)
+
+// decodeTOMLRadarData decodes TOML radar data. Keys that don't map to a
+// field and values of the wrong type are reported as ValidationErrors.
+func decodeTOMLRadarData(file string, content []byte) (RadarData, error) {
+	var data RadarData
+	md, err := toml.Decode(string(content), &data)
+	var parseErr toml.ParseError
+	if errors.As(err, &parseErr) {
+		return RadarData{}, err
+	}
+	if err != nil {
+		verr := ValidationError{File: file, Rule: ruleSchema, Message: err.Error()}
+		if m := tomlErrorPosition.FindStringSubmatch(err.Error()); m != nil {
+			verr.Line, _ = strconv.Atoi(m[1])
+			verr.Field = m[2]
+			verr.Message = m[3]
+		}
+		return RadarData{}, ValidationErrors{verr}
+	}
+
+	var errs ValidationErrors
+	for _, key := range md.Undecoded() {
+		errs = append(errs, ValidationError{File: file, Rule: ruleSchema, Message: "unknown field " + key[len(key)-1]})
+	}
+	if len(errs) > 0 {
+		return RadarData{}, errs
+	}
+	return data, nil
+}
+
+// yamlErrorLine matches the "line N: " prefix of yaml.v3 type errors.
+var yamlErrorLine = regexp.MustCompile(`^line (\d+): (.*)$`)
+
+// yamlErrorMessages rewrites yaml.v3 type error messages, which name Go
+// types, into messages that make sense to someone editing the data file.
+var yamlErrorMessages = []struct {
+	pattern *regexp.Regexp
+	replace string
+}{
+	{regexp.MustCompile(`^field (\S+) not found in type .*$`), "unknown field $1"},
+	{regexp.MustCompile("^cannot unmarshal !!\\w+ .* into \\[\\]main\\.RadarItem$"), "expected a list of items"},
+	{regexp.MustCompile("^cannot unmarshal !!\\w+ .* into main\\.\\w+$"), "expected a mapping"},
+	{regexp.MustCompile("^cannot unmarshal !!\\w+ (`.*`) into (\\S+)$"), "invalid value $1, expected $2"},
+}
+
+// yamlTypeError converts a yaml.v3 type error message into a
+// ValidationError, extracting its line number.
+func yamlTypeError(file, msg string) ValidationError {
+	verr := ValidationError{File: file, Rule: ruleSchema, Message: msg}
+	if m := yamlErrorLine.FindStringSubmatch(msg); m != nil {
+		verr.Line, _ = strconv.Atoi(m[1])
+		verr.Message = m[2]
+	}
+	for _, r := range yamlErrorMessages {
+		if r.pattern.MatchString(verr.Message) {
+			verr.Message = r.pattern.ReplaceAllString(verr.Message, r.replace)
+			break
+		}
+	}
+	return verr
+}
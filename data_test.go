@@ -0,0 +1,222 @@
+package main
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestDecodeRadarData(t *testing.T) {
+	data, err := decodeRadarData("radar.yaml", []byte(`LastModified: May 2024
+Items:
+- Label: Go
+  Quadrant: Programming Languages & Frameworks
+  Ring: Adopted
+  Moved: true
+  Description: Fast to compile.
+  Owners: Team A
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := RadarData{
+		Version:      2,
+		LastModified: "May 2024",
+		Items: []RadarItem{{
+			Label:       "Go",
+			Quadrant:    "Programming Languages & Frameworks",
+			Ring:        "Adopted",
+			Moved:       true,
+			Description: "Fast to compile.",
+			Owners:      Owners{{Name: "Team A"}},
+		}},
+	}
+	if !reflect.DeepEqual(data, want) {
+		t.Errorf("decodeRadarData() = %+v, want %+v", data, want)
+	}
+}
+
+func TestDecodeRadarDataFormats(t *testing.T) {
+	want := RadarData{
+		LastModified: "May 2024",
+		Items:        []RadarItem{{Label: "Go", Quadrant: "Tools", Ring: "Adopted", Moved: true}},
+	}
+	files := map[string]string{
+		"radar.json": `{"LastModified": "May 2024", "Items": [{"Label": "Go", "Quadrant": "Tools", "Ring": "Adopted", "Moved": true}]}`,
+		"radar.toml": "LastModified = \"May 2024\"\n\n[[Items]]\nLabel = \"Go\"\nQuadrant = \"Tools\"\nRing = \"Adopted\"\nMoved = true\n",
+	}
+	for file, content := range files {
+		data, err := decodeRadarData(file, []byte(content))
+		if err != nil || !reflect.DeepEqual(data, want) {
+			t.Errorf("decodeRadarData(%s) = %+v, %v, want %+v", file, data, err, want)
+		}
+	}
+}
+
+func TestDecodeRadarDataFormatsStrict(t *testing.T) {
+	tests := []struct {
+		file string
+		data string
+		want []ValidationError
+	}{
+		{
+			file: "radar.json",
+			data: "{\n  \"Items\": [\n    {\"Label\": \"Go\", \"Moved\": \"maybe\"}\n  ]\n}\n",
+			want: []ValidationError{{File: "radar.json", Line: 3, Rule: ruleSchema, Message: "invalid value `maybe`, expected bool"}},
+		},
+		{
+			file: "radar.toml",
+			data: "[[Items]]\nLabel = \"Go\"\nOwner = \"Team A\"\n",
+			want: []ValidationError{{File: "radar.toml", Rule: ruleSchema, Message: "unknown field Owner"}},
+		},
+		{
+			file: "radar.toml",
+			data: "[[Items]]\nLabel = \"Go\"\nMoved = \"maybe\"\n",
+			want: []ValidationError{{File: "radar.toml", Line: 3, Field: "Items.Moved", Rule: ruleSchema, Message: "incompatible types: TOML value has type string; destination has type boolean"}},
+		},
+	}
+	for _, tt := range tests {
+		_, err := decodeRadarData(tt.file, []byte(tt.data))
+		var errs ValidationErrors
+		if !errors.As(err, &errs) || !reflect.DeepEqual([]ValidationError(errs), tt.want) {
+			t.Errorf("decodeRadarData(%s, %q) error = %#v, want %+v", tt.file, tt.data, err, tt.want)
+		}
+	}
+}
+
+func TestDecodeRadarDataJSONSyntax(t *testing.T) {
+	_, err := decodeRadarData("radar.json", []byte("{\n  \"Items\": [\n    Label: Go\n"))
+	var errs ValidationErrors
+	if err == nil || errors.As(err, &errs) || !strings.HasPrefix(err.Error(), "line 3: ") {
+		t.Fatalf("decodeRadarData() error = %v, want a line 3 syntax error", err)
+	}
+}
+
+func TestDecodeRadarDataStrict(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+		want []ValidationError
+	}{
+		{
+			name: "unknown field",
+			data: "Items:\n- Label: Go\n  Owner: Team A\n",
+			want: []ValidationError{{File: "radar.yaml", Line: 3, Rule: ruleSchema, Message: "unknown field Owner"}},
+		},
+		{
+			name: "wrong type",
+			data: "Items:\n- Label: Go\n  Moved: maybe\n",
+			want: []ValidationError{{File: "radar.yaml", Line: 3, Rule: ruleSchema, Message: "invalid value `maybe`, expected bool"}},
+		},
+		{
+			name: "items not a list",
+			data: "Items: nope\n",
+			want: []ValidationError{{File: "radar.yaml", Line: 1, Rule: ruleSchema, Message: "expected a list of items"}},
+		},
+		{
+			name: "several errors",
+			data: "Status: 2\nItems:\n- Moved: 3\n",
+			want: []ValidationError{
+				{File: "radar.yaml", Line: 1, Rule: ruleSchema, Message: "unknown field Status"},
+				{File: "radar.yaml", Line: 3, Rule: ruleSchema, Message: "invalid value `3`, expected bool"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := decodeRadarData("radar.yaml", []byte(tt.data))
+			var errs ValidationErrors
+			if !errors.As(err, &errs) {
+				t.Fatalf("decodeRadarData() error = %v, want ValidationErrors", err)
+			}
+			if !reflect.DeepEqual([]ValidationError(errs), tt.want) {
+				t.Errorf("decodeRadarData() errors = %+v, want %+v", errs, tt.want)
+			}
+		})
+	}
+}
+
+func TestDecodeRadarDataEmpty(t *testing.T) {
+	data, err := decodeRadarData("radar.yaml", nil)
+	if err != nil || !reflect.DeepEqual(data, RadarData{}) {
+		t.Errorf("decodeRadarData(empty) = %+v, %v", data, err)
+	}
+}
+
+func TestLoadRadarDataReportsParseErrors(t *testing.T) {
+	cfg := defaultConfig()
+	cfg.Data.Path = writeFile(t, "radar.yaml", "Items:\n- Label: Go\n  Colour: red\n")
+	useConfig(t, cfg)
+
+	_, err := loadRadarData()
+	var appErr *AppError
+	if !errors.As(err, &appErr) || appErr.Message != "Failed to parse radar data" {
+		t.Fatalf("loadRadarData() error = %v, want parse AppError", err)
+	}
+}
+
+// writeDataDir writes each file into a fresh temporary directory and
+// returns the directory.
+func writeDataDir(t *testing.T, files map[string]string) string {
+	t.Helper()
+	dir := t.TempDir()
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+func TestDataFiles(t *testing.T) {
+	dir := writeDataDir(t, map[string]string{"b.yaml": "", "a.yml": "", "c.json": "", "notes.txt": ""})
+	want := []string{filepath.Join(dir, "a.yml"), filepath.Join(dir, "b.yaml"), filepath.Join(dir, "c.json")}
+
+	for _, path := range []string{dir, filepath.Join(dir, "*.[jy]*")} {
+		got, err := dataFiles(path)
+		if err != nil || !reflect.DeepEqual(got, want) {
+			t.Errorf("dataFiles(%q) = %v, %v, want %v", path, got, err, want)
+		}
+	}
+	if _, err := dataFiles(filepath.Join(dir, "*.toml")); err == nil {
+		t.Error("dataFiles() with no matches returned no error")
+	}
+}
+
+func TestReadRadarDataMergesFiles(t *testing.T) {
+	dir := writeDataDir(t, map[string]string{
+		"platforms.yaml": "Items:\n- Label: Kubernetes\n  Quadrant: Platforms\n  Ring: Adopted\n",
+		"tools.yaml":     "Items:\n- Label: Terraform\n  Quadrant: Tools\n  Ring: Adopted\n",
+	})
+
+	data, err := readRadarData(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var labels, sources []string
+	for _, item := range data.Items {
+		labels = append(labels, item.Label)
+		sources = append(sources, filepath.Base(item.Source))
+	}
+	if !reflect.DeepEqual(labels, []string{"Kubernetes", "Terraform"}) ||
+		!reflect.DeepEqual(sources, []string{"platforms.yaml", "tools.yaml"}) {
+		t.Errorf("merged items = %v from %v", labels, sources)
+	}
+}
+
+func TestReadRadarDataRejectsDuplicatesAcrossFiles(t *testing.T) {
+	dir := writeDataDir(t, map[string]string{
+		"a.yaml": "Items:\n- Label: Go\n",
+		"b.yaml": "Items:\n- Label: go\n",
+	})
+
+	_, err := readRadarData(dir)
+	var appErr *AppError
+	var errs ValidationErrors
+	if !errors.As(err, &appErr) || !errors.As(appErr.Err, &errs) || len(errs) != 1 || !strings.Contains(errs[0].Error(), "also defined in "+filepath.Join(dir, "a.yaml")) {
+		t.Fatalf("readRadarData() error = %v, want duplicate naming a.yaml", err)
+	}
+}
@@ -0,0 +1,158 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// defaultEnvFile is the dotenv file read when no other one is configured.
+const defaultEnvFile = ".env"
+
+// readEnvFile parses a dotenv file into a map. Lines have the form
+// KEY=VALUE, optionally prefixed with "export"; blank lines and lines
+// starting with # are ignored. Values may be single- or double-quoted, and
+// double-quoted values support the usual backslash escapes.
+func readEnvFile(path string) (map[string]string, error) {
+	file, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	values := make(map[string]string)
+	scanner := bufio.NewScanner(bytes.NewReader(file))
+	for lineNo := 1; scanner.Scan(); lineNo++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		line = strings.TrimSpace(strings.TrimPrefix(line, "export "))
+
+		key, value, ok := strings.Cut(line, "=")
+		key = strings.TrimSpace(key)
+		if !ok || key == "" {
+			return nil, fmt.Errorf("%s:%d: expected KEY=VALUE", path, lineNo)
+		}
+
+		value, err := parseEnvValue(strings.TrimSpace(value))
+		if err != nil {
+			return nil, fmt.Errorf("%s:%d: %v", path, lineNo, err)
+		}
+		values[key] = value
+	}
+	return values, scanner.Err()
+}
+
+// parseEnvValue unquotes a dotenv value and strips trailing comments. Only
+// whitespace or a " #" comment may follow the closing quote of a quoted
+// value.
+func parseEnvValue(value string) (string, error) {
+	var end int
+	switch {
+	case strings.HasPrefix(value, `"`):
+		end = closingQuote(value, '"', true)
+	case strings.HasPrefix(value, "'"):
+		end = closingQuote(value, '\'', false)
+	default:
+		if i := strings.Index(value, " #"); i >= 0 {
+			value = strings.TrimSpace(value[:i])
+		}
+		return value, nil
+	}
+	if end < 0 {
+		return "", fmt.Errorf("unterminated quoted value")
+	}
+
+	rest := value[end+1:]
+	if trimmed := strings.TrimSpace(rest); trimmed != "" && (!strings.HasPrefix(trimmed, "#") || trimmed == rest) {
+		return "", fmt.Errorf("unexpected text after quoted value: %q", rest)
+	}
+
+	if value[0] == '\'' {
+		return value[1:end], nil
+	}
+	return strconv.Unquote(value[:end+1])
+}
+
+// closingQuote returns the index of the quote character that closes the
+// value starting at value[0], or -1 if there is none. When escapes is true
+// a backslash escapes the following character.
+func closingQuote(value string, quote byte, escapes bool) int {
+	for i := 1; i < len(value); i++ {
+		switch {
+		case escapes && value[i] == '\\':
+			i++
+		case value[i] == quote:
+			return i
+		}
+	}
+	return -1
+}
+
+// environment resolves configuration variables from the process environment,
+// falling back to values read from a dotenv file and then to secrets read
+// from files. Real environment variables always win so that a checked-in
+// .env never overrides a deployment.
+type environment struct {
+	dotenv map[string]string
+	// secrets holds the contents of files named by RADAR_*_FILE variables,
+	// keyed by the variable name without the _FILE suffix.
+	secrets map[string]string
+}
+
+// secretFileSuffix marks a variable whose value is the path of a file holding
+// the actual value, e.g. RADAR_ADMIN_TOKEN_FILE for RADAR_ADMIN_TOKEN. This
+// lets Docker and Kubernetes secrets be mounted as files.
+const secretFileSuffix = "_FILE"
+
+// lookup returns the value of the named variable and whether it is set.
+func (e environment) lookup(name string) (string, bool) {
+	if value, ok := os.LookupEnv(name); ok {
+		return value, true
+	}
+	if value, ok := e.dotenv[name]; ok {
+		return value, true
+	}
+	value, ok := e.secrets[name]
+	return value, ok
+}
+
+// readSecretFiles reads the file named by every RADAR_*_FILE variable. It is
+// an error to set both a variable and its _FILE variant.
+func (e *environment) readSecretFiles() error {
+	names := make(map[string]bool)
+	for _, kv := range os.Environ() {
+		name, _, _ := strings.Cut(kv, "=")
+		names[name] = true
+	}
+	for name := range e.dotenv {
+		names[name] = true
+	}
+
+	for name := range names {
+		base, ok := strings.CutSuffix(name, secretFileSuffix)
+		if !ok || !strings.HasPrefix(base, "RADAR_") {
+			continue
+		}
+		path, _ := e.lookup(name)
+		if path == "" {
+			continue
+		}
+		if value, set := e.lookup(base); set && value != "" {
+			return fmt.Errorf("both %s and %s are set", base, name)
+		}
+
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return fmt.Errorf("reading %s: %w", name, err)
+		}
+		if e.secrets == nil {
+			e.secrets = make(map[string]string)
+		}
+		e.secrets[base] = strings.TrimRight(string(content), "\r\n")
+	}
+	return nil
+}
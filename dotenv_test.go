@@ -0,0 +1,158 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseEnvValue(t *testing.T) {
+	tests := []struct {
+		name    string
+		in      string
+		want    string
+		wantErr bool
+	}{
+		{name: "plain", in: "value", want: "value"},
+		{name: "empty", in: "", want: ""},
+		{name: "trailing comment", in: "value # comment", want: "value"},
+		{name: "hash without space", in: "a#b", want: "a#b"},
+		{name: "double quoted", in: `"hello world"`, want: "hello world"},
+		{name: "double quoted escapes", in: `"a\nb \"c\""`, want: "a\nb \"c\""},
+		{name: "double quoted comment", in: `"x" # it's "quoted"`, want: "x"},
+		{name: "single quoted", in: `'a \n b'`, want: `a \n b`},
+		{name: "single quoted comment", in: `'x' # note`, want: "x"},
+		{name: "trailing text", in: `"x" trailing`, wantErr: true},
+		{name: "comment without space", in: `"x"#c`, wantErr: true},
+		{name: "unterminated double", in: `"abc`, wantErr: true},
+		{name: "unterminated single", in: `'abc`, wantErr: true},
+		{name: "escaped closing quote", in: `"abc\"`, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseEnvValue(tt.in)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseEnvValue(%q) error = %v, wantErr %v", tt.in, err, tt.wantErr)
+			}
+			if !tt.wantErr && got != tt.want {
+				t.Errorf("parseEnvValue(%q) = %q, want %q", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestReadEnvFile(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    map[string]string
+		wantErr bool
+	}{
+		{
+			name:    "keys and comments",
+			content: "# comment\n\nRADAR_PORT=9090\nexport RADAR_LOG_LEVEL = debug\nEMPTY=\n",
+			want:    map[string]string{"RADAR_PORT": "9090", "RADAR_LOG_LEVEL": "debug", "EMPTY": ""},
+		},
+		{
+			name:    "quoted values",
+			content: "A=\"x y\"\nB='z' # note\n",
+			want:    map[string]string{"A": "x y", "B": "z"},
+		},
+		{name: "missing equals", content: "RADAR_PORT\n", wantErr: true},
+		{name: "missing key", content: "=value\n", wantErr: true},
+		{name: "bad quoting", content: "A=\"x\" y\n", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), ".env")
+			if err := os.WriteFile(path, []byte(tt.content), 0o644); err != nil {
+				t.Fatal(err)
+			}
+			got, err := readEnvFile(path)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("readEnvFile() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("readEnvFile() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEnvironmentLookupPrefersProcessEnv(t *testing.T) {
+	t.Setenv("RADAR_TEST_VAR", "process")
+	env := environment{dotenv: map[string]string{"RADAR_TEST_VAR": "dotenv", "RADAR_OTHER": "dotenv"}}
+
+	if v, _ := env.lookup("RADAR_TEST_VAR"); v != "process" {
+		t.Errorf("lookup(RADAR_TEST_VAR) = %q, want process", v)
+	}
+	if v, _ := env.lookup("RADAR_OTHER"); v != "dotenv" {
+		t.Errorf("lookup(RADAR_OTHER) = %q, want dotenv", v)
+	}
+	if _, ok := env.lookup("RADAR_UNSET"); ok {
+		t.Errorf("lookup(RADAR_UNSET) reported a value")
+	}
+}
+
+func TestEnvironmentSecretFiles(t *testing.T) {
+	clearRadarEnv(t)
+	secret := writeFile(t, "token", "s3cret\n")
+	t.Setenv("RADAR_TEST_TOKEN_FILE", secret)
+
+	env := environment{dotenv: map[string]string{"RADAR_DOTENV_TOKEN_FILE": secret}}
+	if err := env.readSecretFiles(); err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"RADAR_TEST_TOKEN", "RADAR_DOTENV_TOKEN"} {
+		if v, ok := env.lookup(name); !ok || v != "s3cret" {
+			t.Errorf("lookup(%s) = %q, %v, want s3cret", name, v, ok)
+		}
+	}
+}
+
+func TestEnvironmentSecretFileErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		env     map[string]string
+		wantErr string
+	}{
+		{
+			name:    "missing file",
+			env:     map[string]string{"RADAR_TEST_TOKEN_FILE": "/does/not/exist"},
+			wantErr: "reading RADAR_TEST_TOKEN_FILE",
+		},
+		{
+			name:    "both set",
+			env:     map[string]string{"RADAR_TEST_TOKEN": "inline", "RADAR_TEST_TOKEN_FILE": "/does/not/exist"},
+			wantErr: "both RADAR_TEST_TOKEN and RADAR_TEST_TOKEN_FILE are set",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clearRadarEnv(t)
+			for k, v := range tt.env {
+				t.Setenv(k, v)
+			}
+			var env environment
+			err := env.readSecretFiles()
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("readSecretFiles() error = %v, want it to contain %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestLoadConfigFromSecretFile(t *testing.T) {
+	clearRadarEnv(t)
+	t.Setenv("RADAR_LISTEN_FILE", writeFile(t, "listen", ":7100\n"))
+
+	cfg, err := loadConfig(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Server.Listen != ":7100" {
+		t.Errorf("Server.Listen = %q, want :7100", cfg.Server.Listen)
+	}
+}
@@ -0,0 +1,320 @@
+package main
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"slices"
+	"strings"
+	"unicode"
+)
+
+// maxMergeRequestSize limits the size of a merge request body.
+const maxMergeRequestSize = 64 << 10
+
+// ItemIDs are the IDs of the items merged into a radar item. In SQL they
+// are stored as a JSON array.
+type ItemIDs []string
+
+// Value stores the IDs in a SQL text column as a JSON array.
+func (ids ItemIDs) Value() (driver.Value, error) {
+	if len(ids) == 0 {
+		return "", nil
+	}
+	data, err := json.Marshal([]string(ids))
+	return string(data), err
+}
+
+// Scan reads IDs stored by Value.
+func (ids *ItemIDs) Scan(src any) error {
+	var s string
+	switch v := src.(type) {
+	case nil:
+	case string:
+		s = v
+	case []byte:
+		s = string(v)
+	default:
+		return fmt.Errorf("cannot scan %T into item IDs", src)
+	}
+	*ids = nil
+	if s == "" {
+		return nil
+	}
+	return json.Unmarshal([]byte(s), (*[]string)(ids))
+}
+
+// normalizedLabel returns label without case, spaces and punctuation, so
+// "Node.js" and "NodeJS" compare equal. + and # are kept to tell C, C++
+// and C# apart.
+func normalizedLabel(label string) string {
+	var b strings.Builder
+	for _, r := range strings.ToLower(label) {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) || r == '+' || r == '#' {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// editDistance returns the Levenshtein distance between a and b in runes.
+func editDistance(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	prev, cur := make([]int, len(rb)+1), make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		cur[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev, cur = cur, prev
+	}
+	return prev[len(rb)]
+}
+
+// similarLabels reports whether two normalized labels are likely to name
+// the same technology: equal, or a typo apart. Short labels must be equal,
+// since "Vue" and "Vuex" are different things.
+func similarLabels(a, b string) bool {
+	if a == b {
+		return true
+	}
+	shortest := min(len([]rune(a)), len([]rune(b)))
+	switch d := editDistance(a, b); {
+	case shortest >= 10:
+		return d <= 2
+	case shortest >= 5:
+		return d <= 1
+	}
+	return false
+}
+
+// Reasons items are reported as duplicates.
+const (
+	duplicateSameLabel    = "same label ignoring case and punctuation"
+	duplicateSimilarLabel = "similar labels"
+)
+
+// DuplicateItem is an item of a DuplicateGroup, with the data file it was
+// loaded from.
+type DuplicateItem struct {
+	RadarItem
+	File string `json:"file,omitempty"`
+}
+
+// DuplicateGroup is a set of items whose labels are likely to name the same
+// technology.
+type DuplicateGroup struct {
+	Reason string          `json:"reason"`
+	Items  []DuplicateItem `json:"items"`
+}
+
+// findDuplicates groups the items whose labels are similar, directly or
+// through other items of the group, in the order of their first item.
+func findDuplicates(items []RadarItem) []DuplicateGroup {
+	normalized := make([]string, len(items))
+	for i, item := range items {
+		normalized[i] = normalizedLabel(item.Label)
+	}
+	// group[i] is the first item of the group item i belongs to.
+	group := make([]int, len(items))
+	for i := range group {
+		group[i] = i
+	}
+	for i := range items {
+		for j := i + 1; j < len(items); j++ {
+			if group[j] != group[i] && similarLabels(normalized[i], normalized[j]) {
+				from, to := max(group[i], group[j]), min(group[i], group[j])
+				for k := range group {
+					if group[k] == from {
+						group[k] = to
+					}
+				}
+			}
+		}
+	}
+
+	var groups []DuplicateGroup
+	index := map[int]int{}
+	for i, item := range items {
+		g, ok := index[group[i]]
+		if !ok {
+			g = len(groups)
+			index[group[i]] = g
+			groups = append(groups, DuplicateGroup{Reason: duplicateSameLabel})
+		}
+		groups[g].Items = append(groups[g].Items, DuplicateItem{RadarItem: item, File: item.Source})
+		if normalized[i] != normalized[group[i]] {
+			groups[g].Reason = duplicateSimilarLabel
+		}
+	}
+	return slices.DeleteFunc(groups, func(g DuplicateGroup) bool { return len(g.Items) < 2 })
+}
+
+// logDuplicates warns about the likely duplicates among items.
+func logDuplicates(items []RadarItem) {
+	for _, group := range findDuplicates(items) {
+		var labels []string
+		for _, item := range group.Items {
+			labels = append(labels, fmt.Sprintf("%q (%s)", item.Label, item.File))
+		}
+		log.Printf("Possible duplicate items, %s: %s", group.Reason, strings.Join(labels, ", "))
+	}
+}
+
+// mergeItems returns into with the descriptions, owners, tags, links and
+// relations of items added, and their IDs recorded in MergedFrom so their
+// history is shown with it.
+func mergeItems(into RadarItem, items []RadarItem) RadarItem {
+	into.Owners = slices.Clone(into.Owners)
+	into.Tags = slices.Clone(into.Tags)
+	into.Links = slices.Clone(into.Links)
+	into.Relations = slices.Clone(into.Relations)
+	into.MergedFrom = slices.Clone(into.MergedFrom)
+	for _, item := range items {
+		switch desc := strings.TrimSpace(item.Description); {
+		case desc == "" || strings.Contains(into.Description, desc):
+		case strings.TrimSpace(into.Description) == "":
+			into.Description = desc
+		default:
+			into.Description = strings.TrimRight(into.Description, "\n") + "\n\n" + desc
+		}
+		for _, owner := range item.Owners {
+			if !slices.ContainsFunc(into.Owners, func(o Owner) bool {
+				return strings.EqualFold(o.Name, owner.Name) && strings.EqualFold(o.Email, owner.Email)
+			}) {
+				into.Owners = append(into.Owners, owner)
+			}
+		}
+		for _, tag := range item.Tags {
+			if !slices.Contains(into.Tags, tag) {
+				into.Tags = append(into.Tags, tag)
+			}
+		}
+		for _, link := range item.Links {
+			if !slices.ContainsFunc(into.Links, func(l Link) bool { return l.URL == link.URL }) {
+				into.Links = append(into.Links, link)
+			}
+		}
+		for _, relation := range item.Relations {
+			if !slices.Contains(into.Relations, relation) {
+				into.Relations = append(into.Relations, relation)
+			}
+		}
+		for _, id := range append([]string{item.ID}, item.MergedFrom...) {
+			if !slices.Contains(into.MergedFrom, id) {
+				into.MergedFrom = append(into.MergedFrom, id)
+			}
+		}
+	}
+	return into
+}
+
+// duplicatesHandler serves the groups of likely duplicate items.
+func duplicatesHandler(w http.ResponseWriter, r *http.Request) {
+	data, err := loadRadarData()
+	if err != nil {
+		handleError(w, err)
+		return
+	}
+
+	groups := findDuplicates(data.Items)
+	if groups == nil {
+		groups = []DuplicateGroup{}
+	}
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(map[string][]DuplicateGroup{"duplicates": groups}); err != nil {
+		handleError(w, &AppError{Code: http.StatusInternalServerError, Message: "Failed to encode response", Err: err})
+	}
+}
+
+// mergeRequest is the body of POST /api/admin/duplicates/merge: the ID of
+// the item to keep and those of the items to merge into it.
+type mergeRequest struct {
+	Into  string   `json:"into"`
+	Items []string `json:"items"`
+}
+
+// mergeHandler merges items into another one and removes them, responding
+// with the merged item as saved.
+func mergeHandler(w http.ResponseWriter, r *http.Request) {
+	var req mergeRequest
+	dec := json.NewDecoder(io.LimitReader(r.Body, maxMergeRequestSize))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&req); err != nil {
+		handleError(w, &AppError{Code: http.StatusBadRequest, Message: "Invalid merge request: " + err.Error(), Err: err})
+		return
+	}
+	if req.Into == "" || len(req.Items) == 0 || slices.Contains(req.Items, req.Into) {
+		handleError(w, &AppError{Code: http.StatusBadRequest, Message: "Invalid merge request: into and items must name different items"})
+		return
+	}
+
+	store := currentStore()
+	if store == nil {
+		handleError(w, &AppError{Code: http.StatusServiceUnavailable, Message: "Radar data not available yet"})
+		return
+	}
+	data, err := loadStoreData(store)
+	if err != nil {
+		handleError(w, err)
+		return
+	}
+	into, ok := findItem(data.Items, req.Into)
+	if !ok {
+		handleError(w, &AppError{Code: http.StatusNotFound, Message: fmt.Sprintf("Unknown item %q", req.Into)})
+		return
+	}
+	var merged []RadarItem
+	for _, id := range req.Items {
+		item, ok := findItem(data.Items, id)
+		if !ok {
+			handleError(w, &AppError{Code: http.StatusNotFound, Message: fmt.Sprintf("Unknown item %q", id)})
+			return
+		}
+		merged = append(merged, item)
+	}
+
+	into = mergeItems(into, merged)
+	data.Items = slices.DeleteFunc(slices.Clone(data.Items), func(item RadarItem) bool { return slices.Contains(req.Items, item.ID) })
+	data.Items[slices.IndexFunc(data.Items, func(item RadarItem) bool { return item.ID == req.Into })] = into
+	// Relations to the merged items are to the item they are merged into.
+	data.Items = retargetRelations(data.Items, req.Items, req.Into)
+	detail := fmt.Sprintf("%s into %s", strings.Join(req.Items, ", "), req.Into)
+	ctx := withRequestAudit(r, AuditEvent{Action: "merge", Detail: detail})
+	var errs ValidationErrors
+	err = store.Save(ctx, data)
+	if errors.Is(err, errReadOnly) {
+		handleError(w, &AppError{Code: http.StatusConflict, Message: "Merging requires a store or data.path naming a single local data file", Err: err})
+		return
+	}
+	if errors.As(err, &errs) {
+		handleError(w, invalidDataError("Invalid radar data", errs))
+		return
+	}
+	if err != nil {
+		handleError(w, &AppError{Code: http.StatusInternalServerError, Message: "Failed to write radar data", Err: err})
+		return
+	}
+	log.Printf("Merged %s", detail)
+
+	if data, err = loadStoreData(store); err != nil {
+		handleError(w, err)
+		return
+	}
+	into, _ = findItem(data.Items, req.Into)
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(into); err != nil {
+		handleError(w, &AppError{Code: http.StatusInternalServerError, Message: "Failed to encode response", Err: err})
+	}
+}
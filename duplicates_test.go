@@ -0,0 +1,163 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSimilarLabels(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want bool
+	}{
+		{"Node.js", "NodeJS", true},
+		{"Kubernetes", "Kubernets", true},
+		{"Elasticsearch", "ElasticSerch", true},
+		{"Terraform", "Terraform Cloud", false},
+		{"C", "C++", false},
+		{"C++", "C#", false},
+		{"Vue", "Vuex", false},
+		{"React", "Preact", true},
+		{"Go", "Golang", false},
+	}
+	for _, tt := range tests {
+		if got := similarLabels(normalizedLabel(tt.a), normalizedLabel(tt.b)); got != tt.want {
+			t.Errorf("similarLabels(%q, %q) = %v, want %v", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
+
+func TestFindDuplicates(t *testing.T) {
+	items := []RadarItem{
+		{ID: "nodejs", Label: "NodeJS", Source: "a.yaml"},
+		{ID: "go", Label: "Go", Source: "a.yaml"},
+		{ID: "kubernetes", Label: "Kubernetes", Source: "a.yaml"},
+		{ID: "node-js", Label: "Node.js", Source: "b.yaml"},
+		{ID: "kubernets", Label: "Kubernets", Source: "b.yaml"},
+	}
+	type group struct {
+		Reason string
+		IDs    []string
+	}
+	var got []group
+	for _, g := range findDuplicates(items) {
+		ids := []string{}
+		for _, item := range g.Items {
+			ids = append(ids, item.ID+"@"+item.File)
+		}
+		got = append(got, group{g.Reason, ids})
+	}
+	want := []group{
+		{duplicateSameLabel, []string{"nodejs@a.yaml", "node-js@b.yaml"}},
+		{duplicateSimilarLabel, []string{"kubernetes@a.yaml", "kubernets@b.yaml"}},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("findDuplicates() = %v, want %v", got, want)
+	}
+}
+
+func TestMergeItems(t *testing.T) {
+	into := RadarItem{ID: "nodejs", Label: "Node.js", Description: "Server-side JS.", Tags: []string{"js"},
+		Owners: []Owner{{Name: "Ana"}}, Links: []Link{{URL: "https://nodejs.org"}}}
+	got := mergeItems(into, []RadarItem{
+		{ID: "node", Label: "NodeJS", Description: "Server-side JS.", Tags: []string{"js", "backend"}, Owners: []Owner{{Name: "ana"}, {Name: "Bo"}}},
+		{ID: "node-js", Label: "Node JS", Description: "Use the LTS release.", Links: []Link{{URL: "https://nodejs.org"}, {URL: "https://nodejs.dev"}}, MergedFrom: ItemIDs{"nodejs-lts"},
+			Relations: Relations{{relationDependsOn, "v8"}}},
+	})
+	want := RadarItem{ID: "nodejs", Label: "Node.js", Description: "Server-side JS.\n\nUse the LTS release.", Tags: []string{"js", "backend"},
+		Owners: []Owner{{Name: "Ana"}, {Name: "Bo"}}, Links: []Link{{URL: "https://nodejs.org"}, {URL: "https://nodejs.dev"}},
+		Relations: Relations{{relationDependsOn, "v8"}}, MergedFrom: ItemIDs{"node", "node-js", "nodejs-lts"}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("mergeItems() = %+v, want %+v", got, want)
+	}
+	if len(into.Tags) != 1 {
+		t.Errorf("mergeItems() changed the tags of into: %v", into.Tags)
+	}
+}
+
+func TestWithMergedHistory(t *testing.T) {
+	day := func(d int) time.Time { return time.Date(2024, 1, d, 0, 0, 0, 0, time.UTC) }
+	histories := []ItemHistory{
+		{ID: "node-js", Label: "Node JS", Events: []HistoryEvent{{Event: historyAdded, Date: day(1)}, {Event: historyRemoved, Date: day(5)}}},
+		{ID: "nodejs", Label: "Node.js", Events: []HistoryEvent{{Event: historyAdded, Date: day(3)}}},
+		{ID: "go", Label: "Go", Events: []HistoryEvent{{Event: historyAdded, Date: day(2)}}},
+	}
+	got := withMergedHistory(histories, []RadarItem{{ID: "nodejs", MergedFrom: ItemIDs{"node-js"}}, {ID: "go"}})
+	want := []ItemHistory{
+		{ID: "nodejs", Label: "Node.js", Events: []HistoryEvent{
+			{Event: historyAdded, Date: day(1), Label: "Node JS"},
+			{Event: historyAdded, Date: day(3)},
+			{Event: historyRemoved, Date: day(5), Label: "Node JS"},
+		}},
+		histories[2],
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("withMergedHistory() = %+v, want %+v", got, want)
+	}
+	if len(histories[1].Events) != 1 {
+		t.Errorf("withMergedHistory() changed its argument: %+v", histories[1])
+	}
+}
+
+func TestDuplicatesEndpoints(t *testing.T) {
+	cfg := defaultConfig()
+	cfg.Admin.Token = "s3cret"
+	cfg.Data.Path = writeFile(t, "radar.yaml", radarWith("Node.js", "Adopted")+
+		"  Tags: [js]\n- Label: NodeJS\n  Quadrant: Tools\n  Ring: Adopted\n  Tags: [backend]\n  Description: Use the LTS release.\n")
+	useConfig(t, cfg)
+	handler, err := setupRoutes(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	send := func(method, target, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, target, strings.NewReader(body))
+		req.Header.Set("Authorization", "Bearer s3cret")
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		return rec
+	}
+
+	if rec := doRequest(t, handler, http.MethodGet, "/api/admin/duplicates"); rec.Code != http.StatusUnauthorized {
+		t.Errorf("without token: status = %d, want 401", rec.Code)
+	}
+	rec := send(http.MethodGet, "/api/admin/duplicates", "")
+	var report struct {
+		Duplicates []DuplicateGroup `json:"duplicates"`
+	}
+	if err := json.NewDecoder(rec.Body).Decode(&report); err != nil {
+		t.Fatalf("GET /api/admin/duplicates: status %d: %v", rec.Code, err)
+	}
+	if len(report.Duplicates) != 1 || len(report.Duplicates[0].Items) != 2 || report.Duplicates[0].Reason != duplicateSameLabel {
+		t.Fatalf("GET /api/admin/duplicates = %+v", report)
+	}
+	ids := []string{report.Duplicates[0].Items[0].ID, report.Duplicates[0].Items[1].ID}
+
+	for body, want := range map[string]int{
+		`{"into": "` + ids[0] + `"}`:                                      http.StatusBadRequest,
+		`{"into": "` + ids[0] + `", "items": ["` + ids[0] + `"]}`:         http.StatusBadRequest,
+		`{"into": "` + ids[0] + `", "items": ["cobol"]}`:                  http.StatusNotFound,
+		`{"into": "` + ids[0] + `", "items": ["` + ids[1] + `"], "x": 1}`: http.StatusBadRequest,
+	} {
+		if rec := send(http.MethodPost, "/api/admin/duplicates/merge", body); rec.Code != want {
+			t.Errorf("merge %s: status = %d, want %d: %s", body, rec.Code, want, rec.Body)
+		}
+	}
+
+	rec = send(http.MethodPost, "/api/admin/duplicates/merge", `{"into": "`+ids[0]+`", "items": ["`+ids[1]+`"]}`)
+	var merged RadarItem
+	if err := json.NewDecoder(rec.Body).Decode(&merged); err != nil {
+		t.Fatalf("merge: status %d: %v", rec.Code, err)
+	}
+	if merged.ID != ids[0] || !reflect.DeepEqual(merged.Tags, Tags{"js", "backend"}) || !reflect.DeepEqual(merged.MergedFrom, ItemIDs{ids[1]}) {
+		t.Errorf("merge = %+v", merged)
+	}
+	data, err := readRadarData(cfg.Data.Path)
+	if err != nil || len(data.Items) != 1 || data.Items[0].Description != "Use the LTS release." || !reflect.DeepEqual(data.Items[0].MergedFrom, ItemIDs{ids[1]}) {
+		t.Errorf("data file items = %+v, %v", data.Items, err)
+	}
+}
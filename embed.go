@@ -0,0 +1,86 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// Bounds of the ?size= of the embedded radar, in CSS pixels.
+const (
+	minEmbedSize = 200
+	maxEmbedSize = 4000
+)
+
+// embedPageData is the context passed to the embed template.
+type embedPageData struct {
+	RadarData
+	BasePath string
+	// Size is the width and height of the radar in CSS pixels, or 0 to fill
+	// the frame.
+	Size int
+	// RadarURL is the main page with the filters of the embedded radar.
+	RadarURL  string
+	OEmbedURL string
+}
+
+// frameAncestorsPolicy returns the Content-Security-Policy letting the
+// pages of ancestors frame a response.
+func frameAncestorsPolicy(ancestors []string) string {
+	if len(ancestors) == 0 {
+		return "frame-ancestors 'none'"
+	}
+	return "frame-ancestors " + strings.Join(ancestors, " ")
+}
+
+// newEmbedHandler returns the handler serving the radar without the page
+// around it, for other pages to show in a frame. It takes the filters of
+// parseRadarFilter, which the radar passes on to the API, and ?size=.
+func newEmbedHandler(cfg Config) (http.Handler, error) {
+	load, err := templateLoader(cfg, embedTemplate)
+	if err != nil {
+		return nil, err
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		page := embedPageData{BasePath: cfg.Server.BasePath, OEmbedURL: oEmbedDiscoveryURL(r)}
+		if value := query.Get("size"); value != "" {
+			size, err := strconv.Atoi(value)
+			if err != nil || size < minEmbedSize || size > maxEmbedSize {
+				handleError(w, &AppError{Code: http.StatusBadRequest, Message: fmt.Sprintf("Invalid size %q, must be a number of pixels from %d to %d", value, minEmbedSize, maxEmbedSize)})
+				return
+			}
+			page.Size = size
+		}
+		query.Del("size")
+		page.RadarURL = cfg.Server.BasePath + "/"
+		if len(query) > 0 {
+			page.RadarURL += "?" + query.Encode()
+		}
+
+		tmpl, err := load()
+		if err != nil {
+			handleError(w, &AppError{Code: http.StatusInternalServerError, Message: "Failed to load template", Err: err})
+			return
+		}
+		data, err := loadRadarData()
+		if err != nil {
+			handleError(w, err)
+			return
+		}
+		page.RadarData = withSegments(data)
+		// Reject unknown quadrants and rings here rather than show a frame
+		// that fails to load.
+		if _, err := parseRadarFilter(query, page.RadarData); err != nil {
+			handleError(w, err)
+			return
+		}
+
+		w.Header().Set("Content-Security-Policy", frameAncestorsPolicy(cfg.Server.FrameAncestors))
+		if err := tmpl.Execute(w, page); err != nil {
+			handleError(w, &AppError{Code: http.StatusInternalServerError, Message: "Failed to render template", Err: err})
+		}
+	}), nil
+}
@@ -0,0 +1,81 @@
+package main
+
+import (
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestEmbedHandler(t *testing.T) {
+	cfg := defaultConfig()
+	cfg.Server.BasePath = "/tech-radar"
+	cfg.Server.FrameAncestors = []string{"https://acme.atlassian.net", "https://*.backstage.example.com"}
+	cfg.Data.Path = writeFile(t, "radar.yaml", radarWith("Go", "Adopted"))
+	useConfig(t, cfg)
+	handler, err := setupRoutes(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rec := doRequest(t, handler, http.MethodGet, "/tech-radar/embed?quadrant=Tools&ring=Adopted&size=600")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("GET /embed = %d %q", rec.Code, rec.Body.String())
+	}
+	if got, want := rec.Header().Get("Content-Security-Policy"), "frame-ancestors https://acme.atlassian.net https://*.backstage.example.com"; got != want {
+		t.Errorf("Content-Security-Policy = %q, want %q", got, want)
+	}
+	if got := rec.Header().Get("X-Frame-Options"); got != "" {
+		t.Errorf("X-Frame-Options = %q, want none", got)
+	}
+	body := rec.Body.String()
+	for _, want := range []string{
+		`style="width: 600px; height: 600px"`,
+		`href="/tech-radar/?quadrant=Tools&amp;ring=Adopted"`,
+		`src="/tech-radar/static/radar.js"`,
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("GET /embed body doesn't contain %s", want)
+		}
+	}
+	if strings.Contains(body, "search-input") {
+		t.Error("GET /embed has the search box of the main page")
+	}
+
+	// The main page can only be framed by the radar itself.
+	rec = doRequest(t, handler, http.MethodGet, "/tech-radar/")
+	if rec.Header().Get("X-Frame-Options") != "SAMEORIGIN" || rec.Header().Get("Content-Security-Policy") != "frame-ancestors 'self'" {
+		t.Errorf("GET / = X-Frame-Options %q, Content-Security-Policy %q", rec.Header().Get("X-Frame-Options"), rec.Header().Get("Content-Security-Policy"))
+	}
+
+	for _, target := range []string{"/tech-radar/embed?size=big", "/tech-radar/embed?size=50", "/tech-radar/embed?ring=Hold"} {
+		if rec := doRequest(t, handler, http.MethodGet, target); rec.Code != http.StatusBadRequest {
+			t.Errorf("GET %s = %d, want 400", target, rec.Code)
+		}
+	}
+}
+
+func TestEmbedHandlerCustomTemplates(t *testing.T) {
+	// A templates directory with only index.html embeds the built-in
+	// radar.
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, indexTemplate), []byte("custom"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	cfg := defaultConfig()
+	cfg.Data.Templates = dir
+	cfg.Data.Path = writeFile(t, "radar.yaml", radarWith("Go", "Adopted"))
+	useConfig(t, cfg)
+	handler, err := setupRoutes(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rec := doRequest(t, handler, http.MethodGet, "/embed")
+	if rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), `id="radar"`) || !strings.Contains(rec.Body.String(), "w-screen h-screen") {
+		t.Errorf("GET /embed = %d %q", rec.Code, rec.Body.String())
+	}
+	if got := rec.Header().Get("Content-Security-Policy"); got != "frame-ancestors *" {
+		t.Errorf("Content-Security-Policy = %q, want any ancestor by default", got)
+	}
+}
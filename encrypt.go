@@ -0,0 +1,174 @@
+package main
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+)
+
+// encryptedMagic starts all data encrypted by sealData, so encrypted and
+// plain data can be told apart. It is followed by the nonce and the
+// AES-GCM ciphertext.
+var encryptedMagic = []byte("clean-tech-radar encrypted v1\n")
+
+// errNoEncryptionKey is returned when reading encrypted data without a key.
+var errNoEncryptionKey = errors.New("data is encrypted but no encryption key is configured")
+
+// dataCipher encrypts data files and bbolt values when encryption.key is
+// set. It is set once at startup.
+var dataCipher cipher.AEAD
+
+// parseEncryptionKey decodes a 256-bit AES key given as 64 hex digits or in
+// base64.
+func parseEncryptionKey(s string) ([]byte, error) {
+	s = strings.TrimSpace(s)
+	key, err := hex.DecodeString(s)
+	if err != nil {
+		key, err = base64.StdEncoding.DecodeString(s)
+	}
+	if err != nil || len(key) != 32 {
+		return nil, errors.New("encryption.key must be 32 bytes, hex or base64 encoded")
+	}
+	return key, nil
+}
+
+// newDataCipher returns the AES-GCM cipher of an encryption key, or nil if
+// key is empty.
+func newDataCipher(key string) (cipher.AEAD, error) {
+	if key == "" {
+		return nil, nil
+	}
+	raw, err := parseEncryptionKey(key)
+	if err != nil {
+		return nil, err
+	}
+	block, err := aes.NewCipher(raw)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// useEncryptionKey sets dataCipher from the encryption.key of cfg.
+func useEncryptionKey(cfg Config) error {
+	c, err := newDataCipher(cfg.Encryption.Key)
+	if err != nil {
+		return err
+	}
+	dataCipher = c
+	return nil
+}
+
+// isEncrypted reports whether content was encrypted by sealData.
+func isEncrypted(content []byte) bool {
+	return bytes.HasPrefix(content, encryptedMagic)
+}
+
+// sealData encrypts plain with dataCipher, or returns it as is when no key
+// is configured.
+func sealData(plain []byte) ([]byte, error) {
+	if dataCipher == nil {
+		return plain, nil
+	}
+	nonce := make([]byte, dataCipher.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	sealed := append(bytes.Clone(encryptedMagic), nonce...)
+	return dataCipher.Seal(sealed, nonce, plain, nil), nil
+}
+
+// openData decrypts content encrypted by sealData. Plain content is
+// returned as is, so data written before encryption was enabled stays
+// readable until it is saved again.
+func openData(content []byte) ([]byte, error) {
+	if !isEncrypted(content) {
+		return content, nil
+	}
+	if dataCipher == nil {
+		return nil, errNoEncryptionKey
+	}
+	sealed := content[len(encryptedMagic):]
+	if len(sealed) < dataCipher.NonceSize() {
+		return nil, errors.New("encrypted data is truncated")
+	}
+	nonce, ciphertext := sealed[:dataCipher.NonceSize()], sealed[dataCipher.NonceSize():]
+	plain, err := dataCipher.Open(nil, nonce, ciphertext, nil)
+	if err != nil {
+		return nil, errors.New("decrypting data failed: wrong encryption key or corrupted data")
+	}
+	return plain, nil
+}
+
+// readDataFile reads a data file, decrypting it if it is encrypted.
+func readDataFile(file string) ([]byte, error) {
+	content, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	plain, err := openData(content)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", file, err)
+	}
+	return plain, nil
+}
+
+// runCrypt implements the encrypt and decrypt commands, which rewrite the
+// local data files with or without the configured encryption key. Files
+// that are already encrypted, or plain, are left alone.
+func runCrypt(name string, args []string) error {
+	cfg, err := loadConfig(args)
+	if err != nil {
+		return err
+	}
+	if err := useEncryptionKey(cfg); err != nil {
+		return err
+	}
+	if dataCipher == nil {
+		return errors.New("no encryption key is configured, set RADAR_ENCRYPTION_KEY or RADAR_ENCRYPTION_KEY_FILE")
+	}
+	path := cfg.Data.dataPath()
+	if isRemoteDataPath(path) {
+		return fmt.Errorf("%s requires local data files, not a URL", name)
+	}
+	files, err := dataFiles(path)
+	if err != nil {
+		return err
+	}
+
+	encrypt := name == "encrypt"
+	for _, file := range files {
+		content, err := os.ReadFile(file)
+		if err != nil {
+			return err
+		}
+		if isEncrypted(content) == encrypt {
+			continue
+		}
+		if encrypt {
+			content, err = sealData(content)
+		} else {
+			content, err = openData(content)
+		}
+		if err != nil {
+			return fmt.Errorf("%s: %w", file, err)
+		}
+		if err := replaceFile(file, content, 0o644); err != nil {
+			return err
+		}
+		if encrypt {
+			log.Printf("Encrypted %s", file)
+		} else {
+			log.Printf("Decrypted %s", file)
+		}
+	}
+	return nil
+}
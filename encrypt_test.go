@@ -0,0 +1,160 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+// testEncryptionKey is a valid encryption.key, as hex.
+var testEncryptionKey = strings.Repeat("0123456789abcdef", 4)
+
+// useEncryption sets dataCipher from key for the duration of the test.
+func useEncryption(t *testing.T, key string) {
+	t.Helper()
+	previous := dataCipher
+	c, err := newDataCipher(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	dataCipher = c
+	t.Cleanup(func() { dataCipher = previous })
+}
+
+func TestSealAndOpenData(t *testing.T) {
+	plain := []byte("Items: []\n")
+	useEncryption(t, testEncryptionKey)
+	sealed, err := sealData(plain)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !isEncrypted(sealed) || bytes.Contains(sealed, plain) {
+		t.Fatalf("sealData() = %q, want it encrypted", sealed)
+	}
+	if got, err := openData(sealed); err != nil || !bytes.Equal(got, plain) {
+		t.Errorf("openData() = %q, %v, want %q", got, err, plain)
+	}
+	if got, err := openData(plain); err != nil || !bytes.Equal(got, plain) {
+		t.Errorf("openData(plain) = %q, %v, want it unchanged", got, err)
+	}
+
+	// The same key in base64 opens it too; another key doesn't.
+	useEncryption(t, "ASNFZ4mrze8BI0VniavN7wEjRWeJq83vASNFZ4mrze8=")
+	if _, err := openData(sealed); err != nil {
+		t.Errorf("openData() with the base64 key error = %v", err)
+	}
+	useEncryption(t, strings.Repeat("f", 64))
+	if _, err := openData(sealed); err == nil || !strings.Contains(err.Error(), "wrong encryption key") {
+		t.Errorf("openData() with another key error = %v", err)
+	}
+	useEncryption(t, "")
+	if _, err := openData(sealed); !errors.Is(err, errNoEncryptionKey) {
+		t.Errorf("openData() without a key error = %v, want %v", err, errNoEncryptionKey)
+	}
+	if got, _ := sealData(plain); !bytes.Equal(got, plain) {
+		t.Errorf("sealData() without a key = %q, want it unchanged", got)
+	}
+}
+
+func TestFileStoreEncrypts(t *testing.T) {
+	useEncryption(t, testEncryptionKey)
+	path := writeFile(t, "radar.yaml", radarWith("Go", "Adopted"))
+	store := newFileStore(path)
+	defer store.Close()
+
+	// Plain data is still read, and encrypted when saved.
+	data, err := store.Load(context.Background())
+	if err != nil || len(data.Items) != 1 {
+		t.Fatalf("Load() = %+v, %v", data, err)
+	}
+	data.Items[0].Ring = "In Discovery"
+	if err := store.Save(context.Background(), data); err != nil {
+		t.Fatal(err)
+	}
+	content, err := os.ReadFile(path)
+	if err != nil || !isEncrypted(content) || bytes.Contains(content, []byte("Go")) {
+		t.Fatalf("data file = %q, %v, want it encrypted", content, err)
+	}
+	saved, err := readRadarData(path)
+	if err != nil || len(saved.Items) != 1 || saved.Items[0].Ring != "In Discovery" {
+		t.Errorf("readRadarData() = %+v, %v", saved, err)
+	}
+	if err := validateRadarData(path); err != nil {
+		t.Errorf("validateRadarData() error = %v", err)
+	}
+
+	useEncryption(t, "")
+	if _, err := readRadarData(path); err == nil || !strings.Contains(err.Error(), errNoEncryptionKey.Error()) {
+		t.Errorf("readRadarData() without a key error = %v, want %v", err, errNoEncryptionKey)
+	}
+}
+
+func TestBoltStoreEncrypts(t *testing.T) {
+	useEncryption(t, testEncryptionKey)
+	dsn := filepath.Join(t.TempDir(), "radar.bolt")
+	store := openTestStore(t, "bbolt", dsn)
+	testStore(t, store)
+	store.Close()
+
+	db, err := bolt.Open(dsn, 0o600, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	err = db.View(func(tx *bolt.Tx) error {
+		return tx.ForEach(func(name []byte, b *bolt.Bucket) error {
+			return b.ForEach(func(k, v []byte) error {
+				if !isEncrypted(v) {
+					t.Errorf("%s/%x = %q, want it encrypted", name, k, v)
+				}
+				return nil
+			})
+		})
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestRunCrypt(t *testing.T) {
+	clearRadarEnv(t)
+	useEncryption(t, "")
+	dir := writeDataDir(t, map[string]string{
+		"a.yaml": radarWith("Go", "Adopted"),
+		"b.json": `{"Items": [{"Label": "Rust", "Quadrant": "Tools", "Ring": "Adopted"}]}`,
+	})
+	if err := runCrypt("encrypt", []string{"-data", dir}); err == nil || !strings.Contains(err.Error(), "no encryption key") {
+		t.Errorf("runCrypt() without a key error = %v", err)
+	}
+
+	t.Setenv("RADAR_ENCRYPTION_KEY", testEncryptionKey)
+	if err := runCrypt("encrypt", []string{"-data", dir}); err != nil {
+		t.Fatal(err)
+	}
+	// Encrypting again leaves encrypted files alone.
+	if err := runCrypt("encrypt", []string{"-data", dir}); err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"a.yaml", "b.json"} {
+		content, _ := os.ReadFile(filepath.Join(dir, name))
+		if !isEncrypted(content) {
+			t.Errorf("%s = %q, want it encrypted", name, content)
+		}
+	}
+	if data, err := readRadarData(dir); err != nil || len(data.Items) != 2 {
+		t.Errorf("readRadarData() = %+v, %v", data, err)
+	}
+
+	if err := runCrypt("decrypt", []string{"-data", dir}); err != nil {
+		t.Fatal(err)
+	}
+	if content, _ := os.ReadFile(filepath.Join(dir, "a.yaml")); string(content) != radarWith("Go", "Adopted") {
+		t.Errorf("decrypted a.yaml =\n%s", content)
+	}
+}
@@ -0,0 +1,137 @@
+package main
+
+import (
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"html/template"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// atomContentType is the media type of Atom feeds.
+const atomContentType = "application/atom+xml"
+
+// feedEntries is the number of changes, the most recent ones, listed in the
+// Atom feed.
+const feedEntries = 50
+
+// atomFeed is an Atom feed document, RFC 4287.
+type atomFeed struct {
+	XMLName xml.Name    `xml:"http://www.w3.org/2005/Atom feed"`
+	ID      string      `xml:"id"`
+	Title   string      `xml:"title"`
+	Updated time.Time   `xml:"updated"`
+	Author  atomPerson  `xml:"author"`
+	Links   []atomLink  `xml:"link"`
+	Entries []atomEntry `xml:"entry"`
+}
+
+// atomPerson is the author of an Atom feed.
+type atomPerson struct {
+	Name string `xml:"name"`
+}
+
+// atomLink is a link of an Atom feed or entry.
+type atomLink struct {
+	Rel  string `xml:"rel,attr"`
+	Type string `xml:"type,attr,omitempty"`
+	Href string `xml:"href,attr"`
+}
+
+// atomEntry is an entry of an Atom feed.
+type atomEntry struct {
+	ID      string      `xml:"id"`
+	Title   string      `xml:"title"`
+	Updated time.Time   `xml:"updated"`
+	Link    atomLink    `xml:"link"`
+	Content atomContent `xml:"content"`
+}
+
+// atomContent is the HTML content of an Atom entry.
+type atomContent struct {
+	Type string `xml:"type,attr"`
+	Body string `xml:",chardata"`
+}
+
+// feedEntryContent is the HTML content of the entry of a change.
+var feedEntryContent = template.Must(template.New("entry").Parse(`<p>{{.Ring}} &middot; {{.Quadrant}}</p>` +
+	`{{with .Description}}<p>{{.}}</p>{{end}}` +
+	`{{with .Links}}<ul>{{range .}}<li><a href="{{.URL}}">{{or .Title .URL}}</a></li>{{end}}</ul>{{end}}`))
+
+// feedEntryTitle returns the title of the entry of change.
+func feedEntryTitle(change ItemChange) string {
+	if change.Event == changeMoved {
+		return fmt.Sprintf("%s moved from %s to %s", change.Label, change.PreviousRing, change.Item.Ring)
+	}
+	return fmt.Sprintf("%s added to %s", change.Label, change.Item.Ring)
+}
+
+// feedEntryID returns the ID of the entry of change, a tag URI of host that
+// stays the same when the feed is generated again.
+func feedEntryID(host string, change ItemChange) string {
+	version := change.Commit
+	if version == "" {
+		version = fmt.Sprintf("snapshot-%d", change.Snapshot)
+	}
+	return fmt.Sprintf("tag:%s,%s:%s/%s/%s", (&url.URL{Host: host}).Hostname(), change.Time.UTC().Format(time.DateOnly), version, change.Event, url.PathEscape(change.ID))
+}
+
+// feedHandler serves an Atom feed of the items most recently added to the
+// radar or moved to another ring, from the changes loadRadarChanges
+// returns, newest first.
+func feedHandler(w http.ResponseWriter, r *http.Request) {
+	changes, err := loadRadarChanges(r.Context())
+	if errors.Is(err, errNoHistory) {
+		handleError(w, &AppError{Code: http.StatusNotFound, Message: "The feed requires a store or the data files to be in a Git repository"})
+		return
+	}
+	if err != nil {
+		handleError(w, &AppError{Code: http.StatusInternalServerError, Message: "Failed to read radar changes", Err: err})
+		return
+	}
+
+	site := requestOrigin(r) + currentConfig().Server.BasePath + "/"
+	self := requestOrigin(r) + currentConfig().Server.BasePath + "/feed.atom"
+	feed := atomFeed{
+		ID:     self,
+		Title:  "Clean Tech Radar",
+		Author: atomPerson{Name: "Clean Tech Radar"},
+		Links:  []atomLink{{Rel: "self", Type: atomContentType, Href: self}, {Rel: "alternate", Type: "text/html", Href: site}},
+	}
+	for i := len(changes) - 1; i >= 0 && len(feed.Entries) < feedEntries; i-- {
+		change := changes[i]
+		if change.Event != changeCreated && change.Event != changeMoved {
+			continue
+		}
+		var content strings.Builder
+		if err := feedEntryContent.Execute(&content, change.Item); err != nil {
+			handleError(w, &AppError{Code: http.StatusInternalServerError, Message: "Failed to render feed", Err: err})
+			return
+		}
+		feed.Entries = append(feed.Entries, atomEntry{
+			ID:      feedEntryID(r.Host, change),
+			Title:   feedEntryTitle(change),
+			Updated: change.Time.UTC(),
+			Link:    atomLink{Rel: "alternate", Type: "text/html", Href: site + "#" + url.PathEscape(change.ID)},
+			Content: atomContent{Type: "html", Body: content.String()},
+		})
+	}
+	// A feed without entries has never been updated, but must still say
+	// when.
+	feed.Updated = time.Now().UTC().Truncate(time.Second)
+	if len(feed.Entries) > 0 {
+		feed.Updated = feed.Entries[0].Updated
+	}
+
+	body, err := xml.MarshalIndent(feed, "", "  ")
+	if err != nil {
+		handleError(w, &AppError{Code: http.StatusInternalServerError, Message: "Failed to encode feed", Err: err})
+		return
+	}
+	w.Header().Set("Content-Type", atomContentType+"; charset=utf-8")
+	w.Write([]byte(xml.Header))
+	w.Write(body)
+}
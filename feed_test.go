@@ -0,0 +1,62 @@
+package main
+
+import (
+	"encoding/xml"
+	"net/http"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestFeed(t *testing.T) {
+	repo, commit := gitRadarRepo(t)
+	commit(radarWith("Go", "In Discovery"))
+	commit(radarWith("Go", "Adopted") + "- Label: Rust\n  Quadrant: Tools\n  Ring: In Discovery\n  Description: Safe <systems> code.\n")
+	commit(radarWith("Go", "Adopted") + "- Label: Rust\n  Quadrant: Tools\n  Ring: In Discovery\n  Description: Updated.\n")
+
+	cfg := defaultConfig()
+	cfg.Server.BasePath = "/tech-radar"
+	cfg.Data.Path = filepath.Join(repo, "data", "radar.yaml")
+	useConfig(t, cfg)
+	handler, err := setupRoutes(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rec := doRequest(t, handler, http.MethodGet, "http://radar.example.com/tech-radar/feed.atom")
+	if rec.Code != http.StatusOK || rec.Header().Get("Content-Type") != "application/atom+xml; charset=utf-8" {
+		t.Fatalf("GET /feed.atom = %d %q", rec.Code, rec.Header().Get("Content-Type"))
+	}
+	var feed atomFeed
+	if err := xml.Unmarshal(rec.Body.Bytes(), &feed); err != nil {
+		t.Fatal(err)
+	}
+	// Updates are left out, and the latest changes come first.
+	var titles []string
+	for _, entry := range feed.Entries {
+		titles = append(titles, entry.Title)
+	}
+	if want := []string{"Rust added to In Discovery", "Go moved from In Discovery to Adopted", "Go added to In Discovery"}; !reflect.DeepEqual(titles, want) {
+		t.Fatalf("entries = %q, want %q", titles, want)
+	}
+	rust := feed.Entries[0]
+	if rust.Link.Href != "http://radar.example.com/tech-radar/#rust" || !strings.HasPrefix(rust.ID, "tag:radar.example.com,") || rust.ID == feed.Entries[1].ID {
+		t.Errorf("entry = %+v", rust)
+	}
+	if rust.Content.Type != "html" || !strings.Contains(rust.Content.Body, "Safe &lt;systems&gt; code.") {
+		t.Errorf("entry content = %q", rust.Content.Body)
+	}
+	if !feed.Updated.Equal(rust.Updated) || feed.Links[0].Href != "http://radar.example.com/tech-radar/feed.atom" {
+		t.Errorf("feed = %+v", feed)
+	}
+}
+
+func TestFeedOutsideGit(t *testing.T) {
+	cfg := defaultConfig()
+	cfg.Data.Path = writeFile(t, "radar.yaml", radarWith("Go", "Adopted"))
+	useConfig(t, cfg)
+	if rec := doRequest(t, http.HandlerFunc(feedHandler), http.MethodGet, "/feed.atom"); rec.Code != http.StatusNotFound {
+		t.Errorf("GET /feed.atom outside Git = %d, want 404", rec.Code)
+	}
+}
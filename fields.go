@@ -0,0 +1,87 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"reflect"
+	"slices"
+	"strings"
+)
+
+// itemFields are the JSON names of the fields of a RadarItem, in order.
+var itemFields = func() []string {
+	var names []string
+	t := reflect.TypeFor[RadarItem]()
+	for i := range t.NumField() {
+		name, _, _ := strings.Cut(t.Field(i).Tag.Get("json"), ",")
+		if name != "-" && name != "" {
+			names = append(names, name)
+		}
+	}
+	return names
+}()
+
+// parseFields returns the fields of names asked for with ?fields=, as a
+// comma-separated list or by repeating the parameter, or nil for all of
+// them.
+func parseFields(r *http.Request, names []string) ([]string, error) {
+	var fields []string
+	for _, value := range r.URL.Query()["fields"] {
+		for _, name := range strings.Split(value, ",") {
+			name = strings.TrimSpace(name)
+			if name == "" {
+				continue
+			}
+			if !slices.Contains(names, name) {
+				return nil, &AppError{Code: http.StatusBadRequest, Message: fmt.Sprintf("Invalid field %q, must be one of %s", name, strings.Join(names, ", "))}
+			}
+			if !slices.Contains(fields, name) {
+				fields = append(fields, name)
+			}
+		}
+	}
+	return fields, nil
+}
+
+// sparseItems returns items to encode with only fields, or the items
+// themselves if fields is nil. Fields left out of an item's JSON, such as
+// an unset lastUpdated, stay left out.
+func sparseItems(items []RadarItem, fields []string) (any, error) {
+	if fields == nil {
+		return items, nil
+	}
+	return itemRecords(items, fields)
+}
+
+// itemRecords returns the JSON of fields of every item.
+func itemRecords(items []RadarItem, fields []string) ([]map[string]json.RawMessage, error) {
+	records := make([]map[string]json.RawMessage, len(items))
+	for i, item := range items {
+		var err error
+		if records[i], err = sparseItem(item, fields); err != nil {
+			return nil, err
+		}
+	}
+	return records, nil
+}
+
+// sparseItem returns the JSON of fields of item, a RadarItem or a value
+// embedding one.
+func sparseItem(item any, fields []string) (map[string]json.RawMessage, error) {
+	data, err := json.Marshal(item)
+	if err != nil {
+		return nil, err
+	}
+	var all map[string]json.RawMessage
+	if err := json.Unmarshal(data, &all); err != nil {
+		return nil, err
+	}
+	sparse := make(map[string]json.RawMessage, len(fields))
+	for _, name := range fields {
+		if value, ok := all[name]; ok {
+			sparse[name] = value
+		}
+	}
+	return sparse, nil
+}
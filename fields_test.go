@@ -0,0 +1,84 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestItemFields(t *testing.T) {
+	for _, name := range []string{"id", "label", "quadrant", "ring", "lastUpdated", "mergedFrom"} {
+		if !slices.Contains(itemFields, name) {
+			t.Errorf("itemFields = %q, want %q in it", itemFields, name)
+		}
+	}
+	if slices.Contains(itemFields, "-") || slices.Contains(itemFields, "Source") {
+		t.Errorf("itemFields = %q, want fields left out of JSON left out", itemFields)
+	}
+}
+
+func TestSparseFieldsAPI(t *testing.T) {
+	cfg := defaultConfig()
+	cfg.Data.Path = writeFile(t, "radar.yaml", `Items:
+- Label: Go
+  Quadrant: Tools
+  Ring: Adopted
+  Description: A language.
+  Tags: [backend]
+- Label: Rust
+  Quadrant: Tools
+  Ring: In Discovery
+`)
+	useConfig(t, cfg)
+	handler, err := setupRoutes(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []map[string]any{
+		{"label": "Go", "ring": "Adopted", "quadrant": "Tools"},
+		{"label": "Rust", "ring": "In Discovery", "quadrant": "Tools"},
+	}
+	for _, target := range []string{"/api/radar?fields=label,ring,quadrant", "/api/radar/items?fields=label&fields=ring,quadrant,label"} {
+		rec := doRequest(t, handler, http.MethodGet, target)
+		var body struct{ Items []map[string]any }
+		if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+			t.Fatalf("GET %s = %d %q", target, rec.Code, rec.Body.String())
+		}
+		// Items of /api/radar/items keep their _links whatever the fields.
+		for _, item := range body.Items {
+			if _, ok := item["_links"]; ok != strings.HasPrefix(target, "/api/radar/items") {
+				t.Errorf("GET %s item %v, _links = %t", target, item, ok)
+			}
+			delete(item, "_links")
+		}
+		if !reflect.DeepEqual(body.Items, want) {
+			t.Errorf("GET %s items = %v, want %v", target, body.Items, want)
+		}
+	}
+
+	rec := doRequest(t, handler, http.MethodGet, "/api/radar/items/go?fields=id,tags,lastUpdated")
+	var item map[string]any
+	if err := json.Unmarshal(rec.Body.Bytes(), &item); err != nil {
+		t.Fatalf("GET /api/radar/items/go = %d %q", rec.Code, rec.Body.String())
+	}
+	delete(item, "_links")
+	if want := map[string]any{"id": "go", "tags": []any{"backend"}}; !reflect.DeepEqual(item, want) {
+		t.Errorf("GET /api/radar/items/go?fields=id,tags,lastUpdated = %v, want %v", item, want)
+	}
+
+	// Without fields, items have all of them.
+	rec = doRequest(t, handler, http.MethodGet, "/api/radar?fields=")
+	var data RadarData
+	if err := json.Unmarshal(rec.Body.Bytes(), &data); err != nil || len(data.Items) != 2 || data.Items[0].Description != "A language." {
+		t.Errorf("GET /api/radar?fields= = %d %q, want every field", rec.Code, rec.Body.String())
+	}
+	for _, target := range []string{"/api/radar?fields=label,colour", "/api/radar/items?fields=Label", "/api/radar/items/go?fields=source"} {
+		if rec := doRequest(t, handler, http.MethodGet, target); rec.Code != http.StatusBadRequest {
+			t.Errorf("GET %s = %d, want 400", target, rec.Code)
+		}
+	}
+}
@@ -0,0 +1,83 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// radarFilter selects the items GET /api/radar returns. Each parameter may
+// be repeated: an item must be in one of the quadrants and rings, have one
+// of the owners and all of the tags given.
+type radarFilter struct {
+	quadrants, rings, owners []string
+	tags                     Tags
+	moved                    *bool
+}
+
+// parseRadarFilter reads the filter given by query, rejecting quadrants and
+// rings data doesn't define.
+func parseRadarFilter(query url.Values, data RadarData) (radarFilter, error) {
+	f := radarFilter{tags: parseTags(query["tag"]...)}
+	var err error
+	if f.quadrants, err = segmentFilter("quadrant", query["quadrant"], data.quadrants()); err != nil {
+		return radarFilter{}, err
+	}
+	if f.rings, err = segmentFilter("ring", query["ring"], data.rings()); err != nil {
+		return radarFilter{}, err
+	}
+	for _, owner := range query["owner"] {
+		if owner = strings.TrimSpace(owner); owner != "" {
+			f.owners = append(f.owners, owner)
+		}
+	}
+	if value := query.Get("moved"); value != "" {
+		moved, err := strconv.ParseBool(value)
+		if err != nil {
+			return radarFilter{}, &AppError{Code: http.StatusBadRequest, Message: fmt.Sprintf("Invalid moved %q, must be true or false", value)}
+		}
+		f.moved = &moved
+	}
+	return f, nil
+}
+
+// segmentFilter returns the names of the segments given as values of the
+// parameter called name, matched ignoring case.
+func segmentFilter(name string, values []string, segments []Segment) ([]string, error) {
+	var names []string
+	for _, value := range values {
+		i := slices.IndexFunc(segments, func(s Segment) bool { return labelKey(s.Name) == labelKey(value) })
+		if i < 0 {
+			return nil, &AppError{Code: http.StatusBadRequest, Message: fmt.Sprintf("Invalid %s %q, must be one of %s", name, value, strings.Join(segmentNames(segments), ", "))}
+		}
+		names = append(names, segments[i].Name)
+	}
+	return names, nil
+}
+
+// matches reports whether item is selected by the filter.
+func (f radarFilter) matches(item RadarItem) bool {
+	switch {
+	case len(f.quadrants) > 0 && !slices.Contains(f.quadrants, item.Quadrant),
+		len(f.rings) > 0 && !slices.Contains(f.rings, item.Ring),
+		len(f.owners) > 0 && !slices.ContainsFunc(f.owners, func(owner string) bool { return ownedBy(item, owner) }),
+		!hasTags(item, f.tags),
+		f.moved != nil && item.Moved != *f.moved:
+		return false
+	}
+	return true
+}
+
+// ownedBy reports whether one of the owners of item is owner, see is.
+func ownedBy(item RadarItem, owner string) bool {
+	return slices.ContainsFunc(item.Owners, func(o Owner) bool { return o.is(owner) })
+}
+
+// is reports whether o has owner as its name, team or email address,
+// ignoring case.
+func (o Owner) is(owner string) bool {
+	return strings.EqualFold(o.Name, owner) || strings.EqualFold(o.Team, owner) || strings.EqualFold(o.Email, owner)
+}
@@ -0,0 +1,65 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"testing"
+)
+
+func TestRadarFilters(t *testing.T) {
+	cfg := defaultConfig()
+	cfg.Data.Path = writeFile(t, "radar.yaml", `Items:
+- Label: Go
+  Quadrant: Programming Languages & Frameworks
+  Ring: Adopted
+  Owners: [{Name: Ana, Team: Platform}]
+  Tags: [backend]
+- Label: Terraform
+  Quadrant: Tools
+  Ring: Adopted
+  Moved: true
+  Owners: [{Name: Bo, Email: bo@example.com, Team: platform}]
+- Label: Pulumi
+  Quadrant: Tools
+  Ring: In Discovery
+  Owners: [{Name: Cy, Team: Infra}]
+  Tags: [backend]
+`)
+	useConfig(t, cfg)
+	handler, err := setupRoutes(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for query, labels := range map[string][]string{
+		"quadrant=Tools":                                        {"Terraform", "Pulumi"},
+		"quadrant=tools&ring=adopted":                           {"Terraform"},
+		"ring=Adopted&ring=In+Discovery&tag=backend":            {"Go", "Pulumi"},
+		"owner=platform":                                        {"Go", "Terraform"},
+		"owner=Infra&owner=bo@example.com":                      {"Terraform", "Pulumi"},
+		"moved=true":                                            {"Terraform"},
+		"moved=false&quadrant=Tools":                            {"Pulumi"},
+		"quadrant=Tools&ring=Adopted&owner=Platform&moved=true": {"Terraform"},
+		"owner=nobody":                                          nil,
+	} {
+		rec := doRequest(t, handler, http.MethodGet, "/api/radar?"+query)
+		var data RadarData
+		if err := json.Unmarshal(rec.Body.Bytes(), &data); err != nil {
+			t.Fatalf("GET /api/radar?%s = %d %q", query, rec.Code, rec.Body.String())
+		}
+		var got []string
+		for _, item := range data.Items {
+			got = append(got, item.Label)
+		}
+		if !reflect.DeepEqual(got, labels) {
+			t.Errorf("GET /api/radar?%s items = %q, want %q", query, got, labels)
+		}
+	}
+
+	for _, query := range []string{"quadrant=Gadgets", "ring=Adopt", "moved=maybe"} {
+		if rec := doRequest(t, handler, http.MethodGet, "/api/radar?"+query); rec.Code != http.StatusBadRequest {
+			t.Errorf("GET /api/radar?%s = %d, want 400", query, rec.Code)
+		}
+	}
+}
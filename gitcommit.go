@@ -0,0 +1,98 @@
+package main
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"net/mail"
+	"path/filepath"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Committer identity of the commits of writes in repositories that don't
+// configure one.
+const (
+	defaultCommitterName  = "Clean Tech Radar"
+	defaultCommitterEmail = "clean-tech-radar@localhost"
+)
+
+// commitIdentity returns the name and email address of the author of a
+// write made as actor: data.commit.adminAuthor for the admin, or the
+// reviewer called actor with their email address. Others have none.
+func commitIdentity(cfg Config, actor string) (name, email string) {
+	if actor == "admin" && cfg.Data.Commit.AdminAuthor != "" {
+		if addr, err := mail.ParseAddress(cfg.Data.Commit.AdminAuthor); err == nil {
+			return cmp.Or(addr.Name, actor), addr.Address
+		}
+	}
+	for _, reviewer := range cfg.Review.Reviewers {
+		if reviewer.Name == actor {
+			email = reviewer.Email
+		}
+	}
+	return actor, email
+}
+
+// commitAuthor returns the author of the commit of a write made as actor,
+// see commitIdentity, as "Name <email>".
+func commitAuthor(cfg Config, actor string) string {
+	name, email := commitIdentity(cfg, actor)
+	// git rejects names with angle brackets, which would end them early.
+	name = strings.NewReplacer("<", "", ">", "").Replace(name)
+	return fmt.Sprintf("%s <%s>", name, email)
+}
+
+// commitMessage returns the message of the commit of a write recorded as
+// event, such as "Delete item go", with its justification, if any, and who
+// made it from where.
+func commitMessage(event AuditEvent) string {
+	r, size := utf8.DecodeRuneInString(event.Action)
+	subject := strings.TrimSpace(string(unicode.ToUpper(r)) + event.Action[size:] + " " + event.Detail)
+	body := "Made by " + event.Actor + " through the radar API"
+	if event.SourceIP != "" {
+		body += " from " + event.SourceIP
+	}
+	if event.Justification != "" {
+		body = event.Justification + "\n\n" + body
+	}
+	return subject + "\n\n" + body + "."
+}
+
+// checkGitWorkTree returns an error unless the data file at path is in a
+// Git working tree.
+func checkGitWorkTree(ctx context.Context, path string) error {
+	if _, err := runGit(ctx, filepath.Dir(path), "rev-parse", "--is-inside-work-tree"); err != nil {
+		return fmt.Errorf("data.commit requires %s to be in a Git working tree: %w", path, err)
+	}
+	return nil
+}
+
+// commitDataFile commits the data file at path, written as event, to the
+// repository it is in, authored by commitAuthor. Nothing is committed if
+// the file is unchanged.
+func commitDataFile(ctx context.Context, path string, event AuditEvent) error {
+	// The file is written, so the commit is completed whether or not the
+	// client is still waiting.
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), gitTimeout)
+	defer cancel()
+	dir, file := filepath.Dir(path), filepath.Base(path)
+	gitMu.Lock()
+	defer gitMu.Unlock()
+	if _, err := runGit(ctx, dir, "add", "--", file); err != nil {
+		return err
+	}
+	if status, err := runGit(ctx, dir, "status", "--porcelain", "--", file); err != nil || status == "" {
+		return err
+	}
+	var args []string
+	for key, fallback := range map[string]string{"user.name": defaultCommitterName, "user.email": defaultCommitterEmail} {
+		if _, err := runGit(ctx, dir, "config", key); err != nil {
+			args = append(args, "-c", key+"="+fallback)
+		}
+	}
+	args = append(args, "commit", "--quiet", "--author", commitAuthor(currentConfig(), event.Actor), "-m", commitMessage(event), "--", file)
+	_, err := runGit(ctx, dir, args...)
+	return err
+}
@@ -0,0 +1,88 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"path/filepath"
+	"testing"
+)
+
+func TestCommitAuthor(t *testing.T) {
+	cfg := defaultConfig()
+	cfg.Data.Commit.AdminAuthor = "Radar Admin <radar@example.com>"
+	cfg.Review.Reviewers = []ReviewerConfig{{Name: "Jane Doe", Email: "jane@example.com"}, {Name: "Joe"}}
+	for actor, want := range map[string]string{
+		"admin":    "Radar Admin <radar@example.com>",
+		"Jane Doe": "Jane Doe <jane@example.com>",
+		"Joe":      "Joe <>",
+		"<system>": "system <>",
+	} {
+		if got := commitAuthor(cfg, actor); got != want {
+			t.Errorf("commitAuthor(%q) = %q, want %q", actor, got, want)
+		}
+	}
+}
+
+func TestCommitMessage(t *testing.T) {
+	got := commitMessage(AuditEvent{Actor: "admin", Action: "delete item", Detail: "go", SourceIP: "192.0.2.1"})
+	if want := "Delete item go\n\nMade by admin through the radar API from 192.0.2.1."; got != want {
+		t.Errorf("commitMessage = %q, want %q", got, want)
+	}
+}
+
+func TestItemWritesCommitted(t *testing.T) {
+	repo, commit := gitRadarRepo(t)
+	commit(radarWith("Go", "Adopted"))
+	cfg := defaultConfig()
+	cfg.Admin.Token = "s3cret"
+	cfg.Data.Path = filepath.Join(repo, "data", "radar.yaml")
+	cfg.Data.Commit = CommitConfig{Enabled: true, AdminAuthor: "Radar Admin <radar@example.com>"}
+	if err := cfg.validate(); err != nil {
+		t.Fatal(err)
+	}
+	useConfig(t, cfg)
+	useStore(t, newFileStore(cfg.Data.Path))
+	handler, err := setupRoutes(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lastCommit := func() string {
+		t.Helper()
+		out, err := runGit(context.Background(), repo, "log", "-1", "--format=%an <%ae>|%s|%b")
+		if err != nil {
+			t.Fatal(err)
+		}
+		return out
+	}
+
+	if rec := adminRequest(t, handler, http.MethodPost, "/api/v1/items", nil, `{"label": "Rust", "quadrant": "Tools", "ring": "In Discovery"}`); rec.Code != http.StatusCreated {
+		t.Fatalf("POST /api/v1/items = %d: %s", rec.Code, rec.Body)
+	}
+	if got, want := lastCommit(), "Radar Admin <radar@example.com>|Create item Rust|Made by admin through the radar API from 192.0.2.1."; got != want {
+		t.Errorf("last commit = %q, want %q", got, want)
+	}
+	if status, err := runGit(context.Background(), repo, "status", "--porcelain"); err != nil || status != "" {
+		t.Errorf("uncommitted changes %q, %v", status, err)
+	}
+	if rec := adminRequest(t, handler, http.MethodDelete, "/api/v1/items/go", anyVersion, ""); rec.Code != http.StatusNoContent {
+		t.Fatalf("DELETE /api/v1/items/go = %d: %s", rec.Code, rec.Body)
+	}
+	if got, want := lastCommit(), "Radar Admin <radar@example.com>|Delete item go|Made by admin through the radar API from 192.0.2.1."; got != want {
+		t.Errorf("last commit = %q, want %q", got, want)
+	}
+}
+
+func TestCommitOutsideGit(t *testing.T) {
+	cfg := defaultConfig()
+	cfg.Data.Path = writeFile(t, "radar.yaml", radarWith("Go", "Adopted"))
+	cfg.Data.Commit.Enabled = true
+	useConfig(t, cfg)
+	store := newFileStore(cfg.Data.Path)
+	t.Cleanup(func() { store.Close() })
+	if err := store.Save(context.Background(), RadarData{Items: []RadarItem{{Label: "Rust", Quadrant: "Tools", Ring: "Adopted"}}}); err == nil {
+		t.Error("saving outside a Git working tree succeeded")
+	}
+	if data, err := readRadarData(cfg.Data.Path); err != nil || data.Items[0].Label != "Go" {
+		t.Errorf("data file after failed save = %+v, %v", data, err)
+	}
+}
@@ -0,0 +1,265 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"log/slog"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// gitTimeout bounds a single update of the Git checkout.
+const gitTimeout = 2 * time.Minute
+
+// gitMu serializes git commands on the checkout.
+var gitMu sync.Mutex
+
+// dataPath returns the local path radar data is read from: Path itself, or
+// Path inside the Git checkout.
+func (d DataConfig) dataPath() string {
+	if d.Git.enabled() {
+		return filepath.Join(d.Git.Dir, d.Path)
+	}
+	return d.Path
+}
+
+// runGit runs git in dir and returns its trimmed output.
+func runGit(ctx context.Context, dir string, args ...string) (string, error) {
+	out, err := gitOutput(ctx, dir, args...)
+	return strings.TrimSpace(string(out)), err
+}
+
+// gitOutput runs git in dir and returns its output as is. Errors include
+// what git printed to stderr.
+func gitOutput(ctx context.Context, dir string, args ...string) ([]byte, error) {
+	cmd := exec.CommandContext(ctx, "git", args...)
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(), "GIT_TERMINAL_PROMPT=0")
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("git %s: %w: %s", args[0], err, strings.TrimSpace(stderr.String()))
+	}
+	return out, nil
+}
+
+// gitCheckoutExists reports whether the checkout directory holds a clone.
+func gitCheckoutExists(g GitConfig) bool {
+	_, err := os.Stat(filepath.Join(g.Dir, ".git"))
+	return err == nil
+}
+
+// syncGitRepo clones the branch into the checkout directory, or fetches it
+// and checks out its latest commit. It returns the commit checked out before
+// the update, empty after a fresh clone, and after it.
+func syncGitRepo(ctx context.Context, g GitConfig) (before, after string, err error) {
+	if !gitCheckoutExists(g) {
+		if _, err := runGit(ctx, "", "clone", "--quiet", "--single-branch", "--branch", g.Branch, g.URL, g.Dir); err != nil {
+			return "", "", err
+		}
+	} else {
+		if before, err = runGit(ctx, g.Dir, "rev-parse", "HEAD"); err != nil {
+			return "", "", err
+		}
+		// Fetching from the URL rather than a remote follows changes to the
+		// configured URL without reconfiguring the clone. The full history
+		// is kept for the radar history.
+		fetch := []string{"fetch", "--quiet", g.URL, g.Branch}
+		if shallow, _ := runGit(ctx, g.Dir, "rev-parse", "--is-shallow-repository"); shallow == "true" {
+			fetch = append(fetch, "--unshallow")
+		}
+		if _, err := runGit(ctx, g.Dir, fetch...); err != nil {
+			return "", "", err
+		}
+		if _, err := runGit(ctx, g.Dir, "reset", "--quiet", "--hard", "FETCH_HEAD"); err != nil {
+			return "", "", err
+		}
+	}
+	after, err = runGit(ctx, g.Dir, "rev-parse", "HEAD")
+	return before, after, err
+}
+
+// updateGitData brings the checkout up to date with the branch and reports
+// whether it moved to a new commit. If the new commit has invalid radar
+// data, the checkout returns to the previous commit so the last reviewed
+// data keeps being served.
+func updateGitData(ctx context.Context, d DataConfig) (bool, error) {
+	gitMu.Lock()
+	defer gitMu.Unlock()
+
+	before, after, err := syncGitRepo(ctx, d.Git)
+	if err != nil || before == after {
+		return false, err
+	}
+	if err := validateRadarData(d.dataPath()); err != nil {
+		if before == "" {
+			return false, err
+		}
+		if _, resetErr := runGit(ctx, d.Git.Dir, "reset", "--quiet", "--hard", before); resetErr != nil {
+			return false, errors.Join(err, resetErr)
+		}
+		return false, fmt.Errorf("commit %s has invalid radar data, staying on %s:\n%w", shortCommit(after), shortCommit(before), err)
+	}
+	log.Printf("Radar data updated to commit %s of %s", shortCommit(after), d.Git.Branch)
+	return true, nil
+}
+
+// prepareGitData updates the Git checkout before the data is validated. A
+// failed update is only logged when an earlier checkout can still be served.
+func prepareGitData(d DataConfig) error {
+	if !d.Git.enabled() {
+		return nil
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), gitTimeout)
+	defer cancel()
+	_, err := updateGitData(ctx, d)
+	if err != nil && gitCheckoutExists(d.Git) {
+		slog.Warn("Failed to update radar data repository, serving the current checkout", "err", err)
+		return nil
+	}
+	return err
+}
+
+// shortCommit abbreviates a commit hash for logging.
+func shortCommit(commit string) string {
+	return BuildInfo{Commit: commit}.ShortCommit()
+}
+
+// gitStore is the read-only Store of the data files in a Git checkout. The
+// checkout is updated every poll interval and whenever a webhook asks for
+// it; saving would be undone by the next update.
+type gitStore struct {
+	*fileStore
+	cfg     DataConfig
+	cancel  context.CancelFunc
+	trigger chan struct{}
+}
+
+// newGitStore starts updating the Git checkout of cfg, which prepareGitData
+// already brought up to date.
+func newGitStore(cfg DataConfig) *gitStore {
+	ctx, cancel := context.WithCancel(context.Background())
+	s := &gitStore{fileStore: newFileStore(cfg.dataPath()), cfg: cfg, cancel: cancel, trigger: make(chan struct{}, 1)}
+	go s.poll(ctx)
+	return s
+}
+
+// refreshSoon schedules an update without waiting for it.
+func (s *gitStore) refreshSoon() {
+	select {
+	case s.trigger <- struct{}{}:
+	default:
+	}
+}
+
+// poll updates the checkout until ctx is cancelled. Failures are logged and
+// the current checkout keeps being served.
+func (s *gitStore) poll(ctx context.Context) {
+	ticker := time.NewTicker(s.cfg.PollInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		case <-s.trigger:
+		}
+		updateCtx, cancel := context.WithTimeout(ctx, gitTimeout)
+		changed, err := updateGitData(updateCtx, s.cfg)
+		if err != nil && ctx.Err() == nil {
+			slog.Warn("Failed to update radar data repository, serving the current checkout", "err", err)
+		}
+		if changed {
+			s.invalidate()
+		}
+		cancel()
+	}
+}
+
+// Save opens a pull request changing the data file as a write to a
+// fileStore would, and returns it as a *PullRequest, with
+// data.git.pullRequests. The checkout itself is read-only.
+func (s *gitStore) Save(ctx context.Context, data RadarData) error {
+	if !s.cfg.Git.PullRequests.enabled() || !isSingleDataFile(s.path) {
+		return errReadOnly
+	}
+	current, _ := s.Load(ctx)
+	data, err := prepareSave(ctx, data, current)
+	if err != nil {
+		return err
+	}
+	content, err := radarFileContent(s.path, data)
+	if err != nil {
+		return err
+	}
+	event := auditEventOf(ctx)
+	pr, err := openPullRequest(ctx, s.cfg.Git, s.cfg.Path, content, event)
+	if err != nil {
+		return err
+	}
+	log.Printf("Opened pull request %s to %s %s", pr.URL, event.Action, event.Detail)
+	return pr
+}
+
+func (s *gitStore) Close() error {
+	s.cancel()
+	return s.fileStore.Close()
+}
+
+// gitWebhookHandler schedules an update of the Git checkout when the
+// repository host reports a push. Requests must carry a GitHub
+// X-Hub-Signature-256 signature made with secret, or secret as a GitLab
+// X-Gitlab-Token.
+func gitWebhookHandler(secret string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, 1<<20))
+		if err != nil {
+			handleError(w, &AppError{Code: http.StatusBadRequest, Message: "Failed to read webhook", Err: err})
+			return
+		}
+		if !validWebhook(r.Header, body, secret) {
+			http.Error(w, "Unauthorized", http.StatusUnauthorized)
+			return
+		}
+
+		src, ok := currentStore().(*gitStore)
+		if !ok {
+			handleError(w, &AppError{Code: http.StatusServiceUnavailable, Message: "Git data source not running"})
+			return
+		}
+		src.refreshSoon()
+		w.WriteHeader(http.StatusAccepted)
+		w.Write([]byte("Update scheduled"))
+	})
+}
+
+// validWebhook checks the GitHub signature or GitLab token of a webhook.
+func validWebhook(header http.Header, body []byte, secret string) bool {
+	if sig, ok := strings.CutPrefix(header.Get("X-Hub-Signature-256"), "sha256="); ok {
+		got, err := hex.DecodeString(sig)
+		if err != nil {
+			return false
+		}
+		mac := hmac.New(sha256.New, []byte(secret))
+		mac.Write(body)
+		return hmac.Equal(got, mac.Sum(nil))
+	}
+	if token := header.Get("X-Gitlab-Token"); token != "" {
+		return subtle.ConstantTimeCompare([]byte(token), []byte(secret)) == 1
+	}
+	return false
+}
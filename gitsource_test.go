@@ -0,0 +1,133 @@
+package main
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// gitRadarRepo creates a Git repository on branch main to clone radar data
+// from, and returns a function committing a new data/radar.yaml to it.
+func gitRadarRepo(t *testing.T) (string, func(content string)) {
+	t.Helper()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+	dir := t.TempDir()
+	git := func(args ...string) {
+		t.Helper()
+		args = append([]string{"-c", "user.name=Radar", "-c", "user.email=radar@example.com", "-c", "init.defaultBranch=main"}, args...)
+		if _, err := runGit(context.Background(), dir, args...); err != nil {
+			t.Fatal(err)
+		}
+	}
+	git("init", "--quiet")
+	commit := func(content string) {
+		t.Helper()
+		if err := os.MkdirAll(filepath.Join(dir, "data"), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, "data", "radar.yaml"), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		git("add", ".")
+		git("commit", "--quiet", "-m", "Update radar")
+	}
+	return dir, commit
+}
+
+// radarWith returns radar data holding a single item.
+func radarWith(label, ring string) string {
+	return "Items:\n- Label: " + label + "\n  Quadrant: Tools\n  Ring: " + ring + "\n"
+}
+
+func TestUpdateGitData(t *testing.T) {
+	repo, commit := gitRadarRepo(t)
+	commit(radarWith("Go", "Adopted"))
+
+	cfg := defaultConfig()
+	cfg.Data.Git.URL = repo
+	cfg.Data.Git.Dir = filepath.Join(t.TempDir(), "checkout")
+	if err := cfg.validate(); err != nil {
+		t.Fatal(err)
+	}
+	label := func() string {
+		t.Helper()
+		data, err := readRadarData(cfg.Data.dataPath())
+		if err != nil {
+			t.Fatal(err)
+		}
+		return data.Items[0].Label
+	}
+
+	ctx := context.Background()
+	if _, err := updateGitData(ctx, cfg.Data); err != nil {
+		t.Fatal(err)
+	}
+	if got := label(); got != "Go" {
+		t.Fatalf("after clone label = %q, want Go", got)
+	}
+
+	commit(radarWith("Rust", "Adopted"))
+	if changed, err := updateGitData(ctx, cfg.Data); err != nil || !changed || label() != "Rust" {
+		t.Fatalf("after update err = %v, label = %q, want Rust", err, label())
+	}
+
+	commit(radarWith("Zig", "Someday"))
+	if _, err := updateGitData(ctx, cfg.Data); err == nil || !strings.Contains(err.Error(), "invalid radar data") {
+		t.Errorf("update to invalid commit error = %v, want invalid radar data", err)
+	}
+	if got := label(); got != "Rust" {
+		t.Errorf("after invalid commit label = %q, want the previous commit's Rust", got)
+	}
+}
+
+func TestValidWebhook(t *testing.T) {
+	body := []byte(`{"ref":"refs/heads/main"}`)
+	mac := hmac.New(sha256.New, []byte("s3cret"))
+	mac.Write(body)
+	signature := "sha256=" + hex.EncodeToString(mac.Sum(nil))
+
+	tests := []struct {
+		name   string
+		header http.Header
+		want   bool
+	}{
+		{name: "github signature", header: http.Header{"X-Hub-Signature-256": {signature}}, want: true},
+		{name: "bad github signature", header: http.Header{"X-Hub-Signature-256": {"sha256=00"}}},
+		{name: "gitlab token", header: http.Header{"X-Gitlab-Token": {"s3cret"}}, want: true},
+		{name: "bad gitlab token", header: http.Header{"X-Gitlab-Token": {"wrong"}}},
+		{name: "unsigned", header: http.Header{}},
+	}
+	for _, tt := range tests {
+		if got := validWebhook(tt.header, body, "s3cret"); got != tt.want {
+			t.Errorf("%s: validWebhook() = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestGitWebhookTriggersUpdate(t *testing.T) {
+	src := &gitStore{trigger: make(chan struct{}, 1)}
+	useStore(t, src)
+
+	req := httptest.NewRequest(http.MethodPost, "/api/git/webhook", strings.NewReader("{}"))
+	req.Header.Set("X-Gitlab-Token", "s3cret")
+	rec := httptest.NewRecorder()
+	gitWebhookHandler("s3cret").ServeHTTP(rec, req)
+	if rec.Code != http.StatusAccepted {
+		t.Fatalf("status = %d, want 202", rec.Code)
+	}
+	select {
+	case <-src.trigger:
+	default:
+		t.Error("webhook did not schedule an update")
+	}
+}
@@ -0,0 +1,880 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"reflect"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// maxGraphQLDepth is how deep selection sets may be nested, which bounds
+// the work of a query and fragments spreading themselves.
+const maxGraphQLDepth = 20
+
+// maxGraphQLRequestSize is the largest GraphQL request body accepted.
+const maxGraphQLRequestSize = 1 << 20
+
+// graphQLArg is an argument of a field of the Query type.
+type graphQLArg struct {
+	name, description string
+	typ               graphQLTypeRef
+}
+
+// graphQLField is a field of the Query type. Its arguments are passed to
+// resolve as the query parameters of the request, so resolvers parse them
+// like the REST endpoints do and report the same errors.
+type graphQLField struct {
+	name, description string
+	args              []graphQLArg
+	// typ is the Go type resolve returns, whose JSON fields are those of
+	// the GraphQL type.
+	typ      reflect.Type
+	nullable bool
+	resolve  func(r *http.Request) (any, error)
+}
+
+// Types of the arguments of graphQLFields.
+var (
+	stringArg     = graphQLTypeRef{name: "String"}
+	intArg        = graphQLTypeRef{name: "Int"}
+	booleanArg    = graphQLTypeRef{name: "Boolean"}
+	stringListArg = graphQLTypeRef{elem: &graphQLTypeRef{name: "String", nonNull: true}}
+)
+
+// graphQLItemArgs are the arguments of the items field, those of the
+// GET /api/v1/radar/items parameters.
+var graphQLItemArgs = []graphQLArg{
+	{"quadrant", "Only items in one of these quadrants.", stringListArg},
+	{"ring", "Only items in one of these rings.", stringListArg},
+	{"owner", "Only items with one of these owners, by name, team or email address.", stringListArg},
+	{"tag", "Only items with all of these tags.", stringListArg},
+	{"moved", "Only items that have, or haven't, moved ring.", booleanArg},
+	{"includeArchived", "Include archived items.", booleanArg},
+	{"sort", "Sort items by " + strings.Join(itemOrderNames(), ", ") + ".", stringArg},
+	{"order", "Sort order, asc or desc.", stringArg},
+	{"limit", "Number of items, all if not given.", intArg},
+	{"offset", "Number of items to skip.", intArg},
+}
+
+// graphQLQueryFields are the fields of the Query type.
+var graphQLQueryFields = []graphQLField{
+	{
+		name:        "items",
+		description: "Items of the radar, filtered and sorted like GET /api/v1/radar/items.",
+		args:        graphQLItemArgs,
+		typ:         reflect.TypeFor[[]RadarItem](),
+		resolve: func(r *http.Request) (any, error) {
+			items, _, err := selectPage(r)
+			return items, err
+		},
+	},
+	{
+		name:        "item",
+		description: "The item with an ID, archived or not, with the details of GET /api/v1/radar/items/{id}.",
+		args:        []graphQLArg{{"id", "ID of the item.", graphQLTypeRef{name: "String", nonNull: true}}},
+		typ:         reflect.TypeFor[*ItemDetail](),
+		nullable:    true,
+		resolve: func(r *http.Request) (any, error) {
+			data, err := loadRadarData()
+			if err != nil {
+				return nil, err
+			}
+			item, ok := findItem(data.Items, r.URL.Query().Get("id"))
+			if !ok {
+				return (*ItemDetail)(nil), nil
+			}
+			detail := itemDetail(r, item, data.Items, nil)
+			return &detail, nil
+		},
+	},
+	{
+		name:        "quadrants",
+		description: "Quadrants of the radar, in order, with their number of items.",
+		typ:         reflect.TypeFor[[]SegmentInfo](),
+		resolve: func(r *http.Request) (any, error) {
+			data, err := loadRadarData()
+			if err != nil {
+				return nil, err
+			}
+			return segmentInfos(data.quadrants(), radarStats(data).Quadrants), nil
+		},
+	},
+	{
+		name:        "rings",
+		description: "Rings of the radar, from the innermost, with their number of items.",
+		typ:         reflect.TypeFor[[]SegmentInfo](),
+		resolve: func(r *http.Request) (any, error) {
+			data, err := loadRadarData()
+			if err != nil {
+				return nil, err
+			}
+			return segmentInfos(data.rings(), radarStats(data).Rings), nil
+		},
+	},
+	{
+		name:        "tags",
+		description: "Tags of the items that aren't archived, with their number of items.",
+		typ:         reflect.TypeFor[[]TagCount](),
+		resolve: func(r *http.Request) (any, error) {
+			data, err := loadRadarData()
+			if err != nil {
+				return nil, err
+			}
+			return countTags(visibleItems(data.Items)), nil
+		},
+	},
+	{
+		name:        "stats",
+		description: "Number of items by ring, quadrant and owner.",
+		typ:         reflect.TypeFor[RadarStats](),
+		resolve: func(r *http.Request) (any, error) {
+			data, err := loadRadarData()
+			if err != nil {
+				return nil, err
+			}
+			return storeStats(r.Context(), data)
+		},
+	},
+	{
+		name:        "history",
+		description: "Git history of every item, or of the item with an ID or label.",
+		args:        []graphQLArg{{"item", "ID or label of the item.", stringArg}},
+		typ:         reflect.TypeFor[[]ItemHistory](),
+		resolve: func(r *http.Request) (any, error) {
+			items, err := mergedHistory(r.Context())
+			if err != nil {
+				return nil, err
+			}
+			if key := r.URL.Query().Get("item"); key != "" {
+				history, ok := findHistory(items, key)
+				if !ok {
+					return []ItemHistory{}, nil
+				}
+				return []ItemHistory{history}, nil
+			}
+			return items, nil
+		},
+	},
+}
+
+// graphQLTypes names the Go types of the introspection types.
+var graphQLTypes = map[reflect.Type]string{
+	reflect.TypeFor[introSchema]():     "__Schema",
+	reflect.TypeFor[introType]():       "__Type",
+	reflect.TypeFor[introField]():      "__Field",
+	reflect.TypeFor[introInputValue](): "__InputValue",
+	reflect.TypeFor[introEnumValue]():  "__EnumValue",
+	reflect.TypeFor[introDirective]():  "__Directive",
+}
+
+// graphQLTypeName returns the name of the GraphQL type of a Go type: the
+// name of a struct, or of the scalar it encodes to in JSON. Maps, whose keys
+// need not be GraphQL names, are JSON objects of the JSON scalar.
+func graphQLTypeName(t reflect.Type) string {
+	if name, ok := graphQLTypes[t]; ok {
+		return name
+	}
+	switch t.Kind() {
+	case reflect.Bool:
+		return "Boolean"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64, reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return "Int"
+	case reflect.Float32, reflect.Float64:
+		return "Float"
+	case reflect.Map:
+		return "JSON"
+	case reflect.Struct:
+		if t != reflect.TypeFor[time.Time]() {
+			return strings.ToUpper(t.Name()[:1]) + t.Name()[1:]
+		}
+	}
+	return "String"
+}
+
+// isGraphQLObject reports whether values of t, a Go type without pointers
+// and slices, are GraphQL objects rather than scalars.
+func isGraphQLObject(t reflect.Type) bool {
+	return t.Kind() == reflect.Struct && t != reflect.TypeFor[time.Time]()
+}
+
+// graphQLObjectField is a field of a GraphQL object type, a field of a Go
+// struct that encoding/json encodes.
+type graphQLObjectField struct {
+	name     string
+	index    []int
+	typ      reflect.Type
+	nullable bool
+}
+
+// graphQLObjectFields returns the fields of the GraphQL object type of t, a
+// struct, with those of embedded structs. Fields left out of the JSON when
+// empty are nullable, null when empty.
+func graphQLObjectFields(t reflect.Type) []graphQLObjectField {
+	var fields []graphQLObjectField
+	for _, field := range reflect.VisibleFields(t) {
+		tag := field.Tag.Get("json")
+		name, options, _ := strings.Cut(tag, ",")
+		if !field.IsExported() || name == "-" || field.Anonymous && tag == "" {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		if slices.ContainsFunc(fields, func(f graphQLObjectField) bool { return f.name == name }) {
+			continue
+		}
+		nullable := field.Type.Kind() == reflect.Pointer || strings.Contains(options, "omitempty") || strings.Contains(options, "omitzero")
+		fields = append(fields, graphQLObjectField{name, field.Index, field.Type, nullable})
+	}
+	return fields
+}
+
+// graphQLError is an error of a GraphQL response, with the path of the
+// field it happened to if any.
+type graphQLError struct {
+	Message string `json:"message"`
+	Path    []any  `json:"path,omitempty"`
+}
+
+// graphQLResponseObject is an object of a GraphQL response, which keeps its
+// fields in the order they were selected.
+type graphQLResponseObject []graphQLResponseField
+
+type graphQLResponseField struct {
+	key   string
+	value any
+}
+
+func (o graphQLResponseObject) MarshalJSON() ([]byte, error) {
+	var b strings.Builder
+	b.WriteByte('{')
+	for i, field := range o {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		key, _ := json.Marshal(field.key)
+		value, err := json.Marshal(field.value)
+		if err != nil {
+			return nil, err
+		}
+		b.Write(key)
+		b.WriteByte(':')
+		b.Write(value)
+	}
+	b.WriteByte('}')
+	return []byte(b.String()), nil
+}
+
+// graphQLExecution is the execution of an operation of a document.
+type graphQLExecution struct {
+	r         *http.Request
+	doc       *graphQLDocument
+	variables map[string]any
+	errors    []graphQLError
+}
+
+// collectFields returns the fields of selections on an object of typeName,
+// with those of the fragments that apply to it, leaving out those skipped
+// by @skip and @include. Fields with the same key are merged.
+func (e *graphQLExecution) collectFields(selections []graphQLSelection, typeName string, depth int) ([]graphQLSelection, error) {
+	if depth > maxGraphQLDepth {
+		return nil, fmt.Errorf("The query is nested deeper than %d levels", maxGraphQLDepth)
+	}
+	var fields []graphQLSelection
+	for _, s := range selections {
+		include, err := e.included(s.directives)
+		if err != nil {
+			return nil, err
+		}
+		if !include {
+			continue
+		}
+
+		var spread []graphQLSelection
+		switch {
+		case s.fragment != "":
+			f, ok := e.doc.fragments[s.fragment]
+			if !ok {
+				return nil, fmt.Errorf("Unknown fragment %q", s.fragment)
+			}
+			if f.typeCondition != typeName {
+				continue
+			}
+			spread = f.selections
+		case s.inline:
+			if s.typeCondition != "" && s.typeCondition != typeName {
+				continue
+			}
+			spread = s.selections
+		default:
+			i := slices.IndexFunc(fields, func(f graphQLSelection) bool { return f.key() == s.key() })
+			if i < 0 {
+				fields = append(fields, s)
+			} else if fields[i].name != s.name {
+				return nil, fmt.Errorf("Fields %q conflict because %s and %s are different fields", s.key(), fields[i].name, s.name)
+			} else {
+				fields[i].selections = append(slices.Clip(fields[i].selections), s.selections...)
+			}
+			continue
+		}
+		more, err := e.collectFields(spread, typeName, depth+1)
+		if err != nil {
+			return nil, err
+		}
+		for _, f := range more {
+			if i := slices.IndexFunc(fields, func(g graphQLSelection) bool { return g.key() == f.key() }); i >= 0 {
+				fields[i].selections = append(slices.Clip(fields[i].selections), f.selections...)
+			} else {
+				fields = append(fields, f)
+			}
+		}
+	}
+	return fields, nil
+}
+
+// included applies the @skip and @include directives.
+func (e *graphQLExecution) included(directives []graphQLDirective) (bool, error) {
+	for _, d := range directives {
+		if d.name != "skip" && d.name != "include" {
+			return false, fmt.Errorf("Unknown directive @%s", d.name)
+		}
+		value, err := e.coerce(d.args["if"], graphQLTypeRef{name: "Boolean", nonNull: true}, "@"+d.name+"(if:)")
+		if err != nil {
+			return false, err
+		}
+		if value.(bool) == (d.name == "skip") {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// coerce returns value, a literal or variable of a document, as an
+// argument of type typ: a string, int, bool or list of them, or nil.
+func (e *graphQLExecution) coerce(value any, typ graphQLTypeRef, what string) (any, error) {
+	if v, ok := value.(graphQLVariable); ok {
+		value = e.variables[string(v)]
+	}
+	if value == nil {
+		if typ.nonNull {
+			return nil, fmt.Errorf("%s of required type %s was not provided", what, typ)
+		}
+		return nil, nil
+	}
+	if typ.elem != nil {
+		list, ok := value.([]any)
+		if !ok {
+			// A single value is a list of one.
+			list = []any{value}
+		}
+		var values []any
+		for _, v := range list {
+			v, err := e.coerce(v, *typ.elem, what)
+			if err != nil {
+				return nil, err
+			}
+			values = append(values, v)
+		}
+		return values, nil
+	}
+	invalid := fmt.Errorf("%s of type %s cannot be %v", what, typ, value)
+	switch typ.name {
+	case "String", "ID":
+		if s, ok := value.(string); ok {
+			return s, nil
+		}
+	case "Int":
+		switch n := value.(type) {
+		case int:
+			return n, nil
+		case float64:
+			// Variables are decoded from JSON as floats.
+			if n == float64(int(n)) {
+				return int(n), nil
+			}
+		}
+	case "Boolean":
+		if b, ok := value.(bool); ok {
+			return b, nil
+		}
+	}
+	return nil, invalid
+}
+
+// check validates the selections of a field of type t, checking that its
+// fields exist and that objects, and only objects, have selections.
+func (e *graphQLExecution) check(t reflect.Type, selections []graphQLSelection, parent string, depth int) error {
+	for t.Kind() == reflect.Pointer || t.Kind() == reflect.Slice {
+		t = t.Elem()
+	}
+	if !isGraphQLObject(t) {
+		if selections != nil {
+			return fmt.Errorf("Field %q of type %s must not have a selection", parent, graphQLTypeName(t))
+		}
+		return nil
+	}
+	if selections == nil {
+		return fmt.Errorf("Field %q of type %s must have a selection of subfields", parent, graphQLTypeName(t))
+	}
+	fields, err := e.collectFields(selections, graphQLTypeName(t), depth)
+	if err != nil {
+		return err
+	}
+	objectFields := graphQLObjectFields(t)
+	for _, s := range fields {
+		if s.name == "__typename" {
+			continue
+		}
+		// Introspection lists no deprecated fields, so includeDeprecated
+		// changes nothing.
+		for name := range s.args {
+			if name != "includeDeprecated" {
+				return fmt.Errorf("Unknown argument %q on field %q", name, graphQLTypeName(t)+"."+s.name)
+			}
+		}
+		i := slices.IndexFunc(objectFields, func(f graphQLObjectField) bool { return f.name == s.name })
+		if i < 0 {
+			return fmt.Errorf("Cannot query field %q on type %s", s.name, graphQLTypeName(t))
+		}
+		if err := e.check(objectFields[i].typ, s.selections, s.name, depth+1); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// value returns v projected on the selections, with the fields of objects
+// as selected.
+func (e *graphQLExecution) value(v reflect.Value, selections []graphQLSelection, depth int) any {
+	switch {
+	case v.Kind() == reflect.Pointer || v.Kind() == reflect.Interface:
+		if v.IsNil() {
+			return nil
+		}
+		return e.value(v.Elem(), selections, depth)
+	case v.Kind() == reflect.Slice:
+		list := make([]any, v.Len())
+		for i := range list {
+			list[i] = e.value(v.Index(i), selections, depth)
+		}
+		return list
+	case v.Type() == reflect.TypeFor[time.Time]():
+		return v.Interface().(time.Time).Format(time.RFC3339Nano)
+	case !isGraphQLObject(v.Type()):
+		return v.Interface()
+	}
+	typeName := graphQLTypeName(v.Type())
+	// The selections were collected, and failed if they could not be,
+	// when they were checked.
+	fields, _ := e.collectFields(selections, typeName, depth)
+	objectFields := graphQLObjectFields(v.Type())
+	object := make(graphQLResponseObject, 0, len(fields))
+	for _, s := range fields {
+		if s.name == "__typename" {
+			object = append(object, graphQLResponseField{s.key(), typeName})
+			continue
+		}
+		f := objectFields[slices.IndexFunc(objectFields, func(f graphQLObjectField) bool { return f.name == s.name })]
+		field := v.FieldByIndex(f.index)
+		var value any
+		if !f.nullable || !field.IsZero() {
+			value = e.value(field, s.selections, depth+1)
+		}
+		object = append(object, graphQLResponseField{s.key(), value})
+	}
+	return object
+}
+
+// execute runs the query op. Errors of the request, such as unknown fields,
+// are returned; errors resolving a field are added to e.errors and leave
+// the field null.
+func (e *graphQLExecution) execute(op *graphQLOperation) (graphQLResponseObject, error) {
+	if op.kind != "query" {
+		return nil, fmt.Errorf("Only queries are supported, not %ss", op.kind)
+	}
+	defined := make(map[string]bool)
+	for _, def := range op.variables {
+		defined[def.name] = true
+		if _, ok := e.variables[def.name]; !ok && def.hasDefault {
+			e.variables[def.name] = def.def
+		}
+	}
+	for name := range e.variables {
+		if !defined[name] {
+			delete(e.variables, name)
+		}
+	}
+
+	fields, err := e.collectFields(op.selections, "Query", 0)
+	if err != nil {
+		return nil, err
+	}
+	type resolved struct {
+		selection graphQLSelection
+		field     *graphQLField
+		query     url.Values
+	}
+	var plan []resolved
+	for _, s := range fields {
+		switch s.name {
+		case "__typename":
+			plan = append(plan, resolved{selection: s})
+			continue
+		case "__schema", "__type":
+			t := reflect.TypeFor[introSchema]()
+			if s.name == "__type" {
+				t = reflect.TypeFor[introType]()
+			}
+			if err := e.check(t, s.selections, s.name, 1); err != nil {
+				return nil, err
+			}
+			plan = append(plan, resolved{selection: s})
+			continue
+		}
+		i := slices.IndexFunc(graphQLQueryFields, func(f graphQLField) bool { return f.name == s.name })
+		if i < 0 {
+			return nil, fmt.Errorf("Cannot query field %q on type Query", s.name)
+		}
+		field := &graphQLQueryFields[i]
+		query, err := e.arguments(field, s.args)
+		if err != nil {
+			return nil, err
+		}
+		if err := e.check(field.typ, s.selections, s.name, 1); err != nil {
+			return nil, err
+		}
+		plan = append(plan, resolved{s, field, query})
+	}
+
+	data := make(graphQLResponseObject, 0, len(plan))
+	for _, p := range plan {
+		var value any
+		switch s := p.selection; {
+		case s.name == "__typename":
+			value = "Query"
+		case s.name == "__schema":
+			value = e.value(reflect.ValueOf(graphQLIntrospection()), s.selections, 1)
+		case s.name == "__type":
+			name, err := e.coerce(s.args["name"], graphQLTypeRef{name: "String", nonNull: true}, `Argument "name"`)
+			if err != nil {
+				return nil, err
+			}
+			schema := graphQLIntrospection()
+			if i := slices.IndexFunc(schema.Types, func(t introType) bool { return *t.Name == name }); i >= 0 {
+				value = e.value(reflect.ValueOf(schema.Types[i]), s.selections, 1)
+			}
+		default:
+			result, err := p.field.resolve(withQuery(e.r, p.query))
+			if err != nil {
+				var appErr *AppError
+				message := "Internal Server Error"
+				if errors.As(err, &appErr) {
+					message = appErr.Message
+				}
+				slog.Error("GraphQL field failed", "field", s.name, "err", err)
+				e.errors = append(e.errors, graphQLError{Message: message, Path: []any{s.key()}})
+				if !p.field.nullable {
+					// A required field that failed nulls the whole data.
+					return nil, nil
+				}
+			} else {
+				value = e.value(reflect.ValueOf(result), s.selections, 1)
+			}
+		}
+		data = append(data, graphQLResponseField{p.selection.key(), value})
+	}
+	return data, nil
+}
+
+// arguments returns the arguments of a field as query parameters, checking
+// them against its arguments.
+func (e *graphQLExecution) arguments(field *graphQLField, args map[string]any) (url.Values, error) {
+	for name, value := range args {
+		if !slices.ContainsFunc(field.args, func(a graphQLArg) bool { return a.name == name }) {
+			return nil, fmt.Errorf("Unknown argument %q on field %q", name, "Query."+field.name)
+		}
+		if v, ok := value.(graphQLVariable); ok {
+			if _, defined := e.variables[string(v)]; !defined {
+				return nil, fmt.Errorf("Variable $%s is not defined", v)
+			}
+		}
+	}
+	query := make(url.Values)
+	for _, arg := range field.args {
+		value, err := e.coerce(args[arg.name], arg.typ, fmt.Sprintf("Argument %q", arg.name))
+		if err != nil {
+			return nil, err
+		}
+		values, ok := value.([]any)
+		if !ok && value != nil {
+			values = []any{value}
+		}
+		for _, v := range values {
+			query.Add(arg.name, fmt.Sprint(v))
+		}
+	}
+	return query, nil
+}
+
+// graphQLRequest is a GraphQL request, posted as JSON or sent as the query
+// parameters of a GET request.
+type graphQLRequest struct {
+	Query         string         `json:"query"`
+	OperationName string         `json:"operationName"`
+	Variables     map[string]any `json:"variables"`
+}
+
+// graphQLResponse is the body of a GraphQL response.
+type graphQLResponse struct {
+	Errors []graphQLError `json:"errors,omitempty"`
+	Data   any            `json:"data,omitempty"`
+}
+
+// readGraphQLRequest reads the GraphQL request of r.
+func readGraphQLRequest(r *http.Request) (graphQLRequest, error) {
+	var req graphQLRequest
+	if r.Method == http.MethodGet {
+		query := r.URL.Query()
+		req.Query, req.OperationName = query.Get("query"), query.Get("operationName")
+		if variables := query.Get("variables"); variables != "" {
+			if err := json.Unmarshal([]byte(variables), &req.Variables); err != nil {
+				return req, fmt.Errorf("Invalid variables: %w", err)
+			}
+		}
+	} else {
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			return req, fmt.Errorf("Failed to read request: %w", err)
+		}
+		if mediaType, _, _ := strings.Cut(r.Header.Get("Content-Type"), ";"); strings.TrimSpace(mediaType) == "application/graphql" {
+			req.Query = string(body)
+		} else if err := json.Unmarshal(body, &req); err != nil {
+			return req, fmt.Errorf("Invalid request: %w", err)
+		}
+	}
+	if strings.TrimSpace(req.Query) == "" {
+		return req, errors.New("Missing query")
+	}
+	if req.Variables == nil {
+		req.Variables = make(map[string]any)
+	}
+	return req, nil
+}
+
+// graphQLHandler serves GraphQL queries of the radar data, posted as JSON
+// or sent with GET. Errors of the request are served with a 400 status,
+// errors resolving fields alongside the data of the others.
+func graphQLHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodPost {
+		w.Header().Set("Allow", "GET, POST")
+		handleError(w, &AppError{Code: http.StatusMethodNotAllowed, Message: "Method Not Allowed"})
+		return
+	}
+	r.Body = http.MaxBytesReader(w, r.Body, maxGraphQLRequestSize)
+	respond := func(status int, body graphQLResponse) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+		if err := json.NewEncoder(w).Encode(body); err != nil {
+			slog.Error("Failed to encode GraphQL response", "err", err)
+		}
+	}
+	fail := func(err error) {
+		respond(http.StatusBadRequest, graphQLResponse{Errors: []graphQLError{{Message: err.Error()}}})
+	}
+
+	req, err := readGraphQLRequest(r)
+	if err != nil {
+		fail(err)
+		return
+	}
+	doc, err := parseGraphQL(req.Query)
+	if err != nil {
+		fail(err)
+		return
+	}
+	var op *graphQLOperation
+	for _, o := range doc.operations {
+		if req.OperationName == "" && len(doc.operations) > 1 {
+			fail(errors.New("operationName is required for a document with several operations"))
+			return
+		}
+		if req.OperationName == "" || o.name == req.OperationName {
+			op = o
+		}
+	}
+	if op == nil {
+		fail(fmt.Errorf("Unknown operation %q", req.OperationName))
+		return
+	}
+
+	e := &graphQLExecution{r: r, doc: doc, variables: req.Variables}
+	data, err := e.execute(op)
+	if err != nil {
+		fail(err)
+		return
+	}
+	body := graphQLResponse{Errors: e.errors}
+	if data != nil {
+		body.Data = data
+	} else {
+		body.Data = json.RawMessage("null")
+	}
+	respond(http.StatusOK, body)
+}
+
+// Introspection types, see graphQLTypes. Their fields are those of the
+// GraphQL specification.
+type (
+	introSchema struct {
+		Description      *string          `json:"description"`
+		QueryType        introType        `json:"queryType"`
+		MutationType     *introType       `json:"mutationType"`
+		SubscriptionType *introType       `json:"subscriptionType"`
+		Types            []introType      `json:"types"`
+		Directives       []introDirective `json:"directives"`
+	}
+	introType struct {
+		Kind           string             `json:"kind"`
+		Name           *string            `json:"name"`
+		Description    *string            `json:"description"`
+		Fields         *[]introField      `json:"fields"`
+		Interfaces     *[]introType       `json:"interfaces"`
+		PossibleTypes  *[]introType       `json:"possibleTypes"`
+		EnumValues     *[]introEnumValue  `json:"enumValues"`
+		InputFields    *[]introInputValue `json:"inputFields"`
+		OfType         *introType         `json:"ofType"`
+		SpecifiedByURL *string            `json:"specifiedByURL"`
+	}
+	introField struct {
+		Name              string            `json:"name"`
+		Description       *string           `json:"description"`
+		Args              []introInputValue `json:"args"`
+		Type              introType         `json:"type"`
+		IsDeprecated      bool              `json:"isDeprecated"`
+		DeprecationReason *string           `json:"deprecationReason"`
+	}
+	introInputValue struct {
+		Name              string    `json:"name"`
+		Description       *string   `json:"description"`
+		Type              introType `json:"type"`
+		DefaultValue      *string   `json:"defaultValue"`
+		IsDeprecated      bool      `json:"isDeprecated"`
+		DeprecationReason *string   `json:"deprecationReason"`
+	}
+	introEnumValue struct {
+		Name              string  `json:"name"`
+		Description       *string `json:"description"`
+		IsDeprecated      bool    `json:"isDeprecated"`
+		DeprecationReason *string `json:"deprecationReason"`
+	}
+	introDirective struct {
+		Name         string            `json:"name"`
+		Description  *string           `json:"description"`
+		Locations    []string          `json:"locations"`
+		Args         []introInputValue `json:"args"`
+		IsRepeatable bool              `json:"isRepeatable"`
+	}
+)
+
+// optional returns s as an optional string of introspection, null if empty.
+func optional(s string) *string {
+	if s == "" {
+		return nil
+	}
+	return &s
+}
+
+// introTypeRef returns the introspection type of a reference to the
+// GraphQL type of t, a Go type, which is null if nullable.
+func introTypeRef(t reflect.Type, nullable bool) introType {
+	var ref introType
+	switch {
+	case t.Kind() == reflect.Pointer:
+		return introTypeRef(t.Elem(), true)
+	case t.Kind() == reflect.Slice:
+		elem := introTypeRef(t.Elem(), false)
+		ref = introType{Kind: "LIST", OfType: &elem}
+	case isGraphQLObject(t):
+		ref = introType{Kind: "OBJECT", Name: optional(graphQLTypeName(t))}
+	default:
+		ref = introType{Kind: "SCALAR", Name: optional(graphQLTypeName(t))}
+	}
+	if nullable {
+		return ref
+	}
+	return introType{Kind: "NON_NULL", OfType: &ref}
+}
+
+// introArgRef returns the introspection type of an argument type.
+func introArgRef(t graphQLTypeRef) introType {
+	ref := introType{Kind: "SCALAR", Name: optional(t.name)}
+	if t.elem != nil {
+		elem := introArgRef(*t.elem)
+		ref = introType{Kind: "LIST", OfType: &elem}
+	}
+	if !t.nonNull {
+		return ref
+	}
+	return introType{Kind: "NON_NULL", OfType: &ref}
+}
+
+// graphQLIntrospection returns the schema served to introspection queries.
+var graphQLIntrospection = sync.OnceValue(func() introSchema {
+	empty := func() *[]introType { return &[]introType{} }
+	var queryFields []introField
+	for _, f := range graphQLQueryFields {
+		field := introField{Name: f.name, Description: optional(f.description), Args: []introInputValue{}, Type: introTypeRef(f.typ, f.nullable)}
+		for _, arg := range f.args {
+			field.Args = append(field.Args, introInputValue{Name: arg.name, Description: optional(arg.description), Type: introArgRef(arg.typ)})
+		}
+		queryFields = append(queryFields, field)
+	}
+	schema := introSchema{
+		QueryType: introType{Kind: "OBJECT", Name: optional("Query")},
+		Types:     []introType{{Kind: "OBJECT", Name: optional("Query"), Fields: &queryFields, Interfaces: empty()}},
+	}
+	for _, name := range []string{"String", "Int", "Float", "Boolean"} {
+		schema.Types = append(schema.Types, introType{Kind: "SCALAR", Name: optional(name)})
+	}
+	schema.Types = append(schema.Types, introType{Kind: "SCALAR", Name: optional("JSON"), Description: optional("A JSON object, such as the counts of reactions by kind.")})
+
+	seen := make(map[reflect.Type]bool)
+	var add func(t reflect.Type)
+	add = func(t reflect.Type) {
+		for t.Kind() == reflect.Pointer || t.Kind() == reflect.Slice {
+			t = t.Elem()
+		}
+		if !isGraphQLObject(t) || seen[t] {
+			return
+		}
+		seen[t] = true
+		var fields []introField
+		for _, f := range graphQLObjectFields(t) {
+			fields = append(fields, introField{Name: f.name, Args: []introInputValue{}, Type: introTypeRef(f.typ, f.nullable)})
+		}
+		schema.Types = append(schema.Types, introType{Kind: "OBJECT", Name: optional(graphQLTypeName(t)), Fields: &fields, Interfaces: empty()})
+		for _, f := range graphQLObjectFields(t) {
+			add(f.typ)
+		}
+	}
+	for _, f := range graphQLQueryFields {
+		add(f.typ)
+	}
+	add(reflect.TypeFor[introSchema]())
+
+	condition := []introInputValue{{Name: "if", Type: introArgRef(graphQLTypeRef{name: "Boolean", nonNull: true})}}
+	locations := []string{"FIELD", "FRAGMENT_SPREAD", "INLINE_FRAGMENT"}
+	schema.Directives = []introDirective{
+		{Name: "include", Description: optional("Includes the field only when if is true."), Locations: locations, Args: condition},
+		{Name: "skip", Description: optional("Skips the field when if is true."), Locations: locations, Args: condition},
+	}
+	return schema
+})
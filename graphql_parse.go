@@ -0,0 +1,588 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Kinds of graphQLToken.
+const (
+	tokenEOF = iota
+	tokenPunct
+	tokenName
+	tokenInt
+	tokenFloat
+	tokenString
+)
+
+// graphQLToken is a lexical token of a GraphQL document.
+type graphQLToken struct {
+	kind  int
+	value string
+	// pos is the offset of the token in the document.
+	pos int
+}
+
+func (t graphQLToken) String() string {
+	switch t.kind {
+	case tokenEOF:
+		return "<EOF>"
+	case tokenString:
+		return strconv.Quote(t.value)
+	}
+	return t.value
+}
+
+// graphQLDocument is a parsed GraphQL document.
+type graphQLDocument struct {
+	operations []*graphQLOperation
+	fragments  map[string]*graphQLFragment
+}
+
+// graphQLOperation is a query, mutation or subscription of a document.
+type graphQLOperation struct {
+	kind, name string
+	variables  []graphQLVariableDef
+	selections []graphQLSelection
+}
+
+// graphQLVariableDef declares a variable of an operation, with its default
+// value if it has one.
+type graphQLVariableDef struct {
+	name       string
+	typ        graphQLTypeRef
+	def        any
+	hasDefault bool
+}
+
+// graphQLTypeRef is a type in a variable definition or an argument, such
+// as [String!]!. It is a list of elem if elem is set, else the named type.
+type graphQLTypeRef struct {
+	name    string
+	elem    *graphQLTypeRef
+	nonNull bool
+}
+
+func (t graphQLTypeRef) String() string {
+	s := t.name
+	if t.elem != nil {
+		s = "[" + t.elem.String() + "]"
+	}
+	if t.nonNull {
+		s += "!"
+	}
+	return s
+}
+
+// graphQLFragment is a named fragment of a document.
+type graphQLFragment struct {
+	typeCondition string
+	selections    []graphQLSelection
+}
+
+// graphQLSelection is a field of a selection set, or a spread of the named
+// fragment, or with inline set an inline fragment.
+type graphQLSelection struct {
+	alias, name   string
+	args          map[string]any
+	directives    []graphQLDirective
+	selections    []graphQLSelection
+	fragment      string
+	inline        bool
+	typeCondition string
+}
+
+// key returns the name of the field in the response.
+func (s graphQLSelection) key() string {
+	if s.alias != "" {
+		return s.alias
+	}
+	return s.name
+}
+
+// graphQLDirective is a directive such as @include(if: $shown).
+type graphQLDirective struct {
+	name string
+	args map[string]any
+}
+
+// Values of a document other than strings, numbers, booleans, null, lists
+// and objects.
+type (
+	graphQLVariable string
+	graphQLEnum     string
+)
+
+// lexGraphQL splits a GraphQL document into tokens, leaving out white space,
+// commas and comments.
+func lexGraphQL(src string) ([]graphQLToken, error) {
+	var tokens []graphQLToken
+	isName := func(c byte, first bool) bool {
+		return c == '_' || 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z' || !first && '0' <= c && c <= '9'
+	}
+	isDigit := func(c byte) bool { return '0' <= c && c <= '9' }
+	for i := 0; i < len(src); {
+		c := src[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r' || c == ',':
+			i++
+		case strings.HasPrefix(src[i:], "\uFEFF"):
+			i += len("\uFEFF")
+		case c == '#':
+			for i < len(src) && src[i] != '\n' && src[i] != '\r' {
+				i++
+			}
+		case strings.HasPrefix(src[i:], "..."):
+			tokens = append(tokens, graphQLToken{tokenPunct, "...", i})
+			i += 3
+		case strings.IndexByte("!$&()[]{}:=@|", c) >= 0:
+			tokens = append(tokens, graphQLToken{tokenPunct, string(c), i})
+			i++
+		case isName(c, true):
+			start := i
+			for i < len(src) && isName(src[i], false) {
+				i++
+			}
+			tokens = append(tokens, graphQLToken{tokenName, src[start:i], start})
+		case c == '-' || isDigit(c):
+			start, kind := i, tokenInt
+			if c == '-' {
+				i++
+			}
+			digits := func() int {
+				n := 0
+				for ; i < len(src) && isDigit(src[i]); i++ {
+					n++
+				}
+				return n
+			}
+			if digits() == 0 {
+				return nil, graphQLSyntaxError(src, start, "invalid number")
+			}
+			if i < len(src) && src[i] == '.' {
+				i++
+				kind = tokenFloat
+				if digits() == 0 {
+					return nil, graphQLSyntaxError(src, start, "invalid number")
+				}
+			}
+			if i < len(src) && (src[i] == 'e' || src[i] == 'E') {
+				i++
+				kind = tokenFloat
+				if i < len(src) && (src[i] == '+' || src[i] == '-') {
+					i++
+				}
+				if digits() == 0 {
+					return nil, graphQLSyntaxError(src, start, "invalid number")
+				}
+			}
+			tokens = append(tokens, graphQLToken{kind, src[start:i], start})
+		case strings.HasPrefix(src[i:], `"""`):
+			end := strings.Index(src[i+3:], `"""`)
+			if end < 0 {
+				return nil, graphQLSyntaxError(src, i, "unterminated string")
+			}
+			tokens = append(tokens, graphQLToken{tokenString, blockString(src[i+3 : i+3+end]), i})
+			i += end + 6
+		case c == '"':
+			end := i + 1
+			for end < len(src) && src[end] != '"' && src[end] != '\n' {
+				if src[end] == '\\' {
+					end++
+				}
+				end++
+			}
+			if end >= len(src) || src[end] != '"' {
+				return nil, graphQLSyntaxError(src, i, "unterminated string")
+			}
+			// The escapes of GraphQL strings are those of JSON.
+			var value string
+			if err := json.Unmarshal([]byte(src[i:end+1]), &value); err != nil {
+				return nil, graphQLSyntaxError(src, i, "invalid string")
+			}
+			tokens = append(tokens, graphQLToken{tokenString, value, i})
+			i = end + 1
+		default:
+			return nil, graphQLSyntaxError(src, i, fmt.Sprintf("unexpected character %q", c))
+		}
+	}
+	return append(tokens, graphQLToken{tokenEOF, "", len(src)}), nil
+}
+
+// blockString returns the value of a """block string""": its lines without
+// their common indentation and the blank lines around them.
+func blockString(raw string) string {
+	lines := strings.Split(strings.ReplaceAll(raw, "\r\n", "\n"), "\n")
+	indent := -1
+	for _, line := range lines[1:] {
+		if trimmed := strings.TrimLeft(line, " \t"); trimmed != "" && (indent < 0 || len(line)-len(trimmed) < indent) {
+			indent = len(line) - len(trimmed)
+		}
+	}
+	for i := 1; i < len(lines) && indent > 0; i++ {
+		lines[i] = lines[i][min(indent, len(lines[i])):]
+	}
+	for len(lines) > 0 && strings.TrimSpace(lines[0]) == "" {
+		lines = lines[1:]
+	}
+	for len(lines) > 0 && strings.TrimSpace(lines[len(lines)-1]) == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return strings.Join(lines, "\n")
+}
+
+// graphQLSyntaxError returns the error at offset pos of a document.
+func graphQLSyntaxError(src string, pos int, message string) error {
+	line := strings.Count(src[:pos], "\n") + 1
+	column := pos - strings.LastIndex(src[:pos], "\n")
+	return fmt.Errorf("Syntax error at %d:%d: %s", line, column, message)
+}
+
+// graphQLParser parses the tokens of a document.
+type graphQLParser struct {
+	src    string
+	tokens []graphQLToken
+	i      int
+}
+
+// parseGraphQL parses a document of operations and fragments.
+func parseGraphQL(src string) (*graphQLDocument, error) {
+	tokens, err := lexGraphQL(src)
+	if err != nil {
+		return nil, err
+	}
+	p := &graphQLParser{src: src, tokens: tokens}
+	doc := &graphQLDocument{fragments: make(map[string]*graphQLFragment)}
+	for p.peek().kind != tokenEOF {
+		switch t := p.peek(); {
+		case p.isPunct("{"):
+			selections, err := p.selectionSet()
+			if err != nil {
+				return nil, err
+			}
+			doc.operations = append(doc.operations, &graphQLOperation{kind: "query", selections: selections})
+		case t.kind == tokenName && (t.value == "query" || t.value == "mutation" || t.value == "subscription"):
+			op, err := p.operation()
+			if err != nil {
+				return nil, err
+			}
+			doc.operations = append(doc.operations, op)
+		case t.kind == tokenName && t.value == "fragment":
+			p.next()
+			name, err := p.name()
+			if err != nil {
+				return nil, err
+			}
+			if _, ok := doc.fragments[name]; ok {
+				return nil, fmt.Errorf("There can be only one fragment named %q", name)
+			}
+			f := &graphQLFragment{}
+			if err := p.expectKeyword("on"); err != nil {
+				return nil, err
+			}
+			if f.typeCondition, err = p.name(); err != nil {
+				return nil, err
+			}
+			if _, err := p.directives(); err != nil {
+				return nil, err
+			}
+			if f.selections, err = p.selectionSet(); err != nil {
+				return nil, err
+			}
+			doc.fragments[name] = f
+		default:
+			return nil, p.unexpected("a query or fragment")
+		}
+	}
+	if len(doc.operations) == 0 {
+		return nil, fmt.Errorf("The document has no operation")
+	}
+	return doc, nil
+}
+
+func (p *graphQLParser) peek() graphQLToken { return p.tokens[p.i] }
+
+func (p *graphQLParser) next() graphQLToken {
+	t := p.tokens[p.i]
+	if t.kind != tokenEOF {
+		p.i++
+	}
+	return t
+}
+
+// isPunct reports whether the next token is the punctuator s.
+func (p *graphQLParser) isPunct(s string) bool {
+	t := p.peek()
+	return t.kind == tokenPunct && t.value == s
+}
+
+// unexpected returns the error of finding the next token instead of what
+// was expected.
+func (p *graphQLParser) unexpected(expected string) error {
+	t := p.peek()
+	return graphQLSyntaxError(p.src, t.pos, fmt.Sprintf("expected %s, found %s", expected, t))
+}
+
+func (p *graphQLParser) expect(punct string) error {
+	if !p.isPunct(punct) {
+		return p.unexpected(strconv.Quote(punct))
+	}
+	p.next()
+	return nil
+}
+
+func (p *graphQLParser) expectKeyword(keyword string) error {
+	if t := p.peek(); t.kind != tokenName || t.value != keyword {
+		return p.unexpected(strconv.Quote(keyword))
+	}
+	p.next()
+	return nil
+}
+
+func (p *graphQLParser) name() (string, error) {
+	if p.peek().kind != tokenName {
+		return "", p.unexpected("a name")
+	}
+	return p.next().value, nil
+}
+
+func (p *graphQLParser) operation() (*graphQLOperation, error) {
+	op := &graphQLOperation{kind: p.next().value}
+	if p.peek().kind == tokenName {
+		op.name = p.next().value
+	}
+	if p.isPunct("(") {
+		p.next()
+		for !p.isPunct(")") {
+			var def graphQLVariableDef
+			if err := p.expect("$"); err != nil {
+				return nil, err
+			}
+			var err error
+			if def.name, err = p.name(); err != nil {
+				return nil, err
+			}
+			if err := p.expect(":"); err != nil {
+				return nil, err
+			}
+			if def.typ, err = p.typeRef(); err != nil {
+				return nil, err
+			}
+			if p.isPunct("=") {
+				p.next()
+				if def.def, err = p.value(true); err != nil {
+					return nil, err
+				}
+				def.hasDefault = true
+			}
+			if _, err := p.directives(); err != nil {
+				return nil, err
+			}
+			op.variables = append(op.variables, def)
+		}
+		p.next()
+	}
+	if _, err := p.directives(); err != nil {
+		return nil, err
+	}
+	var err error
+	op.selections, err = p.selectionSet()
+	return op, err
+}
+
+func (p *graphQLParser) typeRef() (graphQLTypeRef, error) {
+	var t graphQLTypeRef
+	if p.isPunct("[") {
+		p.next()
+		elem, err := p.typeRef()
+		if err != nil {
+			return t, err
+		}
+		if err := p.expect("]"); err != nil {
+			return t, err
+		}
+		t.elem = &elem
+	} else {
+		var err error
+		if t.name, err = p.name(); err != nil {
+			return t, err
+		}
+	}
+	if p.isPunct("!") {
+		p.next()
+		t.nonNull = true
+	}
+	return t, nil
+}
+
+func (p *graphQLParser) selectionSet() ([]graphQLSelection, error) {
+	if err := p.expect("{"); err != nil {
+		return nil, err
+	}
+	var selections []graphQLSelection
+	for !p.isPunct("}") {
+		s, err := p.selection()
+		if err != nil {
+			return nil, err
+		}
+		selections = append(selections, s)
+	}
+	if len(selections) == 0 {
+		return nil, p.unexpected("a field")
+	}
+	p.next()
+	return selections, nil
+}
+
+func (p *graphQLParser) selection() (graphQLSelection, error) {
+	var s graphQLSelection
+	var err error
+	if p.isPunct("...") {
+		p.next()
+		if t := p.peek(); t.kind == tokenName && t.value != "on" {
+			s.fragment = p.next().value
+			s.directives, err = p.directives()
+			return s, err
+		}
+		s.inline = true
+		if p.peek().kind == tokenName {
+			p.next()
+			if s.typeCondition, err = p.name(); err != nil {
+				return s, err
+			}
+		}
+		if s.directives, err = p.directives(); err != nil {
+			return s, err
+		}
+		s.selections, err = p.selectionSet()
+		return s, err
+	}
+
+	if s.name, err = p.name(); err != nil {
+		return s, err
+	}
+	if p.isPunct(":") {
+		p.next()
+		s.alias = s.name
+		if s.name, err = p.name(); err != nil {
+			return s, err
+		}
+	}
+	if s.args, err = p.arguments(false); err != nil {
+		return s, err
+	}
+	if s.directives, err = p.directives(); err != nil {
+		return s, err
+	}
+	if p.isPunct("{") {
+		s.selections, err = p.selectionSet()
+	}
+	return s, err
+}
+
+func (p *graphQLParser) arguments(constant bool) (map[string]any, error) {
+	if !p.isPunct("(") {
+		return nil, nil
+	}
+	p.next()
+	args := make(map[string]any)
+	for !p.isPunct(")") {
+		name, err := p.name()
+		if err != nil {
+			return nil, err
+		}
+		if _, ok := args[name]; ok {
+			return nil, fmt.Errorf("There can be only one argument named %q", name)
+		}
+		if err := p.expect(":"); err != nil {
+			return nil, err
+		}
+		if args[name], err = p.value(constant); err != nil {
+			return nil, err
+		}
+	}
+	p.next()
+	return args, nil
+}
+
+func (p *graphQLParser) directives() ([]graphQLDirective, error) {
+	var directives []graphQLDirective
+	for p.isPunct("@") {
+		p.next()
+		name, err := p.name()
+		if err != nil {
+			return nil, err
+		}
+		args, err := p.arguments(false)
+		if err != nil {
+			return nil, err
+		}
+		directives = append(directives, graphQLDirective{name, args})
+	}
+	return directives, nil
+}
+
+// value parses a value, which may not hold variables if constant.
+func (p *graphQLParser) value(constant bool) (any, error) {
+	t := p.peek()
+	switch {
+	case t.kind == tokenPunct && t.value == "$" && !constant:
+		p.next()
+		name, err := p.name()
+		return graphQLVariable(name), err
+	case t.kind == tokenInt:
+		p.next()
+		n, err := strconv.Atoi(t.value)
+		if err != nil {
+			return nil, graphQLSyntaxError(p.src, t.pos, "integer out of range")
+		}
+		return n, nil
+	case t.kind == tokenFloat:
+		p.next()
+		return strconv.ParseFloat(t.value, 64)
+	case t.kind == tokenString:
+		p.next()
+		return t.value, nil
+	case t.kind == tokenName:
+		p.next()
+		switch t.value {
+		case "true", "false":
+			return t.value == "true", nil
+		case "null":
+			return nil, nil
+		}
+		return graphQLEnum(t.value), nil
+	case t.kind == tokenPunct && t.value == "[":
+		p.next()
+		list := []any{}
+		for !p.isPunct("]") {
+			v, err := p.value(constant)
+			if err != nil {
+				return nil, err
+			}
+			list = append(list, v)
+		}
+		p.next()
+		return list, nil
+	case t.kind == tokenPunct && t.value == "{":
+		p.next()
+		object := make(map[string]any)
+		for !p.isPunct("}") {
+			name, err := p.name()
+			if err != nil {
+				return nil, err
+			}
+			if err := p.expect(":"); err != nil {
+				return nil, err
+			}
+			if object[name], err = p.value(constant); err != nil {
+				return nil, err
+			}
+		}
+		p.next()
+		return object, nil
+	}
+	return nil, p.unexpected("a value")
+}
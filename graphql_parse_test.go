@@ -0,0 +1,79 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseGraphQL(t *testing.T) {
+	doc, err := parseGraphQL(`
+# Items of a ring.
+query Ring($ring: [String!]! = ["Adopted"], $n: Int) {
+  first: items(ring: $ring, limit: 1.5e1, sort: "label!") @skip(if: false) {
+    label
+    ... on RadarItem { id }
+    ... @include(if: true) { ring }
+    ...more
+  }
+}
+fragment more on RadarItem { description(format: """
+    Two
+      lines
+""") }
+`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(doc.operations) != 1 || len(doc.fragments) != 1 {
+		t.Fatalf("parsed %d operations and %d fragments", len(doc.operations), len(doc.fragments))
+	}
+	op := doc.operations[0]
+	if op.kind != "query" || op.name != "Ring" || len(op.variables) != 2 {
+		t.Fatalf("operation = %+v", op)
+	}
+	if v := op.variables[0]; v.typ.String() != "[String!]!" || !reflect.DeepEqual(v.def, []any{"Adopted"}) {
+		t.Errorf("$ring = %s = %v", v.typ, v.def)
+	}
+	if v := op.variables[1]; v.typ.String() != "Int" || v.hasDefault {
+		t.Errorf("$n = %s with default %t", v.typ, v.hasDefault)
+	}
+
+	items := op.selections[0]
+	if items.key() != "first" || items.name != "items" || len(items.directives) != 1 || items.directives[0].name != "skip" {
+		t.Errorf("field = %+v", items)
+	}
+	if want := map[string]any{"ring": graphQLVariable("ring"), "limit": 15.0, "sort": "label!"}; !reflect.DeepEqual(items.args, want) {
+		t.Errorf("args = %#v, want %#v", items.args, want)
+	}
+	if s := items.selections; len(s) != 4 || s[1].typeCondition != "RadarItem" || !s[1].inline || !s[2].inline || s[2].typeCondition != "" || s[3].fragment != "more" {
+		t.Errorf("selections = %+v", s)
+	}
+	if got := doc.fragments["more"].selections[0].args["format"]; got != "Two\n  lines" {
+		t.Errorf("block string = %q", got)
+	}
+
+	// A document that is only a selection set is a query.
+	if doc, err := parseGraphQL(`{ stats { total } }`); err != nil || doc.operations[0].kind != "query" {
+		t.Errorf("shorthand query = %+v, %v", doc, err)
+	}
+}
+
+func TestParseGraphQLErrors(t *testing.T) {
+	tests := map[string]string{
+		"{ items ":                      "Syntax error at 1:9: expected a name, found <EOF>",
+		"{ items(ring: ) { label } }":   "Syntax error at 1:15: expected a value, found )",
+		"{\n  items { label } ?":        "Syntax error at 2:19: unexpected character '?'",
+		`{ items(sort: "label) }`:       "Syntax error at 1:15: unterminated string",
+		"{ items(limit: 1.) { id } }":   "Syntax error at 1:16: invalid number",
+		"{}":                            "Syntax error at 1:2: expected a field, found }",
+		"subscribe { items { id } }":    "Syntax error at 1:1: expected a query or fragment, found subscribe",
+		"fragment f on Q { id } ":       "The document has no operation",
+		"{ items(a: 1, a: 2) { id } }":  `There can be only one argument named "a"`,
+		"query ($x: ) { items { id } }": "Syntax error at 1:12: expected a name, found )",
+	}
+	for src, want := range tests {
+		if _, err := parseGraphQL(src); err == nil || err.Error() != want {
+			t.Errorf("parseGraphQL(%q) = %v, want %q", src, err, want)
+		}
+	}
+}
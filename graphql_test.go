@@ -0,0 +1,268 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// postGraphQL posts query with variables to handler and returns the status
+// and body of the response.
+func postGraphQL(t *testing.T, handler http.Handler, query string, variables map[string]any) (int, string) {
+	t.Helper()
+	body, err := json.Marshal(graphQLRequest{Query: query, Variables: variables})
+	if err != nil {
+		t.Fatal(err)
+	}
+	req := httptest.NewRequest(http.MethodPost, "/graphql", strings.NewReader(string(body)))
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	return rec.Code, strings.TrimSpace(rec.Body.String())
+}
+
+func TestGraphQLQueries(t *testing.T) {
+	cfg := defaultConfig()
+	cfg.Data.Path = writeFile(t, "radar.yaml", `Items:
+- Label: Go
+  Quadrant: Tools
+  Ring: Adopted
+  Owners: [{Name: Ana}]
+  Tags: [backend]
+- Label: Rust
+  Quadrant: Tools
+  Ring: In Discovery
+- Label: Perl
+  Quadrant: Tools
+  Ring: Not Recommended
+  Archived: true
+`)
+	useConfig(t, cfg)
+	handler, err := setupRoutes(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name, query string
+		variables   map[string]any
+		status      int
+		want        string
+	}{
+		{
+			name:   "fields in the order selected",
+			query:  `{ items(sort: "label") { label id ring owners { name } } }`,
+			status: http.StatusOK,
+			want:   `{"data":{"items":[{"label":"Go","id":"go","ring":"Adopted","owners":[{"name":"Ana"}]},{"label":"Rust","id":"rust","ring":"In Discovery","owners":[]}]}}`,
+		},
+		{
+			name:   "filters, aliases and several roots",
+			query:  `{ adopted: items(ring: "adopted", includeArchived: true) { label } archived: items(includeArchived: true, limit: 1, offset: 2) { label archived } stats { total archived } }`,
+			status: http.StatusOK,
+			want:   `{"data":{"adopted":[{"label":"Go"}],"archived":[{"label":"Perl","archived":true}],"stats":{"total":2,"archived":1}}}`,
+		},
+		{
+			name:      "variables, fragments and directives",
+			query:     `query Items($tags: [String!], $full: Boolean = false) { items(tag: $tags) { ...names description @include(if: $full) } } fragment names on RadarItem { label __typename }`,
+			variables: map[string]any{"tags": []string{"backend"}},
+			status:    http.StatusOK,
+			want:      `{"data":{"items":[{"label":"Go","__typename":"RadarItem"}]}}`,
+		},
+		{
+			name:   "item",
+			query:  `{ item(id: "go") { label tags } missing: item(id: "cobol") { label } }`,
+			status: http.StatusOK,
+			want:   `{"data":{"item":{"label":"Go","tags":["backend"]},"missing":null}}`,
+		},
+		{
+			name:   "quadrants and rings",
+			query:  `{ rings { name order count } quadrants { name count } }`,
+			status: http.StatusOK,
+			want:   `{"data":{"rings":[{"name":"Adopted","order":0,"count":1},{"name":"In Discovery","order":1,"count":1},{"name":"Not Recommended","order":2,"count":0}],"quadrants":[{"name":"Platforms","count":0},{"name":"Tools","count":2},{"name":"Programming Languages \u0026 Frameworks","count":0},{"name":"Techniques","count":0}]}}`,
+		},
+		{
+			name:   "invalid arguments fail the field",
+			query:  `{ items(ring: "Hold") { label } }`,
+			status: http.StatusOK,
+			want:   `{"errors":[{"message":"Invalid ring \"Hold\", must be one of Adopted, In Discovery, Not Recommended","path":["items"]}],"data":null}`,
+		},
+		{
+			name:   "history without Git",
+			query:  `{ item(id: "go") { label } history { id } }`,
+			status: http.StatusOK,
+			want:   `{"errors":[{"message":"Radar history requires the data files to be in a Git repository","path":["history"]}],"data":null}`,
+		},
+		{
+			name:   "unknown field",
+			query:  `{ items { label votes } }`,
+			status: http.StatusBadRequest,
+			want:   `{"errors":[{"message":"Cannot query field \"votes\" on type RadarItem"}]}`,
+		},
+		{
+			name:   "object without selection",
+			query:  `{ stats }`,
+			status: http.StatusBadRequest,
+			want:   `{"errors":[{"message":"Field \"stats\" of type RadarStats must have a selection of subfields"}]}`,
+		},
+		{
+			name:   "unknown argument",
+			query:  `{ items(color: "red") { label } }`,
+			status: http.StatusBadRequest,
+			want:   `{"errors":[{"message":"Unknown argument \"color\" on field \"Query.items\""}]}`,
+		},
+		{
+			name:   "missing required argument",
+			query:  `{ item { label } }`,
+			status: http.StatusBadRequest,
+			want:   `{"errors":[{"message":"Argument \"id\" of required type String! was not provided"}]}`,
+		},
+		{
+			name:   "mutation",
+			query:  `mutation { items { label } }`,
+			status: http.StatusBadRequest,
+			want:   `{"errors":[{"message":"Only queries are supported, not mutations"}]}`,
+		},
+		{
+			name:   "fragment spreading itself",
+			query:  `{ items { ...f } } fragment f on RadarItem { label ...f }`,
+			status: http.StatusBadRequest,
+			want:   `{"errors":[{"message":"The query is nested deeper than 20 levels"}]}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			status, body := postGraphQL(t, handler, tt.query, tt.variables)
+			if status != tt.status || body != tt.want {
+				t.Errorf("POST /graphql = %d %s\nwant %d %s", status, body, tt.status, tt.want)
+			}
+		})
+	}
+
+	// Queries may be sent with GET, and operations picked by name.
+	query := url.Values{"query": {`query A { tags { tag } } query B { tags { count } }`}, "operationName": {"B"}}
+	if rec := doRequest(t, handler, http.MethodGet, "/graphql?"+query.Encode()); rec.Code != http.StatusOK || strings.TrimSpace(rec.Body.String()) != `{"data":{"tags":[{"count":1}]}}` {
+		t.Errorf("GET /graphql = %d %s", rec.Code, rec.Body.String())
+	}
+	if rec := doRequest(t, handler, http.MethodPut, "/graphql"); rec.Code != http.StatusMethodNotAllowed {
+		t.Errorf("PUT /graphql = %d, want %d", rec.Code, http.StatusMethodNotAllowed)
+	}
+}
+
+func TestGraphQLHistory(t *testing.T) {
+	repo, commit := gitRadarRepo(t)
+	commit(radarWith("Go", "In Discovery"))
+	commit(radarWith("Go", "Adopted"))
+
+	cfg := defaultConfig()
+	cfg.Data.Path = filepath.Join(repo, "data", "radar.yaml")
+	useConfig(t, cfg)
+	handler, err := setupRoutes(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, body := postGraphQL(t, handler, `{ history(item: "Go") { id events { event ring } } item(id: "go") { history { event } } }`, nil)
+	want := `{"data":{"history":[{"id":"go","events":[{"event":"added","ring":"In Discovery"},{"event":"moved","ring":"Adopted"}]}],"item":{"history":[{"event":"added"},{"event":"moved"}]}}}`
+	if body != want {
+		t.Errorf("history = %s, want %s", body, want)
+	}
+}
+
+func TestGraphQLItemDetail(t *testing.T) {
+	db := openTestStore(t, "sqlite", "file::memory:")
+	ctx := context.Background()
+	seed := RadarData{Items: []RadarItem{
+		{ID: "go", Label: "Go", Quadrant: "Tools", Ring: "Adopted"},
+		{ID: "zig", Label: "Zig", Quadrant: "Tools", Ring: "In Discovery", Relations: Relations{{relationReplaces, "go"}}},
+	}}
+	if err := db.Save(ctx, seed, AuditEvent{Actor: "test", Action: "seed"}); err != nil {
+		t.Fatal(err)
+	}
+	created := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	for _, vote := range []Vote{{"go", "Jane", created}, {"go", "Joe", created}} {
+		if _, err := db.SaveVote(ctx, vote); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := db.SaveReaction(ctx, Reaction{"go", reactionRocket, "Jane", created}); err != nil {
+		t.Fatal(err)
+	}
+	cfg := defaultConfig()
+	useConfig(t, cfg)
+	useStore(t, &databaseStore{db: db})
+	handler, err := setupRoutes(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The item has the details GET /api/v1/radar/items/{id} serves.
+	_, body := postGraphQL(t, handler, `{ item(id: "go") { label votes reactions relatedBy { type item } } }`, nil)
+	if want := `{"data":{"item":{"label":"Go","votes":2,"reactions":{"+1":0,"rocket":1,"warning":0},"relatedBy":[{"type":"replaces","item":"zig"}]}}}`; body != want {
+		t.Errorf("item = %s, want %s", body, want)
+	}
+	_, body = postGraphQL(t, handler, `{ __type(name: "ItemDetail") { fields { name type { name } } } }`, nil)
+	if !strings.Contains(body, `{"name":"reactions","type":{"name":"JSON"}}`) {
+		t.Errorf("ItemDetail type = %s, want reactions a JSON scalar", body)
+	}
+}
+
+func TestGraphQLIntrospection(t *testing.T) {
+	cfg := defaultConfig()
+	cfg.Data.Path = writeFile(t, "radar.yaml", radarWith("Go", "Adopted"))
+	useConfig(t, cfg)
+	handler, err := setupRoutes(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	status, body := postGraphQL(t, handler, `{
+  __schema {
+    queryType { name }
+    types { kind name fields(includeDeprecated: true) { name type { kind name ofType { kind name ofType { kind name ofType { kind name } } } } } }
+  }
+}`, nil)
+	if status != http.StatusOK {
+		t.Fatalf("introspection = %d %s", status, body)
+	}
+	var resp struct {
+		Data struct {
+			Schema introSchema `json:"__schema"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal([]byte(body), &resp); err != nil {
+		t.Fatal(err)
+	}
+	types := make(map[string]introType)
+	for _, typ := range resp.Data.Schema.Types {
+		types[*typ.Name] = typ
+	}
+	if *resp.Data.Schema.QueryType.Name != "Query" || types["Query"].Fields == nil || len(*types["Query"].Fields) != len(graphQLQueryFields) {
+		t.Fatalf("schema = %s, want the Query type with its fields", body)
+	}
+	// Every type a field refers to is listed.
+	for _, typ := range types {
+		if typ.Fields == nil {
+			continue
+		}
+		for _, field := range *typ.Fields {
+			ref := &field.Type
+			for ref.OfType != nil {
+				ref = ref.OfType
+			}
+			if _, ok := types[*ref.Name]; !ok {
+				t.Errorf("%s.%s is of type %s, which isn't listed", *typ.Name, field.Name, *ref.Name)
+			}
+		}
+	}
+
+	_, body = postGraphQL(t, handler, `{ __type(name: "ItemHistory") { name fields { name } } }`, nil)
+	if want := `{"data":{"__type":{"name":"ItemHistory","fields":[{"name":"id"},{"name":"label"},{"name":"events"}]}}}`; body != want {
+		t.Errorf("__type = %s, want %s", body, want)
+	}
+}
@@ -0,0 +1,471 @@
+package main
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// grpcService is the full name of the service in radar.proto, the first
+// element of the path of its methods.
+const grpcService = "radar.v1.RadarService"
+
+// grpcMaxMessageSize is the largest request message accepted, the default
+// of gRPC implementations.
+const grpcMaxMessageSize = 4 << 20
+
+// grpcCode is a gRPC status code.
+type grpcCode int
+
+// The gRPC status codes the server sends.
+const (
+	grpcOK                 grpcCode = 0
+	grpcCanceled           grpcCode = 1
+	grpcInvalidArgument    grpcCode = 3
+	grpcDeadlineExceeded   grpcCode = 4
+	grpcNotFound           grpcCode = 5
+	grpcPermissionDenied   grpcCode = 7
+	grpcResourceExhausted  grpcCode = 8
+	grpcFailedPrecondition grpcCode = 9
+	grpcAborted            grpcCode = 10
+	grpcUnimplemented      grpcCode = 12
+	grpcInternal           grpcCode = 13
+	grpcUnavailable        grpcCode = 14
+	grpcUnauthenticated    grpcCode = 16
+)
+
+// grpcCodes maps the status of an AppError to the gRPC status reporting
+// it, following the mapping of the Google API design guide.
+var grpcCodes = map[int]grpcCode{
+	http.StatusBadRequest:            grpcInvalidArgument,
+	http.StatusUnauthorized:          grpcUnauthenticated,
+	http.StatusForbidden:             grpcPermissionDenied,
+	http.StatusNotFound:              grpcNotFound,
+	http.StatusConflict:              grpcAborted,
+	http.StatusPreconditionFailed:    grpcFailedPrecondition,
+	http.StatusRequestEntityTooLarge: grpcResourceExhausted,
+	http.StatusTooManyRequests:       grpcResourceExhausted,
+	http.StatusNotImplemented:        grpcUnimplemented,
+	http.StatusServiceUnavailable:    grpcUnavailable,
+	http.StatusGatewayTimeout:        grpcDeadlineExceeded,
+}
+
+// grpcError is a call failing with a gRPC status.
+type grpcError struct {
+	code    grpcCode
+	message string
+}
+
+func (e *grpcError) Error() string {
+	return e.message
+}
+
+// errServerClosing cancels the calls still running when the gRPC server
+// shuts down, so streams end instead of holding up the shutdown.
+var errServerClosing = errors.New("the server is shutting down")
+
+// grpcStatus returns the gRPC status reporting err, with a message safe to
+// send to the client.
+func grpcStatus(err error) (grpcCode, string) {
+	var appErr *AppError
+	var rpcErr *grpcError
+	switch {
+	case err == nil:
+		return grpcOK, ""
+	case errors.As(err, &rpcErr):
+		return rpcErr.code, rpcErr.message
+	case errors.As(err, &appErr):
+		if code, ok := grpcCodes[appErr.Code]; ok {
+			return code, appErr.Message
+		}
+		return grpcInternal, appErr.Message
+	case errors.Is(err, errServerClosing):
+		return grpcUnavailable, err.Error()
+	case errors.Is(err, context.DeadlineExceeded):
+		return grpcDeadlineExceeded, "Deadline exceeded"
+	case errors.Is(err, context.Canceled):
+		return grpcCanceled, "Call canceled"
+	}
+	return grpcInternal, "Internal Server Error"
+}
+
+// grpcParam is the query parameter of the REST endpoint a field of a
+// request message stands for. value returns the parameter of the field,
+// or "" to leave it out.
+type grpcParam struct {
+	name  string
+	value func(protoField) (string, error)
+}
+
+// Values of grpcParams.
+var (
+	stringParam = protoField.string
+	boolParam   = func(f protoField) (string, error) {
+		v, err := f.bool()
+		return strconv.FormatBool(v), err
+	}
+	// intParam leaves out 0, proto3's default, so the endpoint's default
+	// applies.
+	intParam = func(f protoField) (string, error) {
+		v, err := f.int32()
+		if v == 0 {
+			return "", err
+		}
+		return strconv.Itoa(int(v)), err
+	}
+)
+
+// grpcMethod is a method of the RadarService. params maps the fields of
+// its request message to query parameters, and unary returns its response
+// or stream sends its responses until it returns.
+type grpcMethod struct {
+	params map[int]grpcParam
+	unary  func(r *http.Request) (protoMessage, error)
+	stream func(r *http.Request, send func(protoMessage) error) error
+}
+
+// grpcMethods are the methods of the RadarService, by name. Their requests
+// are passed to them as the query parameters of the REST endpoint they
+// match, so they parse them like it does and report the same errors.
+var grpcMethods = map[string]grpcMethod{
+	"ListItems": {
+		params: map[int]grpcParam{
+			1:  {"quadrant", stringParam},
+			2:  {"ring", stringParam},
+			3:  {"owner", stringParam},
+			4:  {"tag", stringParam},
+			5:  {"moved", boolParam},
+			6:  {"includeArchived", boolParam},
+			7:  {"sort", stringParam},
+			8:  {"order", stringParam},
+			9:  {"limit", intParam},
+			10: {"offset", intParam},
+		},
+		unary: grpcListItems,
+	},
+	"GetItem": {
+		params: map[int]grpcParam{1: {"id", stringParam}},
+		unary:  grpcGetItem,
+	},
+	"Search": {
+		params: map[int]grpcParam{
+			1: {"q", stringParam},
+			2: {"limit", intParam},
+			3: {"includeArchived", boolParam},
+		},
+		unary: grpcSearch,
+	},
+	"WatchChanges": {stream: grpcWatchChanges},
+}
+
+// grpcQuery returns the query parameters the fields of a request message
+// stand for. Unknown fields are ignored, as protobuf requires.
+func grpcQuery(msg []byte, params map[int]grpcParam) (url.Values, error) {
+	fields, err := decodeProto(msg)
+	if err != nil {
+		return nil, &grpcError{grpcInvalidArgument, "Invalid request message: " + err.Error()}
+	}
+	query := url.Values{}
+	for _, f := range fields {
+		param, ok := params[f.number]
+		if !ok {
+			continue
+		}
+		value, err := param.value(f)
+		if err != nil {
+			return nil, &grpcError{grpcInvalidArgument, "Invalid request message: " + err.Error()}
+		}
+		if value != "" {
+			query.Add(param.name, value)
+		}
+	}
+	return query, nil
+}
+
+// protoItem encodes item as an Item message.
+func protoItem(item RadarItem) protoMessage {
+	var m protoMessage
+	m.string(1, item.ID)
+	m.string(2, item.Label)
+	m.string(3, item.Quadrant)
+	m.string(4, item.Ring)
+	m.bool(5, item.Moved)
+	m.string(6, item.Description)
+	for _, owner := range item.Owners {
+		var o protoMessage
+		o.string(1, owner.Name)
+		o.string(2, owner.Email)
+		o.string(3, owner.Team)
+		o.string(4, owner.Slack)
+		m.message(7, o)
+	}
+	m.strings(8, item.Tags)
+	for _, link := range item.Links {
+		var l protoMessage
+		l.string(1, link.Title)
+		l.string(2, link.URL)
+		m.message(9, l)
+	}
+	m.timestamp(10, item.LastUpdated)
+	m.bool(11, item.Archived)
+	m.strings(12, item.MergedFrom)
+	m.string(13, item.ReviewDate)
+	m.string(14, item.Direction)
+	m.string(15, item.PreviousRing)
+	m.string(16, item.SunsetDate)
+	for _, relation := range item.Relations {
+		var r protoMessage
+		r.string(1, relation.Type)
+		r.string(2, relation.Item)
+		m.message(17, r)
+	}
+	return m
+}
+
+// grpcListItems answers ListItems with the page of items selectPage
+// selects.
+func grpcListItems(r *http.Request) (protoMessage, error) {
+	items, total, err := selectPage(r)
+	if err != nil {
+		return nil, err
+	}
+	var resp protoMessage
+	for _, item := range items {
+		resp.message(1, protoItem(item))
+	}
+	resp.int(2, int64(total))
+	return resp, nil
+}
+
+// grpcGetItem answers GetItem with the item with the requested ID.
+func grpcGetItem(r *http.Request) (protoMessage, error) {
+	id := r.URL.Query().Get("id")
+	if id == "" {
+		return nil, &AppError{Code: http.StatusBadRequest, Message: "Missing item ID"}
+	}
+	data, err := loadRadarData()
+	if err != nil {
+		return nil, err
+	}
+	item, ok := findItem(data.Items, id)
+	if !ok {
+		return nil, &AppError{Code: http.StatusNotFound, Message: "Unknown item"}
+	}
+	return protoItem(item), nil
+}
+
+// grpcSearch answers Search with the results runSearch finds.
+func grpcSearch(r *http.Request) (protoMessage, error) {
+	_, total, results, err := runSearch(r)
+	if err != nil {
+		return nil, err
+	}
+	var resp protoMessage
+	for _, result := range results {
+		var m protoMessage
+		m.message(1, protoItem(result.RadarItem))
+		m.int(2, int64(result.Score))
+		m.strings(3, result.Matched)
+		m.string(4, result.Snippet)
+		resp.message(1, m)
+	}
+	resp.int(2, int64(total))
+	return resp, nil
+}
+
+// grpcWatchChanges streams a Change message with the items added, updated
+// and removed every time the active store reports a change. It ends with
+// UNAVAILABLE when the store is closed, as it is when a reload replaces
+// it, so clients reconnect to the new one.
+func grpcWatchChanges(r *http.Request, send func(protoMessage) error) error {
+	ctx := r.Context()
+	store := currentStore()
+	changes := store.Watch(ctx)
+	data, err := loadStoreData(store)
+	if err != nil {
+		return err
+	}
+	items := storedData(data).Items
+	for {
+		if _, ok := <-changes; !ok {
+			if err := context.Cause(ctx); err != nil {
+				return err
+			}
+			return &grpcError{grpcUnavailable, "The radar data store was closed"}
+		}
+		data, err := loadStoreData(store)
+		if err != nil {
+			slog.Warn("Failed to load changed radar data", "err", err)
+			continue
+		}
+		edits := diffItems(items, storedData(data).Items)
+		items = storedData(data).Items
+		if len(edits) == 0 {
+			continue
+		}
+		var change protoMessage
+		change.timestamp(1, time.Now())
+		for _, edit := range edits {
+			switch edit.Action {
+			case editAdded:
+				change.message(2, protoItem(*edit.After))
+			case editUpdated:
+				change.message(3, protoItem(*edit.After))
+			case editRemoved:
+				change.bytes(4, []byte(edit.Before.ID))
+			}
+		}
+		if err := send(change); err != nil {
+			return err
+		}
+	}
+}
+
+// readGRPCMessage reads the length-prefixed message a unary call sends.
+func readGRPCMessage(body io.Reader) ([]byte, error) {
+	var prefix [5]byte
+	if _, err := io.ReadFull(body, prefix[:]); err != nil {
+		return nil, &grpcError{grpcInvalidArgument, "Missing request message"}
+	}
+	if prefix[0] != 0 {
+		return nil, &grpcError{grpcUnimplemented, "Compressed messages are not supported"}
+	}
+	size := binary.BigEndian.Uint32(prefix[1:])
+	if size > grpcMaxMessageSize {
+		return nil, &grpcError{grpcResourceExhausted, fmt.Sprintf("Request message larger than %d bytes", grpcMaxMessageSize)}
+	}
+	msg := make([]byte, size)
+	if _, err := io.ReadFull(body, msg); err != nil {
+		return nil, &grpcError{grpcInvalidArgument, "Truncated request message"}
+	}
+	return msg, nil
+}
+
+// writeGRPCMessage writes msg to w, length-prefixed, and flushes it.
+func writeGRPCMessage(w http.ResponseWriter, msg protoMessage) error {
+	frame := make([]byte, 5, 5+len(msg))
+	binary.BigEndian.PutUint32(frame[1:], uint32(len(msg)))
+	if _, err := w.Write(append(frame, msg...)); err != nil {
+		return err
+	}
+	return http.NewResponseController(w).Flush()
+}
+
+// parseGRPCTimeout parses the grpc-timeout header, such as "500m" for
+// 500 milliseconds.
+func parseGRPCTimeout(value string) (time.Duration, error) {
+	units := map[byte]time.Duration{'H': time.Hour, 'M': time.Minute, 'S': time.Second, 'm': time.Millisecond, 'u': time.Microsecond, 'n': time.Nanosecond}
+	if len(value) < 2 || len(value) > 9 {
+		return 0, fmt.Errorf("invalid grpc-timeout %q", value)
+	}
+	unit, ok := units[value[len(value)-1]]
+	n, err := strconv.ParseUint(value[:len(value)-1], 10, 64)
+	if !ok || err != nil {
+		return 0, fmt.Errorf("invalid grpc-timeout %q", value)
+	}
+	return time.Duration(n) * unit, nil
+}
+
+// grpcMessage percent-encodes a status message for the grpc-message
+// trailer.
+func grpcMessage(message string) string {
+	var b strings.Builder
+	for i := 0; i < len(message); i++ {
+		if c := message[i]; c < ' ' || c > '~' || c == '%' {
+			fmt.Fprintf(&b, "%%%02X", c)
+		} else {
+			b.WriteByte(c)
+		}
+	}
+	return b.String()
+}
+
+// grpcHandler serves the RadarService of radar.proto over HTTP/2. Requests
+// and responses use the protobuf encoding; compressed messages are
+// rejected. The status of every call is sent in the grpc-status and
+// grpc-message trailers.
+func grpcHandler(w http.ResponseWriter, r *http.Request) {
+	start := time.Now()
+	if r.ProtoMajor != 2 {
+		http.Error(w, "gRPC requires HTTP/2", http.StatusHTTPVersionNotSupported)
+		return
+	}
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		http.Error(w, "Method Not Allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	contentType := r.Header.Get("Content-Type")
+	if contentType != "application/grpc" && !strings.HasPrefix(contentType, "application/grpc+proto") && !strings.HasPrefix(contentType, "application/grpc;") {
+		http.Error(w, "Unsupported Media Type", http.StatusUnsupportedMediaType)
+		return
+	}
+
+	// The headers are sent right away, so clients of a stream know the
+	// call started before the first message.
+	w.Header().Set("Content-Type", "application/grpc")
+	w.WriteHeader(http.StatusOK)
+	http.NewResponseController(w).Flush()
+
+	name, _ := strings.CutPrefix(r.URL.Path, "/"+grpcService+"/")
+	err := func() error {
+		method, ok := grpcMethods[name]
+		if !ok {
+			return &grpcError{grpcUnimplemented, fmt.Sprintf("Unknown method %s", r.URL.Path)}
+		}
+		ctx := r.Context()
+		if value := r.Header.Get("Grpc-Timeout"); value != "" {
+			timeout, err := parseGRPCTimeout(value)
+			if err != nil {
+				return &grpcError{grpcInvalidArgument, err.Error()}
+			}
+			var cancel context.CancelFunc
+			ctx, cancel = context.WithTimeout(ctx, timeout)
+			defer cancel()
+		}
+		msg, err := readGRPCMessage(r.Body)
+		if err != nil {
+			return err
+		}
+		query, err := grpcQuery(msg, method.params)
+		if err != nil {
+			return err
+		}
+		req := withQuery(r.WithContext(ctx), query)
+		if method.stream != nil {
+			return method.stream(req, func(msg protoMessage) error {
+				return writeGRPCMessage(w, msg)
+			})
+		}
+		resp, err := method.unary(req)
+		if err != nil {
+			return err
+		}
+		return writeGRPCMessage(w, resp)
+	}()
+
+	code, message := grpcStatus(err)
+	w.Header().Set(http.TrailerPrefix+"Grpc-Status", strconv.Itoa(int(code)))
+	if message != "" {
+		w.Header().Set(http.TrailerPrefix+"Grpc-Message", grpcMessage(message))
+	}
+	if code != grpcOK && code != grpcCanceled && !errors.Is(err, errServerClosing) {
+		slog.Error("RPC failed", "method", r.URL.Path, "code", code, "err", err)
+	}
+	if currentConfig().Logging.AccessLog {
+		slog.Info("rpc",
+			"method", r.URL.Path,
+			"code", code,
+			"duration", time.Since(start),
+			"remote", r.RemoteAddr,
+		)
+	}
+}
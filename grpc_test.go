@@ -0,0 +1,284 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+	"time"
+)
+
+// startGRPCServer serves the gRPC API on a local port until the test ends,
+// and returns the server and its address.
+func startGRPCServer(t *testing.T, cfg Config) (*http.Server, string) {
+	t.Helper()
+	cfg.Server.GRPCListen = "127.0.0.1:0"
+	srv := newGRPCServer(cfg, nil)
+	ln, err := net.Listen("tcp", srv.Addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	go srv.Serve(ln)
+	t.Cleanup(func() { srv.Close() })
+	return srv, ln.Addr().String()
+}
+
+// grpcClient returns a client speaking HTTP/2 without TLS.
+func grpcClient() *http.Client {
+	transport := &http.Transport{Protocols: new(http.Protocols)}
+	transport.Protocols.SetUnencryptedHTTP2(true)
+	return &http.Client{Transport: transport}
+}
+
+// grpcStream calls method of the RadarService at addr with req and returns
+// the response, whose body is the stream of messages.
+func grpcStream(t *testing.T, addr, method string, req protoMessage) *http.Response {
+	t.Helper()
+	body := binary.BigEndian.AppendUint32([]byte{0}, uint32(len(req)))
+	httpReq, err := http.NewRequest(http.MethodPost, "http://"+addr+"/"+grpcService+"/"+method, bytes.NewReader(append(body, req...)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	httpReq.Header.Set("Content-Type", "application/grpc")
+	resp, err := grpcClient().Do(httpReq)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.StatusCode != http.StatusOK || resp.Header.Get("Content-Type") != "application/grpc" {
+		t.Fatalf("%s = %d %q", method, resp.StatusCode, resp.Header.Get("Content-Type"))
+	}
+	return resp
+}
+
+// grpcCall calls method of the RadarService at addr with req and returns
+// the messages of the response, its grpc-status and its grpc-message.
+func grpcCall(t *testing.T, addr, method string, req protoMessage) ([][]byte, string, string) {
+	t.Helper()
+	resp := grpcStream(t, addr, method, req)
+	defer resp.Body.Close()
+	var msgs [][]byte
+	for {
+		msg, err := readGRPCMessage(resp.Body)
+		if err != nil {
+			break
+		}
+		msgs = append(msgs, msg)
+	}
+	io.Copy(io.Discard, resp.Body)
+	return msgs, resp.Trailer.Get("Grpc-Status"), resp.Trailer.Get("Grpc-Message")
+}
+
+// protoValues returns the values of a string or embedded message field of
+// msg.
+func protoValues(t *testing.T, msg []byte, number int) []string {
+	t.Helper()
+	fields, err := decodeProto(msg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var values []string
+	for _, f := range fields {
+		if f.number == number {
+			values = append(values, string(f.data))
+		}
+	}
+	return values
+}
+
+// itemLabels returns the labels of the Item messages of a field of msg.
+func itemLabels(t *testing.T, msg []byte, number int) []string {
+	t.Helper()
+	var labels []string
+	for _, item := range protoValues(t, msg, number) {
+		labels = append(labels, protoValues(t, []byte(item), 2)...)
+	}
+	return labels
+}
+
+func TestGRPCUnaryMethods(t *testing.T) {
+	cfg := defaultConfig()
+	cfg.Data.Path = writeFile(t, "radar.yaml", `Items:
+- Label: Go
+  Quadrant: Tools
+  Ring: Adopted
+  Owners: [{Name: Ana, Team: Platform}]
+  Tags: [backend]
+- Label: Rust
+  Quadrant: Tools
+  Ring: In Discovery
+  Description: Like Go, safer.
+- Label: Perl
+  Quadrant: Tools
+  Ring: Not Recommended
+  Archived: true
+`)
+	useConfig(t, cfg)
+	_, addr := startGRPCServer(t, cfg)
+
+	var list protoMessage
+	list.string(2, "Adopted")
+	list.string(2, "In Discovery")
+	list.string(7, "label")
+	list.int(9, 1)
+	msgs, status, message := grpcCall(t, addr, "ListItems", list)
+	if status != "0" || len(msgs) != 1 {
+		t.Fatalf("ListItems = %d messages, status %s %q", len(msgs), status, message)
+	}
+	if labels := itemLabels(t, msgs[0], 1); !slices.Equal(labels, []string{"Go"}) {
+		t.Errorf("ListItems items = %v, want Go", labels)
+	}
+	if fields, _ := decodeProto(msgs[0]); fields[len(fields)-1].number != 2 || fields[len(fields)-1].value != 2 {
+		t.Errorf("ListItems total = %+v, want 2", fields[len(fields)-1])
+	}
+	item := protoValues(t, msgs[0], 1)[0]
+	if owners := protoValues(t, []byte(item), 7); len(owners) != 1 || !slices.Equal(protoValues(t, []byte(owners[0]), 3), []string{"Platform"}) {
+		t.Errorf("Go owners = %q, want Ana of Platform", owners)
+	}
+
+	var archived protoMessage
+	archived.bool(6, true)
+	if msgs, _, _ := grpcCall(t, addr, "ListItems", archived); len(msgs) != 1 || len(itemLabels(t, msgs[0], 1)) != 3 {
+		t.Errorf("ListItems with include_archived = %v, want 3 items", msgs)
+	}
+
+	var search protoMessage
+	search.string(1, "go")
+	msgs, status, _ = grpcCall(t, addr, "Search", search)
+	if status != "0" || len(msgs) != 1 {
+		t.Fatalf("Search status = %s", status)
+	}
+	results := protoValues(t, msgs[0], 1)
+	if len(results) != 2 || !slices.Equal(itemLabels(t, []byte(results[0]), 1), []string{"Go"}) || !slices.Equal(protoValues(t, []byte(results[1]), 3), []string{"description"}) {
+		t.Errorf("Search results = %q, want Go then Rust by description", results)
+	}
+
+	tests := []struct {
+		method, id    string
+		status, label string
+		message       string
+	}{
+		{method: "GetItem", id: "perl", status: "0", label: "Perl"},
+		{method: "GetItem", id: "cobol", status: "5", message: "Unknown item"},
+		{method: "GetItem", status: "3", message: "Missing item ID"},
+		{method: "Search", status: "3", message: "Missing search query q"},
+		{method: "DeleteItem", status: "12", message: "Unknown method /radar.v1.RadarService/DeleteItem"},
+	}
+	for _, tt := range tests {
+		var req protoMessage
+		req.string(1, tt.id)
+		msgs, status, message := grpcCall(t, addr, tt.method, req)
+		if status != tt.status || message != tt.message {
+			t.Errorf("%s(%q) = status %s %q, want %s %q", tt.method, tt.id, status, message, tt.status, tt.message)
+		}
+		if tt.label != "" && (len(msgs) != 1 || !slices.Equal(protoValues(t, msgs[0], 2), []string{tt.label})) {
+			t.Errorf("%s(%q) = %q, want %s", tt.method, tt.id, msgs, tt.label)
+		}
+	}
+
+	var invalid protoMessage
+	invalid.string(2, "Hold")
+	if _, status, message := grpcCall(t, addr, "ListItems", invalid); status != "3" || message != "Invalid ring \"Hold\", must be one of Adopted, In Discovery, Not Recommended" {
+		t.Errorf("ListItems with an unknown ring = status %s %q", status, message)
+	}
+}
+
+func TestGRPCWatchChanges(t *testing.T) {
+	cfg := defaultConfig()
+	cfg.Data.Path = writeFile(t, "radar.yaml", radarWith("Go", "Adopted"))
+	useConfig(t, cfg)
+	srv, addr := startGRPCServer(t, cfg)
+
+	resp := grpcStream(t, addr, "WatchChanges", nil)
+	defer resp.Body.Close()
+	changes := make(chan []byte)
+	go func() {
+		defer close(changes)
+		for {
+			msg, err := readGRPCMessage(resp.Body)
+			if err != nil {
+				return
+			}
+			changes <- msg
+		}
+	}()
+
+	// The call may not be watching yet when the file is first written, so
+	// the ring of Go is flipped until a change is sent.
+	var change []byte
+	for i := 0; change == nil; i++ {
+		if i == 20 {
+			t.Fatal("WatchChanges sent no change")
+		}
+		// The file is replaced at once, so it isn't seen empty.
+		if err := replaceFile(cfg.Data.Path, []byte(radarWith("Go", []string{"In Discovery", "Adopted"}[i%2])), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		select {
+		case change = <-changes:
+		case <-time.After(200 * time.Millisecond):
+		}
+	}
+	if updated := itemLabels(t, change, 3); !slices.Equal(updated, []string{"Go"}) || len(protoValues(t, change, 1)) != 1 {
+		t.Errorf("change updated %v, want Go at a time", updated)
+	}
+
+	if err := replaceFile(cfg.Data.Path, []byte(radarWith("Rust", "Adopted")), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case change = <-changes:
+		if added, removed := itemLabels(t, change, 2), protoValues(t, change, 4); !slices.Equal(added, []string{"Rust"}) || !slices.Equal(removed, []string{"go"}) {
+			t.Errorf("change added %v and removed %v, want Rust and go", added, removed)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("WatchChanges sent no change for a replaced item")
+	}
+
+	// Shutting down ends the call, so clients reconnect elsewhere.
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := srv.Shutdown(ctx); err != nil {
+		t.Fatal(err)
+	}
+	for range changes {
+	}
+	if status := resp.Trailer.Get("Grpc-Status"); status != "14" {
+		t.Errorf("WatchChanges ended with status %s %q, want 14", status, resp.Trailer.Get("Grpc-Message"))
+	}
+}
+
+func TestGRPCHandlerRejectsOtherRequests(t *testing.T) {
+	req := httptest.NewRequest(http.MethodPost, "/"+grpcService+"/ListItems", nil)
+	req.Header.Set("Content-Type", "application/grpc")
+	rec := httptest.NewRecorder()
+	grpcHandler(rec, req)
+	if rec.Code != http.StatusHTTPVersionNotSupported {
+		t.Errorf("gRPC over HTTP/1.1 = %d, want %d", rec.Code, http.StatusHTTPVersionNotSupported)
+	}
+
+	req.ProtoMajor = 2
+	req.Header.Set("Content-Type", "application/json")
+	rec = httptest.NewRecorder()
+	grpcHandler(rec, req)
+	if rec.Code != http.StatusUnsupportedMediaType {
+		t.Errorf("JSON request = %d, want %d", rec.Code, http.StatusUnsupportedMediaType)
+	}
+}
+
+func TestParseGRPCTimeout(t *testing.T) {
+	for value, want := range map[string]time.Duration{"1H": time.Hour, "500m": 500 * time.Millisecond, "30S": 30 * time.Second, "100u": 100 * time.Microsecond} {
+		if got, err := parseGRPCTimeout(value); err != nil || got != want {
+			t.Errorf("parseGRPCTimeout(%q) = %s, %v, want %s", value, got, err, want)
+		}
+	}
+	for _, value := range []string{"", "5", "5s", "-1S", "123456789S"} {
+		if _, err := parseGRPCTimeout(value); err == nil {
+			t.Errorf("parseGRPCTimeout(%q) succeeded", value)
+		}
+	}
+}
@@ -0,0 +1,302 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// errNoHistory is returned when the radar data is not tracked by Git.
+var errNoHistory = errors.New("radar data is not in a Git repository")
+
+// History event kinds.
+const (
+	historyAdded   = "added"
+	historyMoved   = "moved"
+	historyRemoved = "removed"
+)
+
+// HistoryEvent is a change to an item found in the Git history of the data
+// files.
+type HistoryEvent struct {
+	Event        string    `json:"event"`
+	Commit       string    `json:"commit"`
+	Date         time.Time `json:"date"`
+	Ring         string    `json:"ring,omitempty"`
+	PreviousRing string    `json:"previousRing,omitempty"`
+	Quadrant     string    `json:"quadrant,omitempty"`
+	// Label is the label of the item the event happened to when it was one
+	// of the items merged into this one.
+	Label string `json:"label,omitempty"`
+}
+
+// ItemHistory lists the changes to one item, oldest first. Items are
+// followed by ID, so an item renamed after its ID was written to the data
+// file keeps its history.
+type ItemHistory struct {
+	ID     string         `json:"id"`
+	Label  string         `json:"label"`
+	Events []HistoryEvent `json:"events"`
+}
+
+// historyCache holds the history computed for a data path at a commit, so
+// it is only rebuilt when a new commit is checked out.
+var historyCache struct {
+	sync.Mutex
+	key   string
+	items []ItemHistory
+}
+
+// gitDataRepo returns the toplevel of the Git repository of the configured
+// data files, its HEAD commit and the data files, or errNoHistory if they
+// are not tracked by Git.
+func gitDataRepo(ctx context.Context) (top, head string, files []string, err error) {
+	path := currentConfig().Data.dataPath()
+	if isRemoteDataPath(path) {
+		return "", "", nil, errNoHistory
+	}
+	if files, err = dataFiles(path); err != nil {
+		return "", "", nil, err
+	}
+	if top, err = runGit(ctx, filepath.Dir(files[0]), "rev-parse", "--show-toplevel"); err != nil {
+		return "", "", nil, errNoHistory
+	}
+	if head, err = runGit(ctx, top, "rev-parse", "HEAD"); err != nil {
+		return "", "", nil, errNoHistory
+	}
+	return top, head, files, nil
+}
+
+// loadRadarHistory returns the history of every item in the configured data
+// files, or errNoHistory if they are not tracked by Git.
+func loadRadarHistory(ctx context.Context) ([]ItemHistory, error) {
+	top, head, files, err := gitDataRepo(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	historyCache.Lock()
+	defer historyCache.Unlock()
+	key := head + "\x00" + currentConfig().Data.dataPath()
+	if historyCache.key == key {
+		return historyCache.items, nil
+	}
+	items, err := radarHistory(ctx, top, files)
+	if err != nil {
+		return nil, err
+	}
+	historyCache.key, historyCache.items = key, items
+	return items, nil
+}
+
+// walkRadarCommits calls fn with the author and items of files, keyed by
+// ID, at every commit of the repository at top that touched one of them,
+// oldest first. Commits where a file can't be decoded are skipped.
+func walkRadarCommits(ctx context.Context, top string, files []string, fn func(commit, author string, date time.Time, items map[string]RadarItem)) error {
+	rels := make([]string, len(files))
+	for i, file := range files {
+		abs, err := filepath.Abs(file)
+		if err != nil {
+			return err
+		}
+		// The toplevel is reported with symlinks resolved.
+		if resolved, err := filepath.EvalSymlinks(abs); err == nil {
+			abs = resolved
+		}
+		rel, err := filepath.Rel(top, abs)
+		if err != nil {
+			return err
+		}
+		rels[i] = filepath.ToSlash(rel)
+	}
+
+	commits, err := runGit(ctx, top, append([]string{"log", "--reverse", "--format=%H %cI %an", "--"}, rels...)...)
+	if err != nil {
+		return err
+	}
+	for _, line := range strings.Split(commits, "\n") {
+		commit, rest, ok := strings.Cut(line, " ")
+		if !ok {
+			continue
+		}
+		dateText, author, _ := strings.Cut(rest, " ")
+		date, _ := time.Parse(time.RFC3339, dateText)
+		if items, ok := radarAtCommit(ctx, top, commit, rels); ok {
+			fn(commit, author, date, items)
+		}
+	}
+	return nil
+}
+
+// radarHistory replays every commit of the repository at top that touched
+// one of files, recording when each item was added, changed rings or was
+// removed.
+func radarHistory(ctx context.Context, top string, files []string) ([]ItemHistory, error) {
+	histories := make(map[string]*ItemHistory)
+	previous := make(map[string]RadarItem)
+	err := walkRadarCommits(ctx, top, files, func(commit, _ string, date time.Time, current map[string]RadarItem) {
+		record := func(item RadarItem, event HistoryEvent) {
+			event.Commit, event.Date = shortCommit(commit), date
+			h, ok := histories[item.ID]
+			if !ok {
+				h = &ItemHistory{ID: item.ID}
+				histories[item.ID] = h
+			}
+			h.Label = item.Label
+			h.Events = append(h.Events, event)
+		}
+		for id, item := range current {
+			before, existed := previous[id]
+			switch {
+			case !existed:
+				record(item, HistoryEvent{Event: historyAdded, Ring: item.Ring, Quadrant: item.Quadrant})
+			case before.Ring != item.Ring:
+				record(item, HistoryEvent{Event: historyMoved, Ring: item.Ring, PreviousRing: before.Ring, Quadrant: item.Quadrant})
+			}
+		}
+		for id, item := range previous {
+			if _, ok := current[id]; !ok {
+				record(item, HistoryEvent{Event: historyRemoved, PreviousRing: item.Ring})
+			}
+		}
+		previous = current
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	items := make([]ItemHistory, 0, len(histories))
+	for _, h := range histories {
+		items = append(items, *h)
+	}
+	sort.Slice(items, func(i, j int) bool { return labelKey(items[i].Label) < labelKey(items[j].Label) })
+	return items, nil
+}
+
+// withMergedHistory returns histories with those of the items merged into
+// one of items folded into its own, their events labelled with the label
+// of the merged item and ordered by date.
+func withMergedHistory(histories []ItemHistory, items []RadarItem) []ItemHistory {
+	into := make(map[string]string)
+	for _, item := range items {
+		for _, id := range item.MergedFrom {
+			into[id] = item.ID
+		}
+	}
+	if len(into) == 0 {
+		return histories
+	}
+
+	merged := make(map[string][]HistoryEvent)
+	for _, h := range histories {
+		if id, ok := into[h.ID]; ok {
+			for _, event := range h.Events {
+				event.Label = h.Label
+				merged[id] = append(merged[id], event)
+			}
+		}
+	}
+	result := make([]ItemHistory, 0, len(histories))
+	for _, h := range histories {
+		if _, ok := into[h.ID]; ok {
+			continue
+		}
+		if events := merged[h.ID]; events != nil {
+			h.Events = append(slices.Clone(h.Events), events...)
+			slices.SortStableFunc(h.Events, func(a, b HistoryEvent) int { return a.Date.Compare(b.Date) })
+		}
+		result = append(result, h)
+	}
+	return result
+}
+
+// radarAtCommit returns the items of the data files at commit, keyed by ID.
+// Files that don't exist yet at that commit contribute no items; ok is false
+// if a file can't be decoded.
+func radarAtCommit(ctx context.Context, top, commit string, rels []string) (map[string]RadarItem, bool) {
+	var all RadarData
+	for _, rel := range rels {
+		content, err := gitOutput(ctx, top, "show", commit+":"+rel)
+		if err != nil {
+			continue
+		}
+		if content, err = openData(content); err != nil {
+			return nil, false
+		}
+		data, err := decodeRadarData(rel, content)
+		if err != nil {
+			return nil, false
+		}
+		all.Items = append(all.Items, data.Items...)
+	}
+	all, err := withItemIDs(all, nil)
+	if err != nil {
+		return nil, false
+	}
+	items := make(map[string]RadarItem, len(all.Items))
+	for _, item := range all.Items {
+		items[item.ID] = item
+	}
+	return items, true
+}
+
+// findHistory returns the history of the item of items whose ID, or else
+// label, is key.
+func findHistory(items []ItemHistory, key string) (ItemHistory, bool) {
+	i := slices.IndexFunc(items, func(h ItemHistory) bool { return h.ID == key })
+	if i < 0 {
+		i = slices.IndexFunc(items, func(h ItemHistory) bool { return labelKey(h.Label) == labelKey(key) })
+	}
+	if i < 0 {
+		return ItemHistory{}, false
+	}
+	return items[i], true
+}
+
+// mergedHistory returns the history of every item with that of the items
+// merged into it, or an AppError if it can't be read.
+func mergedHistory(ctx context.Context) ([]ItemHistory, error) {
+	items, err := loadRadarHistory(ctx)
+	if errors.Is(err, errNoHistory) {
+		return nil, &AppError{Code: http.StatusNotFound, Message: "Radar history requires the data files to be in a Git repository"}
+	}
+	if err != nil {
+		return nil, &AppError{Code: http.StatusInternalServerError, Message: "Failed to read radar history", Err: err}
+	}
+	if data, err := loadRadarData(); err == nil {
+		items = withMergedHistory(items, data.Items)
+	}
+	return items, nil
+}
+
+// historyHandler serves the Git history of every item, or of the item whose
+// ID or label is the item path value.
+func historyHandler(w http.ResponseWriter, r *http.Request) {
+	items, err := mergedHistory(r.Context())
+	if err != nil {
+		handleError(w, err)
+		return
+	}
+
+	var body any = map[string][]ItemHistory{"items": items}
+	if key := r.PathValue("item"); key != "" {
+		history, ok := findHistory(items, key)
+		if !ok {
+			handleError(w, &AppError{Code: http.StatusNotFound, Message: "Unknown item"})
+			return
+		}
+		body = history
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(body); err != nil {
+		handleError(w, &AppError{Code: http.StatusInternalServerError, Message: "Failed to encode response", Err: err})
+	}
+}
@@ -0,0 +1,107 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// historyEvents summarizes the events of an item as "event:ring" strings,
+// using the previous ring for removals.
+func historyEvents(h ItemHistory) []string {
+	var out []string
+	for _, e := range h.Events {
+		ring := e.Ring
+		if e.Event == historyRemoved {
+			ring = e.PreviousRing
+		}
+		out = append(out, e.Event+":"+ring)
+	}
+	return out
+}
+
+func TestLoadRadarHistory(t *testing.T) {
+	repo, commit := gitRadarRepo(t)
+	commit(radarWith("Go", "In Discovery"))
+	commit(radarWith("Go", "Adopted") + "- Label: Rust\n  Quadrant: Tools\n  Ring: In Discovery\n")
+	commit("Items: [\n") // unparseable commits are skipped
+	commit(radarWith("Rust", "In Discovery"))
+
+	cfg := defaultConfig()
+	cfg.Data.Path = filepath.Join(repo, "data", "radar.yaml")
+	activeConfig.Store(&cfg)
+	t.Cleanup(func() { activeConfig.Store(nil) })
+
+	items, err := loadRadarHistory(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := make(map[string][]string)
+	for _, h := range items {
+		got[h.Label] = historyEvents(h)
+	}
+	want := map[string][]string{
+		"Go":   {"added:In Discovery", "moved:Adopted", "removed:Adopted"},
+		"Rust": {"added:In Discovery"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("history = %v, want %v", got, want)
+	}
+	if moved := items[0].Events[1]; moved.PreviousRing != "In Discovery" || len(moved.Commit) != 7 || moved.Date.IsZero() {
+		t.Errorf("moved event = %+v", moved)
+	}
+
+	handler, err := setupRoutes(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rec := doRequest(t, handler, http.MethodGet, "/api/history/rust")
+	var item ItemHistory
+	if err := json.Unmarshal(rec.Body.Bytes(), &item); err != nil || item.Label != "Rust" {
+		t.Errorf("GET /api/history/rust = %d %q", rec.Code, rec.Body.String())
+	}
+}
+
+func TestLoadRadarHistoryOutsideGit(t *testing.T) {
+	cfg := defaultConfig()
+	cfg.Data.Path = writeFile(t, "radar.yaml", radarWith("Go", "Adopted"))
+	activeConfig.Store(&cfg)
+	t.Cleanup(func() { activeConfig.Store(nil) })
+
+	if _, err := loadRadarHistory(context.Background()); !errors.Is(err, errNoHistory) {
+		t.Errorf("loadRadarHistory() error = %v, want errNoHistory", err)
+	}
+	if rec := doRequest(t, http.HandlerFunc(historyHandler), http.MethodGet, "/api/history"); rec.Code != http.StatusNotFound {
+		t.Errorf("status = %d, want 404", rec.Code)
+	}
+}
+
+func TestRadarHistoryFollowsIDs(t *testing.T) {
+	repo, commit := gitRadarRepo(t)
+	commit("Items:\n- ID: go\n  Label: Go\n  Quadrant: Tools\n  Ring: In Discovery\n")
+	commit("Items:\n- ID: go\n  Label: Golang\n  Quadrant: Tools\n  Ring: Adopted\n")
+
+	cfg := defaultConfig()
+	cfg.Data.Path = filepath.Join(repo, "data", "radar.yaml")
+	activeConfig.Store(&cfg)
+	t.Cleanup(func() { activeConfig.Store(nil) })
+
+	handler, err := setupRoutes(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, key := range []string{"go", "golang"} {
+		rec := doRequest(t, handler, http.MethodGet, "/api/history/"+key)
+		var item ItemHistory
+		if err := json.Unmarshal(rec.Body.Bytes(), &item); err != nil {
+			t.Fatalf("GET /api/history/%s = %d %q", key, rec.Code, rec.Body.String())
+		}
+		if want := []string{"added:In Discovery", "moved:Adopted"}; item.ID != "go" || item.Label != "Golang" || !reflect.DeepEqual(historyEvents(item), want) {
+			t.Errorf("GET /api/history/%s = %+v, want the renamed item with events %v", key, item, want)
+		}
+	}
+}
@@ -0,0 +1,75 @@
+package main
+
+import (
+	"encoding/json"
+	"net/url"
+)
+
+// HALLink is a link of a _links object, in the format of HAL.
+type HALLink struct {
+	Href string `json:"href"`
+}
+
+// ItemLinks are the _links of an item in API responses: the item itself,
+// the items in its quadrant and ring, its history and the radar page
+// showing it.
+type ItemLinks struct {
+	Self     HALLink `json:"self"`
+	Quadrant HALLink `json:"quadrant"`
+	Ring     HALLink `json:"ring"`
+	History  HALLink `json:"history"`
+	HTML     HALLink `json:"html"`
+}
+
+// apiURL returns the path of an API endpoint, under the base path the app
+// is mounted under.
+func apiURL(path string) string {
+	return currentConfig().Server.BasePath + apiPrefix + path
+}
+
+// itemLinks returns the _links of item.
+func itemLinks(item RadarItem) ItemLinks {
+	id := url.PathEscape(item.ID)
+	return ItemLinks{
+		Self:     HALLink{apiURL("/radar/items/" + id)},
+		Quadrant: HALLink{apiURL("/radar/items?" + url.Values{"quadrant": {item.Quadrant}}.Encode())},
+		Ring:     HALLink{apiURL("/radar/items?" + url.Values{"ring": {item.Ring}}.Encode())},
+		History:  HALLink{apiURL("/history/" + id)},
+		HTML:     HALLink{currentConfig().Server.BasePath + "/#" + id},
+	}
+}
+
+// LinkedItem is a radar item with its _links.
+type LinkedItem struct {
+	RadarItem
+	Links ItemLinks `json:"_links"`
+}
+
+// LinkedItemDetail is a radar item with its history and _links, as served
+// by GET /api/radar/items/{id}.
+type LinkedItemDetail struct {
+	ItemDetail
+	Links ItemLinks `json:"_links"`
+}
+
+// linkedItems returns items with their _links to encode, with only fields
+// besides the links, or all of them if fields is nil.
+func linkedItems(items []RadarItem, fields []string) (any, error) {
+	if fields == nil {
+		linked := make([]LinkedItem, len(items))
+		for i, item := range items {
+			linked[i] = LinkedItem{item, itemLinks(item)}
+		}
+		return linked, nil
+	}
+	records, err := itemRecords(items, fields)
+	if err != nil {
+		return nil, err
+	}
+	for i, record := range records {
+		if record["_links"], err = json.Marshal(itemLinks(items[i])); err != nil {
+			return nil, err
+		}
+	}
+	return records, nil
+}
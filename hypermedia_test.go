@@ -0,0 +1,103 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+)
+
+func TestItemLinks(t *testing.T) {
+	cfg := defaultConfig()
+	cfg.Server.BasePath = "/tech-radar"
+	useConfig(t, cfg)
+	links := itemLinks(RadarItem{ID: "c++", Quadrant: "Programming Languages & Frameworks", Ring: "In Discovery"})
+	want := ItemLinks{
+		Self:     HALLink{"/tech-radar/api/v1/radar/items/c++"},
+		Quadrant: HALLink{"/tech-radar/api/v1/radar/items?quadrant=Programming+Languages+%26+Frameworks"},
+		Ring:     HALLink{"/tech-radar/api/v1/radar/items?ring=In+Discovery"},
+		History:  HALLink{"/tech-radar/api/v1/history/c++"},
+		HTML:     HALLink{"/tech-radar/#c++"},
+	}
+	if links != want {
+		t.Errorf("itemLinks = %+v, want %+v", links, want)
+	}
+}
+
+func TestHypermediaLinks(t *testing.T) {
+	cfg := defaultConfig()
+	cfg.Server.BasePath = "/tech-radar"
+	cfg.Data.Path = writeFile(t, "radar.yaml", `Items:
+- Label: Go
+  Quadrant: Tools
+  Ring: Adopted
+- Label: Rust
+  Quadrant: Tools
+  Ring: In Discovery
+  Description: Like Go, safer.
+- Label: Perl
+  Quadrant: Tools
+  Ring: Not Recommended
+`)
+	useConfig(t, cfg)
+	handler, err := setupRoutes(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Links followed as given lead to what they describe, under the base
+	// path.
+	follow := func(href string, body any) {
+		t.Helper()
+		rec := doRequest(t, handler, http.MethodGet, href)
+		if err := json.Unmarshal(rec.Body.Bytes(), body); rec.Code != http.StatusOK || err != nil {
+			t.Fatalf("GET %s = %d %q", href, rec.Code, rec.Body.String())
+		}
+	}
+	var page struct {
+		Items []LinkedItem
+		Links map[string]HALLink `json:"_links"`
+	}
+	follow("/tech-radar/api/v1/radar/items?limit=1&offset=1", &page)
+	if len(page.Items) != 1 || page.Items[0].Label != "Rust" {
+		t.Fatalf("second page = %+v, want Rust", page.Items)
+	}
+	for rel, href := range map[string]string{
+		"self":  "/tech-radar/api/v1/radar/items?limit=1&offset=1",
+		"first": "/tech-radar/api/v1/radar/items?limit=1&offset=0",
+		"last":  "/tech-radar/api/v1/radar/items?limit=1&offset=2",
+		"prev":  "/tech-radar/api/v1/radar/items?limit=1&offset=0",
+		"next":  "/tech-radar/api/v1/radar/items?limit=1&offset=2",
+	} {
+		if page.Links[rel].Href != href {
+			t.Errorf("page %s link = %q, want %q", rel, page.Links[rel].Href, href)
+		}
+	}
+
+	var item LinkedItemDetail
+	follow(page.Items[0].Links.Self.Href, &item)
+	if item.Label != "Rust" || item.Links != page.Items[0].Links {
+		t.Errorf("%s = %s with %+v", page.Items[0].Links.Self.Href, item.Label, item.Links)
+	}
+	var ring struct{ Items []RadarItem }
+	follow(item.Links.Ring.Href, &ring)
+	if len(ring.Items) != 1 || ring.Items[0].Label != "Rust" {
+		t.Errorf("%s = %+v, want Rust", item.Links.Ring.Href, ring.Items)
+	}
+	var quadrant struct{ Total int }
+	follow(item.Links.Quadrant.Href, &quadrant)
+	if quadrant.Total != 3 {
+		t.Errorf("%s total = %d, want 3", item.Links.Quadrant.Href, quadrant.Total)
+	}
+
+	var search struct {
+		Results []SearchResult
+		Links   map[string]HALLink `json:"_links"`
+	}
+	follow("/tech-radar/api/v1/search?q=go", &search)
+	if len(search.Results) != 2 || search.Results[1].Links == nil || search.Results[1].Links.Self.Href != "/tech-radar/api/v1/radar/items/rust" {
+		t.Errorf("search results = %+v, want Go and Rust with their links", search.Results)
+	}
+	if search.Links["self"].Href != "/tech-radar/api/v1/search?q=go" {
+		t.Errorf("search self link = %q", search.Links["self"].Href)
+	}
+}
@@ -0,0 +1,152 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// itemIDPattern is the form of item IDs: lowercase slugs, which also covers
+// UUIDs.
+var itemIDPattern = regexp.MustCompile(`^[a-z0-9]+(-[a-z0-9]+)*$`)
+
+// itemSlug returns the ID derived from a label: its lowercase letters and
+// digits, with every other run of characters replaced by a dash.
+func itemSlug(label string) string {
+	var b strings.Builder
+	dash := false
+	for _, r := range strings.ToLower(label) {
+		if r >= 'a' && r <= 'z' || r >= '0' && r <= '9' {
+			if dash && b.Len() > 0 {
+				b.WriteByte('-')
+			}
+			b.WriteRune(r)
+			dash = false
+		} else {
+			dash = true
+		}
+	}
+	if b.Len() == 0 {
+		return "item"
+	}
+	return b.String()
+}
+
+// withItemIDs returns a copy of data in which every item has an ID. Items
+// without one keep the ID of the item with the same label in previous, or
+// get the slug of their label, suffixed with a number if it is taken. Items
+// sharing an ID are reported as ValidationErrors.
+func withItemIDs(data RadarData, previous []RadarItem) (RadarData, error) {
+	data.Items = slices.Clone(data.Items)
+	owners := make(map[string]string, len(data.Items))
+	var errs ValidationErrors
+	for _, item := range data.Items {
+		if item.ID == "" {
+			continue
+		}
+		if first, ok := owners[item.ID]; ok {
+			errs = append(errs, ValidationError{
+				File:    item.Source,
+				Field:   fmt.Sprintf("item %q", item.Label),
+				Rule:    ruleUnique,
+				Message: fmt.Sprintf("duplicate ID %q, also used by item %q", item.ID, first),
+			})
+			continue
+		}
+		owners[item.ID] = item.Label
+	}
+	if len(errs) > 0 {
+		return RadarData{}, errs
+	}
+
+	previousIDs := make(map[string]string, len(previous))
+	for _, item := range previous {
+		if item.ID != "" {
+			previousIDs[labelKey(item.Label)] = item.ID
+		}
+	}
+	for i := range data.Items {
+		item := &data.Items[i]
+		if item.ID != "" {
+			continue
+		}
+		if id, ok := previousIDs[labelKey(item.Label)]; ok && owners[id] == "" {
+			item.ID = id
+		} else {
+			item.ID = itemSlug(item.Label)
+			for n := 2; owners[item.ID] != ""; n++ {
+				item.ID = fmt.Sprintf("%s-%d", itemSlug(item.Label), n)
+			}
+		}
+		owners[item.ID] = item.Label
+	}
+	return data, nil
+}
+
+// findItem returns the item with the given ID.
+func findItem(items []RadarItem, id string) (RadarItem, bool) {
+	for _, item := range items {
+		if item.ID == id {
+			return item, true
+		}
+	}
+	return RadarItem{}, false
+}
+
+// runAssignIDs implements the assign-ids command, which writes the IDs of
+// items that don't have one yet into their local data files, so they stay
+// the same when the items are renamed.
+func runAssignIDs(args []string) error {
+	cfg, err := loadConfig(args)
+	if err != nil {
+		return err
+	}
+	if err := useEncryptionKey(cfg); err != nil {
+		return err
+	}
+	path := cfg.Data.dataPath()
+	if isRemoteDataPath(path) || cfg.Data.Git.URL != "" {
+		return errors.New("assign-ids requires local data files, not a URL or Git checkout")
+	}
+	merged, err := readRadarData(path)
+	if err != nil {
+		return err
+	}
+	ids := make(map[string]string, len(merged.Items))
+	for _, item := range merged.Items {
+		ids[item.Source+"\n"+labelKey(item.Label)] = item.ID
+	}
+
+	files, err := dataFiles(path)
+	if err != nil {
+		return err
+	}
+	for _, file := range files {
+		content, err := readDataFile(file)
+		if err != nil {
+			return err
+		}
+		data, err := decodeRadarData(file, content)
+		if err != nil {
+			return err
+		}
+		n := 0
+		for i, item := range data.Items {
+			if item.ID == "" {
+				data.Items[i].ID = ids[file+"\n"+labelKey(item.Label)]
+				n++
+			}
+		}
+		if n == 0 {
+			continue
+		}
+		if err := writeRadarData(file, data); err != nil {
+			return err
+		}
+		log.Printf("Assigned IDs to %d items in %s", n, file)
+	}
+	return nil
+}
@@ -0,0 +1,138 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestItemSlug(t *testing.T) {
+	tests := map[string]string{
+		"Go":                                 "go",
+		"Programming Languages & Frameworks": "programming-languages-frameworks",
+		"  Node.js 20 ":                      "node-js-20",
+		"C++":                                "c",
+		"???":                                "item",
+	}
+	for label, want := range tests {
+		if got := itemSlug(label); got != want {
+			t.Errorf("itemSlug(%q) = %q, want %q", label, got, want)
+		}
+	}
+}
+
+func TestWithItemIDs(t *testing.T) {
+	data := RadarData{Items: []RadarItem{
+		{Label: "C++"},
+		{Label: "C"},
+		{ID: "c", Label: "C#"},
+		{Label: "Golang"},
+	}}
+	previous := []RadarItem{{ID: "go", Label: "golang"}}
+
+	got, err := withItemIDs(data, previous)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var ids []string
+	for _, item := range got.Items {
+		ids = append(ids, item.ID)
+	}
+	if want := []string{"c-2", "c-3", "c", "go"}; !reflect.DeepEqual(ids, want) {
+		t.Errorf("IDs = %q, want %q", ids, want)
+	}
+	if data.Items[0].ID != "" {
+		t.Error("withItemIDs() changed the items it was given")
+	}
+
+	data.Items[1].ID = "c"
+	data.Items[2].Source = "b.yaml"
+	_, err = withItemIDs(data, nil)
+	var errs ValidationErrors
+	if !errors.As(err, &errs) || err.Error() != `b.yaml: item "C#": duplicate ID "c", also used by item "C"` {
+		t.Errorf("withItemIDs() error = %v, want a duplicate ID", err)
+	}
+}
+
+func TestValidateItemIDs(t *testing.T) {
+	dir := writeDataDir(t, map[string]string{
+		"a.yaml": "Items:\n- ID: go\n  Label: Go\n  Quadrant: Tools\n  Ring: Adopted\n- ID: Rust_Lang\n  Label: Rust\n  Quadrant: Tools\n  Ring: Adopted\n",
+		"b.yaml": "Items:\n- ID: go\n  Label: Golang\n  Quadrant: Tools\n  Ring: Adopted\n",
+	})
+	err := validateRadarData(dir)
+	for _, want := range []string{
+		`a.yaml:6: item "Rust".ID: must be lowercase letters and digits separated by single dashes`,
+		`b.yaml:2: item "Golang".ID: duplicate ID, first used in ` + filepath.Join(dir, "a.yaml") + ":2",
+	} {
+		if err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("validateRadarData() = %v, want %s", err, want)
+		}
+	}
+}
+
+func TestItemHandler(t *testing.T) {
+	cfg := defaultConfig()
+	cfg.Data.Path = writeFile(t, "radar.yaml", "Items:\n- ID: golang\n  Label: Go\n  Quadrant: Tools\n  Ring: Adopted\n- Label: Rust\n  Quadrant: Tools\n  Ring: Adopted\n")
+	useConfig(t, cfg)
+	handler, err := setupRoutes(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for id, label := range map[string]string{"golang": "Go", "rust": "Rust"} {
+		rec := doRequest(t, handler, http.MethodGet, "/api/radar/items/"+id)
+		var item RadarItem
+		if err := json.Unmarshal(rec.Body.Bytes(), &item); err != nil || item.Label != label || item.ID != id {
+			t.Errorf("GET /api/radar/items/%s = %d %q", id, rec.Code, rec.Body.String())
+		}
+	}
+	if rec := doRequest(t, handler, http.MethodGet, "/api/radar/items/go"); rec.Code != http.StatusNotFound {
+		t.Errorf("GET of an unknown ID = %d, want 404", rec.Code)
+	}
+}
+
+func TestFileStoreSaveKeepsIDs(t *testing.T) {
+	path := writeFile(t, "radar.yaml", "Items:\n- ID: golang\n  Label: Go\n  Quadrant: Tools\n  Ring: Adopted\n")
+	store := newFileStore(path)
+	defer store.Close()
+
+	// An import that doesn't carry IDs keeps those of labels it still has.
+	data := RadarData{Items: []RadarItem{{Label: "go", Quadrant: "Tools", Ring: "Adopted"}, {Label: "Rust", Quadrant: "Tools", Ring: "Adopted"}}}
+	if err := store.Save(context.Background(), data); err != nil {
+		t.Fatal(err)
+	}
+	got, err := readRadarData(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	content, _ := os.ReadFile(path)
+	if got.Items[0].ID != "golang" || got.Items[1].ID != "rust" || !strings.Contains(string(content), "ID: rust") {
+		t.Errorf("saved items = %+v, file\n%s", got.Items, content)
+	}
+}
+
+func TestRunAssignIDs(t *testing.T) {
+	clearRadarEnv(t)
+	dir := writeDataDir(t, map[string]string{
+		"a.yaml": "# Languages\nItems:\n- Label: Go\n  Quadrant: Tools\n  Ring: Adopted\n",
+		"b.json": `{"Items": [{"ID": "rust", "Label": "Rust", "Quadrant": "Tools", "Ring": "Adopted"}, {"Label": "Go!", "Quadrant": "Tools", "Ring": "Adopted"}]}`,
+	})
+	if err := runAssignIDs([]string{"-data", dir}); err != nil {
+		t.Fatal(err)
+	}
+
+	content, err := os.ReadFile(filepath.Join(dir, "a.yaml"))
+	if err != nil || !strings.Contains(string(content), "# Languages") || !strings.Contains(string(content), "ID: go\n") {
+		t.Errorf("a.yaml =\n%s", content)
+	}
+	data, err := readRadarData(filepath.Join(dir, "b.json"))
+	if err != nil || len(data.Items) != 2 || data.Items[0].ID != "rust" || data.Items[1].ID != "go-2" {
+		t.Errorf("b.json items = %+v, %v", data.Items, err)
+	}
+}
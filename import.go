@@ -0,0 +1,302 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"mime"
+	"net/http"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// maxImportSize limits the size of an uploaded import.
+const maxImportSize = 10 << 20
+
+// Actions of an import on the rows of its report.
+const (
+	importCreated = "created"
+	importUpdated = "updated"
+	importSkipped = "skipped"
+	importRemoved = "removed"
+	importFailed  = "error"
+)
+
+// ImportRow reports what an import does with one of its items, or with an
+// item of the radar it leaves out, which has no row.
+type ImportRow struct {
+	// Row is the position of the item in the import, from 1, and Line the
+	// line it starts on when known.
+	Row        int               `json:"row,omitempty"`
+	Line       int               `json:"line,omitempty"`
+	ID         string            `json:"id,omitempty"`
+	Label      string            `json:"label"`
+	Action     string            `json:"action"`
+	Violations []ValidationError `json:"violations,omitempty"`
+}
+
+// ImportReport is the response of POST /import: the number of items
+// imported, how many of them are created, updated and skipped as unchanged,
+// how many items of the radar are removed and how many rows are invalid,
+// with a row for each. Violations lists every problem found, those of rows
+// too, so a rejected import is reported like other invalid data.
+type ImportReport struct {
+	Error      string            `json:"error,omitempty"`
+	DryRun     bool              `json:"dryRun"`
+	Imported   int               `json:"imported"`
+	Created    int               `json:"created"`
+	Updated    int               `json:"updated"`
+	Skipped    int               `json:"skipped"`
+	Removed    int               `json:"removed"`
+	Errors     int               `json:"errors"`
+	Rows       []ImportRow       `json:"rows"`
+	Violations []ValidationError `json:"violations,omitempty"`
+}
+
+// importFileName returns the name of an upload sent as the request body with
+// the media type mediaType, whose extension selects the format it is read
+// in, see parseImport.
+func importFileName(mediaType string) string {
+	switch mediaType {
+	case "application/json":
+		return "upload.json"
+	case "application/yaml", "application/x-yaml", "text/yaml":
+		return "upload.yaml"
+	case "application/toml":
+		return "upload.toml"
+	}
+	return "upload.csv"
+}
+
+// importFormat returns the format of an upload named file: that of a data
+// file if its extension is one, and otherwise formatCSV for a Build Your
+// Own Radar CSV file.
+func importFormat(file string) string {
+	switch strings.ToLower(filepath.Ext(file)) {
+	case ".json", ".yaml", ".yml", ".toml":
+		return dataFormat(file)
+	}
+	return formatCSV
+}
+
+// parseImport parses an upload named file in its importFormat. It returns
+// the line every item starts on, or 0 when unknown, and with
+// ValidationErrors the items found, if any.
+func parseImport(file string, content []byte) (RadarData, []int, error) {
+	if importFormat(file) == formatCSV {
+		items, lines, err := parseBYORRows(file, bytes.NewReader(content))
+		return RadarData{LastModified: time.Now().Format("January 2006"), Items: items}, lines, err
+	}
+
+	data, err := decodeRadarData(file, content)
+	if err != nil {
+		return RadarData{}, nil, err
+	}
+	if data.LastModified == "" {
+		data.LastModified = time.Now().Format("January 2006")
+	}
+	lines := make([]int, len(data.Items))
+	if doc, err := parseRadarNode(file, content); err == nil && len(doc.Content) > 0 {
+		if items := mappingValue(doc.Content[0], "Items"); items != nil && items.Kind == yaml.SequenceNode && len(items.Content) == len(lines) {
+			for i, item := range items.Content {
+				lines[i] = item.Line
+			}
+		}
+	}
+	return data, lines, nil
+}
+
+// violationRow returns the index of the row of items, starting on lines, the
+// problem e was found in, or -1 if it isn't in one. Problems are located by
+// their line, or by the label or index of their item.
+func violationRow(e ValidationError, items []RadarItem, lines []int) int {
+	if e.Line > 0 {
+		row := -1
+		for i, line := range lines {
+			if line > 0 && line <= e.Line {
+				row = i
+			}
+		}
+		return row
+	}
+	if rest, ok := strings.CutPrefix(e.Field, "Items["); ok {
+		if end := strings.Index(rest, "]"); end > 0 {
+			if i, err := strconv.Atoi(rest[:end]); err == nil && i < len(items) {
+				return i
+			}
+		}
+	}
+	if rest, ok := strings.CutPrefix(e.Field, "item "); ok {
+		if quoted, err := strconv.QuotedPrefix(rest); err == nil {
+			label, _ := strconv.Unquote(quoted)
+			// Duplicates are reported on the later item.
+			for i := len(items) - 1; i >= 0; i-- {
+				if items[i].Label == label {
+					return i
+				}
+			}
+		}
+	}
+	return -1
+}
+
+// importReport returns the report of importing items, starting on lines,
+// over the current items: saved are the items as they would be saved, with
+// their IDs, and errs the problems that keep them from being saved.
+func importReport(items []RadarItem, lines []int, saved []RadarItem, current []RadarItem, errs ValidationErrors) ImportReport {
+	report := ImportReport{Imported: len(items), Rows: []ImportRow{}, Violations: errs}
+	for i, item := range items {
+		report.Rows = append(report.Rows, ImportRow{Row: i + 1, Line: lines[i], ID: item.ID, Label: item.Label})
+	}
+	for _, e := range errs {
+		if i := violationRow(e, items, lines); i >= 0 {
+			report.Rows[i].Violations = append(report.Rows[i].Violations, e)
+		}
+	}
+
+	if len(errs) > 0 {
+		for i := range report.Rows {
+			if report.Rows[i].Violations != nil {
+				report.Rows[i].Action = importFailed
+				report.Errors++
+			}
+		}
+		return report
+	}
+	kept := make(map[string]bool, len(saved))
+	for i, item := range saved {
+		row := &report.Rows[i]
+		row.ID = item.ID
+		kept[item.ID] = true
+		previous, ok := findItem(current, item.ID)
+		switch {
+		case !ok:
+			row.Action = importCreated
+			report.Created++
+		case sameItem(item, previous):
+			row.Action = importSkipped
+			report.Skipped++
+		default:
+			row.Action = importUpdated
+			report.Updated++
+		}
+	}
+	for _, item := range current {
+		if !kept[item.ID] {
+			report.Rows = append(report.Rows, ImportRow{ID: item.ID, Label: item.Label, Action: importRemoved})
+			report.Removed++
+		}
+	}
+	return report
+}
+
+// importHandler replaces the radar data with the items of an upload, sent
+// either as the request body or as the "file" field of a multipart form: a
+// Build Your Own Radar CSV file, or a YAML, JSON or TOML data file, as
+// selected by its media type or file name. It responds with an
+// ImportReport. With ?dryRun=true, the upload is only checked and reported
+// on. Otherwise it is saved in one step, or not at all if any row is
+// invalid. The active Store must be writable: a database, or a data path
+// naming a single local file.
+func importHandler(w http.ResponseWriter, r *http.Request) {
+	dryRun := false
+	if value := r.URL.Query().Get("dryRun"); value != "" {
+		var err error
+		if dryRun, err = strconv.ParseBool(value); err != nil {
+			handleError(w, &AppError{Code: http.StatusBadRequest, Message: fmt.Sprintf("Invalid dryRun %q, must be true or false", value)})
+			return
+		}
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, maxImportSize)
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	body, name := io.Reader(r.Body), importFileName(mediaType)
+	if mediaType == "multipart/form-data" {
+		f, header, err := r.FormFile("file")
+		if err != nil {
+			handleError(w, importReadError(name, err))
+			return
+		}
+		defer f.Close()
+		body, name = f, header.Filename
+	}
+	content, err := io.ReadAll(body)
+	if err != nil {
+		handleError(w, importReadError(name, err))
+		return
+	}
+	var errs ValidationErrors
+	imported, lines, err := parseImport(name, content)
+	if err != nil && !errors.As(err, &errs) {
+		handleError(w, importReadError(name, err))
+		return
+	}
+
+	editMu.Lock()
+	defer editMu.Unlock()
+	store := currentStore()
+	if store == nil {
+		handleError(w, &AppError{Code: http.StatusServiceUnavailable, Message: "Radar data not available yet"})
+		return
+	}
+	// Like a file store, an import may replace data that can't be loaded,
+	// which then has no items to report on.
+	current, _ := loadStoreData(store)
+	ctx := withRequestAudit(r, AuditEvent{Action: "import", Detail: name})
+	var saved RadarData
+	if errs == nil {
+		saved, err = prepareSave(ctx, imported, current)
+		if err != nil && !errors.As(err, &errs) {
+			handleError(w, &AppError{Code: http.StatusInternalServerError, Message: "Failed to check radar data", Err: err})
+			return
+		}
+	}
+	report := importReport(imported.Items, lines, saved.Items, current.Items, errs)
+	report.DryRun = dryRun
+
+	code := http.StatusOK
+	switch {
+	case errs != nil && !dryRun:
+		report.Error = "Invalid radar data"
+		code = http.StatusBadRequest
+	case !dryRun:
+		err = store.Save(ctx, imported)
+		if errors.Is(err, errReadOnly) {
+			handleError(w, &AppError{Code: http.StatusConflict, Message: "Import requires a store or data.path naming a single local data file", Err: err})
+			return
+		}
+		if errors.As(err, &errs) {
+			handleError(w, invalidDataError("Invalid radar data", errs))
+			return
+		}
+		if err != nil {
+			handleError(w, &AppError{Code: http.StatusInternalServerError, Message: "Failed to write radar data", Err: err})
+			return
+		}
+		invalidateRadarCache(r.Context())
+		log.Printf("Imported %d items from %s: %d created, %d updated, %d removed", report.Imported, name, report.Created, report.Updated, report.Removed)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	if err := json.NewEncoder(w).Encode(report); err != nil {
+		log.Printf("Failed to encode import report: %v", err)
+	}
+}
+
+// importReadError converts an error reading the upload named file into an
+// AppError.
+func importReadError(file string, err error) *AppError {
+	var maxErr *http.MaxBytesError
+	if errors.As(err, &maxErr) {
+		return &AppError{Code: http.StatusRequestEntityTooLarge, Message: "Upload too large", Err: err}
+	}
+	return &AppError{Code: http.StatusBadRequest, Message: fmt.Sprintf("Invalid %s: %v", strings.ToUpper(importFormat(file)), err), Err: err}
+}
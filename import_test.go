@@ -0,0 +1,199 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestImportEndpoint(t *testing.T) {
+	cfg := defaultConfig()
+	cfg.Admin.Token = "s3cret"
+	cfg.Data.Path = writeFile(t, "radar.json", `{"Items": []}`)
+	useConfig(t, cfg)
+	handler, err := setupRoutes(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	post := func(token, contentType string, body []byte) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/api/import", bytes.NewReader(body))
+		req.Header.Set("Authorization", "Bearer "+token)
+		req.Header.Set("Content-Type", contentType)
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		return rec
+	}
+
+	csv := []byte("name,ring,quadrant\nGo,adopt,tools\nRust,assess,languages & frameworks\n")
+	if rec := post("wrong", "text/csv", csv); rec.Code != http.StatusUnauthorized {
+		t.Errorf("wrong token: status = %d, want 401", rec.Code)
+	}
+	if rec := post("s3cret", "text/csv", []byte("name,ring,quadrant\nGo,adopt,gadgets\n")); rec.Code != http.StatusBadRequest {
+		t.Errorf("invalid CSV: status = %d, want 400", rec.Code)
+	}
+
+	var form bytes.Buffer
+	mw := multipart.NewWriter(&form)
+	part, _ := mw.CreateFormFile("file", "radar.csv")
+	part.Write(csv)
+	mw.Close()
+	rec := post("s3cret", mw.FormDataContentType(), form.Bytes())
+	var report ImportReport
+	if err := json.Unmarshal(rec.Body.Bytes(), &report); err != nil || rec.Code != http.StatusOK || report.Imported != 2 || report.Created != 2 {
+		t.Fatalf("upload: status = %d, body = %q", rec.Code, rec.Body.String())
+	}
+
+	data, err := loadRadarData()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(data.Items) != 2 || data.Items[1].Quadrant != "Programming Languages & Frameworks" {
+		t.Errorf("imported data = %+v", data)
+	}
+	if err := validateRadarData(cfg.Data.Path); err != nil {
+		t.Errorf("imported data is invalid: %v", err)
+	}
+}
+
+func TestImportEndpointRequiresSingleFile(t *testing.T) {
+	cfg := defaultConfig()
+	cfg.Admin.Token = "s3cret"
+	cfg.Data.Path = filepath.Dir(writeFile(t, "radar.yaml", "Items: []\n"))
+	useConfig(t, cfg)
+
+	req := httptest.NewRequest(http.MethodPost, "/api/import", strings.NewReader("name,ring,quadrant\n"))
+	rec := httptest.NewRecorder()
+	importHandler(rec, req)
+	if rec.Code != http.StatusConflict {
+		t.Errorf("status = %d, want 409", rec.Code)
+	}
+}
+
+func TestImportEndpointDisabledWithoutToken(t *testing.T) {
+	cfg := defaultConfig()
+	cfg.Features.UI = false
+	handler, err := setupRoutes(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rec := doRequest(t, handler, http.MethodPost, "/api/import"); rec.Code != http.StatusNotFound {
+		t.Errorf("status = %d, want 404", rec.Code)
+	}
+}
+
+func TestImportReport(t *testing.T) {
+	const radar = `Items:
+- Label: Go
+  Quadrant: Tools
+  Ring: Adopted
+  Description: A language.
+- Label: Kotlin
+  Quadrant: Tools
+  Ring: In Discovery
+- Label: Perl
+  Quadrant: Tools
+  Ring: Not Recommended
+`
+	cfg := defaultConfig()
+	cfg.Admin.Token = "s3cret"
+	cfg.Data.Path = writeFile(t, "radar.yaml", radar)
+	useConfig(t, cfg)
+	handler, err := setupRoutes(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	post := func(target, contentType, body string) (int, ImportReport) {
+		t.Helper()
+		rec := adminRequest(t, handler, http.MethodPost, target, http.Header{"Content-Type": {contentType}}, body)
+		var report ImportReport
+		if strings.HasPrefix(rec.Header().Get("Content-Type"), "application/json") {
+			if err := json.Unmarshal(rec.Body.Bytes(), &report); err != nil {
+				t.Fatal(err)
+			}
+		}
+		return rec.Code, report
+	}
+	unchanged := func() {
+		t.Helper()
+		if content, _ := os.ReadFile(cfg.Data.Path); string(content) != radar {
+			t.Fatalf("data file changed:\n%s", content)
+		}
+	}
+	actions := func(report ImportReport) string {
+		var actions []string
+		for _, row := range report.Rows {
+			actions = append(actions, row.ID+"="+row.Action)
+		}
+		return strings.Join(actions, " ")
+	}
+
+	update := `{"Items": [
+  {"Label": "Go", "Quadrant": "Tools", "Ring": "Adopted", "Description": "A language."},
+  {"Label": "Kotlin", "Quadrant": "Tools", "Ring": "Adopted"},
+  {"Label": "Rust", "Quadrant": "Tools", "Ring": "In Discovery"}
+]}`
+	code, report := post("/api/v1/import?dryRun=true", "application/json", update)
+	if code != http.StatusOK || !report.DryRun || report.Imported != 3 || report.Created != 1 || report.Updated != 1 || report.Skipped != 1 || report.Removed != 1 || report.Errors != 0 {
+		t.Errorf("dry run = %d %+v", code, report)
+	}
+	if got, want := actions(report), "go=skipped kotlin=updated rust=created perl=removed"; got != want {
+		t.Errorf("dry run rows = %s, want %s", got, want)
+	}
+	if report.Rows[1].Row != 2 || report.Rows[1].Line != 3 || report.Rows[3].Row != 0 {
+		t.Errorf("dry run rows = %+v", report.Rows)
+	}
+	unchanged()
+
+	invalid := "Items:\n- Label: Go\n  Quadrant: Tools\n  Ring: Adopted\n- Label: Rust\n  Quadrant: Tools\n  Ring: Hold\n"
+	code, report = post("/api/v1/import?dryRun=true", "application/yaml", invalid)
+	if code != http.StatusOK || report.Errors != 1 || len(report.Violations) != 1 || report.Rows[1].Action != "error" || len(report.Rows[1].Violations) != 1 || report.Rows[0].Action != "" {
+		t.Errorf("dry run of invalid data = %d %+v", code, report)
+	}
+	code, report = post("/api/v1/import", "application/yaml", invalid)
+	if code != http.StatusBadRequest || report.Error == "" || report.DryRun || len(report.Violations) != 1 {
+		t.Errorf("invalid import = %d %+v", code, report)
+	}
+	unchanged()
+
+	csv := "name,ring,quadrant\nGo,adopt,tools\nRust,someday,tools\nPerl,hold,tools\n"
+	code, report = post("/api/v1/import?dryRun=true", "text/csv", csv)
+	if code != http.StatusOK || report.Errors != 1 || report.Rows[1].Line != 3 || report.Rows[1].Action != "error" {
+		t.Errorf("dry run of invalid CSV = %d %+v", code, report)
+	}
+	for _, tt := range []struct{ target, contentType, body string }{
+		{"/api/v1/import?dryRun=maybe", "application/json", update},
+		{"/api/v1/import?dryRun=true", "application/json", `{"Items": [`},
+	} {
+		if code, _ := post(tt.target, tt.contentType, tt.body); code != http.StatusBadRequest {
+			t.Errorf("POST %s %s = %d, want 400", tt.target, tt.body, code)
+		}
+	}
+	// Problems outside the rows are only listed as violations.
+	if code, report := post("/api/v1/import?dryRun=true", "text/csv", "label,ring\n"); code != http.StatusOK || len(report.Violations) != 2 || len(report.Rows) != 0 {
+		t.Errorf("dry run of CSV without columns = %d %+v", code, report)
+	}
+	unchanged()
+
+	code, report = post("/api/v1/import", "application/json", update)
+	if code != http.StatusOK || report.DryRun || report.Created != 1 || report.Removed != 1 {
+		t.Fatalf("import = %d %+v", code, report)
+	}
+	data, err := loadRadarData()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var ids []string
+	for _, item := range data.Items {
+		ids = append(ids, item.ID+"="+item.Ring)
+	}
+	if got, want := strings.Join(ids, " "), "go=Adopted kotlin=Adopted rust=In Discovery"; got != want {
+		t.Errorf("imported items = %s, want %s", got, want)
+	}
+}
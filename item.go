@@ -0,0 +1,107 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"slices"
+)
+
+// ItemDetail is a radar item with its history, as served by
+// GET /api/radar/items/{id} with its _links.
+type ItemDetail struct {
+	RadarItem
+	// History lists the changes to the item, oldest first, including those
+	// of the items merged into it. It is left out when the data files
+	// aren't in a Git repository.
+	History []HistoryEvent `json:"history,omitempty"`
+	// Votes counts the votes for the item. It is left out without a
+	// database store, which keeps the votes.
+	Votes *int `json:"votes,omitempty"`
+	// Reactions counts the reactions to the item by kind, and is left out
+	// like Votes.
+	Reactions map[string]int `json:"reactions,omitempty"`
+	// RelatedBy are the relations of other items to the item, each with the
+	// ID of the item it is of, such as the item replacing it.
+	RelatedBy Relations `json:"relatedBy,omitempty"`
+}
+
+// itemDetailFields are the fields GET /api/radar/items/{id}?fields= can
+// ask for.
+var itemDetailFields = append(slices.Clone(itemFields), "history", "votes", "reactions", "relatedBy")
+
+// itemEvents returns the history of the item with id of items, or nil if
+// there is none.
+func itemEvents(r *http.Request, id string, items []RadarItem) []HistoryEvent {
+	histories, err := loadRadarHistory(r.Context())
+	if err != nil {
+		if !errors.Is(err, errNoHistory) {
+			slog.Warn("Failed to read item history", "item", id, "err", err)
+		}
+		return nil
+	}
+	histories = withMergedHistory(histories, items)
+	if i := slices.IndexFunc(histories, func(h ItemHistory) bool { return h.ID == id }); i >= 0 {
+		return histories[i].Events
+	}
+	return nil
+}
+
+// itemDetail returns the details of item, one of items: the relations of
+// the other items to it, and its history, votes and reactions when fields,
+// as parseFields returns them, asks for them or is nil.
+func itemDetail(r *http.Request, item RadarItem, items []RadarItem, fields []string) ItemDetail {
+	detail := ItemDetail{RadarItem: item, RelatedBy: relatedBy(items, item.ID)}
+	if fields == nil || slices.Contains(fields, "history") {
+		detail.History = itemEvents(r, item.ID, items)
+	}
+	if fields == nil || slices.Contains(fields, "votes") {
+		detail.Votes = itemVotes(r, item.ID)
+	}
+	if fields == nil || slices.Contains(fields, "reactions") {
+		detail.Reactions = itemReactions(r, item.ID)
+	}
+	return detail
+}
+
+// itemHandler serves the radar item with the ID given by the path, archived
+// or not, and its history, with the fields parseFields asks for and its
+// _links. Errors are served as JSON.
+func itemHandler(w http.ResponseWriter, r *http.Request) {
+	fields, err := parseFields(r, itemDetailFields)
+	if err != nil {
+		handleJSONError(w, err)
+		return
+	}
+	data, err := loadRadarData()
+	if err != nil {
+		handleJSONError(w, err)
+		return
+	}
+	item, ok := findItem(data.Items, r.PathValue("id"))
+	if !ok {
+		handleJSONError(w, &AppError{Code: http.StatusNotFound, Message: "Unknown item"})
+		return
+	}
+	detail := itemDetail(r, item, data.Items, fields)
+	var body any = LinkedItemDetail{detail, itemLinks(item)}
+	if fields != nil {
+		record, err := sparseItem(detail, fields)
+		if err == nil {
+			record["_links"], err = json.Marshal(itemLinks(item))
+		}
+		if err != nil {
+			handleJSONError(w, &AppError{Code: http.StatusInternalServerError, Message: "Failed to encode response", Err: err})
+			return
+		}
+		body = record
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	// The ETag is that of the item, for writes to send back as If-Match.
+	w.Header().Set("ETag", itemETag(item))
+	if err := json.NewEncoder(w).Encode(body); err != nil {
+		handleJSONError(w, &AppError{Code: http.StatusInternalServerError, Message: "Failed to encode response", Err: err})
+	}
+}
@@ -0,0 +1,66 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestItemDetail(t *testing.T) {
+	repo, commit := gitRadarRepo(t)
+	commit("Items:\n- ID: go\n  Label: Go\n  Quadrant: Tools\n  Ring: In Discovery\n")
+	commit("Items:\n- ID: go\n  Label: Go\n  Quadrant: Tools\n  Ring: Adopted\n  Description: A language.\n  Tags: [backend]\n  Owners: Platform\n  Links: [{URL: \"https://go.dev\"}]\n")
+
+	cfg := defaultConfig()
+	cfg.Data.Path = filepath.Join(repo, "data", "radar.yaml")
+	useConfig(t, cfg)
+	handler, err := setupRoutes(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rec := doRequest(t, handler, http.MethodGet, "/api/radar/items/go")
+	var item ItemDetail
+	if err := json.Unmarshal(rec.Body.Bytes(), &item); err != nil {
+		t.Fatalf("GET /api/radar/items/go = %d %q", rec.Code, rec.Body.String())
+	}
+	if item.Description != "A language." || len(item.Tags) != 1 || len(item.Owners) != 1 || len(item.Links) != 1 {
+		t.Errorf("GET /api/radar/items/go = %+v, want every field of the item", item)
+	}
+	if got, want := historyEvents(ItemHistory{Events: item.History}), []string{"added:In Discovery", "moved:Adopted"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("GET /api/radar/items/go history = %v, want %v", got, want)
+	}
+
+	rec = doRequest(t, handler, http.MethodGet, "/api/radar/items/go?fields=label,history")
+	var sparse map[string]json.RawMessage
+	if err := json.Unmarshal(rec.Body.Bytes(), &sparse); err != nil || len(sparse) != 3 || sparse["history"] == nil || sparse["_links"] == nil {
+		t.Errorf("GET /api/radar/items/go?fields=label,history = %d %q", rec.Code, rec.Body.String())
+	}
+
+	rec = doRequest(t, handler, http.MethodGet, "/api/radar/items/rust")
+	var body errorResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &body); rec.Code != http.StatusNotFound || err != nil || body.Error != "Unknown item" || rec.Header().Get("Content-Type") != "application/json" {
+		t.Errorf("GET /api/radar/items/rust = %d %q, want a JSON 404", rec.Code, rec.Body.String())
+	}
+}
+
+func TestItemDetailOutsideGit(t *testing.T) {
+	cfg := defaultConfig()
+	cfg.Data.Path = writeFile(t, "radar.yaml", radarWith("Go", "Adopted"))
+	useConfig(t, cfg)
+	handler, err := setupRoutes(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rec := doRequest(t, handler, http.MethodGet, "/api/radar/items/go")
+	var item map[string]json.RawMessage
+	if err := json.Unmarshal(rec.Body.Bytes(), &item); err != nil || item["label"] == nil {
+		t.Fatalf("GET /api/radar/items/go = %d %q", rec.Code, rec.Body.String())
+	}
+	if _, ok := item["history"]; ok {
+		t.Errorf("GET /api/radar/items/go = %q, want no history outside Git", rec.Body.String())
+	}
+}
@@ -0,0 +1,261 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"mime"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// maxItemSize limits the size of the body of an item write.
+const maxItemSize = 1 << 20
+
+// editMu serializes item writes, so that one checked against the ETag of an
+// item isn't overtaken by another before it is saved.
+var editMu sync.Mutex
+
+// itemInput is the body of an item write: a radar item, which may be one
+// read from the API with its _links, history and relatedBy, which are
+// ignored.
+type itemInput struct {
+	RadarItem
+	Links     json.RawMessage `json:"_links,omitempty"`
+	History   json.RawMessage `json:"history,omitempty"`
+	RelatedBy json.RawMessage `json:"relatedBy,omitempty"`
+}
+
+// decodeItem decodes the JSON radar item in the body of r.
+func decodeItem(w http.ResponseWriter, r *http.Request) (RadarItem, error) {
+	if mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type")); mediaType != "" && mediaType != "application/json" {
+		return RadarItem{}, &AppError{Code: http.StatusUnsupportedMediaType, Message: fmt.Sprintf("Unsupported content type %q, items are sent as application/json", mediaType)}
+	}
+	body, err := readItemBody(w, r)
+	if err != nil {
+		return RadarItem{}, err
+	}
+	return parseItem(body)
+}
+
+// readItemBody reads the body of an item write, of at most maxItemSize.
+func readItemBody(w http.ResponseWriter, r *http.Request) ([]byte, error) {
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxItemSize))
+	var maxErr *http.MaxBytesError
+	if errors.As(err, &maxErr) {
+		return nil, &AppError{Code: http.StatusRequestEntityTooLarge, Message: "Item too large", Err: err}
+	}
+	if err != nil {
+		return nil, &AppError{Code: http.StatusBadRequest, Message: "Failed to read item", Err: err}
+	}
+	return body, nil
+}
+
+// parseItem decodes the JSON radar item body, rejecting unknown fields so
+// misspelled ones aren't silently dropped.
+func parseItem(body []byte) (RadarItem, error) {
+	dec := json.NewDecoder(bytes.NewReader(body))
+	dec.DisallowUnknownFields()
+	var input itemInput
+	if err := dec.Decode(&input); err != nil {
+		return RadarItem{}, &AppError{Code: http.StatusBadRequest, Message: "Invalid item: " + err.Error(), Err: err}
+	}
+	if _, err := dec.Token(); !errors.Is(err, io.EOF) {
+		return RadarItem{}, &AppError{Code: http.StatusBadRequest, Message: "Invalid item: more than one JSON value"}
+	}
+	return input.RadarItem, nil
+}
+
+// editableData returns the writable Store and its current data, loaded from
+// the store rather than the cache so a save doesn't undo changes the cache
+// hasn't seen yet.
+func editableData() (Store, RadarData, error) {
+	store := currentStore()
+	if store == nil {
+		return nil, RadarData{}, &AppError{Code: http.StatusServiceUnavailable, Message: "Radar data not available yet"}
+	}
+	data, err := loadStoreData(store)
+	if err != nil {
+		return nil, RadarData{}, err
+	}
+	return store, data, nil
+}
+
+// itemETag returns the ETag of the version of item, which changes whenever
+// one of its fields does, however it is represented.
+func itemETag(item RadarItem) string {
+	encoded, err := json.Marshal(item)
+	if err != nil {
+		return ""
+	}
+	return contentETag(encoded)
+}
+
+// checkIfMatch returns an error unless the If-Match header of r, a write to
+// item, lists the current ETag of item or is "*". Writes without it are
+// rejected so that editors can't overwrite changes they haven't seen.
+func checkIfMatch(r *http.Request, item RadarItem) error {
+	header := strings.Join(r.Header.Values("If-Match"), ",")
+	if header == "" {
+		return &AppError{Code: http.StatusPreconditionRequired, Message: "Writes to items require an If-Match header with the ETag of the item"}
+	}
+	etag := itemETag(item)
+	for _, match := range strings.Split(header, ",") {
+		// Weak ETags never match, as If-Match compares them strongly.
+		if match = strings.TrimSpace(match); match == "*" || match == etag {
+			return nil
+		}
+	}
+	return &AppError{Code: http.StatusPreconditionFailed, Message: fmt.Sprintf("Item %s has changed since it was read", item.ID)}
+}
+
+// saveEdit saves data, edited by the request r, to store as the month of
+// now, recording event as the reason, see withRequestAudit. It returns the
+// data as saved.
+func saveEdit(r *http.Request, store Store, data RadarData, event AuditEvent) (RadarData, error) {
+	data.LastModified = time.Now().Format("January 2006")
+	var errs ValidationErrors
+	err := store.Save(withRequestAudit(r, event), data)
+	if errors.Is(err, errReadOnly) {
+		return RadarData{}, &AppError{Code: http.StatusConflict, Message: "Editing items requires a store or data.path naming a single local data file", Err: err}
+	}
+	if errors.As(err, &errs) {
+		return RadarData{}, invalidDataError("Invalid radar data", errs)
+	}
+	if err != nil {
+		return RadarData{}, &AppError{Code: http.StatusInternalServerError, Message: "Failed to write radar data", Err: err}
+	}
+	// The store notifies its watchers in the background; the next request
+	// must not be served from the cache.
+	invalidateRadarCache(r.Context())
+	return loadStoreData(store)
+}
+
+// writeItem responds with item, as saved, and its ETag with status code.
+func writeItem(w http.ResponseWriter, code int, item RadarItem) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("ETag", itemETag(item))
+	w.WriteHeader(code)
+	if err := json.NewEncoder(w).Encode(item); err != nil {
+		log.Printf("Failed to encode item %s: %v", item.ID, err)
+	}
+}
+
+// createItemHandler adds the item in the body to the radar, with the ID it
+// gives or else the slug of its label, and responds with it as saved and
+// its URL as the Location.
+func createItemHandler(w http.ResponseWriter, r *http.Request) {
+	item, err := decodeItem(w, r)
+	if err != nil {
+		handleError(w, err)
+		return
+	}
+	editMu.Lock()
+	defer editMu.Unlock()
+	store, data, err := editableData()
+	if err != nil {
+		handleError(w, err)
+		return
+	}
+	if _, ok := findItem(data.Items, item.ID); ok && item.ID != "" {
+		handleError(w, &AppError{Code: http.StatusConflict, Message: fmt.Sprintf("Item %s already exists", item.ID)})
+		return
+	}
+
+	data.Items = append(slices.Clone(data.Items), item)
+	saved, err := saveEdit(r, store, data, AuditEvent{Action: "create item", Detail: item.Label})
+	if err != nil {
+		handleError(w, err)
+		return
+	}
+	i := slices.IndexFunc(saved.Items, func(s RadarItem) bool { return labelKey(s.Label) == labelKey(item.Label) })
+	if i < 0 {
+		handleError(w, &AppError{Code: http.StatusInternalServerError, Message: "Created item not found"})
+		return
+	}
+	log.Printf("Created item %s", saved.Items[i].ID)
+	w.Header().Set("Location", apiURL("/radar/items/"+url.PathEscape(saved.Items[i].ID)))
+	writeItem(w, http.StatusCreated, saved.Items[i])
+}
+
+// replaceItemHandler replaces the item with the ID given by the path with
+// the item in the body, which keeps that ID, and responds with it as saved.
+func replaceItemHandler(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+	item, err := decodeItem(w, r)
+	if err != nil {
+		handleError(w, err)
+		return
+	}
+	if item.ID != "" && item.ID != id {
+		handleError(w, &AppError{Code: http.StatusBadRequest, Message: fmt.Sprintf("Item ID %s doesn't match the path", item.ID)})
+		return
+	}
+	editMu.Lock()
+	defer editMu.Unlock()
+	store, data, err := editableData()
+	if err != nil {
+		handleError(w, err)
+		return
+	}
+	i := slices.IndexFunc(data.Items, func(item RadarItem) bool { return item.ID == id })
+	if i < 0 {
+		handleError(w, &AppError{Code: http.StatusNotFound, Message: "Unknown item"})
+		return
+	}
+	if err := checkIfMatch(r, data.Items[i]); err != nil {
+		handleError(w, err)
+		return
+	}
+
+	item.ID = id
+	item.Source = data.Items[i].Source
+	data.Items = slices.Clone(data.Items)
+	data.Items[i] = item
+	saved, err := saveEdit(r, store, data, AuditEvent{Action: "replace item", Detail: id})
+	if err != nil {
+		handleError(w, err)
+		return
+	}
+	log.Printf("Replaced item %s", id)
+	item, _ = findItem(saved.Items, id)
+	writeItem(w, http.StatusOK, item)
+}
+
+// deleteItemHandler removes the item with the ID given by the path from the
+// radar. Archiving it instead keeps it and its history.
+func deleteItemHandler(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+	editMu.Lock()
+	defer editMu.Unlock()
+	store, data, err := editableData()
+	if err != nil {
+		handleError(w, err)
+		return
+	}
+	i := slices.IndexFunc(data.Items, func(item RadarItem) bool { return item.ID == id })
+	if i < 0 {
+		handleError(w, &AppError{Code: http.StatusNotFound, Message: "Unknown item"})
+		return
+	}
+	if err := checkIfMatch(r, data.Items[i]); err != nil {
+		handleError(w, err)
+		return
+	}
+
+	// The relations of other items to it go with it.
+	data.Items = retargetRelations(slices.Delete(slices.Clone(data.Items), i, i+1), []string{id}, "")
+	if _, err := saveEdit(r, store, data, AuditEvent{Action: "delete item", Detail: id}); err != nil {
+		handleError(w, err)
+		return
+	}
+	log.Printf("Deleted item %s", id)
+	w.WriteHeader(http.StatusNoContent)
+}
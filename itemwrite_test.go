@@ -0,0 +1,193 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"maps"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+)
+
+// adminRequest sends a request with the admin token s3cret, header and
+// body.
+func adminRequest(t *testing.T, handler http.Handler, method, target string, header http.Header, body string) *httptest.ResponseRecorder {
+	t.Helper()
+	req := httptest.NewRequest(method, target, strings.NewReader(body))
+	maps.Copy(req.Header, header)
+	req.Header.Set("Authorization", "Bearer s3cret")
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	return rec
+}
+
+// anyVersion are headers writing to items whatever their version.
+var anyVersion = http.Header{"If-Match": {"*"}}
+
+func TestItemWrites(t *testing.T) {
+	cfg := defaultConfig()
+	cfg.Admin.Token = "s3cret"
+	cfg.Data.Path = writeFile(t, "radar.yaml", "LastModified: January 2020\n"+radarWith("Go", "Adopted"))
+	useConfig(t, cfg)
+	handler, err := setupRoutes(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	item := func(id string) (RadarItem, int) {
+		t.Helper()
+		rec := doRequest(t, handler, http.MethodGet, "/api/v1/radar/items/"+id)
+		var item RadarItem
+		if rec.Code == http.StatusOK {
+			if err := json.Unmarshal(rec.Body.Bytes(), &item); err != nil {
+				t.Fatal(err)
+			}
+		}
+		return item, rec.Code
+	}
+
+	if rec := doRequest(t, handler, http.MethodPost, "/api/v1/items"); rec.Code != http.StatusUnauthorized {
+		t.Errorf("POST /api/v1/items without token = %d, want 401", rec.Code)
+	}
+	rec := adminRequest(t, handler, http.MethodPost, "/api/v1/items", http.Header{"Content-Type": {"application/json"}}, `{"label": "Rust", "quadrant": "Tools", "ring": "In Discovery", "owners": [{"name": "Platform Team"}]}`)
+	if rec.Code != http.StatusCreated || rec.Header().Get("Location") != "/api/v1/radar/items/rust" {
+		t.Fatalf("POST /api/v1/items = %d %q: %s", rec.Code, rec.Header().Get("Location"), rec.Body)
+	}
+	if rust, code := item("rust"); code != http.StatusOK || rust.Ring != "In Discovery" || rust.Owners.names() != "Platform Team" || rust.LastUpdated.IsZero() {
+		t.Errorf("created item = %d %+v", code, rust)
+	}
+	content, _ := os.ReadFile(cfg.Data.Path)
+	if !strings.Contains(string(content), "Label: Rust") || strings.Contains(string(content), "January 2020") {
+		t.Errorf("data file after create:\n%s", content)
+	}
+
+	for _, tt := range []struct {
+		method, target string
+		header         http.Header
+		body           string
+		want           int
+	}{
+		{http.MethodPost, "/api/v1/items", nil, `{"id": "go", "label": "Golang", "quadrant": "Tools", "ring": "Adopted"}`, http.StatusConflict},
+		{http.MethodPost, "/api/v1/items", nil, `{"label": "Perl", "quadrant": "Tools", "ring": "Hold"}`, http.StatusBadRequest},
+		{http.MethodPost, "/api/v1/items", nil, `{"label": "Perl", "quadrant": "Tools", "rign": "Adopted"}`, http.StatusBadRequest},
+		{http.MethodPost, "/api/v1/items", nil, `{"label": "Go", "quadrant": "Tools", "ring": "Adopted"}`, http.StatusBadRequest},
+		{http.MethodPost, "/api/v1/items", http.Header{"Content-Type": {"text/yaml"}}, "label: Perl", http.StatusUnsupportedMediaType},
+		{http.MethodPut, "/api/v1/items/cobol", anyVersion, `{"label": "Cobol", "quadrant": "Tools", "ring": "Adopted"}`, http.StatusNotFound},
+		{http.MethodPut, "/api/v1/items/go", anyVersion, `{"id": "rust", "label": "Go", "quadrant": "Tools", "ring": "Adopted"}`, http.StatusBadRequest},
+		{http.MethodDelete, "/api/v1/items/cobol", anyVersion, "", http.StatusNotFound},
+	} {
+		if rec := adminRequest(t, handler, tt.method, tt.target, tt.header, tt.body); rec.Code != tt.want {
+			t.Errorf("%s %s %s = %d, want %d: %s", tt.method, tt.target, tt.body, rec.Code, tt.want, rec.Body)
+		}
+	}
+
+	// An item as GET returns it, with its _links and history, can be sent
+	// back.
+	rec = doRequest(t, handler, http.MethodGet, "/api/v1/radar/items/go")
+	body := strings.Replace(rec.Body.String(), `"ring":"Adopted"`, `"ring":"Not Recommended"`, 1)
+	if rec := adminRequest(t, handler, http.MethodPut, "/api/v1/items/go", http.Header{"Content-Type": {"application/json"}, "If-Match": {rec.Header().Get("ETag")}}, body); rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), `"ring":"Not Recommended"`) {
+		t.Errorf("PUT /api/v1/items/go = %d: %s", rec.Code, rec.Body)
+	}
+	if goItem, _ := item("go"); goItem.Ring != "Not Recommended" {
+		t.Errorf("replaced item = %+v", goItem)
+	}
+
+	if rec := adminRequest(t, handler, http.MethodDelete, "/api/v1/items/rust", anyVersion, ""); rec.Code != http.StatusNoContent {
+		t.Errorf("DELETE /api/v1/items/rust = %d: %s", rec.Code, rec.Body)
+	}
+	if _, code := item("rust"); code != http.StatusNotFound {
+		t.Errorf("GET deleted item = %d, want 404", code)
+	}
+}
+
+func TestItemWritesAudited(t *testing.T) {
+	db := openTestStore(t, "sqlite", "file::memory:")
+	if err := db.Save(context.Background(), RadarData{Items: []RadarItem{{Label: "Go", Quadrant: "Tools", Ring: "Adopted"}}}, AuditEvent{Actor: "test", Action: "seed"}); err != nil {
+		t.Fatal(err)
+	}
+	cfg := defaultConfig()
+	cfg.Admin.Token = "s3cret"
+	useConfig(t, cfg)
+	useStore(t, &databaseStore{db: db})
+	handler, err := setupRoutes(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if rec := adminRequest(t, handler, http.MethodDelete, "/api/v1/items/go", anyVersion, ""); rec.Code != http.StatusNoContent {
+		t.Fatalf("DELETE /api/v1/items/go = %d: %s", rec.Code, rec.Body)
+	}
+	events, err := db.AuditEvents(context.Background(), 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(events) != 1 || events[0].Actor != "admin" || events[0].Action != "delete item" || events[0].Detail != "go" || events[0].SourceIP != "192.0.2.1" {
+		t.Errorf("audit events = %+v", events)
+	}
+}
+
+func TestItemWritesIfMatch(t *testing.T) {
+	cfg := defaultConfig()
+	cfg.Admin.Token = "s3cret"
+	cfg.Data.Path = writeFile(t, "radar.yaml", radarWith("Go", "Adopted"))
+	useConfig(t, cfg)
+	handler, err := setupRoutes(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	etag := func() string {
+		t.Helper()
+		rec := doRequest(t, handler, http.MethodGet, "/api/v1/radar/items/go")
+		if rec.Header().Get("ETag") == "" {
+			t.Fatalf("GET /api/v1/radar/items/go has no ETag")
+		}
+		return rec.Header().Get("ETag")
+	}
+	patch := `{"description": "The Go language."}`
+	patchHeader := func(match string) http.Header {
+		return http.Header{"Content-Type": {mergePatchType}, "If-Match": {match}}
+	}
+
+	read := etag()
+	if rec := adminRequest(t, handler, http.MethodPatch, "/api/v1/items/go", http.Header{"Content-Type": {mergePatchType}}, patch); rec.Code != http.StatusPreconditionRequired {
+		t.Errorf("PATCH without If-Match = %d, want 428", rec.Code)
+	}
+	rec := adminRequest(t, handler, http.MethodPatch, "/api/v1/items/go", patchHeader(read), patch)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("PATCH If-Match %s = %d: %s", read, rec.Code, rec.Body)
+	}
+	current := etag()
+	if current == read || rec.Header().Get("ETag") != current {
+		t.Errorf("ETag after PATCH = %q, then GET %q, before %q", rec.Header().Get("ETag"), current, read)
+	}
+	if again := etag(); again != current {
+		t.Errorf("ETag changed without a write: %q, then %q", current, again)
+	}
+
+	// The editor who read the item before the patch can't overwrite it.
+	stale := adminRequest(t, handler, http.MethodPut, "/api/v1/items/go", http.Header{"If-Match": {read}}, `{"label": "Go", "quadrant": "Tools", "ring": "Not Recommended"}`)
+	if stale.Code != http.StatusPreconditionFailed {
+		t.Errorf("PUT with stale If-Match = %d, want 412", stale.Code)
+	}
+	for _, match := range []string{read, "W/" + current, `"other"`} {
+		if rec := adminRequest(t, handler, http.MethodDelete, "/api/v1/items/go", http.Header{"If-Match": {match}}, ""); rec.Code != http.StatusPreconditionFailed {
+			t.Errorf("DELETE If-Match %s = %d, want 412", match, rec.Code)
+		}
+	}
+	data, err := loadRadarData()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if goItem, _ := findItem(data.Items, "go"); goItem.Ring != "Adopted" || goItem.Description != "The Go language." {
+		t.Errorf("item after stale writes = %+v", goItem)
+	}
+
+	// ETags of compressed responses and lists of ETags match too.
+	if rec := adminRequest(t, handler, http.MethodPatch, "/api/v1/items/go", patchHeader(encodedETag(current, "gzip")), `{"ring": "In Discovery"}`); rec.Code != http.StatusOK {
+		t.Errorf("PATCH If-Match %s = %d: %s", encodedETag(current, "gzip"), rec.Code, rec.Body)
+	}
+	if rec := adminRequest(t, handler, http.MethodDelete, "/api/v1/items/go", http.Header{"If-Match": {read + ", " + etag()}}, ""); rec.Code != http.StatusNoContent {
+		t.Errorf("DELETE with current ETag listed = %d: %s", rec.Code, rec.Body)
+	}
+}
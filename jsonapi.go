@@ -0,0 +1,185 @@
+package main
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"slices"
+	"strconv"
+)
+
+// formatJSONAPI is the JSON:API response format of GET /api/radar.
+const formatJSONAPI = "jsonapi"
+
+// jsonAPIVersion is the version of the JSON:API specification followed.
+const jsonAPIVersion = "1.1"
+
+// jsonAPIRelationships are the fields of a RadarItem served as
+// relationships to other resources rather than as attributes.
+var jsonAPIRelationships = []string{"id", "quadrant", "owners"}
+
+// JSONAPIDocument is a JSON:API document of radar items, with the
+// quadrants and owners they refer to in Included.
+type JSONAPIDocument struct {
+	JSONAPI  JSONAPIObject     `json:"jsonapi"`
+	Data     []JSONAPIResource `json:"data"`
+	Included []JSONAPIResource `json:"included,omitempty"`
+	Meta     map[string]any    `json:"meta,omitempty"`
+	Links    map[string]string `json:"links,omitempty"`
+}
+
+// JSONAPIObject describes the JSON:API implementation of a document.
+type JSONAPIObject struct {
+	Version string `json:"version"`
+}
+
+// JSONAPIResource is a JSON:API resource object.
+type JSONAPIResource struct {
+	Type          string                         `json:"type"`
+	ID            string                         `json:"id"`
+	Attributes    map[string]any                 `json:"attributes,omitempty"`
+	Relationships map[string]JSONAPIRelationship `json:"relationships,omitempty"`
+	Links         map[string]string              `json:"links,omitempty"`
+}
+
+// JSONAPIRelationship links a resource to another, or, with a list of
+// identifiers, to several.
+type JSONAPIRelationship struct {
+	Data any `json:"data"`
+}
+
+// JSONAPIIdentifier identifies a resource by its type and ID.
+type JSONAPIIdentifier struct {
+	Type string `json:"type"`
+	ID   string `json:"id"`
+}
+
+// JSONAPIErrors is the JSON:API document of a failed request.
+type JSONAPIErrors struct {
+	Errors []JSONAPIError `json:"errors"`
+}
+
+// JSONAPIError is a JSON:API error object.
+type JSONAPIError struct {
+	Status string `json:"status"`
+	Title  string `json:"title"`
+	Detail string `json:"detail,omitempty"`
+}
+
+// ownerID returns the ID of the owners resource of owner: the slug of its
+// name, as owners are told apart by name ignoring case.
+func ownerID(owner Owner) string {
+	return itemSlug(owner.Name)
+}
+
+// jsonAPIDocument returns the JSON:API document of items, placed in the
+// quadrants of data, with the fields asked for, or all of them if fields
+// is nil. Quadrants and owners are included as resources of their own,
+// which items refer to in their relationships. basePath is the path prefix
+// of the links to the items.
+func jsonAPIDocument(data RadarData, items []RadarItem, fields []string, basePath, self string) (JSONAPIDocument, error) {
+	if fields == nil {
+		fields = itemFields
+	}
+	var attributes []string
+	for _, name := range fields {
+		if !slices.Contains(jsonAPIRelationships, name) {
+			attributes = append(attributes, name)
+		}
+	}
+
+	doc := JSONAPIDocument{
+		JSONAPI: JSONAPIObject{Version: jsonAPIVersion},
+		Data:    make([]JSONAPIResource, 0, len(items)),
+		Meta:    map[string]any{"total": len(items)},
+		Links:   map[string]string{"self": self},
+	}
+	quadrants := make(map[string]bool)
+	owners := make(map[string]bool)
+	var includedOwners []JSONAPIResource
+	for _, item := range items {
+		record, err := sparseItem(item, attributes)
+		if err != nil {
+			return JSONAPIDocument{}, err
+		}
+		resource := JSONAPIResource{
+			Type:       "items",
+			ID:         item.ID,
+			Attributes: make(map[string]any, len(record)),
+			Links:      map[string]string{"self": basePath + apiPrefix + "/radar/items/" + item.ID},
+		}
+		for name, value := range record {
+			resource.Attributes[name] = value
+		}
+		relationships := make(map[string]JSONAPIRelationship)
+		if slices.Contains(fields, "quadrant") {
+			id := itemSlug(item.Quadrant)
+			relationships["quadrant"] = JSONAPIRelationship{Data: JSONAPIIdentifier{Type: "quadrants", ID: id}}
+			quadrants[id] = true
+		}
+		if slices.Contains(fields, "owners") {
+			linked := make([]JSONAPIIdentifier, 0, len(item.Owners))
+			for _, owner := range item.Owners {
+				id := ownerID(owner)
+				linked = append(linked, JSONAPIIdentifier{Type: "owners", ID: id})
+				if !owners[id] {
+					owners[id] = true
+					includedOwners = append(includedOwners, jsonAPIOwner(owner))
+				}
+			}
+			relationships["owners"] = JSONAPIRelationship{Data: linked}
+		}
+		if len(relationships) > 0 {
+			resource.Relationships = relationships
+		}
+		doc.Data = append(doc.Data, resource)
+	}
+
+	// Only the quadrants items refer to are included, in their order.
+	for i, quadrant := range data.quadrants() {
+		if id := itemSlug(quadrant.Name); quadrants[id] {
+			attributes := map[string]any{"name": quadrant.Name, "order": i}
+			for name, value := range map[string]string{"color": quadrant.Color, "description": quadrant.Description} {
+				if value != "" {
+					attributes[name] = value
+				}
+			}
+			doc.Included = append(doc.Included, JSONAPIResource{Type: "quadrants", ID: id, Attributes: attributes})
+		}
+	}
+	doc.Included = append(doc.Included, includedOwners...)
+	return doc, nil
+}
+
+// jsonAPIOwner returns the owners resource of owner.
+func jsonAPIOwner(owner Owner) JSONAPIResource {
+	attributes := map[string]any{"name": owner.Name}
+	for name, value := range map[string]string{"email": owner.Email, "team": owner.Team, "slack": owner.Slack} {
+		if value != "" {
+			attributes[name] = value
+		}
+	}
+	return JSONAPIResource{Type: "owners", ID: ownerID(owner), Attributes: attributes}
+}
+
+// handleJSONAPIError writes an error response to the client like
+// handleError, as a JSON:API error document.
+func handleJSONAPIError(w http.ResponseWriter, err error) {
+	appErr, ok := err.(*AppError)
+	if !ok {
+		appErr = &AppError{Code: http.StatusInternalServerError, Err: err}
+	}
+	body := JSONAPIErrors{Errors: []JSONAPIError{{
+		Status: strconv.Itoa(appErr.Code),
+		Title:  http.StatusText(appErr.Code),
+		Detail: appErr.Message,
+	}}}
+	for _, v := range appErr.Violations {
+		body.Errors = append(body.Errors, JSONAPIError{Status: body.Errors[0].Status, Title: body.Errors[0].Title, Detail: v.Error()})
+	}
+	w.Header().Set("Content-Type", "application/vnd.api+json")
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.WriteHeader(appErr.Code)
+	json.NewEncoder(w).Encode(body)
+	slog.Error("Request failed", "err", err)
+}
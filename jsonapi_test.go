@@ -0,0 +1,65 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestRadarJSONAPI(t *testing.T) {
+	cfg := defaultConfig()
+	cfg.Server.BasePath = "/tech-radar"
+	cfg.Data.Path = writeFile(t, "radar.yaml", `Items:
+- Label: Go
+  Quadrant: Tools
+  Ring: Adopted
+  Owners: [{Name: Ana, Team: Platform}, {Name: Bo}]
+- Label: Rust
+  Quadrant: Tools
+  Ring: In Discovery
+  Owners: [{Name: ana}]
+- Label: Kubernetes
+  Quadrant: Platforms
+  Ring: Adopted
+  Archived: true
+`)
+	useConfig(t, cfg)
+	handler, err := setupRoutes(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/tech-radar/api/v1/radar?fields=label,quadrant,owners", nil)
+	req.Header.Set("Accept", "application/vnd.api+json")
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	if ct := rec.Header().Get("Content-Type"); rec.Code != http.StatusOK || ct != "application/vnd.api+json" {
+		t.Fatalf("GET /api/v1/radar as JSON:API = %d %q", rec.Code, ct)
+	}
+	// The owner Ana is included once, as resources are told apart by type
+	// and ID, and only the quadrants of the items are.
+	want := `{"jsonapi":{"version":"1.1"},"data":[` +
+		`{"type":"items","id":"go","attributes":{"label":"Go"},"relationships":{"owners":{"data":[{"type":"owners","id":"ana"},{"type":"owners","id":"bo"}]},"quadrant":{"data":{"type":"quadrants","id":"tools"}}},"links":{"self":"/tech-radar/api/v1/radar/items/go"}},` +
+		`{"type":"items","id":"rust","attributes":{"label":"Rust"},"relationships":{"owners":{"data":[{"type":"owners","id":"ana"}]},"quadrant":{"data":{"type":"quadrants","id":"tools"}}},"links":{"self":"/tech-radar/api/v1/radar/items/rust"}}],` +
+		`"included":[{"type":"quadrants","id":"tools","attributes":{"name":"Tools","order":1}},` +
+		`{"type":"owners","id":"ana","attributes":{"name":"Ana","team":"Platform"}},` +
+		`{"type":"owners","id":"bo","attributes":{"name":"Bo"}}],` +
+		`"meta":{"total":2},"links":{"self":"/tech-radar/api/v1/radar?fields=label,quadrant,owners"}}`
+	if got := strings.TrimSpace(rec.Body.String()); got != want {
+		t.Errorf("GET /api/v1/radar as JSON:API =\n%s\nwant\n%s", got, want)
+	}
+
+	// Without relationships asked for, nothing is included.
+	rec = doRequest(t, handler, http.MethodGet, "/tech-radar/api/v1/radar?format=jsonapi&fields=ring&includeArchived=true")
+	if body := rec.Body.String(); !strings.Contains(body, `"attributes":{"ring":"Adopted"}`) || strings.Contains(body, "included") || !strings.Contains(body, `"total":3`) {
+		t.Errorf("GET /api/v1/radar?format=jsonapi&fields=ring = %s", body)
+	}
+
+	// Errors are JSON:API error documents.
+	rec = doRequest(t, handler, http.MethodGet, "/tech-radar/api/v1/radar?format=jsonapi&ring=Hold")
+	want = `{"errors":[{"status":"400","title":"Bad Request","detail":"Invalid ring \"Hold\", must be one of Adopted, In Discovery, Not Recommended"}]}`
+	if got := strings.TrimSpace(rec.Body.String()); rec.Code != http.StatusBadRequest || rec.Header().Get("Content-Type") != "application/vnd.api+json" || got != want {
+		t.Errorf("invalid ring = %d %q %s", rec.Code, rec.Header().Get("Content-Type"), got)
+	}
+}
@@ -0,0 +1,81 @@
+package main
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+)
+
+// Link points from a radar item to a related document, such as an ADR, the
+// documentation or a proof-of-concept repository.
+type Link struct {
+	Title string `yaml:"Title,omitempty" json:"title,omitempty" toml:"Title,omitempty"`
+	URL   string `yaml:"URL" json:"url" toml:"URL"`
+}
+
+// Links are the links of a radar item. In JSON they are always an array.
+type Links []Link
+
+// checkLinkURL reports why raw is not an absolute HTTP(S) URL a browser can
+// follow, or nil if it is.
+func checkLinkURL(raw string) error {
+	u, err := url.Parse(raw)
+	switch {
+	case raw == "":
+		return errors.New("missing")
+	case err != nil:
+		return errors.New("invalid URL")
+	case u.Scheme != "http" && u.Scheme != "https":
+		return fmt.Errorf("URL %q must use http or https", raw)
+	case u.Host == "":
+		return fmt.Errorf("URL %q has no host", raw)
+	}
+	return nil
+}
+
+func (l Links) MarshalJSON() ([]byte, error) {
+	if l == nil {
+		return []byte("[]"), nil
+	}
+	return json.Marshal([]Link(l))
+}
+
+func (l *Links) UnmarshalJSON(data []byte) error {
+	if err := json.Unmarshal(data, (*[]Link)(l)); err != nil {
+		return err
+	}
+	if len(*l) == 0 {
+		*l = nil
+	}
+	return nil
+}
+
+// Value stores the links in a SQL text column as a JSON array.
+func (l Links) Value() (driver.Value, error) {
+	if len(l) == 0 {
+		return "", nil
+	}
+	data, err := json.Marshal([]Link(l))
+	return string(data), err
+}
+
+// Scan reads links stored by Value.
+func (l *Links) Scan(src any) error {
+	var s string
+	switch v := src.(type) {
+	case nil:
+	case string:
+		s = v
+	case []byte:
+		s = string(v)
+	default:
+		return fmt.Errorf("cannot scan %T into links", src)
+	}
+	*l = nil
+	if s == "" {
+		return nil
+	}
+	return l.UnmarshalJSON([]byte(s))
+}
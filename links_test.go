@@ -0,0 +1,51 @@
+package main
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestDecodeLinks(t *testing.T) {
+	data, err := decodeRadarData("radar.yaml", []byte("Items:\n- Label: Go\n  Links:\n  - Title: ADR 12\n    URL: https://example.com/adr/12\n  - URL: https://go.dev\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := Links{{Title: "ADR 12", URL: "https://example.com/adr/12"}, {URL: "https://go.dev"}}
+	if !reflect.DeepEqual(data.Items[0].Links, want) {
+		t.Errorf("links = %+v, want %+v", data.Items[0].Links, want)
+	}
+
+	out, err := json.Marshal(data.Items[0])
+	if err != nil || !strings.Contains(string(out), `"links":[{"title":"ADR 12","url":"https://example.com/adr/12"},{"url":"https://go.dev"}]`) {
+		t.Errorf("json.Marshal() = %s, %v", out, err)
+	}
+	if out, _ := json.Marshal(RadarItem{}); !strings.Contains(string(out), `"links":[]`) {
+		t.Errorf("json.Marshal() = %s, want an empty links array", out)
+	}
+}
+
+func TestValidateLinks(t *testing.T) {
+	content := `Items:
+- Label: Go
+  Quadrant: Tools
+  Ring: Adopted
+  Links:
+  - URL: https://go.dev
+  - Title: Docs
+  - URL: ftp://example.com/go
+  - URL: /docs/go
+  - URL: "http://"
+`
+	err := validateRadarContent("radar.yaml", []byte(content), newValidationScope())
+	want := []string{
+		`radar.yaml:7: item "Go".Links[1].URL: missing`,
+		`radar.yaml:8: item "Go".Links[2].URL: URL "ftp://example.com/go" must use http or https`,
+		`radar.yaml:9: item "Go".Links[3].URL: URL "/docs/go" must use http or https`,
+		`radar.yaml:10: item "Go".Links[4].URL: URL "http://" has no host`,
+	}
+	if err == nil || err.Error() != strings.Join(want, "\n") {
+		t.Errorf("validateRadarContent() = %v, want\n%s", err, strings.Join(want, "\n"))
+	}
+}
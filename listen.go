@@ -0,0 +1,113 @@
+package main
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"syscall"
+)
+
+// unixPrefix marks a listen address as a Unix domain socket path, e.g.
+// unix:///run/radar.sock.
+const unixPrefix = "unix://"
+
+// systemdFirstFD is the first file descriptor passed by systemd socket
+// activation (SD_LISTEN_FDS_START).
+const systemdFirstFD = 3
+
+// parseListenAddr splits a listen address into the network and address
+// accepted by net.Listen. TCP addresses take the usual host:port form.
+func parseListenAddr(addr string) (network, address string, err error) {
+	if path, ok := strings.CutPrefix(addr, unixPrefix); ok {
+		if path == "" {
+			return "", "", fmt.Errorf("invalid listen address %q: missing socket path", addr)
+		}
+		return "unix", path, nil
+	}
+
+	_, port, err := net.SplitHostPort(addr)
+	if err != nil {
+		return "", "", fmt.Errorf("invalid listen address %q: %v", addr, err)
+	}
+	if n, err := strconv.Atoi(port); err != nil || n < 0 || n > 65535 {
+		return "", "", fmt.Errorf("invalid port %q: must be a number between 0 and 65535", port)
+	}
+	return "tcp", addr, nil
+}
+
+// listen opens a listener for addr. A stale Unix socket left behind by a
+// previous process is removed before binding.
+func listen(addr string) (net.Listener, error) {
+	network, address, err := parseListenAddr(addr)
+	if err != nil {
+		return nil, err
+	}
+	if network == "unix" {
+		if info, err := os.Stat(address); err == nil && info.Mode()&os.ModeSocket != 0 {
+			os.Remove(address)
+		}
+	}
+	return net.Listen(network, address)
+}
+
+// systemdListeners returns the sockets passed by systemd socket activation,
+// in the order they are declared in the .socket unit, or nil when the process
+// was not socket-activated.
+func systemdListeners() ([]net.Listener, error) {
+	pid, err := strconv.Atoi(os.Getenv("LISTEN_PID"))
+	if err != nil || pid != os.Getpid() {
+		return nil, nil
+	}
+	count, err := strconv.Atoi(os.Getenv("LISTEN_FDS"))
+	if err != nil || count < 1 {
+		return nil, nil
+	}
+	// Don't pass the sockets on to child processes.
+	os.Unsetenv("LISTEN_PID")
+	os.Unsetenv("LISTEN_FDS")
+	os.Unsetenv("LISTEN_FDNAMES")
+
+	listeners := make([]net.Listener, 0, count)
+	for fd := systemdFirstFD; fd < systemdFirstFD+count; fd++ {
+		syscall.CloseOnExec(fd)
+		file := os.NewFile(uintptr(fd), "LISTEN_FD_"+strconv.Itoa(fd))
+		ln, err := net.FileListener(file)
+		file.Close()
+		if err != nil {
+			return nil, fmt.Errorf("socket activation fd %d: %w", fd, err)
+		}
+		listeners = append(listeners, ln)
+	}
+	return listeners, nil
+}
+
+// openListeners returns one listener per address. Sockets passed by systemd
+// are used first, in order; any remaining addresses are bound directly.
+func openListeners(addrs []string) ([]net.Listener, error) {
+	activated, err := systemdListeners()
+	if err != nil {
+		return nil, err
+	}
+
+	listeners := make([]net.Listener, len(addrs))
+	for i, addr := range addrs {
+		if i < len(activated) {
+			listeners[i] = activated[i]
+			continue
+		}
+		ln, err := listen(addr)
+		if err != nil {
+			for _, opened := range listeners[:i] {
+				opened.Close()
+			}
+			return nil, err
+		}
+		listeners[i] = ln
+	}
+	for _, extra := range activated[min(len(activated), len(addrs)):] {
+		extra.Close()
+	}
+	return listeners, nil
+}
@@ -0,0 +1,96 @@
+package main
+
+import (
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+)
+
+func TestParseListenAddr(t *testing.T) {
+	tests := []struct {
+		addr        string
+		wantNetwork string
+		wantAddress string
+		wantErr     bool
+	}{
+		{addr: ":8080", wantNetwork: "tcp", wantAddress: ":8080"},
+		{addr: "127.0.0.1:0", wantNetwork: "tcp", wantAddress: "127.0.0.1:0"},
+		{addr: "[::1]:443", wantNetwork: "tcp", wantAddress: "[::1]:443"},
+		{addr: "unix:///run/radar.sock", wantNetwork: "unix", wantAddress: "/run/radar.sock"},
+		{addr: "unix://relative.sock", wantNetwork: "unix", wantAddress: "relative.sock"},
+		{addr: "unix://", wantErr: true},
+		{addr: "8080", wantErr: true},
+		{addr: ":http", wantErr: true},
+		{addr: ":70000", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.addr, func(t *testing.T) {
+			network, address, err := parseListenAddr(tt.addr)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseListenAddr(%q) error = %v, wantErr %v", tt.addr, err, tt.wantErr)
+			}
+			if network != tt.wantNetwork || address != tt.wantAddress {
+				t.Errorf("parseListenAddr(%q) = %q, %q, want %q, %q", tt.addr, network, address, tt.wantNetwork, tt.wantAddress)
+			}
+		})
+	}
+}
+
+func TestListenUnixReplacesStaleSocket(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "radar.sock")
+
+	// A listener closed without unlinking leaves its socket file behind, as
+	// it would after a crash.
+	stale, err := net.Listen("unix", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	stale.(*net.UnixListener).SetUnlinkOnClose(false)
+	stale.Close()
+
+	ln, err := listen(unixPrefix + path)
+	if err != nil {
+		t.Fatalf("listen() error = %v", err)
+	}
+	defer ln.Close()
+
+	go http.Serve(ln, http.HandlerFunc(healthHandler))
+	client := http.Client{Transport: &http.Transport{
+		Dial: func(string, string) (net.Conn, error) { return net.Dial("unix", path) },
+	}}
+	resp, err := client.Get("http://radar/health")
+	if err != nil {
+		t.Fatalf("GET over unix socket: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("status = %d, want 200", resp.StatusCode)
+	}
+}
+
+func TestListenRefusesToReplaceRegularFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "radar.sock")
+	if err := os.WriteFile(path, []byte("not a socket"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if ln, err := listen(unixPrefix + path); err == nil {
+		ln.Close()
+		t.Fatal("listen() succeeded over a regular file")
+	}
+}
+
+func TestSystemdListenersIgnoresOtherProcess(t *testing.T) {
+	t.Setenv("LISTEN_PID", strconv.Itoa(os.Getpid()+1))
+	t.Setenv("LISTEN_FDS", "1")
+
+	listeners, err := systemdListeners()
+	if err != nil || listeners != nil {
+		t.Fatalf("systemdListeners() = %v, %v, want nil, nil", listeners, err)
+	}
+	if os.Getenv("LISTEN_FDS") != "1" {
+		t.Error("LISTEN_FDS was cleared for another process's sockets")
+	}
+}
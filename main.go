@@ -1,147 +1,237 @@
 package main
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
 	"html/template"
 	"log"
+	"log/slog"
 	"net/http"
 	"os"
-
-	"gopkg.in/yaml.v3"
-)
-
-const (
-	port         = ":8080"
-	dataFilePath = "data/radar.yaml"
-	templatePath = "templates/index.html"
+	"slices"
 )
 
-// RadarData represents the complete radar data structure.
-type RadarData struct {
-	LastModified string      `yaml:"LastModified" json:"lastModified"`
-	Items        []RadarItem `yaml:"Items" json:"items"`
-}
-
-// RadarItem represents a technology item in the radar.
-type RadarItem struct {
-	Label       string `yaml:"Label" json:"label"`
-	Quadrant    string `yaml:"Quadrant" json:"quadrant"`
-	Ring        string `yaml:"Ring" json:"ring"`
-	Moved       bool   `yaml:"Moved" json:"moved"`
-	Description string `yaml:"Description" json:"description"`
-	Owners      string `yaml:"Owners" json:"owners"`
-}
-
 // AppError represents an application error with HTTP status code.
 type AppError struct {
 	Code    int
 	Message string
 	Err     error
+	// Violations are the problems that made data invalid, sent as a JSON
+	// report instead of a plain text message.
+	Violations ValidationErrors
 }
 
 func (e *AppError) Error() string {
-	if e.Err != nil {
+	switch {
+	case e.Violations != nil:
+		return e.Message + ":\n" + e.Violations.Error()
+	case e.Err != nil:
 		return e.Message + ": " + e.Err.Error()
 	}
 	return e.Message
 }
 
-// loadRadarData reads and parses the radar data from a YAML file.
-func loadRadarData() (RadarData, error) {
-	file, err := os.ReadFile(dataFilePath)
-	if err != nil {
-		return RadarData{}, &AppError{Code: http.StatusInternalServerError, Message: "Failed to read radar data", Err: err}
-	}
-
-	var yamlMap map[string]interface{}
-	if err := yaml.Unmarshal(file, &yamlMap); err != nil {
-		return RadarData{}, &AppError{Code: http.StatusInternalServerError, Message: "Failed to parse radar data", Err: err}
-	}
-
-	// Create our RadarData structure
-	var radarData RadarData
-
-	// Get LastModified field
-	if lastModified, ok := yamlMap["LastModified"]; ok {
-		if str, ok := lastModified.(string); ok {
-			radarData.LastModified = str
-		}
-	}
-
-	// Get Items field and parse the items
-	if items, ok := yamlMap["Items"].([]interface{}); ok {
-		for _, item := range items {
-			if itemMap, ok := item.(map[string]interface{}); ok {
-				radarItem := RadarItem{}
-
-				if label, ok := itemMap["Label"].(string); ok {
-					radarItem.Label = label
-				}
-				if quadrant, ok := itemMap["Quadrant"].(string); ok {
-					radarItem.Quadrant = quadrant
-				}
-				if ring, ok := itemMap["Ring"].(string); ok {
-					radarItem.Ring = ring
-				}
-				if moved, ok := itemMap["Moved"].(bool); ok {
-					radarItem.Moved = moved
-				}
-				if desc, ok := itemMap["Description"].(string); ok {
-					radarItem.Description = desc
-				}
-				if owners, ok := itemMap["Owners"].(string); ok {
-					radarItem.Owners = owners
-				}
-
-				radarData.Items = append(radarData.Items, radarItem)
-			}
-		}
-	}
+// invalidDataError returns the AppError rejecting data with the problems
+// in errs.
+func invalidDataError(message string, errs ValidationErrors) *AppError {
+	return &AppError{Code: http.StatusBadRequest, Message: message, Violations: errs}
+}
+
+// validationReport is the JSON body of responses rejecting invalid data.
+type validationReport struct {
+	Error      string            `json:"error,omitempty"`
+	Violations []ValidationError `json:"violations"`
+}
 
-	return radarData, nil
+// errorResponse is the JSON body of error responses of endpoints that
+// serve a JSON resource, see handleJSONError.
+type errorResponse struct {
+	Error string `json:"error"`
+}
+
+// handleJSONError writes an error response to the client like handleError,
+// with the message of an AppError as JSON.
+func handleJSONError(w http.ResponseWriter, err error) {
+	appErr, ok := err.(*AppError)
+	if !ok || appErr.Violations != nil {
+		handleError(w, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.WriteHeader(appErr.Code)
+	json.NewEncoder(w).Encode(errorResponse{Error: appErr.Message})
+	slog.Error("Request failed", "err", err)
 }
 
 // handleError writes an error response to the client.
 func handleError(w http.ResponseWriter, err error) {
-	if appErr, ok := err.(*AppError); ok {
+	// A write that opened a pull request rather than saved has succeeded.
+	var pr *PullRequest
+	if appErr, ok := err.(*AppError); ok && errors.As(appErr.Err, &pr) || errors.As(err, &pr) {
+		writePullRequest(w, pr)
+		return
+	}
+	if appErr, ok := err.(*AppError); ok && appErr.Violations != nil {
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("X-Content-Type-Options", "nosniff")
+		w.WriteHeader(appErr.Code)
+		json.NewEncoder(w).Encode(validationReport{Error: appErr.Message, Violations: appErr.Violations})
+	} else if ok {
 		http.Error(w, appErr.Message, appErr.Code)
 	} else {
 		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
 	}
-	log.Printf("Error: %v", err)
+	slog.Error("Request failed", "err", err)
+}
+
+// selectItems returns the items of data the request asks for: those that
+// aren't archived unless ?includeArchived=true, filtered with the
+// parameters of radarFilter and, with ?sort=, sorted by sortItems.
+func selectItems(r *http.Request, data RadarData) ([]RadarItem, error) {
+	include, err := includeArchived(r)
+	if err != nil {
+		return nil, err
+	}
+	items := data.Items
+	if !include {
+		items = visibleItems(items)
+	}
+	filter, err := parseRadarFilter(r.URL.Query(), data)
+	if err != nil {
+		return nil, err
+	}
+	items = slices.DeleteFunc(slices.Clone(items), func(item RadarItem) bool { return !filter.matches(item) })
+	by, order := r.URL.Query().Get("sort"), r.URL.Query().Get("order")
+	if order != "" && order != "asc" && order != "desc" {
+		return nil, &AppError{Code: http.StatusBadRequest, Message: fmt.Sprintf("Invalid order %q, must be asc or desc", order)}
+	}
+	if by == "" {
+		if order != "" {
+			return nil, &AppError{Code: http.StatusBadRequest, Message: "order requires sort"}
+		}
+		return items, nil
+	}
+	if err := sortItems(items, data, by, order == "desc"); err != nil {
+		return nil, err
+	}
+	return items, nil
 }
 
-// apiHandler serves the radar data as a JSON API.
+// apiHandler serves the radar data, with the quadrants and rings its items
+// are placed in and the items selectItems selects, with the fields
+// parseFields asks for, in the format negotiateFormat picks.
 func apiHandler(w http.ResponseWriter, r *http.Request) {
-	data, err := loadRadarData()
+	w.Header().Add("Vary", "Accept")
+	format, err := negotiateFormat(r)
 	if err != nil {
 		handleError(w, err)
 		return
 	}
-
-	w.Header().Set("Content-Type", "application/json")
-	if err := json.NewEncoder(w).Encode(data); err != nil {
-		handleError(w, &AppError{Code: http.StatusInternalServerError, Message: "Failed to encode response", Err: err})
+	fail := handleError
+	if format == formatJSONAPI {
+		fail = handleJSONAPIError
 	}
-}
-
-// indexHandler serves the main HTML page.
-func indexHandler(w http.ResponseWriter, r *http.Request) {
-	tmpl, err := template.ParseFiles(templatePath)
+	fields, err := parseFields(r, itemFields)
 	if err != nil {
-		handleError(w, &AppError{Code: http.StatusInternalServerError, Message: "Failed to load template", Err: err})
+		fail(w, err)
 		return
 	}
-
 	data, err := loadRadarData()
 	if err != nil {
-		handleError(w, err)
+		fail(w, err)
+		return
+	}
+	data = withSegments(data)
+	if data.Items, err = selectItems(r, data); err != nil {
+		fail(w, err)
 		return
 	}
+	var body any
+	var records []map[string]json.RawMessage
+	switch format {
+	case formatJSONAPI:
+		body, err = jsonAPIDocument(data, data.Items, fields, currentConfig().Server.BasePath, requestURL(r).String())
+	case formatCSV:
+		if fields == nil {
+			fields = itemFields
+		}
+		records, err = itemRecords(data.Items, fields)
+	default:
+		radar := struct {
+			RadarData
+			Items any `json:"items"`
+		}{RadarData: data}
+		radar.Items, err = sparseItems(data.Items, fields)
+		body = radar
+	}
+	if err != nil {
+		fail(w, &AppError{Code: http.StatusInternalServerError, Message: "Failed to encode response", Err: err})
+		return
+	}
+
+	writeResponse(w, r, format, body, records, fields)
+}
 
-	if err := tmpl.Execute(w, data); err != nil {
-		handleError(w, &AppError{Code: http.StatusInternalServerError, Message: "Failed to render template", Err: err})
+// indexPageData is the context passed to the index template.
+type indexPageData struct {
+	RadarData
+	// BasePath is the path prefix the app is mounted under, without a
+	// trailing slash, used to build asset and API URLs.
+	BasePath string
+	Build    BuildInfo
+	// OEmbedURL is the oEmbed preview of the page, see oEmbedHandler.
+	OEmbedURL string
+}
+
+// templateLoader returns a function returning the template called name.
+// The template is parsed once up front, except in development mode where
+// it is re-parsed on every call so edits show up on reload.
+func templateLoader(cfg Config, name string) (func() (*template.Template, error), error) {
+	load := func() (*template.Template, error) {
+		return template.ParseFS(cfg.templateFS(name), name)
+	}
+	if cfg.Dev {
+		return load, nil
+	}
+	tmpl, err := load()
+	if err != nil {
+		return nil, err
+	}
+	return func() (*template.Template, error) { return tmpl, nil }, nil
+}
+
+// newIndexHandler returns the handler serving the main HTML page, which
+// only the radar itself may show in a frame.
+func newIndexHandler(cfg Config) (http.Handler, error) {
+	load, err := templateLoader(cfg, indexTemplate)
+	if err != nil {
+		return nil, err
 	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Frame-Options", "SAMEORIGIN")
+		w.Header().Set("Content-Security-Policy", "frame-ancestors 'self'")
+		tmpl, err := load()
+		if err != nil {
+			handleError(w, &AppError{Code: http.StatusInternalServerError, Message: "Failed to load template", Err: err})
+			return
+		}
+
+		data, err := loadRadarData()
+		if err != nil {
+			handleError(w, err)
+			return
+		}
+
+		page := indexPageData{RadarData: withSegments(data), BasePath: cfg.Server.BasePath, Build: buildInfo(), OEmbedURL: oEmbedDiscoveryURL(r)}
+		if err := tmpl.Execute(w, page); err != nil {
+			handleError(w, &AppError{Code: http.StatusInternalServerError, Message: "Failed to render template", Err: err})
+		}
+	}), nil
 }
 
 // healthHandler responds with a simple OK status.
@@ -150,19 +240,293 @@ func healthHandler(w http.ResponseWriter, r *http.Request) {
 	w.Write([]byte("OK"))
 }
 
-// setupRoutes configures the HTTP routes.
-func setupRoutes() {
-	http.HandleFunc("/", indexHandler)
-	http.HandleFunc("/api/radar", apiHandler)
-	http.HandleFunc("/health", healthHandler)
-	http.Handle("/static/", http.StripPrefix("/static/", http.FileServer(http.Dir("static"))))
+// setupRoutes configures the HTTP routes and wraps them in the configured
+// middleware.
+func setupRoutes(cfg Config) (http.Handler, error) {
+	mux := http.NewServeMux()
+	// GET patterns also match HEAD requests, and other methods get a 405
+	// with an Allow header.
+	mux.HandleFunc("GET /health", healthHandler)
+	mux.HandleFunc("GET /version", versionHandler)
+	if cfg.Features.UI {
+		index, err := newIndexHandler(cfg)
+		if err != nil {
+			return nil, fmt.Errorf("loading templates: %w", err)
+		}
+		mux.Handle("GET /{$}", index)
+		embed, err := newEmbedHandler(cfg)
+		if err != nil {
+			return nil, fmt.Errorf("loading templates: %w", err)
+		}
+		mux.Handle("GET /embed", embed)
+		if cfg.Features.API && cfg.reviewEnabled() {
+			moderation, err := newModerationPageHandler(cfg)
+			if err != nil {
+				return nil, fmt.Errorf("loading templates: %w", err)
+			}
+			mux.Handle("GET /moderation", moderation)
+		}
+		mux.HandleFunc("GET /feed.atom", feedHandler)
+		mux.HandleFunc("GET /calendar.ics", calendarHandler)
+		mux.HandleFunc("GET /radar.svg", svgHandler)
+		mux.Handle("GET /static/", cacheControlHandler(staticCacheControl, http.StripPrefix("/static/", http.FileServerFS(cfg.staticFS()))))
+	}
+	if cfg.Features.API {
+		// Endpoints registered with api or admin are documented in
+		// apiOperations.
+		api := func(pattern string, handler http.Handler) {
+			handleAPI(mux, cfg.Server.BasePath, pattern, handler)
+		}
+		if err := handleAPIDocs(mux, cfg); err != nil {
+			return nil, fmt.Errorf("generating the OpenAPI document: %w", err)
+		}
+		api("GET /radar", http.HandlerFunc(apiHandler))
+		api("GET /radar/items", http.HandlerFunc(itemsHandler))
+		api("GET /radar/items.ndjson", http.HandlerFunc(ndjsonHandler))
+		api("GET /radar/items/{id}", http.HandlerFunc(itemHandler))
+		api("GET /tags", http.HandlerFunc(tagsHandler))
+		api("GET /stats", http.HandlerFunc(statsHandler))
+		api("GET /quadrants", http.HandlerFunc(quadrantsHandler))
+		api("GET /rings", http.HandlerFunc(ringsHandler))
+		api("GET /search", http.HandlerFunc(searchHandler))
+		api("GET /suggest", http.HandlerFunc(suggestHandler))
+		api("GET /history", http.HandlerFunc(historyHandler))
+		api("GET /history/{item}", http.HandlerFunc(historyHandler))
+		api("GET /changes", http.HandlerFunc(changesHandler))
+		api("GET /sunsets", http.HandlerFunc(sunsetsHandler))
+		api("GET /reviews/overdue", http.HandlerFunc(overdueReviewsHandler))
+		api("GET /reports/stale", http.HandlerFunc(staleReportHandler))
+		api("GET /backstage/tech-radar", http.HandlerFunc(backstageHandler))
+		api("GET /export/zalando.json", http.HandlerFunc(zalandoHandler))
+		api("GET /export/byor.json", byorExportHandler(formatJSON))
+		api("GET /export/byor.csv", byorExportHandler(formatCSV))
+		api("GET /export.xlsx", http.HandlerFunc(xlsxHandler))
+		api("GET /export.md", http.HandlerFunc(markdownHandler))
+		api("GET /export.pdf", http.HandlerFunc(pdfHandler))
+		api("GET /proposals", http.HandlerFunc(listProposalsHandler))
+		api("POST /proposals", http.HandlerFunc(createProposalHandler))
+		api("GET /proposals/{id}", http.HandlerFunc(proposalHandler))
+		api("PUT /proposals/{id}", http.HandlerFunc(updateProposalHandler))
+		api("POST /proposals/{id}/withdraw", http.HandlerFunc(withdrawProposalHandler))
+		api("GET /items/{id}/history", http.HandlerFunc(itemHistoryHandler))
+		api("GET /items/{id}/comments", http.HandlerFunc(listCommentsHandler))
+		api("POST /items/{id}/comments", http.HandlerFunc(createCommentHandler))
+		api("GET /items/{id}/comments/{comment}", http.HandlerFunc(commentHandler))
+		api("DELETE /items/{id}/comments/{comment}", http.HandlerFunc(deleteCommentHandler))
+		api("POST /items/{id}/vote", voteHandler(true))
+		api("DELETE /items/{id}/vote", voteHandler(false))
+		api("GET /most-wanted", http.HandlerFunc(mostWantedHandler))
+		api("GET /items/{id}/reactions", http.HandlerFunc(reactionsHandler))
+		api("PUT /items/{id}/reactions/{reaction}", reactHandler(true))
+		api("DELETE /items/{id}/reactions/{reaction}", reactHandler(false))
+		if cfg.reviewEnabled() {
+			api("POST /proposals/{id}/review", reviewHandler(reviewStart))
+			api("POST /proposals/{id}/approve", reviewHandler(reviewApprove))
+			api("POST /proposals/{id}/reject", reviewHandler(reviewReject))
+			api("POST /proposals/{id}/publish", reviewHandler(reviewPublish))
+			api("POST /proposals/{id}/assign", http.HandlerFunc(assignHandler))
+			api("POST /items/{id}/comments/{comment}/approve", http.HandlerFunc(approveCommentHandler))
+			api("GET /moderation", http.HandlerFunc(moderationHandler))
+		}
+		if cfg.Features.UI {
+			api("GET /oembed", http.HandlerFunc(oEmbedHandler))
+		}
+		if cfg.Admin.Token != "" {
+			admin := func(pattern string, handler http.Handler) {
+				api(pattern, bearerAuthMiddleware(cfg.Admin.Token, handler))
+			}
+			admin("POST /import", http.HandlerFunc(importHandler))
+			admin("GET /admin/backup", http.HandlerFunc(backupHandler))
+			admin("POST /admin/restore", http.HandlerFunc(restoreHandler))
+			admin("POST /admin/rollback", http.HandlerFunc(rollbackHandler))
+			admin("POST /items", http.HandlerFunc(createItemHandler))
+			admin("POST /items/bulk", http.HandlerFunc(bulkEditHandler))
+			admin("POST /admin/owners/transfer", http.HandlerFunc(ownerTransferHandler))
+			admin("PUT /items/{id}", http.HandlerFunc(replaceItemHandler))
+			admin("PATCH /items/{id}", http.HandlerFunc(patchItemHandler))
+			admin("DELETE /items/{id}", http.HandlerFunc(deleteItemHandler))
+			admin("POST /admin/items/{id}/archive", archiveHandler(true))
+			admin("POST /admin/items/{id}/unarchive", archiveHandler(false))
+			admin("GET /admin/duplicates", http.HandlerFunc(duplicatesHandler))
+			admin("GET /audit", http.HandlerFunc(auditHandler))
+			admin("POST /admin/duplicates/merge", http.HandlerFunc(mergeHandler))
+		}
+		mux.HandleFunc("/graphql", graphQLHandler)
+		if cfg.Data.Git.enabled() && cfg.Data.Git.WebhookSecret != "" {
+			api("POST /git/webhook", gitWebhookHandler(cfg.Data.Git.WebhookSecret))
+		}
+	}
+
+	var handler http.Handler = mux
+	if cfg.Server.RequestTimeout > 0 {
+		handler = timeoutMiddleware(cfg.Server.RequestTimeout)(handler)
+	}
+	if cfg.Features.API {
+		handler = apiErrorsHandler(handler)
+		if cfg.Server.CORS.enabled() {
+			handler = corsHandler(cfg.Server.CORS, handler)
+		}
+	}
+	if cfg.Server.BasePath != "" {
+		handler = basePathHandler(cfg.Server.BasePath, handler)
+	}
+	if cfg.Server.Compress {
+		handler = compressMiddleware(handler)
+	}
+	if cfg.Logging.AccessLog {
+		handler = accessLogMiddleware(handler)
+	}
+	if cfg.Dev {
+		handler = noCacheMiddleware(handler)
+	}
+	if cfg.Server.TrustProxy {
+		handler = proxyHeadersMiddleware(handler)
+	}
+	return handler, nil
 }
 
 func main() {
-	setupRoutes()
+	args := os.Args[1:]
+	if len(args) > 0 && args[0] == "import-csv" {
+		if err := runImportCSV(args[1:], os.Stdin, os.Stdout); err != nil {
+			if errors.Is(err, flag.ErrHelp) {
+				return
+			}
+			fmt.Fprintf(os.Stderr, "Import failed:\n%v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+	if len(args) > 0 && args[0] == "validate" {
+		if err := runValidate(args[1:], os.Stdout); err != nil {
+			if errors.Is(err, flag.ErrHelp) {
+				return
+			}
+			fmt.Fprintf(os.Stderr, "Validation failed:\n%v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+	if len(args) > 0 && args[0] == "report" {
+		if err := runReport(args[1:], os.Stdout); err != nil {
+			if errors.Is(err, flag.ErrHelp) {
+				return
+			}
+			fmt.Fprintf(os.Stderr, "Report failed:\n%v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+	if len(args) > 0 && args[0] == "assign-ids" {
+		if err := runAssignIDs(args[1:]); err != nil {
+			if errors.Is(err, flag.ErrHelp) {
+				return
+			}
+			fmt.Fprintf(os.Stderr, "Assigning IDs failed:\n%v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+	if len(args) > 0 && (args[0] == "encrypt" || args[0] == "decrypt") {
+		if err := runCrypt(args[0], args[1:]); err != nil {
+			if errors.Is(err, flag.ErrHelp) {
+				return
+			}
+			fmt.Fprintf(os.Stderr, "Rewriting data files failed:\n%v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+	if len(args) > 0 && args[0] == "import" {
+		if err := runImport(args[1:]); err != nil {
+			if errors.Is(err, flag.ErrHelp) {
+				return
+			}
+			fmt.Fprintf(os.Stderr, "Import failed:\n%v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	cfg, err := loadConfig(args)
+	if err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return
+		}
+		log.Fatalf("Invalid configuration: %v", err)
+	}
+	configuredRings = ringSegments(cfg.Radar.Rings)
+	if err := useEncryptionKey(cfg); err != nil {
+		log.Fatalf("Invalid configuration: %v", err)
+	}
+	if err := prepareGitData(cfg.Data); err != nil {
+		log.Fatalf("Failed to check out radar data repository: %v", err)
+	}
+	if cfg.Store.enabled() {
+		// The data files are only read to seed a new store.
+		store, err := openStore(context.Background(), cfg)
+		if err != nil {
+			log.Fatalf("Failed to open %s store: %v", cfg.Store.Driver, err)
+		}
+		setStore(&databaseStore{db: store})
+		defer currentStore().Close()
+	} else if err := validateRadarData(cfg.Data.dataPath()); err != nil {
+		fmt.Fprintf(os.Stderr, "Invalid radar data:\n%v\n", err)
+		os.Exit(1)
+	}
+	if cfg.Check {
+		if cfg.Store.enabled() {
+			fmt.Printf("Configuration and %s store are valid\n", cfg.Store.Driver)
+		} else {
+			fmt.Printf("Configuration and radar data in %s are valid\n", cfg.Data.dataPath())
+		}
+		return
+	}
+	if cfg.Cache.enabled() {
+		cache, err := openRadarCache(cfg.Cache)
+		if err != nil {
+			log.Fatalf("Failed to open Redis cache: %v", err)
+		}
+		defer cache.Close()
+		activeCache.Store(cache)
+	}
+
+	handler := &swappableHandler{}
+	if err := applyConfig(cfg, handler); err != nil {
+		log.Fatalf("Failed to start: %v", err)
+	}
+	watchReloadSignal(args, handler)
+	if cfg.Backup.enabled() {
+		targets, err := backupTargets(cfg.Backup)
+		if err != nil {
+			log.Fatalf("Failed to start backups: %v", err)
+		}
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		go runScheduledBackups(ctx, cfg.Backup, targets)
+	}
+	if cfg.Sunset.enabled() {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		go runSunsetChecks(ctx, cfg)
+	}
+	if cfg.Reminders.enabled() {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		go runReviewReminders(ctx, cfg)
+	}
+	if cfg.Path != "" {
+		log.Printf("Loaded configuration from %s", cfg.Path)
+	}
+	build := buildInfo()
+	log.Printf("Clean Tech Radar %s (commit %s, built %s)", build.Version, build.ShortCommit(), build.BuildDate)
+	log.Printf("Effective configuration: %s", cfg.summary())
+	if cfg.Dev {
+		slog.Warn("Development mode: templates are re-parsed on every request and caching is disabled")
+	}
 
-	log.Printf("Server running at http://localhost%s", port)
-	if err := http.ListenAndServe(port, nil); err != nil {
-		log.Fatalf("Server failed to start: %v", err)
+	if err := runServer(cfg, handler); err != nil {
+		slog.Error("Server failed", "err", err)
+		os.Exit(1)
 	}
 }
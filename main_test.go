@@ -0,0 +1,82 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// doRequest runs a request against handler and returns the recorded response.
+func doRequest(t *testing.T, handler http.Handler, method, target string) *httptest.ResponseRecorder {
+	t.Helper()
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(method, target, nil))
+	return rec
+}
+
+func TestIndexHandlerTemplateReload(t *testing.T) {
+	for _, dev := range []bool{false, true} {
+		name := "production"
+		if dev {
+			name = "dev"
+		}
+		t.Run(name, func(t *testing.T) {
+			dir := t.TempDir()
+			path := filepath.Join(dir, indexTemplate)
+			if err := os.WriteFile(path, []byte("first {{.LastModified}}"), 0o644); err != nil {
+				t.Fatal(err)
+			}
+			cfg := defaultConfig()
+			cfg.Data.Templates = dir
+			cfg.Dev = dev
+			useConfig(t, cfg)
+
+			handler, err := newIndexHandler(cfg)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := os.WriteFile(path, []byte("second"), 0o644); err != nil {
+				t.Fatal(err)
+			}
+
+			body := doRequest(t, handler, http.MethodGet, "/").Body.String()
+			want := "first January 2024"
+			if dev {
+				want = "second"
+			}
+			if body != want {
+				t.Errorf("body = %q, want %q", body, want)
+			}
+		})
+	}
+}
+
+func TestSetupRoutesRejectsBrokenTemplate(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, indexTemplate), []byte("{{.Broken"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	cfg := defaultConfig()
+	cfg.Data.Templates = dir
+
+	if _, err := setupRoutes(cfg); err == nil || !strings.Contains(err.Error(), "loading templates") {
+		t.Fatalf("setupRoutes() error = %v, want template error", err)
+	}
+}
+
+func TestSetupRoutesDevDisablesCaching(t *testing.T) {
+	cfg := defaultConfig()
+	cfg.Dev = true
+	handler, err := setupRoutes(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rec := doRequest(t, handler, http.MethodGet, "/static/radar.js")
+	if got := rec.Header().Get("Cache-Control"); got != "no-store" {
+		t.Errorf("Cache-Control = %q, want no-store", got)
+	}
+}
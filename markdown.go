@@ -0,0 +1,158 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+)
+
+// markdownEscaper escapes the characters Markdown would read as formatting
+// in plain text.
+var markdownEscaper = strings.NewReplacer(`\`, `\\`, "`", "\\`", "*", `\*`, "_", `\_`, "[", `\[`, "]", `\]`, "<", `\<`, ">", `\>`, "|", `\|`)
+
+// markdownText returns the plain text s as Markdown on the lines of a list
+// item, indented by two spaces after the first.
+func markdownText(s string) string {
+	lines := strings.Split(strings.TrimSpace(s), "\n")
+	for i, line := range lines {
+		lines[i] = markdownEscaper.Replace(strings.TrimSpace(line))
+	}
+	return strings.Join(lines, "\n  ")
+}
+
+// markdownReport returns a Markdown report of items, with a section per
+// quadrant of data and in it one per ring holding items, listing their
+// descriptions and owners. Without page, items aren't linked to the radar;
+// with it, they link to page followed by their ID.
+func markdownReport(data RadarData, items []RadarItem, page string) string {
+	var b strings.Builder
+	b.WriteString("# Clean Tech Radar\n")
+	var dates []string
+	if data.LastModified != "" {
+		dates = append(dates, "Last updated "+markdownEscaper.Replace(data.LastModified)+".")
+	}
+	if data.ReviewDate != "" {
+		dates = append(dates, "Next review on "+data.ReviewDate+".")
+	}
+	if len(dates) > 0 {
+		b.WriteString("\n" + strings.Join(dates, " ") + "\n")
+	}
+
+	for _, quadrant := range data.Quadrants {
+		fmt.Fprintf(&b, "\n## %s\n", markdownEscaper.Replace(quadrant.Name))
+		if quadrant.Description != "" {
+			b.WriteString("\n" + markdownText(quadrant.Description) + "\n")
+		}
+		empty := true
+		for _, ring := range data.Rings {
+			var list strings.Builder
+			for _, item := range items {
+				if item.Quadrant != quadrant.Name || item.Ring != ring.Name {
+					continue
+				}
+				label := markdownEscaper.Replace(item.Label)
+				if page != "" {
+					label = fmt.Sprintf("[%s](%s%s)", label, page, url.PathEscape(item.ID))
+				}
+				list.WriteString("- **" + label + "**")
+				if item.Moved {
+					list.WriteString(" (moved)")
+				}
+				if item.Description != "" {
+					list.WriteString(": " + markdownText(item.Description))
+				}
+				if names := item.Owners.names(); names != "" {
+					list.WriteString("\n  Owned by " + markdownEscaper.Replace(names) + ".")
+				}
+				list.WriteString("\n")
+			}
+			if list.Len() > 0 {
+				fmt.Fprintf(&b, "\n### %s\n\n%s", markdownEscaper.Replace(ring.Name), list.String())
+				empty = false
+			}
+		}
+		if empty {
+			b.WriteString("\nNo items.\n")
+		}
+	}
+	return b.String()
+}
+
+// markdownHandler serves a Markdown report of the items selectItems
+// selects, ready to paste into a wiki, with items linked to the radar.
+func markdownHandler(w http.ResponseWriter, r *http.Request) {
+	data, err := loadRadarData()
+	if err != nil {
+		handleError(w, err)
+		return
+	}
+	data = withSegments(data)
+	items, err := selectItems(r, data)
+	if err != nil {
+		handleError(w, err)
+		return
+	}
+	page := requestOrigin(r) + currentConfig().Server.BasePath + "/#"
+	w.Header().Set("Content-Type", "text/markdown; charset=utf-8")
+	serveConditional(w, r, []byte(markdownReport(data, items, page)), dataModTime(r.Context()))
+}
+
+// runReport implements the report command, which writes the Markdown report
+// of the items that aren't archived in local data files to stdout, or to the
+// file given by -o, such as RADAR.md.
+func runReport(args []string, stdout io.Writer) error {
+	fs := flag.NewFlagSet("report", flag.ContinueOnError)
+	out := fs.String("o", "", "write the report to this file instead of stdout")
+	site := fs.String("url", "", "link items to the radar served at this URL")
+	configPath := fs.String("config", "", "path to a YAML config file giving the rings, encryption key and default data path (env RADAR_CONFIG)")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: clean-tech-radar report [-o file] [-url url] [-config file] [data path]")
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() > 1 {
+		fs.Usage()
+		return errors.New("expected at most one data path")
+	}
+	var configArgs []string
+	if *configPath != "" {
+		configArgs = []string{"-config", *configPath}
+	}
+	cfg, err := loadConfig(configArgs)
+	if err != nil {
+		return err
+	}
+	configuredRings = ringSegments(cfg.Radar.Rings)
+	if err := useEncryptionKey(cfg); err != nil {
+		return err
+	}
+	path := cfg.Data.dataPath()
+	if fs.NArg() == 1 {
+		path = fs.Arg(0)
+	}
+	if isRemoteDataPath(path) {
+		return errors.New("report requires local data files, not a URL")
+	}
+
+	data, err := readRadarData(path)
+	if err != nil {
+		return err
+	}
+	page := ""
+	if *site != "" {
+		page = strings.TrimSuffix(*site, "/") + "/#"
+	}
+	report := markdownReport(withSegments(data), visibleItems(data.Items), page)
+	if *out == "" {
+		_, err := io.WriteString(stdout, report)
+		return err
+	}
+	return os.WriteFile(*out, []byte(report), 0o644)
+}
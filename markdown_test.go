@@ -0,0 +1,80 @@
+package main
+
+import (
+	"bytes"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const markdownRadar = `LastModified: March 2025
+ReviewDate: "2025-06-30"
+Items:
+- Label: Go
+  Quadrant: Tools
+  Ring: Adopted
+  Moved: true
+  Description: |-
+    Fast and *simple*.
+    Compiles quickly.
+  Owners: Platform Team
+- Label: Perl
+  Quadrant: Tools
+  Ring: Not Recommended
+- Label: Old
+  Quadrant: Tools
+  Ring: Adopted
+  Archived: true
+`
+
+func TestMarkdownExport(t *testing.T) {
+	cfg := defaultConfig()
+	cfg.Data.Path = writeFile(t, "radar.yaml", markdownRadar)
+	useConfig(t, cfg)
+	handler, err := setupRoutes(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rec := doRequest(t, handler, http.MethodGet, "http://radar.example.com/api/v1/export.md?ring=Adopted")
+	if rec.Code != http.StatusOK || rec.Header().Get("Content-Type") != "text/markdown; charset=utf-8" {
+		t.Fatalf("GET /api/v1/export.md = %d %q", rec.Code, rec.Header().Get("Content-Type"))
+	}
+	want := "## Tools\n\n### Adopted\n\n" +
+		"- **[Go](http://radar.example.com/#go)** (moved): Fast and \\*simple\\*.\n  Compiles quickly.\n  Owned by Platform Team.\n" +
+		"\n## Programming Languages & Frameworks\n\nNo items.\n"
+	if body := rec.Body.String(); !strings.HasPrefix(body, "# Clean Tech Radar\n\nLast updated March 2025. Next review on 2025-06-30.\n") || !strings.Contains(body, want) || strings.Contains(body, "Perl") {
+		t.Errorf("report = %q, want it to contain %q", body, want)
+	}
+}
+
+func TestRunReport(t *testing.T) {
+	clearRadarEnv(t)
+	path := writeFile(t, "radar.yaml", markdownRadar)
+
+	var out bytes.Buffer
+	if err := runReport([]string{path}, &out); err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"- **Go** (moved): Fast", "### Not Recommended\n\n- **Perl**\n"} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("report = %q, want it to contain %q", out.String(), want)
+		}
+	}
+	if strings.Contains(out.String(), "Old") {
+		t.Errorf("report lists an archived item: %q", out.String())
+	}
+
+	file := filepath.Join(t.TempDir(), "RADAR.md")
+	if err := runReport([]string{"-o", file, "-url", "https://radar.example.com/", path}, &out); err != nil {
+		t.Fatal(err)
+	}
+	if content, err := os.ReadFile(file); err != nil || !strings.Contains(string(content), "[Go](https://radar.example.com/#go)") {
+		t.Errorf("RADAR.md = %q, %v", content, err)
+	}
+	if err := runReport([]string{"https://radar.example.com/radar.yaml"}, &out); err == nil {
+		t.Error("runReport(URL) = nil, want an error")
+	}
+}
@@ -0,0 +1,108 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestBasePathHandler(t *testing.T) {
+	inner := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(r.URL.Path))
+	})
+	handler := basePathHandler("/tech-radar", inner)
+
+	tests := []struct {
+		target       string
+		wantStatus   int
+		wantBody     string
+		wantLocation string
+	}{
+		{target: "/tech-radar/", wantStatus: http.StatusOK, wantBody: "/"},
+		{target: "/tech-radar/api/radar", wantStatus: http.StatusOK, wantBody: "/api/radar"},
+		{target: "/tech-radar", wantStatus: http.StatusMovedPermanently, wantLocation: "/tech-radar/"},
+		{target: "/api/radar", wantStatus: http.StatusNotFound},
+		{target: "/tech-radarx/", wantStatus: http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.target, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, tt.target, nil))
+
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d", rec.Code, tt.wantStatus)
+			}
+			if tt.wantBody != "" && rec.Body.String() != tt.wantBody {
+				t.Errorf("inner path = %q, want %q", rec.Body.String(), tt.wantBody)
+			}
+			if got := rec.Header().Get("Location"); got != tt.wantLocation {
+				t.Errorf("Location = %q, want %q", got, tt.wantLocation)
+			}
+		})
+	}
+}
+
+func TestProxyHeadersMiddleware(t *testing.T) {
+	var got *http.Request
+	handler := proxyHeadersMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r
+	}))
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("X-Forwarded-For", "203.0.113.7, 10.0.0.2")
+	req.Header.Set("X-Forwarded-Proto", "HTTPS")
+	req.Header.Set("X-Forwarded-Host", "radar.example.com")
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+
+	if got.RemoteAddr != "203.0.113.7" {
+		t.Errorf("RemoteAddr = %q, want 203.0.113.7", got.RemoteAddr)
+	}
+	if got.URL.Scheme != "https" {
+		t.Errorf("URL.Scheme = %q, want https", got.URL.Scheme)
+	}
+	if got.Host != "radar.example.com" {
+		t.Errorf("Host = %q, want radar.example.com", got.Host)
+	}
+}
+
+func TestProxyHeadersMiddlewareIgnoresInvalidProto(t *testing.T) {
+	var got *http.Request
+	handler := proxyHeadersMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r
+	}))
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("X-Forwarded-Proto", "javascript")
+	remote := req.RemoteAddr
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+
+	if got.URL.Scheme != "" || got.RemoteAddr != remote {
+		t.Errorf("request modified without forwarded headers: scheme %q, remote %q", got.URL.Scheme, got.RemoteAddr)
+	}
+}
+
+func TestBearerAuthMiddleware(t *testing.T) {
+	handler := bearerAuthMiddleware("s3cret", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("ok"))
+	}))
+
+	for header, want := range map[string]int{
+		"":              http.StatusUnauthorized,
+		"Bearer wrong":  http.StatusUnauthorized,
+		"Basic s3cret":  http.StatusUnauthorized,
+		"Bearer s3cret": http.StatusOK,
+	} {
+		req := httptest.NewRequest(http.MethodPost, "/", nil)
+		if header != "" {
+			req.Header.Set("Authorization", header)
+		}
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		if rec.Code != want {
+			t.Errorf("Authorization %q: status = %d, want %d", header, rec.Code, want)
+		}
+		if want == http.StatusUnauthorized && rec.Header().Get("WWW-Authenticate") == "" {
+			t.Errorf("Authorization %q: missing WWW-Authenticate header", header)
+		}
+	}
+}
@@ -0,0 +1,44 @@
+CREATE TABLE meta (
+    key   TEXT PRIMARY KEY,
+    value TEXT NOT NULL
+);
+
+CREATE TABLE items (
+    position    INTEGER NOT NULL,
+    label       TEXT    NOT NULL,
+    quadrant    TEXT    NOT NULL,
+    ring        TEXT    NOT NULL,
+    moved       BOOLEAN NOT NULL DEFAULT FALSE,
+    description TEXT    NOT NULL DEFAULT '',
+    owners      TEXT    NOT NULL DEFAULT ''
+);
+
+CREATE UNIQUE INDEX items_label ON items (lower(label));
+
+-- Timestamps are RFC 3339 text, as in the SQLite schema, so both stores
+-- share their queries.
+CREATE TABLE snapshots (
+    id         BIGSERIAL PRIMARY KEY,
+    created_at TEXT      NOT NULL,
+    data       TEXT      NOT NULL
+);
+
+CREATE TABLE edits (
+    id          BIGSERIAL PRIMARY KEY,
+    snapshot_id BIGINT    NOT NULL REFERENCES snapshots (id),
+    label       TEXT      NOT NULL,
+    action      TEXT      NOT NULL,
+    before      TEXT,
+    after       TEXT
+);
+
+CREATE INDEX edits_label ON edits (lower(label));
+
+CREATE TABLE audit_events (
+    id          BIGSERIAL PRIMARY KEY,
+    created_at  TEXT      NOT NULL,
+    actor       TEXT      NOT NULL,
+    action      TEXT      NOT NULL,
+    detail      TEXT      NOT NULL DEFAULT '',
+    snapshot_id BIGINT    REFERENCES snapshots (id)
+);
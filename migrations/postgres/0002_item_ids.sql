@@ -0,0 +1,5 @@
+-- Items saved before they had IDs keep an empty one until they are saved
+-- again.
+ALTER TABLE items ADD COLUMN id TEXT NOT NULL DEFAULT '';
+
+CREATE UNIQUE INDEX items_id ON items (id) WHERE id <> '';
@@ -0,0 +1 @@
+ALTER TABLE items ADD COLUMN tags TEXT NOT NULL DEFAULT '';
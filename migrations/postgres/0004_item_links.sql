@@ -0,0 +1 @@
+ALTER TABLE items ADD COLUMN links TEXT NOT NULL DEFAULT '';
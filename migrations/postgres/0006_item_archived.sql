@@ -0,0 +1 @@
+ALTER TABLE items ADD COLUMN archived BOOLEAN NOT NULL DEFAULT FALSE;
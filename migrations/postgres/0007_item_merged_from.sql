@@ -0,0 +1 @@
+ALTER TABLE items ADD COLUMN merged_from TEXT NOT NULL DEFAULT '';
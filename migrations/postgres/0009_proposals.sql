@@ -0,0 +1,9 @@
+-- Proposals are kept as JSON documents, so their fields can grow without
+-- migrations; only what is queried has a column.
+CREATE TABLE proposals (
+    id         BIGSERIAL PRIMARY KEY,
+    created_at TEXT      NOT NULL,
+    status     TEXT      NOT NULL,
+    token_hash TEXT      NOT NULL DEFAULT '',
+    data       TEXT      NOT NULL
+);
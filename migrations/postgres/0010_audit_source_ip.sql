@@ -0,0 +1,2 @@
+-- Earlier events have the client address, if any, at the end of their detail.
+ALTER TABLE audit_events ADD COLUMN source_ip TEXT NOT NULL DEFAULT '';
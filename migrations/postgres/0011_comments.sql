@@ -0,0 +1,11 @@
+-- Comments are kept as JSON documents like proposals; only what is queried
+-- has a column.
+CREATE TABLE comments (
+    id         BIGSERIAL PRIMARY KEY,
+    item_id    TEXT      NOT NULL,
+    created_at TEXT      NOT NULL,
+    token_hash TEXT      NOT NULL DEFAULT '',
+    data       TEXT      NOT NULL
+);
+
+CREATE INDEX comments_item_id ON comments (item_id);
@@ -0,0 +1,7 @@
+-- A vote per item and voter; voting again changes nothing.
+CREATE TABLE votes (
+    item_id    TEXT NOT NULL,
+    voter      TEXT NOT NULL,
+    created_at TEXT NOT NULL,
+    PRIMARY KEY (item_id, voter)
+);
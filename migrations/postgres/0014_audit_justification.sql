@@ -0,0 +1 @@
+ALTER TABLE audit_events ADD COLUMN justification TEXT NOT NULL DEFAULT '';
@@ -0,0 +1,2 @@
+ALTER TABLE items ADD COLUMN direction TEXT NOT NULL DEFAULT '';
+ALTER TABLE items ADD COLUMN previous_ring TEXT NOT NULL DEFAULT '';
@@ -0,0 +1 @@
+ALTER TABLE items ADD COLUMN relations TEXT NOT NULL DEFAULT '';
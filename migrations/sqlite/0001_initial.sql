@@ -0,0 +1,40 @@
+CREATE TABLE meta (
+    key   TEXT PRIMARY KEY,
+    value TEXT NOT NULL
+);
+
+CREATE TABLE items (
+    position    INTEGER NOT NULL,
+    label       TEXT    NOT NULL PRIMARY KEY COLLATE NOCASE,
+    quadrant    TEXT    NOT NULL,
+    ring        TEXT    NOT NULL,
+    moved       BOOLEAN NOT NULL DEFAULT FALSE,
+    description TEXT    NOT NULL DEFAULT '',
+    owners      TEXT    NOT NULL DEFAULT ''
+);
+
+CREATE TABLE snapshots (
+    id         INTEGER PRIMARY KEY AUTOINCREMENT,
+    created_at TEXT    NOT NULL,
+    data       TEXT    NOT NULL
+);
+
+CREATE TABLE edits (
+    id          INTEGER PRIMARY KEY AUTOINCREMENT,
+    snapshot_id INTEGER NOT NULL REFERENCES snapshots (id),
+    label       TEXT    NOT NULL,
+    action      TEXT    NOT NULL,
+    before      TEXT,
+    after       TEXT
+);
+
+CREATE INDEX edits_label ON edits (label COLLATE NOCASE);
+
+CREATE TABLE audit_events (
+    id          INTEGER PRIMARY KEY AUTOINCREMENT,
+    created_at  TEXT    NOT NULL,
+    actor       TEXT    NOT NULL,
+    action      TEXT    NOT NULL,
+    detail      TEXT    NOT NULL DEFAULT '',
+    snapshot_id INTEGER REFERENCES snapshots (id)
+);
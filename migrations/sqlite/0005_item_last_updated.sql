@@ -0,0 +1 @@
+ALTER TABLE items ADD COLUMN last_updated TEXT NOT NULL DEFAULT '';
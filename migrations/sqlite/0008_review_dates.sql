@@ -0,0 +1 @@
+ALTER TABLE items ADD COLUMN review_date TEXT NOT NULL DEFAULT '';
@@ -0,0 +1,8 @@
+-- A reaction per item, user and kind of reaction.
+CREATE TABLE reactions (
+    item_id    TEXT NOT NULL,
+    reaction   TEXT NOT NULL,
+    user_name  TEXT NOT NULL,
+    created_at TEXT NOT NULL,
+    PRIMARY KEY (item_id, reaction, user_name)
+);
@@ -0,0 +1 @@
+ALTER TABLE items ADD COLUMN sunset_date TEXT NOT NULL DEFAULT '';
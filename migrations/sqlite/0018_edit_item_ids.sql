@@ -0,0 +1,5 @@
+-- The ID of the item an edit changed, so the audit log can be filtered by
+-- item in the query. Existing edits are filled in by backfillEditItemIDs.
+ALTER TABLE edits ADD COLUMN item_id TEXT NOT NULL DEFAULT '';
+
+CREATE INDEX edits_item_id ON edits (item_id);
@@ -0,0 +1,295 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Kinds of change a proposal makes.
+const (
+	changeNewItem = "new-item"
+	changeEdit    = "edit"
+	changeRing    = "ring-change"
+)
+
+// reviewAssign is the action assigning a proposal to reviewers.
+const reviewAssign = "assign"
+
+// pendingStates are the states of proposals awaiting a decision.
+var pendingStates = []string{proposalProposed, proposalUnderReview, proposalApproved}
+
+// kind returns the kind of change p makes.
+func (p Proposal) kind() string {
+	switch {
+	case p.ItemID == "":
+		return changeNewItem
+	case p.Item == nil:
+		return changeRing
+	}
+	return changeEdit
+}
+
+// Types of entries in the moderation queue.
+const (
+	moderationProposal = "proposal"
+	moderationComment  = "comment"
+)
+
+// ModerationEntry is a proposal awaiting a decision, with the kind of change
+// it makes, or a comment awaiting approval in the moderation queue, with
+// _links to the review actions that apply to it.
+type ModerationEntry struct {
+	Type     string             `json:"type"`
+	Kind     string             `json:"kind,omitempty"`
+	Proposal *Proposal          `json:"proposal,omitempty"`
+	Comment  *Comment           `json:"comment,omitempty"`
+	Links    map[string]HALLink `json:"_links"`
+}
+
+// ModerationCounts counts the entries of the moderation queue, in total and
+// by type, and the proposals by state and kind and those assigned to nobody.
+type ModerationCounts struct {
+	Total      int            `json:"total"`
+	ByType     map[string]int `json:"byType"`
+	ByState    map[string]int `json:"byState"`
+	ByKind     map[string]int `json:"byKind"`
+	Unassigned int            `json:"unassigned"`
+}
+
+// ModerationQueue is the response of GET /moderation.
+type ModerationQueue struct {
+	Counts  ModerationCounts  `json:"counts"`
+	Entries []ModerationEntry `json:"entries"`
+}
+
+// moderationEntry returns the entry of p in the moderation queue.
+func moderationEntry(p Proposal) ModerationEntry {
+	self := apiURL("/proposals/" + strconv.FormatInt(p.ID, 10))
+	links := map[string]HALLink{"self": {self}, reviewAssign: {self + "/" + reviewAssign}}
+	for action, states := range reviewFrom {
+		if slices.Contains(states, p.Status) {
+			links[action] = HALLink{self + "/" + action}
+		}
+	}
+	return ModerationEntry{Type: moderationProposal, Kind: p.kind(), Proposal: &p, Links: links}
+}
+
+// commentEntry returns the entry of c in the moderation queue, with _links
+// to approve it with POST and to delete it with DELETE.
+func commentEntry(c Comment) ModerationEntry {
+	self := apiURL(fmt.Sprintf("/items/%s/comments/%d", c.ItemID, c.ID))
+	links := map[string]HALLink{"self": {self}, reviewApprove: {self + "/" + reviewApprove}, "delete": {self}}
+	return ModerationEntry{Type: moderationComment, Comment: &c, Links: links}
+}
+
+// created returns when the proposal or comment of e was made.
+func (e ModerationEntry) created() time.Time {
+	if e.Comment != nil {
+		return e.Comment.Created
+	}
+	return e.Proposal.Created
+}
+
+// moderationHandler lists the proposals awaiting a decision and the comments
+// neither approved nor deleted, oldest first, for reviewers: only those of
+// the type given as ?type, and only the proposals of the kind, in the state
+// and assigned to the reviewer given as ?kind, ?status and ?assignee if set.
+// The counts are those of the entries listed.
+func moderationHandler(w http.ResponseWriter, r *http.Request) {
+	if _, err := signedInReviewer(w, r); err != nil {
+		handleError(w, err)
+		return
+	}
+	query := r.URL.Query()
+	typ, kind, status, assignee := query.Get("type"), query.Get("kind"), query.Get("status"), query.Get("assignee")
+	if typ != "" && typ != moderationProposal && typ != moderationComment {
+		handleError(w, &AppError{Code: http.StatusBadRequest, Message: fmt.Sprintf("Invalid type %q, must be one of %s, %s", typ, moderationProposal, moderationComment)})
+		return
+	}
+	if status != "" && !slices.Contains(pendingStates, status) {
+		handleError(w, &AppError{Code: http.StatusBadRequest, Message: fmt.Sprintf("Invalid status %q, must be one of %s", status, strings.Join(pendingStates, ", "))})
+		return
+	}
+	if kind != "" && kind != changeNewItem && kind != changeEdit && kind != changeRing {
+		handleError(w, &AppError{Code: http.StatusBadRequest, Message: fmt.Sprintf("Invalid kind %q, must be one of %s, %s, %s", kind, changeNewItem, changeEdit, changeRing)})
+		return
+	}
+	db, err := proposalDB()
+	if err != nil {
+		handleError(w, err)
+		return
+	}
+
+	queue := ModerationQueue{
+		Counts:  ModerationCounts{ByType: make(map[string]int), ByState: make(map[string]int), ByKind: make(map[string]int)},
+		Entries: []ModerationEntry{},
+	}
+	if typ != moderationComment {
+		proposals, err := db.Proposals(r.Context())
+		if err != nil {
+			handleError(w, &AppError{Code: http.StatusInternalServerError, Message: "Failed to read proposals", Err: err})
+			return
+		}
+		for _, p := range proposals {
+			switch {
+			case !slices.Contains(pendingStates, p.Status),
+				status != "" && p.Status != status,
+				kind != "" && p.kind() != kind,
+				assignee != "" && !slices.ContainsFunc(p.Assignees, func(a string) bool { return strings.EqualFold(a, assignee) }):
+				continue
+			}
+			entry := moderationEntry(p)
+			queue.Entries = append(queue.Entries, entry)
+			queue.Counts.ByState[p.Status]++
+			queue.Counts.ByKind[entry.Kind]++
+			if len(p.Assignees) == 0 {
+				queue.Counts.Unassigned++
+			}
+		}
+	}
+	// Comments have no kind, state or assignees, so those filters leave them
+	// out.
+	if typ != moderationProposal && kind == "" && status == "" && assignee == "" {
+		comments, err := db.Comments(r.Context(), "")
+		if err != nil {
+			handleError(w, &AppError{Code: http.StatusInternalServerError, Message: "Failed to read comments", Err: err})
+			return
+		}
+		for _, c := range comments {
+			if c.Deleted == nil && c.Reviewed == nil {
+				queue.Entries = append(queue.Entries, commentEntry(c))
+			}
+		}
+	}
+	slices.SortStableFunc(queue.Entries, func(a, b ModerationEntry) int { return a.created().Compare(b.created()) })
+	for _, entry := range queue.Entries {
+		queue.Counts.Total++
+		queue.Counts.ByType[entry.Type]++
+	}
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(queue); err != nil {
+		log.Printf("Failed to encode moderation queue: %v", err)
+	}
+}
+
+// AssignInput is the body of POST /proposals/{id}/assign: the names of the
+// reviewers to assign, replacing any assigned before, or none to unassign
+// the proposal.
+type AssignInput struct {
+	Reviewers []string `json:"reviewers"`
+}
+
+// reviewerNamed returns the name of the configured reviewer, or "admin",
+// called name ignoring case.
+func reviewerNamed(name string) (string, bool) {
+	for _, reviewer := range currentConfig().Review.Reviewers {
+		if strings.EqualFold(reviewer.Name, name) {
+			return reviewer.Name, true
+		}
+	}
+	if strings.EqualFold(name, "admin") && currentConfig().Admin.Token != "" {
+		return "admin", true
+	}
+	return "", false
+}
+
+// assignHandler assigns the proposal with the ID given by the path, while
+// it awaits a decision, to the reviewers of the AssignInput in the body,
+// recorded as a Decision, and responds with it.
+func assignHandler(w http.ResponseWriter, r *http.Request) {
+	reviewer, err := signedInReviewer(w, r)
+	if err != nil {
+		handleError(w, err)
+		return
+	}
+	body, err := readItemBody(w, r)
+	if err != nil {
+		handleError(w, err)
+		return
+	}
+	dec := json.NewDecoder(bytes.NewReader(body))
+	dec.DisallowUnknownFields()
+	var input AssignInput
+	if err := dec.Decode(&input); err != nil {
+		handleError(w, &AppError{Code: http.StatusBadRequest, Message: "Invalid assignment: " + err.Error(), Err: err})
+		return
+	}
+	var assignees []string
+	for _, name := range input.Reviewers {
+		assignee, ok := reviewerNamed(name)
+		if !ok {
+			handleError(w, &AppError{Code: http.StatusBadRequest, Message: fmt.Sprintf("Unknown reviewer %q", name)})
+			return
+		}
+		if !slices.Contains(assignees, assignee) {
+			assignees = append(assignees, assignee)
+		}
+	}
+	db, err := proposalDB()
+	if err != nil {
+		handleError(w, err)
+		return
+	}
+
+	proposalMu.Lock()
+	defer proposalMu.Unlock()
+	p, err := findProposal(r.Context(), db, r)
+	if err != nil {
+		handleError(w, err)
+		return
+	}
+	if !slices.Contains(pendingStates, p.Status) {
+		handleError(w, &AppError{Code: http.StatusConflict, Message: fmt.Sprintf("Proposal %d is %s", p.ID, p.Status)})
+		return
+	}
+	now := time.Now().UTC().Truncate(time.Second)
+	p.Assignees, p.Updated = assignees, now
+	p.Decisions = append(p.Decisions, Decision{Action: reviewAssign, Reviewer: reviewer.Name, Roles: reviewer.Roles, Comment: strings.Join(assignees, ", "), Time: now})
+	if p, err = db.SaveProposal(r.Context(), p); err != nil {
+		handleError(w, &AppError{Code: http.StatusInternalServerError, Message: "Failed to save proposal", Err: err})
+		return
+	}
+	log.Printf("Proposal %d assigned to %q by %s", p.ID, assignees, reviewer.Name)
+	writeProposal(w, http.StatusOK, p)
+}
+
+// moderationPageData is the context passed to the moderation template.
+type moderationPageData struct {
+	BasePath string
+	// Reviewers are the names the queue can be narrowed to the proposals
+	// assigned to.
+	Reviewers []string
+}
+
+// newModerationPageHandler returns the handler serving the page showing
+// reviewers the moderation queue, which it reads from GET /moderation with
+// the reviewer token entered, and taking the review actions of its _links.
+func newModerationPageHandler(cfg Config) (http.Handler, error) {
+	load, err := templateLoader(cfg, moderationTemplate)
+	if err != nil {
+		return nil, err
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		tmpl, err := load()
+		if err != nil {
+			handleError(w, &AppError{Code: http.StatusInternalServerError, Message: "Failed to load template", Err: err})
+			return
+		}
+		page := moderationPageData{BasePath: cfg.Server.BasePath}
+		for _, reviewer := range currentConfig().Review.Reviewers {
+			page.Reviewers = append(page.Reviewers, reviewer.Name)
+		}
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		if err := tmpl.Execute(w, page); err != nil {
+			handleError(w, &AppError{Code: http.StatusInternalServerError, Message: "Failed to render template", Err: err})
+		}
+	}), nil
+}
@@ -0,0 +1,133 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestModeration(t *testing.T) {
+	db := openTestStore(t, "sqlite", "file::memory:")
+	seed := RadarData{Items: []RadarItem{{Label: "Go", Quadrant: "Tools", Ring: "In Discovery"}}}
+	if err := db.Save(context.Background(), seed, AuditEvent{Actor: "test", Action: "seed"}); err != nil {
+		t.Fatal(err)
+	}
+	cfg := defaultConfig()
+	cfg.Admin.Token = "s3cret"
+	cfg.Review.Reviewers = []ReviewerConfig{{Name: "Jane", Token: "jane-token"}, {Name: "Joe", Token: "joe-token"}}
+	useConfig(t, cfg)
+	useStore(t, &databaseStore{db: db})
+	handler, err := setupRoutes(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	send := func(method, target, token, body string) *httptest.ResponseRecorder {
+		t.Helper()
+		req := httptest.NewRequest(method, target, strings.NewReader(body))
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		return rec
+	}
+	for _, body := range []string{
+		`{"item": {"label": "Rust", "quadrant": "Tools", "ring": "In Discovery"}, "author": "Ann"}`,
+		`{"itemId": "go", "item": {"label": "Go", "quadrant": "Tools", "ring": "In Discovery", "description": "Fast."}, "author": "Ann"}`,
+		`{"itemId": "go", "ring": "Adopted", "author": "Ann"}`,
+		`{"itemId": "go", "ring": "Not Recommended", "author": "Ann"}`,
+	} {
+		if rec := send(http.MethodPost, "/api/v1/proposals", "", body); rec.Code != http.StatusCreated {
+			t.Fatalf("POST %s = %d %s", body, rec.Code, rec.Body)
+		}
+	}
+	for _, body := range []string{`{"author": "Ann", "body": "Why?"}`, `{"author": "Bob", "body": "Spam"}`, `{"author": "Cy", "body": "Ok"}`} {
+		if rec := send(http.MethodPost, "/api/v1/items/go/comments", "", body); rec.Code != http.StatusCreated {
+			t.Fatalf("POST comment %s = %d %s", body, rec.Code, rec.Body)
+		}
+	}
+	if rec := send(http.MethodPost, "/api/v1/items/go/comments/1/approve", "jane-token", ""); rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), `"reviewedBy":"Jane"`) {
+		t.Errorf("approve comment = %d %s", rec.Code, rec.Body)
+	}
+	if rec := send(http.MethodPost, "/api/v1/items/go/comments/1/approve", "jane-token", ""); rec.Code != http.StatusConflict {
+		t.Errorf("approve comment again = %d, want 409", rec.Code)
+	}
+	if rec := send(http.MethodPost, "/api/v1/items/go/comments/2/approve", "", ""); rec.Code != http.StatusUnauthorized {
+		t.Errorf("approve comment without token = %d, want 401", rec.Code)
+	}
+	send(http.MethodDelete, "/api/v1/items/go/comments/2", "jane-token", "")
+	send(http.MethodPost, "/api/v1/proposals/3/review", "jane-token", "")
+	send(http.MethodPost, "/api/v1/proposals/4/reject", "jane-token", "")
+
+	rec := send(http.MethodPost, "/api/v1/proposals/3/assign", "jane-token", `{"reviewers": ["joe", "Joe", "admin"]}`)
+	var p Proposal
+	if err := json.Unmarshal(rec.Body.Bytes(), &p); err != nil || rec.Code != http.StatusOK {
+		t.Fatalf("assign = %d %s", rec.Code, rec.Body)
+	}
+	if strings.Join(p.Assignees, ",") != "Joe,admin" || p.Decisions[len(p.Decisions)-1].Action != reviewAssign {
+		t.Errorf("assigned proposal = %+v", p)
+	}
+	for _, bad := range []struct {
+		target, body string
+		code         int
+	}{
+		{"/api/v1/proposals/3/assign", `{"reviewers": ["Mallory"]}`, http.StatusBadRequest},
+		{"/api/v1/proposals/4/assign", `{"reviewers": ["Joe"]}`, http.StatusConflict},
+		{"/api/v1/proposals/9/assign", `{"reviewers": ["Joe"]}`, http.StatusNotFound},
+	} {
+		if rec := send(http.MethodPost, bad.target, "jane-token", bad.body); rec.Code != bad.code {
+			t.Errorf("POST %s %s = %d, want %d", bad.target, bad.body, rec.Code, bad.code)
+		}
+	}
+
+	queue := func(target string) ModerationQueue {
+		t.Helper()
+		rec := send(http.MethodGet, target, "joe-token", "")
+		var queue ModerationQueue
+		if err := json.Unmarshal(rec.Body.Bytes(), &queue); err != nil || rec.Code != http.StatusOK {
+			t.Fatalf("GET %s = %d %s", target, rec.Code, rec.Body)
+		}
+		return queue
+	}
+	all := queue("/api/v1/moderation")
+	if c := all.Counts; c.Total != 4 || c.ByType[moderationProposal] != 3 || c.ByType[moderationComment] != 1 || c.ByState[proposalProposed] != 2 || c.ByState[proposalUnderReview] != 1 || c.Unassigned != 2 ||
+		c.ByKind[changeNewItem] != 1 || c.ByKind[changeEdit] != 1 || c.ByKind[changeRing] != 1 {
+		t.Errorf("counts = %+v", c)
+	}
+	if len(all.Entries) != 4 || all.Entries[2].Proposal.ID != 3 || all.Entries[2].Kind != changeRing {
+		t.Fatalf("entries = %+v", all.Entries)
+	}
+	// Only the comment neither approved nor deleted awaits a reviewer.
+	if e := all.Entries[3]; e.Type != moderationComment || e.Comment == nil || e.Comment.ID != 3 ||
+		e.Links["approve"].Href != "/api/v1/items/go/comments/3/approve" || e.Links["delete"].Href != "/api/v1/items/go/comments/3" {
+		t.Errorf("comment entry = %+v", e)
+	}
+	if comments := queue("/api/v1/moderation?type=comment"); comments.Counts.Total != 1 || comments.Entries[0].Comment.Author != "Cy" {
+		t.Errorf("comments = %+v", comments)
+	}
+	links := all.Entries[2].Links
+	if links["approve"].Href != "/api/v1/proposals/3/approve" || links["reject"].Href == "" || links["review"].Href != "" || links["self"].Href != "/api/v1/proposals/3" {
+		t.Errorf("links of a proposal under review = %+v", links)
+	}
+	if mine := queue("/api/v1/moderation?assignee=JOE"); mine.Counts.Total != 1 || mine.Entries[0].Proposal.ID != 3 {
+		t.Errorf("assigned to Joe = %+v", mine)
+	}
+	if edits := queue("/api/v1/moderation?kind=edit&status=proposed"); edits.Counts.Total != 1 || edits.Entries[0].Proposal.ID != 2 {
+		t.Errorf("proposed edits = %+v", edits)
+	}
+
+	if rec := send(http.MethodGet, "/api/v1/moderation", "", ""); rec.Code != http.StatusUnauthorized {
+		t.Errorf("GET /moderation without token = %d", rec.Code)
+	}
+	for _, target := range []string{"/api/v1/moderation?status=rejected", "/api/v1/moderation?type=vote"} {
+		if rec := send(http.MethodGet, target, "joe-token", ""); rec.Code != http.StatusBadRequest {
+			t.Errorf("GET %s = %d, want 400", target, rec.Code)
+		}
+	}
+	if rec := send(http.MethodGet, "/moderation", "", ""); rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), `"Joe"`) {
+		t.Errorf("GET /moderation page = %d %s", rec.Code, rec.Body)
+	}
+}
@@ -0,0 +1,54 @@
+package main
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+)
+
+// ndjsonContentType is the content type of newline-delimited JSON.
+const ndjsonContentType = "application/x-ndjson"
+
+// ndjsonHandler streams the items selectItems selects as newline-delimited
+// JSON, one item per line with the fields parseFields asks for, flushing
+// each line so clients can process them as they arrive. Errors found after
+// the first line end the stream, as its status has been sent.
+func ndjsonHandler(w http.ResponseWriter, r *http.Request) {
+	fields, err := parseFields(r, itemFields)
+	if err != nil {
+		handleError(w, err)
+		return
+	}
+	data, err := loadRadarData()
+	if err != nil {
+		handleError(w, err)
+		return
+	}
+	items, err := selectItems(r, withSegments(data))
+	if err != nil {
+		handleError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", ndjsonContentType)
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.WriteHeader(http.StatusOK)
+	rc := http.NewResponseController(w)
+	enc := json.NewEncoder(w)
+	for _, item := range items {
+		var line any = item
+		if fields != nil {
+			if line, err = sparseItem(item, fields); err != nil {
+				slog.Error("Streaming items failed", "err", err)
+				return
+			}
+		}
+		// Writes only fail once the client is gone.
+		if err := enc.Encode(line); err != nil {
+			return
+		}
+		// Responses can't be flushed through a request timeout, which
+		// buffers them.
+		rc.Flush()
+	}
+}
@@ -0,0 +1,64 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestItemsNDJSON(t *testing.T) {
+	cfg := defaultConfig()
+	cfg.Data.Path = writeFile(t, "radar.yaml", `Items:
+- Label: Go
+  Quadrant: Tools
+  Ring: Adopted
+  Tags: [backend]
+- Label: Rust
+  Quadrant: Tools
+  Ring: In Discovery
+- Label: Perl
+  Quadrant: Tools
+  Ring: Not Recommended
+  Archived: true
+`)
+	useConfig(t, cfg)
+	handler, err := setupRoutes(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rec := doRequest(t, handler, http.MethodGet, "/api/v1/radar/items.ndjson?sort=label&order=desc")
+	if ct := rec.Header().Get("Content-Type"); rec.Code != http.StatusOK || ct != ndjsonContentType {
+		t.Fatalf("GET /api/v1/radar/items.ndjson = %d %q", rec.Code, ct)
+	}
+	if !rec.Flushed {
+		t.Error("GET /api/v1/radar/items.ndjson wasn't flushed")
+	}
+	var labels []string
+	scanner := bufio.NewScanner(rec.Body)
+	for scanner.Scan() {
+		var item RadarItem
+		if err := json.Unmarshal(scanner.Bytes(), &item); err != nil {
+			t.Fatalf("line %q: %v", scanner.Text(), err)
+		}
+		labels = append(labels, item.Label)
+	}
+	if want := []string{"Rust", "Go"}; !reflect.DeepEqual(labels, want) {
+		t.Errorf("GET /api/v1/radar/items.ndjson labels = %v, want %v", labels, want)
+	}
+
+	rec = doRequest(t, handler, http.MethodGet, "/api/v1/radar/items.ndjson?fields=id,tags&includeArchived=true&ring=Adopted&ring=Not+Recommended")
+	want := `{"id":"go","tags":["backend"]}` + "\n" + `{"id":"perl","tags":[]}` + "\n"
+	if rec.Body.String() != want {
+		t.Errorf("GET /api/v1/radar/items.ndjson with fields = %q, want %q", rec.Body.String(), want)
+	}
+
+	// Errors found before streaming are served as JSON.
+	rec = doRequest(t, handler, http.MethodGet, "/api/v1/radar/items.ndjson?ring=Hold")
+	if rec.Code != http.StatusBadRequest || !strings.HasPrefix(rec.Body.String(), `{"error":"Invalid ring`) {
+		t.Errorf("GET /api/v1/radar/items.ndjson?ring=Hold = %d %q", rec.Code, rec.Body.String())
+	}
+}
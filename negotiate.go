@@ -0,0 +1,214 @@
+package main
+
+import (
+	"bytes"
+	"cmp"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"mime"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// formatCSV is the CSV response format. CSV isn't a data file format, as
+// it has no room for quadrants and rings.
+const formatCSV = "csv"
+
+// responseFormats are the formats GET /api/radar can respond in, in order
+// of preference, with their content types.
+var responseFormats = []struct {
+	format, contentType string
+	// mediaTypes are the media types of the Accept header asking for it.
+	mediaTypes []string
+}{
+	{formatJSON, "application/json", []string{"application/json"}},
+	{formatYAML, "application/yaml", []string{"application/yaml", "application/x-yaml", "text/yaml", "text/x-yaml"}},
+	{formatCSV, "text/csv; charset=utf-8", []string{"text/csv"}},
+	{formatJSONAPI, "application/vnd.api+json", []string{"application/vnd.api+json"}},
+}
+
+// responseFormatNames returns the names of responseFormats.
+func responseFormatNames() []string {
+	var names []string
+	for _, f := range responseFormats {
+		names = append(names, f.format)
+	}
+	return names
+}
+
+// negotiateFormat returns the format the request asks for with ?format=,
+// or else the one its Accept header prefers. Without either, or with only
+// wildcards, it is JSON.
+func negotiateFormat(r *http.Request) (string, error) {
+	if format := r.URL.Query().Get("format"); format != "" {
+		if !slices.Contains(responseFormatNames(), format) {
+			return "", &AppError{Code: http.StatusBadRequest, Message: fmt.Sprintf("Invalid format %q, must be one of %s", format, strings.Join(responseFormatNames(), ", "))}
+		}
+		return format, nil
+	}
+	accept := strings.Join(r.Header.Values("Accept"), ",")
+	if strings.TrimSpace(accept) == "" {
+		return formatJSON, nil
+	}
+
+	type choice struct {
+		format string
+		q      float64
+		// specific is 2 for a media type, 1 for type/* and 0 for */*.
+		specific, index int
+	}
+	var choices []choice
+	for index, part := range strings.Split(accept, ",") {
+		mediaType, params, err := mime.ParseMediaType(strings.TrimSpace(part))
+		if err != nil {
+			continue
+		}
+		q := 1.0
+		if value, ok := params["q"]; ok {
+			if q, err = strconv.ParseFloat(value, 64); err != nil || q <= 0 {
+				continue
+			}
+		}
+		for _, f := range responseFormats {
+			for _, t := range f.mediaTypes {
+				switch major, _, _ := strings.Cut(t, "/"); mediaType {
+				case t:
+					choices = append(choices, choice{f.format, q, 2, index})
+				case major + "/*":
+					choices = append(choices, choice{f.format, q, 1, index})
+				case "*/*":
+					choices = append(choices, choice{f.format, q, 0, index})
+				}
+			}
+		}
+	}
+	if len(choices) == 0 {
+		return "", &AppError{Code: http.StatusNotAcceptable, Message: fmt.Sprintf("Not acceptable, the radar is served as %s", strings.Join(responseFormatNames(), ", "))}
+	}
+	best := slices.MinFunc(choices, func(a, b choice) int {
+		return cmp.Or(cmp.Compare(b.q, a.q), b.specific-a.specific, a.index-b.index)
+	})
+	return best.format, nil
+}
+
+// writeResponse writes body, a value encoding to a JSON object, in format
+// with serveConditional. YAML has the same fields as JSON, and JSON:API is
+// JSON of a JSONAPIDocument body. CSV has a row of the fields of each of
+// items, taken from body, with header columns.
+func writeResponse(w http.ResponseWriter, r *http.Request, format string, body any, items []map[string]json.RawMessage, columns []string) {
+	var content []byte
+	var err error
+	switch format {
+	case formatYAML:
+		content, err = jsonToYAML(body)
+	case formatCSV:
+		content, err = itemsCSV(items, columns)
+	default:
+		if format != formatJSONAPI {
+			format = formatJSON
+		}
+		var buf bytes.Buffer
+		err = json.NewEncoder(&buf).Encode(body)
+		content = buf.Bytes()
+	}
+	if err != nil {
+		handleError(w, &AppError{Code: http.StatusInternalServerError, Message: "Failed to encode response", Err: err})
+		return
+	}
+	for _, f := range responseFormats {
+		if f.format == format {
+			w.Header().Set("Content-Type", f.contentType)
+		}
+	}
+	serveConditional(w, r, content, dataModTime(r.Context()))
+}
+
+// jsonToYAML encodes v as YAML with the keys, and in the order, of its JSON
+// encoding.
+func jsonToYAML(v any) ([]byte, error) {
+	content, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	// JSON is YAML, so decoding it as a node keeps the order of the keys,
+	// in the flow style and quoting of JSON, which block style replaces.
+	var node yaml.Node
+	if err := yaml.Unmarshal(content, &node); err != nil {
+		return nil, err
+	}
+	var plain func(n *yaml.Node)
+	plain = func(n *yaml.Node) {
+		n.Style = 0
+		for _, c := range n.Content {
+			plain(c)
+		}
+	}
+	plain(&node)
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(&node); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), enc.Close()
+}
+
+// itemsCSV encodes the columns of items as CSV with a header.
+func itemsCSV(items []map[string]json.RawMessage, columns []string) ([]byte, error) {
+	var buf bytes.Buffer
+	cw := csv.NewWriter(&buf)
+	cw.Write(columns)
+	for _, item := range items {
+		record := make([]string, len(columns))
+		for i, column := range columns {
+			var err error
+			if record[i], err = csvValue(item[column]); err != nil {
+				return nil, err
+			}
+		}
+		cw.Write(record)
+	}
+	cw.Flush()
+	return buf.Bytes(), cw.Error()
+}
+
+// csvValue returns the text of a JSON value in a CSV cell. Lists are
+// comma-separated, owners by name and links by URL.
+func csvValue(raw json.RawMessage) (string, error) {
+	if raw == nil {
+		return "", nil
+	}
+	var v any
+	if err := json.Unmarshal(raw, &v); err != nil {
+		return "", err
+	}
+	var text func(v any) string
+	text = func(v any) string {
+		switch v := v.(type) {
+		case nil:
+			return ""
+		case string:
+			return v
+		case []any:
+			values := make([]string, len(v))
+			for i, e := range v {
+				values[i] = text(e)
+			}
+			return strings.Join(values, ", ")
+		case map[string]any:
+			for _, key := range []string{"name", "url"} {
+				if s, ok := v[key].(string); ok {
+					return s
+				}
+			}
+		}
+		content, _ := json.Marshal(v)
+		return string(content)
+	}
+	return text(v), nil
+}
@@ -0,0 +1,114 @@
+package main
+
+import (
+	"encoding/csv"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+func TestNegotiateFormat(t *testing.T) {
+	tests := []struct {
+		target, accept string
+		want           string
+		code           int
+	}{
+		{"/api/radar", "", formatJSON, 0},
+		{"/api/radar", "text/html,application/xhtml+xml,*/*;q=0.8", formatJSON, 0},
+		{"/api/radar", "application/yaml", formatYAML, 0},
+		{"/api/radar", "application/json;q=0.5, text/csv", formatCSV, 0},
+		{"/api/radar", "text/*, application/json;q=0.9", formatYAML, 0},
+		{"/api/radar", "text/csv;q=0, */*", formatJSON, 0},
+		{"/api/radar?format=csv", "application/json", formatCSV, 0},
+		{"/api/radar", "application/vnd.api+json", formatJSONAPI, 0},
+		{"/api/radar?format=jsonapi", "", formatJSONAPI, 0},
+		{"/api/radar", "image/png", "", http.StatusNotAcceptable},
+		{"/api/radar?format=xml", "", "", http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		r := httptest.NewRequest(http.MethodGet, tt.target, nil)
+		if tt.accept != "" {
+			r.Header.Set("Accept", tt.accept)
+		}
+		got, err := negotiateFormat(r)
+		if tt.code != 0 {
+			if e, ok := err.(*AppError); !ok || e.Code != tt.code {
+				t.Errorf("negotiateFormat(%s, Accept %q) error = %v, want %d", tt.target, tt.accept, err, tt.code)
+			}
+			continue
+		}
+		if err != nil || got != tt.want {
+			t.Errorf("negotiateFormat(%s, Accept %q) = %q, %v, want %q", tt.target, tt.accept, got, err, tt.want)
+		}
+	}
+}
+
+func TestRadarFormats(t *testing.T) {
+	cfg := defaultConfig()
+	cfg.Data.Path = writeFile(t, "radar.yaml", `Items:
+- Label: Go
+  Quadrant: Tools
+  Ring: Adopted
+  Description: "Fast, \"simple\"."
+  Owners: [{Name: Ana}, {Name: Bo}]
+  Tags: [backend, lang]
+  Links: [{Title: Site, URL: "https://go.dev"}]
+- Label: "true"
+  Quadrant: Tools
+  Ring: In Discovery
+`)
+	useConfig(t, cfg)
+	handler, err := setupRoutes(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/api/radar?fields=label,owners,tags", nil)
+	req.Header.Set("Accept", "application/yaml")
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	if ct := rec.Header().Get("Content-Type"); ct != "application/yaml" || !strings.Contains(rec.Header().Get("Vary"), "Accept") {
+		t.Errorf("GET /api/radar as YAML: Content-Type %q, Vary %q", ct, rec.Header().Get("Vary"))
+	}
+	var body struct {
+		Rings []Segment
+		Items []map[string]any
+	}
+	if err := yaml.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+		t.Fatalf("GET /api/radar as YAML = %q: %v", rec.Body.String(), err)
+	}
+	want := []map[string]any{
+		{"label": "Go", "owners": []any{map[string]any{"name": "Ana"}, map[string]any{"name": "Bo"}}, "tags": []any{"backend", "lang"}},
+		{"label": "true", "owners": []any{}, "tags": []any{}},
+	}
+	if len(body.Rings) != 3 || !reflect.DeepEqual(body.Items, want) {
+		t.Errorf("GET /api/radar as YAML = %+v, want rings and items %v", body, want)
+	}
+
+	rec = doRequest(t, handler, http.MethodGet, "/api/radar?format=csv&fields=label,ring,description,owners,tags,links,moved")
+	if ct := rec.Header().Get("Content-Type"); ct != "text/csv; charset=utf-8" {
+		t.Errorf("GET /api/radar?format=csv: Content-Type %q", ct)
+	}
+	records, err := csv.NewReader(rec.Body).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	wantRecords := [][]string{
+		{"label", "ring", "description", "owners", "tags", "links", "moved"},
+		{"Go", "Adopted", `Fast, "simple".`, "Ana, Bo", "backend, lang", "https://go.dev", "false"},
+		{"true", "In Discovery", "", "", "", "", "false"},
+	}
+	if !reflect.DeepEqual(records, wantRecords) {
+		t.Errorf("GET /api/radar?format=csv = %q, want %q", records, wantRecords)
+	}
+
+	// Without fields, CSV has a column for every field.
+	rec = doRequest(t, handler, http.MethodGet, "/api/radar?format=csv&ring=adopted")
+	if records, err := csv.NewReader(rec.Body).ReadAll(); err != nil || len(records) != 2 || !reflect.DeepEqual(records[0], itemFields) {
+		t.Errorf("GET /api/radar?format=csv&ring=adopted = %q, %v", records, err)
+	}
+}
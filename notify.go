@@ -0,0 +1,125 @@
+package main
+
+import (
+	"bytes"
+	"cmp"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net"
+	"net/http"
+	"net/mail"
+	"net/smtp"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// notifyClient sends notifications.
+var notifyClient = &http.Client{Timeout: 30 * time.Second}
+
+// Notification is a message about the radar, such as items nearing their
+// sunset, sent to the channels of the NotificationConfig.
+type Notification struct {
+	// Event names what happened, such as sunset-upcoming.
+	Event string `json:"event"`
+	// Subject is the subject of the email, if it is emailed.
+	Subject string      `json:"subject,omitempty"`
+	Text    string      `json:"text"`
+	Items   []RadarItem `json:"items,omitempty"`
+	// To are the email addresses of the people it is for, such as the
+	// owners of the items. Only those notifications are emailed.
+	To []string `json:"to,omitempty"`
+}
+
+// enabled reports whether notifications are sent anywhere.
+func (n NotificationConfig) enabled() bool {
+	return n.Webhook != "" || n.Slack != "" || n.Email.SMTP != ""
+}
+
+// notify sends n to every channel of cfg: as JSON to the webhook, its text
+// as a Slack message and as an email to n.To. It carries on with the other
+// channels when one fails, returning the first error.
+func notify(ctx context.Context, cfg NotificationConfig, n Notification) error {
+	var first error
+	failed := func(channel string, err error) {
+		if err != nil && first == nil {
+			first = fmt.Errorf("%s: %w", channel, err)
+		}
+	}
+	if cfg.Webhook != "" {
+		failed("webhook", postJSON(ctx, cfg.Webhook, n))
+	}
+	if cfg.Slack != "" {
+		failed("slack", postJSON(ctx, cfg.Slack, map[string]string{"text": n.Text}))
+	}
+	if cfg.Email.SMTP != "" && len(n.To) > 0 {
+		failed("email", sendEmail(cfg.Email, n))
+	}
+	return first
+}
+
+// sendEmail sends the text of n as a plain text email to n.To through the
+// SMTP server of cfg.
+func sendEmail(cfg EmailConfig, n Notification) error {
+	from, err := mail.ParseAddress(cfg.From)
+	if err != nil {
+		return err
+	}
+	to := make([]string, len(n.To))
+	for i, addr := range n.To {
+		// Checked, as a line break would add headers.
+		parsed, err := mail.ParseAddress(addr)
+		if err != nil {
+			return fmt.Errorf("recipient %q: %w", addr, err)
+		}
+		to[i] = parsed.Address
+	}
+	subject := cmp.Or(n.Subject, "Tech radar: "+n.Event)
+	var msg strings.Builder
+	fmt.Fprintf(&msg, "From: %s\r\n", from)
+	fmt.Fprintf(&msg, "To: %s\r\n", strings.Join(to, ", "))
+	fmt.Fprintf(&msg, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", subject))
+	fmt.Fprintf(&msg, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	msg.WriteString("MIME-Version: 1.0\r\nContent-Type: text/plain; charset=utf-8\r\n\r\n")
+	msg.WriteString(strings.ReplaceAll(n.Text, "\n", "\r\n") + "\r\n")
+
+	var auth smtp.Auth
+	if cfg.Username != "" {
+		host, _, _ := net.SplitHostPort(cfg.SMTP)
+		auth = smtp.PlainAuth("", cfg.Username, cfg.Password, host)
+	}
+	return smtp.SendMail(cfg.SMTP, auth, from.Address, to, []byte(msg.String()))
+}
+
+// postJSON posts body as JSON to target, failing unless it answers with a
+// 2xx status.
+func postJSON(ctx context.Context, target string, body any) error {
+	encoded, err := json.Marshal(body)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, target, bytes.NewReader(encoded))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := notifyClient.Do(req)
+	var urlErr *url.Error
+	if errors.As(err, &urlErr) {
+		// The error would show the URL, and the token in it.
+		return urlErr.Err
+	}
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 1<<16))
+	if resp.StatusCode/100 != 2 {
+		return fmt.Errorf("answered %s", resp.Status)
+	}
+	return nil
+}
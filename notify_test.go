@@ -0,0 +1,119 @@
+package main
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/textproto"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestNotifyFailures(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "no", http.StatusForbidden)
+	}))
+	t.Cleanup(server.Close)
+
+	n := Notification{Event: sunsetUpcoming, Text: "Sunset within 30 days: Go (2024-06-01)"}
+	err := notify(context.Background(), NotificationConfig{Slack: server.URL + "/services/s3cret"}, n)
+	if err == nil || err.Error() != "slack: answered 403 Forbidden" {
+		t.Errorf("notify() = %v", err)
+	}
+	// Errors don't show the URL, which holds a token.
+	err = notify(context.Background(), NotificationConfig{Webhook: "http://127.0.0.1:1/hooks/s3cret"}, n)
+	if err == nil || strings.Contains(err.Error(), "s3cret") {
+		t.Errorf("notify() to a closed port = %v", err)
+	}
+}
+
+// smtpMessage is an email received by the server of smtpServer.
+type smtpMessage struct {
+	To   []string
+	Data string
+}
+
+// smtpServer starts an SMTP server for the test and returns its address and
+// a function returning the emails it received.
+func smtpServer(t *testing.T) (string, func() []smtpMessage) {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { ln.Close() })
+	var mu sync.Mutex
+	var received []smtpMessage
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			text := textproto.NewConn(conn)
+			text.PrintfLine("220 localhost")
+			var msg smtpMessage
+			for {
+				line, err := text.ReadLine()
+				if err != nil {
+					break
+				}
+				switch verb, arg, _ := strings.Cut(line, " "); strings.ToUpper(verb) {
+				case "RCPT":
+					msg.To = append(msg.To, strings.Trim(strings.TrimPrefix(arg, "TO:"), "<>"))
+					text.PrintfLine("250 OK")
+				case "DATA":
+					text.PrintfLine("354 Go ahead")
+					data, _ := text.ReadDotBytes()
+					msg.Data = string(data)
+					mu.Lock()
+					received = append(received, msg)
+					mu.Unlock()
+					msg = smtpMessage{}
+					text.PrintfLine("250 OK")
+				case "QUIT":
+					text.PrintfLine("221 Bye")
+				default:
+					text.PrintfLine("250 OK")
+				}
+			}
+			text.Close()
+		}
+	}()
+	return ln.Addr().String(), func() []smtpMessage {
+		mu.Lock()
+		defer mu.Unlock()
+		return slices.Clone(received)
+	}
+}
+
+func TestNotifyEmail(t *testing.T) {
+	addr, received := smtpServer(t)
+	cfg := NotificationConfig{Email: EmailConfig{SMTP: addr, From: "Tech Radar <radar@example.com>"}}
+	n := Notification{Event: reviewDue, Subject: "Items due for review", Text: "Jane: Go is due for review by 2024-06-01", To: []string{"jane@example.com"}}
+	if err := notify(context.Background(), cfg, n); err != nil {
+		t.Fatal(err)
+	}
+	got := received()
+	if len(got) != 1 || !slices.Equal(got[0].To, []string{"jane@example.com"}) {
+		t.Fatalf("emails = %+v", got)
+	}
+	for _, want := range []string{"Subject: Items due for review\n", "To: jane@example.com\n", "\n\nJane: Go is due for review by 2024-06-01"} {
+		if !strings.Contains(got[0].Data, want) {
+			t.Errorf("email = %q, want %q in it", got[0].Data, want)
+		}
+	}
+
+	// Notifications for no one aren't emailed, and addresses can't add
+	// headers.
+	if err := notify(context.Background(), cfg, Notification{Event: sunsetUpcoming, Text: "Sunset"}); err != nil || len(received()) != 1 {
+		t.Errorf("notify() without recipients = %v, emails %+v", err, received())
+	}
+	n.To = []string{"jane@example.com\r\nBcc: eve@example.com"}
+	if err := notify(context.Background(), cfg, n); err == nil || len(received()) != 1 {
+		t.Errorf("notify() to a bad address = %v, emails %+v", err, received())
+	}
+}
@@ -0,0 +1,170 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// oEmbedVersion is the version of the oEmbed specification responses
+// follow.
+const oEmbedVersion = "1.0"
+
+// Sizes of oEmbed previews in pixels, unless the consumer asks for smaller
+// ones with ?maxwidth= and ?maxheight=.
+const (
+	oEmbedRadarSize  = 600
+	oEmbedCardWidth  = 400
+	oEmbedCardHeight = 160
+)
+
+// oEmbedDescriptionLength is the number of characters of an item's
+// description shown on its preview card.
+const oEmbedDescriptionLength = 280
+
+// oEmbedResponse is a rich oEmbed response, the HTML of a preview of a
+// radar URL.
+type oEmbedResponse struct {
+	Type         string `json:"type"`
+	Version      string `json:"version"`
+	Title        string `json:"title"`
+	ProviderName string `json:"provider_name"`
+	ProviderURL  string `json:"provider_url"`
+	HTML         string `json:"html"`
+	Width        int    `json:"width"`
+	Height       int    `json:"height"`
+}
+
+// oEmbedCard is the preview card of an item.
+var oEmbedCard = template.Must(template.New("card").Parse(`<blockquote style="margin:0;padding:12px 16px;border-left:4px solid #9ca3af;font-family:sans-serif;font-size:14px">` +
+	`<p style="margin:0 0 4px;font-size:16px;font-weight:bold"><a href="{{.URL}}" target="_blank" rel="noopener">{{.Label}}</a></p>` +
+	`<p style="margin:0 0 8px;color:#6b7280">{{.Ring}} &middot; {{.Quadrant}}</p>` +
+	`{{with .Description}}<p style="margin:0">{{.}}</p>{{end}}</blockquote>`))
+
+// requestOrigin returns the scheme and host r was sent to, such as
+// https://radar.example.com.
+func requestOrigin(r *http.Request) string {
+	scheme := r.URL.Scheme
+	if scheme == "" {
+		scheme = "http"
+		if r.TLS != nil {
+			scheme = "https"
+		}
+	}
+	return scheme + "://" + r.Host
+}
+
+// excerpt returns s cut to at most n characters, with an ellipsis if cut.
+func excerpt(s string, n int) string {
+	runes := []rune(strings.TrimSpace(s))
+	if len(runes) <= n {
+		return string(runes)
+	}
+	return strings.TrimSpace(string(runes[:n])) + "…"
+}
+
+// oEmbedDimension returns the size in pixels of a preview dimension: def,
+// or the ?name= of r if smaller.
+func oEmbedDimension(r *http.Request, name string, def int) (int, error) {
+	value := r.URL.Query().Get(name)
+	if value == "" {
+		return def, nil
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil || n <= 0 {
+		return 0, &AppError{Code: http.StatusBadRequest, Message: fmt.Sprintf("Invalid %s %q, must be a positive number of pixels", name, value)}
+	}
+	return min(n, def), nil
+}
+
+// oEmbedHandler serves the oEmbed preview of the radar page or /embed given
+// as ?url=, showing the radar with the filters of the URL in a frame, or of
+// an item linked to by the fragment of the page URL such as /#go, as a card
+// with its ring, quadrant and description. Only JSON responses are
+// supported.
+func oEmbedHandler(w http.ResponseWriter, r *http.Request) {
+	if format := r.URL.Query().Get("format"); format != "" && format != "json" {
+		handleError(w, &AppError{Code: http.StatusNotImplemented, Message: fmt.Sprintf("Unsupported format %q, only json is supported", format)})
+		return
+	}
+	maxWidth, err := oEmbedDimension(r, "maxwidth", oEmbedRadarSize)
+	if err != nil {
+		handleError(w, err)
+		return
+	}
+	maxHeight, err := oEmbedDimension(r, "maxheight", oEmbedRadarSize)
+	if err != nil {
+		handleError(w, err)
+		return
+	}
+	notFound := &AppError{Code: http.StatusNotFound, Message: fmt.Sprintf("No preview for %q", r.URL.Query().Get("url"))}
+	origin, base := requestOrigin(r), currentConfig().Server.BasePath
+	target, err := url.Parse(r.URL.Query().Get("url"))
+	if err != nil || !target.IsAbs() || !strings.EqualFold(target.Scheme+"://"+target.Host, origin) || (target.Path != base+"/" && target.Path != base+"/embed") {
+		handleError(w, notFound)
+		return
+	}
+	data, err := loadRadarData()
+	if err != nil {
+		handleError(w, err)
+		return
+	}
+	data = withSegments(data)
+	filters := target.Query()
+	filters.Del("size")
+	if _, err := parseRadarFilter(filters, data); err != nil {
+		handleError(w, notFound)
+		return
+	}
+
+	preview := oEmbedResponse{Type: "rich", Version: oEmbedVersion, Title: "Clean Tech Radar", ProviderName: "Clean Tech Radar", ProviderURL: origin + base + "/"}
+	if target.Fragment == "" {
+		size := min(maxWidth, maxHeight)
+		if size < minEmbedSize {
+			handleError(w, &AppError{Code: http.StatusNotFound, Message: fmt.Sprintf("No preview fits in %dx%d pixels", maxWidth, maxHeight)})
+			return
+		}
+		filters.Set("size", strconv.Itoa(size))
+		src := origin + base + "/embed?" + filters.Encode()
+		preview.HTML = fmt.Sprintf(`<iframe src="%s" width="%d" height="%d" style="border:0" title="Clean Tech Radar" loading="lazy"></iframe>`, template.HTMLEscapeString(src), size, size)
+		preview.Width, preview.Height = size, size
+	} else {
+		item, ok := findItem(visibleItems(data.Items), target.Fragment)
+		if !ok {
+			handleError(w, notFound)
+			return
+		}
+		var card bytes.Buffer
+		err := oEmbedCard.Execute(&card, map[string]string{
+			"URL":         origin + base + "/#" + url.PathEscape(item.ID),
+			"Label":       item.Label,
+			"Ring":        item.Ring,
+			"Quadrant":    item.Quadrant,
+			"Description": excerpt(item.Description, oEmbedDescriptionLength),
+		})
+		if err != nil {
+			handleError(w, &AppError{Code: http.StatusInternalServerError, Message: "Failed to render preview", Err: err})
+			return
+		}
+		preview.Title = item.Label + " – " + item.Ring
+		preview.HTML = card.String()
+		preview.Width, preview.Height = min(maxWidth, oEmbedCardWidth), min(maxHeight, oEmbedCardHeight)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(preview); err != nil {
+		handleError(w, &AppError{Code: http.StatusInternalServerError, Message: "Failed to encode response", Err: err})
+	}
+}
+
+// oEmbedDiscoveryURL returns the URL of the oEmbed preview of the page r
+// requested, for the page to link to so consumers find it.
+func oEmbedDiscoveryURL(r *http.Request) string {
+	page := requestOrigin(r) + requestURL(r).RequestURI()
+	return apiURL("/oembed?" + url.Values{"url": {page}, "format": {"json"}}.Encode())
+}
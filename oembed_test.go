@@ -0,0 +1,87 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+func TestOEmbed(t *testing.T) {
+	cfg := defaultConfig()
+	cfg.Server.BasePath = "/tech-radar"
+	cfg.Data.Path = writeFile(t, "radar.yaml", `Items:
+- Label: Go
+  Quadrant: Tools
+  Ring: Adopted
+  Description: Fast <and> simple.
+`)
+	useConfig(t, cfg)
+	handler, err := setupRoutes(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	oEmbed := func(target string, params ...string) (*oEmbedResponse, int) {
+		t.Helper()
+		query := url.Values{"url": {target}}
+		for i := 0; i < len(params); i += 2 {
+			query.Set(params[i], params[i+1])
+		}
+		rec := doRequest(t, handler, http.MethodGet, "http://radar.example.com/tech-radar/api/v1/oembed?"+query.Encode())
+		if rec.Code != http.StatusOK {
+			return nil, rec.Code
+		}
+		var preview oEmbedResponse
+		if err := json.Unmarshal(rec.Body.Bytes(), &preview); err != nil {
+			t.Fatal(err)
+		}
+		return &preview, rec.Code
+	}
+
+	preview, code := oEmbed("http://radar.example.com/tech-radar/?quadrant=Tools", "maxwidth", "500")
+	if code != http.StatusOK {
+		t.Fatalf("oEmbed of the radar = %d", code)
+	}
+	if preview.Type != "rich" || preview.Version != "1.0" || preview.Width != 500 || preview.Height != 500 ||
+		!strings.Contains(preview.HTML, `<iframe src="http://radar.example.com/tech-radar/embed?quadrant=Tools&amp;size=500" width="500" height="500"`) {
+		t.Errorf("oEmbed of the radar = %+v", preview)
+	}
+
+	preview, code = oEmbed("http://radar.example.com/tech-radar/#go")
+	if code != http.StatusOK {
+		t.Fatalf("oEmbed of an item = %d", code)
+	}
+	for _, want := range []string{`href="http://radar.example.com/tech-radar/#go"`, ">Go</a>", "Adopted &middot; Tools", "Fast &lt;and&gt; simple."} {
+		if !strings.Contains(preview.HTML, want) {
+			t.Errorf("oEmbed card %q doesn't contain %q", preview.HTML, want)
+		}
+	}
+	if preview.Title != "Go – Adopted" || preview.Width != oEmbedCardWidth {
+		t.Errorf("oEmbed of an item = %+v", preview)
+	}
+
+	for _, tt := range []struct {
+		params []string
+		want   int
+	}{
+		{[]string{"url", "http://radar.example.com/tech-radar/#rust"}, http.StatusNotFound},
+		{[]string{"url", "http://other.example.com/tech-radar/"}, http.StatusNotFound},
+		{[]string{"url", "http://radar.example.com/tech-radar/api/v1/radar"}, http.StatusNotFound},
+		{[]string{"url", "http://radar.example.com/tech-radar/?ring=Hold"}, http.StatusNotFound},
+		{[]string{"url", "http://radar.example.com/tech-radar/", "maxwidth", "100"}, http.StatusNotFound},
+		{[]string{"url", "http://radar.example.com/tech-radar/", "maxwidth", "wide"}, http.StatusBadRequest},
+		{[]string{"url", "http://radar.example.com/tech-radar/", "format", "xml"}, http.StatusNotImplemented},
+	} {
+		if _, code := oEmbed(tt.params[1], tt.params[2:]...); code != tt.want {
+			t.Errorf("oEmbed %v = %d, want %d", tt.params, code, tt.want)
+		}
+	}
+
+	// The pages link to their preview.
+	rec := doRequest(t, handler, http.MethodGet, "http://radar.example.com/tech-radar/")
+	want := `<link rel="alternate" type="application/json+oembed" href="/tech-radar/api/v1/oembed?format=json&amp;url=http%3A%2F%2Fradar.example.com%2Ftech-radar%2F"`
+	if !strings.Contains(rec.Body.String(), want) {
+		t.Errorf("GET / doesn't contain %s", want)
+	}
+}
@@ -0,0 +1,861 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"html/template"
+	"net/http"
+	"reflect"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// openAPIVersion is the version of the OpenAPI specification the API is
+// described in.
+const openAPIVersion = "3.0.3"
+
+// swaggerUIVersion is the version of Swagger UI the API docs page loads.
+const swaggerUIVersion = "5.17.14"
+
+// apiContent is a body of a request or response in one media type. Schema
+// is either a schema as a map or a Go value whose type, as encoded by
+// encoding/json, describes the body.
+type apiContent struct {
+	mediaType string
+	schema    any
+}
+
+// apiParam is a query parameter of an operation.
+type apiParam struct {
+	name, description string
+	schema            map[string]any
+	required          bool
+}
+
+// apiOperation documents an endpoint registered with handleAPI.
+type apiOperation struct {
+	// pattern is the pattern the endpoint is registered with, without the
+	// API prefix. Patterns without a method are documented as GET.
+	pattern, summary string
+	params           []apiParam
+	request          []apiContent
+	// status is the status of a successful response, 200 if zero.
+	status   int
+	response []apiContent
+	// admin endpoints require the admin token.
+	admin bool
+	// enabled reports whether the endpoint is served with a config, always
+	// if nil.
+	enabled func(cfg Config) bool
+}
+
+// Schemas of the values of parameters and bodies.
+var (
+	stringSchema  = map[string]any{"type": "string"}
+	booleanSchema = map[string]any{"type": "boolean"}
+	binarySchema  = map[string]any{"type": "string", "format": "binary"}
+	uploadSchema  = map[string]any{"type": "object", "properties": map[string]any{"file": binarySchema}, "required": []string{"file"}}
+)
+
+// enumSchema returns the schema of a string that is one of values.
+func enumSchema(values ...string) map[string]any {
+	return map[string]any{"type": "string", "enum": values}
+}
+
+// integerSchema returns the schema of an integer between minimum and
+// maximum, defaulting to def.
+func integerSchema(minimum, maximum, def int) map[string]any {
+	return map[string]any{"type": "integer", "minimum": minimum, "maximum": maximum, "default": def}
+}
+
+// arraySchema returns the schema of a repeated parameter of items.
+func arraySchema(items map[string]any) map[string]any {
+	return map[string]any{"type": "array", "items": items}
+}
+
+// itemParams are the parameters selecting and sorting the items of the
+// radar, see selectItems, and the fields of them to respond with.
+var itemParams = []apiParam{
+	{name: "quadrant", description: "Only items in one of these quadrants.", schema: arraySchema(stringSchema)},
+	{name: "ring", description: "Only items in one of these rings.", schema: arraySchema(stringSchema)},
+	{name: "owner", description: "Only items with one of these owners, by name, team or email address.", schema: arraySchema(stringSchema)},
+	{name: "tag", description: "Only items with all of these tags.", schema: arraySchema(stringSchema)},
+	{name: "moved", description: "Only items that have, or haven't, moved ring.", schema: booleanSchema},
+	{name: "includeArchived", description: "Include archived items.", schema: booleanSchema},
+	{name: "sort", description: "Sort items by a field, by label and ID when equal.", schema: enumSchema(itemOrderNames()...)},
+	{name: "order", description: "Sort order, asc if not given. Requires sort.", schema: enumSchema("asc", "desc")},
+	{name: "fields", description: "Only these fields of each item, comma-separated or repeated.", schema: arraySchema(enumSchema(itemFields...))},
+}
+
+// selectParams are the parameters of selectItems, for endpoints serving
+// the items in a format of their own.
+var selectParams = slices.DeleteFunc(slices.Clone(itemParams), func(p apiParam) bool { return p.name == "fields" })
+
+// adminEnabled reports whether the admin endpoints are served.
+func adminEnabled(cfg Config) bool {
+	return cfg.Admin.Token != ""
+}
+
+// apiOperations document every endpoint registered in setupRoutes.
+var apiOperations = []apiOperation{
+	{
+		pattern: "GET /radar",
+		summary: "Radar data, with the quadrants, rings and the selected items",
+		params: append(slices.Clone(itemParams),
+			apiParam{name: "format", description: "Response format, taking precedence over the Accept header.", schema: enumSchema(responseFormatNames()...)},
+		),
+		response: []apiContent{
+			{"application/json", RadarData{}},
+			{"application/yaml", RadarData{}},
+			{"text/csv", stringSchema},
+			{"application/vnd.api+json", JSONAPIDocument{}},
+		},
+	},
+	{
+		pattern: "GET /radar/items",
+		summary: "A page of the selected items",
+		params: append(slices.Clone(itemParams),
+			apiParam{name: "limit", description: "Number of items per page.", schema: integerSchema(1, maxPageLimit, defaultPageLimit)},
+			apiParam{name: "offset", description: "Number of items to skip.", schema: map[string]any{"type": "integer", "minimum": 0, "default": 0}},
+		),
+		response: []apiContent{{"application/json", struct {
+			Items  []LinkedItem       `json:"items"`
+			Total  int                `json:"total"`
+			Limit  int                `json:"limit"`
+			Offset int                `json:"offset"`
+			Links  map[string]HALLink `json:"_links"`
+		}{}}},
+	},
+	{
+		pattern:  "GET /radar/items.ndjson",
+		summary:  "The selected items as newline-delimited JSON, one item per line",
+		params:   itemParams,
+		response: []apiContent{{ndjsonContentType, RadarItem{}}},
+	},
+	{
+		pattern:  "GET /radar/items/{id}",
+		summary:  "An item with its history",
+		params:   []apiParam{{name: "fields", description: "Only these fields of the item, comma-separated or repeated.", schema: arraySchema(enumSchema(itemDetailFields...))}},
+		response: []apiContent{{"application/json", LinkedItemDetail{}}},
+	},
+	{
+		pattern: "GET /tags",
+		summary: "Tags of the items, with their number of items",
+		response: []apiContent{{"application/json", struct {
+			Tags []TagCount `json:"tags"`
+		}{}}},
+	},
+	{
+		pattern:  "GET /stats",
+		summary:  "Number of items by ring, quadrant and owner",
+		response: []apiContent{{"application/json", RadarStats{}}},
+	},
+	{
+		pattern: "GET /quadrants",
+		summary: "Quadrants, in order, with their number of items",
+		response: []apiContent{{"application/json", struct {
+			Quadrants []SegmentInfo `json:"quadrants"`
+		}{}}},
+	},
+	{
+		pattern: "GET /rings",
+		summary: "Rings, from the innermost, with their number of items",
+		response: []apiContent{{"application/json", struct {
+			Rings []SegmentInfo `json:"rings"`
+		}{}}},
+	},
+	{
+		pattern: "GET /search",
+		summary: "Items matching a query, best match first",
+		params: []apiParam{
+			{name: "q", description: "Words to search labels, tags, owners and descriptions for.", schema: stringSchema, required: true},
+			{name: "limit", description: "Number of results.", schema: integerSchema(1, maxSearchLimit, defaultSearchLimit)},
+			{name: "includeArchived", description: "Include archived items.", schema: booleanSchema},
+		},
+		response: []apiContent{{"application/json", struct {
+			Query   string             `json:"query"`
+			Total   int                `json:"total"`
+			Results []SearchResult     `json:"results"`
+			Links   map[string]HALLink `json:"_links"`
+		}{}}},
+	},
+	{
+		pattern: "GET /suggest",
+		summary: "Labels and tags starting with the text typed so far",
+		params: []apiParam{
+			{name: "q", description: "Text typed so far.", schema: stringSchema, required: true},
+			{name: "limit", description: "Number of suggestions.", schema: integerSchema(1, maxSuggestLimit, defaultSuggestLimit)},
+		},
+		response: []apiContent{{"application/json", struct {
+			Query       string       `json:"query"`
+			Suggestions []Suggestion `json:"suggestions"`
+		}{}}},
+	},
+	{
+		pattern: "GET /history",
+		summary: "Git history of every item",
+		response: []apiContent{{"application/json", struct {
+			Items []ItemHistory `json:"items"`
+		}{}}},
+	},
+	{
+		pattern:  "GET /history/{item}",
+		summary:  "Git history of an item, by ID or label",
+		response: []apiContent{{"application/json", ItemHistory{}}},
+	},
+	{
+		pattern: "GET /changes",
+		summary: "Changes to the items since a time, oldest first",
+		params:  []apiParam{{name: "since", description: "Only changes made at or after this time.", schema: map[string]any{"type": "string", "format": "date-time"}}},
+		response: []apiContent{{"application/json", struct {
+			Changes []ItemChange `json:"changes"`
+		}{}}},
+	},
+	{
+		pattern: "GET /sunsets",
+		summary: "Items whose sunset is upcoming or past, soonest first",
+		params:  []apiParam{{name: "days", description: "Only items whose sunset is at most this many days away; sunset.notice by default.", schema: map[string]any{"type": "integer", "minimum": 0}}},
+		response: []apiContent{{"application/json", struct {
+			Items []SunsetItem `json:"items"`
+		}{}}},
+	},
+	{
+		pattern: "GET /reviews/overdue",
+		summary: "Items past their review date, most overdue first",
+		params:  selectParams,
+		response: []apiContent{{"application/json", struct {
+			Items []OverdueReview `json:"items"`
+		}{}}},
+	},
+	{
+		pattern: "GET /reports/stale",
+		summary: "Items whose description and ring haven't changed in a number of days, grouped by owner",
+		params:  []apiParam{{name: "days", description: "Only items unchanged for at least this many days; 180 by default.", schema: map[string]any{"type": "integer", "minimum": 0}}},
+		response: []apiContent{{"application/json", struct {
+			Days   int          `json:"days"`
+			Owners []StaleOwner `json:"owners"`
+		}{}}},
+	},
+	{
+		pattern:  "GET /backstage/tech-radar",
+		summary:  "The radar in the format of the Backstage TechRadar plugin",
+		params:   selectParams,
+		response: []apiContent{{"application/json", backstageRadar{}}},
+	},
+	{
+		pattern:  "GET /export/zalando.json",
+		summary:  "The radar in the format of the Zalando tech radar visualization",
+		params:   selectParams,
+		response: []apiContent{{"application/json", zalandoRadar{}}},
+	},
+	{
+		pattern:  "GET /export/byor.json",
+		summary:  "The selected items as a Build Your Own Radar JSON sheet",
+		params:   selectParams,
+		response: []apiContent{{"application/json", []byorEntry{}}},
+	},
+	{
+		pattern:  "GET /export/byor.csv",
+		summary:  "The selected items as a Build Your Own Radar CSV file",
+		params:   selectParams,
+		response: []apiContent{{"text/csv", stringSchema}},
+	},
+	{
+		pattern:  "GET /export.xlsx",
+		summary:  "The selected items as an Excel workbook with a sheet per quadrant",
+		params:   selectParams,
+		response: []apiContent{{xlsxContentType, binarySchema}},
+	},
+	{
+		pattern:  "GET /export.md",
+		summary:  "A Markdown report of the selected items by quadrant and ring",
+		params:   selectParams,
+		response: []apiContent{{"text/markdown", stringSchema}},
+	},
+	{
+		pattern: "GET /export.pdf",
+		summary: "A PDF report of the selected items, with a summary page, a section per quadrant and the change log",
+		params: append(slices.Clone(selectParams),
+			apiParam{name: "since", description: "List the changes made since this RFC 3339 time rather than those of the latest save or commit.", schema: map[string]any{"type": "string", "format": "date-time"}},
+		),
+		response: []apiContent{{"application/pdf", binarySchema}},
+	},
+	{
+		pattern: "GET /proposals",
+		summary: "Proposed changes to the radar, oldest first",
+		params: []apiParam{
+			{name: "status", description: "Only list the proposals in this state, and no comments.", schema: enumSchema(proposalProposed, proposalUnderReview, proposalApproved, proposalPublished, proposalRejected, proposalWithdrawn)},
+		},
+		response: []apiContent{{"application/json", struct {
+			Proposals []Proposal `json:"proposals"`
+		}{}}},
+	},
+	{
+		pattern:  "POST /proposals",
+		summary:  "Propose a new item, a change to an item or a ring move, without changing the radar",
+		request:  []apiContent{{"application/json", ProposalInput{}}},
+		status:   http.StatusCreated,
+		response: []apiContent{{"application/json", ProposalReceipt{}}},
+	},
+	{
+		pattern:  "GET /proposals/{id}",
+		summary:  "A proposal",
+		response: []apiContent{{"application/json", Proposal{}}},
+	},
+	{
+		pattern:  "PUT /proposals/{id}",
+		summary:  "Edit a proposal, with its token as the bearer token",
+		request:  []apiContent{{"application/json", ProposalInput{}}},
+		response: []apiContent{{"application/json", Proposal{}}},
+	},
+	{
+		pattern:  "POST /proposals/{id}/withdraw",
+		summary:  "Withdraw a proposal, with its token as the bearer token",
+		response: []apiContent{{"application/json", Proposal{}}},
+	},
+	{
+		pattern:  "GET /items/{id}/history",
+		summary:  "Every change to an item, oldest first, with who made it",
+		response: []apiContent{{"application/json", ItemChanges{}}},
+	},
+	{
+		pattern: "GET /items/{id}/comments",
+		summary: "The comments on an item, oldest first, with deleted ones left without a body",
+		response: []apiContent{{"application/json", struct {
+			Comments []Comment `json:"comments"`
+		}{}}},
+	},
+	{
+		pattern:  "POST /items/{id}/comments",
+		summary:  "Comment on an item, or reply to a comment on it",
+		request:  []apiContent{{"application/json", CommentInput{}}},
+		status:   http.StatusCreated,
+		response: []apiContent{{"application/json", CommentReceipt{}}},
+	},
+	{
+		pattern:  "GET /items/{id}/comments/{comment}",
+		summary:  "A comment on an item",
+		response: []apiContent{{"application/json", Comment{}}},
+	},
+	{
+		pattern: "DELETE /items/{id}/comments/{comment}",
+		summary: "Delete a comment, keeping its place in the thread, with its token or a reviewer token",
+		status:  http.StatusNoContent,
+	},
+	{
+		pattern:  "POST /items/{id}/vote",
+		summary:  "Vote for an item, as the user the reverse proxy signed in or with a reviewer token, once per voter",
+		response: []apiContent{{"application/json", VoteResult{}}},
+	},
+	{
+		pattern:  "DELETE /items/{id}/vote",
+		summary:  "Withdraw a vote for an item",
+		response: []apiContent{{"application/json", VoteResult{}}},
+	},
+	{
+		pattern: "GET /most-wanted",
+		summary: "The items with votes, most voted for first",
+		params: []apiParam{
+			{name: "ring", description: "Only rank the items in this ring.", schema: stringSchema},
+			{name: "limit", description: "Number of items per page.", schema: integerSchema(1, maxPageLimit, defaultPageLimit)},
+			{name: "offset", description: "Number of items to skip.", schema: map[string]any{"type": "integer", "minimum": 0, "default": 0}},
+		},
+		response: []apiContent{{"application/json", struct {
+			Items []RankedItem `json:"items"`
+		}{}}},
+	},
+	{
+		pattern:  "GET /items/{id}/reactions",
+		summary:  "The reactions to an item by kind, and those of the user signed in",
+		response: []apiContent{{"application/json", ReactionSummary{}}},
+	},
+	{
+		pattern:  "PUT /items/{id}/reactions/{reaction}",
+		summary:  "React to an item with +1, rocket or warning, as the user the reverse proxy signed in or with a reviewer token",
+		response: []apiContent{{"application/json", ReactionSummary{}}},
+	},
+	{
+		pattern:  "DELETE /items/{id}/reactions/{reaction}",
+		summary:  "Take back a reaction to an item",
+		response: []apiContent{{"application/json", ReactionSummary{}}},
+	},
+	{
+		pattern:  "POST /proposals/{id}/review",
+		summary:  "Take a proposal under review, with a reviewer token",
+		request:  []apiContent{{"application/json", ReviewInput{}}},
+		response: []apiContent{{"application/json", Proposal{}}},
+		enabled:  Config.reviewEnabled,
+	},
+	{
+		pattern:  "POST /proposals/{id}/approve",
+		summary:  "Approve a proposal under review, which is approved once every required role has, with a reviewer token",
+		request:  []apiContent{{"application/json", ReviewInput{}}},
+		response: []apiContent{{"application/json", Proposal{}}},
+		enabled:  Config.reviewEnabled,
+	},
+	{
+		pattern:  "POST /proposals/{id}/reject",
+		summary:  "Reject a proposal, with a reviewer token",
+		request:  []apiContent{{"application/json", ReviewInput{}}},
+		response: []apiContent{{"application/json", Proposal{}}},
+		enabled:  Config.reviewEnabled,
+	},
+	{
+		pattern:  "POST /proposals/{id}/publish",
+		summary:  "Make the change of an approved proposal to the radar, with a reviewer token",
+		request:  []apiContent{{"application/json", ReviewInput{}}},
+		response: []apiContent{{"application/json", Proposal{}}},
+		enabled:  Config.reviewEnabled,
+	},
+	{
+		pattern:  "POST /proposals/{id}/assign",
+		summary:  "Assign a proposal awaiting a decision to reviewers, with a reviewer token",
+		request:  []apiContent{{"application/json", AssignInput{}}},
+		response: []apiContent{{"application/json", Proposal{}}},
+		enabled:  Config.reviewEnabled,
+	},
+	{
+		pattern:  "POST /items/{id}/comments/{comment}/approve",
+		summary:  "Approve a comment, taking it out of the moderation queue, with a reviewer token",
+		response: []apiContent{{"application/json", Comment{}}},
+		enabled:  Config.reviewEnabled,
+	},
+	{
+		pattern: "GET /moderation",
+		summary: "The proposals awaiting a decision and the comments awaiting approval, with counts and their review actions, with a reviewer token",
+		params: []apiParam{
+			{name: "type", description: "Only list the entries of this type.", schema: enumSchema(moderationProposal, moderationComment)},
+			{name: "kind", description: "Only list the proposals making this kind of change, and no comments.", schema: enumSchema(changeNewItem, changeEdit, changeRing)},
+			{name: "status", description: "Only list the proposals in this state, and no comments.", schema: enumSchema(pendingStates...)},
+			{name: "assignee", description: "Only list the proposals assigned to this reviewer, and no comments.", schema: stringSchema},
+		},
+		response: []apiContent{{"application/json", ModerationQueue{}}},
+		enabled:  Config.reviewEnabled,
+	},
+	{
+		pattern: "GET /oembed",
+		summary: "oEmbed preview of the radar page, /embed or an item linked to as /#{id}",
+		params: []apiParam{
+			{name: "url", description: "The URL to preview.", schema: stringSchema, required: true},
+			{name: "format", description: "Only json is supported.", schema: enumSchema("json")},
+			{name: "maxwidth", description: "Maximum width of the preview in pixels.", schema: map[string]any{"type": "integer", "minimum": 1}},
+			{name: "maxheight", description: "Maximum height of the preview in pixels.", schema: map[string]any{"type": "integer", "minimum": 1}},
+		},
+		response: []apiContent{{"application/json", oEmbedResponse{}}},
+		enabled:  func(cfg Config) bool { return cfg.Features.UI },
+	},
+	{
+		pattern: "POST /import",
+		summary: "Replace the radar data with a Build Your Own Radar CSV file or a data file",
+		params: []apiParam{
+			{name: "dryRun", description: "Only check the upload and report what importing it would do.", schema: booleanSchema},
+		},
+		request:  []apiContent{{"text/csv", stringSchema}, {"application/json", RadarData{}}, {"application/yaml", stringSchema}, {"multipart/form-data", uploadSchema}},
+		response: []apiContent{{"application/json", ImportReport{}}},
+		admin:    true,
+		enabled:  adminEnabled,
+	},
+	{
+		pattern: "GET /admin/backup",
+		summary: "Backup of the radar data, encrypted if encryption is configured",
+		params:  []apiParam{{name: "format", description: "A YAML data file, or an archive also holding the snapshots of a database store.", schema: enumSchema(backupYAML, backupArchive)}},
+		response: []apiContent{
+			{"application/yaml", binarySchema},
+			{"application/gzip", binarySchema},
+			{"application/octet-stream", binarySchema},
+		},
+		admin:   true,
+		enabled: adminEnabled,
+	},
+	{
+		pattern: "POST /admin/restore",
+		summary: "Replace the radar data with a backup",
+		request: []apiContent{{"application/octet-stream", binarySchema}, {"multipart/form-data", uploadSchema}},
+		response: []apiContent{{"application/json", struct {
+			Restored int `json:"restored"`
+		}{}}},
+		admin:   true,
+		enabled: adminEnabled,
+	},
+	{
+		pattern:  "POST /admin/rollback",
+		summary:  "Revert the radar or an item to a snapshot, as a new snapshot",
+		request:  []apiContent{{"application/json", RollbackRequest{}}},
+		response: []apiContent{{"application/json", RollbackResponse{}}},
+		admin:    true,
+		enabled:  adminEnabled,
+	},
+	{
+		pattern:  "POST /items",
+		summary:  "Add an item to the radar",
+		request:  []apiContent{{"application/json", RadarItem{}}},
+		status:   http.StatusCreated,
+		response: []apiContent{{"application/json", RadarItem{}}},
+		admin:    true,
+		enabled:  adminEnabled,
+	},
+	{
+		pattern:  "POST /items/bulk",
+		summary:  "Make one change to many items",
+		params:   selectParams,
+		request:  []apiContent{{"application/json", BulkEdit{}}},
+		response: []apiContent{{"application/json", BulkEditResult{}}},
+		admin:    true,
+		enabled:  adminEnabled,
+	},
+	{
+		pattern:  "POST /admin/owners/transfer",
+		summary:  "Transfer all items of an owner to another",
+		params:   []apiParam{{name: "dryRun", description: "Only check and preview the transfer.", schema: booleanSchema}},
+		request:  []apiContent{{"application/json", OwnerTransfer{}}},
+		response: []apiContent{{"application/json", OwnerTransferResult{}}},
+		admin:    true,
+		enabled:  adminEnabled,
+	},
+	{
+		pattern:  "PUT /items/{id}",
+		summary:  "Replace an item",
+		request:  []apiContent{{"application/json", RadarItem{}}},
+		response: []apiContent{{"application/json", RadarItem{}}},
+		admin:    true,
+		enabled:  adminEnabled,
+	},
+	{
+		pattern:  "PATCH /items/{id}",
+		summary:  "Update fields of an item",
+		request:  []apiContent{{mergePatchType, map[string]any{"type": "object"}}, {jsonPatchType, []jsonPatchOperation{}}},
+		response: []apiContent{{"application/json", RadarItem{}}},
+		admin:    true,
+		enabled:  adminEnabled,
+	},
+	{
+		pattern: "DELETE /items/{id}",
+		summary: "Remove an item from the radar",
+		status:  http.StatusNoContent,
+		admin:   true,
+		enabled: adminEnabled,
+	},
+	{
+		pattern:  "POST /admin/items/{id}/archive",
+		summary:  "Archive an item",
+		response: []apiContent{{"application/json", RadarItem{}}},
+		admin:    true,
+		enabled:  adminEnabled,
+	},
+	{
+		pattern:  "POST /admin/items/{id}/unarchive",
+		summary:  "Restore an archived item",
+		response: []apiContent{{"application/json", RadarItem{}}},
+		admin:    true,
+		enabled:  adminEnabled,
+	},
+	{
+		pattern: "GET /audit",
+		summary: "A page of the audit log of writes, newest first, with the changes each made to items",
+		params: []apiParam{
+			{name: "item", description: "Only entries changing the item with this ID.", schema: map[string]any{"type": "string"}},
+			{name: "actor", description: "Only entries made as this principal, ignoring case.", schema: map[string]any{"type": "string"}},
+			{name: "since", description: "Only entries made at or after this time, or from the start of this date.", schema: map[string]any{"type": "string"}},
+			{name: "until", description: "Only entries made at or before this time, or by the end of this date.", schema: map[string]any{"type": "string"}},
+			{name: "limit", description: "Number of entries per page.", schema: integerSchema(1, maxPageLimit, defaultPageLimit)},
+			{name: "offset", description: "Number of entries to skip.", schema: map[string]any{"type": "integer", "minimum": 0, "default": 0}},
+		},
+		response: []apiContent{{"application/json", auditPage{}}},
+		admin:    true,
+		enabled:  adminEnabled,
+	},
+	{
+		pattern: "GET /admin/duplicates",
+		summary: "Groups of items likely to name the same technology",
+		response: []apiContent{{"application/json", struct {
+			Duplicates []DuplicateGroup `json:"duplicates"`
+		}{}}},
+		admin:   true,
+		enabled: adminEnabled,
+	},
+	{
+		pattern:  "POST /admin/duplicates/merge",
+		summary:  "Merge items into another one",
+		request:  []apiContent{{"application/json", mergeRequest{}}},
+		response: []apiContent{{"application/json", RadarItem{}}},
+		admin:    true,
+		enabled:  adminEnabled,
+	},
+	{
+		pattern:  "POST /git/webhook",
+		summary:  "Fetch the Git data source, called by GitHub or GitLab on push",
+		request:  []apiContent{{"application/json", map[string]any{}}},
+		status:   http.StatusAccepted,
+		response: []apiContent{{"text/plain", stringSchema}},
+		enabled: func(cfg Config) bool {
+			return cfg.Data.Git.enabled() && cfg.Data.Git.WebhookSecret != ""
+		},
+	},
+}
+
+// pathParamPattern matches the wildcards of a pattern, such as {id}.
+var pathParamPattern = regexp.MustCompile(`\{(\w+)\}`)
+
+// openAPIDocument returns the OpenAPI document of the endpoints cfg serves.
+func openAPIDocument(cfg Config) map[string]any {
+	schemas := make(map[string]any)
+	paths := make(map[string]map[string]any)
+	for _, op := range apiOperations {
+		if op.enabled != nil && !op.enabled(cfg) {
+			continue
+		}
+		method, path, ok := strings.Cut(op.pattern, " ")
+		if !ok {
+			method, path = http.MethodGet, op.pattern
+		}
+
+		var params []map[string]any
+		for _, match := range pathParamPattern.FindAllStringSubmatch(path, -1) {
+			params = append(params, map[string]any{"name": match[1], "in": "path", "required": true, "schema": stringSchema})
+		}
+		for _, p := range op.params {
+			param := map[string]any{"name": p.name, "in": "query", "description": p.description, "schema": p.schema}
+			if p.required {
+				param["required"] = true
+			}
+			// Lists of fields are comma-separated, other lists repeated.
+			if p.name == "fields" {
+				param["explode"] = false
+			}
+			params = append(params, param)
+		}
+
+		status := op.status
+		if status == 0 {
+			status = http.StatusOK
+		}
+		success := map[string]any{"description": http.StatusText(status)}
+		if op.response != nil {
+			success["content"] = openAPIContent(op.response, schemas)
+		}
+		responses := map[string]any{
+			strconv.Itoa(status): success,
+			"default":            map[string]any{"$ref": "#/components/responses/Error"},
+		}
+		if len(pathParamPattern.FindAllString(path, -1)) > 0 {
+			responses["404"] = map[string]any{"$ref": "#/components/responses/NotFound"}
+		}
+
+		operation := map[string]any{
+			"summary":     op.summary,
+			"operationId": operationID(method, path),
+			"responses":   responses,
+		}
+		if params != nil {
+			operation["parameters"] = params
+		}
+		if op.request != nil {
+			operation["requestBody"] = map[string]any{"required": true, "content": openAPIContent(op.request, schemas)}
+		}
+		if op.admin {
+			operation["security"] = []map[string][]string{{"adminToken": {}}}
+			responses["401"] = map[string]any{"$ref": "#/components/responses/Unauthorized"}
+		}
+		if paths[path] == nil {
+			paths[path] = make(map[string]any)
+		}
+		paths[path][strings.ToLower(method)] = operation
+	}
+
+	errorSchema := openAPISchema(reflect.TypeFor[validationReport](), schemas)
+	return map[string]any{
+		"openapi": openAPIVersion,
+		"info": map[string]any{
+			"title":       "Clean Tech Radar API",
+			"version":     buildInfo().Version,
+			"description": "The API of the technology radar. The unversioned paths under " + legacyAPIPrefix + " are deprecated aliases of these.",
+		},
+		"servers": []map[string]any{{"url": cfg.Server.BasePath + apiPrefix}},
+		"paths":   paths,
+		"components": map[string]any{
+			"schemas": schemas,
+			"responses": map[string]any{
+				"Error": map[string]any{
+					"description": "The request failed. Errors are plain text, except for invalid data and item lookups, which are JSON.",
+					"content": map[string]any{
+						"text/plain":       map[string]any{"schema": stringSchema},
+						"application/json": map[string]any{"schema": map[string]any{"oneOf": []any{openAPISchema(reflect.TypeFor[errorResponse](), schemas), errorSchema}}},
+					},
+				},
+				"NotFound":     map[string]any{"description": "There is no such item."},
+				"Unauthorized": map[string]any{"description": "The admin token is missing or wrong."},
+			},
+			"securitySchemes": map[string]any{
+				"adminToken": map[string]any{"type": "http", "scheme": "bearer", "description": "The admin token set by admin.token."},
+			},
+		},
+	}
+}
+
+// operationID returns the ID of the operation of method on path, such as
+// getRadarItemsId for GET /radar/items/{id}.
+func operationID(method, path string) string {
+	id := strings.ToLower(method)
+	for _, word := range strings.FieldsFunc(path, func(r rune) bool { return !unicode.IsLetter(r) && !unicode.IsDigit(r) }) {
+		id += strings.ToUpper(word[:1]) + word[1:]
+	}
+	return id
+}
+
+// openAPIContent returns the content object of bodies, adding the schemas
+// of the named types they use to schemas.
+func openAPIContent(bodies []apiContent, schemas map[string]any) map[string]any {
+	content := make(map[string]any)
+	for _, body := range bodies {
+		schema, ok := body.schema.(map[string]any)
+		if !ok || len(schema) == 0 {
+			schema = openAPISchema(reflect.TypeOf(body.schema), schemas)
+		}
+		content[body.mediaType] = map[string]any{"schema": schema}
+	}
+	return content
+}
+
+// openAPISchema returns the schema of the JSON encoding of values of t.
+// Structs of this package are added to schemas under their name and
+// referred to, anonymous ones are inlined.
+func openAPISchema(t reflect.Type, schemas map[string]any) map[string]any {
+	if t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	switch {
+	case t == reflect.TypeFor[time.Time]():
+		return map[string]any{"type": "string", "format": "date-time"}
+	case t.Kind() == reflect.Struct && t.Name() != "":
+		name := t.Name()
+		name = strings.ToUpper(name[:1]) + name[1:]
+		if _, ok := schemas[name]; !ok {
+			// The placeholder ends the recursion of types referring to
+			// themselves.
+			schemas[name] = nil
+			schemas[name] = openAPIObject(t, schemas)
+		}
+		return map[string]any{"$ref": "#/components/schemas/" + name}
+	}
+	switch t.Kind() {
+	case reflect.Struct:
+		return openAPIObject(t, schemas)
+	case reflect.Slice, reflect.Array:
+		return map[string]any{"type": "array", "items": openAPISchema(t.Elem(), schemas)}
+	case reflect.Map:
+		return map[string]any{"type": "object", "additionalProperties": openAPISchema(t.Elem(), schemas)}
+	case reflect.String:
+		return map[string]any{"type": "string"}
+	case reflect.Bool:
+		return map[string]any{"type": "boolean"}
+	case reflect.Int64:
+		return map[string]any{"type": "integer", "format": "int64"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return map[string]any{"type": "integer"}
+	case reflect.Float32, reflect.Float64:
+		return map[string]any{"type": "number"}
+	}
+	return map[string]any{}
+}
+
+// openAPIObject returns the schema of a struct, with the fields of embedded
+// structs inlined as encoding/json does. Fields that are always encoded are
+// required.
+func openAPIObject(t reflect.Type, schemas map[string]any) map[string]any {
+	properties := make(map[string]any)
+	var required []string
+	var add func(t reflect.Type)
+	add = func(t reflect.Type) {
+		for i := range t.NumField() {
+			field := t.Field(i)
+			tag := field.Tag.Get("json")
+			name, options, _ := strings.Cut(tag, ",")
+			if field.Anonymous && tag == "" && field.Type.Kind() == reflect.Struct {
+				add(field.Type)
+				continue
+			}
+			if !field.IsExported() || name == "-" {
+				continue
+			}
+			if name == "" {
+				name = field.Name
+			}
+			// The fields of the outer struct hide embedded ones.
+			if _, ok := properties[name]; ok {
+				continue
+			}
+			properties[name] = openAPISchema(field.Type, schemas)
+			if !strings.Contains(options, "omitempty") && !strings.Contains(options, "omitzero") {
+				required = append(required, name)
+			}
+		}
+	}
+	add(t)
+	schema := map[string]any{"type": "object", "properties": properties}
+	if required != nil {
+		schema["required"] = required
+	}
+	return schema
+}
+
+// openAPIHandler serves the OpenAPI document of the endpoints cfg serves.
+func openAPIHandler(cfg Config) (http.Handler, error) {
+	var buf bytes.Buffer
+	if err := json.NewEncoder(&buf).Encode(openAPIDocument(cfg)); err != nil {
+		return nil, err
+	}
+	content := buf.Bytes()
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		serveConditional(w, r, content, time.Time{})
+	}), nil
+}
+
+// apiDocsPage is the Swagger UI page of the OpenAPI document, loaded from a
+// CDN like the scripts of the index page.
+var apiDocsPage = template.Must(template.New("docs").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+    <meta charset="UTF-8">
+    <meta name="viewport" content="width=device-width, initial-scale=1.0">
+    <title>Clean Tech Radar API</title>
+    <link rel="stylesheet" href="https://unpkg.com/swagger-ui-dist@{{.Version}}/swagger-ui.css">
+</head>
+<body>
+    <div id="swagger-ui"></div>
+    <script src="https://unpkg.com/swagger-ui-dist@{{.Version}}/swagger-ui-bundle.js"></script>
+    <script>
+        window.ui = SwaggerUIBundle({url: "{{.SpecURL}}", dom_id: "#swagger-ui"});
+    </script>
+</body>
+</html>
+`))
+
+// apiDocsHandler serves the Swagger UI page of the OpenAPI document.
+func apiDocsHandler(basePath string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		page := struct{ Version, SpecURL string }{swaggerUIVersion, basePath + apiPrefix + "/openapi.json"}
+		if err := apiDocsPage.Execute(w, page); err != nil {
+			handleError(w, &AppError{Code: http.StatusInternalServerError, Message: "Failed to render template", Err: err})
+		}
+	})
+}
+
+// handleAPIDocs registers the OpenAPI document of the API under apiPrefix
+// and, as the entry point to the current version, under legacyAPIPrefix,
+// and the Swagger UI page at legacyAPIPrefix/docs.
+func handleAPIDocs(mux *http.ServeMux, cfg Config) error {
+	spec, err := openAPIHandler(cfg)
+	if err != nil {
+		return err
+	}
+	mux.Handle("GET "+apiPrefix+"/openapi.json", spec)
+	mux.Handle("GET "+legacyAPIPrefix+"/openapi.json", spec)
+	mux.Handle("GET "+legacyAPIPrefix+"/docs", apiDocsHandler(cfg.Server.BasePath))
+	return nil
+}
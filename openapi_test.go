@@ -0,0 +1,184 @@
+package main
+
+import (
+	"encoding/json"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"net/http"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestOpenAPIDocumentsEveryRoute(t *testing.T) {
+	// Every pattern setupRoutes registers with api or admin is documented,
+	// and nothing else is.
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, "main.go", nil, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var registered []string
+	ast.Inspect(file, func(n ast.Node) bool {
+		call, ok := n.(*ast.CallExpr)
+		if !ok || len(call.Args) == 0 {
+			return true
+		}
+		fun, ok := call.Fun.(*ast.Ident)
+		lit, isLit := call.Args[0].(*ast.BasicLit)
+		if ok && isLit && (fun.Name == "api" || fun.Name == "admin") {
+			pattern, err := strconv.Unquote(lit.Value)
+			if err != nil {
+				t.Fatal(err)
+			}
+			registered = append(registered, pattern)
+		}
+		return true
+	})
+	if len(registered) == 0 {
+		t.Fatal("found no routes in setupRoutes")
+	}
+
+	var documented []string
+	for _, op := range apiOperations {
+		documented = append(documented, op.pattern)
+	}
+	for _, pattern := range registered {
+		if !slices.Contains(documented, pattern) {
+			t.Errorf("%q is not in apiOperations", pattern)
+		}
+	}
+	for _, pattern := range documented {
+		if !slices.Contains(registered, pattern) {
+			t.Errorf("%q is documented but not registered", pattern)
+		}
+	}
+}
+
+func TestOpenAPIHandler(t *testing.T) {
+	cfg := defaultConfig()
+	cfg.Server.BasePath = "/tech-radar"
+	cfg.Admin.Token = "s3cret"
+	cfg.Data.Path = writeFile(t, "radar.yaml", radarWith("Go", "Adopted"))
+	useConfig(t, cfg)
+	handler, err := setupRoutes(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rec := doRequest(t, handler, http.MethodGet, "/tech-radar/api/openapi.json")
+	if rec.Code != http.StatusOK || rec.Header().Get("Content-Type") != "application/json" {
+		t.Fatalf("GET /api/openapi.json = %d %q", rec.Code, rec.Header().Get("Content-Type"))
+	}
+	if rec.Header().Get("Deprecation") != "" {
+		t.Errorf("GET /api/openapi.json is marked as deprecated")
+	}
+	if v1 := doRequest(t, handler, http.MethodGet, "/tech-radar/api/v1/openapi.json"); v1.Body.String() != rec.Body.String() {
+		t.Errorf("GET /api/v1/openapi.json = %d, want the same document", v1.Code)
+	}
+
+	var doc struct {
+		OpenAPI string `json:"openapi"`
+		Servers []struct {
+			URL string `json:"url"`
+		} `json:"servers"`
+		Paths      map[string]map[string]json.RawMessage `json:"paths"`
+		Components struct {
+			Schemas map[string]json.RawMessage `json:"schemas"`
+		} `json:"components"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &doc); err != nil {
+		t.Fatal(err)
+	}
+	if doc.OpenAPI != openAPIVersion || len(doc.Servers) != 1 || doc.Servers[0].URL != "/tech-radar/api/v1" {
+		t.Errorf("openapi %q, servers %+v, want the versioned API under the base path", doc.OpenAPI, doc.Servers)
+	}
+	for path, method := range map[string]string{"/radar": "get", "/radar/items/{id}": "get", "/admin/duplicates/merge": "post"} {
+		if _, ok := doc.Paths[path][method]; !ok {
+			t.Errorf("paths[%q] = %v, want %s", path, doc.Paths[path], method)
+		}
+	}
+	if _, ok := doc.Paths["/git/webhook"]; ok {
+		t.Error("documents the Git webhook, which isn't served without a Git data source")
+	}
+	// Every schema referred to is defined.
+	for _, ref := range strings.Split(rec.Body.String(), `"$ref":"`)[1:] {
+		ref, _, _ = strings.Cut(ref, `"`)
+		if name, ok := strings.CutPrefix(ref, "#/components/schemas/"); ok && doc.Components.Schemas[name] == nil {
+			t.Errorf("%s is not defined", ref)
+		}
+	}
+
+	// Every documented path without wildcards is served, if only to answer
+	// that the request is invalid or the history missing.
+	for path := range doc.Paths {
+		if strings.Contains(path, "{") {
+			continue
+		}
+		for method := range doc.Paths[path] {
+			rec := doRequest(t, handler, strings.ToUpper(method), "/tech-radar/api/v1"+path)
+			if rec.Code == http.StatusMethodNotAllowed || rec.Body.String() == "404 page not found\n" {
+				t.Errorf("%s %s = %d %q", strings.ToUpper(method), path, rec.Code, rec.Body.String())
+			}
+		}
+	}
+
+	cfg.Admin.Token = ""
+	if doc := openAPIDocument(cfg); doc["paths"].(map[string]map[string]any)["/admin/backup"] != nil {
+		t.Error("documents admin endpoints without an admin token")
+	}
+}
+
+func TestOpenAPISchema(t *testing.T) {
+	schemas := make(map[string]any)
+	if ref := openAPISchema(reflect.TypeFor[ItemDetail](), schemas); ref["$ref"] != "#/components/schemas/ItemDetail" {
+		t.Fatalf("schema = %v, want a reference", ref)
+	}
+	detail := schemas["ItemDetail"].(map[string]any)
+	properties := detail["properties"].(map[string]any)
+	// The fields of the embedded RadarItem are inlined.
+	for _, name := range []string{"id", "label", "owners", "lastUpdated", "history"} {
+		if properties[name] == nil {
+			t.Errorf("ItemDetail has no %s", name)
+		}
+	}
+	if properties["Source"] != nil {
+		t.Error("ItemDetail has Source, which isn't encoded")
+	}
+	required := detail["required"].([]string)
+	if !slices.Contains(required, "label") || slices.Contains(required, "history") || slices.Contains(required, "lastUpdated") {
+		t.Errorf("required = %v, want the fields always encoded", required)
+	}
+	if got := properties["lastUpdated"].(map[string]any)["format"]; got != "date-time" {
+		t.Errorf("lastUpdated format = %v, want date-time", got)
+	}
+	if got := properties["owners"].(map[string]any)["items"].(map[string]any)["$ref"]; got != "#/components/schemas/Owner" || schemas["Owner"] == nil {
+		t.Errorf("owners items = %v, want Owner", got)
+	}
+
+	if got := operationID("POST", "/admin/items/{id}/archive"); got != "postAdminItemsIdArchive" {
+		t.Errorf("operationID = %q", got)
+	}
+}
+
+func TestAPIDocsPage(t *testing.T) {
+	cfg := defaultConfig()
+	cfg.Server.BasePath = "/tech-radar"
+	cfg.Data.Path = writeFile(t, "radar.yaml", radarWith("Go", "Adopted"))
+	useConfig(t, cfg)
+	handler, err := setupRoutes(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rec := doRequest(t, handler, http.MethodGet, "/tech-radar/api/docs")
+	if rec.Code != http.StatusOK || !strings.HasPrefix(rec.Header().Get("Content-Type"), "text/html") {
+		t.Fatalf("GET /api/docs = %d %q", rec.Code, rec.Header().Get("Content-Type"))
+	}
+	if body := rec.Body.String(); !strings.Contains(body, `\/tech-radar\/api\/v1\/openapi.json`) || !strings.Contains(body, "swagger-ui-dist@"+swaggerUIVersion) {
+		t.Errorf("GET /api/docs = %q, want Swagger UI loading the document", body)
+	}
+}
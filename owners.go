@@ -0,0 +1,137 @@
+package main
+
+import (
+	"bytes"
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Owner is a person or team responsible for a radar item.
+type Owner struct {
+	Name  string `yaml:"Name" json:"name" toml:"Name"`
+	Email string `yaml:"Email,omitempty" json:"email,omitempty" toml:"Email,omitempty"`
+	Team  string `yaml:"Team,omitempty" json:"team,omitempty" toml:"Team,omitempty"`
+	Slack string `yaml:"Slack,omitempty" json:"slack,omitempty" toml:"Slack,omitempty"`
+}
+
+// Owners are the owners of a radar item. In JSON they are always an array,
+// and the comma-separated string of data saved before owners were structured
+// is still accepted.
+type Owners []Owner
+
+// parseOwners splits the comma-separated owners string of the version 1
+// data format into owners named by each entry.
+func parseOwners(s string) Owners {
+	var owners Owners
+	for _, name := range strings.Split(s, ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			owners = append(owners, Owner{Name: name})
+		}
+	}
+	return owners
+}
+
+// names returns the names of the owners, separated by commas.
+func (o Owners) names() string {
+	names := make([]string, len(o))
+	for i, owner := range o {
+		names[i] = owner.Name
+	}
+	return strings.Join(names, ", ")
+}
+
+func (o Owners) MarshalJSON() ([]byte, error) {
+	if o == nil {
+		return []byte("[]"), nil
+	}
+	return json.Marshal([]Owner(o))
+}
+
+func (o *Owners) UnmarshalJSON(data []byte) error {
+	var s string
+	if bytes.HasPrefix(bytes.TrimSpace(data), []byte(`"`)) {
+		if err := json.Unmarshal(data, &s); err != nil {
+			return err
+		}
+		*o = parseOwners(s)
+		return nil
+	}
+	return o.unmarshalList(data)
+}
+
+// unmarshalList decodes a JSON array of owners, leaving an empty one nil.
+func (o *Owners) unmarshalList(data []byte) error {
+	if err := json.Unmarshal(data, (*[]Owner)(o)); err != nil {
+		return err
+	}
+	if len(*o) == 0 {
+		*o = nil
+	}
+	return nil
+}
+
+// Value stores the owners in a SQL text column as a JSON array.
+func (o Owners) Value() (driver.Value, error) {
+	if len(o) == 0 {
+		return "", nil
+	}
+	data, err := json.Marshal([]Owner(o))
+	return string(data), err
+}
+
+// Scan reads owners stored by Value, or the comma-separated string stored
+// before owners were structured.
+func (o *Owners) Scan(src any) error {
+	var s string
+	switch v := src.(type) {
+	case nil:
+	case string:
+		s = v
+	case []byte:
+		s = string(v)
+	default:
+		return fmt.Errorf("cannot scan %T into owners", src)
+	}
+	if s = strings.TrimSpace(s); strings.HasPrefix(s, "[") {
+		return o.unmarshalList([]byte(s))
+	}
+	*o = parseOwners(s)
+	return nil
+}
+
+// migrateOwnersToList replaces the Owners strings of items with lists of
+// owners. Empty strings are dropped.
+func migrateOwnersToList(root *yaml.Node) (bool, error) {
+	items := mappingValue(root, "Items")
+	if items == nil || items.Kind != yaml.SequenceNode {
+		return false, nil
+	}
+	changed := false
+	for _, item := range items.Content {
+		if item.Kind != yaml.MappingNode {
+			continue
+		}
+		i := yamlMappingIndex(item, "Owners")
+		if i < 0 || item.Content[i+1].Kind != yaml.ScalarNode || item.Content[i+1].Tag == "!!null" {
+			continue
+		}
+		changed = true
+		old := item.Content[i+1]
+		owners := parseOwners(old.Value)
+		if len(owners) == 0 {
+			item.Content = append(item.Content[:i], item.Content[i+2:]...)
+			continue
+		}
+		var list yaml.Node
+		if err := list.Encode([]Owner(owners)); err != nil {
+			return false, err
+		}
+		list.Line, list.LineComment, list.FootComment = old.Line, old.LineComment, old.FootComment
+		item.Content[i+1] = &list
+	}
+	return changed, nil
+}
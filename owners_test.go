@@ -0,0 +1,108 @@
+package main
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseOwners(t *testing.T) {
+	got := parseOwners(" Team A,, Jane Doe ,")
+	want := Owners{{Name: "Team A"}, {Name: "Jane Doe"}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("parseOwners() = %+v, want %+v", got, want)
+	}
+	if got := parseOwners(""); got != nil {
+		t.Errorf("parseOwners(\"\") = %+v, want nil", got)
+	}
+}
+
+func TestOwnersJSON(t *testing.T) {
+	out, err := json.Marshal(RadarItem{Label: "Go"})
+	if err != nil || !strings.Contains(string(out), `"owners":[]`) {
+		t.Errorf("json.Marshal() = %s, %v, want an empty owners array", out, err)
+	}
+	out, err = json.Marshal(Owners{{Name: "Jane", Email: "jane@example.com"}})
+	if err != nil || string(out) != `[{"name":"Jane","email":"jane@example.com"}]` {
+		t.Errorf("json.Marshal() = %s, %v", out, err)
+	}
+
+	tests := []struct {
+		data string
+		want Owners
+	}{
+		{data: `"Team A, Team B"`, want: Owners{{Name: "Team A"}, {Name: "Team B"}}},
+		{data: `[{"name": "Jane", "team": "Team A", "slack": "#team-a"}]`, want: Owners{{Name: "Jane", Team: "Team A", Slack: "#team-a"}}},
+		{data: `[]`},
+		{data: `""`},
+	}
+	for _, tt := range tests {
+		var got Owners
+		if err := json.Unmarshal([]byte(tt.data), &got); err != nil || !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("json.Unmarshal(%s) = %+v, %v, want %+v", tt.data, got, err, tt.want)
+		}
+	}
+}
+
+func TestOwnersSQL(t *testing.T) {
+	owners := Owners{{Name: "Jane", Email: "jane@example.com"}}
+	value, err := owners.Value()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got Owners
+	if err := got.Scan(value); err != nil || !reflect.DeepEqual(got, owners) {
+		t.Errorf("Scan(%v) = %+v, %v, want %+v", value, got, err, owners)
+	}
+
+	// Columns saved before owners were structured hold the plain string.
+	if err := got.Scan([]byte("Team A, Team B")); err != nil || !reflect.DeepEqual(got, Owners{{Name: "Team A"}, {Name: "Team B"}}) {
+		t.Errorf("Scan() of a legacy string = %+v, %v", got, err)
+	}
+	if value, _ := Owners(nil).Value(); value != "" {
+		t.Errorf("Value() of no owners = %q, want empty", value)
+	}
+}
+
+func TestDecodeMigratesOwnersStrings(t *testing.T) {
+	content := "Items:\n- Label: Go\n  Owners: Team A, Team B # both\n- Label: Rust\n  Owners: \"\"\n"
+	data, applied, err := upgradeRadarData("radar.yaml", []byte(content))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(applied) != 1 || data.Version != 2 {
+		t.Errorf("applied = %v, version %d, want the owners migration to version 2", applied, data.Version)
+	}
+	if want := (Owners{{Name: "Team A"}, {Name: "Team B"}}); !reflect.DeepEqual(data.Items[0].Owners, want) {
+		t.Errorf("owners = %+v, want %+v", data.Items[0].Owners, want)
+	}
+	if data.Items[1].Owners != nil {
+		t.Errorf("owners of an empty string = %+v, want none", data.Items[1].Owners)
+	}
+
+	// Version 2 files must use the list.
+	if _, err := decodeRadarData("radar.yaml", []byte("Version: 2\n"+content)); err == nil {
+		t.Error("decodeRadarData() accepted an owners string in version 2")
+	}
+}
+
+func TestValidateOwners(t *testing.T) {
+	content := `Version: 2
+Items:
+- Label: Go
+  Quadrant: Tools
+  Ring: Adopted
+  Owners:
+  - Name: Jane
+    Email: jane@example.com
+  - Team: Team A
+  - Name: Joe
+    Email: joe at example.com
+`
+	err := validateRadarContent("radar.yaml", []byte(content), newValidationScope())
+	want := "radar.yaml:9: item \"Go\".Owners[1].Name: missing\nradar.yaml:11: item \"Go\".Owners[2].Email: invalid email address \"joe at example.com\""
+	if err == nil || err.Error() != want {
+		t.Errorf("validateRadarContent() = %v, want\n%s", err, want)
+	}
+}
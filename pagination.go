@@ -0,0 +1,172 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// Page sizes of GET /api/radar/items.
+const (
+	defaultPageLimit = 100
+	maxPageLimit     = 1000
+)
+
+// page is the slice of a list asked for with ?limit= and ?offset=.
+type page struct {
+	limit, offset int
+}
+
+// parsePage reads the page asked for by the request.
+func parsePage(r *http.Request) (page, error) {
+	p := page{limit: defaultPageLimit}
+	query := r.URL.Query()
+	if value := query.Get("limit"); value != "" {
+		n, err := strconv.Atoi(value)
+		if err != nil || n < 1 || n > maxPageLimit {
+			return page{}, &AppError{Code: http.StatusBadRequest, Message: fmt.Sprintf("Invalid limit %q, must be between 1 and %d", value, maxPageLimit)}
+		}
+		p.limit = n
+	}
+	if value := query.Get("offset"); value != "" {
+		n, err := strconv.Atoi(value)
+		if err != nil || n < 0 {
+			return page{}, &AppError{Code: http.StatusBadRequest, Message: fmt.Sprintf("Invalid offset %q, must be a number of items", value)}
+		}
+		p.offset = n
+	}
+	return p, nil
+}
+
+// slice returns the part of a list of total elements the page covers, as
+// the start and end of a slice expression.
+func (p page) slice(total int) (int, int) {
+	start := min(p.offset, total)
+	return start, min(start+p.limit, total)
+}
+
+// pageLink is a link from a page of a list to another.
+type pageLink struct {
+	rel, href string
+}
+
+// pageLinks returns the links to the first, last, previous and next pages
+// of a list of total elements served at u, those of the last two only if
+// there are such pages.
+func (p page) pageLinks(u *url.URL, total int) []pageLink {
+	link := func(rel string, offset int) pageLink {
+		query := u.Query()
+		query.Set("limit", strconv.Itoa(p.limit))
+		query.Set("offset", strconv.Itoa(offset))
+		target := url.URL{Path: u.Path, RawQuery: query.Encode()}
+		return pageLink{rel, target.String()}
+	}
+	last := 0
+	if total > 0 {
+		last = (total - 1) / p.limit * p.limit
+	}
+	links := []pageLink{link("first", 0), link("last", last)}
+	if p.offset > 0 {
+		links = append(links, link("prev", max(0, min(p.offset, total)-p.limit)))
+	}
+	if p.offset+p.limit < total {
+		links = append(links, link("next", p.offset+p.limit))
+	}
+	return links
+}
+
+// links returns the value of the Link header pointing to the pageLinks of
+// a list of total elements served at u.
+func (p page) links(u *url.URL, total int) string {
+	var links []string
+	for _, link := range p.pageLinks(u, total) {
+		links = append(links, fmt.Sprintf("<%s>; rel=%q", link.href, link.rel))
+	}
+	return strings.Join(links, ", ")
+}
+
+// requestURL returns the URL the client asked for, with the path it used
+// rather than the one left once the base path is stripped.
+func requestURL(r *http.Request) *url.URL {
+	if u, err := url.ParseRequestURI(r.RequestURI); err == nil {
+		return u
+	}
+	return r.URL
+}
+
+// selectPage returns the page of the items selectItems selects that
+// ?limit= and ?offset= ask for, all of them without a limit, and the
+// number of items selected.
+func selectPage(r *http.Request) ([]RadarItem, int, error) {
+	p, err := parsePage(r)
+	if err != nil {
+		return nil, 0, err
+	}
+	data, err := loadRadarData()
+	if err != nil {
+		return nil, 0, err
+	}
+	items, err := selectItems(r, withSegments(data))
+	if err != nil {
+		return nil, 0, err
+	}
+	if !r.URL.Query().Has("limit") {
+		p.limit = len(items)
+	}
+	start, end := p.slice(len(items))
+	return items[start:end], len(items), nil
+}
+
+// itemsHandler serves a page of the items selectItems selects, with the
+// fields parseFields asks for, their total count and Link headers to the
+// other pages.
+func itemsHandler(w http.ResponseWriter, r *http.Request) {
+	p, err := parsePage(r)
+	if err != nil {
+		handleError(w, err)
+		return
+	}
+	fields, err := parseFields(r, itemFields)
+	if err != nil {
+		handleError(w, err)
+		return
+	}
+	data, err := loadRadarData()
+	if err != nil {
+		handleError(w, err)
+		return
+	}
+	items, err := selectItems(r, withSegments(data))
+	if err != nil {
+		handleError(w, err)
+		return
+	}
+
+	start, end := p.slice(len(items))
+	shown := items[start:end]
+	body := struct {
+		Items  any                `json:"items"`
+		Total  int                `json:"total"`
+		Limit  int                `json:"limit"`
+		Offset int                `json:"offset"`
+		Links  map[string]HALLink `json:"_links"`
+	}{Total: len(items), Limit: p.limit, Offset: p.offset}
+	if body.Items, err = linkedItems(shown, fields); err != nil {
+		handleError(w, &AppError{Code: http.StatusInternalServerError, Message: "Failed to encode response", Err: err})
+		return
+	}
+	u := requestURL(r)
+	body.Links = map[string]HALLink{"self": {u.String()}}
+	for _, link := range p.pageLinks(u, len(items)) {
+		body.Links[link.rel] = HALLink{link.href}
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Add("Link", p.links(u, len(items)))
+	w.Header().Set("X-Total-Count", strconv.Itoa(len(items)))
+	if err := json.NewEncoder(w).Encode(body); err != nil {
+		handleError(w, &AppError{Code: http.StatusInternalServerError, Message: "Failed to encode response", Err: err})
+	}
+}
@@ -0,0 +1,100 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestItemsPagination(t *testing.T) {
+	var data strings.Builder
+	data.WriteString("Items:\n")
+	for i := 1; i <= 5; i++ {
+		fmt.Fprintf(&data, "- Label: Item %d\n  Quadrant: Tools\n  Ring: Adopted\n", i)
+	}
+	data.WriteString("- Label: Old\n  Quadrant: Tools\n  Ring: Not Recommended\n  Archived: true\n")
+	cfg := defaultConfig()
+	cfg.Data.Path = writeFile(t, "radar.yaml", data.String())
+	useConfig(t, cfg)
+	handler, err := setupRoutes(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		target string
+		labels string
+		total  int
+		links  []string
+	}{
+		{
+			target: "/api/v1/radar/items",
+			labels: "Item 1,Item 2,Item 3,Item 4,Item 5",
+			total:  5,
+			links:  []string{`</api/v1/radar/items?limit=100&offset=0>; rel="first"`, `</api/v1/radar/items?limit=100&offset=0>; rel="last"`},
+		},
+		{
+			target: "/api/v1/radar/items?limit=2",
+			labels: "Item 1,Item 2",
+			total:  5,
+			links:  []string{`rel="first"`, `</api/v1/radar/items?limit=2&offset=4>; rel="last"`, `</api/v1/radar/items?limit=2&offset=2>; rel="next"`},
+		},
+		{
+			target: "/api/v1/radar/items?limit=2&offset=3&sort=label",
+			labels: "Item 4,Item 5",
+			total:  5,
+			links:  []string{`</api/v1/radar/items?limit=2&offset=1&sort=label>; rel="prev"`},
+		},
+		{target: "/api/v1/radar/items?offset=10", labels: "", total: 5},
+		{target: "/api/v1/radar/items?includeArchived=true&ring=not+recommended", labels: "Old", total: 1},
+	}
+	for _, tt := range tests {
+		rec := doRequest(t, handler, http.MethodGet, tt.target)
+		var body struct {
+			Items []RadarItem
+			Total int
+		}
+		if err := json.NewDecoder(rec.Body).Decode(&body); err != nil {
+			t.Fatalf("GET %s: status %d: %v", tt.target, rec.Code, err)
+		}
+		var labels []string
+		for _, item := range body.Items {
+			labels = append(labels, item.Label)
+		}
+		if got := strings.Join(labels, ","); got != tt.labels || body.Total != tt.total || rec.Header().Get("X-Total-Count") != fmt.Sprint(tt.total) {
+			t.Errorf("GET %s = %q of %d, want %q of %d", tt.target, got, body.Total, tt.labels, tt.total)
+		}
+		link := rec.Header().Get("Link")
+		for _, want := range tt.links {
+			if !strings.Contains(link, want) {
+				t.Errorf("GET %s: Link = %q, want it to contain %q", tt.target, link, want)
+			}
+		}
+		if strings.Contains(tt.target, "limit=2&offset=3") && strings.Contains(link, `rel="next"`) {
+			t.Errorf("GET %s: Link = %q, want no next page", tt.target, link)
+		}
+	}
+
+	for _, query := range []string{"limit=0", "limit=1001", "offset=-1", "offset=x"} {
+		if rec := doRequest(t, handler, http.MethodGet, "/api/radar/items?"+query); rec.Code != http.StatusBadRequest {
+			t.Errorf("GET /api/radar/items?%s = %d, want 400", query, rec.Code)
+		}
+	}
+}
+
+func TestItemsPaginationBasePath(t *testing.T) {
+	cfg := defaultConfig()
+	cfg.Server.BasePath = "/radar"
+	cfg.Data.Path = writeFile(t, "radar.yaml", radarWith("Go", "Adopted"))
+	useConfig(t, cfg)
+	handler, err := setupRoutes(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rec := doRequest(t, handler, http.MethodGet, "/radar/api/v1/radar/items?limit=1")
+	if link := rec.Header().Get("Link"); !strings.HasPrefix(link, "</radar/api/v1/radar/items?limit=1&offset=0>") {
+		t.Errorf("Link = %q, want links under the base path", link)
+	}
+}
@@ -0,0 +1,368 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"maps"
+	"mime"
+	"net/http"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// Media types of the patches PATCH /items/{id} applies.
+const (
+	mergePatchType = "application/merge-patch+json"
+	jsonPatchType  = "application/json-patch+json"
+)
+
+// errPatchConflict is returned by applyJSONPatch when a patch doesn't apply
+// to the document, as a path that doesn't exist or a failed test.
+var errPatchConflict = errors.New("patch doesn't apply")
+
+// jsonPatchOperation is an operation of a JSON Patch, RFC 6902.
+type jsonPatchOperation struct {
+	Op    string          `json:"op"`
+	Path  string          `json:"path"`
+	From  string          `json:"from,omitempty"`
+	Value json.RawMessage `json:"value,omitempty"`
+}
+
+// mergePatch returns target with the JSON Merge Patch, RFC 7396, applied:
+// the members of an object patch replace those of target, recursively for
+// objects, and null members remove them. Any other patch replaces target.
+func mergePatch(target, patch any) any {
+	members, ok := patch.(map[string]any)
+	if !ok {
+		return patch
+	}
+	result, ok := target.(map[string]any)
+	if !ok {
+		result = make(map[string]any)
+	}
+	for name, value := range members {
+		if value == nil {
+			delete(result, name)
+		} else {
+			result[name] = mergePatch(result[name], value)
+		}
+	}
+	return result
+}
+
+// jsonPointer splits the JSON Pointer, RFC 6901, path into its unescaped
+// reference tokens; the empty pointer refers to the whole document.
+func jsonPointer(path string) ([]string, error) {
+	if path == "" {
+		return nil, nil
+	}
+	if !strings.HasPrefix(path, "/") {
+		return nil, fmt.Errorf("path %q doesn't start with /", path)
+	}
+	tokens := strings.Split(path[1:], "/")
+	for i, token := range tokens {
+		tokens[i] = strings.NewReplacer("~1", "/", "~0", "~").Replace(token)
+	}
+	return tokens, nil
+}
+
+// arrayIndex returns the index token refers to in an array of n elements,
+// which may be n itself, or "-" for it, when end is set.
+func arrayIndex(token string, n int, end bool) (int, error) {
+	if token == "-" && end {
+		return n, nil
+	}
+	i, err := strconv.Atoi(token)
+	if err != nil || i < 0 || token != strconv.Itoa(i) {
+		return 0, fmt.Errorf("%w: %q is not an array index", errPatchConflict, token)
+	}
+	if i > n || i == n && !end {
+		return 0, fmt.Errorf("%w: index %d is out of range", errPatchConflict, i)
+	}
+	return i, nil
+}
+
+// pointerValue returns the value tokens refer to in doc.
+func pointerValue(doc any, tokens []string) (any, error) {
+	for _, token := range tokens {
+		switch container := doc.(type) {
+		case map[string]any:
+			value, ok := container[token]
+			if !ok {
+				return nil, fmt.Errorf("%w: no member %q", errPatchConflict, token)
+			}
+			doc = value
+		case []any:
+			i, err := arrayIndex(token, len(container), false)
+			if err != nil {
+				return nil, err
+			}
+			doc = container[i]
+		default:
+			return nil, fmt.Errorf("%w: %q is not in an object or array", errPatchConflict, token)
+		}
+	}
+	return doc, nil
+}
+
+// patchContainer returns doc with the object or array holding the value
+// tokens refer to replaced by what edit returns for it and the last token.
+func patchContainer(doc any, tokens []string, edit func(container any, token string) (any, error)) (any, error) {
+	if len(tokens) == 1 {
+		return edit(doc, tokens[0])
+	}
+	child, err := pointerValue(doc, tokens[:1])
+	if err != nil {
+		return nil, err
+	}
+	child, err = patchContainer(child, tokens[1:], edit)
+	if err != nil {
+		return nil, err
+	}
+	switch container := doc.(type) {
+	case map[string]any:
+		container[tokens[0]] = child
+	case []any:
+		i, _ := arrayIndex(tokens[0], len(container), false)
+		container[i] = child
+	}
+	return doc, nil
+}
+
+// addValue returns doc with value added at tokens, inserted if they refer
+// to an array element and replacing any member they refer to.
+func addValue(doc any, tokens []string, value any) (any, error) {
+	if len(tokens) == 0 {
+		return value, nil
+	}
+	return patchContainer(doc, tokens, func(container any, token string) (any, error) {
+		switch container := container.(type) {
+		case map[string]any:
+			container[token] = value
+			return container, nil
+		case []any:
+			i, err := arrayIndex(token, len(container), true)
+			if err != nil {
+				return nil, err
+			}
+			return slices.Insert(container, i, value), nil
+		}
+		return nil, fmt.Errorf("%w: %q is not in an object or array", errPatchConflict, token)
+	})
+}
+
+// removeValue returns doc with the value at tokens, which must exist,
+// removed.
+func removeValue(doc any, tokens []string) (any, error) {
+	if len(tokens) == 0 {
+		return nil, errors.New("the whole document can't be removed")
+	}
+	return patchContainer(doc, tokens, func(container any, token string) (any, error) {
+		if _, err := pointerValue(container, []string{token}); err != nil {
+			return nil, err
+		}
+		switch container := container.(type) {
+		case map[string]any:
+			delete(container, token)
+			return container, nil
+		case []any:
+			i, _ := arrayIndex(token, len(container), false)
+			return slices.Delete(container, i, i+1), nil
+		}
+		return container, nil
+	})
+}
+
+// copyJSON returns a deep copy of the decoded JSON value v.
+func copyJSON(v any) any {
+	switch v := v.(type) {
+	case map[string]any:
+		c := maps.Clone(v)
+		for name, value := range c {
+			c[name] = copyJSON(value)
+		}
+		return c
+	case []any:
+		c := slices.Clone(v)
+		for i, value := range c {
+			c[i] = copyJSON(value)
+		}
+		return c
+	}
+	return v
+}
+
+// decodeJSON decodes data as an arbitrary JSON value, keeping numbers as
+// written.
+func decodeJSON(data []byte) (any, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var v any
+	if err := dec.Decode(&v); err != nil {
+		return nil, err
+	}
+	return v, nil
+}
+
+// applyJSONPatch returns doc with the operations of the JSON Patch, RFC
+// 6902, applied in turn. Operations that don't apply to doc fail with
+// errPatchConflict, and the patch with them.
+func applyJSONPatch(doc any, patch []jsonPatchOperation) (any, error) {
+	for i, op := range patch {
+		path, err := jsonPointer(op.Path)
+		if err != nil {
+			return nil, fmt.Errorf("operation %d: %w", i, err)
+		}
+		var value, from any
+		switch op.Op {
+		case "add", "replace", "test":
+			if op.Value == nil {
+				return nil, fmt.Errorf("operation %d: %s requires a value", i, op.Op)
+			}
+			if value, err = decodeJSON(op.Value); err != nil {
+				return nil, fmt.Errorf("operation %d: %w", i, err)
+			}
+		case "move", "copy":
+			tokens, err := jsonPointer(op.From)
+			if err != nil {
+				return nil, fmt.Errorf("operation %d: %w", i, err)
+			}
+			if op.Op == "move" && strings.HasPrefix(op.Path+"/", op.From+"/") && op.Path != op.From {
+				return nil, fmt.Errorf("operation %d: %q can't be moved into itself", i, op.From)
+			}
+			if from, err = pointerValue(doc, tokens); err != nil {
+				return nil, fmt.Errorf("operation %d: %w", i, err)
+			}
+			if op.Op == "move" {
+				if doc, err = removeValue(doc, tokens); err != nil {
+					return nil, fmt.Errorf("operation %d: %w", i, err)
+				}
+			} else {
+				from = copyJSON(from)
+			}
+		case "remove":
+		default:
+			return nil, fmt.Errorf("operation %d: unknown op %q", i, op.Op)
+		}
+
+		switch op.Op {
+		case "add":
+			doc, err = addValue(doc, path, value)
+		case "remove":
+			doc, err = removeValue(doc, path)
+		case "replace":
+			if _, err = pointerValue(doc, path); err == nil && len(path) > 0 {
+				doc, err = removeValue(doc, path)
+			}
+			if err == nil {
+				doc, err = addValue(doc, path, value)
+			}
+		case "move", "copy":
+			doc, err = addValue(doc, path, from)
+		case "test":
+			var current any
+			if current, err = pointerValue(doc, path); err == nil && !reflect.DeepEqual(current, value) {
+				err = fmt.Errorf("%w: test of %q failed", errPatchConflict, op.Path)
+			}
+		}
+		if err != nil {
+			return nil, fmt.Errorf("operation %d: %w", i, err)
+		}
+	}
+	return doc, nil
+}
+
+// patchItem returns item with the patch, of the media type mediaType,
+// applied to its JSON encoding.
+func patchItem(item RadarItem, mediaType string, patch []byte) (RadarItem, error) {
+	encoded, err := json.Marshal(item)
+	if err != nil {
+		return RadarItem{}, err
+	}
+	doc, err := decodeJSON(encoded)
+	if err != nil {
+		return RadarItem{}, err
+	}
+	switch mediaType {
+	case mergePatchType:
+		value, err := decodeJSON(patch)
+		if err != nil {
+			return RadarItem{}, &AppError{Code: http.StatusBadRequest, Message: "Invalid patch: " + err.Error(), Err: err}
+		}
+		doc = mergePatch(doc, value)
+	case jsonPatchType:
+		var operations []jsonPatchOperation
+		if err := json.Unmarshal(patch, &operations); err != nil {
+			return RadarItem{}, &AppError{Code: http.StatusBadRequest, Message: "Invalid patch: " + err.Error(), Err: err}
+		}
+		if doc, err = applyJSONPatch(doc, operations); errors.Is(err, errPatchConflict) {
+			return RadarItem{}, &AppError{Code: http.StatusConflict, Message: "Patch doesn't apply: " + err.Error(), Err: err}
+		} else if err != nil {
+			return RadarItem{}, &AppError{Code: http.StatusBadRequest, Message: "Invalid patch: " + err.Error(), Err: err}
+		}
+	}
+	if encoded, err = json.Marshal(doc); err != nil {
+		return RadarItem{}, err
+	}
+	return parseItem(encoded)
+}
+
+// patchItemHandler applies the JSON Merge Patch or JSON Patch in the body to
+// the item with the ID given by the path, which it may not change, and
+// responds with the item as saved.
+func patchItemHandler(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if mediaType != mergePatchType && mediaType != jsonPatchType {
+		w.Header().Set("Accept-Patch", mergePatchType+", "+jsonPatchType)
+		handleError(w, &AppError{Code: http.StatusUnsupportedMediaType, Message: fmt.Sprintf("Unsupported content type %q, patches are sent as %s or %s", mediaType, mergePatchType, jsonPatchType)})
+		return
+	}
+	patch, err := readItemBody(w, r)
+	if err != nil {
+		handleError(w, err)
+		return
+	}
+	editMu.Lock()
+	defer editMu.Unlock()
+	store, data, err := editableData()
+	if err != nil {
+		handleError(w, err)
+		return
+	}
+	i := slices.IndexFunc(data.Items, func(item RadarItem) bool { return item.ID == id })
+	if i < 0 {
+		handleError(w, &AppError{Code: http.StatusNotFound, Message: "Unknown item"})
+		return
+	}
+	if err := checkIfMatch(r, data.Items[i]); err != nil {
+		handleError(w, err)
+		return
+	}
+	item, err := patchItem(data.Items[i], mediaType, patch)
+	if err != nil {
+		handleError(w, err)
+		return
+	}
+	if item.ID != id {
+		handleError(w, &AppError{Code: http.StatusBadRequest, Message: "The ID of an item can't be patched"})
+		return
+	}
+
+	item.Source = data.Items[i].Source
+	data.Items = slices.Clone(data.Items)
+	data.Items[i] = item
+	saved, err := saveEdit(r, store, data, AuditEvent{Action: "patch item", Detail: id})
+	if err != nil {
+		handleError(w, err)
+		return
+	}
+	log.Printf("Patched item %s", id)
+	item, _ = findItem(saved.Items, id)
+	writeItem(w, http.StatusOK, item)
+}
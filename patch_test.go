@@ -0,0 +1,140 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestMergePatch(t *testing.T) {
+	// Examples of RFC 7396, appendix A.
+	for _, tt := range []struct{ target, patch, want string }{
+		{`{"a":"b"}`, `{"a":"c"}`, `{"a":"c"}`},
+		{`{"a":"b"}`, `{"b":"c"}`, `{"a":"b","b":"c"}`},
+		{`{"a":"b"}`, `{"a":null}`, `{}`},
+		{`{"a":"b","b":"c"}`, `{"a":null}`, `{"b":"c"}`},
+		{`{"a":["b"]}`, `{"a":"c"}`, `{"a":"c"}`},
+		{`{"a":"c"}`, `{"a":["b"]}`, `{"a":["b"]}`},
+		{`{"a":{"b":"c"}}`, `{"a":{"b":"d","c":null}}`, `{"a":{"b":"d"}}`},
+		{`{"a":[{"b":"c"}]}`, `{"a":[1]}`, `{"a":[1]}`},
+		{`["a","b"]`, `["c","d"]`, `["c","d"]`},
+		{`{"a":"b"}`, `["c"]`, `["c"]`},
+		{`{"a":"foo"}`, `null`, `null`},
+		{`{"e":null}`, `{"a":1}`, `{"a":1,"e":null}`},
+		{`[1,2]`, `{"a":"b","c":null}`, `{"a":"b"}`},
+		{`{}`, `{"a":{"bb":{"ccc":null}}}`, `{"a":{"bb":{}}}`},
+	} {
+		target, _ := decodeJSON([]byte(tt.target))
+		patch, _ := decodeJSON([]byte(tt.patch))
+		want, _ := decodeJSON([]byte(tt.want))
+		if got := mergePatch(target, patch); !reflect.DeepEqual(got, want) {
+			t.Errorf("mergePatch(%s, %s) = %v, want %s", tt.target, tt.patch, got, tt.want)
+		}
+	}
+}
+
+func TestApplyJSONPatch(t *testing.T) {
+	// Examples of RFC 6902, appendix A, and errors.
+	for _, tt := range []struct {
+		doc, patch, want string
+		conflict         bool
+	}{
+		{`{"foo":"bar"}`, `[{"op":"add","path":"/baz","value":"qux"}]`, `{"baz":"qux","foo":"bar"}`, false},
+		{`{"foo":["bar","baz"]}`, `[{"op":"add","path":"/foo/1","value":"qux"}]`, `{"foo":["bar","qux","baz"]}`, false},
+		{`{"foo":["bar"]}`, `[{"op":"add","path":"/foo/-","value":["abc","def"]}]`, `{"foo":["bar",["abc","def"]]}`, false},
+		{`{"baz":"qux","foo":"bar"}`, `[{"op":"remove","path":"/baz"}]`, `{"foo":"bar"}`, false},
+		{`{"foo":["bar","qux","baz"]}`, `[{"op":"remove","path":"/foo/1"}]`, `{"foo":["bar","baz"]}`, false},
+		{`{"baz":"qux","foo":"bar"}`, `[{"op":"replace","path":"/baz","value":"boo"}]`, `{"baz":"boo","foo":"bar"}`, false},
+		{`{"foo":{"bar":"baz","waldo":"fred"},"qux":{"corge":"grault"}}`, `[{"op":"move","from":"/foo/waldo","path":"/qux/thud"}]`, `{"foo":{"bar":"baz"},"qux":{"corge":"grault","thud":"fred"}}`, false},
+		{`{"foo":["all","grass","cows","eat"]}`, `[{"op":"move","from":"/foo/1","path":"/foo/3"}]`, `{"foo":["all","cows","eat","grass"]}`, false},
+		{`{"foo":{"bar":[1]}}`, `[{"op":"copy","from":"/foo/bar","path":"/baz"},{"op":"add","path":"/baz/-","value":2}]`, `{"foo":{"bar":[1]},"baz":[1,2]}`, false},
+		{`{"baz":"qux","foo":["a",2,"c"]}`, `[{"op":"test","path":"/baz","value":"qux"},{"op":"test","path":"/foo/1","value":2}]`, `{"baz":"qux","foo":["a",2,"c"]}`, false},
+		{`{"/":9,"~1":10}`, `[{"op":"replace","path":"/~01","value":null},{"op":"remove","path":"/~1"}]`, `{"~1":null}`, false},
+		{`{"foo":"bar"}`, `[{"op":"replace","path":"","value":[1]}]`, `[1]`, false},
+		{`{"baz":"qux"}`, `[{"op":"test","path":"/baz","value":"bar"}]`, ``, true},
+		{`{"foo":"bar"}`, `[{"op":"add","path":"/baz/bat","value":"qux"}]`, ``, true},
+		{`{"foo":"bar"}`, `[{"op":"remove","path":"/baz"}]`, ``, true},
+		{`{"foo":"bar"}`, `[{"op":"replace","path":"/baz","value":1}]`, ``, true},
+		{`{"foo":[1]}`, `[{"op":"add","path":"/foo/2","value":1}]`, ``, true},
+		{`{"foo":[1]}`, `[{"op":"remove","path":"/foo/01"}]`, ``, true},
+		{`{"foo":"bar"}`, `[{"op":"add","path":"/baz"}]`, ``, false},
+		{`{"foo":"bar"}`, `[{"op":"delete","path":"/foo"}]`, ``, false},
+		{`{"foo":"bar"}`, `[{"op":"remove","path":"foo"}]`, ``, false},
+		{`{"foo":{"bar":1}}`, `[{"op":"move","from":"/foo","path":"/foo/bar/baz"}]`, ``, false},
+	} {
+		doc, _ := decodeJSON([]byte(tt.doc))
+		var patch []jsonPatchOperation
+		if err := json.Unmarshal([]byte(tt.patch), &patch); err != nil {
+			t.Fatal(err)
+		}
+		got, err := applyJSONPatch(doc, patch)
+		if tt.want == "" {
+			if err == nil || errors.Is(err, errPatchConflict) != tt.conflict {
+				t.Errorf("applyJSONPatch(%s, %s) = %v, %v, want an error, a conflict: %t", tt.doc, tt.patch, got, err, tt.conflict)
+			}
+			continue
+		}
+		want, _ := decodeJSON([]byte(tt.want))
+		if err != nil || !reflect.DeepEqual(got, want) {
+			t.Errorf("applyJSONPatch(%s, %s) = %v, %v, want %s", tt.doc, tt.patch, got, err, tt.want)
+		}
+	}
+}
+
+func TestPatchItem(t *testing.T) {
+	cfg := defaultConfig()
+	cfg.Admin.Token = "s3cret"
+	cfg.Data.Path = writeFile(t, "radar.yaml", `Items:
+- Label: Go
+  Quadrant: Programming Languages & Frameworks
+  Ring: In Discovery
+  Description: A langauge.
+  Tags: [backend]
+`)
+	useConfig(t, cfg)
+	handler, err := setupRoutes(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rec := adminRequest(t, handler, http.MethodPatch, "/api/v1/items/go", http.Header{"Content-Type": {mergePatchType}, "If-Match": {"*"}}, `{"ring": "Adopted", "tags": null}`)
+	if rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), `"ring":"Adopted"`) || !strings.Contains(rec.Body.String(), `"description":"A langauge."`) || !strings.Contains(rec.Body.String(), `"tags":[]`) {
+		t.Errorf("merge patch = %d: %s", rec.Code, rec.Body)
+	}
+	rec = adminRequest(t, handler, http.MethodPatch, "/api/v1/items/go", http.Header{"Content-Type": {jsonPatchType}, "If-Match": {"*"}}, `[{"op": "test", "path": "/ring", "value": "Adopted"}, {"op": "replace", "path": "/description", "value": "A language."}, {"op": "add", "path": "/tags/-", "value": "cli"}]`)
+	if rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), `"description":"A language."`) || !strings.Contains(rec.Body.String(), `"tags":["cli"]`) {
+		t.Errorf("JSON patch = %d: %s", rec.Code, rec.Body)
+	}
+
+	for _, tt := range []struct {
+		contentType, body string
+		want              int
+	}{
+		{"application/json", `{"ring": "Adopted"}`, http.StatusUnsupportedMediaType},
+		{mergePatchType, `{"ring": "Hold"}`, http.StatusBadRequest},
+		{mergePatchType, `{"rign": "Hold"}`, http.StatusBadRequest},
+		{mergePatchType, `{"id": "golang"}`, http.StatusBadRequest},
+		{mergePatchType, `{"ring": `, http.StatusBadRequest},
+		{jsonPatchType, `[{"op": "test", "path": "/ring", "value": "In Discovery"}]`, http.StatusConflict},
+		{jsonPatchType, `[{"op": "remove", "path": "/owners/0"}]`, http.StatusConflict},
+		{jsonPatchType, `[{"op": "frobnicate", "path": "/ring"}]`, http.StatusBadRequest},
+	} {
+		if rec := adminRequest(t, handler, http.MethodPatch, "/api/v1/items/go", http.Header{"Content-Type": {tt.contentType}, "If-Match": {"*"}}, tt.body); rec.Code != tt.want {
+			t.Errorf("PATCH %s %s = %d, want %d: %s", tt.contentType, tt.body, rec.Code, tt.want, rec.Body)
+		}
+	}
+	if rec := adminRequest(t, handler, http.MethodPatch, "/api/v1/items/go", http.Header{"Content-Type": {"text/plain"}}, "x"); rec.Header().Get("Accept-Patch") != mergePatchType+", "+jsonPatchType {
+		t.Errorf("Accept-Patch = %q", rec.Header().Get("Accept-Patch"))
+	}
+	if rec := adminRequest(t, handler, http.MethodPatch, "/api/v1/items/cobol", http.Header{"Content-Type": {mergePatchType}, "If-Match": {"*"}}, `{}`); rec.Code != http.StatusNotFound {
+		t.Errorf("PATCH unknown item = %d, want 404", rec.Code)
+	}
+
+	rec = doRequest(t, handler, http.MethodGet, "/api/v1/radar/items/go")
+	if !strings.Contains(rec.Body.String(), `"ring":"Adopted"`) || !strings.Contains(rec.Body.String(), `"description":"A language."`) {
+		t.Errorf("patched item = %s", rec.Body)
+	}
+}
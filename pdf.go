@@ -0,0 +1,444 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Layout of PDF reports, in points: A4 pages with the same margin all round.
+const (
+	pdfPageWidth  = 595
+	pdfPageHeight = 842
+	pdfMargin     = 56
+)
+
+// PDF fonts, the standard Helvetica fonts every reader has.
+const (
+	pdfRegular = "F1"
+	pdfBold    = "F2"
+)
+
+// pdfFontNames are the base fonts of the PDF fonts.
+var pdfFontNames = map[string]string{pdfRegular: "Helvetica", pdfBold: "Helvetica-Bold"}
+
+// pdfWidths are the widths, in thousandths of the font size, of the
+// printable ASCII characters from the space on in the PDF fonts, from their
+// Adobe font metrics. Other characters are taken to be as wide as a digit.
+var pdfWidths = map[string][]int{
+	pdfRegular: {
+		278, 278, 355, 556, 556, 889, 667, 191, 333, 333, 389, 584, 278, 333, 278, 278,
+		556, 556, 556, 556, 556, 556, 556, 556, 556, 556, 278, 278, 584, 584, 584, 556,
+		1015, 667, 667, 722, 722, 667, 611, 778, 722, 278, 500, 667, 556, 833, 722, 778,
+		667, 778, 722, 667, 611, 722, 667, 944, 667, 667, 611, 278, 278, 278, 469, 556,
+		333, 556, 556, 500, 556, 556, 278, 556, 556, 222, 222, 500, 222, 833, 556, 556,
+		556, 556, 333, 500, 278, 556, 500, 722, 500, 500, 500, 334, 260, 334, 584,
+	},
+	pdfBold: {
+		278, 333, 474, 556, 556, 889, 722, 238, 333, 333, 389, 584, 278, 333, 278, 278,
+		556, 556, 556, 556, 556, 556, 556, 556, 556, 556, 333, 333, 584, 584, 584, 611,
+		975, 722, 722, 722, 722, 667, 611, 778, 722, 278, 556, 722, 611, 833, 722, 778,
+		667, 778, 722, 667, 611, 722, 667, 944, 667, 667, 611, 333, 278, 333, 584, 556,
+		333, 556, 611, 556, 611, 556, 333, 611, 611, 278, 278, 556, 278, 889, 611, 611,
+		611, 611, 389, 556, 333, 611, 556, 778, 556, 556, 500, 389, 280, 389, 584,
+	},
+}
+
+// winAnsiSpecials are the characters outside Latin-1 that WinAnsiEncoding,
+// the encoding of the PDF fonts, has codes for.
+var winAnsiSpecials = map[rune]byte{
+	'€': 0x80, '‚': 0x82, '„': 0x84, '…': 0x85, '‘': 0x91, '’': 0x92, '“': 0x93, '”': 0x94,
+	'•': 0x95, '–': 0x96, '—': 0x97, '™': 0x99,
+}
+
+// winAnsi encodes s in WinAnsiEncoding, with a question mark for characters
+// it has no code for.
+func winAnsi(s string) []byte {
+	b := make([]byte, 0, len(s))
+	for _, r := range s {
+		switch code, ok := winAnsiSpecials[r]; {
+		case ok:
+			b = append(b, code)
+		case r == '\t':
+			b = append(b, ' ')
+		case r >= 0x20 && r < 0x7f, r >= 0xa0 && r <= 0xff:
+			b = append(b, byte(r))
+		default:
+			b = append(b, '?')
+		}
+	}
+	return b
+}
+
+// pdfString returns text, encoded by winAnsi, as a PDF literal string.
+func pdfString(text []byte) string {
+	var b strings.Builder
+	b.WriteByte('(')
+	for _, c := range text {
+		switch {
+		case c == '(' || c == ')' || c == '\\':
+			b.WriteByte('\\')
+			b.WriteByte(c)
+		case c < 0x20 || c > 0x7e:
+			fmt.Fprintf(&b, "\\%03o", c)
+		default:
+			b.WriteByte(c)
+		}
+	}
+	b.WriteByte(')')
+	return b.String()
+}
+
+// pdfTextWidth returns the width in points of s set in font at size.
+func pdfTextWidth(font string, size float64, s string) float64 {
+	total := 0
+	for _, c := range winAnsi(s) {
+		if c >= 0x20 && c < 0x7f {
+			total += pdfWidths[font][c-0x20]
+		} else {
+			total += 556
+		}
+	}
+	return float64(total) * size / 1000
+}
+
+// pdfWrap splits s into lines of at most width points set in font at size,
+// breaking at spaces and, for words longer than a line, within them.
+func pdfWrap(font string, size, width float64, s string) []string {
+	var lines []string
+	for _, paragraph := range strings.Split(s, "\n") {
+		line := ""
+		for _, word := range strings.Fields(paragraph) {
+			candidate := strings.TrimSpace(line + " " + word)
+			if pdfTextWidth(font, size, candidate) <= width {
+				line = candidate
+				continue
+			}
+			if line != "" {
+				lines = append(lines, line)
+			}
+			line = ""
+			for _, r := range word {
+				if line != "" && pdfTextWidth(font, size, line+string(r)) > width {
+					lines = append(lines, line)
+					line = ""
+				}
+				line += string(r)
+			}
+		}
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+// pdfColor returns the PDF color operands of a hex color such as #0a0 or
+// #00c000.
+func pdfColor(color string) string {
+	hex := strings.TrimPrefix(color, "#")
+	if len(hex) == 3 {
+		hex = string([]byte{hex[0], hex[0], hex[1], hex[1], hex[2], hex[2]})
+	}
+	value, _ := strconv.ParseUint(hex, 16, 32)
+	return fmt.Sprintf("%.3f %.3f %.3f", float64(value>>16&0xff)/255, float64(value>>8&0xff)/255, float64(value&0xff)/255)
+}
+
+// pdfDocument lays out text top to bottom on the pages of a PDF document,
+// starting a page whenever the current one is full.
+type pdfDocument struct {
+	pages []*bytes.Buffer
+	// y is the baseline of the next line on the last page.
+	y float64
+}
+
+// newPage starts a page.
+func (d *pdfDocument) newPage() {
+	d.pages = append(d.pages, new(bytes.Buffer))
+	d.y = pdfPageHeight - pdfMargin
+}
+
+// need starts a page unless the current one has height points left.
+func (d *pdfDocument) need(height float64) {
+	if len(d.pages) == 0 || d.y-height < pdfMargin+20 {
+		d.newPage()
+	}
+}
+
+// textAt writes a line of text at x on the current page with its baseline
+// at y.
+func (d *pdfDocument) textAt(x, y float64, font string, size float64, s string) {
+	fmt.Fprintf(d.pages[len(d.pages)-1], "BT /%s %g Tf %.1f %.1f Td %s Tj ET\n", font, size, x, y, pdfString(winAnsi(s)))
+}
+
+// box fills a square of size points in color with its bottom left corner at
+// x and y.
+func (d *pdfDocument) box(x, y, size float64, color string) {
+	fmt.Fprintf(d.pages[len(d.pages)-1], "q %s rg %.1f %.1f %g %g re f Q\n", pdfColor(color), x, y, size, size)
+}
+
+// paragraph writes s wrapped to the width of the page less indent, in font
+// at size, below the previous text.
+func (d *pdfDocument) paragraph(font string, size, indent float64, s string) {
+	leading := size * 1.35
+	for _, line := range pdfWrap(font, size, pdfPageWidth-2*pdfMargin-indent, s) {
+		d.need(leading)
+		d.y -= leading
+		d.textAt(pdfMargin+indent, d.y, font, size, line)
+	}
+}
+
+// gap leaves height points of space below the previous text.
+func (d *pdfDocument) gap(height float64) {
+	d.y -= height
+}
+
+// bytes returns the document as a PDF file titled title, with the pages
+// numbered in their footers.
+func (d *pdfDocument) bytes(title string) []byte {
+	var out bytes.Buffer
+	var offsets []int
+	object := func(format string, args ...any) {
+		offsets = append(offsets, out.Len())
+		fmt.Fprintf(&out, "%d 0 obj\n", len(offsets))
+		fmt.Fprintf(&out, format, args...)
+		out.WriteString("\nendobj\n")
+	}
+
+	// Objects 1 to 5 are the catalog, the page tree, the fonts and the
+	// document information; each page is followed by its content.
+	out.WriteString("%PDF-1.4\n%\xe2\xe3\xcf\xd3\n")
+	var kids []string
+	for i := range d.pages {
+		kids = append(kids, fmt.Sprintf("%d 0 R", 6+2*i))
+	}
+	object("<< /Type /Catalog /Pages 2 0 R >>")
+	object("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), len(d.pages))
+	for _, font := range []string{pdfRegular, pdfBold} {
+		object("<< /Type /Font /Subtype /Type1 /BaseFont /%s /Encoding /WinAnsiEncoding >>", pdfFontNames[font])
+	}
+	object("<< /Title %s /Producer (Clean Tech Radar) >>", pdfString(winAnsi(title)))
+	for i, page := range d.pages {
+		footer := fmt.Sprintf("Page %d of %d", i+1, len(d.pages))
+		fmt.Fprintf(page, "q 0.4 g BT /%s 8 Tf %.1f %d Td %s Tj ET Q\n", pdfRegular, pdfPageWidth-pdfMargin-pdfTextWidth(pdfRegular, 8, footer), pdfMargin/2, pdfString(winAnsi(footer)))
+		object("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %d %d] /Resources << /Font << /F1 3 0 R /F2 4 0 R >> >> /Contents %d 0 R >>", pdfPageWidth, pdfPageHeight, 7+2*i)
+		object("<< /Length %d >>\nstream\n%s\nendstream", page.Len(), page.Bytes())
+	}
+
+	xref := out.Len()
+	fmt.Fprintf(&out, "xref\n0 %d\n0000000000 65535 f \n", len(offsets)+1)
+	for _, offset := range offsets {
+		fmt.Fprintf(&out, "%010d 00000 n \n", offset)
+	}
+	fmt.Fprintf(&out, "trailer\n<< /Size %d /Root 1 0 R /Info 5 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(offsets)+1, xref)
+	return out.Bytes()
+}
+
+// changeSummary describes change in a line of the change log.
+func changeSummary(change ItemChange) string {
+	switch change.Event {
+	case changeCreated, changeMoved:
+		return feedEntryTitle(change)
+	case changeArchived:
+		return change.Label + " archived"
+	case changeUnarchived:
+		return fmt.Sprintf("%s put back on the radar in %s", change.Label, change.Item.Ring)
+	case changeRemoved:
+		return change.Label + " removed"
+	}
+	return change.Label + " updated"
+}
+
+// reportChanges returns the changes of all, oldest first, made since since
+// or, when it is zero, since the snapshot before the current data: those of
+// the latest save or commit.
+func reportChanges(all []ItemChange, since time.Time) []ItemChange {
+	var changes []ItemChange
+	for _, change := range all {
+		if since.IsZero() {
+			if latest := all[len(all)-1]; change.Commit != latest.Commit || change.Snapshot != latest.Snapshot {
+				continue
+			}
+		} else if change.Time.Before(since) {
+			continue
+		}
+		changes = append(changes, change)
+	}
+	return changes
+}
+
+// pdfReport returns a PDF report of items: a summary page counting them by
+// quadrant and ring, a section for each quadrant of data listing them by
+// ring, and the change log of changes. Without history, the change log says
+// changes aren't tracked.
+func pdfReport(data RadarData, items []RadarItem, changes []ItemChange, history bool) []byte {
+	doc := &pdfDocument{}
+	doc.need(0)
+	doc.paragraph(pdfBold, 26, 0, "Clean Tech Radar")
+	var dates []string
+	if data.LastModified != "" {
+		dates = append(dates, "Last updated "+data.LastModified+".")
+	}
+	if data.ReviewDate != "" {
+		dates = append(dates, "Next review on "+data.ReviewDate+".")
+	}
+	if len(dates) > 0 {
+		doc.gap(4)
+		doc.paragraph(pdfRegular, 12, 0, strings.Join(dates, " "))
+	}
+	doc.gap(10)
+	doc.paragraph(pdfRegular, 11, 0, fmt.Sprintf("%d items in %d quadrants and %d rings, %d of them moved, %d changes in the change log.",
+		len(items), len(data.Quadrants), len(data.Rings), countMoved(items), len(changes)))
+
+	// The summary table has a column of quadrants and one for each ring,
+	// with names too long for their column wrapped.
+	doc.gap(16)
+	const labelWidth = 180.0
+	column := (pdfPageWidth - 2*pdfMargin - labelWidth) / float64(len(data.Rings)+1)
+	row := func(font string, label string, cells []string) {
+		lines := [][]string{pdfWrap(font, 10, labelWidth-8, label)}
+		height := len(lines[0])
+		for _, cell := range cells {
+			lines = append(lines, pdfWrap(font, 9, column-6, cell))
+			height = max(height, len(lines[len(lines)-1]))
+		}
+		doc.need(float64(height)*12 + 6)
+		doc.y -= 6
+		for i, cellLines := range lines {
+			x := pdfMargin + labelWidth + float64(i-1)*column
+			if i == 0 {
+				x = pdfMargin
+			}
+			for j, line := range cellLines {
+				doc.textAt(x, doc.y-12*float64(j+1), font, 10-float64(min(i, 1)), line)
+			}
+		}
+		doc.y -= float64(height) * 12
+	}
+	row(pdfBold, "", append(segmentNames(data.Rings), "Total"))
+	for _, quadrant := range data.Quadrants {
+		var cells []string
+		total := 0
+		for _, ring := range data.Rings {
+			count := 0
+			for _, item := range items {
+				if item.Quadrant == quadrant.Name && item.Ring == ring.Name {
+					count++
+				}
+			}
+			total += count
+			cells = append(cells, strconv.Itoa(count))
+		}
+		row(pdfRegular, quadrant.Name, append(cells, strconv.Itoa(total)))
+	}
+
+	for _, quadrant := range data.Quadrants {
+		doc.newPage()
+		doc.paragraph(pdfBold, 20, 0, quadrant.Name)
+		if quadrant.Description != "" {
+			doc.gap(2)
+			doc.paragraph(pdfRegular, 10, 0, quadrant.Description)
+		}
+		empty := true
+		for r, ring := range data.Rings {
+			var ringItems []RadarItem
+			for _, item := range items {
+				if item.Quadrant == quadrant.Name && item.Ring == ring.Name {
+					ringItems = append(ringItems, item)
+				}
+			}
+			if len(ringItems) == 0 {
+				continue
+			}
+			empty = false
+			doc.gap(12)
+			doc.need(40)
+			doc.y -= 16
+			doc.box(pdfMargin, doc.y-1, 11, segmentColor(data.Rings, r))
+			doc.textAt(pdfMargin+18, doc.y, pdfBold, 14, ring.Name)
+			for _, item := range ringItems {
+				doc.gap(6)
+				label := item.Label
+				if item.Moved {
+					label += " (moved)"
+				}
+				doc.paragraph(pdfBold, 11, 18, label)
+				if item.Description != "" {
+					doc.paragraph(pdfRegular, 10, 18, item.Description)
+				}
+				if names := item.Owners.names(); names != "" {
+					doc.paragraph(pdfRegular, 9, 18, "Owned by "+names+".")
+				}
+			}
+		}
+		if empty {
+			doc.gap(8)
+			doc.paragraph(pdfRegular, 10, 0, "No items.")
+		}
+	}
+
+	doc.newPage()
+	doc.paragraph(pdfBold, 20, 0, "Change Log")
+	doc.gap(8)
+	switch {
+	case !history:
+		doc.paragraph(pdfRegular, 10, 0, "Changes are only tracked with a store or data files in a Git repository.")
+	case len(changes) == 0:
+		doc.paragraph(pdfRegular, 10, 0, "No changes.")
+	}
+	for _, change := range changes {
+		doc.paragraph(pdfRegular, 10, 0, change.Time.UTC().Format(time.DateOnly)+"   "+changeSummary(change))
+	}
+	return doc.bytes("Clean Tech Radar")
+}
+
+// countMoved returns the number of items marked as moved.
+func countMoved(items []RadarItem) int {
+	n := 0
+	for _, item := range items {
+		if item.Moved {
+			n++
+		}
+	}
+	return n
+}
+
+// pdfHandler serves a PDF report of the items selectItems selects, with the
+// changes made since ?since=, an RFC 3339 time, or else since the snapshot
+// before the current data, for distributing the radar as a document.
+func pdfHandler(w http.ResponseWriter, r *http.Request) {
+	var since time.Time
+	if value := r.URL.Query().Get("since"); value != "" {
+		var err error
+		if since, err = time.Parse(time.RFC3339, value); err != nil {
+			handleError(w, &AppError{Code: http.StatusBadRequest, Message: fmt.Sprintf("Invalid since %q, must be a time such as 2024-01-01T00:00:00Z", value)})
+			return
+		}
+	}
+	data, err := loadRadarData()
+	if err != nil {
+		handleError(w, err)
+		return
+	}
+	data = withSegments(data)
+	items, err := selectItems(r, data)
+	if err != nil {
+		handleError(w, err)
+		return
+	}
+	all, err := loadRadarChanges(r.Context())
+	if err != nil && !errors.Is(err, errNoHistory) {
+		handleError(w, &AppError{Code: http.StatusInternalServerError, Message: "Failed to read radar changes", Err: err})
+		return
+	}
+	var changes []ItemChange
+	if len(all) > 0 {
+		changes = reportChanges(all, since)
+	}
+
+	w.Header().Set("Content-Type", "application/pdf")
+	w.Header().Set("Content-Disposition", `attachment; filename="tech-radar.pdf"`)
+	w.Write(pdfReport(data, items, changes, err == nil))
+}
@@ -0,0 +1,140 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestPDFWrap(t *testing.T) {
+	lines := pdfWrap(pdfRegular, 10, 60, "Fast and simple to learn\n\nSupercalifragilistic")
+	want := []string{"Fast and", "simple to", "learn", "", "Supercalifrag", "ilistic"}
+	if strings.Join(lines, "|") != strings.Join(want, "|") {
+		t.Errorf("pdfWrap() = %q, want %q", lines, want)
+	}
+	for _, line := range lines {
+		if width := pdfTextWidth(pdfRegular, 10, line); width > 60 {
+			t.Errorf("line %q is %g points wide", line, width)
+		}
+	}
+	if got := pdfString(winAnsi("Café (“new”) \\ ☃")); got != `(Caf\351 \(\223new\224\) \\ ?)` {
+		t.Errorf("pdfString() = %s", got)
+	}
+}
+
+func TestReportChanges(t *testing.T) {
+	day := func(d int) time.Time { return time.Date(2025, 1, d, 0, 0, 0, 0, time.UTC) }
+	all := []ItemChange{
+		{Event: changeCreated, Label: "Go", Time: day(1), Commit: "a"},
+		{Event: changeCreated, Label: "Perl", Time: day(2), Commit: "b"},
+		{Event: changeMoved, Label: "Go", Time: day(3), Commit: "c"},
+		{Event: changeRemoved, Label: "Perl", Time: day(3), Commit: "c"},
+	}
+	if got := reportChanges(all, time.Time{}); len(got) != 2 || got[0].Label != "Go" || got[1].Label != "Perl" {
+		t.Errorf("reportChanges(latest) = %+v", got)
+	}
+	if got := reportChanges(all, day(2)); len(got) != 3 {
+		t.Errorf("reportChanges(since) = %+v", got)
+	}
+}
+
+// checkPDF fails t unless content is a PDF file whose cross-reference table
+// points at each of its objects, and returns its number of pages.
+func checkPDF(t *testing.T, content []byte) int {
+	t.Helper()
+	if !bytes.HasPrefix(content, []byte("%PDF-1.4\n")) || !bytes.HasSuffix(content, []byte("%%EOF\n")) {
+		t.Fatalf("not a PDF file: %q", content)
+	}
+	startxref := regexp.MustCompile(`startxref\n(\d+)\n%%EOF\n$`).FindSubmatch(content)
+	if startxref == nil {
+		t.Fatal("no startxref")
+	}
+	xref, _ := strconv.Atoi(string(startxref[1]))
+	if !bytes.HasPrefix(content[xref:], []byte("xref\n")) {
+		t.Fatalf("startxref %d doesn't point at the xref table", xref)
+	}
+	for i, offset := range regexp.MustCompile(`(\d{10}) 00000 n `).FindAllSubmatch(content[xref:], -1) {
+		at, _ := strconv.Atoi(string(offset[1]))
+		if !bytes.HasPrefix(content[at:], fmt.Appendf(nil, "%d 0 obj\n", i+1)) {
+			t.Errorf("xref entry %d doesn't point at its object", i+1)
+		}
+	}
+	for _, stream := range regexp.MustCompile(`(?s)<< /Length (\d+) >>\nstream\n(.*?)\nendstream`).FindAllSubmatch(content, -1) {
+		if length, _ := strconv.Atoi(string(stream[1])); length != len(stream[2]) {
+			t.Errorf("stream of %d bytes has /Length %d", len(stream[2]), length)
+		}
+	}
+	count := regexp.MustCompile(`/Type /Pages /Kids \[[^]]*\] /Count (\d+)`).FindSubmatch(content)
+	if count == nil {
+		t.Fatal("no page tree")
+	}
+	pages, _ := strconv.Atoi(string(count[1]))
+	return pages
+}
+
+func TestPDFExport(t *testing.T) {
+	repo, commit := gitRadarRepo(t)
+	commit(radarWith("Go", "In Discovery") + "- Label: Perl\n  Quadrant: Techniques\n  Ring: Adopted\n")
+	commit("LastModified: March 2025\n" + radarWith("Go", "Adopted") + "  Moved: true\n  Description: Fast (and simple).\n  Owners: Platform Team\n")
+
+	cfg := defaultConfig()
+	cfg.Data.Path = filepath.Join(repo, "data", "radar.yaml")
+	useConfig(t, cfg)
+	handler, err := setupRoutes(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rec := doRequest(t, handler, http.MethodGet, "/api/v1/export.pdf")
+	if rec.Code != http.StatusOK || rec.Header().Get("Content-Type") != "application/pdf" {
+		t.Fatalf("GET /api/v1/export.pdf = %d %q", rec.Code, rec.Header().Get("Content-Type"))
+	}
+	content := rec.Body.Bytes()
+	// A summary page, one for each quadrant and the change log.
+	if pages := checkPDF(t, content); pages != 6 {
+		t.Errorf("report has %d pages, want 6", pages)
+	}
+	for _, want := range []string{
+		"(Last updated March 2025.)",
+		"(1 items in 4 quadrants and 3 rings, 1 of them moved, 2 changes in the change log.)",
+		"(Go \\(moved\\))",
+		"(Fast \\(and simple\\).)",
+		"(Owned by Platform Team.)",
+		"Go moved from In Discovery to Adopted)",
+		"Perl removed)",
+		"(Page 6 of 6)",
+	} {
+		if !bytes.Contains(content, []byte(want)) {
+			t.Errorf("report doesn't contain %s", want)
+		}
+	}
+
+	rec = doRequest(t, handler, http.MethodGet, "/api/v1/export.pdf?since=2000-01-01T00:00:00Z")
+	if !bytes.Contains(rec.Body.Bytes(), []byte("Perl added to Adopted)")) {
+		t.Error("report since 2000 doesn't list Perl being added")
+	}
+	if rec := doRequest(t, handler, http.MethodGet, "/api/v1/export.pdf?since=yesterday"); rec.Code != http.StatusBadRequest {
+		t.Errorf("GET /api/v1/export.pdf?since=yesterday = %d, want 400", rec.Code)
+	}
+}
+
+func TestPDFExportWithoutHistory(t *testing.T) {
+	cfg := defaultConfig()
+	cfg.Data.Path = writeFile(t, "radar.yaml", radarWith("Go", "Adopted"))
+	useConfig(t, cfg)
+	handler, err := setupRoutes(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rec := doRequest(t, handler, http.MethodGet, "/api/v1/export.pdf")
+	checkPDF(t, rec.Body.Bytes())
+	if !bytes.Contains(rec.Body.Bytes(), []byte("(Changes are only tracked with a store or data files in a Git repository.)")) {
+		t.Error("report without history doesn't say changes aren't tracked")
+	}
+}
@@ -0,0 +1,361 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Proposal states. A proposal is proposed until a reviewer takes it under
+// review, then approved by the required roles, see ReviewConfig, and
+// published to the radar, unless it is rejected or withdrawn first.
+const (
+	proposalProposed    = "proposed"
+	proposalUnderReview = "under-review"
+	proposalApproved    = "approved"
+	proposalPublished   = "published"
+	proposalRejected    = "rejected"
+	proposalWithdrawn   = "withdrawn"
+)
+
+// proposalMu serializes changes to proposals, so that an edit isn't lost to
+// another made at the same time.
+var proposalMu sync.Mutex
+
+// Proposal is a change to the radar submitted for review instead of made to
+// the live radar: a new item, a change to an item or the move of an item to
+// another ring.
+type Proposal struct {
+	ID     int64  `json:"id"`
+	Status string `json:"status"`
+	// ItemID is the item the proposal changes, empty for a new item.
+	ItemID string `json:"itemId,omitempty"`
+	// Item is the new or changed item, and Ring the ring an item moves to
+	// instead.
+	Item      *RadarItem `json:"item,omitempty"`
+	Ring      string     `json:"ring,omitempty"`
+	Rationale string     `json:"rationale,omitempty"`
+	Author    string     `json:"author"`
+	Created   time.Time  `json:"created"`
+	Updated   time.Time  `json:"updated"`
+	// Assignees are the reviewers it is assigned to, if any.
+	Assignees []string `json:"assignees,omitempty"`
+	// Decisions are the steps of its review, oldest first.
+	Decisions []Decision `json:"decisions,omitempty"`
+	// TokenHash is the SHA-256 hash of the token that edits and withdraws
+	// the proposal, kept by the Database but never shown.
+	TokenHash string `json:"-"`
+}
+
+// ProposalInput is the body of a proposal submitted or edited.
+type ProposalInput struct {
+	ItemID    string     `json:"itemId,omitempty"`
+	Item      *RadarItem `json:"item,omitempty"`
+	Ring      string     `json:"ring,omitempty"`
+	Rationale string     `json:"rationale,omitempty"`
+	Author    string     `json:"author"`
+}
+
+// ProposalReceipt is the response of a submitted proposal: the proposal and
+// the token that edits and withdraws it, which is shown only once.
+type ProposalReceipt struct {
+	Proposal
+	Token string `json:"token"`
+}
+
+// apply returns data with the change p proposes made to it.
+func (p Proposal) apply(data RadarData) (RadarData, error) {
+	if p.ItemID == "" {
+		data.Items = append(slices.Clone(data.Items), *p.Item)
+		return data, nil
+	}
+	i := slices.IndexFunc(data.Items, func(item RadarItem) bool { return item.ID == p.ItemID })
+	if i < 0 {
+		return RadarData{}, &AppError{Code: http.StatusBadRequest, Message: fmt.Sprintf("Unknown item %s", p.ItemID)}
+	}
+	item := data.Items[i]
+	if p.Item != nil {
+		source := item.Source
+		item = *p.Item
+		item.ID, item.Source = p.ItemID, source
+	} else {
+		item.Ring = p.Ring
+	}
+	data.Items = slices.Clone(data.Items)
+	data.Items[i] = item
+	return data, nil
+}
+
+// decodeProposal decodes the ProposalInput in the body of r into a proposal
+// and checks that it applies to the current radar data like an edit.
+func decodeProposal(w http.ResponseWriter, r *http.Request) (Proposal, error) {
+	body, err := readItemBody(w, r)
+	if err != nil {
+		return Proposal{}, err
+	}
+	// The item is parsed like an item write, so one read from the API can
+	// be proposed as it is.
+	var input struct {
+		ProposalInput
+		Item json.RawMessage `json:"item,omitempty"`
+	}
+	dec := json.NewDecoder(bytes.NewReader(body))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&input); err != nil {
+		return Proposal{}, &AppError{Code: http.StatusBadRequest, Message: "Invalid proposal: " + err.Error(), Err: err}
+	}
+	if _, err := dec.Token(); !errors.Is(err, io.EOF) {
+		return Proposal{}, &AppError{Code: http.StatusBadRequest, Message: "Invalid proposal: more than one JSON value"}
+	}
+	p := Proposal{ItemID: input.ItemID, Ring: input.Ring, Rationale: input.Rationale, Author: strings.TrimSpace(input.Author)}
+	if input.Item != nil {
+		item, err := parseItem(input.Item)
+		if err != nil {
+			return Proposal{}, err
+		}
+		p.Item = &item
+	}
+
+	switch {
+	case p.Author == "":
+		return Proposal{}, &AppError{Code: http.StatusBadRequest, Message: "A proposal requires an author"}
+	case (p.Item == nil) == (p.Ring == ""):
+		return Proposal{}, &AppError{Code: http.StatusBadRequest, Message: "A proposal requires either an item or the ring to move an item to"}
+	case p.Ring != "" && p.ItemID == "":
+		return Proposal{}, &AppError{Code: http.StatusBadRequest, Message: "A ring change requires the itemId of the item to move"}
+	case p.Item != nil && p.ItemID != "" && p.Item.ID != "" && p.Item.ID != p.ItemID:
+		return Proposal{}, &AppError{Code: http.StatusBadRequest, Message: fmt.Sprintf("Item ID %s doesn't match itemId", p.Item.ID)}
+	}
+
+	store := currentStore()
+	if store == nil {
+		return Proposal{}, &AppError{Code: http.StatusServiceUnavailable, Message: "Radar data not available yet"}
+	}
+	data, err := loadStoreData(store)
+	if err != nil {
+		return Proposal{}, err
+	}
+	if p.ItemID == "" && p.Item.ID != "" {
+		if _, ok := findItem(data.Items, p.Item.ID); ok {
+			return Proposal{}, &AppError{Code: http.StatusConflict, Message: fmt.Sprintf("Item %s already exists", p.Item.ID)}
+		}
+	}
+	proposed, err := p.apply(data)
+	if err != nil {
+		return Proposal{}, err
+	}
+	// The rationale justifies the proposal once it is published.
+	ctx := withAuditEvent(r.Context(), AuditEvent{Justification: strings.TrimSpace(p.Rationale)})
+	var errs ValidationErrors
+	if _, err := prepareSave(ctx, proposed, data); errors.As(err, &errs) {
+		return Proposal{}, invalidDataError("Invalid proposal", errs)
+	} else if err != nil {
+		return Proposal{}, &AppError{Code: http.StatusInternalServerError, Message: "Failed to check proposal", Err: err}
+	}
+	return p, nil
+}
+
+// proposalDB returns the Database keeping proposals: that of the active
+// Store, as other stores have nowhere to keep them.
+func proposalDB() (Database, error) {
+	if s, ok := currentStore().(*databaseStore); ok {
+		return s.db, nil
+	}
+	return nil, &AppError{Code: http.StatusConflict, Message: "Proposals require a database store, see store.driver"}
+}
+
+// findProposal returns the proposal of db with the ID given by the path of
+// r.
+func findProposal(ctx context.Context, db Database, r *http.Request) (Proposal, error) {
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		return Proposal{}, &AppError{Code: http.StatusNotFound, Message: "Unknown proposal"}
+	}
+	proposals, err := db.Proposals(ctx)
+	if err != nil {
+		return Proposal{}, &AppError{Code: http.StatusInternalServerError, Message: "Failed to read proposals", Err: err}
+	}
+	i := slices.IndexFunc(proposals, func(p Proposal) bool { return p.ID == id })
+	if i < 0 {
+		return Proposal{}, &AppError{Code: http.StatusNotFound, Message: "Unknown proposal"}
+	}
+	return proposals[i], nil
+}
+
+// proposalToken returns a new random token and its hash, as kept in
+// Proposal.TokenHash.
+func proposalToken() (token, hash string) {
+	token = rand.Text()
+	return token, tokenHash(token)
+}
+
+// tokenHash returns the hex-encoded SHA-256 hash of token.
+func tokenHash(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// checkProposalToken returns an error unless r carries, as an
+// "Authorization: Bearer" header, the token of p or the admin token.
+func checkProposalToken(w http.ResponseWriter, r *http.Request, p Proposal) error {
+	token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	admin := currentConfig().Admin.Token
+	switch {
+	case ok && subtle.ConstantTimeCompare([]byte(tokenHash(token)), []byte(p.TokenHash)) == 1:
+		return nil
+	case ok && admin != "" && subtle.ConstantTimeCompare([]byte(token), []byte(admin)) == 1:
+		return nil
+	}
+	w.Header().Set("WWW-Authenticate", `Bearer realm="clean-tech-radar"`)
+	return &AppError{Code: http.StatusUnauthorized, Message: "Changing a proposal requires its token"}
+}
+
+// writeProposal responds with v, a proposal, and status code.
+func writeProposal(w http.ResponseWriter, code int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		log.Printf("Failed to encode proposal: %v", err)
+	}
+}
+
+// listProposalsHandler lists the proposals, oldest first, only those in the
+// state given as ?status if set.
+func listProposalsHandler(w http.ResponseWriter, r *http.Request) {
+	db, err := proposalDB()
+	if err != nil {
+		handleError(w, err)
+		return
+	}
+	proposals, err := db.Proposals(r.Context())
+	if err != nil {
+		handleError(w, &AppError{Code: http.StatusInternalServerError, Message: "Failed to read proposals", Err: err})
+		return
+	}
+	if status := r.URL.Query().Get("status"); status != "" {
+		proposals = slices.DeleteFunc(proposals, func(p Proposal) bool { return p.Status != status })
+	}
+	writeProposal(w, http.StatusOK, struct {
+		Proposals []Proposal `json:"proposals"`
+	}{append([]Proposal{}, proposals...)})
+}
+
+// proposalHandler responds with the proposal with the ID given by the path.
+func proposalHandler(w http.ResponseWriter, r *http.Request) {
+	db, err := proposalDB()
+	if err != nil {
+		handleError(w, err)
+		return
+	}
+	p, err := findProposal(r.Context(), db, r)
+	if err != nil {
+		handleError(w, err)
+		return
+	}
+	writeProposal(w, http.StatusOK, p)
+}
+
+// createProposalHandler submits the proposal in the body, which anyone may,
+// and responds with its ProposalReceipt and URL as the Location. The live
+// radar is left as it is.
+func createProposalHandler(w http.ResponseWriter, r *http.Request) {
+	db, err := proposalDB()
+	if err != nil {
+		handleError(w, err)
+		return
+	}
+	p, err := decodeProposal(w, r)
+	if err != nil {
+		handleError(w, err)
+		return
+	}
+	token, hash := proposalToken()
+	p.Status, p.TokenHash = proposalProposed, hash
+	p.Created = time.Now().UTC().Truncate(time.Second)
+	p.Updated = p.Created
+	proposalMu.Lock()
+	defer proposalMu.Unlock()
+	if p, err = db.SaveProposal(r.Context(), p); err != nil {
+		handleError(w, &AppError{Code: http.StatusInternalServerError, Message: "Failed to save proposal", Err: err})
+		return
+	}
+	log.Printf("Proposal %d submitted by %s from %s", p.ID, p.Author, r.RemoteAddr)
+	w.Header().Set("Location", apiURL("/proposals/"+strconv.FormatInt(p.ID, 10)))
+	writeProposal(w, http.StatusCreated, ProposalReceipt{Proposal: p, Token: token})
+}
+
+// updateProposalHandler replaces the proposal with the ID given by the path
+// with the one in the body, as long as it is proposed, and responds with it.
+func updateProposalHandler(w http.ResponseWriter, r *http.Request) {
+	changeProposal(w, r, "edited", []string{proposalProposed}, func(p *Proposal) error {
+		edited, err := decodeProposal(w, r)
+		if err != nil {
+			return err
+		}
+		edited.ID, edited.Status, edited.Created, edited.TokenHash = p.ID, p.Status, p.Created, p.TokenHash
+		edited.Assignees, edited.Decisions = p.Assignees, p.Decisions
+		*p = edited
+		return nil
+	})
+}
+
+// withdrawProposalHandler withdraws the proposal with the ID given by the
+// path, which is kept, and responds with it. It may be withdrawn until it
+// is decided on.
+func withdrawProposalHandler(w http.ResponseWriter, r *http.Request) {
+	changeProposal(w, r, "withdrawn", []string{proposalProposed, proposalUnderReview}, func(p *Proposal) error {
+		p.Status = proposalWithdrawn
+		return nil
+	})
+}
+
+// changeProposal makes change to the proposal with the ID given by the path
+// of r, if r carries its token and it is in one of states, saves it and
+// responds with it, logging it as action.
+func changeProposal(w http.ResponseWriter, r *http.Request, action string, states []string, change func(p *Proposal) error) {
+	db, err := proposalDB()
+	if err != nil {
+		handleError(w, err)
+		return
+	}
+	proposalMu.Lock()
+	defer proposalMu.Unlock()
+	p, err := findProposal(r.Context(), db, r)
+	if err != nil {
+		handleError(w, err)
+		return
+	}
+	if err := checkProposalToken(w, r, p); err != nil {
+		handleError(w, err)
+		return
+	}
+	if !slices.Contains(states, p.Status) {
+		handleError(w, &AppError{Code: http.StatusConflict, Message: fmt.Sprintf("Proposal %d is %s", p.ID, p.Status)})
+		return
+	}
+	if err := change(&p); err != nil {
+		handleError(w, err)
+		return
+	}
+	p.Updated = time.Now().UTC().Truncate(time.Second)
+	if p, err = db.SaveProposal(r.Context(), p); err != nil {
+		handleError(w, &AppError{Code: http.StatusInternalServerError, Message: "Failed to save proposal", Err: err})
+		return
+	}
+	log.Printf("Proposal %d %s from %s", p.ID, action, r.RemoteAddr)
+	writeProposal(w, http.StatusOK, p)
+}
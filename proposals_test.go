@@ -0,0 +1,182 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestProposalStores(t *testing.T) {
+	for _, driver := range []string{"sqlite", "bbolt"} {
+		t.Run(driver, func(t *testing.T) {
+			db := openTestStore(t, driver, filepath.Join(t.TempDir(), "radar.db"))
+			ctx := context.Background()
+			created := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+			first, err := db.SaveProposal(ctx, Proposal{Status: proposalProposed, ItemID: "go", Ring: "Adopted", Author: "Jane", Created: created, TokenHash: "abc"})
+			if err != nil {
+				t.Fatal(err)
+			}
+			second, err := db.SaveProposal(ctx, Proposal{Status: proposalProposed, Item: &RadarItem{Label: "Rust"}, Author: "Joe", Created: created})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if first.ID == 0 || second.ID == first.ID {
+				t.Fatalf("IDs = %d, %d", first.ID, second.ID)
+			}
+			first.Status = proposalWithdrawn
+			if _, err := db.SaveProposal(ctx, first); err != nil {
+				t.Fatal(err)
+			}
+			if _, err := db.SaveProposal(ctx, Proposal{ID: 99, Status: proposalProposed}); err == nil {
+				t.Error("saving an unknown proposal succeeded")
+			}
+
+			proposals, err := db.Proposals(ctx)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(proposals) != 2 || proposals[0].ID != first.ID || proposals[0].Status != proposalWithdrawn || proposals[0].TokenHash != "abc" || !proposals[0].Created.Equal(created) {
+				t.Fatalf("proposals = %+v", proposals)
+			}
+			if proposals[1].Item == nil || proposals[1].Item.Label != "Rust" || proposals[1].Author != "Joe" {
+				t.Errorf("second proposal = %+v", proposals[1])
+			}
+		})
+	}
+}
+
+func TestProposals(t *testing.T) {
+	db := openTestStore(t, "sqlite", "file::memory:")
+	seed := RadarData{Items: []RadarItem{{Label: "Go", Quadrant: "Tools", Ring: "In Discovery"}}}
+	if err := db.Save(context.Background(), seed, AuditEvent{Actor: "test", Action: "seed"}); err != nil {
+		t.Fatal(err)
+	}
+	cfg := defaultConfig()
+	cfg.Admin.Token = "s3cret"
+	useConfig(t, cfg)
+	useStore(t, &databaseStore{db: db})
+	handler, err := setupRoutes(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	send := func(method, target, token, body string) *httptest.ResponseRecorder {
+		t.Helper()
+		req := httptest.NewRequest(method, target, strings.NewReader(body))
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		return rec
+	}
+
+	// Anyone may propose, and the radar stays as it is.
+	rec := send(http.MethodPost, "/api/v1/proposals", "", `{"item": {"label": "Rust", "quadrant": "Tools", "ring": "In Discovery"}, "rationale": "Memory safety.", "author": "Jane"}`)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("POST = %d %s", rec.Code, rec.Body)
+	}
+	var receipt ProposalReceipt
+	if err := json.Unmarshal(rec.Body.Bytes(), &receipt); err != nil {
+		t.Fatal(err)
+	}
+	if receipt.Token == "" || receipt.Status != proposalProposed || receipt.Item.Label != "Rust" || rec.Header().Get("Location") != "/api/v1/proposals/1" {
+		t.Fatalf("receipt = %+v, Location %q", receipt, rec.Header().Get("Location"))
+	}
+	if strings.Contains(rec.Body.String(), "tokenHash") {
+		t.Errorf("receipt shows the token hash: %s", rec.Body)
+	}
+	if data, _ := loadRadarData(); len(data.Items) != 1 {
+		t.Errorf("radar items after proposal = %+v", data.Items)
+	}
+
+	move := send(http.MethodPost, "/api/v1/proposals", "", `{"itemId": "go", "ring": "Adopted", "author": "Joe"}`)
+	if move.Code != http.StatusCreated {
+		t.Fatalf("ring change = %d %s", move.Code, move.Body)
+	}
+
+	for _, bad := range []struct {
+		body string
+		code int
+	}{
+		{`{"itemId": "go", "ring": "Adopted"}`, http.StatusBadRequest},
+		{`{"author": "Jane"}`, http.StatusBadRequest},
+		{`{"ring": "Adopted", "author": "Jane"}`, http.StatusBadRequest},
+		{`{"itemId": "perl", "ring": "Adopted", "author": "Jane"}`, http.StatusBadRequest},
+		{`{"itemId": "go", "ring": "Sometime", "author": "Jane"}`, http.StatusBadRequest},
+		{`{"item": {"label": "Go", "quadrant": "Tools", "ring": "Adopted"}, "author": "Jane"}`, http.StatusBadRequest},
+		{`{"item": {"id": "go", "label": "Golang", "quadrant": "Tools", "ring": "Adopted"}, "author": "Jane"}`, http.StatusConflict},
+		{`{"itemId": "go", "ring": "Adopted", "author": "Jane", "votes": 3}`, http.StatusBadRequest},
+	} {
+		if rec := send(http.MethodPost, "/api/v1/proposals", "", bad.body); rec.Code != bad.code {
+			t.Errorf("POST %s = %d %s, want %d", bad.body, rec.Code, rec.Body, bad.code)
+		}
+	}
+
+	// Edits need the token of the proposal, or the admin token.
+	edit := `{"item": {"label": "Rust", "quadrant": "Tools", "ring": "In Discovery", "description": "Safe systems language."}, "author": "Jane"}`
+	if rec := send(http.MethodPut, "/api/v1/proposals/1", "", edit); rec.Code != http.StatusUnauthorized {
+		t.Errorf("PUT without token = %d", rec.Code)
+	}
+	if rec := send(http.MethodPut, "/api/v1/proposals/1", "wrong", edit); rec.Code != http.StatusUnauthorized {
+		t.Errorf("PUT with wrong token = %d", rec.Code)
+	}
+	rec = send(http.MethodPut, "/api/v1/proposals/1", receipt.Token, edit)
+	var edited Proposal
+	if err := json.Unmarshal(rec.Body.Bytes(), &edited); err != nil || rec.Code != http.StatusOK {
+		t.Fatalf("PUT = %d %s", rec.Code, rec.Body)
+	}
+	if edited.ID != 1 || edited.Item.Description != "Safe systems language." || edited.Rationale != "" || !edited.Created.Equal(receipt.Created) {
+		t.Errorf("edited = %+v", edited)
+	}
+	if rec := send(http.MethodPost, "/api/v1/proposals/2/withdraw", receipt.Token, ""); rec.Code != http.StatusUnauthorized {
+		t.Errorf("withdrawing with another proposal's token = %d", rec.Code)
+	}
+	if rec := send(http.MethodPost, "/api/v1/proposals/2/withdraw", "s3cret", ""); rec.Code != http.StatusOK {
+		t.Errorf("withdrawing with the admin token = %d %s", rec.Code, rec.Body)
+	}
+	if rec := send(http.MethodPut, "/api/v1/proposals/2", "s3cret", `{"itemId": "go", "ring": "Adopted", "author": "Joe"}`); rec.Code != http.StatusConflict {
+		t.Errorf("editing a withdrawn proposal = %d", rec.Code)
+	}
+
+	list := func(target string) []Proposal {
+		t.Helper()
+		rec := send(http.MethodGet, target, "", "")
+		var body struct{ Proposals []Proposal }
+		if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil || rec.Code != http.StatusOK {
+			t.Fatalf("GET %s = %d %s", target, rec.Code, rec.Body)
+		}
+		return body.Proposals
+	}
+	if all := list("/api/v1/proposals"); len(all) != 2 || all[1].Status != proposalWithdrawn {
+		t.Errorf("proposals = %+v", all)
+	}
+	if proposed := list("/api/v1/proposals?status=proposed"); len(proposed) != 1 || proposed[0].ID != 1 {
+		t.Errorf("proposed = %+v", proposed)
+	}
+	if rec := send(http.MethodGet, "/api/v1/proposals/1", "", ""); rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), "Safe systems language.") {
+		t.Errorf("GET proposal = %d %s", rec.Code, rec.Body)
+	}
+	for _, target := range []string{"/api/v1/proposals/3", "/api/v1/proposals/x"} {
+		if rec := send(http.MethodGet, target, "", ""); rec.Code != http.StatusNotFound {
+			t.Errorf("GET %s = %d", target, rec.Code)
+		}
+	}
+}
+
+func TestProposalsRequireDatabase(t *testing.T) {
+	cfg := defaultConfig()
+	cfg.Data.Path = writeFile(t, "radar.yaml", "LastModified: January 2020\n"+radarWith("Go", "Adopted"))
+	useConfig(t, cfg)
+	handler, err := setupRoutes(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rec := doRequest(t, handler, http.MethodGet, "/api/v1/proposals"); rec.Code != http.StatusConflict {
+		t.Errorf("GET /proposals without a database = %d", rec.Code)
+	}
+}
@@ -0,0 +1,165 @@
+package main
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"time"
+	"unicode/utf8"
+)
+
+// Wire types of protobuf fields.
+const (
+	protoVarint  = 0
+	protoFixed64 = 1
+	protoBytes   = 2
+	protoFixed32 = 5
+)
+
+// protoMessage is a protobuf message in the wire format, built by
+// appending its fields. As in proto3, fields with their zero value are
+// left out, except embedded messages and optional fields.
+type protoMessage []byte
+
+// tag appends the key of field number field with wire type wire.
+func (m *protoMessage) tag(field, wire int) {
+	*m = binary.AppendUvarint(*m, uint64(field)<<3|uint64(wire))
+}
+
+// uint appends a varint field, such as an int32 or int64.
+func (m *protoMessage) uint(field int, v uint64) {
+	if v != 0 {
+		m.tag(field, protoVarint)
+		*m = binary.AppendUvarint(*m, v)
+	}
+}
+
+// int appends an int32 or int64 field. Negative numbers take ten bytes,
+// as they are encoded in two's complement.
+func (m *protoMessage) int(field int, v int64) {
+	m.uint(field, uint64(v))
+}
+
+// bool appends a bool field.
+func (m *protoMessage) bool(field int, v bool) {
+	if v {
+		m.uint(field, 1)
+	}
+}
+
+// string appends a string field.
+func (m *protoMessage) string(field int, s string) {
+	if s != "" {
+		m.bytes(field, []byte(s))
+	}
+}
+
+// strings appends a repeated string field.
+func (m *protoMessage) strings(field int, values []string) {
+	for _, s := range values {
+		m.bytes(field, []byte(s))
+	}
+}
+
+// bytes appends a length-delimited field, whether empty or not.
+func (m *protoMessage) bytes(field int, b []byte) {
+	m.tag(field, protoBytes)
+	*m = binary.AppendUvarint(*m, uint64(len(b)))
+	*m = append(*m, b...)
+}
+
+// message appends an embedded message field.
+func (m *protoMessage) message(field int, sub protoMessage) {
+	m.bytes(field, sub)
+}
+
+// timestamp appends a google.protobuf.Timestamp field, unless t is zero.
+func (m *protoMessage) timestamp(field int, t time.Time) {
+	if t.IsZero() {
+		return
+	}
+	var ts protoMessage
+	ts.int(1, t.Unix())
+	ts.int(2, int64(t.Nanosecond()))
+	m.message(field, ts)
+}
+
+// protoField is a field decoded from a protobuf message: the value of a
+// varint or fixed-size field, or the bytes of a length-delimited one.
+type protoField struct {
+	number int
+	wire   int
+	value  uint64
+	data   []byte
+}
+
+// errProtoTruncated is returned for a message that ends within a field.
+var errProtoTruncated = errors.New("truncated protobuf message")
+
+// decodeProto splits a protobuf message into its fields, in the order they
+// appear. Fields of the deprecated group wire types are rejected.
+func decodeProto(b []byte) ([]protoField, error) {
+	var fields []protoField
+	for len(b) > 0 {
+		key, n := binary.Uvarint(b)
+		if n <= 0 {
+			return nil, errProtoTruncated
+		}
+		b = b[n:]
+		f := protoField{number: int(key >> 3), wire: int(key & 7)}
+		if f.number < 1 || key>>3 > 1<<29-1 {
+			return nil, fmt.Errorf("invalid protobuf field number %d", key>>3)
+		}
+		switch f.wire {
+		case protoVarint:
+			if f.value, n = binary.Uvarint(b); n <= 0 {
+				return nil, errProtoTruncated
+			}
+			b = b[n:]
+		case protoFixed64:
+			if len(b) < 8 {
+				return nil, errProtoTruncated
+			}
+			f.value, b = binary.LittleEndian.Uint64(b), b[8:]
+		case protoFixed32:
+			if len(b) < 4 {
+				return nil, errProtoTruncated
+			}
+			f.value, b = uint64(binary.LittleEndian.Uint32(b)), b[4:]
+		case protoBytes:
+			size, n := binary.Uvarint(b)
+			if n <= 0 || size > uint64(len(b)-n) {
+				return nil, errProtoTruncated
+			}
+			f.data, b = b[n:n+int(size)], b[n+int(size):]
+		default:
+			return nil, fmt.Errorf("unsupported protobuf wire type %d", f.wire)
+		}
+		fields = append(fields, f)
+	}
+	return fields, nil
+}
+
+// string returns the value of a string field.
+func (f protoField) string() (string, error) {
+	if f.wire != protoBytes || !utf8.Valid(f.data) {
+		return "", fmt.Errorf("field %d is not a valid string", f.number)
+	}
+	return string(f.data), nil
+}
+
+// int32 returns the value of an int32 field.
+func (f protoField) int32() (int32, error) {
+	if f.wire != protoVarint {
+		return 0, fmt.Errorf("field %d is not an int32", f.number)
+	}
+	return int32(f.value), nil
+}
+
+// bool returns the value of a bool field.
+func (f protoField) bool() (bool, error) {
+	if f.wire != protoVarint {
+		return false, fmt.Errorf("field %d is not a bool", f.number)
+	}
+	return f.value != 0, nil
+}
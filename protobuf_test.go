@@ -0,0 +1,66 @@
+package main
+
+import (
+	"bytes"
+	"testing"
+	"time"
+)
+
+func TestProtoMessage(t *testing.T) {
+	var m protoMessage
+	m.string(1, "Go")
+	m.string(2, "")
+	m.bool(3, false)
+	m.int(4, 300)
+	m.strings(5, []string{"a", ""})
+	m.timestamp(6, time.Time{})
+	var owner protoMessage
+	m.message(7, owner)
+	// Fields with their zero value are left out, but repeated elements and
+	// embedded messages are not.
+	want := []byte{0x0a, 2, 'G', 'o', 0x20, 0xac, 0x02, 0x2a, 1, 'a', 0x2a, 0, 0x3a, 0}
+	if !bytes.Equal(m, want) {
+		t.Fatalf("message = % x, want % x", []byte(m), want)
+	}
+
+	fields, err := decodeProto(m)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(fields) != 5 || fields[1].value != 300 || string(fields[2].data) != "a" {
+		t.Errorf("decoded %+v", fields)
+	}
+	if s, err := fields[0].string(); s != "Go" || err != nil {
+		t.Errorf("string() = %q, %v", s, err)
+	}
+	if _, err := fields[1].string(); err == nil {
+		t.Error("a varint decoded as a string")
+	}
+
+	var ts protoMessage
+	ts.timestamp(1, time.Unix(1700000000, 5))
+	inner, _ := decodeProto(ts)
+	if seconds, _ := decodeProto(inner[0].data); len(seconds) != 2 || seconds[0].value != 1700000000 || seconds[1].value != 5 {
+		t.Errorf("timestamp = %+v", seconds)
+	}
+}
+
+func TestDecodeProtoErrors(t *testing.T) {
+	for name, b := range map[string][]byte{
+		"truncated varint":  {0x08, 0x80},
+		"truncated bytes":   {0x0a, 5, 'a'},
+		"truncated fixed32": {0x0d, 1, 2},
+		"group":             {0x0b},
+		"field zero":        {0x00, 1},
+	} {
+		if _, err := decodeProto(b); err == nil {
+			t.Errorf("%s: decodeProto(% x) succeeded", name, b)
+		}
+	}
+}
+
+func TestGRPCMessage(t *testing.T) {
+	if got := grpcMessage("Invalid ring \"Höld\": 100%"); got != "Invalid ring \"H%C3%B6ld\": 100%25" {
+		t.Errorf("grpcMessage = %q", got)
+	}
+}
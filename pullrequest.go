@@ -0,0 +1,158 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// githubClient calls the GitHub API.
+var githubClient = &http.Client{Timeout: 30 * time.Second}
+
+// PullRequest is a pull request opened for a write instead of making it.
+// gitStore.Save returns it as its error, as the radar data is unchanged
+// until the pull request is merged, and handleError answers with it.
+type PullRequest struct {
+	Number int    `json:"number"`
+	URL    string `json:"url"`
+	Branch string `json:"branch"`
+}
+
+func (p *PullRequest) Error() string {
+	return "opened pull request " + p.URL
+}
+
+// writePullRequest answers a write with the pull request opened for it.
+func writePullRequest(w http.ResponseWriter, pr *PullRequest) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Location", pr.URL)
+	w.WriteHeader(http.StatusAccepted)
+	if err := json.NewEncoder(w).Encode(map[string]*PullRequest{"pullRequest": pr}); err != nil {
+		log.Printf("Failed to encode pull request: %v", err)
+	}
+}
+
+// githubError is an error response of the GitHub API.
+type githubError struct {
+	Status  int
+	Message string
+}
+
+func (e *githubError) Error() string {
+	return fmt.Sprintf("GitHub API: %d %s", e.Status, e.Message)
+}
+
+// githubAPI makes the request method to path of the GitHub API of cfg,
+// sending body as JSON unless it is nil, and decodes the response into
+// result unless it is nil.
+func githubAPI(ctx context.Context, cfg PullRequestConfig, method, path string, body, result any) error {
+	var reader io.Reader
+	if body != nil {
+		encoded, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reader = bytes.NewReader(encoded)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, strings.TrimSuffix(cfg.APIURL, "/")+path, reader)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/vnd.github+json")
+	req.Header.Set("Authorization", "Bearer "+cfg.Token)
+	req.Header.Set("X-GitHub-Api-Version", "2022-11-28")
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	resp, err := githubClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		var msg struct {
+			Message string `json:"message"`
+		}
+		json.NewDecoder(io.LimitReader(resp.Body, 1<<16)).Decode(&msg)
+		return &githubError{Status: resp.StatusCode, Message: msg.Message}
+	}
+	if result == nil {
+		return nil
+	}
+	return json.NewDecoder(resp.Body).Decode(result)
+}
+
+// pullRequestBranch returns a new branch name for the pull request of a
+// write recorded as event.
+func pullRequestBranch(event AuditEvent, now time.Time) string {
+	return fmt.Sprintf("radar/%s-%s-%s", itemSlug(event.Action), now.UTC().Format("20060102T150405Z"), strings.ToLower(rand.Text()[:6]))
+}
+
+// openPullRequest opens a pull request against the branch of cfg in the
+// repository of cfg.PullRequests, replacing the data file at cfg.Path with
+// content in a commit described by commitMessage and authored by
+// commitIdentity, if they have an email address.
+func openPullRequest(ctx context.Context, cfg GitConfig, path string, content []byte, event AuditEvent) (*PullRequest, error) {
+	prs := cfg.PullRequests
+	repo := "/repos/" + prs.Repo
+	var ref struct {
+		Object struct {
+			SHA string `json:"sha"`
+		} `json:"object"`
+	}
+	if err := githubAPI(ctx, prs, http.MethodGet, repo+"/git/ref/heads/"+url.PathEscape(cfg.Branch), nil, &ref); err != nil {
+		return nil, fmt.Errorf("reading branch %s: %w", cfg.Branch, err)
+	}
+	branch := pullRequestBranch(event, time.Now())
+	if err := githubAPI(ctx, prs, http.MethodPost, repo+"/git/refs", map[string]string{"ref": "refs/heads/" + branch, "sha": ref.Object.SHA}, nil); err != nil {
+		return nil, fmt.Errorf("creating branch %s: %w", branch, err)
+	}
+
+	contentsPath := repo + "/contents/" + (&url.URL{Path: filepath.ToSlash(filepath.Clean(path))}).EscapedPath()
+	var file struct {
+		SHA string `json:"sha"`
+	}
+	err := githubAPI(ctx, prs, http.MethodGet, contentsPath+"?ref="+url.QueryEscape(branch), nil, &file)
+	var ghErr *githubError
+	if err != nil && !(errors.As(err, &ghErr) && ghErr.Status == http.StatusNotFound) {
+		return nil, fmt.Errorf("reading %s: %w", path, err)
+	}
+	message := commitMessage(event)
+	update := map[string]any{
+		"message": message,
+		"content": base64.StdEncoding.EncodeToString(content),
+		"branch":  branch,
+	}
+	if file.SHA != "" {
+		update["sha"] = file.SHA
+	}
+	// GitHub requires an email address of authors; without one the commit
+	// is authored by the owner of the token.
+	if name, email := commitIdentity(currentConfig(), event.Actor); email != "" {
+		update["author"] = map[string]string{"name": name, "email": email}
+	}
+	if err := githubAPI(ctx, prs, http.MethodPut, contentsPath, update, nil); err != nil {
+		return nil, fmt.Errorf("committing %s: %w", path, err)
+	}
+
+	title, body, _ := strings.Cut(message, "\n\n")
+	var pull struct {
+		Number  int    `json:"number"`
+		HTMLURL string `json:"html_url"`
+	}
+	if err := githubAPI(ctx, prs, http.MethodPost, repo+"/pulls", map[string]string{"title": title, "body": body, "head": branch, "base": cfg.Branch}, &pull); err != nil {
+		return nil, fmt.Errorf("opening pull request: %w", err)
+	}
+	return &PullRequest{Number: pull.Number, URL: pull.HTMLURL, Branch: branch}, nil
+}
@@ -0,0 +1,124 @@
+package main
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// fakeGitHub serves the parts of the GitHub API openPullRequest uses, and
+// records the requests made to it.
+type fakeGitHub struct {
+	mu       sync.Mutex
+	requests []string
+	branch   string
+	update   map[string]any
+	pull     map[string]string
+}
+
+func (f *fakeGitHub) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.requests = append(f.requests, r.Method+" "+r.URL.Path)
+	if r.Header.Get("Authorization") != "Bearer gh-token" {
+		http.Error(w, `{"message": "Bad credentials"}`, http.StatusUnauthorized)
+		return
+	}
+	var body map[string]any
+	json.NewDecoder(r.Body).Decode(&body)
+	switch r.Method + " " + r.URL.Path {
+	case "GET /repos/acme/radar/git/ref/heads/main":
+		w.Write([]byte(`{"object": {"sha": "abc123"}}`))
+	case "POST /repos/acme/radar/git/refs":
+		if body["sha"] != "abc123" {
+			http.Error(w, `{"message": "Wrong base"}`, http.StatusUnprocessableEntity)
+			return
+		}
+		f.branch = strings.TrimPrefix(body["ref"].(string), "refs/heads/")
+		w.WriteHeader(http.StatusCreated)
+	case "GET /repos/acme/radar/contents/data/radar.yaml":
+		w.Write([]byte(`{"sha": "file456"}`))
+	case "PUT /repos/acme/radar/contents/data/radar.yaml":
+		f.update = body
+		w.WriteHeader(http.StatusCreated)
+	case "POST /repos/acme/radar/pulls":
+		f.pull = map[string]string{}
+		for key, value := range body {
+			f.pull[key], _ = value.(string)
+		}
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte(`{"number": 7, "html_url": "https://github.com/acme/radar/pull/7"}`))
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func TestWritesOpenPullRequests(t *testing.T) {
+	repo, commit := gitRadarRepo(t)
+	commit(radarWith("Go", "Adopted"))
+	github := &fakeGitHub{}
+	server := httptest.NewServer(github)
+	t.Cleanup(server.Close)
+
+	cfg := defaultConfig()
+	cfg.Admin.Token = "s3cret"
+	cfg.Data.Commit.AdminAuthor = "Radar Admin <radar@example.com>"
+	cfg.Data.Git.URL = repo
+	cfg.Data.Git.Dir = filepath.Join(t.TempDir(), "checkout")
+	cfg.Data.Git.PullRequests = PullRequestConfig{Repo: "acme/radar", Token: "gh-token", APIURL: server.URL}
+	if err := cfg.validate(); err != nil {
+		t.Fatal(err)
+	}
+	if err := prepareGitData(cfg.Data); err != nil {
+		t.Fatal(err)
+	}
+	useConfig(t, cfg)
+	useStore(t, newGitStore(cfg.Data))
+	handler, err := setupRoutes(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rec := adminRequest(t, handler, http.MethodPost, "/api/v1/items", nil, `{"label": "Rust", "quadrant": "Tools", "ring": "In Discovery"}`)
+	var resp struct {
+		PullRequest PullRequest `json:"pullRequest"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil || rec.Code != http.StatusAccepted {
+		t.Fatalf("POST /api/v1/items = %d %s", rec.Code, rec.Body)
+	}
+	if pr := resp.PullRequest; pr.Number != 7 || pr.URL != "https://github.com/acme/radar/pull/7" || pr.Branch != github.branch || rec.Header().Get("Location") != pr.URL {
+		t.Errorf("pull request = %+v, Location %q", pr, rec.Header().Get("Location"))
+	}
+	if !strings.HasPrefix(github.branch, "radar/create-item-") {
+		t.Errorf("branch = %q", github.branch)
+	}
+	content, err := base64.StdEncoding.DecodeString(github.update["content"].(string))
+	if err != nil || !strings.Contains(string(content), "Label: Rust") || !strings.Contains(string(content), "Label: Go") {
+		t.Errorf("committed content = %q, %v", content, err)
+	}
+	if github.update["sha"] != "file456" || github.update["branch"] != github.branch || github.update["message"] != "Create item Rust\n\nMade by admin through the radar API from 192.0.2.1." {
+		t.Errorf("update = %+v", github.update)
+	}
+	if author, _ := github.update["author"].(map[string]any); author["name"] != "Radar Admin" || author["email"] != "radar@example.com" {
+		t.Errorf("author = %+v", github.update["author"])
+	}
+	if pull := github.pull; pull["title"] != "Create item Rust" || pull["head"] != github.branch || pull["base"] != "main" {
+		t.Errorf("pull request opened = %+v", pull)
+	}
+	// The radar is unchanged until the pull request is merged.
+	if data, err := loadRadarData(); err != nil || len(data.Items) != 1 {
+		t.Errorf("radar after write = %+v, %v", data, err)
+	}
+
+	cfg.Data.Git.PullRequests.Token = "wrong"
+	useConfig(t, cfg)
+	useStore(t, newGitStore(cfg.Data))
+	if rec := adminRequest(t, handler, http.MethodDelete, "/api/v1/items/go", anyVersion, ""); rec.Code != http.StatusInternalServerError {
+		t.Errorf("write with a bad GitHub token = %d %s", rec.Code, rec.Body)
+	}
+}
@@ -0,0 +1,152 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+)
+
+// Reactions to items: lightweight sentiment, unlike votes, which endorse an
+// item. The radar page shows them as 👍, 🚀 and ⚠️.
+const (
+	reactionThumbsUp = "+1"
+	reactionRocket   = "rocket"
+	reactionWarning  = "warning"
+)
+
+// reactionKinds are the reactions an item may get, in the order they are
+// shown.
+var reactionKinds = []string{reactionThumbsUp, reactionRocket, reactionWarning}
+
+// Reaction is the reaction of a user to a radar item.
+type Reaction struct {
+	ItemID   string    `json:"itemId"`
+	Reaction string    `json:"reaction"`
+	User     string    `json:"user"`
+	Created  time.Time `json:"created"`
+}
+
+// ReactionSummary counts the reactions to an item by kind, and lists those
+// of the user signed in.
+type ReactionSummary struct {
+	ItemID string         `json:"itemId"`
+	Counts map[string]int `json:"counts"`
+	Mine   []string       `json:"mine"`
+}
+
+// reactionDB returns the Database keeping reactions, see proposalDB.
+func reactionDB() (Database, error) {
+	if s, ok := currentStore().(*databaseStore); ok {
+		return s.db, nil
+	}
+	return nil, &AppError{Code: http.StatusConflict, Message: "Reactions require a database store, see store.driver"}
+}
+
+// reactionCounts counts reactions by kind, with every kind present.
+func reactionCounts(reactions []Reaction) map[string]int {
+	counts := map[string]int{}
+	for _, kind := range reactionKinds {
+		counts[kind] = 0
+	}
+	for _, reaction := range reactions {
+		counts[reaction.Reaction]++
+	}
+	return counts
+}
+
+// itemReactions returns the counts of the reactions to the item with the ID
+// id, or nil if there are none to count, as the store is not a database.
+func itemReactions(r *http.Request, id string) map[string]int {
+	db, err := reactionDB()
+	if err != nil {
+		return nil
+	}
+	reactions, err := db.Reactions(r.Context(), id)
+	if err != nil {
+		log.Printf("Failed to read reactions: %v", err)
+		return nil
+	}
+	return reactionCounts(reactions)
+}
+
+// writeReactions responds with the ReactionSummary of the item with the ID
+// itemID for user.
+func writeReactions(w http.ResponseWriter, r *http.Request, db Database, itemID, user string) {
+	reactions, err := db.Reactions(r.Context(), itemID)
+	if err != nil {
+		handleError(w, &AppError{Code: http.StatusInternalServerError, Message: "Failed to read reactions", Err: err})
+		return
+	}
+	summary := ReactionSummary{ItemID: itemID, Counts: reactionCounts(reactions), Mine: []string{}}
+	for _, kind := range reactionKinds {
+		mine := func(reaction Reaction) bool { return reaction.Reaction == kind && reaction.User == user }
+		if user != "" && slices.ContainsFunc(reactions, mine) {
+			summary.Mine = append(summary.Mine, kind)
+		}
+	}
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(summary); err != nil {
+		log.Printf("Failed to encode reactions: %v", err)
+	}
+}
+
+// reactionsHandler responds with the ReactionSummary of the item with the ID
+// given by the path, which anyone may see.
+func reactionsHandler(w http.ResponseWriter, r *http.Request) {
+	db, err := reactionDB()
+	if err != nil {
+		handleError(w, err)
+		return
+	}
+	itemID, err := pathItemID(r)
+	if err != nil {
+		handleError(w, err)
+		return
+	}
+	user, _ := userOf(r)
+	writeReactions(w, r, db, itemID, user)
+}
+
+// reactHandler adds the reaction given by the path of the user signed in,
+// see userOf, to the item with the ID given by the path, or removes it
+// unless add, and responds with the item's ReactionSummary.
+func reactHandler(add bool) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		db, err := reactionDB()
+		if err != nil {
+			handleError(w, err)
+			return
+		}
+		user, ok := userOf(r)
+		if !ok {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="clean-tech-radar"`)
+			handleError(w, &AppError{Code: http.StatusUnauthorized, Message: "Reacting requires signing in"})
+			return
+		}
+		kind := r.PathValue("reaction")
+		if !slices.Contains(reactionKinds, kind) {
+			handleError(w, &AppError{Code: http.StatusBadRequest, Message: fmt.Sprintf("Unknown reaction %q, must be one of %s", kind, strings.Join(reactionKinds, ", "))})
+			return
+		}
+		itemID, err := pathItemID(r)
+		if err != nil {
+			handleError(w, err)
+			return
+		}
+		reaction := Reaction{ItemID: itemID, Reaction: kind, User: user, Created: time.Now().UTC().Truncate(time.Second)}
+		if add {
+			_, err = db.SaveReaction(r.Context(), reaction)
+		} else {
+			_, err = db.DeleteReaction(r.Context(), reaction)
+		}
+		if err != nil {
+			handleError(w, &AppError{Code: http.StatusInternalServerError, Message: "Failed to save reaction", Err: err})
+			return
+		}
+		writeReactions(w, r, db, itemID, user)
+	})
+}
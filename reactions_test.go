@@ -0,0 +1,105 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestReactionStores(t *testing.T) {
+	for _, driver := range []string{"sqlite", "bbolt"} {
+		t.Run(driver, func(t *testing.T) {
+			db := openTestStore(t, driver, filepath.Join(t.TempDir(), "radar.db"))
+			ctx := context.Background()
+			created := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+			for _, reaction := range []Reaction{
+				{"go", reactionThumbsUp, "Jane", created},
+				{"go", reactionRocket, "Jane", created},
+				{"go", reactionThumbsUp, "Joe", created},
+				{"golang", reactionWarning, "Jane", created},
+			} {
+				if added, err := db.SaveReaction(ctx, reaction); err != nil || !added {
+					t.Fatalf("SaveReaction(%+v) = %v, %v", reaction, added, err)
+				}
+			}
+			if added, err := db.SaveReaction(ctx, Reaction{"go", reactionThumbsUp, "Jane", created}); err != nil || added {
+				t.Errorf("reacting again = %v, %v", added, err)
+			}
+			if deleted, err := db.DeleteReaction(ctx, Reaction{ItemID: "go", Reaction: reactionRocket, User: "Jane"}); err != nil || !deleted {
+				t.Errorf("DeleteReaction = %v, %v", deleted, err)
+			}
+
+			reactions, err := db.Reactions(ctx, "go")
+			if err != nil {
+				t.Fatal(err)
+			}
+			counts := reactionCounts(reactions)
+			if len(reactions) != 2 || counts[reactionThumbsUp] != 2 || counts[reactionRocket] != 0 || counts[reactionWarning] != 0 {
+				t.Errorf("reactions = %+v", reactions)
+			}
+			if !reactions[0].Created.Equal(created) {
+				t.Errorf("created = %v", reactions[0].Created)
+			}
+		})
+	}
+}
+
+func TestReactions(t *testing.T) {
+	db := openTestStore(t, "sqlite", "file::memory:")
+	seed := RadarData{Items: []RadarItem{{ID: "go", Label: "Go", Quadrant: "Tools", Ring: "Adopted"}}}
+	if err := db.Save(context.Background(), seed, AuditEvent{Actor: "test", Action: "seed"}); err != nil {
+		t.Fatal(err)
+	}
+	cfg := defaultConfig()
+	cfg.Server.TrustProxy, cfg.Server.UserHeader = true, "X-Forwarded-User"
+	useConfig(t, cfg)
+	useStore(t, &databaseStore{db: db})
+	handler, err := setupRoutes(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	react := func(method, target, user string) (ReactionSummary, int) {
+		t.Helper()
+		req := httptest.NewRequest(method, target, nil)
+		if user != "" {
+			req.Header.Set("X-Forwarded-User", user)
+		}
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		var summary ReactionSummary
+		if rec.Code == http.StatusOK {
+			if err := json.Unmarshal(rec.Body.Bytes(), &summary); err != nil {
+				t.Fatalf("%s %s: %v %s", method, target, err, rec.Body)
+			}
+		}
+		return summary, rec.Code
+	}
+
+	if _, code := react(http.MethodPut, "/api/v1/items/go/reactions/rocket", ""); code != http.StatusUnauthorized {
+		t.Errorf("anonymous reaction = %d", code)
+	}
+	if _, code := react(http.MethodPut, "/api/v1/items/go/reactions/heart", "jane"); code != http.StatusBadRequest {
+		t.Errorf("unknown reaction = %d", code)
+	}
+	react(http.MethodPut, "/api/v1/items/go/reactions/%2B1", "jane")
+	react(http.MethodPut, "/api/v1/items/go/reactions/rocket", "jane")
+	react(http.MethodPut, "/api/v1/items/go/reactions/rocket", "jane")
+	react(http.MethodPut, "/api/v1/items/go/reactions/rocket", "joe")
+	summary, code := react(http.MethodDelete, "/api/v1/items/go/reactions/%2B1", "jane")
+	if code != http.StatusOK || summary.Counts[reactionThumbsUp] != 0 || summary.Counts[reactionRocket] != 2 || len(summary.Mine) != 1 || summary.Mine[0] != reactionRocket {
+		t.Errorf("reactions after taking one back = %d %+v", code, summary)
+	}
+	if summary, _ := react(http.MethodGet, "/api/v1/items/go/reactions", ""); len(summary.Mine) != 0 || summary.Counts[reactionRocket] != 2 || summary.Counts[reactionWarning] != 0 {
+		t.Errorf("anonymous GET = %+v", summary)
+	}
+
+	rec := doRequest(t, handler, http.MethodGet, "/api/v1/radar/items/go?fields=id,reactions")
+	var detail ItemDetail
+	if err := json.Unmarshal(rec.Body.Bytes(), &detail); err != nil || detail.Reactions[reactionRocket] != 2 {
+		t.Errorf("GET /api/v1/radar/items/go = %d %s", rec.Code, rec.Body)
+	}
+}
@@ -0,0 +1,131 @@
+package main
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"slices"
+)
+
+// Types of relations between items.
+const (
+	relationReplaces  = "replaces"
+	relationRelatedTo = "related-to"
+	relationDependsOn = "depends-on"
+)
+
+// relationTypes are the types a relation may have.
+var relationTypes = []string{relationReplaces, relationRelatedTo, relationDependsOn}
+
+// Relation is a typed link from a radar item to another, such as Deno
+// replacing Node for scripts.
+type Relation struct {
+	Type string `yaml:"Type" json:"type" toml:"Type"`
+	// Item is the ID of the other item.
+	Item string `yaml:"Item" json:"item" toml:"Item"`
+}
+
+// Relations are the relations of a radar item. In JSON they are always an
+// array.
+type Relations []Relation
+
+// checkRelations reports the relations of items to items that don't exist
+// or to the item itself. items must have their IDs.
+func checkRelations(items []RadarItem) ValidationErrors {
+	var errs ValidationErrors
+	for _, item := range items {
+		for j, relation := range item.Relations {
+			field := fmt.Sprintf("item %q.Relations[%d].Item", item.Label, j)
+			switch _, ok := findItem(items, relation.Item); {
+			case relation.Item == item.ID:
+				errs = append(errs, ValidationError{File: item.Source, Field: field, Rule: ruleRelation, Message: "an item can't relate to itself"})
+			case !ok:
+				errs = append(errs, ValidationError{File: item.Source, Field: field, Rule: ruleRelation, Message: fmt.Sprintf("unknown item %q", relation.Item)})
+			}
+		}
+	}
+	return errs
+}
+
+// relatedBy returns the relations of the other items of items to the item
+// with the ID id, each with the ID of the item it is of as its Item.
+func relatedBy(items []RadarItem, id string) Relations {
+	var related Relations
+	for _, item := range items {
+		for _, relation := range item.Relations {
+			if relation.Item == id {
+				related = append(related, Relation{Type: relation.Type, Item: item.ID})
+			}
+		}
+	}
+	return related
+}
+
+// retargetRelations returns items with their relations to the items with the
+// IDs from pointed at the item with the ID to instead, or removed if to is
+// empty. Relations that would then point at the item itself, or repeat
+// another, are removed.
+func retargetRelations(items []RadarItem, from []string, to string) []RadarItem {
+	items = slices.Clone(items)
+	for i, item := range items {
+		if !slices.ContainsFunc(item.Relations, func(r Relation) bool { return slices.Contains(from, r.Item) }) {
+			continue
+		}
+		var relations Relations
+		for _, relation := range item.Relations {
+			if slices.Contains(from, relation.Item) {
+				relation.Item = to
+			}
+			if relation.Item != "" && relation.Item != item.ID && !slices.Contains(relations, relation) {
+				relations = append(relations, relation)
+			}
+		}
+		items[i].Relations = relations
+	}
+	return items
+}
+
+func (r Relations) MarshalJSON() ([]byte, error) {
+	if r == nil {
+		return []byte("[]"), nil
+	}
+	return json.Marshal([]Relation(r))
+}
+
+func (r *Relations) UnmarshalJSON(data []byte) error {
+	if err := json.Unmarshal(data, (*[]Relation)(r)); err != nil {
+		return err
+	}
+	if len(*r) == 0 {
+		*r = nil
+	}
+	return nil
+}
+
+// Value stores the relations in a SQL text column as a JSON array.
+func (r Relations) Value() (driver.Value, error) {
+	if len(r) == 0 {
+		return "", nil
+	}
+	data, err := json.Marshal([]Relation(r))
+	return string(data), err
+}
+
+// Scan reads relations stored by Value.
+func (r *Relations) Scan(src any) error {
+	var s string
+	switch v := src.(type) {
+	case nil:
+	case string:
+		s = v
+	case []byte:
+		s = string(v)
+	default:
+		return fmt.Errorf("cannot scan %T into relations", src)
+	}
+	*r = nil
+	if s == "" {
+		return nil
+	}
+	return r.UnmarshalJSON([]byte(s))
+}
@@ -0,0 +1,133 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// relationsRadar has Deno replacing Node and OpenTelemetry related to
+// Jaeger, in two data files.
+var relationsRadar = []string{`Items:
+- Label: Deno
+  Quadrant: Tools
+  Ring: In Discovery
+  Relations:
+  - Type: replaces
+    Item: node
+- Label: Node
+  Quadrant: Tools
+  Ring: Adopted
+`, `Items:
+- Label: OpenTelemetry
+  Quadrant: Tools
+  Ring: Adopted
+  Relations:
+  - Type: related-to
+    Item: jaeger
+- Label: Jaeger
+  Quadrant: Tools
+  Ring: Adopted
+`}
+
+func TestValidateRelations(t *testing.T) {
+	content := `Items:
+- Label: Deno
+  Quadrant: Tools
+  Ring: In Discovery
+  Relations:
+  - Type: replaces
+    Item: node
+  - Type: supersedes
+    Item: node
+  - Type: depends-on
+  - Type: related-to
+    Item: Node.js
+`
+	err := validateRadarContent("radar.yaml", []byte(content), newValidationScope())
+	want := []string{
+		`radar.yaml:8: item "Deno".Relations[1].Type: unknown relation type "supersedes", must be one of replaces, related-to, depends-on`,
+		`radar.yaml:10: item "Deno".Relations[2].Item: missing`,
+		`radar.yaml:12: item "Deno".Relations[3].Item: must be the ID of an item`,
+	}
+	if err == nil || err.Error() != strings.Join(want, "\n") {
+		t.Errorf("validateRadarContent() = %v, want\n%s", err, strings.Join(want, "\n"))
+	}
+
+	// Relations are to the items of any of the data files.
+	if err := validateRadarData(writeDataDir(t, map[string]string{"a.yaml": relationsRadar[0], "b.yaml": relationsRadar[1]})); err != nil {
+		t.Errorf("validateRadarData() = %v", err)
+	}
+	dir := writeDataDir(t, map[string]string{
+		"a.yaml": relationsRadar[0],
+		"b.yaml": strings.Replace(relationsRadar[1], "Item: jaeger", "Item: zipkin", 1),
+		"c.yaml": "Items:\n- Label: Go\n  Quadrant: Tools\n  Ring: Adopted\n  Relations:\n  - Type: related-to\n    Item: go\n",
+	})
+	want = []string{
+		filepath.Join(dir, "b.yaml") + `: item "OpenTelemetry".Relations[0].Item: unknown item "zipkin"`,
+		filepath.Join(dir, "c.yaml") + `: item "Go".Relations[0].Item: an item can't relate to itself`,
+	}
+	if err := validateRadarData(dir); err == nil || err.Error() != strings.Join(want, "\n") {
+		t.Errorf("validateRadarData() = %v, want\n%s", err, strings.Join(want, "\n"))
+	}
+}
+
+func TestRetargetRelations(t *testing.T) {
+	items := []RadarItem{
+		{ID: "deno", Relations: Relations{{relationReplaces, "node"}, {relationRelatedTo, "bun"}, {relationReplaces, "nodejs"}}},
+		{ID: "node", Relations: Relations{{relationRelatedTo, "nodejs"}}},
+		{ID: "go"},
+	}
+	got := retargetRelations(items, []string{"nodejs"}, "node")
+	want := []Relations{{{relationReplaces, "node"}, {relationRelatedTo, "bun"}}, nil, nil}
+	for i, item := range got {
+		if !reflect.DeepEqual(item.Relations, want[i]) {
+			t.Errorf("relations of %s = %+v, want %+v", item.ID, item.Relations, want[i])
+		}
+	}
+	if got := retargetRelations(items, []string{"node", "nodejs"}, ""); len(got[0].Relations) != 1 || len(items[0].Relations) != 3 {
+		t.Errorf("relations after removing node = %+v, want only bun's, and items left alone", got[0].Relations)
+	}
+}
+
+func TestRelationsAPI(t *testing.T) {
+	cfg := defaultConfig()
+	cfg.Admin.Token = "s3cret"
+	cfg.Data.Path = writeFile(t, "radar.yaml", relationsRadar[0])
+	useConfig(t, cfg)
+	handler, err := setupRoutes(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rec := doRequest(t, handler, http.MethodGet, "/api/v1/radar/items/node")
+	var node ItemDetail
+	if err := json.Unmarshal(rec.Body.Bytes(), &node); err != nil || rec.Code != http.StatusOK || !reflect.DeepEqual(node.RelatedBy, Relations{{relationReplaces, "deno"}}) {
+		t.Errorf("GET /api/v1/radar/items/node = %d %s, want it related by deno", rec.Code, rec.Body)
+	}
+	if rec := doRequest(t, handler, http.MethodGet, "/api/v1/radar/items/deno"); !strings.Contains(rec.Body.String(), `"relations":[{"type":"replaces","item":"node"}]`) {
+		t.Errorf("GET /api/v1/radar/items/deno = %s", rec.Body)
+	}
+
+	body := `{"label": "Bun", "quadrant": "Tools", "ring": "In Discovery", "relations": [{"type": "related-to", "item": "zig"}]}`
+	if rec := adminRequest(t, handler, http.MethodPost, "/api/v1/items", nil, body); rec.Code != http.StatusBadRequest || !strings.Contains(rec.Body.String(), `unknown item \"zig\"`) {
+		t.Errorf("POST /api/v1/items relating to an unknown item = %d %s, want 400", rec.Code, rec.Body)
+	}
+	body = strings.Replace(body, `"zig"`, `"deno"`, 1)
+	if rec := adminRequest(t, handler, http.MethodPost, "/api/v1/items", nil, body); rec.Code != http.StatusCreated {
+		t.Fatalf("POST /api/v1/items = %d %s", rec.Code, rec.Body)
+	}
+
+	// Deleting an item removes the relations to it.
+	if rec := adminRequest(t, handler, http.MethodDelete, "/api/v1/items/node", http.Header{"If-Match": {"*"}}, ""); rec.Code != http.StatusNoContent {
+		t.Fatalf("DELETE /api/v1/items/node = %d %s", rec.Code, rec.Body)
+	}
+	content, _ := os.ReadFile(cfg.Data.Path)
+	if strings.Contains(string(content), "Item: node") || !strings.Contains(string(content), "Item: deno") {
+		t.Errorf("data file after deleting node:\n%s", content)
+	}
+}
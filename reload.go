@@ -0,0 +1,153 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"log/slog"
+	"net/http"
+	"os"
+	"os/signal"
+	"sync/atomic"
+	"syscall"
+)
+
+// activeConfig holds the configuration currently used to serve requests.
+var activeConfig atomic.Pointer[Config]
+
+// currentConfig returns the active configuration.
+func currentConfig() Config {
+	if cfg := activeConfig.Load(); cfg != nil {
+		return *cfg
+	}
+	return defaultConfig()
+}
+
+// swappableHandler forwards requests to a handler that can be replaced at
+// runtime. Requests already being served keep using the handler they started
+// with.
+type swappableHandler struct {
+	current atomic.Pointer[http.Handler]
+}
+
+func (h *swappableHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	(*h.current.Load()).ServeHTTP(w, r)
+}
+
+// store replaces the handler used for new requests.
+func (h *swappableHandler) store(handler http.Handler) {
+	h.current.Store(&handler)
+}
+
+// applyConfig builds the routes for cfg and, if that succeeds, makes it the
+// active configuration with a matching logger and Store. The
+// cached radar data is dropped, since the data may have changed with it.
+func applyConfig(cfg Config, handler *swappableHandler) error {
+	routes, err := setupRoutes(cfg)
+	if err != nil {
+		return err
+	}
+	startStore(cfg)
+	activeConfig.Store(&cfg)
+	slog.SetDefault(cfg.logger())
+	handler.store(routes)
+	invalidateRadarCache(context.Background())
+	return nil
+}
+
+// watchReloadSignal reloads the configuration every time the process
+// receives SIGHUP.
+func watchReloadSignal(args []string, handler *swappableHandler) {
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGHUP)
+	go func() {
+		for range signals {
+			reloadConfig(args, handler)
+		}
+	}()
+}
+
+// reloadConfig re-reads the configuration and swaps it in if it is valid.
+// An invalid configuration is logged and the previous one stays active.
+func reloadConfig(args []string, handler *swappableHandler) {
+	log.Printf("Received SIGHUP, reloading configuration")
+
+	cfg, err := loadConfig(args)
+	if err != nil {
+		slog.Error("Reload failed, keeping previous configuration", "err", err)
+		return
+	}
+	if err := prepareGitData(cfg.Data); err != nil {
+		slog.Error("Reload failed, keeping previous configuration", "err", fmt.Errorf("checking out radar data repository: %w", err))
+		return
+	}
+	if !currentConfig().Store.enabled() {
+		if err := validateRadarData(cfg.Data.dataPath()); err != nil {
+			slog.Error("Reload failed, keeping previous configuration", "err", fmt.Errorf("invalid radar data: %w", err))
+			return
+		}
+	}
+
+	old := currentConfig()
+	requested := cfg
+	// The listener, its connection timeouts and the TLS file paths are fixed
+	// for the lifetime of the process; the settings below only affect
+	// routing and can change at runtime. The certificate files themselves
+	// are re-read below.
+	cfg.Server = old.Server
+	cfg.Server.RequestTimeout = requested.Server.RequestTimeout
+	cfg.Server.BasePath = requested.Server.BasePath
+	cfg.Server.TrustProxy = requested.Server.TrustProxy
+	cfg.Server.Compress = requested.Server.Compress
+	cfg.Server.CORS = requested.Server.CORS
+	cfg.Server.FrameAncestors = requested.Server.FrameAncestors
+	cfg.Server.UserHeader = requested.Server.UserHeader
+	// The store and cache are opened once at startup, and the configured
+	// rings the data was validated against and the encryption key the data
+	// was read with are fixed with it.
+	cfg.Store = old.Store
+	cfg.Cache = old.Cache
+	cfg.Radar = old.Radar
+	cfg.Encryption = old.Encryption
+	// The backup job keeps running with the settings it was started with.
+	cfg.Backup = old.Backup
+
+	if err := applyConfig(cfg, handler); err != nil {
+		slog.Error("Reload failed, keeping previous configuration", "err", err)
+		return
+	}
+
+	if certs := activeCertificate.Load(); certs != nil {
+		if err := certs.reload(); err != nil {
+			slog.Error("Keeping previous TLS certificate", "err", err)
+		} else {
+			log.Printf("Reloaded TLS certificate from %s", certs.certFile)
+		}
+	}
+
+	changes := configDiff(old, cfg)
+	pending := configDiff(cfg, requested)
+	if len(changes) == 0 && len(pending) == 0 {
+		log.Printf("Configuration reloaded, no changes")
+		return
+	}
+	for _, change := range changes {
+		log.Printf("Configuration changed: %s", change)
+	}
+	for _, change := range pending {
+		slog.Warn("Configuration change requires a restart, not applied: " + change)
+	}
+}
+
+// configDiff lists the settings that differ between two configurations, one
+// "key: old -> new" entry per setting, using the config file key names.
+func configDiff(old, new Config) []string {
+	var changes []string
+	newSettings := new.settings()
+	for i, s := range old.settings() {
+		if s.Value != newSettings[i].Value {
+			changes = append(changes, fmt.Sprintf("%s: %s -> %s", s.Key, s, newSettings[i]))
+		}
+	}
+	return changes
+}
@@ -0,0 +1,46 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestConfigDiff(t *testing.T) {
+	tests := []struct {
+		name   string
+		modify func(*Config)
+		want   []string
+	}{
+		{name: "no changes", modify: func(*Config) {}},
+		{
+			name:   "nested fields use config file keys",
+			modify: func(c *Config) { c.Server.RequestTimeout = 5 * time.Second; c.Logging.AccessLog = true },
+			want:   []string{"server.requestTimeout: 0s -> 5s", "logging.accessLog: false -> true"},
+		},
+		{
+			name:   "tls settings",
+			modify: func(c *Config) { c.Server.TLS.Cert = "cert.pem" },
+			want:   []string{"server.tls.cert:  -> cert.pem"},
+		},
+		{
+			name:   "secrets are redacted",
+			modify: func(c *Config) { c.Admin.Token = "s3cret" },
+			want:   []string{"admin.token:  -> [redacted]"},
+		},
+		{
+			name:   "untagged paths are ignored",
+			modify: func(c *Config) { c.Path = "other.yaml"; c.EnvFile = "other.env" },
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			old := defaultConfig()
+			updated := defaultConfig()
+			tt.modify(&updated)
+			if got := configDiff(old, updated); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("configDiff() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,195 @@
+package main
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+)
+
+// Review reminder events.
+const (
+	reviewDue     = "review-due"
+	reviewOverdue = "review-overdue"
+)
+
+// OverdueReview is an item past its ReviewDate, as GET
+// /api/v1/reviews/overdue lists it.
+type OverdueReview struct {
+	RadarItem
+	// DaysOverdue is the number of days since the review date.
+	DaysOverdue int `json:"daysOverdue"`
+}
+
+// dueReview is an item whose review is within the notice of a reminder, or
+// past, with the days left until it.
+type dueReview struct {
+	RadarItem
+	daysLeft int
+}
+
+// overdueReviews returns the items of items whose review date is before the
+// day of now, most overdue first.
+func overdueReviews(items []RadarItem, now time.Time) []OverdueReview {
+	overdue := []OverdueReview{}
+	for _, item := range items {
+		if left, ok := daysUntil(item.ReviewDate, now); ok && left < 0 {
+			overdue = append(overdue, OverdueReview{RadarItem: item, DaysOverdue: -left})
+		}
+	}
+	slices.SortStableFunc(overdue, func(a, b OverdueReview) int {
+		return cmp.Or(b.DaysOverdue-a.DaysOverdue, strings.Compare(labelKey(a.Label), labelKey(b.Label)))
+	})
+	return overdue
+}
+
+// overdueReviewsHandler lists the items selectItems selects whose review
+// date is past, most overdue first.
+func overdueReviewsHandler(w http.ResponseWriter, r *http.Request) {
+	data, err := loadRadarData()
+	if err != nil {
+		handleError(w, err)
+		return
+	}
+	items, err := selectItems(r, data)
+	if err != nil {
+		handleError(w, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(map[string][]OverdueReview{"items": overdueReviews(items, time.Now())}); err != nil {
+		handleError(w, &AppError{Code: http.StatusInternalServerError, Message: "Failed to encode response", Err: err})
+	}
+}
+
+// reviewRecipients are reviews of items with the same owners, who are
+// reminded of them together.
+type reviewRecipients struct {
+	owners  Owners
+	reviews []dueReview
+}
+
+// groupReviews groups reviews by their owners, in the order of reviews.
+func groupReviews(reviews []dueReview) []reviewRecipients {
+	var groups []reviewRecipients
+	for _, review := range reviews {
+		key := ownersKey(review.Owners)
+		i := slices.IndexFunc(groups, func(g reviewRecipients) bool { return ownersKey(g.owners) == key })
+		if i < 0 {
+			groups = append(groups, reviewRecipients{owners: review.Owners})
+			i = len(groups) - 1
+		}
+		groups[i].reviews = append(groups[i].reviews, review)
+	}
+	return groups
+}
+
+// ownersKey identifies owners regardless of their order, each by its email
+// if it has one and its name if it doesn't.
+func ownersKey(owners Owners) string {
+	keys := make([]string, len(owners))
+	for i, owner := range owners {
+		keys[i] = strings.ToLower(cmp.Or(owner.Email, owner.Name))
+	}
+	slices.Sort(keys)
+	return strings.Join(keys, "\x00")
+}
+
+// reminderText is the text of the reminder of reviews to owners, with the
+// Slack handles of the owners so that Slack mentions them.
+func reminderText(owners Owners, reviews []dueReview) string {
+	var parts []string
+	for _, review := range reviews {
+		verb := "is"
+		if review.daysLeft < 0 {
+			verb = "was"
+		}
+		parts = append(parts, fmt.Sprintf("%s %s due for review by %s", review.Label, verb, review.ReviewDate))
+	}
+	names := make([]string, len(owners))
+	for i, owner := range owners {
+		names[i] = cmp.Or(owner.Name, owner.Team, owner.Email)
+		if owner.Slack != "" {
+			names[i] += " (" + owner.Slack + ")"
+		}
+	}
+	if len(names) == 0 {
+		names = []string{"Unowned"}
+	}
+	return strings.Join(names, ", ") + ": " + strings.Join(parts, ", ")
+}
+
+// checkReviews reminds the owners of the items whose review date is within
+// cfg.Notice of now, or past: it logs them and notifies their owners, once
+// for each the first time it is due and the first time it is overdue, as
+// recorded in notified. The reminders are emailed to the owners with an
+// email, and sent to the webhook and Slack with one notification for the
+// items of the same owners.
+func checkReviews(ctx context.Context, cfg ReminderConfig, notifications NotificationConfig, notified map[string]bool, now time.Time) error {
+	data, err := loadRadarData()
+	if err != nil {
+		return err
+	}
+	notice := int(cfg.Notice / (24 * time.Hour))
+	var due []dueReview
+	for _, item := range visibleItems(data.Items) {
+		left, ok := daysUntil(item.ReviewDate, now)
+		if !ok || left > notice {
+			continue
+		}
+		key := item.ID + "\x00" + item.ReviewDate + "\x00" + fmt.Sprint(left < 0)
+		if !notified[key] {
+			due = append(due, dueReview{item, left})
+		}
+		notified[key] = true
+	}
+	slices.SortStableFunc(due, func(a, b dueReview) int {
+		return cmp.Or(a.daysLeft-b.daysLeft, strings.Compare(labelKey(a.Label), labelKey(b.Label)))
+	})
+
+	var errs []error
+	for _, group := range groupReviews(due) {
+		n := Notification{Event: reviewDue, Subject: "Tech radar items due for review", Text: reminderText(group.owners, group.reviews)}
+		for _, review := range group.reviews {
+			if review.daysLeft < 0 {
+				n.Event, n.Subject = reviewOverdue, "Tech radar items overdue for review"
+			}
+			n.Items = append(n.Items, review.RadarItem)
+		}
+		for _, owner := range group.owners {
+			if owner.Email != "" {
+				n.To = append(n.To, owner.Email)
+			}
+		}
+		slog.Warn(n.Text, "event", n.Event)
+		if !notifications.enabled() {
+			continue
+		}
+		if err := notify(ctx, notifications, n); err != nil {
+			errs = append(errs, fmt.Errorf("notifying %s: %w", cmp.Or(group.owners.names(), "no owner"), err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// runReviewReminders runs checkReviews every cfg.Reminders.Interval, starting
+// now, until ctx is done. Owners are reminded again after a restart.
+func runReviewReminders(ctx context.Context, cfg Config) {
+	notified := make(map[string]bool)
+	for {
+		if err := checkReviews(ctx, cfg.Reminders, cfg.Notifications, notified, time.Now()); err != nil {
+			slog.Error("Sending review reminders failed", "err", err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(cfg.Reminders.Interval):
+		}
+	}
+}
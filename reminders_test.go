@@ -0,0 +1,128 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// reviewRadar holds items with reviews around 2024-05-10.
+const reviewRadar = `Items:
+- Label: Go
+  Quadrant: Tools
+  Ring: Adopted
+  ReviewDate: "2024-05-20"
+  Owners:
+  - Name: Jane Doe
+    Email: jane@example.com
+    Slack: "@jane"
+- Label: Perl
+  Quadrant: Tools
+  Ring: Adopted
+  ReviewDate: "2024-05-01"
+  Owners:
+  - Name: Jane Doe
+    Email: jane@example.com
+    Slack: "@jane"
+- Label: Ruby
+  Quadrant: Tools
+  Ring: In Discovery
+  ReviewDate: "2024-05-12"
+- Label: Zig
+  Quadrant: Tools
+  Ring: In Discovery
+  ReviewDate: "2024-12-01"
+  Owners:
+  - Name: Platform
+    Email: platform@example.com
+`
+
+func TestOverdueReviews(t *testing.T) {
+	data, err := decodeRadarData("radar.yaml", []byte(reviewRadar))
+	if err != nil {
+		t.Fatal(err)
+	}
+	overdue := overdueReviews(data.Items, time.Date(2024, 5, 12, 15, 0, 0, 0, time.UTC))
+	if len(overdue) != 1 || overdue[0].Label != "Perl" || overdue[0].DaysOverdue != 11 {
+		t.Errorf("overdueReviews() = %+v, want Perl only, Ruby being due on the day", overdue)
+	}
+}
+
+func TestCheckReviews(t *testing.T) {
+	var mu sync.Mutex
+	var received []Notification
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		var n Notification
+		json.NewDecoder(r.Body).Decode(&n)
+		received = append(received, n)
+	}))
+	t.Cleanup(server.Close)
+	addr, emails := smtpServer(t)
+
+	cfg := defaultConfig()
+	cfg.Data.Path = writeFile(t, "radar.yaml", reviewRadar)
+	useConfig(t, cfg)
+	reminders := ReminderConfig{Interval: time.Hour, Notice: 14 * 24 * time.Hour}
+	notifications := NotificationConfig{Webhook: server.URL, Email: EmailConfig{SMTP: addr, From: "radar@example.com"}}
+	notified := make(map[string]bool)
+	now := time.Date(2024, 5, 10, 0, 0, 0, 0, time.UTC)
+	if err := checkReviews(context.Background(), reminders, notifications, notified, now); err != nil {
+		t.Fatal(err)
+	}
+
+	// Jane is reminded of both her items at once, Ruby has no one to email.
+	if len(received) != 2 {
+		t.Fatalf("notifications = %+v", received)
+	}
+	if n := received[0]; n.Event != reviewOverdue || len(n.Items) != 2 || !slices.Equal(n.To, []string{"jane@example.com"}) ||
+		n.Text != "Jane Doe (@jane): Perl was due for review by 2024-05-01, Go is due for review by 2024-05-20" {
+		t.Errorf("reminder to Jane = %+v", n)
+	}
+	if n := received[1]; n.Event != reviewDue || n.To != nil || n.Text != "Unowned: Ruby is due for review by 2024-05-12" {
+		t.Errorf("reminder of Ruby = %+v", n)
+	}
+	if got := emails(); len(got) != 1 || !strings.Contains(got[0].Data, "Subject: Tech radar items overdue for review") {
+		t.Errorf("emails = %+v", got)
+	}
+
+	// Owners are reminded once, and again when the review is overdue.
+	if err := checkReviews(context.Background(), reminders, notifications, notified, now.AddDate(0, 0, 1)); err != nil {
+		t.Fatal(err)
+	}
+	if len(received) != 2 {
+		t.Errorf("notifications after the next check = %+v", received[2:])
+	}
+	if err := checkReviews(context.Background(), reminders, notifications, notified, now.AddDate(0, 0, 3)); err != nil {
+		t.Fatal(err)
+	}
+	if len(received) != 3 || received[2].Event != reviewOverdue || received[2].Items[0].ID != "ruby" {
+		t.Errorf("notifications once Ruby is overdue = %+v", received)
+	}
+}
+
+func TestOverdueReviewsAPI(t *testing.T) {
+	cfg := defaultConfig()
+	cfg.Data.Path = writeFile(t, "radar.yaml", reviewRadar)
+	useConfig(t, cfg)
+	handler, err := setupRoutes(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rec := doRequest(t, handler, http.MethodGet, "/api/v1/reviews/overdue")
+	var body struct{ Items []OverdueReview }
+	if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil || rec.Code != http.StatusOK || len(body.Items) != 4 || body.Items[0].Label != "Perl" {
+		t.Errorf("GET /api/v1/reviews/overdue = %d %s", rec.Code, rec.Body)
+	}
+	rec = doRequest(t, handler, http.MethodGet, "/api/v1/reviews/overdue?ring=In+Discovery")
+	if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil || len(body.Items) != 2 || body.Items[0].Label != "Ruby" {
+		t.Errorf("GET /api/v1/reviews/overdue?ring=In+Discovery = %d %s", rec.Code, rec.Body)
+	}
+}
@@ -0,0 +1,183 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// maxRemoteDataSize limits the size of radar data fetched from a URL.
+const maxRemoteDataSize = 10 << 20
+
+// remoteClient fetches remote radar data.
+var remoteClient = &http.Client{Timeout: 30 * time.Second}
+
+// isRemoteDataPath reports whether the data path is an HTTP(S) URL rather
+// than a local path.
+func isRemoteDataPath(path string) bool {
+	return strings.HasPrefix(path, "https://") || strings.HasPrefix(path, "http://")
+}
+
+// remoteDataName returns a data URL without credentials or query string, for
+// use in logs and error messages. Its extension selects the data format.
+func remoteDataName(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return rawURL
+	}
+	return (&url.URL{Scheme: u.Scheme, Host: u.Host, Path: u.Path}).String()
+}
+
+// fetchRemoteData downloads radar data from rawURL. When etag is set it is
+// sent as If-None-Match, and a 304 Not Modified response returns nil content.
+// The returned ETag identifies the content for the next fetch.
+func fetchRemoteData(ctx context.Context, rawURL, etag string) ([]byte, string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, rawURL, nil)
+	if err != nil {
+		return nil, "", err
+	}
+	if etag != "" {
+		req.Header.Set("If-None-Match", etag)
+	}
+
+	resp, err := remoteClient.Do(req)
+	if err != nil {
+		return nil, "", err
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusNotModified && etag != "":
+		return nil, etag, nil
+	case resp.StatusCode != http.StatusOK:
+		return nil, "", fmt.Errorf("unexpected status %s", resp.Status)
+	}
+	content, err := io.ReadAll(io.LimitReader(resp.Body, maxRemoteDataSize+1))
+	if err != nil {
+		return nil, "", err
+	}
+	if len(content) > maxRemoteDataSize {
+		return nil, "", fmt.Errorf("radar data larger than %d bytes", maxRemoteDataSize)
+	}
+	if content, err = openData(content); err != nil {
+		return nil, "", err
+	}
+	return content, resp.Header.Get("ETag"), nil
+}
+
+// remoteStore is the read-only Store of a data URL. It keeps the last good
+// copy of the radar data fetched from it.
+type remoteStore struct {
+	url      string
+	name     string
+	interval time.Duration
+	cancel   context.CancelFunc
+	changes  changeNotifier
+
+	mu   sync.Mutex
+	etag string
+	data *RadarData
+	err  error
+}
+
+// refresh fetches the data if it changed since the last fetch. Data that
+// fails validation is rejected and the last good copy is kept.
+func (s *remoteStore) refresh(ctx context.Context) error {
+	s.mu.Lock()
+	etag := s.etag
+	s.mu.Unlock()
+
+	content, etag, err := fetchRemoteData(ctx, s.url, etag)
+	if err == nil && content == nil {
+		return nil
+	}
+	var data RadarData
+	if err == nil {
+		err = validateRadarContent(s.name, content, newValidationScope())
+	}
+	if err == nil {
+		var applied []schemaMigration
+		data, applied, err = upgradeRadarData(s.name, content)
+		logSchemaMigrations(s.name, applied)
+	}
+	if err == nil {
+		data, err = withItemIDs(data, nil)
+	}
+
+	s.mu.Lock()
+	s.err = err
+	if err == nil {
+		s.etag = etag
+		s.data = &data
+	}
+	s.mu.Unlock()
+	if err == nil {
+		s.changes.notify()
+	}
+	return err
+}
+
+// Load returns the last good copy of the data.
+func (s *remoteStore) Load(ctx context.Context) (RadarData, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.data == nil {
+		return RadarData{}, &AppError{Code: http.StatusServiceUnavailable, Message: "Radar data not available yet", Err: s.err}
+	}
+	return *s.data, nil
+}
+
+// poll refreshes the data every interval until ctx is cancelled. Failures
+// are logged and the last good copy keeps being served.
+func (s *remoteStore) poll(ctx context.Context) {
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := s.refresh(ctx); err != nil && ctx.Err() == nil {
+				slog.Warn("Failed to refresh radar data, serving last good copy", "url", s.name, "err", err)
+			}
+		}
+	}
+}
+
+func (s *remoteStore) Save(ctx context.Context, data RadarData) error {
+	return errReadOnly
+}
+
+func (s *remoteStore) Watch(ctx context.Context) <-chan struct{} {
+	return s.changes.watch(ctx)
+}
+
+func (s *remoteStore) Close() error {
+	s.cancel()
+	s.changes.close()
+	return nil
+}
+
+// newRemoteStore starts polling the data URL of cfg. The first fetch
+// completes before it returns; when old polled the same URL, its last good
+// copy carries over.
+func newRemoteStore(cfg DataConfig, old *remoteStore) *remoteStore {
+	ctx, cancel := context.WithCancel(context.Background())
+	src := &remoteStore{url: cfg.Path, name: remoteDataName(cfg.Path), interval: cfg.PollInterval, cancel: cancel}
+	if old != nil && old.url == cfg.Path {
+		old.mu.Lock()
+		src.etag, src.data = old.etag, old.data
+		old.mu.Unlock()
+	}
+	if err := src.refresh(ctx); err != nil {
+		slog.Warn("Failed to fetch radar data", "url", src.name, "err", err)
+	}
+	go src.poll(ctx)
+	return src
+}
@@ -0,0 +1,134 @@
+package main
+
+import (
+	"cmp"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// defaultStaleDays is the number of days after which an item is stale by
+// default.
+const defaultStaleDays = 180
+
+// StaleItem is an item of the staleness report.
+type StaleItem struct {
+	RadarItem
+	// LastChanged is when its description or ring last changed, zero if
+	// that isn't known.
+	LastChanged time.Time `json:"lastChanged,omitzero"`
+}
+
+// StaleOwner is an owner and its stale items, or the stale items without
+// an owner if Owner is nil.
+type StaleOwner struct {
+	Owner *Owner      `json:"owner"`
+	Items []StaleItem `json:"items"`
+}
+
+// contentChanges returns when the description or ring of each item last
+// changed among changes, which are oldest first, by item ID.
+func contentChanges(changes []ItemChange) map[string]time.Time {
+	last := make(map[string]time.Time)
+	previous := make(map[string]RadarItem)
+	for _, change := range changes {
+		if change.Item == nil {
+			delete(previous, change.ID)
+			continue
+		}
+		before, existed := previous[change.ID]
+		if !existed || before.Description != change.Item.Description || before.Ring != change.Item.Ring {
+			last[change.ID] = change.Time
+		}
+		previous[change.ID] = *change.Item
+	}
+	return last
+}
+
+// staleItems returns the items of items whose description and ring haven't
+// changed in days before now, according to changed, or else their
+// LastUpdated, least recently changed first. Items with neither are stale,
+// and come first.
+func staleItems(items []RadarItem, changed map[string]time.Time, now time.Time, days int) []StaleItem {
+	cutoff := now.AddDate(0, 0, -days)
+	stale := []StaleItem{}
+	for _, item := range items {
+		last, ok := changed[item.ID]
+		if !ok {
+			last = item.LastUpdated
+		}
+		if last.Before(cutoff) {
+			stale = append(stale, StaleItem{RadarItem: item, LastChanged: last})
+		}
+	}
+	slices.SortStableFunc(stale, func(a, b StaleItem) int {
+		return cmp.Or(a.LastChanged.Compare(b.LastChanged), strings.Compare(labelKey(a.Label), labelKey(b.Label)))
+	})
+	return stale
+}
+
+// staleByOwner groups items by owner, an item with several owners being
+// listed for each, ordered by owner name with the items without an owner
+// last. Owners are told apart by name, regardless of case.
+func staleByOwner(items []StaleItem) []StaleOwner {
+	groups := []StaleOwner{}
+	unowned := StaleOwner{Items: []StaleItem{}}
+	key := func(o *Owner) string { return strings.ToLower(cmp.Or(o.Name, o.Team, o.Email)) }
+	for _, item := range items {
+		if len(item.Owners) == 0 {
+			unowned.Items = append(unowned.Items, item)
+		}
+		for _, owner := range item.Owners {
+			i := slices.IndexFunc(groups, func(g StaleOwner) bool { return key(g.Owner) == key(&owner) })
+			if i < 0 {
+				groups = append(groups, StaleOwner{Owner: &owner, Items: []StaleItem{}})
+				i = len(groups) - 1
+			}
+			groups[i].Items = append(groups[i].Items, item)
+		}
+	}
+	slices.SortStableFunc(groups, func(a, b StaleOwner) int { return strings.Compare(key(a.Owner), key(b.Owner)) })
+	if len(unowned.Items) > 0 {
+		groups = append(groups, unowned)
+	}
+	return groups
+}
+
+// staleReportHandler lists the items on the radar whose description and
+// ring haven't changed in ?days= days, 180 by default, grouped by owner.
+// When they were changed is taken from the change feed, or from the
+// LastUpdated of the items when changes aren't tracked.
+func staleReportHandler(w http.ResponseWriter, r *http.Request) {
+	days := defaultStaleDays
+	if value := r.URL.Query().Get("days"); value != "" {
+		n, err := strconv.Atoi(value)
+		if err != nil || n < 0 {
+			handleError(w, &AppError{Code: http.StatusBadRequest, Message: fmt.Sprintf("Invalid days %q, must be a number of days", value)})
+			return
+		}
+		days = n
+	}
+	data, err := loadRadarData()
+	if err != nil {
+		handleError(w, err)
+		return
+	}
+	changes, err := loadRadarChanges(r.Context())
+	if err != nil && !errors.Is(err, errNoHistory) {
+		handleError(w, &AppError{Code: http.StatusInternalServerError, Message: "Failed to read radar changes", Err: err})
+		return
+	}
+	stale := staleItems(visibleItems(data.Items), contentChanges(changes), time.Now(), days)
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(struct {
+		Days   int          `json:"days"`
+		Owners []StaleOwner `json:"owners"`
+	}{days, staleByOwner(stale)}); err != nil {
+		handleError(w, &AppError{Code: http.StatusInternalServerError, Message: "Failed to encode response", Err: err})
+	}
+}
@@ -0,0 +1,94 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestContentChanges(t *testing.T) {
+	day := func(d int) time.Time { return time.Date(2024, 1, d, 0, 0, 0, 0, time.UTC) }
+	changes := []ItemChange{
+		{Event: changeCreated, ID: "go", Time: day(1), Item: &RadarItem{Ring: "In Discovery"}},
+		{Event: changeCreated, ID: "perl", Time: day(1), Item: &RadarItem{Ring: "Adopted"}},
+		{Event: changeMoved, ID: "go", Time: day(2), Item: &RadarItem{Ring: "Adopted"}},
+		{Event: changeUpdated, ID: "go", Time: day(3), Item: &RadarItem{Ring: "Adopted", Tags: Tags{"backend"}}},
+		{Event: changeUpdated, ID: "perl", Time: day(4), Item: &RadarItem{Ring: "Adopted", Description: "Legacy."}},
+		{Event: changeRemoved, ID: "perl", Time: day(5)},
+		{Event: changeCreated, ID: "perl", Time: day(6), Item: &RadarItem{Ring: "Adopted", Description: "Legacy."}},
+	}
+	// Changes to other fields, such as tags, don't count.
+	want := map[string]time.Time{"go": day(2), "perl": day(6)}
+	if got := contentChanges(changes); !reflect.DeepEqual(got, want) {
+		t.Errorf("contentChanges() = %v, want %v", got, want)
+	}
+}
+
+func TestStaleItems(t *testing.T) {
+	now := time.Date(2024, 7, 1, 0, 0, 0, 0, time.UTC)
+	items := []RadarItem{
+		{ID: "go", Label: "Go", Owners: Owners{{Name: "Platform"}, {Name: "Jane"}}},
+		{ID: "perl", Label: "Perl", Owners: Owners{{Name: "platform"}}},
+		{ID: "rust", Label: "Rust", LastUpdated: now.AddDate(0, -1, 0)},
+		{ID: "zig", Label: "Zig"},
+	}
+	changed := map[string]time.Time{"go": now.AddDate(-1, 0, 0), "perl": now.AddDate(0, -7, 0)}
+	stale := staleItems(items, changed, now, 180)
+	var labels []string
+	for _, item := range stale {
+		labels = append(labels, item.Label)
+	}
+	if !reflect.DeepEqual(labels, []string{"Zig", "Go", "Perl"}) {
+		t.Fatalf("staleItems() = %v, want items never known to change first", labels)
+	}
+
+	groups := staleByOwner(stale)
+	var got []string
+	for _, group := range groups {
+		name := "unowned"
+		if group.Owner != nil {
+			name = group.Owner.Name
+		}
+		for _, item := range group.Items {
+			got = append(got, name+":"+item.ID)
+		}
+	}
+	if want := []string{"Jane:go", "Platform:go", "Platform:perl", "unowned:zig"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("staleByOwner() = %v, want %v", got, want)
+	}
+}
+
+func TestStaleReportAPI(t *testing.T) {
+	cfg := defaultConfig()
+	cfg.Data.Path = writeFile(t, "radar.yaml", `Items:
+- Label: Go
+  Quadrant: Tools
+  Ring: Adopted
+  LastUpdated: 2020-01-01T00:00:00Z
+  Owners:
+  - Name: Platform
+- Label: Rust
+  Quadrant: Tools
+  Ring: Adopted
+  LastUpdated: 2999-01-01T00:00:00Z
+`)
+	useConfig(t, cfg)
+	handler, err := setupRoutes(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rec := doRequest(t, handler, http.MethodGet, "/api/v1/reports/stale?days=30")
+	var body struct {
+		Days   int
+		Owners []StaleOwner
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil || rec.Code != http.StatusOK || body.Days != 30 ||
+		len(body.Owners) != 1 || body.Owners[0].Owner.Name != "Platform" || len(body.Owners[0].Items) != 1 || body.Owners[0].Items[0].ID != "go" {
+		t.Errorf("GET /api/v1/reports/stale?days=30 = %d %s", rec.Code, rec.Body)
+	}
+	if rec := doRequest(t, handler, http.MethodGet, "/api/v1/reports/stale?days=-1"); rec.Code != http.StatusBadRequest {
+		t.Errorf("GET /api/v1/reports/stale?days=-1 = %d, want 400", rec.Code)
+	}
+}
@@ -0,0 +1,195 @@
+package main
+
+import (
+	"bytes"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Review actions, each the last segment of the path of its endpoint.
+const (
+	reviewStart   = "review"
+	reviewApprove = "approve"
+	reviewReject  = "reject"
+	reviewPublish = "publish"
+)
+
+// reviewFrom lists the states a proposal may be in for each review action.
+var reviewFrom = map[string][]string{
+	reviewStart:   {proposalProposed},
+	reviewApprove: {proposalUnderReview},
+	reviewReject:  {proposalProposed, proposalUnderReview, proposalApproved},
+	reviewPublish: {proposalApproved},
+}
+
+// Decision records a step of the review of a proposal: the action, the
+// reviewer who took it with the roles they held and their comment.
+type Decision struct {
+	Action   string    `json:"action"`
+	Reviewer string    `json:"reviewer"`
+	Roles    []string  `json:"roles,omitempty"`
+	Comment  string    `json:"comment,omitempty"`
+	Time     time.Time `json:"time"`
+}
+
+// ReviewInput is the optional body of a review action.
+type ReviewInput struct {
+	Comment string `json:"comment,omitempty"`
+}
+
+// reviewerOf returns the reviewer whose token r carries as an
+// "Authorization: Bearer" header. The admin token signs in as "admin",
+// holding every required role.
+func reviewerOf(r *http.Request) (ReviewerConfig, bool) {
+	token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if !ok || token == "" {
+		return ReviewerConfig{}, false
+	}
+	cfg := currentConfig()
+	for _, reviewer := range cfg.Review.Reviewers {
+		if subtle.ConstantTimeCompare([]byte(token), []byte(reviewer.Token)) == 1 {
+			return reviewer, true
+		}
+	}
+	if cfg.Admin.Token != "" && subtle.ConstantTimeCompare([]byte(token), []byte(cfg.Admin.Token)) == 1 {
+		return ReviewerConfig{Name: "admin", Roles: slices.Clone(cfg.Review.RequiredRoles)}, true
+	}
+	return ReviewerConfig{}, false
+}
+
+// signedInReviewer returns the reviewer signed in with r, see reviewerOf, or
+// an error asking for a reviewer token.
+func signedInReviewer(w http.ResponseWriter, r *http.Request) (ReviewerConfig, error) {
+	reviewer, ok := reviewerOf(r)
+	if !ok {
+		w.Header().Set("WWW-Authenticate", `Bearer realm="clean-tech-radar"`)
+		return ReviewerConfig{}, &AppError{Code: http.StatusUnauthorized, Message: "Reviewing proposals requires a reviewer token"}
+	}
+	return reviewer, nil
+}
+
+// approved reports whether the approvals among decisions were given by
+// reviewers holding each of the required roles between them, or whether
+// there is any approval when no role is required.
+func approved(decisions []Decision, required []string) bool {
+	var roles []string
+	approvals := 0
+	for _, d := range decisions {
+		if d.Action == reviewApprove {
+			roles = append(roles, d.Roles...)
+			approvals++
+		}
+	}
+	for _, role := range required {
+		if !slices.Contains(roles, role) {
+			return false
+		}
+	}
+	return approvals > 0
+}
+
+// reviewHandler takes the review action on the proposal with the ID given by
+// the path, as the reviewer signed in, recording it as a Decision with the
+// comment of the ReviewInput in the body, and responds with the proposal.
+// Taking a proposal under review and rejecting it set its state. An approval
+// approves it once the required roles have all approved it. Publishing it
+// makes its change to the live radar, saved like an item write.
+func reviewHandler(action string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		reviewer, err := signedInReviewer(w, r)
+		if err != nil {
+			handleError(w, err)
+			return
+		}
+		body, err := readItemBody(w, r)
+		if err != nil {
+			handleError(w, err)
+			return
+		}
+		var input ReviewInput
+		if len(bytes.TrimSpace(body)) > 0 {
+			dec := json.NewDecoder(bytes.NewReader(body))
+			dec.DisallowUnknownFields()
+			if err := dec.Decode(&input); err != nil {
+				handleError(w, &AppError{Code: http.StatusBadRequest, Message: "Invalid review: " + err.Error(), Err: err})
+				return
+			}
+		}
+		db, err := proposalDB()
+		if err != nil {
+			handleError(w, err)
+			return
+		}
+
+		proposalMu.Lock()
+		defer proposalMu.Unlock()
+		p, err := findProposal(r.Context(), db, r)
+		if err != nil {
+			handleError(w, err)
+			return
+		}
+		if !slices.Contains(reviewFrom[action], p.Status) {
+			handleError(w, &AppError{Code: http.StatusConflict, Message: fmt.Sprintf("Proposal %d is %s", p.ID, p.Status)})
+			return
+		}
+		now := time.Now().UTC().Truncate(time.Second)
+		decision := Decision{Action: action, Reviewer: reviewer.Name, Roles: reviewer.Roles, Comment: input.Comment, Time: now}
+		switch action {
+		case reviewStart:
+			p.Status = proposalUnderReview
+		case reviewReject:
+			p.Status = proposalRejected
+		case reviewApprove:
+			if slices.ContainsFunc(p.Decisions, func(d Decision) bool { return d.Action == reviewApprove && d.Reviewer == reviewer.Name }) {
+				handleError(w, &AppError{Code: http.StatusConflict, Message: fmt.Sprintf("Proposal %d is already approved by %s", p.ID, reviewer.Name)})
+				return
+			}
+			if approved(append(slices.Clone(p.Decisions), decision), currentConfig().Review.RequiredRoles) {
+				p.Status = proposalApproved
+			}
+		case reviewPublish:
+			if err := publishProposal(r, p, reviewer); err != nil {
+				handleError(w, err)
+				return
+			}
+			p.Status = proposalPublished
+		}
+		p.Decisions = append(p.Decisions, decision)
+		p.Updated = now
+		if p, err = db.SaveProposal(r.Context(), p); err != nil {
+			handleError(w, &AppError{Code: http.StatusInternalServerError, Message: "Failed to save proposal", Err: err})
+			return
+		}
+		log.Printf("Proposal %d: %s by %s, now %s", p.ID, action, reviewer.Name, p.Status)
+		writeProposal(w, http.StatusOK, p)
+	}
+}
+
+// publishProposal makes the change p proposes to the live radar, as
+// reviewer.
+func publishProposal(r *http.Request, p Proposal, reviewer ReviewerConfig) error {
+	editMu.Lock()
+	defer editMu.Unlock()
+	store, data, err := editableData()
+	if err != nil {
+		return err
+	}
+	data, err = p.apply(data)
+	var appErr *AppError
+	if errors.As(err, &appErr) && appErr.Code == http.StatusBadRequest {
+		return &AppError{Code: http.StatusConflict, Message: fmt.Sprintf("Proposal %d no longer applies: %s", p.ID, appErr.Message)}
+	}
+	if err != nil {
+		return err
+	}
+	_, err = saveEdit(r, store, data, AuditEvent{Actor: reviewer.Name, Action: "publish proposal", Detail: strconv.FormatInt(p.ID, 10), Justification: p.Rationale})
+	return err
+}
@@ -0,0 +1,149 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestApproved(t *testing.T) {
+	architect := Decision{Action: reviewApprove, Reviewer: "Jane", Roles: []string{"architect"}}
+	security := Decision{Action: reviewApprove, Reviewer: "Joe", Roles: []string{"security", "architect"}}
+	started := Decision{Action: reviewStart, Reviewer: "Ann", Roles: []string{"security"}}
+	tests := []struct {
+		decisions []Decision
+		required  []string
+		want      bool
+	}{
+		{nil, nil, false},
+		{[]Decision{started}, nil, false},
+		{[]Decision{architect}, nil, true},
+		{[]Decision{architect}, []string{"architect", "security"}, false},
+		{[]Decision{started, architect}, []string{"architect", "security"}, false},
+		{[]Decision{architect, security}, []string{"architect", "security"}, true},
+		{[]Decision{security}, []string{"architect", "security"}, true},
+	}
+	for _, tt := range tests {
+		if got := approved(tt.decisions, tt.required); got != tt.want {
+			t.Errorf("approved(%+v, %v) = %v, want %v", tt.decisions, tt.required, got, tt.want)
+		}
+	}
+}
+
+func TestReview(t *testing.T) {
+	db := openTestStore(t, "sqlite", "file::memory:")
+	seed := RadarData{Items: []RadarItem{{Label: "Go", Quadrant: "Tools", Ring: "In Discovery"}}}
+	if err := db.Save(context.Background(), seed, AuditEvent{Actor: "test", Action: "seed"}); err != nil {
+		t.Fatal(err)
+	}
+	cfg := defaultConfig()
+	cfg.Review = ReviewConfig{
+		Reviewers: []ReviewerConfig{
+			{Name: "Jane", Token: "jane-token", Roles: []string{"architect"}},
+			{Name: "Joe", Token: "joe-token", Roles: []string{"security"}},
+		},
+		RequiredRoles: []string{"architect", "security"},
+	}
+	useConfig(t, cfg)
+	useStore(t, &databaseStore{db: db})
+	handler, err := setupRoutes(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	send := func(target, token, body string) (int, Proposal) {
+		t.Helper()
+		req := httptest.NewRequest(http.MethodPost, target, strings.NewReader(body))
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		var p Proposal
+		if rec.Code < 300 {
+			if err := json.Unmarshal(rec.Body.Bytes(), &p); err != nil {
+				t.Fatal(err)
+			}
+		}
+		return rec.Code, p
+	}
+
+	if code, _ := send("/api/v1/proposals", "", `{"itemId": "go", "ring": "Adopted", "author": "Ann"}`); code != http.StatusCreated {
+		t.Fatalf("proposing = %d", code)
+	}
+	if code, _ := send("/api/v1/proposals/1/review", "", ""); code != http.StatusUnauthorized {
+		t.Errorf("review without token = %d", code)
+	}
+	if code, _ := send("/api/v1/proposals/1/approve", "jane-token", ""); code != http.StatusConflict {
+		t.Errorf("approving a proposal not under review = %d", code)
+	}
+	if code, p := send("/api/v1/proposals/1/review", "jane-token", `{"comment": "On the agenda."}`); code != http.StatusOK || p.Status != proposalUnderReview {
+		t.Fatalf("review = %d %+v", code, p)
+	}
+	if code, p := send("/api/v1/proposals/1/approve", "jane-token", ""); code != http.StatusOK || p.Status != proposalUnderReview {
+		t.Fatalf("approval of one role = %d %+v", code, p)
+	}
+	if code, _ := send("/api/v1/proposals/1/approve", "jane-token", ""); code != http.StatusConflict {
+		t.Errorf("approving twice = %d", code)
+	}
+	if code, _ := send("/api/v1/proposals/1/publish", "jane-token", ""); code != http.StatusConflict {
+		t.Errorf("publishing before approval = %d", code)
+	}
+	code, p := send("/api/v1/proposals/1/approve", "joe-token", `{"comment": "No concerns."}`)
+	if code != http.StatusOK || p.Status != proposalApproved {
+		t.Fatalf("approval of every role = %d %+v", code, p)
+	}
+	if data, _ := loadRadarData(); data.Items[0].Ring != "In Discovery" {
+		t.Errorf("ring before publishing = %s", data.Items[0].Ring)
+	}
+
+	code, p = send("/api/v1/proposals/1/publish", "joe-token", "")
+	if code != http.StatusOK || p.Status != proposalPublished {
+		t.Fatalf("publish = %d %+v", code, p)
+	}
+	var actions []string
+	for _, d := range p.Decisions {
+		actions = append(actions, d.Action+":"+d.Reviewer)
+	}
+	if got := strings.Join(actions, ","); got != "review:Jane,approve:Jane,approve:Joe,publish:Joe" || p.Decisions[2].Comment != "No concerns." || p.Decisions[2].Roles[0] != "security" {
+		t.Errorf("decisions = %s, %+v", got, p.Decisions)
+	}
+	if data, _ := loadRadarData(); data.Items[0].Ring != "Adopted" {
+		t.Errorf("ring after publishing = %s", data.Items[0].Ring)
+	}
+	events, err := db.AuditEvents(context.Background(), 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if events[0].Actor != "Joe" || events[0].Action != "publish proposal" || events[0].Detail != "1" || events[0].SourceIP != "192.0.2.1" {
+		t.Errorf("audit event = %+v", events[0])
+	}
+	if code, _ := send("/api/v1/proposals/1/reject", "jane-token", ""); code != http.StatusConflict {
+		t.Errorf("rejecting a published proposal = %d", code)
+	}
+	if code, _ := send("/api/v1/proposals/1/withdraw", "jane-token", ""); code != http.StatusUnauthorized {
+		t.Errorf("a reviewer withdrawing a proposal = %d", code)
+	}
+
+	// A proposal may be rejected, and one that no longer applies can't be
+	// published.
+	send("/api/v1/proposals", "", `{"itemId": "go", "ring": "Not Recommended", "author": "Ann"}`)
+	if code, p := send("/api/v1/proposals/2/reject", "joe-token", `{"comment": "Too early."}`); code != http.StatusOK || p.Status != proposalRejected {
+		t.Errorf("reject = %d %+v", code, p)
+	}
+	if code, _ := send("/api/v1/proposals/2/review", "joe-token", `{"note": "typo"}`); code != http.StatusBadRequest {
+		t.Errorf("review with unknown field = %d", code)
+	}
+	send("/api/v1/proposals", "", `{"item": {"label": "Rust", "quadrant": "Tools", "ring": "In Discovery"}, "author": "Ann"}`)
+	send("/api/v1/proposals/3/review", "jane-token", "")
+	send("/api/v1/proposals/3/approve", "jane-token", "")
+	send("/api/v1/proposals/3/approve", "joe-token", "")
+	if err := currentStore().Save(context.Background(), RadarData{Items: []RadarItem{{Label: "Go", Quadrant: "Tools", Ring: "Adopted"}, {Label: "Rust", Quadrant: "Tools", Ring: "Adopted"}}}); err != nil {
+		t.Fatal(err)
+	}
+	if code, p := send("/api/v1/proposals/3/publish", "jane-token", ""); code != http.StatusBadRequest || p.Status != "" {
+		t.Errorf("publishing a duplicate = %d %+v", code, p)
+	}
+}
@@ -0,0 +1,429 @@
+package main
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+)
+
+// RingConfig declares a ring in the configuration file, used by radars
+// whose data declares no rings. It has the fields of a Segment.
+type RingConfig struct {
+	Name        string   `yaml:"name"`
+	Color       string   `yaml:"color"`
+	Description string   `yaml:"description"`
+	MovesTo     []string `yaml:"movesTo"`
+	// RequiresJustification is the RequiresJustification of the Segment.
+	RequiresJustification bool `yaml:"requiresJustification"`
+}
+
+// configuredRings are the rings of radar.rings, set once at startup.
+var configuredRings []Segment
+
+// ringSegments converts rings declared in the configuration into segments.
+func ringSegments(rings []RingConfig) []Segment {
+	if len(rings) == 0 {
+		return nil
+	}
+	segments := make([]Segment, len(rings))
+	for i, r := range rings {
+		segments[i] = Segment{Name: r.Name, Color: r.Color, Description: r.Description, MovesTo: r.MovesTo, RequiresJustification: r.RequiresJustification}
+	}
+	return segments
+}
+
+// fallbackRings returns the rings of data that declares none: those of the
+// configuration, else defaultRings.
+func fallbackRings() []Segment {
+	if len(configuredRings) > 0 {
+		return configuredRings
+	}
+	return defaultRings
+}
+
+// checkRingConfig reports the problems of rings declared in the
+// configuration file, like validateSegmentsNode does for data files.
+func checkRingConfig(rings []RingConfig) []error {
+	var errs []error
+	names := make(map[string]bool, len(rings))
+	for i, r := range rings {
+		switch {
+		case strings.TrimSpace(r.Name) == "":
+			errs = append(errs, fmt.Errorf("radar.rings[%d].name must be set", i))
+		case names[labelKey(r.Name)]:
+			errs = append(errs, fmt.Errorf("radar.rings[%d]: duplicate ring %q", i, r.Name))
+		}
+		names[labelKey(r.Name)] = true
+		if r.Color != "" && !segmentColorPattern.MatchString(r.Color) {
+			errs = append(errs, fmt.Errorf("radar.rings[%d].color %q must be a hex color such as #00c000", i, r.Color))
+		}
+	}
+	declared := segmentNames(ringSegments(rings))
+	for i, r := range rings {
+		for _, to := range r.MovesTo {
+			if !slices.Contains(declared, to) {
+				errs = append(errs, fmt.Errorf("radar.rings[%d].movesTo: unknown ring %q", i, to))
+			}
+		}
+	}
+	return errs
+}
+
+// segmentRank returns the position of the segment called name in
+// segments, or len(segments) if there is none, so unknown rings and
+// quadrants sort last.
+func segmentRank(segments []Segment, name string) int {
+	if i := slices.IndexFunc(segments, func(s Segment) bool { return s.Name == name }); i >= 0 {
+		return i
+	}
+	return len(segments)
+}
+
+// checkRingMoves reports the items of data that moved from their ring in
+// previous, matched by ID, to a ring their previous ring doesn't list in
+// its MovesTo, or, without a justification, to a ring that requires one.
+// Rings without MovesTo allow moving anywhere.
+func checkRingMoves(data RadarData, previous []RadarItem, justification string) error {
+	before := make(map[string]string, len(previous))
+	for _, item := range previous {
+		before[item.ID] = item.Ring
+	}
+	rings := data.rings()
+	var errs ValidationErrors
+	for _, item := range data.Items {
+		from, ok := before[item.ID]
+		if !ok || from == item.Ring {
+			continue
+		}
+		field := fmt.Sprintf("item %q.Ring", item.Label)
+		if i := segmentRank(rings, from); i < len(rings) && len(rings[i].MovesTo) > 0 && !slices.Contains(rings[i].MovesTo, item.Ring) {
+			errs = append(errs, ValidationError{
+				File:    item.Source,
+				Field:   field,
+				Rule:    ruleRingMove,
+				Message: fmt.Sprintf("can't move from %q to %q, only to %s", from, item.Ring, strings.Join(rings[i].MovesTo, ", ")),
+			})
+			continue
+		}
+		if j := segmentRank(rings, item.Ring); j < len(rings) && rings[j].RequiresJustification && justification == "" {
+			errs = append(errs, ValidationError{
+				File:    item.Source,
+				Field:   field,
+				Rule:    ruleJustification,
+				Message: fmt.Sprintf("moving to %q requires a justification, sent as the %s header", item.Ring, justificationHeader),
+			})
+		}
+	}
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+// Directions of moved items.
+const (
+	movedUp   = "up"
+	movedDown = "down"
+)
+
+// stampMoved returns a copy of data in which the items are marked as moved
+// if they are in another ring than at the previous edition of the radar,
+// the last data with another LastModified than data, along with the
+// Direction and PreviousRing. previous is the data saved before data: when
+// it is of another edition it is that edition, and otherwise it keeps the
+// PreviousRing of its items. Items marked as moved by hand, without a
+// PreviousRing, stay marked until the next edition.
+func stampMoved(data, previous RadarData) RadarData {
+	before := make(map[string]RadarItem, len(previous.Items))
+	for _, item := range previous.Items {
+		before[item.ID] = item
+	}
+	sameEdition := data.LastModified == previous.LastModified
+	rings := data.rings()
+	data.Items = slices.Clone(data.Items)
+	for i := range data.Items {
+		item := &data.Items[i]
+		prev, ok := before[item.ID]
+		from := prev.Ring
+		if sameEdition && prev.PreviousRing != "" {
+			from = prev.PreviousRing
+		}
+		item.Moved, item.Direction, item.PreviousRing = false, "", ""
+		switch {
+		case !ok:
+		case from != item.Ring:
+			item.Moved, item.PreviousRing = true, from
+			switch ringMove(rings, from, item.Ring) {
+			case 1:
+				item.Direction = movedUp
+			case -1:
+				item.Direction = movedDown
+			}
+		case sameEdition && prev.Ring == item.Ring:
+			item.Moved, item.Direction = prev.Moved, prev.Direction
+		}
+	}
+	return data
+}
+
+// itemOrders are the orders GET /api/radar?sort= can list items in. Rings
+// sort from the innermost outwards, quadrants in their declared order and
+// lastUpdated from the oldest change, items never stamped first.
+var itemOrders = map[string]func(data RadarData) func(a, b RadarItem) int{
+	"label": func(RadarData) func(a, b RadarItem) int {
+		return func(a, b RadarItem) int { return cmp.Compare(labelKey(a.Label), labelKey(b.Label)) }
+	},
+	"ring": func(data RadarData) func(a, b RadarItem) int {
+		rings := data.rings()
+		return func(a, b RadarItem) int { return segmentRank(rings, a.Ring) - segmentRank(rings, b.Ring) }
+	},
+	"quadrant": func(data RadarData) func(a, b RadarItem) int {
+		quadrants, rings := data.quadrants(), data.rings()
+		return func(a, b RadarItem) int {
+			if c := segmentRank(quadrants, a.Quadrant) - segmentRank(quadrants, b.Quadrant); c != 0 {
+				return c
+			}
+			return segmentRank(rings, a.Ring) - segmentRank(rings, b.Ring)
+		}
+	},
+	"lastUpdated": func(RadarData) func(a, b RadarItem) int {
+		return func(a, b RadarItem) int { return a.LastUpdated.Compare(b.LastUpdated) }
+	},
+}
+
+// sortItems sorts items by the order named by, descending if desc. Items
+// that compare equal are listed by label and then ID, whatever the
+// direction, so every order is the same from one request to the next.
+func sortItems(items []RadarItem, data RadarData, by string, desc bool) error {
+	order, ok := itemOrders[by]
+	if !ok {
+		return &AppError{Code: http.StatusBadRequest, Message: fmt.Sprintf("Invalid sort %q, must be one of %s", by, strings.Join(itemOrderNames(), ", "))}
+	}
+	compare := order(data)
+	dir := 1
+	if desc {
+		dir = -1
+	}
+	slices.SortFunc(items, func(a, b RadarItem) int {
+		return cmp.Or(dir*compare(a, b), cmp.Compare(labelKey(a.Label), labelKey(b.Label)), cmp.Compare(a.ID, b.ID))
+	})
+	return nil
+}
+
+// itemOrderNames returns the names of itemOrders, sorted.
+func itemOrderNames() []string {
+	names := make([]string, 0, len(itemOrders))
+	for name := range itemOrders {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
+
+// SegmentCount is the number of items in a ring or quadrant, and for a
+// quadrant the number in each of its rings.
+type SegmentCount struct {
+	Name  string         `json:"name"`
+	Count int            `json:"count"`
+	Moved int            `json:"moved"`
+	Rings []SegmentCount `json:"rings,omitempty"`
+}
+
+// RadarStats counts the items of the radar by ring, quadrant and owner.
+// Archived items are only counted in Archived.
+type RadarStats struct {
+	Total     int            `json:"total"`
+	Archived  int            `json:"archived"`
+	Moved     int            `json:"moved"`
+	Rings     []SegmentCount `json:"rings"`
+	Quadrants []SegmentCount `json:"quadrants"`
+	// Owners counts the items of each owner, by name ignoring case, most
+	// items first; Unowned is the number of items without owners.
+	Owners  []SegmentCount `json:"owners"`
+	Unowned int            `json:"unowned"`
+	// Added lists the items added since the previous snapshot of a
+	// database store, and is left out for other stores.
+	Added *AddedItems `json:"added,omitempty"`
+}
+
+// AddedItems are the IDs of the items added since a snapshot.
+type AddedItems struct {
+	Since    time.Time `json:"since"`
+	Snapshot int64     `json:"snapshot"`
+	Count    int       `json:"count"`
+	Items    []string  `json:"items"`
+}
+
+// radarStats counts the items of data in every ring and quadrant, listed in
+// their declared order, including empty ones.
+func radarStats(data RadarData) RadarStats {
+	rings, quadrants := data.rings(), data.quadrants()
+	newCounts := func() []SegmentCount {
+		counts := make([]SegmentCount, len(rings))
+		for i, ring := range rings {
+			counts[i].Name = ring.Name
+		}
+		return counts
+	}
+	items := visibleItems(data.Items)
+	stats := RadarStats{Total: len(items), Archived: len(data.Items) - len(items), Rings: newCounts(), Quadrants: make([]SegmentCount, len(quadrants))}
+	for i, quadrant := range quadrants {
+		stats.Quadrants[i] = SegmentCount{Name: quadrant.Name, Rings: newCounts()}
+	}
+
+	count := func(c *SegmentCount, item RadarItem) {
+		c.Count++
+		if item.Moved {
+			c.Moved++
+		}
+	}
+	owners := make(map[string]int)
+	for _, item := range items {
+		if item.Moved {
+			stats.Moved++
+		}
+		if len(item.Owners) == 0 {
+			stats.Unowned++
+		}
+		var counted []string
+		for _, owner := range item.Owners {
+			key := strings.ToLower(strings.TrimSpace(owner.Name))
+			if key == "" || slices.Contains(counted, key) {
+				continue
+			}
+			counted = append(counted, key)
+			o, ok := owners[key]
+			if !ok {
+				o = len(stats.Owners)
+				owners[key] = o
+				stats.Owners = append(stats.Owners, SegmentCount{Name: strings.TrimSpace(owner.Name)})
+			}
+			count(&stats.Owners[o], item)
+		}
+		r := segmentRank(rings, item.Ring)
+		if r < len(rings) {
+			count(&stats.Rings[r], item)
+		}
+		if q := segmentRank(quadrants, item.Quadrant); q < len(quadrants) {
+			count(&stats.Quadrants[q], item)
+			if r < len(rings) {
+				count(&stats.Quadrants[q].Rings[r], item)
+			}
+		}
+	}
+	slices.SortStableFunc(stats.Owners, func(a, b SegmentCount) int {
+		return cmp.Or(b.Count-a.Count, cmp.Compare(strings.ToLower(a.Name), strings.ToLower(b.Name)))
+	})
+	if stats.Owners == nil {
+		stats.Owners = []SegmentCount{}
+	}
+	return stats
+}
+
+// addedItems returns the items of data that aren't archived and weren't in
+// previous, a snapshot.
+func addedItems(data RadarData, previous Snapshot) *AddedItems {
+	before := previous.Data
+	if withIDs, err := withItemIDs(before, nil); err == nil {
+		before = withIDs
+	}
+	added := &AddedItems{Since: previous.Time, Snapshot: previous.ID, Items: []string{}}
+	for _, item := range visibleItems(data.Items) {
+		if _, ok := findItem(before.Items, item.ID); !ok {
+			added.Items = append(added.Items, item.ID)
+		}
+	}
+	added.Count = len(added.Items)
+	return added
+}
+
+// storeStats returns the radarStats of data, the data of the active Store,
+// and for a database store the items added since the snapshot before the
+// current data, which every save records.
+func storeStats(ctx context.Context, data RadarData) (RadarStats, error) {
+	stats := radarStats(data)
+	if db, ok := currentStore().(*databaseStore); ok {
+		snapshots, err := db.db.Snapshots(ctx, 2)
+		if err != nil {
+			return RadarStats{}, &AppError{Code: http.StatusInternalServerError, Message: "Failed to read snapshots", Err: err}
+		}
+		if len(snapshots) == 2 {
+			stats.Added = addedItems(data, snapshots[1])
+		}
+	}
+	return stats, nil
+}
+
+// statsHandler serves the storeStats of the radar: the number of items in
+// every ring, quadrant and of every owner, and those recently added.
+func statsHandler(w http.ResponseWriter, r *http.Request) {
+	data, err := loadRadarData()
+	if err != nil {
+		handleError(w, err)
+		return
+	}
+	stats, err := storeStats(r.Context(), data)
+	if err != nil {
+		handleError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(stats); err != nil {
+		handleError(w, &AppError{Code: http.StatusInternalServerError, Message: "Failed to encode response", Err: err})
+	}
+}
+
+// SegmentInfo is the definition of a quadrant or ring with its position,
+// from 0 for the first declared, and the number of items it holds.
+type SegmentInfo struct {
+	Segment
+	Order int `json:"order"`
+	Count int `json:"count"`
+	Moved int `json:"moved"`
+}
+
+// segmentInfos returns segments with the counts of radarStats, listed in
+// the same order.
+func segmentInfos(segments []Segment, counts []SegmentCount) []SegmentInfo {
+	infos := make([]SegmentInfo, len(segments))
+	for i, segment := range segments {
+		infos[i] = SegmentInfo{Segment: segment, Order: i, Count: counts[i].Count, Moved: counts[i].Moved}
+	}
+	return infos
+}
+
+// quadrantsHandler serves the quadrants of the radar, so clients don't have
+// to know the defaults.
+func quadrantsHandler(w http.ResponseWriter, r *http.Request) {
+	segmentsHandler(w, "quadrants", func(data RadarData) []SegmentInfo {
+		return segmentInfos(data.quadrants(), radarStats(data).Quadrants)
+	})
+}
+
+// ringsHandler serves the rings of the radar, from the innermost outwards.
+func ringsHandler(w http.ResponseWriter, r *http.Request) {
+	segmentsHandler(w, "rings", func(data RadarData) []SegmentInfo {
+		return segmentInfos(data.rings(), radarStats(data).Rings)
+	})
+}
+
+// segmentsHandler serves the segments infos returns for the radar data
+// under key.
+func segmentsHandler(w http.ResponseWriter, key string, infos func(RadarData) []SegmentInfo) {
+	data, err := loadRadarData()
+	if err != nil {
+		handleError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(map[string][]SegmentInfo{key: infos(data)}); err != nil {
+		handleError(w, &AppError{Code: http.StatusInternalServerError, Message: "Failed to encode response", Err: err})
+	}
+}
@@ -0,0 +1,404 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+// ringMoves declares rings from which items may only move to some rings.
+const ringMoves = `Rings:
+- Name: Adopt
+  MovesTo: [Retire]
+- Name: Experiment
+- Name: Retire
+  MovesTo: [Adopt, Experiment]
+`
+
+func TestValidateRingMoves(t *testing.T) {
+	content := `Quadrants:
+- Name: Tools
+  MovesTo: [Tools]
+Rings:
+- Name: Adopt
+  MovesTo: [Hold]
+Items: []
+`
+	err := validateRadarContent("radar.yaml", []byte(content), newValidationScope())
+	want := []string{
+		`radar.yaml:3: Quadrants[0].MovesTo: only rings can restrict moves`,
+		`radar.yaml:6: Rings[0].MovesTo: unknown ring "Hold"`,
+	}
+	if err == nil || err.Error() != strings.Join(want, "\n") {
+		t.Errorf("validateRadarContent() = %v, want\n%s", err, strings.Join(want, "\n"))
+	}
+}
+
+func TestSaveChecksRingMoves(t *testing.T) {
+	path := writeFile(t, "radar.yaml", ringMoves+"Items:\n- Label: Go\n  Quadrant: Tools\n  Ring: Adopt\n")
+	store := newFileStore(path)
+	defer store.Close()
+	ctx := context.Background()
+
+	err := store.Save(ctx, RadarData{Items: []RadarItem{{Label: "Go", Quadrant: "Tools", Ring: "Experiment"}}})
+	want := `item "Go".Ring: can't move from "Adopt" to "Experiment", only to Retire`
+	if err == nil || err.Error() != want {
+		t.Errorf("Save() = %v, want %s", err, want)
+	}
+	// Retire allows moving anywhere it lists, and new items may start in
+	// any ring.
+	for _, ring := range []string{"Retire", "Experiment"} {
+		data := RadarData{Items: []RadarItem{{Label: "Go", Quadrant: "Tools", Ring: ring}, {Label: ring, Quadrant: "Tools", Ring: "Adopt"}}}
+		if err := store.Save(ctx, data); err != nil {
+			t.Errorf("Save() moving Go to %s = %v", ring, err)
+		}
+	}
+}
+
+func TestRingRequiresJustification(t *testing.T) {
+	db := openTestStore(t, "sqlite", "file::memory:")
+	seed := RadarData{
+		Rings: []Segment{{Name: "Adopt"}, {Name: "Hold", RequiresJustification: true}},
+		Items: []RadarItem{{ID: "go", Label: "Go", Quadrant: "Tools", Ring: "Adopt"}},
+	}
+	if err := db.Save(context.Background(), seed, AuditEvent{Actor: "test", Action: "seed"}); err != nil {
+		t.Fatal(err)
+	}
+	cfg := defaultConfig()
+	cfg.Admin.Token = "s3cret"
+	useConfig(t, cfg)
+	useStore(t, &databaseStore{db: db})
+	handler, err := setupRoutes(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	body := `{"label": "Go", "quadrant": "Tools", "ring": "Hold"}`
+	rec := adminRequest(t, handler, http.MethodPut, "/api/v1/items/go", anyVersion, body)
+	if rec.Code != http.StatusBadRequest || !strings.Contains(rec.Body.String(), `"rule":"justification"`) {
+		t.Errorf("PUT without a justification = %d %s", rec.Code, rec.Body)
+	}
+	header := http.Header{"If-Match": {"*"}, justificationHeader: {"Licensing changed"}}
+	if rec := adminRequest(t, handler, http.MethodPut, "/api/v1/items/go", header, body); rec.Code != http.StatusOK {
+		t.Fatalf("PUT with a justification = %d %s", rec.Code, rec.Body)
+	}
+	events, err := db.AuditEvents(context.Background(), 1)
+	if err != nil || len(events) != 1 || events[0].Justification != "Licensing changed" {
+		t.Errorf("AuditEvents() = %+v, %v", events, err)
+	}
+	// Items staying in the ring, or added to it, need none.
+	body = `{"label": "Go", "quadrant": "Tools", "ring": "Hold", "description": "Moved."}`
+	if rec := adminRequest(t, handler, http.MethodPut, "/api/v1/items/go", anyVersion, body); rec.Code != http.StatusOK {
+		t.Errorf("PUT within the ring = %d %s", rec.Code, rec.Body)
+	}
+	if rec := adminRequest(t, handler, http.MethodPost, "/api/v1/items", nil, `{"label": "Zig", "quadrant": "Tools", "ring": "Hold"}`); rec.Code != http.StatusCreated {
+		t.Errorf("POST to the ring = %d %s", rec.Code, rec.Body)
+	}
+}
+
+func TestValidateRequiresJustification(t *testing.T) {
+	content := "Quadrants:\n- Name: Tools\n  RequiresJustification: true\nRings:\n- Name: Hold\n  RequiresJustification: true\nItems: []\n"
+	err := validateRadarContent("radar.yaml", []byte(content), newValidationScope())
+	want := `radar.yaml:3: Quadrants[0].RequiresJustification: only rings can require a justification`
+	if err == nil || err.Error() != want {
+		t.Errorf("validateRadarContent() = %v, want %s", err, want)
+	}
+}
+
+func TestStampMoved(t *testing.T) {
+	rings := []Segment{{Name: "Adopt"}, {Name: "Trial"}, {Name: "Hold"}}
+	edition := func(month string, items ...RadarItem) RadarData {
+		return RadarData{LastModified: month, Rings: rings, Items: items}
+	}
+	go_ := func(ring string) RadarItem { return RadarItem{ID: "go", Label: "Go", Quadrant: "Tools", Ring: ring} }
+	moved := func(item RadarItem, from, direction string) RadarItem {
+		item.Moved, item.PreviousRing, item.Direction = true, from, direction
+		return item
+	}
+	handMoved := go_("Trial")
+	handMoved.Moved = true
+
+	for _, tc := range []struct {
+		name           string
+		data, previous RadarData
+		want           RadarItem
+	}{
+		{"down", edition("May 2024", go_("Hold")), edition("May 2024", go_("Trial")), moved(go_("Hold"), "Trial", movedDown)},
+		{"up", edition("May 2024", go_("Adopt")), edition("April 2024", go_("Trial")), moved(go_("Adopt"), "Trial", movedUp)},
+		{"new", edition("May 2024", go_("Hold")), edition("May 2024"), go_("Hold")},
+		{"kept in the edition", edition("May 2024", go_("Hold")), edition("May 2024", moved(go_("Hold"), "Trial", movedDown)), moved(go_("Hold"), "Trial", movedDown)},
+		{"moved on in the edition", edition("May 2024", go_("Adopt")), edition("May 2024", moved(go_("Hold"), "Trial", movedDown)), moved(go_("Adopt"), "Trial", movedUp)},
+		{"moved back in the edition", edition("May 2024", go_("Trial")), edition("May 2024", moved(go_("Hold"), "Trial", movedDown)), go_("Trial")},
+		{"next edition", edition("June 2024", go_("Hold")), edition("May 2024", moved(go_("Hold"), "Trial", movedDown)), go_("Hold")},
+		{"sent as moved", edition("May 2024", moved(go_("Trial"), "Hold", movedUp)), edition("May 2024", go_("Trial")), go_("Trial")},
+		{"marked by hand", edition("May 2024", go_("Trial")), edition("May 2024", handMoved), handMoved},
+	} {
+		got := stampMoved(tc.data, tc.previous)
+		if !reflect.DeepEqual(got.Items, []RadarItem{tc.want}) {
+			t.Errorf("%s: stampMoved() = %+v, want %+v", tc.name, got.Items, tc.want)
+		}
+	}
+}
+
+func TestSavesMarkMovedItems(t *testing.T) {
+	db := openTestStore(t, "sqlite", "file::memory:")
+	seed := RadarData{Items: []RadarItem{{ID: "go", Label: "Go", Quadrant: "Tools", Ring: "Adopted"}}}
+	if err := db.Save(context.Background(), seed, AuditEvent{Actor: "test", Action: "seed"}); err != nil {
+		t.Fatal(err)
+	}
+	cfg := defaultConfig()
+	cfg.Admin.Token = "s3cret"
+	useConfig(t, cfg)
+	useStore(t, &databaseStore{db: db})
+	handler, err := setupRoutes(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if rec := adminRequest(t, handler, http.MethodPut, "/api/v1/items/go", anyVersion, `{"label": "Go", "quadrant": "Tools", "ring": "Not Recommended"}`); rec.Code != http.StatusOK {
+		t.Fatalf("PUT /api/v1/items/go = %d %s", rec.Code, rec.Body)
+	}
+	rec := doRequest(t, handler, http.MethodGet, "/api/v1/radar/items/go")
+	var item RadarItem
+	if err := json.Unmarshal(rec.Body.Bytes(), &item); err != nil {
+		t.Fatalf("GET /api/v1/radar/items/go = %d %s", rec.Code, rec.Body)
+	}
+	if !item.Moved || item.Direction != movedDown || item.PreviousRing != "Adopted" {
+		t.Errorf("moved item = %+v", item)
+	}
+}
+
+func TestConfiguredRings(t *testing.T) {
+	configuredRings = ringSegments([]RingConfig{{Name: "Adopt"}, {Name: "Retire", Color: "#999"}})
+	t.Cleanup(func() { configuredRings = nil })
+
+	content := "Items:\n- Label: Go\n  Quadrant: Tools\n  Ring: Retire\n"
+	if err := validateRadarContent("radar.yaml", []byte(content), newValidationScope()); err != nil {
+		t.Errorf("validateRadarContent() = %v, want the configured rings to apply", err)
+	}
+	// Rings declared by the data win over the configured ones.
+	content = "Rings:\n- Name: Hold\n" + content
+	if err := validateRadarContent("radar.yaml", []byte(content), newValidationScope()); err == nil || !strings.Contains(err.Error(), `unknown ring "Retire", must be one of Hold`) {
+		t.Errorf("validateRadarContent() = %v, want Retire to be unknown", err)
+	}
+}
+
+func TestSortAndStatsAPI(t *testing.T) {
+	cfg := defaultConfig()
+	cfg.Data.Path = writeFile(t, "radar.yaml", ringMoves+`Items:
+- Label: Zig
+  Quadrant: Tools
+  Ring: Retire
+- Label: Go
+  Quadrant: Techniques
+  Ring: Experiment
+  Moved: true
+- Label: Rust
+  Quadrant: Tools
+  Ring: Adopt
+`)
+	useConfig(t, cfg)
+	handler, err := setupRoutes(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for sort, want := range map[string][]string{
+		"ring":     {"Rust", "Go", "Zig"},
+		"quadrant": {"Rust", "Zig", "Go"},
+		"label":    {"Go", "Rust", "Zig"},
+	} {
+		rec := doRequest(t, handler, http.MethodGet, "/api/radar?sort="+sort)
+		var data RadarData
+		if err := json.Unmarshal(rec.Body.Bytes(), &data); err != nil {
+			t.Fatalf("GET /api/radar?sort=%s = %d %q", sort, rec.Code, rec.Body.String())
+		}
+		var got []string
+		for _, item := range data.Items {
+			got = append(got, item.Label)
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("GET /api/radar?sort=%s = %q, want %q", sort, got, want)
+		}
+	}
+	for _, target := range []string{"/api/radar?sort=age", "/api/radar?sort=label&order=up", "/api/radar?order=desc"} {
+		if rec := doRequest(t, handler, http.MethodGet, target); rec.Code != http.StatusBadRequest {
+			t.Errorf("GET %s = %d, want 400", target, rec.Code)
+		}
+	}
+	rec := doRequest(t, handler, http.MethodGet, "/api/radar/items?sort=ring&order=desc")
+	var page struct{ Items []RadarItem }
+	if err := json.Unmarshal(rec.Body.Bytes(), &page); err != nil || len(page.Items) != 3 || page.Items[0].Label != "Zig" || page.Items[2].Label != "Rust" {
+		t.Errorf("GET /api/radar/items?sort=ring&order=desc = %d %q, want Zig to Rust", rec.Code, rec.Body.String())
+	}
+
+	rec = doRequest(t, handler, http.MethodGet, "/api/stats")
+	var stats RadarStats
+	if err := json.Unmarshal(rec.Body.Bytes(), &stats); err != nil {
+		t.Fatalf("GET /api/stats = %d %q", rec.Code, rec.Body.String())
+	}
+	wantRings := []SegmentCount{{Name: "Adopt", Count: 1}, {Name: "Experiment", Count: 1, Moved: 1}, {Name: "Retire", Count: 1}}
+	if stats.Total != 3 || !reflect.DeepEqual(stats.Rings, wantRings) {
+		t.Errorf("GET /api/stats = %+v, want 3 items in %+v", stats, wantRings)
+	}
+	tools := stats.Quadrants[1]
+	wantTools := SegmentCount{Name: "Tools", Count: 2, Rings: []SegmentCount{{Name: "Adopt", Count: 1}, {Name: "Experiment"}, {Name: "Retire", Count: 1}}}
+	if len(stats.Quadrants) != 4 || !reflect.DeepEqual(tools, wantTools) {
+		t.Errorf("GET /api/stats quadrants = %+v, want Tools %+v", stats.Quadrants, wantTools)
+	}
+	// Data files have no snapshots to compare with.
+	if stats.Moved != 1 || stats.Unowned != 3 || stats.Added != nil {
+		t.Errorf("GET /api/stats = %+v, want 1 moved and 3 unowned items and nothing added", stats)
+	}
+}
+
+func TestRadarStatsOwners(t *testing.T) {
+	stats := radarStats(RadarData{Items: []RadarItem{
+		{Label: "Go", Quadrant: "Tools", Ring: "Adopted", Moved: true, Owners: Owners{{Name: "Platform"}, {Name: "Ana"}}},
+		{Label: "Rust", Quadrant: "Tools", Ring: "Adopted", Owners: Owners{{Name: "platform"}, {Name: "Platform", Email: "p@example.com"}}},
+		{Label: "Zig", Quadrant: "Tools", Ring: "In Discovery"},
+		{Label: "Perl", Quadrant: "Tools", Ring: "Adopted", Owners: Owners{{Name: "Bo"}}, Archived: true},
+	}})
+	want := []SegmentCount{{Name: "Platform", Count: 2, Moved: 1}, {Name: "Ana", Count: 1, Moved: 1}}
+	if !reflect.DeepEqual(stats.Owners, want) || stats.Unowned != 1 || stats.Moved != 1 {
+		t.Errorf("radarStats() = owners %+v, %d unowned, %d moved, want owners %+v, 1 unowned, 1 moved", stats.Owners, stats.Unowned, stats.Moved, want)
+	}
+}
+
+func TestStatsAddedSinceSnapshot(t *testing.T) {
+	db := openTestStore(t, "sqlite", filepath.Join(t.TempDir(), "radar.db"))
+	store := &databaseStore{db: db}
+	useStore(t, store)
+	handler := http.HandlerFunc(statsHandler)
+	ctx := context.Background()
+
+	// The only snapshot is the current data, so nothing was added since.
+	if err := store.Save(ctx, RadarData{Items: []RadarItem{{Label: "Go", Quadrant: "Tools", Ring: "Adopted"}}}); err != nil {
+		t.Fatal(err)
+	}
+	var stats RadarStats
+	if err := json.Unmarshal(doRequest(t, handler, http.MethodGet, "/api/stats").Body.Bytes(), &stats); err != nil || stats.Added != nil {
+		t.Errorf("GET /api/stats added = %+v, %v, want none", stats.Added, err)
+	}
+
+	if err := store.Save(ctx, RadarData{Items: []RadarItem{
+		{Label: "Go", Quadrant: "Tools", Ring: "Adopted"},
+		{Label: "Rust", Quadrant: "Tools", Ring: "In Discovery"},
+		{Label: "Zig", Quadrant: "Tools", Ring: "In Discovery", Archived: true},
+	}}); err != nil {
+		t.Fatal(err)
+	}
+	snapshots, err := db.Snapshots(ctx, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	stats = RadarStats{}
+	if err := json.Unmarshal(doRequest(t, handler, http.MethodGet, "/api/stats").Body.Bytes(), &stats); err != nil {
+		t.Fatal(err)
+	}
+	want := &AddedItems{Since: snapshots[1].Time, Snapshot: snapshots[1].ID, Count: 1, Items: []string{"rust"}}
+	if !reflect.DeepEqual(stats.Added, want) {
+		t.Errorf("GET /api/stats added = %+v, want %+v", stats.Added, want)
+	}
+}
+
+func TestSortItems(t *testing.T) {
+	day := func(d int) time.Time { return time.Date(2026, 1, d, 0, 0, 0, 0, time.UTC) }
+	data := RadarData{Items: []RadarItem{
+		{ID: "zig", Label: "Zig", Quadrant: "Tools", Ring: "Adopted", LastUpdated: day(2)},
+		{ID: "go-2", Label: "Go", Quadrant: "Tools", Ring: "In Discovery", LastUpdated: day(3)},
+		{ID: "go", Label: "go", Quadrant: "Techniques", Ring: "Adopted", LastUpdated: day(3)},
+		{ID: "awk", Label: "awk", Quadrant: "Tools", Ring: "Adopted"},
+	}}
+	for _, tc := range []struct {
+		by   string
+		desc bool
+		want []string
+	}{
+		{"label", false, []string{"awk", "go", "go-2", "zig"}},
+		{"label", true, []string{"zig", "go", "go-2", "awk"}},
+		{"ring", false, []string{"awk", "go", "zig", "go-2"}},
+		{"ring", true, []string{"go-2", "awk", "go", "zig"}},
+		{"lastUpdated", false, []string{"awk", "zig", "go", "go-2"}},
+		{"lastUpdated", true, []string{"go", "go-2", "zig", "awk"}},
+	} {
+		// Sorting the items in reverse must give the same order.
+		reversed := slices.Clone(data.Items)
+		slices.Reverse(reversed)
+		for _, items := range [][]RadarItem{slices.Clone(data.Items), reversed} {
+			if err := sortItems(items, withSegments(data), tc.by, tc.desc); err != nil {
+				t.Fatal(err)
+			}
+			var got []string
+			for _, item := range items {
+				got = append(got, item.ID)
+			}
+			if !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("sortItems(%s, desc %t) = %q, want %q", tc.by, tc.desc, got, tc.want)
+			}
+		}
+	}
+}
+
+func TestQuadrantsAndRingsAPI(t *testing.T) {
+	cfg := defaultConfig()
+	cfg.Data.Path = writeFile(t, "radar.yaml", ringMoves+`Quadrants:
+- Name: Tools
+  Color: "#123456"
+  Description: Things we use.
+- Name: Techniques
+Items:
+- Label: Zig
+  Quadrant: Tools
+  Ring: Retire
+- Label: Go
+  Quadrant: Techniques
+  Ring: Adopt
+  Moved: true
+- Label: Rust
+  Quadrant: Tools
+  Ring: Adopt
+- Label: Perl
+  Quadrant: Tools
+  Ring: Adopt
+  Archived: true
+`)
+	useConfig(t, cfg)
+	handler, err := setupRoutes(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rec := doRequest(t, handler, http.MethodGet, "/api/quadrants")
+	var quadrants map[string][]SegmentInfo
+	if err := json.Unmarshal(rec.Body.Bytes(), &quadrants); err != nil {
+		t.Fatalf("GET /api/quadrants = %d %q", rec.Code, rec.Body.String())
+	}
+	want := []SegmentInfo{
+		{Segment: Segment{Name: "Tools", Color: "#123456", Description: "Things we use."}, Order: 0, Count: 2},
+		{Segment: Segment{Name: "Techniques"}, Order: 1, Count: 1, Moved: 1},
+	}
+	if !reflect.DeepEqual(quadrants["quadrants"], want) {
+		t.Errorf("GET /api/quadrants = %+v, want %+v", quadrants["quadrants"], want)
+	}
+
+	rec = doRequest(t, handler, http.MethodGet, "/api/rings")
+	var rings map[string][]SegmentInfo
+	if err := json.Unmarshal(rec.Body.Bytes(), &rings); err != nil {
+		t.Fatalf("GET /api/rings = %d %q", rec.Code, rec.Body.String())
+	}
+	want = []SegmentInfo{
+		{Segment: Segment{Name: "Adopt", MovesTo: []string{"Retire"}}, Order: 0, Count: 2, Moved: 1},
+		{Segment: Segment{Name: "Experiment"}, Order: 1},
+		{Segment: Segment{Name: "Retire", MovesTo: []string{"Adopt", "Experiment"}}, Order: 2, Count: 1},
+	}
+	if !reflect.DeepEqual(rings["rings"], want) {
+		t.Errorf("GET /api/rings = %+v, want %+v", rings["rings"], want)
+	}
+}
@@ -0,0 +1,112 @@
+package main
+
+import (
+	"bytes"
+	"cmp"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"slices"
+)
+
+// RollbackRequest is the body of POST /admin/rollback: the snapshot to go
+// back to, such as the snapshotId of an audit log entry, and the ID of the
+// only item to revert, or none to revert the whole radar.
+type RollbackRequest struct {
+	Snapshot int64  `json:"snapshot"`
+	Item     string `json:"item,omitempty"`
+}
+
+// RollbackResponse lists the changes a rollback made.
+type RollbackResponse struct {
+	Snapshot int64      `json:"snapshot"`
+	Changes  []ItemEdit `json:"changes"`
+}
+
+// rolledBack returns current with the item with the ID id as it was in
+// the snapshot data old: replaced, added back if it was removed since, or
+// removed if it was added since.
+func rolledBack(current, old RadarData, id string) (RadarData, error) {
+	matches := func(item RadarItem) bool { return cmp.Or(item.ID, itemSlug(item.Label)) == id }
+	i, j := slices.IndexFunc(current.Items, matches), slices.IndexFunc(old.Items, matches)
+	current.Items = slices.Clone(current.Items)
+	switch {
+	case i < 0 && j < 0:
+		return RadarData{}, &AppError{Code: http.StatusNotFound, Message: "Unknown item"}
+	case j < 0:
+		current.Items = slices.Delete(current.Items, i, i+1)
+	case i < 0:
+		current.Items = append(current.Items, old.Items[j])
+	default:
+		current.Items[i] = old.Items[j]
+	}
+	return current, nil
+}
+
+// rollbackHandler reverts the radar, or the item of the RollbackRequest in
+// the body, to a snapshot of the database store. The data of the snapshot
+// is saved as it was, like a restore, so the rollback is itself a new
+// snapshot and audit event rather than a removal of those since.
+func rollbackHandler(w http.ResponseWriter, r *http.Request) {
+	body, err := readItemBody(w, r)
+	if err != nil {
+		handleError(w, err)
+		return
+	}
+	dec := json.NewDecoder(bytes.NewReader(body))
+	dec.DisallowUnknownFields()
+	var req RollbackRequest
+	if err := dec.Decode(&req); err != nil {
+		handleError(w, &AppError{Code: http.StatusBadRequest, Message: "Invalid rollback: " + err.Error(), Err: err})
+		return
+	}
+
+	editMu.Lock()
+	defer editMu.Unlock()
+	store, current, err := editableData()
+	if err != nil {
+		handleError(w, err)
+		return
+	}
+	db, ok := store.(*databaseStore)
+	if !ok {
+		handleError(w, &AppError{Code: http.StatusConflict, Message: "Rollback requires a database store, see store.driver"})
+		return
+	}
+	snapshot, ok, err := db.db.Snapshot(r.Context(), req.Snapshot)
+	if err != nil {
+		handleError(w, &AppError{Code: http.StatusInternalServerError, Message: "Failed to read the snapshot", Err: err})
+		return
+	}
+	if !ok {
+		handleError(w, &AppError{Code: http.StatusNotFound, Message: fmt.Sprintf("Unknown snapshot %d", req.Snapshot)})
+		return
+	}
+
+	// Snapshots saved without quadrants and rings of their own had those of
+	// the configuration, which may still apply.
+	data, detail := keepSegments(snapshot.Data, current), fmt.Sprintf("to snapshot %d", req.Snapshot)
+	if req.Item != "" {
+		if data, err = rolledBack(current, data, req.Item); err != nil {
+			handleError(w, err)
+			return
+		}
+		detail = req.Item + " " + detail
+	}
+	saved, err := saveEdit(r.WithContext(withRestore(r.Context())), store, data, AuditEvent{Action: "rollback", Detail: detail})
+	if err != nil {
+		handleError(w, err)
+		return
+	}
+	log.Printf("Rolled back %s", detail)
+
+	resp := RollbackResponse{Snapshot: req.Snapshot, Changes: diffItems(current.Items, saved.Items)}
+	if resp.Changes == nil {
+		resp.Changes = []ItemEdit{}
+	}
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(resp); err != nil {
+		log.Printf("Failed to encode rollback: %v", err)
+	}
+}
@@ -0,0 +1,96 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+)
+
+func TestRollback(t *testing.T) {
+	db := openTestStore(t, "sqlite", "file::memory:")
+	cfg := defaultConfig()
+	cfg.Admin.Token = "s3cret"
+	useConfig(t, cfg)
+	useStore(t, &databaseStore{db: db})
+	handler, err := setupRoutes(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, data := range []RadarData{
+		{Items: []RadarItem{{Label: "Go", Quadrant: "Tools", Ring: "In Discovery"}, {Label: "Perl", Quadrant: "Tools", Ring: "Adopted"}}},
+		{Items: []RadarItem{{Label: "Go", Quadrant: "Tools", Ring: "Adopted"}, {Label: "Rust", Quadrant: "Tools", Ring: "In Discovery"}}},
+	} {
+		if err := currentStore().Save(context.Background(), data); err != nil {
+			t.Fatal(err)
+		}
+	}
+	items := func() map[string]string {
+		data, err := loadRadarData()
+		if err != nil {
+			t.Fatal(err)
+		}
+		rings := make(map[string]string)
+		for _, item := range data.Items {
+			rings[item.ID] = item.Ring
+		}
+		return rings
+	}
+	rollback := func(body string) (int, RollbackResponse) {
+		t.Helper()
+		rec := adminRequest(t, handler, http.MethodPost, "/api/v1/admin/rollback", nil, body)
+		var resp RollbackResponse
+		if rec.Code == http.StatusOK {
+			if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+				t.Fatal(err)
+			}
+		}
+		return rec.Code, resp
+	}
+
+	// Reverting an item leaves the others alone, and brings back one that
+	// was removed since.
+	if code, resp := rollback(`{"snapshot": 1, "item": "go"}`); code != http.StatusOK || len(resp.Changes) != 1 || resp.Changes[0].After.Ring != "In Discovery" {
+		t.Fatalf("item rollback = %d %+v", code, resp)
+	}
+	if got := items(); got["go"] != "In Discovery" || got["rust"] != "In Discovery" || len(got) != 2 {
+		t.Errorf("items after item rollback = %v", got)
+	}
+	if code, _ := rollback(`{"snapshot": 1, "item": "perl"}`); code != http.StatusOK || items()["perl"] != "Adopted" {
+		t.Errorf("rollback of a removed item = %d, items %v", code, items())
+	}
+	if code, _ := rollback(`{"snapshot": 1, "item": "rust"}`); code != http.StatusOK || items()["rust"] != "" {
+		t.Errorf("rollback of an added item = %d, items %v", code, items())
+	}
+
+	if code, _ := rollback(`{"snapshot": 2}`); code != http.StatusOK {
+		t.Fatalf("radar rollback = %d", code)
+	}
+	if got := items(); fmt.Sprint(got) != "map[go:Adopted rust:In Discovery]" {
+		t.Errorf("items after radar rollback = %v", got)
+	}
+	// Rollbacks add snapshots and audit events rather than remove those
+	// since.
+	snapshots, err := db.Snapshots(context.Background(), 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	events, err := db.AuditEvents(context.Background(), 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(snapshots) != 6 || events[0].Action != "rollback" || events[0].Detail != "to snapshot 2" {
+		t.Errorf("%d snapshots, last audit event %+v", len(snapshots), events[0])
+	}
+
+	for body, want := range map[string]int{
+		`{"snapshot": 99}`:                 http.StatusNotFound,
+		`{"snapshot": 1, "item": "java"}`:  http.StatusNotFound,
+		`{"snapshot": 1, "items": ["go"]}`: http.StatusBadRequest,
+	} {
+		if code, _ := rollback(body); code != want {
+			t.Errorf("rollback %s = %d, want %d", body, code, want)
+		}
+	}
+}
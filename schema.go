@@ -0,0 +1,124 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"log"
+	"strconv"
+
+	"gopkg.in/yaml.v3"
+)
+
+// schemaMigration upgrades a radar document from the previous format version
+// to version, reporting whether it changed anything.
+type schemaMigration struct {
+	version     int
+	description string
+	apply       func(root *yaml.Node) (bool, error)
+}
+
+// schemaMigrations upgrade data files written in older formats, in version
+// order starting with the upgrade to version 2. Files without a Version are
+// in version 1, the format before versions were recorded.
+var schemaMigrations = []schemaMigration{
+	{version: 2, description: "converted Owners strings to lists of owners", apply: migrateOwnersToList},
+}
+
+// latestSchemaVersion returns the version of the data format this build
+// reads and writes.
+func latestSchemaVersion() int {
+	return 1 + len(schemaMigrations)
+}
+
+// migrateRadarNode upgrades a parsed radar document in place to the latest
+// version and returns the migrations that changed it. A document in a newer
+// version than this build knows is rejected.
+func migrateRadarNode(file string, doc *yaml.Node) ([]schemaMigration, error) {
+	root := doc
+	if root.Kind == yaml.DocumentNode && len(root.Content) > 0 {
+		root = root.Content[0]
+	}
+	if root.Kind != yaml.MappingNode {
+		return nil, nil
+	}
+
+	version := 1
+	node := mappingValue(root, "Version")
+	if node != nil {
+		v, err := strconv.Atoi(node.Value)
+		if node.Kind != yaml.ScalarNode || err != nil || v < 1 {
+			return nil, ValidationErrors{{File: file, Line: node.Line, Field: "Version", Rule: ruleSchema, Message: "must be a positive integer"}}
+		}
+		version = v
+	}
+	if latest := latestSchemaVersion(); version > latest {
+		return nil, ValidationErrors{{File: file, Line: node.Line, Field: "Version", Rule: ruleSchema, Message: fmt.Sprintf("format version %d is newer than the latest supported version %d", version, latest)}}
+	}
+
+	var applied []schemaMigration
+	for _, m := range schemaMigrations {
+		if m.version <= version {
+			continue
+		}
+		changed, err := m.apply(root)
+		if err != nil {
+			return nil, fmt.Errorf("migrating to format version %d: %w", m.version, err)
+		}
+		if changed {
+			applied = append(applied, m)
+		}
+	}
+	if version < latestSchemaVersion() {
+		value := &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!int", Value: strconv.Itoa(latestSchemaVersion())}
+		if node != nil {
+			*node = *value
+		} else {
+			// The comment heading the file stays above the new key.
+			key := &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: "Version"}
+			if len(root.Content) > 0 {
+				key.HeadComment, root.Content[0].HeadComment = root.Content[0].HeadComment, ""
+			}
+			root.Content = append([]*yaml.Node{key, value}, root.Content...)
+		}
+	}
+	return applied, nil
+}
+
+// upgradeRadarData decodes content like decodeRadarData and returns the
+// migrations applied to bring it to the latest format version. Content that
+// no migration changed is decoded as is; migrated content is decoded as YAML,
+// so its error line numbers may not match the file.
+func upgradeRadarData(file string, content []byte) (RadarData, []schemaMigration, error) {
+	doc, err := parseRadarNode(file, content)
+	if err != nil {
+		// Let the decoder report the syntax error.
+		data, err := decodeRadarContent(file, content)
+		return data, nil, err
+	}
+	applied, err := migrateRadarNode(file, doc)
+	if err != nil {
+		return RadarData{}, nil, err
+	}
+	if len(applied) == 0 {
+		data, err := decodeRadarContent(file, content)
+		return data, nil, err
+	}
+
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	if err := enc.Encode(doc); err != nil {
+		return RadarData{}, nil, err
+	}
+	if err := enc.Close(); err != nil {
+		return RadarData{}, nil, err
+	}
+	data, err := decodeYAMLRadarData(file, buf.Bytes())
+	return data, applied, err
+}
+
+// logSchemaMigrations logs the migrations applied to a data file.
+func logSchemaMigrations(file string, applied []schemaMigration) {
+	for _, m := range applied {
+		log.Printf("Migrated %s to data format version %d: %s", file, m.version, m.description)
+	}
+}
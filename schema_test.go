@@ -0,0 +1,126 @@
+package main
+
+import (
+	"errors"
+	"os"
+	"strings"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+// useSchemaMigrations replaces the registered migrations for the test.
+func useSchemaMigrations(t *testing.T, migrations ...schemaMigration) {
+	old := schemaMigrations
+	schemaMigrations = migrations
+	t.Cleanup(func() { schemaMigrations = old })
+}
+
+// renameSummaryMigration is a version 2 migration renaming the Summary
+// field of items to Description.
+var renameSummaryMigration = schemaMigration{
+	version:     2,
+	description: "renamed Summary to Description",
+	apply: func(root *yaml.Node) (bool, error) {
+		items := mappingValue(root, "Items")
+		if items == nil {
+			return false, nil
+		}
+		changed := false
+		for _, item := range items.Content {
+			if i := yamlMappingIndex(item, "Summary"); i >= 0 {
+				item.Content[i].Value = "Description"
+				changed = true
+			}
+		}
+		return changed, nil
+	},
+}
+
+func TestUpgradeRadarData(t *testing.T) {
+	useSchemaMigrations(t, renameSummaryMigration)
+
+	tests := []struct {
+		name, file, data string
+		migrated         bool
+	}{
+		{name: "yaml without version", file: "radar.yaml", data: "Items:\n- Label: Go\n  Summary: Fast\n", migrated: true},
+		{name: "yaml version 1", file: "radar.yaml", data: "Version: 1\nItems:\n- Label: Go\n  Summary: Fast\n", migrated: true},
+		{name: "json", file: "radar.json", data: `{"Items": [{"Label": "Go", "Summary": "Fast"}]}`, migrated: true},
+		{name: "toml", file: "radar.toml", data: "[[Items]]\nLabel = \"Go\"\nSummary = \"Fast\"\n", migrated: true},
+		{name: "latest version", file: "radar.yaml", data: "Version: 2\nItems:\n- Label: Go\n  Description: Fast\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, applied, err := upgradeRadarData(tt.file, []byte(tt.data))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := len(applied) > 0; got != tt.migrated {
+				t.Errorf("applied = %v, want migrated %v", applied, tt.migrated)
+			}
+			if data.Version != 2 || len(data.Items) != 1 || data.Items[0].Description != "Fast" {
+				t.Errorf("upgradeRadarData() = %+v, want version 2 with the description of Go", data)
+			}
+		})
+	}
+}
+
+func TestUpgradeRadarDataRejectsUnknownVersions(t *testing.T) {
+	useSchemaMigrations(t)
+
+	tests := []struct {
+		data, want string
+	}{
+		{data: "Version: 2\nItems: []\n", want: "radar.yaml:1: Version: format version 2 is newer than the latest supported version 1"},
+		{data: "Items: []\nVersion: latest\n", want: "radar.yaml:2: Version: must be a positive integer"},
+		{data: "Version: 0\n", want: "radar.yaml:1: Version: must be a positive integer"},
+	}
+	for _, tt := range tests {
+		_, _, err := upgradeRadarData("radar.yaml", []byte(tt.data))
+		var errs ValidationErrors
+		if !errors.As(err, &errs) || err.Error() != tt.want {
+			t.Errorf("upgradeRadarData(%q) error = %v, want %q", tt.data, err, tt.want)
+		}
+	}
+}
+
+func TestValidateMigratesOlderVersions(t *testing.T) {
+	useSchemaMigrations(t, renameSummaryMigration)
+
+	data := "Items:\n- Label: Go\n  Quadrant: Tools\n  Ring: Adopted\n  Summary: Fast\n"
+	if err := validateRadarContent("radar.yaml", []byte(data), newValidationScope()); err != nil {
+		t.Errorf("validateRadarContent() = %v, want the migrated data to be valid", err)
+	}
+
+	// The migration leaves its version's own fields alone.
+	data = "Version: 2\n" + data
+	err := validateRadarContent("radar.yaml", []byte(data), newValidationScope())
+	if err == nil || !strings.Contains(err.Error(), "unknown field Summary") {
+		t.Errorf("validateRadarContent() = %v, want unknown field Summary", err)
+	}
+}
+
+func TestReadRadarDataWritesLatestVersion(t *testing.T) {
+	useSchemaMigrations(t, renameSummaryMigration)
+	path := writeFile(t, "radar.yaml", "# Team radar\nItems:\n- Label: Go\n  Summary: Fast\n")
+
+	data, err := readRadarData(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := writeRadarData(path, data); err != nil {
+		t.Fatal(err)
+	}
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(content), "# Team radar") {
+		t.Errorf("saved data lost its comment:\n%s", content)
+	}
+	data, applied, err := upgradeRadarData(path, content)
+	if err != nil || len(applied) > 0 || data.Version != 2 {
+		t.Errorf("saved data = %+v, applied %v, %v; want version 2 needing no migration", data, applied, err)
+	}
+}
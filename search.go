@@ -0,0 +1,278 @@
+package main
+
+import (
+	"cmp"
+	"encoding/json"
+	"fmt"
+	"html"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Limits of GET /api/search.
+const (
+	defaultSearchLimit = 20
+	maxSearchLimit     = 100
+	// snippetLength is the number of characters of the description shown
+	// around the first match.
+	snippetLength = 160
+)
+
+// Weights of a query term matching each field of an item. A term matching
+// the whole label outranks one matching its start, which outranks one
+// found anywhere in it.
+const (
+	scoreLabelExact  = 20
+	scoreLabelPrefix = 10
+	scoreLabel       = 5
+	scoreTag         = 4
+	scoreOwner       = 3
+	scoreDescription = 1
+	// maxDescriptionMatches caps the score a term gets from being repeated
+	// in a description.
+	maxDescriptionMatches = 3
+)
+
+// SearchResult is an item matching a search, with its score, the fields
+// the query matched and an HTML snippet of its description with the
+// matches in <mark> elements. GET /api/search serves it with its _links.
+type SearchResult struct {
+	RadarItem
+	Score   int        `json:"score"`
+	Matched []string   `json:"matched"`
+	Snippet string     `json:"snippet,omitempty"`
+	Links   *ItemLinks `json:"_links,omitempty"`
+}
+
+// searchTerms splits a query into lowercase words. + and # are kept so
+// "C++" and "C#" can be searched for.
+func searchTerms(query string) []string {
+	var terms []string
+	for _, term := range strings.FieldsFunc(strings.ToLower(query), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '+' && r != '#'
+	}) {
+		if !slices.Contains(terms, term) {
+			terms = append(terms, term)
+		}
+	}
+	return terms
+}
+
+// isWordStart reports whether the byte at i of s starts a word.
+func isWordStart(s string, i int) bool {
+	if i == 0 {
+		return true
+	}
+	r, _ := utf8.DecodeLastRuneInString(s[:i])
+	return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+}
+
+// scoreItem returns how well item matches every one of terms, with the
+// fields they were found in, or 0 if one of them isn't found.
+func scoreItem(item RadarItem, terms []string) (int, []string) {
+	label := strings.ToLower(item.Label)
+	description := strings.ToLower(item.Description)
+	score := 0
+	var matched []string
+	match := func(field string, points int) {
+		score += points
+		if !slices.Contains(matched, field) {
+			matched = append(matched, field)
+		}
+	}
+	for _, term := range terms {
+		before := score
+		switch i := strings.Index(label, term); {
+		case label == term || normalizedLabel(label) == term:
+			match("label", scoreLabelExact)
+		case i >= 0 && isWordStart(label, i):
+			match("label", scoreLabelPrefix)
+		case i >= 0:
+			match("label", scoreLabel)
+		}
+		if slices.ContainsFunc(item.Tags, func(tag string) bool { return strings.HasPrefix(tag, term) }) {
+			match("tags", scoreTag)
+		}
+		if slices.ContainsFunc(item.Owners, func(o Owner) bool {
+			return strings.Contains(strings.ToLower(o.Name), term) || strings.Contains(strings.ToLower(o.Team), term) || strings.Contains(strings.ToLower(o.Email), term)
+		}) {
+			match("owners", scoreOwner)
+		}
+		if n := strings.Count(description, term); n > 0 {
+			match("description", scoreDescription*min(n, maxDescriptionMatches))
+		}
+		if score == before {
+			return 0, nil
+		}
+	}
+	return score, matched
+}
+
+// matchRanges returns the byte ranges of text where one of terms occurs,
+// ignoring case, in order and without overlaps.
+func matchRanges(text string, terms []string) [][2]int {
+	var ranges [][2]int
+	for i := 0; i < len(text); {
+		end := i
+		for _, term := range terms {
+			if n := len(term); i+n <= len(text) && n > end-i && strings.EqualFold(text[i:i+n], term) {
+				end = i + n
+			}
+		}
+		if end > i {
+			ranges = append(ranges, [2]int{i, end})
+			i = end
+			continue
+		}
+		_, size := utf8.DecodeRuneInString(text[i:])
+		i += size
+	}
+	return ranges
+}
+
+// highlight returns text escaped as HTML with the ranges in <mark>
+// elements.
+func highlight(text string, ranges [][2]int) string {
+	var b strings.Builder
+	last := 0
+	for _, r := range ranges {
+		b.WriteString(html.EscapeString(text[last:r[0]]))
+		b.WriteString("<mark>" + html.EscapeString(text[r[0]:r[1]]) + "</mark>")
+		last = r[1]
+	}
+	b.WriteString(html.EscapeString(text[last:]))
+	return b.String()
+}
+
+// searchSnippet returns about snippetLength characters of description
+// around its first match of terms, highlighted, or "" if it has none.
+func searchSnippet(description string, terms []string) string {
+	description = strings.Join(strings.Fields(description), " ")
+	ranges := matchRanges(description, terms)
+	if len(ranges) == 0 {
+		return ""
+	}
+	start, end := 0, len(description)
+	if utf8.RuneCountInString(description) > snippetLength {
+		// Start a little before the match, at a word.
+		start = ranges[0][0]
+		for n := 0; start > 0 && n < snippetLength/4; n++ {
+			_, size := utf8.DecodeLastRuneInString(description[:start])
+			start -= size
+		}
+		if i := strings.IndexByte(description[start:ranges[0][0]], ' '); start > 0 && i >= 0 {
+			start += i + 1
+		}
+		end = start
+		for n := 0; end < len(description) && n < snippetLength; n++ {
+			_, size := utf8.DecodeRuneInString(description[end:])
+			end += size
+		}
+		if i := strings.LastIndexByte(description[ranges[0][1]:end], ' '); end < len(description) && i >= 0 {
+			end = ranges[0][1] + i
+		}
+	}
+
+	var inside [][2]int
+	for _, r := range ranges {
+		if r[0] >= start && r[1] <= end {
+			inside = append(inside, [2]int{r[0] - start, r[1] - start})
+		}
+	}
+	snippet := highlight(description[start:end], inside)
+	if start > 0 {
+		snippet = "…" + snippet
+	}
+	if end < len(description) {
+		snippet += "…"
+	}
+	return snippet
+}
+
+// searchItems returns the items matching every term of query, best first
+// and then by label.
+func searchItems(items []RadarItem, query string) []SearchResult {
+	terms := searchTerms(query)
+	if len(terms) == 0 {
+		return nil
+	}
+	var results []SearchResult
+	for _, item := range items {
+		if score, matched := scoreItem(item, terms); score > 0 {
+			results = append(results, SearchResult{RadarItem: item, Score: score, Matched: matched, Snippet: searchSnippet(item.Description, terms)})
+		}
+	}
+	slices.SortStableFunc(results, func(a, b SearchResult) int {
+		return cmp.Or(b.Score-a.Score, strings.Compare(labelKey(a.Label), labelKey(b.Label)))
+	})
+	return results
+}
+
+// searchLimit returns the number of results asked for with ?limit=.
+func searchLimit(r *http.Request) (int, error) {
+	value := r.URL.Query().Get("limit")
+	if value == "" {
+		return defaultSearchLimit, nil
+	}
+	limit, err := strconv.Atoi(value)
+	if err != nil || limit < 1 || limit > maxSearchLimit {
+		return 0, &AppError{Code: http.StatusBadRequest, Message: fmt.Sprintf("Invalid limit %q, must be between 1 and %d", value, maxSearchLimit)}
+	}
+	return limit, nil
+}
+
+// runSearch returns the query of a request for GET /api/search, the
+// number of items matching it and the results up to ?limit=.
+func runSearch(r *http.Request) (string, int, []SearchResult, error) {
+	query := strings.TrimSpace(r.URL.Query().Get("q"))
+	if len(searchTerms(query)) == 0 {
+		return "", 0, nil, &AppError{Code: http.StatusBadRequest, Message: "Missing search query q"}
+	}
+	limit, err := searchLimit(r)
+	if err != nil {
+		return "", 0, nil, err
+	}
+	include, err := includeArchived(r)
+	if err != nil {
+		return "", 0, nil, err
+	}
+	data, err := loadRadarData()
+	if err != nil {
+		return "", 0, nil, err
+	}
+	items := data.Items
+	if !include {
+		items = visibleItems(items)
+	}
+	results := searchItems(items, query)
+	return query, len(results), results[:min(limit, len(results))], nil
+}
+
+// searchHandler serves the items matching ?q=, searched for in their
+// labels, tags, owners and descriptions.
+func searchHandler(w http.ResponseWriter, r *http.Request) {
+	query, total, results, err := runSearch(r)
+	if err != nil {
+		handleError(w, err)
+		return
+	}
+	body := struct {
+		Query   string             `json:"query"`
+		Total   int                `json:"total"`
+		Results []SearchResult     `json:"results"`
+		Links   map[string]HALLink `json:"_links"`
+	}{query, total, make([]SearchResult, len(results)), map[string]HALLink{"self": {requestURL(r).String()}}}
+	for i, result := range results {
+		links := itemLinks(result.RadarItem)
+		result.Links = &links
+		body.Results[i] = result
+	}
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(body); err != nil {
+		handleError(w, &AppError{Code: http.StatusInternalServerError, Message: "Failed to encode response", Err: err})
+	}
+}
@@ -0,0 +1,95 @@
+package main
+
+import (
+	"encoding/json"
+	"html"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestSearchItems(t *testing.T) {
+	items := []RadarItem{
+		{Label: "Kafka Streams", Description: "Stream processing on top of Kafka."},
+		{Label: "Redpanda", Description: "A Kafka-compatible broker.", Tags: Tags{"messaging"}},
+		{Label: "Kafka", Description: "Event streaming platform.", Owners: Owners{{Name: "Ana", Team: "Platform"}}},
+		{Label: "RabbitMQ", Tags: Tags{"messaging"}, Owners: Owners{{Name: "Bo", Team: "platform"}}},
+	}
+	labels := func(results []SearchResult) []string {
+		var out []string
+		for _, r := range results {
+			out = append(out, r.Label)
+		}
+		return out
+	}
+
+	for query, want := range map[string][]string{
+		"kafka":           {"Kafka", "Kafka Streams", "Redpanda"},
+		"KAFKA platform":  {"Kafka"},
+		"messag":          {"RabbitMQ", "Redpanda"},
+		"platform broker": nil,
+		"!!":              nil,
+	} {
+		if got := labels(searchItems(items, query)); !reflect.DeepEqual(got, want) {
+			t.Errorf("searchItems(%q) = %q, want %q", query, got, want)
+		}
+	}
+
+	results := searchItems(items, "kafka")
+	if results[0].Score <= results[1].Score || !reflect.DeepEqual(results[1].Matched, []string{"label", "description"}) {
+		t.Errorf("searchItems(kafka) = %+v", results)
+	}
+	if got, want := results[1].Snippet, "Stream processing on top of <mark>Kafka</mark>."; got != want {
+		t.Errorf("snippet = %q, want %q", got, want)
+	}
+}
+
+func TestSearchSnippet(t *testing.T) {
+	long := strings.Repeat("lorem ipsum ", 30) + "uses <Kafka> & more " + strings.Repeat("dolor sit ", 30)
+	got := searchSnippet(long, []string{"kafka"})
+	if !strings.HasPrefix(got, "…") || !strings.HasSuffix(got, "…") || !strings.Contains(got, "uses &lt;<mark>Kafka</mark>&gt; &amp; more") {
+		t.Errorf("searchSnippet() = %q", got)
+	}
+	text := html.UnescapeString(strings.NewReplacer("<mark>", "", "</mark>", "").Replace(got))
+	if n := len([]rune(text)); n > snippetLength+2 {
+		t.Errorf("searchSnippet() is %d characters long, want at most %d", n, snippetLength+2)
+	}
+	if got := searchSnippet("Go is fast", []string{"rust"}); got != "" {
+		t.Errorf("searchSnippet() without a match = %q, want none", got)
+	}
+}
+
+func TestSearchHandler(t *testing.T) {
+	cfg := defaultConfig()
+	cfg.Data.Path = writeFile(t, "radar.yaml", radarWith("Kafka", "Adopted")+
+		"- Label: Kafka Connect\n  Quadrant: Tools\n  Ring: Retired\n  Archived: true\n")
+	useConfig(t, cfg)
+	handler, err := setupRoutes(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for target, want := range map[string]int{
+		"/api/search?q=kafka":                              1,
+		"/api/search?q=kafka&includeArchived=true":         2,
+		"/api/search?q=kafka&includeArchived=true&limit=1": 1,
+	} {
+		rec := doRequest(t, handler, http.MethodGet, target)
+		var body struct {
+			Total   int
+			Results []SearchResult
+		}
+		if err := json.NewDecoder(rec.Body).Decode(&body); err != nil {
+			t.Fatalf("GET %s: status %d: %v", target, rec.Code, err)
+		}
+		if len(body.Results) != want || body.Results[0].Label != "Kafka" {
+			t.Errorf("GET %s = %+v, want %d results", target, body, want)
+		}
+	}
+	for _, target := range []string{"/api/search", "/api/search?q=+", "/api/search?q=go&limit=0", "/api/search?q=go&limit=1000"} {
+		if rec := doRequest(t, handler, http.MethodGet, target); rec.Code != http.StatusBadRequest {
+			t.Errorf("GET %s = %d, want 400", target, rec.Code)
+		}
+	}
+}
@@ -0,0 +1,146 @@
+package main
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// Segment defines one of the quadrants or rings of the radar.
+type Segment struct {
+	Name        string `yaml:"Name" json:"name"`
+	Color       string `yaml:"Color,omitempty" json:"color,omitempty" toml:"Color,omitempty"`
+	Description string `yaml:"Description,omitempty" json:"description,omitempty" toml:"Description,omitempty"`
+	// MovesTo, for a ring, lists the rings its items may be moved to by a
+	// save; when empty they may be moved to any ring.
+	MovesTo []string `yaml:"MovesTo,omitempty" json:"movesTo,omitempty" toml:"MovesTo,omitempty"`
+	// RequiresJustification, for a ring, rejects saves moving items into it
+	// without a justification, see checkRingMoves.
+	RequiresJustification bool `yaml:"RequiresJustification,omitempty" json:"requiresJustification,omitempty" toml:"RequiresJustification,omitempty"`
+}
+
+// defaultQuadrants are the quadrants of a radar whose data declares none.
+var defaultQuadrants = []Segment{
+	{Name: "Platforms"},
+	{Name: "Tools"},
+	{Name: "Programming Languages & Frameworks"},
+	{Name: "Techniques"},
+}
+
+// defaultRings are the rings of a radar whose data declares none, from the
+// innermost outwards.
+var defaultRings = []Segment{
+	{Name: "Adopted", Color: "#00C000"},
+	{Name: "In Discovery", Color: "#FFA500"},
+	{Name: "Not Recommended", Color: "#FF0000"},
+}
+
+// segmentPalette colors the segments declared without a color, in turn,
+// like the page does with d3.schemeTableau10.
+var segmentPalette = []string{"#4e79a7", "#f28e2c", "#e15759", "#76b7b2", "#59a14f", "#edc949", "#af7aa1", "#ff9da7", "#9c755f", "#bab0ab"}
+
+// segmentColor returns the color of the i-th of segments: its own, or one
+// of segmentPalette.
+func segmentColor(segments []Segment, i int) string {
+	if segments[i].Color != "" {
+		return segments[i].Color
+	}
+	return segmentPalette[i%len(segmentPalette)]
+}
+
+// segmentColorPattern matches the colors a segment may be drawn in: a hex
+// color such as #0a0 or #00c000.
+var segmentColorPattern = regexp.MustCompile(`^#([0-9a-fA-F]{3}|[0-9a-fA-F]{6})$`)
+
+// quadrants returns the quadrants declared by d, or the default ones.
+func (d RadarData) quadrants() []Segment {
+	if len(d.Quadrants) > 0 {
+		return d.Quadrants
+	}
+	return defaultQuadrants
+}
+
+// rings returns the rings declared by d, or those of fallbackRings.
+func (d RadarData) rings() []Segment {
+	if len(d.Rings) > 0 {
+		return d.Rings
+	}
+	return fallbackRings()
+}
+
+// withSegments returns data with the quadrants and rings its items may use
+// spelled out, so the API and the page don't have to know the defaults.
+func withSegments(data RadarData) RadarData {
+	data.Quadrants, data.Rings = data.quadrants(), data.rings()
+	return data
+}
+
+// segmentNames returns the names of segments in order.
+func segmentNames(segments []Segment) []string {
+	names := make([]string, len(segments))
+	for i, s := range segments {
+		names[i] = s.Name
+	}
+	return names
+}
+
+// segmentChoiceError returns why value can't be the Quadrant or Ring field
+// of an item, or "" if it is one of allowed.
+func segmentChoiceError(field, value string, allowed []string) string {
+	switch {
+	case strings.TrimSpace(value) == "":
+		return "missing"
+	case !slices.Contains(allowed, value):
+		return fmt.Sprintf("unknown %s %q, must be one of %s", strings.ToLower(field), value, strings.Join(allowed, ", "))
+	}
+	return ""
+}
+
+// segmentChoiceRule returns the rule broken by the Quadrant or Ring value
+// segmentChoiceError rejects.
+func segmentChoiceRule(value string) string {
+	if strings.TrimSpace(value) == "" {
+		return ruleRequired
+	}
+	return ruleAllowedValue
+}
+
+// keepSegments returns data with the quadrants and rings of current when it
+// declares none itself, so saving items doesn't drop the definitions.
+func keepSegments(data, current RadarData) RadarData {
+	if data.Quadrants == nil {
+		data.Quadrants = current.Quadrants
+	}
+	if data.Rings == nil {
+		data.Rings = current.Rings
+	}
+	return data
+}
+
+// segmentDeclarations collects the quadrants and rings declared by the data
+// files loaded together. They apply to the items of every file, so they
+// may be declared by any one file, or by several files that agree.
+type segmentDeclarations struct {
+	quadrants, rings         []Segment
+	quadrantsFile, ringsFile string
+}
+
+// add records the quadrants and rings declared by data, loaded from file,
+// reporting declarations that differ from those of an earlier file.
+func (d *segmentDeclarations) add(file string, data RadarData) ValidationErrors {
+	var errs ValidationErrors
+	declare := func(field string, segments []Segment, declared *[]Segment, declaredIn *string) {
+		switch {
+		case segments == nil:
+		case *declared == nil:
+			*declared, *declaredIn = segments, file
+		case !reflect.DeepEqual(segments, *declared):
+			errs = append(errs, ValidationError{File: file, Field: field, Rule: ruleConsistent, Message: "differ from those declared in " + *declaredIn})
+		}
+	}
+	declare("Quadrants", data.Quadrants, &d.quadrants, &d.quadrantsFile)
+	declare("Rings", data.Rings, &d.rings, &d.ringsFile)
+	return errs
+}
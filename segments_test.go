@@ -0,0 +1,142 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// customSegments declares two quadrants and two rings.
+const customSegments = `Quadrants:
+- Name: Backend
+  Color: "#336699"
+- Name: Frontend
+Rings:
+- Name: Use
+  Color: "#0a0"
+  Description: Proven in production.
+- Name: Avoid
+`
+
+func TestValidateSegments(t *testing.T) {
+	content := customSegments + "Items:\n- Label: Go\n  Quadrant: Backend\n  Ring: Use\n- Label: Perl\n  Quadrant: Tools\n  Ring: Avoid\n"
+	err := validateRadarContent("radar.yaml", []byte(content), newValidationScope())
+	want := `radar.yaml:15: item "Perl".Quadrant: unknown quadrant "Tools", must be one of Backend, Frontend`
+	if err == nil || err.Error() != want {
+		t.Errorf("validateRadarContent() = %v, want %s", err, want)
+	}
+
+	content = `Quadrants: []
+Rings:
+- Name: Use
+  Color: green
+- Name: use
+- Description: No name.
+Items: []
+`
+	err = validateRadarContent("radar.yaml", []byte(content), newValidationScope())
+	wantErrs := []string{
+		`radar.yaml:1: Quadrants: must list at least one quadrant`,
+		`radar.yaml:4: Rings[0].Color: invalid color "green", must be a hex color such as #00c000`,
+		`radar.yaml:5: Rings[1].Name: duplicate name "use", first defined on line 3`,
+		`radar.yaml:6: Rings[2].Name: missing`,
+	}
+	if err == nil || err.Error() != strings.Join(wantErrs, "\n") {
+		t.Errorf("validateRadarContent() = %v, want\n%s", err, strings.Join(wantErrs, "\n"))
+	}
+}
+
+func TestSegmentsAcrossFiles(t *testing.T) {
+	dir := writeDataDir(t, map[string]string{
+		"a.yaml":       "Items:\n- Label: Go\n  Quadrant: Backend\n  Ring: Use\n",
+		"segments.yml": customSegments,
+	})
+	if err := validateRadarData(dir); err != nil {
+		t.Errorf("validateRadarData() = %v, want the segments of segments.yml to apply to a.yaml", err)
+	}
+	data, err := readRadarData(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if names := segmentNames(data.rings()); !reflect.DeepEqual(names, []string{"Use", "Avoid"}) {
+		t.Errorf("readRadarData() rings = %q, want Use, Avoid", names)
+	}
+
+	dir = writeDataDir(t, map[string]string{
+		"a.yaml": "Rings:\n- Name: Use\nItems: []\n",
+		"b.yaml": "Rings:\n- Name: Avoid\nItems: []\n",
+	})
+	want := filepath.Join(dir, "b.yaml") + ": Rings: differ from those declared in " + filepath.Join(dir, "a.yaml")
+	if err := validateRadarData(dir); err == nil || err.Error() != want {
+		t.Errorf("validateRadarData() = %v, want %s", err, want)
+	}
+	if _, err := readRadarData(dir); err == nil || !strings.Contains(err.Error(), want) {
+		t.Errorf("readRadarData() = %v, want %s", err, want)
+	}
+}
+
+func TestFileStoreSaveKeepsSegments(t *testing.T) {
+	path := writeFile(t, "radar.yaml", customSegments+"Items: []\n")
+	store := newFileStore(path)
+	defer store.Close()
+	ctx := context.Background()
+
+	if err := store.Save(ctx, RadarData{Items: []RadarItem{{Label: "Go", Quadrant: "Backend", Ring: "Use"}}}); err != nil {
+		t.Fatal(err)
+	}
+	data, err := readRadarData(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(data.Quadrants) != 2 || len(data.Rings) != 2 || len(data.Items) != 1 {
+		t.Errorf("readRadarData() = %+v, want the declared quadrants and rings kept", data)
+	}
+
+	err = store.Save(ctx, RadarData{Items: []RadarItem{{Label: "Go", Quadrant: "Tools", Ring: "Adopted"}}})
+	want := `item "Go".Quadrant: unknown quadrant "Tools", must be one of Backend, Frontend` + "\n" +
+		`item "Go".Ring: unknown ring "Adopted", must be one of Use, Avoid`
+	if err == nil || err.Error() != want {
+		t.Errorf("Save() = %v, want\n%s", err, want)
+	}
+}
+
+func TestSegmentsAPI(t *testing.T) {
+	for name, tt := range map[string]struct {
+		data             string
+		quadrants, rings []string
+		quadrantOption   string
+	}{
+		"defaults": {"Items: []\n", segmentNames(defaultQuadrants), segmentNames(defaultRings), `<option value="Techniques">`},
+		"declared": {customSegments + "Items: []\n", []string{"Backend", "Frontend"}, []string{"Use", "Avoid"}, `<option value="Backend">`},
+	} {
+		t.Run(name, func(t *testing.T) {
+			cfg := defaultConfig()
+			cfg.Data.Path = writeFile(t, "radar.yaml", tt.data)
+			useConfig(t, cfg)
+			handler, err := setupRoutes(cfg)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			rec := doRequest(t, handler, http.MethodGet, "/api/radar")
+			var data RadarData
+			if err := json.Unmarshal(rec.Body.Bytes(), &data); err != nil {
+				t.Fatalf("GET /api/radar = %d %q", rec.Code, rec.Body.String())
+			}
+			if got := segmentNames(data.Quadrants); !reflect.DeepEqual(got, tt.quadrants) {
+				t.Errorf("GET /api/radar quadrants = %q, want %q", got, tt.quadrants)
+			}
+			if got := segmentNames(data.Rings); !reflect.DeepEqual(got, tt.rings) {
+				t.Errorf("GET /api/radar rings = %q, want %q", got, tt.rings)
+			}
+
+			if body := doRequest(t, handler, http.MethodGet, "/").Body.String(); !strings.Contains(body, tt.quadrantOption) {
+				t.Errorf("GET / has no %s quadrant filter option", tt.quadrantOption)
+			}
+		})
+	}
+}
@@ -0,0 +1,166 @@
+package main
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"log"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"os/signal"
+	"strings"
+	"sync"
+	"syscall"
+)
+
+// runServer serves handler until the process receives SIGINT or SIGTERM,
+// then stops accepting connections and waits up to the configured drain
+// timeout for in-flight requests to complete.
+func runServer(cfg Config, handler http.Handler) error {
+	servers := []*http.Server{newHTTPServer(cfg, cfg.Server.Listen, handler)}
+	names := []string{"Server"}
+	if cfg.Server.TLS.enabled() {
+		var redirect http.Handler = httpsRedirectHandler(cfg.Server.Listen)
+		if acme := cfg.Server.TLS.ACME; acme.enabled() {
+			manager := newACMEManager(acme)
+			servers[0].TLSConfig = manager.TLSConfig()
+			// The redirect listener also answers HTTP-01 challenges.
+			redirect = manager.HTTPHandler(redirect)
+			log.Printf("Obtaining certificates from Let's Encrypt for %s", strings.Join(acme.Domains, ", "))
+		} else {
+			certs, err := loadCertificateFiles(cfg.Server.TLS.Cert, cfg.Server.TLS.Key)
+			if err != nil {
+				return err
+			}
+			activeCertificate.Store(certs)
+			servers[0].TLSConfig = &tls.Config{GetCertificate: certs.getCertificate}
+		}
+		if cfg.Server.TLS.RedirectListen != "" {
+			servers = append(servers, newHTTPServer(cfg, cfg.Server.TLS.RedirectListen, redirect))
+			names = append(names, "HTTPS redirect")
+		}
+	}
+	if cfg.Server.GRPCListen != "" {
+		servers = append(servers, newGRPCServer(cfg, servers[0].TLSConfig))
+		names = append(names, "gRPC API")
+	}
+
+	addrs := make([]string, len(servers))
+	for i, srv := range servers {
+		addrs[i] = srv.Addr
+	}
+	listeners, err := openListeners(addrs)
+	if err != nil {
+		return err
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	serveErr := make(chan error, len(servers))
+	for i, srv := range servers {
+		go func() {
+			serveErr <- serve(srv, listeners[i], names[i])
+		}()
+	}
+
+	select {
+	case err := <-serveErr:
+		return err
+	case <-ctx.Done():
+	}
+	stop()
+
+	log.Printf("Shutting down, waiting up to %s for in-flight requests", cfg.Server.ShutdownTimeout)
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), cfg.Server.ShutdownTimeout)
+	defer cancel()
+
+	var wg sync.WaitGroup
+	shutdownErrs := make([]error, len(servers))
+	for i, srv := range servers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			shutdownErrs[i] = srv.Shutdown(shutdownCtx)
+		}()
+	}
+	wg.Wait()
+	if err := errors.Join(shutdownErrs...); err != nil {
+		return err
+	}
+	for range servers {
+		if err := <-serveErr; !errors.Is(err, http.ErrServerClosed) {
+			return err
+		}
+	}
+	log.Printf("Server stopped")
+	return nil
+}
+
+// newHTTPServer returns an http.Server for addr with the configured timeouts.
+func newHTTPServer(cfg Config, addr string, handler http.Handler) *http.Server {
+	return &http.Server{
+		Addr:              addr,
+		Handler:           handler,
+		ReadTimeout:       cfg.Server.ReadTimeout,
+		ReadHeaderTimeout: cfg.Server.ReadHeaderTimeout,
+		WriteTimeout:      cfg.Server.WriteTimeout,
+		IdleTimeout:       cfg.Server.IdleTimeout,
+	}
+}
+
+// newGRPCServer returns the http.Server of the gRPC API, which speaks
+// HTTP/2 only: over TLS with tlsConfig when it is set, and without (h2c)
+// otherwise. Streams stay open for as long as clients watch, so only the
+// header and idle timeouts apply, and calls still running on shutdown are
+// canceled with errServerClosing.
+func newGRPCServer(cfg Config, tlsConfig *tls.Config) *http.Server {
+	ctx, cancel := context.WithCancelCause(context.Background())
+	srv := &http.Server{
+		Addr:              cfg.Server.GRPCListen,
+		Handler:           http.HandlerFunc(grpcHandler),
+		ReadHeaderTimeout: cfg.Server.ReadHeaderTimeout,
+		IdleTimeout:       cfg.Server.IdleTimeout,
+		TLSConfig:         tlsConfig,
+		Protocols:         new(http.Protocols),
+		BaseContext:       func(net.Listener) context.Context { return ctx },
+	}
+	if tlsConfig != nil {
+		srv.Protocols.SetHTTP2(true)
+	} else {
+		srv.Protocols.SetUnencryptedHTTP2(true)
+	}
+	srv.RegisterOnShutdown(func() { cancel(errServerClosing) })
+	return srv
+}
+
+// serve runs srv on ln until it is shut down, with TLS when the server has
+// a certificate. name describes the server in the log.
+func serve(srv *http.Server, ln net.Listener, name string) error {
+	addr := ln.Addr().Network() + " " + ln.Addr().String()
+	if srv.TLSConfig != nil {
+		log.Printf("%s running with HTTPS on %s", name, addr)
+		return srv.ServeTLS(ln, "", "")
+	}
+	log.Printf("%s running with HTTP on %s", name, addr)
+	return srv.Serve(ln)
+}
+
+// httpsRedirectHandler permanently redirects every request to the same URL
+// on the HTTPS listener at httpsAddr.
+func httpsRedirectHandler(httpsAddr string) http.Handler {
+	_, httpsPort, _ := net.SplitHostPort(httpsAddr)
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Hostname strips the port and the brackets around IPv6 literals.
+		host := (&url.URL{Host: r.Host}).Hostname()
+		switch {
+		case httpsPort != "" && httpsPort != "443":
+			host = net.JoinHostPort(host, httpsPort)
+		case strings.Contains(host, ":"):
+			host = "[" + host + "]"
+		}
+		http.Redirect(w, r, "https://"+host+r.URL.RequestURI(), http.StatusMovedPermanently)
+	})
+}
@@ -0,0 +1,40 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestHTTPSRedirectHandler(t *testing.T) {
+	tests := []struct {
+		name      string
+		httpsAddr string
+		host      string
+		target    string
+		want      string
+	}{
+		{name: "default port", httpsAddr: ":443", host: "radar.example.com", target: "/api/radar?x=1", want: "https://radar.example.com/api/radar?x=1"},
+		{name: "strips http port", httpsAddr: ":443", host: "radar.example.com:80", target: "/", want: "https://radar.example.com/"},
+		{name: "custom port", httpsAddr: ":8443", host: "localhost:8080", target: "/health", want: "https://localhost:8443/health"},
+		{name: "ipv6 without port", httpsAddr: ":8443", host: "[::1]", target: "/", want: "https://[::1]:8443/"},
+		{name: "ipv6 with port", httpsAddr: ":8443", host: "[::1]:8080", target: "/", want: "https://[::1]:8443/"},
+		{name: "ipv6 default port", httpsAddr: ":443", host: "[::1]:80", target: "/", want: "https://[::1]/"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, tt.target, nil)
+			req.Host = tt.host
+			rec := httptest.NewRecorder()
+
+			httpsRedirectHandler(tt.httpsAddr).ServeHTTP(rec, req)
+
+			if rec.Code != http.StatusMovedPermanently {
+				t.Errorf("status = %d, want %d", rec.Code, http.StatusMovedPermanently)
+			}
+			if got := rec.Header().Get("Location"); got != tt.want {
+				t.Errorf("Location = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -2,16 +2,24 @@
 // Constants and Configuration
 // =============================================================================
 
-const RINGS = ['Not Recommended', 'In Discovery', 'Adopted']; // Inner to Outer visually
-const QUADRANTS = ['Platforms', 'Tools', 'Programming Languages & Frameworks', 'Techniques'];
+// Path prefix the app is mounted under, set by the server in the page template
+const BASE_PATH = window.RADAR_BASE_PATH || '';
+
+// Rings and quadrants, replaced by those the API declares once loaded
+let RINGS = ['Not Recommended', 'In Discovery', 'Adopted']; // Outer to inner
+let QUADRANTS = ['Platforms', 'Tools', 'Programming Languages & Frameworks', 'Techniques'];
 
 // Vivid node colors for better visibility
-const RING_COLORS = {
+let RING_COLORS = {
     'Adopted': '#00C000', // Bright Green
     'In Discovery': '#FFA500', // Orange
     'Not Recommended': '#FF0000'  // Bright Red
 };
 
+// Quadrant label colors and ring and quadrant descriptions, by name
+let QUADRANT_COLORS = {};
+let SEGMENT_DESCRIPTIONS = {};
+
 // Theme-specific UI colors
 const THEME_COLORS = {
     light: {
@@ -36,6 +44,44 @@ const THEME_COLORS = {
     }
 };
 
+// Reactions to items, by the name the API uses for them
+const REACTION_EMOJI = {
+    '+1': '👍',
+    rocket: '🚀',
+    warning: '⚠️'
+};
+
+// Arrows of moved items, by the direction the API gives
+const MOVED_ARROWS = {
+    up: '▲',
+    down: '▼'
+};
+
+/** Returns the arrow of a moved item, followed by a space, or nothing */
+function movedArrow(item) {
+    return item.moved && MOVED_ARROWS[item.direction] ? `${MOVED_ARROWS[item.direction]} ` : '';
+}
+
+// How relations read from the item that has them, and from the item they point at
+const RELATION_LABELS = {
+    replaces: ['Replaces', 'Replaced by'],
+    'related-to': ['Related to', 'Related to'],
+    'depends-on': ['Depends on', 'Needed by']
+};
+
+/** Returns the relations of an item and those of other items to it, as links to the related items */
+function formatRelations(item) {
+    const link = id => {
+        const related = radarData.find(other => other.id === id);
+        return related ? `<a href="#${encodeURIComponent(id)}" class="text-blue-600 dark:text-blue-400 underline">${related.label}</a>` : id;
+    };
+    const outgoing = (item.relations || []).map(r => [RELATION_LABELS[r.type]?.[0] || r.type, r.item]);
+    const incoming = radarData.flatMap(other => (other.relations || [])
+        .filter(r => r.item === item.id)
+        .map(r => [RELATION_LABELS[r.type]?.[1] || r.type, other.id]));
+    return [...outgoing, ...incoming].map(([label, id]) => `<li>${label} ${link(id)}</li>`).join('');
+}
+
 // Layout and appearance settings
 const LAYOUT = {
     margin: 0,
@@ -49,7 +95,8 @@ const LAYOUT = {
     forceStrength: 0.05,
     simulationTicks: 200,
     ringLabelExclusionRadius: 40,
-    resizeDebounceDelay: 250
+    resizeDebounceDelay: 250,
+    searchDebounceDelay: 200
 };
 
 // UI Selectors
@@ -62,6 +109,10 @@ const SELECTORS = {
     quadrantFilter: '#quadrant-filter',
     statusFilter: '#status-filter',
     listContainer: '#quadrants-list',
+    searchInput: '#search-input',
+    searchResults: '#search-results',
+    searchSuggestions: '#search-suggestions',
+    itemReactions: '#item-reactions',
     themeToggle: '#theme-toggle'
 };
 
@@ -89,6 +140,25 @@ function debounce(func, wait) {
     };
 }
 
+/** Replaces the rings and quadrants with those declared by the radar data */
+function applySegments(data) {
+    const rings = data.rings || [];
+    const quadrants = data.quadrants || [];
+    if (rings.length) {
+        // The API lists rings from the innermost outwards
+        RINGS = rings.map(ring => ring.name).reverse();
+        RING_COLORS = Object.fromEntries(rings.map((ring, i) =>
+            [ring.name, ring.color || d3.schemeTableau10[i % d3.schemeTableau10.length]]));
+    }
+    if (quadrants.length) {
+        QUADRANTS = quadrants.map(quadrant => quadrant.name);
+        QUADRANT_COLORS = Object.fromEntries(quadrants.filter(q => q.color).map(q => [q.name, q.color]));
+    }
+    SEGMENT_DESCRIPTIONS = Object.fromEntries([...rings, ...quadrants]
+        .filter(segment => segment.description)
+        .map(segment => [segment.name, segment.description]));
+}
+
 /** Filters data based on active filters */
 function filterData(data) {
     if (!activeFilters.ring && !activeFilters.quadrant) return data;
@@ -211,6 +281,18 @@ function initTheme() {
 // UI Interaction Functions
 // =============================================================================
 
+/** Formats the owners of an item as HTML, linking their email addresses */
+function formatOwners(owners) {
+    if (!owners || owners.length === 0) {
+        return 'N/A';
+    }
+    return owners.map(owner => {
+        const name = owner.email ? `<a href="mailto:${owner.email}" class="underline">${owner.name}</a>` : owner.name;
+        const details = [owner.team, owner.slack].filter(Boolean).join(', ');
+        return details ? `${name} (${details})` : name;
+    }).join('<br>');
+}
+
 /** Shows the details panel for a selected technology item */
 function showDetails(item) {
     const panel = document.querySelector(SELECTORS.detailsPanel);
@@ -230,6 +312,7 @@ function showDetails(item) {
 
     selectedNodeId = item.id;
     const ringColor = RING_COLORS[item.ring];
+    const relations = formatRelations(item);
 
     title.textContent = item.label;
     content.innerHTML = `
@@ -237,26 +320,85 @@ function showDetails(item) {
             <div class="w-4 h-4 rounded-full mr-2" style="background-color: ${ringColor};"></div>
             <span class="font-medium text-gray-800 dark:text-gray-200">${item.ring}</span>
         </div>
+        ${SEGMENT_DESCRIPTIONS[item.ring] ? `<p class="text-gray-500 dark:text-gray-400 text-sm -mt-2 mb-4">${SEGMENT_DESCRIPTIONS[item.ring]}</p>` : ''}
         <div class="details-item mb-4">
             <h4 class="font-semibold text-gray-600 dark:text-gray-400 mb-1">Quadrant</h4>
             <p class="text-gray-800 dark:text-gray-200">${item.quadrant}</p>
         </div>
         <div class="details-item mb-4">
-            <h4 class="font-semibold text-gray-600 dark:text-gray-400 mb-1">Owner</h4>
-            <p class="text-gray-800 dark:text-gray-200">${item.owners || 'N/A'}</p>
+            <h4 class="font-semibold text-gray-600 dark:text-gray-400 mb-1">Owners</h4>
+            <p class="text-gray-800 dark:text-gray-200">${formatOwners(item.owners)}</p>
         </div>
+        ${item.tags && item.tags.length ? `<div class="details-item mb-4">
+            <h4 class="font-semibold text-gray-600 dark:text-gray-400 mb-1">Tags</h4>
+            <p class="flex flex-wrap gap-1">${item.tags.map(tag => `<span class="text-xs px-2 py-0.5 rounded-full bg-gray-200 text-gray-800 dark:bg-gray-700 dark:text-gray-200">${tag}</span>`).join('')}</p>
+        </div>` : ''}
         <div class="details-item mb-4">
             <h4 class="font-semibold text-gray-600 dark:text-gray-400 mb-1">Description</h4>
             <p class="text-gray-800 dark:text-gray-200 text-sm">${item.description || 'No description available.'}</p>
         </div>
-        ${item.moved ? '<div class="details-item"><p class="moved text-sm italic text-gray-500 dark:text-gray-400 mt-2">* This item has been moved recently.</p></div>' : ''}
+        ${item.links && item.links.length ? `<div class="details-item mb-4">
+            <h4 class="font-semibold text-gray-600 dark:text-gray-400 mb-1">Links</h4>
+            <ul class="text-sm list-disc list-inside">${item.links.map(link => `<li><a href="${link.url}" target="_blank" rel="noopener noreferrer" class="text-blue-600 dark:text-blue-400 underline">${link.title || link.url}</a></li>`).join('')}</ul>
+        </div>` : ''}
+        ${relations ? `<div class="details-item mb-4">
+            <h4 class="font-semibold text-gray-600 dark:text-gray-400 mb-1">Related Items</h4>
+            <ul class="text-sm list-disc list-inside text-gray-800 dark:text-gray-200">${relations}</ul>
+        </div>` : ''}
+        ${item.lastUpdated ? `<div class="details-item mb-4">
+            <h4 class="font-semibold text-gray-600 dark:text-gray-400 mb-1">Last Updated</h4>
+            <p class="text-gray-800 dark:text-gray-200 text-sm">${new Date(item.lastUpdated).toLocaleDateString()}</p>
+        </div>` : ''}
+        <div id="item-reactions" class="details-item mb-4 hidden"></div>
+        ${item.moved ? `<div class="details-item"><p class="moved text-sm italic text-gray-500 dark:text-gray-400 mt-2">${movedArrow(item) || '* '}This item has been moved recently${item.previousRing ? ` from ${item.previousRing}` : ''}.</p></div>` : ''}
     `;
+    loadReactions(item.id);
 
     panel.classList.add('open');
     panel.classList.remove('right-[-400px]');
     panel.classList.add('right-0');
 }
 
+/** Shows the reactions to the item in the details panel as buttons toggling those of the user */
+function renderReactions(summary) {
+    const container = document.querySelector(SELECTORS.itemReactions);
+    if (!container) return;
+    container.classList.remove('hidden');
+    container.innerHTML = `
+        <h4 class="font-semibold text-gray-600 dark:text-gray-400 mb-1">Reactions</h4>
+        <p class="flex gap-2"></p>
+    `;
+    const row = container.querySelector('p');
+    Object.entries(REACTION_EMOJI).forEach(([reaction, emoji]) => {
+        const mine = summary.mine.includes(reaction);
+        const button = document.createElement('button');
+        button.className = `text-sm px-2 py-0.5 rounded-full border ${mine ? 'border-blue-500 bg-blue-100 dark:bg-blue-900' : 'border-gray-300 dark:border-gray-600'} hover:bg-gray-100 dark:hover:bg-gray-700`;
+        button.textContent = `${emoji} ${summary.counts[reaction] || 0}`;
+        button.title = reaction;
+        button.setAttribute('aria-pressed', mine);
+        button.addEventListener('click', () => loadReactions(summary.itemId, reaction, mine ? 'DELETE' : 'PUT'));
+        row.appendChild(button);
+    });
+}
+
+/** Loads the reactions to an item, first adding or removing a reaction of the user with method if given */
+function loadReactions(itemId, reaction = '', method = 'GET') {
+    const path = reaction ? `/${encodeURIComponent(reaction)}` : '';
+    fetch(`${BASE_PATH}/api/v1/items/${encodeURIComponent(itemId)}/reactions${path}`, { method })
+        .then(response => {
+            // Reacting requires signing in; without a database store there are no reactions
+            if (response.status === 401) {
+                document.querySelector(SELECTORS.itemReactions)?.setAttribute('title', 'Sign in to react');
+            }
+            return response.ok ? response.json() : null;
+        })
+        .then(summary => {
+            // The panel may show another item by now
+            if (summary && selectedNodeId === itemId) renderReactions(summary);
+        })
+        .catch(error => console.error('Error loading reactions:', error));
+}
+
 /** Closes the details panel */
 function closeDetails() {
     const panel = document.querySelector(SELECTORS.detailsPanel);
@@ -268,6 +410,13 @@ function closeDetails() {
     }
 }
 
+/** Shows the details of the item whose ID is the fragment of the page URL, as linked to by the API */
+function showLinkedItem() {
+    const id = decodeURIComponent(window.location.hash.slice(1));
+    const item = radarData.find(item => item.id === id);
+    if (item && selectedNodeId !== item.id) showDetails(item);
+}
+
 /** Applies filters based on selected quadrant and status */
 function applyFilters() {
     const quadrantFilter = document.querySelector(SELECTORS.quadrantFilter)?.value ?? '';
@@ -287,7 +436,9 @@ function createList(data) {
     const themeColors = getThemeColors();
 
     QUADRANTS.forEach(quadrant => {
-        const quadrantData = data.filter(item => item.quadrant === quadrant);
+        // Innermost rings first; RINGS lists them from the outside in
+        const quadrantData = data.filter(item => item.quadrant === quadrant)
+            .sort((a, b) => RINGS.indexOf(b.ring) - RINGS.indexOf(a.ring));
         if (quadrantData.length === 0) return;
 
         const quadrantDiv = container.append('div')
@@ -314,12 +465,88 @@ function createList(data) {
                         <span class="ring text-sm text-gray-500 dark:text-gray-400 ml-2">(${d.ring})</span>
                     </div>
                     <p class="description text-sm text-gray-600 dark:text-gray-400 mt-1">${d.description || ''}</p>
-                    ${d.moved ? '<p class="moved text-xs italic text-gray-500 dark:text-gray-400 mt-1">Moved</p>' : ''}
+                    ${d.moved ? `<p class="moved text-xs italic text-gray-500 dark:text-gray-400 mt-1">${movedArrow(d)}Moved</p>` : ''}
                 `;
             });
     });
 }
 
+/** Shows the results of a search, with their highlighted snippets */
+function showSearchResults(results) {
+    const list = d3.select(SELECTORS.searchResults);
+    list.selectAll('*').remove();
+    list.classed('hidden', false);
+    if (results.length === 0) {
+        list.append('li')
+            .attr('class', 'p-3 text-sm text-gray-500 dark:text-gray-400')
+            .text('No matching technologies');
+        return;
+    }
+    list.selectAll('.search-result')
+        .data(results, d => d.id)
+        .join('li')
+        .attr('class', 'search-result p-3 cursor-pointer hover:bg-gray-100 dark:hover:bg-gray-700')
+        .on('click', (event, d) => {
+            list.classed('hidden', true);
+            showDetails(radarData.find(item => item.id === d.id) || d);
+        })
+        .html(d => `
+            <div class="flex items-center">
+                <div class="mr-2 w-3 h-3 rounded-full" style="background-color: ${RING_COLORS[d.ring]};"></div>
+                <span class="font-medium text-gray-800 dark:text-gray-200">${d.label}</span>
+                <span class="text-sm text-gray-500 dark:text-gray-400 ml-2">${d.quadrant} &middot; ${d.ring}</span>
+            </div>
+            ${d.snippet ? `<p class="text-sm text-gray-600 dark:text-gray-400 mt-1">${d.snippet}</p>` : ''}
+        `);
+}
+
+/** Completes the labels and tags typed in the search box */
+function updateSuggestions(query) {
+    const datalist = d3.select(SELECTORS.searchSuggestions);
+    if (!query) {
+        datalist.selectAll('*').remove();
+        return;
+    }
+    fetch(`${BASE_PATH}/api/v1/suggest?q=${encodeURIComponent(query)}`)
+        .then(response => response.ok ? response.json() : { suggestions: [] })
+        .then(data => {
+            datalist.selectAll('option')
+                .data(data.suggestions || [])
+                .join('option')
+                .attr('value', d => d.label || d.tag)
+                .attr('label', d => d.type === 'tag' ? `Tag, ${d.count} items` : `${d.quadrant} · ${d.ring}`);
+        })
+        .catch(error => console.error('Error loading suggestions:', error));
+}
+
+/** Searches the radar as the user types in the search box */
+function setupSearch() {
+    const input = document.querySelector(SELECTORS.searchInput);
+    const results = document.querySelector(SELECTORS.searchResults);
+    if (!input || !results) return;
+
+    let latest = 0;
+    input.addEventListener('input', () => updateSuggestions(input.value.trim()));
+    input.addEventListener('input', debounce(() => {
+        const query = input.value.trim();
+        const request = ++latest;
+        if (!query) {
+            results.classList.add('hidden');
+            return;
+        }
+        fetch(`${BASE_PATH}/api/v1/search?q=${encodeURIComponent(query)}`)
+            .then(response => response.ok ? response.json() : { results: [] })
+            .then(data => {
+                // Answers to earlier queries may arrive late
+                if (request === latest) showSearchResults(data.results || []);
+            })
+            .catch(error => console.error('Error searching radar:', error));
+    }, LAYOUT.searchDebounceDelay));
+    document.addEventListener('click', event => {
+        if (!event.target.closest('.search-container')) results.classList.add('hidden');
+    });
+}
+
 // =============================================================================
 // Radar Drawing Functions
 // =============================================================================
@@ -372,6 +599,10 @@ function setupLegend(svg) {
             updateFiltersUI();
         });
 
+    legendItems.filter(d => SEGMENT_DESCRIPTIONS[d[0]])
+        .append('title')
+        .text(d => SEGMENT_DESCRIPTIONS[d[0]]);
+
     legendItems.append('rect')
         .attr('width', legendColorWidth)
         .attr('height', legendColorWidth)
@@ -452,7 +683,7 @@ function drawRadarStructure(radarGroup, radius) {
             .attr('alignment-baseline', 'middle')
             .attr('font-weight', activeFilters.quadrant === quadrant ? 'bolder' : 'bold')
             .attr('font-size', '16px')
-            .attr('fill', themeColors.quadLabel)
+            .attr('fill', QUADRANT_COLORS[quadrant] || themeColors.quadLabel)
             .attr('class', 'quadrant-label')
             .attr('data-quadrant', quadrant)
             .style('cursor', 'pointer')
@@ -473,6 +704,9 @@ function drawRadarStructure(radarGroup, radius) {
         } else {
             textElement.text(quadrant);
         }
+        if (SEGMENT_DESCRIPTIONS[quadrant]) {
+            textElement.append('title').text(SEGMENT_DESCRIPTIONS[quadrant]);
+        }
     });
     
     return { radiusScale, angleSlice, ringLabelPositions };
@@ -501,7 +735,7 @@ function prepareNodesForSimulation(data, radiusScale, angleSlice) {
 
         return {
             ...item,
-            id: `node-${index}`,
+            id: item.id,
             x: targetX,
             y: targetY,
             targetX,
@@ -572,7 +806,7 @@ function drawNodes(radarGroup, nodes, simulation) {
         .attr("fill", themeColors.nodeLabel)
         .style("text-decoration", d => d.moved ? "underline" : "none")
         .style("opacity", getNodeOpacity)
-        .text(d => d.label);
+        .text(d => movedArrow(d) + d.label);
 
     node.attr("transform", d => `translate(${d.x}, ${d.y})`);
     
@@ -647,19 +881,24 @@ function initializeRadar() {
     window.closeDetails = closeDetails;
     window.applyFilters = applyFilters;
     window.resetFilters = resetFilters;
+    setupSearch();
 
     // Fetch data
-    fetch('/api/radar')
+    // The page URL may filter the radar with the parameters of the API
+    fetch(`${BASE_PATH}/api/v1/radar${window.location.search}`)
         .then(response => {
             if (!response.ok) throw new Error(`HTTP error! status: ${response.status}`);
             return response.json();
         })
         .then(data => {
+            applySegments(data);
             radarData = data.items || [];
             lastModified = data.lastModified || "";
 
             drawRadar(radarData);
             createList(radarData);
+            showLinkedItem();
+            window.addEventListener('hashchange', showLinkedItem);
 
             // Handle window resize
             window.addEventListener('resize', debounce(() => drawRadar(radarData), LAYOUT.resizeDebounceDelay));
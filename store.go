@@ -0,0 +1,509 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"reflect"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// errReadOnly is returned by Store.Save when the data source can't be
+// written to.
+var errReadOnly = errors.New("radar data source is read-only")
+
+// Store is where the radar data is loaded from and saved to: local data
+// files, a URL, a Git checkout or a database, selected by the configuration.
+type Store interface {
+	// Load returns the current radar data.
+	Load(ctx context.Context) (RadarData, error)
+	// Save replaces the radar data, or returns errReadOnly. Databases
+	// record the audit event carried by ctx, see withAuditEvent.
+	Save(ctx context.Context, data RadarData) error
+	// Watch returns a channel that receives a value whenever the data may
+	// have changed. It is closed when ctx is done or the store is closed.
+	Watch(ctx context.Context) <-chan struct{}
+	// Close stops any background work of the store.
+	Close() error
+}
+
+// activeStore is the Store of the active configuration.
+var activeStore atomic.Pointer[Store]
+
+// currentStore returns the Store of the active configuration, or nil before
+// one is started.
+func currentStore() Store {
+	if s := activeStore.Load(); s != nil {
+		return *s
+	}
+	return nil
+}
+
+// startStore swaps in the Store for the data source of cfg and closes the
+// previous one, unless the source is unchanged. A database is opened once at
+// startup and kept; local data files are re-read on every call, since a
+// reload may follow an edit.
+func startStore(cfg Config) {
+	old := currentStore()
+	var store Store
+	switch {
+	case cfg.Store.enabled():
+		return
+	case cfg.Data.Git.enabled():
+		if g, ok := old.(*gitStore); ok && g.cfg == cfg.Data {
+			return
+		}
+		store = newGitStore(cfg.Data)
+	case isRemoteDataPath(cfg.Data.Path):
+		r, _ := old.(*remoteStore)
+		if r != nil && r.url == cfg.Data.Path && r.interval == cfg.Data.PollInterval {
+			return
+		}
+		store = newRemoteStore(cfg.Data, r)
+	default:
+		store = newFileStore(cfg.Data.Path)
+	}
+	setStore(store)
+	if old != nil {
+		old.Close()
+	}
+}
+
+// setStore makes store the active Store and invalidates the cached radar
+// data whenever it changes.
+func setStore(store Store) {
+	activeStore.Store(&store)
+	go func() {
+		for range store.Watch(context.Background()) {
+			invalidateRadarCache(context.Background())
+		}
+	}()
+}
+
+// changeNotifier implements Watch for a Store.
+type changeNotifier struct {
+	mu       sync.Mutex
+	watchers map[chan struct{}]bool
+	closed   bool
+}
+
+// watch returns a channel notified of changes until ctx is done or the
+// notifier is closed.
+func (n *changeNotifier) watch(ctx context.Context) <-chan struct{} {
+	ch := make(chan struct{}, 1)
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	if n.closed {
+		close(ch)
+		return ch
+	}
+	if n.watchers == nil {
+		n.watchers = make(map[chan struct{}]bool)
+	}
+	n.watchers[ch] = true
+	context.AfterFunc(ctx, func() {
+		n.mu.Lock()
+		defer n.mu.Unlock()
+		if n.watchers[ch] {
+			delete(n.watchers, ch)
+			close(ch)
+		}
+	})
+	return ch
+}
+
+// notify tells every watcher about a change without waiting for them; a
+// watcher that hasn't received the previous notification gets just one.
+func (n *changeNotifier) notify() {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	for ch := range n.watchers {
+		select {
+		case ch <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// close closes every watcher's channel.
+func (n *changeNotifier) close() {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	for ch := range n.watchers {
+		close(ch)
+	}
+	n.watchers, n.closed = nil, true
+}
+
+// auditEventKey is the context key of the audit event recorded by a save.
+type auditEventKey struct{}
+
+// withAuditEvent returns a context whose saves to a database are recorded
+// as event.
+func withAuditEvent(ctx context.Context, event AuditEvent) context.Context {
+	return context.WithValue(ctx, auditEventKey{}, event)
+}
+
+// auditEventOf returns the audit event ctx carries, see withAuditEvent, or
+// a save by the system.
+func auditEventOf(ctx context.Context) AuditEvent {
+	event, ok := ctx.Value(auditEventKey{}).(AuditEvent)
+	if !ok {
+		event = AuditEvent{Actor: "system", Action: "save"}
+	}
+	return event
+}
+
+// restoreKey is the context key marking a save as a restore, see
+// withRestore.
+type restoreKey struct{}
+
+// withRestore returns a context whose saves put back the data of a backup
+// as it is: they don't keep the current quadrants and rings, restrict ring
+// moves or stamp LastUpdated.
+func withRestore(ctx context.Context) context.Context {
+	return context.WithValue(ctx, restoreKey{}, true)
+}
+
+// isRestore reports whether ctx was returned by withRestore.
+func isRestore(ctx context.Context) bool {
+	restore, _ := ctx.Value(restoreKey{}).(bool)
+	return restore
+}
+
+// prepareSave returns data as a Store saves it over current: with the
+// quadrants and rings of current unless data declares its own, checked like
+// a data file, with the IDs of items whose label is unchanged, its relations
+// checked against them, and with their ring moves checked and LastUpdated
+// stamped, except for a restore.
+func prepareSave(ctx context.Context, data, current RadarData) (RadarData, error) {
+	restore := isRestore(ctx)
+	if !restore {
+		data = keepSegments(data, current)
+	}
+	if err := validateSavedData(data); err != nil {
+		return RadarData{}, err
+	}
+	data, err := withItemIDs(data, current.Items)
+	if err != nil {
+		return RadarData{}, err
+	}
+	if errs := checkRelations(data.Items); len(errs) > 0 {
+		return RadarData{}, errs
+	}
+	if !restore {
+		if err := checkRingMoves(data, current.Items, auditEventOf(ctx).Justification); err != nil {
+			return RadarData{}, err
+		}
+		data = stampMoved(data, current)
+		data = stampLastUpdated(data, current.Items, time.Now().UTC().Truncate(time.Second))
+	}
+	return data, nil
+}
+
+// databaseStore is the Store of a Database.
+type databaseStore struct {
+	db      Database
+	changes changeNotifier
+}
+
+// Load gives items saved before they had IDs the slug of their label.
+func (s *databaseStore) Load(ctx context.Context) (RadarData, error) {
+	data, err := s.db.Load(ctx)
+	if err != nil {
+		return RadarData{}, err
+	}
+	return withItemIDs(data, nil)
+}
+
+func (s *databaseStore) Save(ctx context.Context, data RadarData) error {
+	event := auditEventOf(ctx)
+	current, err := s.Load(ctx)
+	if err != nil {
+		return err
+	}
+	data, err = prepareSave(ctx, data, current)
+	if err != nil {
+		return err
+	}
+	if err := s.db.Save(ctx, data, event); err != nil {
+		return err
+	}
+	s.changes.notify()
+	return nil
+}
+
+// Watch only reports saves made through this instance; other instances
+// sharing the database invalidate the Redis cache themselves.
+func (s *databaseStore) Watch(ctx context.Context) <-chan struct{} {
+	return s.changes.watch(ctx)
+}
+
+func (s *databaseStore) Close() error {
+	s.changes.close()
+	return s.db.Close()
+}
+
+// Database persists radar data in a database. Every Save records a snapshot
+// of the new data, an edit for each item it changed and an audit event, so
+// changes can be traced.
+type Database interface {
+	// Load returns the current radar data.
+	Load(ctx context.Context) (RadarData, error)
+	// Save replaces the radar data and records event as the reason.
+	Save(ctx context.Context, data RadarData, event AuditEvent) error
+	// Snapshots, Edits and AuditEvents return up to limit records, newest
+	// first.
+	Snapshots(ctx context.Context, limit int) ([]Snapshot, error)
+	// Snapshot returns the snapshot with the ID id, and false if there is
+	// none.
+	Snapshot(ctx context.Context, id int64) (Snapshot, bool, error)
+	Edits(ctx context.Context, limit int) ([]ItemEdit, error)
+	AuditEvents(ctx context.Context, limit int) ([]AuditEvent, error)
+	// AuditLog returns the audit events filter selects, newest first,
+	// skipping offset of them and up to limit, each with the edits of its
+	// save, and the number of events selected.
+	AuditLog(ctx context.Context, filter auditFilter, limit, offset int) ([]AuditEntry, int, error)
+	// SaveProposal adds proposal if its ID is zero, and otherwise replaces
+	// the proposal with its ID. It returns the proposal as saved.
+	SaveProposal(ctx context.Context, proposal Proposal) (Proposal, error)
+	// Proposals returns every proposal, oldest first.
+	Proposals(ctx context.Context) ([]Proposal, error)
+	// SaveComment adds comment if its ID is zero, and otherwise replaces the
+	// comment with its ID. It returns the comment as saved.
+	SaveComment(ctx context.Context, comment Comment) (Comment, error)
+	// Comments returns the comments on the item with the ID itemID, or on
+	// every item if itemID is empty, oldest first.
+	Comments(ctx context.Context, itemID string) ([]Comment, error)
+	// SaveVote adds vote unless its voter already voted for its item, and
+	// reports whether it did. DeleteVote removes the vote of voter for the
+	// item with the ID itemID, and reports whether there was one.
+	SaveVote(ctx context.Context, vote Vote) (bool, error)
+	DeleteVote(ctx context.Context, itemID, voter string) (bool, error)
+	// Votes returns every vote.
+	Votes(ctx context.Context) ([]Vote, error)
+	// SaveReaction adds reaction unless its user already reacted so to its
+	// item, and DeleteReaction removes it; both report whether they did.
+	SaveReaction(ctx context.Context, reaction Reaction) (bool, error)
+	DeleteReaction(ctx context.Context, reaction Reaction) (bool, error)
+	// Reactions returns the reactions to the item with the ID itemID.
+	Reactions(ctx context.Context, itemID string) ([]Reaction, error)
+	Close() error
+}
+
+// Snapshot is the radar data as saved at one point in time.
+type Snapshot struct {
+	ID   int64     `json:"id"`
+	Time time.Time `json:"time"`
+	Data RadarData `json:"data"`
+}
+
+// Item edit actions.
+const (
+	editAdded   = "added"
+	editUpdated = "updated"
+	editRemoved = "removed"
+)
+
+// ItemEdit is a change to one item made by a save.
+type ItemEdit struct {
+	SnapshotID int64      `json:"snapshotId"`
+	Label      string     `json:"label"`
+	Action     string     `json:"action"`
+	Before     *RadarItem `json:"before,omitempty"`
+	After      *RadarItem `json:"after,omitempty"`
+}
+
+// AuditEvent records who changed the radar data and why: the Actor is the
+// principal the change was made as, such as "admin" for the admin token,
+// and SourceIP the address of the client that made it.
+type AuditEvent struct {
+	Time       time.Time `json:"time"`
+	Actor      string    `json:"actor"`
+	Action     string    `json:"action"`
+	Detail     string    `json:"detail,omitempty"`
+	SourceIP   string    `json:"sourceIp,omitempty"`
+	SnapshotID int64     `json:"snapshotId,omitempty"`
+	// Justification is why the save was made, such as why an item moved to
+	// a ring that requires one.
+	Justification string `json:"justification,omitempty"`
+}
+
+// storeDrivers opens a Database for each supported store.driver.
+var storeDrivers = map[string]func(ctx context.Context, cfg StoreConfig) (Database, error){
+	"bbolt":    openBoltStore,
+	"postgres": openPostgresStore,
+	"sqlite":   openSQLiteStore,
+}
+
+// storeDriverNames returns the supported store drivers in sorted order.
+func storeDriverNames() []string {
+	names := make([]string, 0, len(storeDrivers))
+	for name := range storeDrivers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// openStore opens the configured store, applying pending migrations, and
+// seeds it from the data files if it has never been saved to.
+func openStore(ctx context.Context, cfg Config) (Database, error) {
+	store, err := storeDrivers[cfg.Store.Driver](ctx, cfg.Store)
+	if err != nil {
+		return nil, err
+	}
+	if err := seedStore(ctx, store, cfg.Data.dataPath()); err != nil {
+		store.Close()
+		return nil, err
+	}
+	return store, nil
+}
+
+// seedStore copies the radar data at path into store if it is empty, so
+// switching to a store keeps the current radar.
+func seedStore(ctx context.Context, store Database, path string) error {
+	snapshots, err := store.Snapshots(ctx, 1)
+	if err != nil || len(snapshots) > 0 {
+		return err
+	}
+	n, err := importToStore(ctx, store, path, AuditEvent{Actor: "system", Action: "seed"})
+	if err != nil {
+		return fmt.Errorf("seeding store: %w", err)
+	}
+	log.Printf("Seeded store with %d items from %s", n, dataPathName(path))
+	return nil
+}
+
+// importToStore validates the radar data at path and saves it to store,
+// recording event with the data path as its detail. It returns the number
+// of items saved.
+func importToStore(ctx context.Context, store Database, path string, event AuditEvent) (int, error) {
+	name := dataPathName(path)
+	if err := validateRadarData(path); err != nil {
+		return 0, fmt.Errorf("%s: %w", name, err)
+	}
+	data, err := readSeedData(ctx, path)
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", name, err)
+	}
+	current, err := store.Load(ctx)
+	if err != nil {
+		return 0, err
+	}
+	data, err = withItemIDs(data, current.Items)
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", name, err)
+	}
+	event.Detail = "from " + name
+	if err := store.Save(ctx, data, event); err != nil {
+		return 0, err
+	}
+	return len(data.Items), nil
+}
+
+// dataPathName returns path as it can be logged, without URL credentials.
+func dataPathName(path string) string {
+	if isRemoteDataPath(path) {
+		return remoteDataName(path)
+	}
+	return path
+}
+
+// runImport implements the import command, which replaces the contents of
+// the configured store with the radar data files, for example to move an
+// existing radar into a store that was already seeded. It accepts the same
+// flags and environment variables as the server.
+func runImport(args []string) error {
+	cfg, err := loadConfig(args)
+	if err != nil {
+		return err
+	}
+	if !cfg.Store.enabled() {
+		return errors.New("import requires store.driver to be set")
+	}
+	if err := useEncryptionKey(cfg); err != nil {
+		return err
+	}
+	if err := prepareGitData(cfg.Data); err != nil {
+		return err
+	}
+
+	ctx := context.Background()
+	store, err := storeDrivers[cfg.Store.Driver](ctx, cfg.Store)
+	if err != nil {
+		return err
+	}
+	defer store.Close()
+	n, err := importToStore(ctx, store, cfg.Data.dataPath(), AuditEvent{Actor: "cli", Action: "import"})
+	if err != nil {
+		return err
+	}
+	// Running servers sharing the cache pick up the import right away.
+	if cfg.Cache.enabled() {
+		cache, err := openRadarCache(cfg.Cache)
+		if err != nil {
+			return err
+		}
+		cache.invalidate(ctx)
+		cache.Close()
+	}
+	log.Printf("Imported %d items from %s into the %s store", n, dataPathName(cfg.Data.dataPath()), cfg.Store.Driver)
+	return nil
+}
+
+// readSeedData reads the radar data at a local path or URL.
+func readSeedData(ctx context.Context, path string) (RadarData, error) {
+	if !isRemoteDataPath(path) {
+		return readRadarData(path)
+	}
+	content, _, err := fetchRemoteData(ctx, path, "")
+	if err != nil {
+		return RadarData{}, err
+	}
+	return decodeRadarData(remoteDataName(path), content)
+}
+
+// storedData returns a copy of data as a store saves it, without the data
+// file each item was read from.
+func storedData(data RadarData) RadarData {
+	items := make([]RadarItem, len(data.Items))
+	for i, item := range data.Items {
+		item.Source = ""
+		items[i] = item
+	}
+	data.Items = items
+	return data
+}
+
+// diffItems lists the edits that turn the items in old into those in new,
+// matching items by label.
+func diffItems(old, new []RadarItem) []ItemEdit {
+	before := make(map[string]RadarItem, len(old))
+	for _, item := range old {
+		before[labelKey(item.Label)] = item
+	}
+
+	var edits []ItemEdit
+	for _, item := range new {
+		key := labelKey(item.Label)
+		prev, ok := before[key]
+		delete(before, key)
+		switch {
+		case !ok:
+			edits = append(edits, ItemEdit{Label: item.Label, Action: editAdded, After: &item})
+		case !reflect.DeepEqual(prev, item):
+			edits = append(edits, ItemEdit{Label: item.Label, Action: editUpdated, Before: &prev, After: &item})
+		}
+	}
+	for _, item := range old {
+		if prev, ok := before[labelKey(item.Label)]; ok {
+			edits = append(edits, ItemEdit{Label: prev.Label, Action: editRemoved, Before: &prev})
+		}
+	}
+	return edits
+}
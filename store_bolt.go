@@ -0,0 +1,493 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"math"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+// Buckets of a bbolt store. The current radar data is kept under
+// boltCurrentKey in boltMetaBucket; every other bucket holds JSON records
+// keyed by their big-endian sequence number, so cursors walk them in order,
+// but votes and reactions, which are keyed by their item and user, see
+// boltVoteKey and boltReactionKey. With an encryption key, every value is
+// encrypted by sealData.
+var (
+	boltMetaBucket      = []byte("meta")
+	boltSnapshotsBucket = []byte("snapshots")
+	boltEditsBucket     = []byte("edits")
+	boltAuditBucket     = []byte("audit_events")
+	boltProposalsBucket = []byte("proposals")
+	boltCommentsBucket  = []byte("comments")
+	boltVotesBucket     = []byte("votes")
+	boltReactionsBucket = []byte("reactions")
+	boltCurrentKey      = []byte("current")
+)
+
+// boltStore is a Database in a bbolt database file. It needs neither a
+// database server nor SQL, but the file can only be opened by one process.
+type boltStore struct {
+	db *bolt.DB
+}
+
+// openBoltStore opens the bbolt database file at cfg.DSN, creating it and
+// its buckets if needed.
+func openBoltStore(ctx context.Context, cfg StoreConfig) (Database, error) {
+	// The file is locked while open; give up rather than wait forever for
+	// another instance to release it.
+	db, err := bolt.Open(cfg.DSN, 0o600, &bolt.Options{Timeout: 5 * time.Second})
+	if err != nil {
+		return nil, err
+	}
+	err = db.Update(func(tx *bolt.Tx) error {
+		for _, name := range [][]byte{boltMetaBucket, boltSnapshotsBucket, boltEditsBucket, boltAuditBucket, boltProposalsBucket, boltCommentsBucket, boltVotesBucket, boltReactionsBucket} {
+			if _, err := tx.CreateBucketIfNotExists(name); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+	return &boltStore{db: db}, nil
+}
+
+// boltKey encodes a sequence number as a bucket key.
+func boltKey(id uint64) []byte {
+	return binary.BigEndian.AppendUint64(nil, id)
+}
+
+// boltValue encodes v as a stored value.
+func boltValue(v any) ([]byte, error) {
+	value, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	return sealData(value)
+}
+
+// boltDecode decodes a stored value into v.
+func boltDecode(value []byte, v any) error {
+	value, err := openData(value)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(value, v)
+}
+
+// boltPut stores v as JSON under the next sequence number of bucket and
+// returns that number.
+func boltPut(bucket *bolt.Bucket, v any) (int64, error) {
+	id, err := bucket.NextSequence()
+	if err != nil {
+		return 0, err
+	}
+	value, err := boltValue(v)
+	if err != nil {
+		return 0, err
+	}
+	return int64(id), bucket.Put(boltKey(id), value)
+}
+
+// boltNewest decodes up to limit records of bucket, newest first, calling
+// add with each one's sequence number and value.
+func boltNewest(db *bolt.DB, bucket []byte, limit int, add func(id int64, value []byte) error) error {
+	return db.View(func(tx *bolt.Tx) error {
+		c := tx.Bucket(bucket).Cursor()
+		for k, v := c.Last(); k != nil && limit > 0; k, v = c.Prev() {
+			if err := add(int64(binary.BigEndian.Uint64(k)), v); err != nil {
+				return err
+			}
+			limit--
+		}
+		return nil
+	})
+}
+
+func (s *boltStore) Load(ctx context.Context) (RadarData, error) {
+	var data RadarData
+	err := s.db.View(func(tx *bolt.Tx) error {
+		return boltCurrent(tx, &data)
+	})
+	return data, err
+}
+
+// boltCurrent decodes the current radar data, leaving data empty if nothing
+// was saved yet.
+func boltCurrent(tx *bolt.Tx, data *RadarData) error {
+	value := tx.Bucket(boltMetaBucket).Get(boltCurrentKey)
+	if value == nil {
+		return nil
+	}
+	return boltDecode(value, data)
+}
+
+func (s *boltStore) Save(ctx context.Context, data RadarData, event AuditEvent) error {
+	data = storedData(data)
+	if event.Time.IsZero() {
+		event.Time = time.Now()
+	}
+	current, err := boltValue(data)
+	if err != nil {
+		return err
+	}
+
+	return s.db.Update(func(tx *bolt.Tx) error {
+		var old RadarData
+		if err := boltCurrent(tx, &old); err != nil {
+			return err
+		}
+		if err := tx.Bucket(boltMetaBucket).Put(boltCurrentKey, current); err != nil {
+			return err
+		}
+		snapshotID, err := boltPut(tx.Bucket(boltSnapshotsBucket), Snapshot{Time: event.Time, Data: data})
+		if err != nil {
+			return err
+		}
+		for _, edit := range diffItems(old.Items, data.Items) {
+			edit.SnapshotID = snapshotID
+			if _, err := boltPut(tx.Bucket(boltEditsBucket), edit); err != nil {
+				return err
+			}
+		}
+		event.SnapshotID = snapshotID
+		_, err = boltPut(tx.Bucket(boltAuditBucket), event)
+		return err
+	})
+}
+
+func (s *boltStore) Snapshots(ctx context.Context, limit int) ([]Snapshot, error) {
+	var snapshots []Snapshot
+	err := boltNewest(s.db, boltSnapshotsBucket, limit, func(id int64, value []byte) error {
+		var snapshot Snapshot
+		if err := boltDecode(value, &snapshot); err != nil {
+			return err
+		}
+		snapshot.ID = id
+		snapshots = append(snapshots, snapshot)
+		return nil
+	})
+	return snapshots, err
+}
+
+func (s *boltStore) Snapshot(ctx context.Context, id int64) (Snapshot, bool, error) {
+	var snapshot Snapshot
+	found := false
+	err := s.db.View(func(tx *bolt.Tx) error {
+		value := tx.Bucket(boltSnapshotsBucket).Get(boltKey(uint64(id)))
+		if value == nil {
+			return nil
+		}
+		found = true
+		return boltDecode(value, &snapshot)
+	})
+	snapshot.ID = id
+	return snapshot, found && err == nil, err
+}
+
+func (s *boltStore) Edits(ctx context.Context, limit int) ([]ItemEdit, error) {
+	var edits []ItemEdit
+	err := boltNewest(s.db, boltEditsBucket, limit, func(_ int64, value []byte) error {
+		var edit ItemEdit
+		if err := boltDecode(value, &edit); err != nil {
+			return err
+		}
+		edits = append(edits, edit)
+		return nil
+	})
+	return edits, err
+}
+
+func (s *boltStore) AuditEvents(ctx context.Context, limit int) ([]AuditEvent, error) {
+	var events []AuditEvent
+	err := boltNewest(s.db, boltAuditBucket, limit, func(_ int64, value []byte) error {
+		var event AuditEvent
+		if err := boltDecode(value, &event); err != nil {
+			return err
+		}
+		events = append(events, event)
+		return nil
+	})
+	return events, err
+}
+
+// AuditLog scans the audit events, newest first, without their edits, and
+// the edits of the events on the page. Filtering by item scans the edits.
+func (s *boltStore) AuditLog(ctx context.Context, filter auditFilter, limit, offset int) ([]AuditEntry, int, error) {
+	var events []AuditEvent
+	var edits []ItemEdit
+	total := 0
+	err := s.db.View(func(tx *bolt.Tx) error {
+		var changed map[int64]bool
+		if filter.item != "" {
+			changed = make(map[int64]bool)
+			if err := tx.Bucket(boltEditsBucket).ForEach(func(_, v []byte) error {
+				var edit ItemEdit
+				if err := boltDecode(v, &edit); err != nil {
+					return err
+				}
+				if edit.itemID() == filter.item {
+					changed[edit.SnapshotID] = true
+				}
+				return nil
+			}); err != nil {
+				return err
+			}
+		}
+		c := tx.Bucket(boltAuditBucket).Cursor()
+		for k, v := c.Last(); k != nil; k, v = c.Prev() {
+			var event AuditEvent
+			if err := boltDecode(v, &event); err != nil {
+				return err
+			}
+			if !filter.matchesEvent(event) || changed != nil && !changed[event.SnapshotID] {
+				continue
+			}
+			if total >= offset && total < offset+limit {
+				events = append(events, event)
+			}
+			total++
+		}
+
+		// Edits are added with their snapshots, so those of the page are
+		// among the newest, down to its oldest snapshot.
+		page := make(map[int64]bool)
+		oldest := int64(math.MaxInt64)
+		for _, event := range events {
+			if event.SnapshotID != 0 {
+				page[event.SnapshotID] = true
+				oldest = min(oldest, event.SnapshotID)
+			}
+		}
+		c = tx.Bucket(boltEditsBucket).Cursor()
+		for k, v := c.Last(); k != nil && len(page) > 0; k, v = c.Prev() {
+			var edit ItemEdit
+			if err := boltDecode(v, &edit); err != nil {
+				return err
+			}
+			if edit.SnapshotID < oldest {
+				break
+			}
+			if page[edit.SnapshotID] {
+				edits = append(edits, edit)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+	return withChanges(events, edits), total, nil
+}
+
+// boltProposal is a Proposal as stored, with the hash of its token.
+type boltProposal struct {
+	Proposal
+	TokenHash string `json:"tokenHash"`
+}
+
+func (s *boltStore) SaveProposal(ctx context.Context, proposal Proposal) (Proposal, error) {
+	err := s.db.Update(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(boltProposalsBucket)
+		stored := boltProposal{proposal, proposal.TokenHash}
+		if proposal.ID == 0 {
+			id, err := boltPut(bucket, stored)
+			proposal.ID = id
+			return err
+		}
+		key := boltKey(uint64(proposal.ID))
+		if bucket.Get(key) == nil {
+			return fmt.Errorf("no proposal %d", proposal.ID)
+		}
+		value, err := boltValue(stored)
+		if err != nil {
+			return err
+		}
+		return bucket.Put(key, value)
+	})
+	return proposal, err
+}
+
+func (s *boltStore) Proposals(ctx context.Context) ([]Proposal, error) {
+	var proposals []Proposal
+	err := s.db.View(func(tx *bolt.Tx) error {
+		return tx.Bucket(boltProposalsBucket).ForEach(func(k, v []byte) error {
+			var stored boltProposal
+			if err := boltDecode(v, &stored); err != nil {
+				return err
+			}
+			stored.ID, stored.Proposal.TokenHash = int64(binary.BigEndian.Uint64(k)), stored.TokenHash
+			proposals = append(proposals, stored.Proposal)
+			return nil
+		})
+	})
+	return proposals, err
+}
+
+// boltComment is a Comment as stored, with the hash of its token.
+type boltComment struct {
+	Comment
+	TokenHash string `json:"tokenHash"`
+}
+
+func (s *boltStore) SaveComment(ctx context.Context, comment Comment) (Comment, error) {
+	err := s.db.Update(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(boltCommentsBucket)
+		stored := boltComment{comment, comment.TokenHash}
+		if comment.ID == 0 {
+			id, err := boltPut(bucket, stored)
+			comment.ID = id
+			return err
+		}
+		key := boltKey(uint64(comment.ID))
+		if bucket.Get(key) == nil {
+			return fmt.Errorf("no comment %d", comment.ID)
+		}
+		value, err := boltValue(stored)
+		if err != nil {
+			return err
+		}
+		return bucket.Put(key, value)
+	})
+	return comment, err
+}
+
+// Comments walks the comments on every item, as bbolt has no index.
+func (s *boltStore) Comments(ctx context.Context, itemID string) ([]Comment, error) {
+	var comments []Comment
+	err := s.db.View(func(tx *bolt.Tx) error {
+		return tx.Bucket(boltCommentsBucket).ForEach(func(k, v []byte) error {
+			var stored boltComment
+			if err := boltDecode(v, &stored); err != nil {
+				return err
+			}
+			if itemID == "" || stored.ItemID == itemID {
+				stored.ID, stored.Comment.TokenHash = int64(binary.BigEndian.Uint64(k)), stored.TokenHash
+				comments = append(comments, stored.Comment)
+			}
+			return nil
+		})
+	})
+	return comments, err
+}
+
+// boltVoteKey returns the key of the vote of voter for the item with the ID
+// itemID. Item IDs are slugs, so they can't contain the separator.
+func boltVoteKey(itemID, voter string) []byte {
+	return []byte(itemID + "\x00" + voter)
+}
+
+func (s *boltStore) SaveVote(ctx context.Context, vote Vote) (bool, error) {
+	added := false
+	err := s.db.Update(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(boltVotesBucket)
+		key := boltVoteKey(vote.ItemID, vote.Voter)
+		if bucket.Get(key) != nil {
+			return nil
+		}
+		value, err := boltValue(vote)
+		if err != nil {
+			return err
+		}
+		added = true
+		return bucket.Put(key, value)
+	})
+	return added, err
+}
+
+func (s *boltStore) DeleteVote(ctx context.Context, itemID, voter string) (bool, error) {
+	deleted := false
+	err := s.db.Update(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(boltVotesBucket)
+		key := boltVoteKey(itemID, voter)
+		if bucket.Get(key) == nil {
+			return nil
+		}
+		deleted = true
+		return bucket.Delete(key)
+	})
+	return deleted, err
+}
+
+func (s *boltStore) Votes(ctx context.Context) ([]Vote, error) {
+	var votes []Vote
+	err := s.db.View(func(tx *bolt.Tx) error {
+		return tx.Bucket(boltVotesBucket).ForEach(func(k, v []byte) error {
+			var vote Vote
+			if err := boltDecode(v, &vote); err != nil {
+				return err
+			}
+			votes = append(votes, vote)
+			return nil
+		})
+	})
+	return votes, err
+}
+
+// boltReactionKey returns the key of reaction, which begins with the ID of
+// its item and the separator of boltVoteKey, so the reactions to an item
+// are next to each other.
+func boltReactionKey(reaction Reaction) []byte {
+	return []byte(reaction.ItemID + "\x00" + reaction.Reaction + "\x00" + reaction.User)
+}
+
+func (s *boltStore) SaveReaction(ctx context.Context, reaction Reaction) (bool, error) {
+	added := false
+	err := s.db.Update(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(boltReactionsBucket)
+		key := boltReactionKey(reaction)
+		if bucket.Get(key) != nil {
+			return nil
+		}
+		value, err := boltValue(reaction)
+		if err != nil {
+			return err
+		}
+		added = true
+		return bucket.Put(key, value)
+	})
+	return added, err
+}
+
+func (s *boltStore) DeleteReaction(ctx context.Context, reaction Reaction) (bool, error) {
+	deleted := false
+	err := s.db.Update(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(boltReactionsBucket)
+		key := boltReactionKey(reaction)
+		if bucket.Get(key) == nil {
+			return nil
+		}
+		deleted = true
+		return bucket.Delete(key)
+	})
+	return deleted, err
+}
+
+func (s *boltStore) Reactions(ctx context.Context, itemID string) ([]Reaction, error) {
+	var reactions []Reaction
+	prefix := []byte(itemID + "\x00")
+	err := s.db.View(func(tx *bolt.Tx) error {
+		c := tx.Bucket(boltReactionsBucket).Cursor()
+		for k, v := c.Seek(prefix); k != nil && bytes.HasPrefix(k, prefix); k, v = c.Next() {
+			var reaction Reaction
+			if err := boltDecode(v, &reaction); err != nil {
+				return err
+			}
+			reactions = append(reactions, reaction)
+		}
+		return nil
+	})
+	return reactions, err
+}
+
+func (s *boltStore) Close() error {
+	return s.db.Close()
+}
@@ -0,0 +1,183 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// fileStore is the Store of local data files. It keeps the parsed radar data
+// in memory and drops it when a file in one of the watched directories
+// changes, so the files are only read again after they were edited. If the
+// files can't be watched, they are read on every Load.
+type fileStore struct {
+	path    string
+	watcher *fsnotify.Watcher
+	changes changeNotifier
+
+	mu   sync.RWMutex
+	data *RadarData
+}
+
+// newFileStore returns the Store of the data files at path.
+func newFileStore(path string) *fileStore {
+	s := &fileStore{path: path}
+	watcher, err := watchDataFiles(path)
+	if err != nil {
+		slog.Warn("Failed to watch radar data files, reading them on every request", "path", path, "err", err)
+		return s
+	}
+	s.watcher = watcher
+	go s.watch()
+	return s
+}
+
+// watchDataFiles watches the directories of the data files at path. Whole
+// directories are watched so that editors and tools replacing a file by
+// renaming a new one over it are noticed too.
+func watchDataFiles(path string) (*fsnotify.Watcher, error) {
+	files, err := dataFiles(path)
+	if err != nil {
+		return nil, err
+	}
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, err
+	}
+	dirs := map[string]bool{}
+	for _, file := range files {
+		dirs[filepath.Dir(file)] = true
+	}
+	if info, err := os.Stat(path); err == nil && info.IsDir() {
+		dirs[path] = true
+	}
+	for dir := range dirs {
+		if err := watcher.Add(dir); err != nil {
+			watcher.Close()
+			return nil, err
+		}
+	}
+	return watcher, nil
+}
+
+// watch drops the cached data on every change until the watcher is closed.
+func (s *fileStore) watch() {
+	for {
+		select {
+		case event, ok := <-s.watcher.Events:
+			if !ok {
+				return
+			}
+			if event.Op != fsnotify.Chmod {
+				s.drop()
+			}
+		case err, ok := <-s.watcher.Errors:
+			if !ok {
+				return
+			}
+			// Events may have been lost, so the cache can't be trusted.
+			slog.Warn("Watching radar data files failed", "path", s.path, "err", err)
+			s.drop()
+		}
+	}
+}
+
+// drop forgets the cached data. Only the first change after the data was
+// read notifies watchers; the events that usually follow for the same edit
+// don't.
+func (s *fileStore) drop() {
+	s.mu.Lock()
+	cached := s.data != nil
+	s.data = nil
+	s.mu.Unlock()
+	if cached {
+		s.changes.notify()
+	}
+}
+
+// Load returns the cached data, reading the files if they changed.
+func (s *fileStore) Load(ctx context.Context) (RadarData, error) {
+	if s.watcher == nil {
+		return readRadarData(s.path)
+	}
+	s.mu.RLock()
+	if s.data != nil {
+		defer s.mu.RUnlock()
+		return *s.data, nil
+	}
+	s.mu.RUnlock()
+
+	// Reading under the write lock makes a change during the read drop the
+	// new copy rather than be lost.
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.data != nil {
+		return *s.data, nil
+	}
+	data, err := readRadarData(s.path)
+	if err != nil {
+		return RadarData{}, err
+	}
+	// Once per change, not on every request.
+	logDuplicates(data.Items)
+	s.data = &data
+	return data, nil
+}
+
+// Save writes data to the data file, keeping the IDs of items whose label
+// is unchanged, and the quadrants and rings of the file unless data
+// declares its own. A directory or glob of files can't be written to. With
+// data.commit, the file is then committed as the audit event ctx carries.
+func (s *fileStore) Save(ctx context.Context, data RadarData) error {
+	if !isSingleDataFile(s.path) {
+		return errReadOnly
+	}
+	commit := currentConfig().Data.Commit.Enabled
+	if commit {
+		if err := checkGitWorkTree(ctx, s.path); err != nil {
+			return err
+		}
+	}
+	// A missing or invalid file has no IDs to keep.
+	current, _ := s.Load(ctx)
+	data, err := prepareSave(ctx, data, current)
+	if err != nil {
+		return err
+	}
+	if err := writeRadarData(s.path, data); err != nil {
+		return err
+	}
+	s.invalidate()
+	if commit {
+		if err := commitDataFile(ctx, s.path, auditEventOf(ctx)); err != nil {
+			return fmt.Errorf("committing %s: %w", s.path, err)
+		}
+	}
+	return nil
+}
+
+// invalidate forgets the cached data and notifies watchers after the files
+// were changed through the store.
+func (s *fileStore) invalidate() {
+	s.mu.Lock()
+	s.data = nil
+	s.mu.Unlock()
+	s.changes.notify()
+}
+
+func (s *fileStore) Watch(ctx context.Context) <-chan struct{} {
+	return s.changes.watch(ctx)
+}
+
+func (s *fileStore) Close() error {
+	s.changes.close()
+	if s.watcher != nil {
+		return s.watcher.Close()
+	}
+	return nil
+}
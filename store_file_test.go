@@ -0,0 +1,149 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// waitForItems polls store until it returns n items, the first one in ring.
+func waitForItems(t *testing.T, store Store, n int, ring string) {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		data, err := store.Load(context.Background())
+		if err == nil && len(data.Items) == n && data.Items[0].Ring == ring {
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("Load() = %+v, %v, want %d items, the first in ring %q", data, err, n, ring)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestFileStoreReloadsChangedFiles(t *testing.T) {
+	path := writeFile(t, "radar.yaml", radarWith("Go", "Adopted"))
+	store := newFileStore(path)
+	defer store.Close()
+	if store.watcher == nil {
+		t.Fatal("data files not watched")
+	}
+	changes := store.Watch(context.Background())
+	waitForItems(t, store, 1, "Adopted")
+
+	// Until a file changes, the cached copy is served.
+	store.mu.Lock()
+	store.data.Items[0].Ring = "Cached"
+	store.mu.Unlock()
+	waitForItems(t, store, 1, "Cached")
+
+	if err := os.WriteFile(path, []byte(radarWith("Go", "In Discovery")), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	waitForItems(t, store, 1, "In Discovery")
+	select {
+	case <-changes:
+	case <-time.After(time.Second):
+		t.Error("Watch() did not report the change")
+	}
+
+	// Replacing the file by renaming another over it is noticed too.
+	tmp := filepath.Join(filepath.Dir(path), "radar.yaml.tmp")
+	if err := os.WriteFile(tmp, []byte(radarWith("Go", "Not Recommended")), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		t.Fatal(err)
+	}
+	waitForItems(t, store, 1, "Not Recommended")
+
+	store.Close()
+	waitClosed(t, changes)
+}
+
+// waitClosed fails the test unless changes is closed soon, skipping any
+// pending notification.
+func waitClosed(t *testing.T, changes <-chan struct{}) {
+	t.Helper()
+	timeout := time.After(time.Second)
+	for {
+		select {
+		case _, ok := <-changes:
+			if !ok {
+				return
+			}
+		case <-timeout:
+			t.Fatal("Watch() channel still open after Close")
+		}
+	}
+}
+
+func TestFileStoreWatchesNewFilesInDirectory(t *testing.T) {
+	dir := writeDataDir(t, map[string]string{"a.yaml": radarWith("Go", "Adopted")})
+	store := newFileStore(dir)
+	defer store.Close()
+	waitForItems(t, store, 1, "Adopted")
+
+	if err := os.WriteFile(filepath.Join(dir, "b.yaml"), []byte(radarWith("Rust", "Adopted")), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	waitForItems(t, store, 2, "Adopted")
+}
+
+func TestFileStoreSave(t *testing.T) {
+	path := writeFile(t, "radar.yaml", radarWith("Go", "Adopted"))
+	store := newFileStore(path)
+	defer store.Close()
+	changes := store.Watch(context.Background())
+	waitForItems(t, store, 1, "Adopted")
+
+	data := RadarData{Items: []RadarItem{{Label: "Go", Quadrant: "Tools", Ring: "In Discovery"}}}
+	if err := store.Save(context.Background(), data); err != nil {
+		t.Fatal(err)
+	}
+	waitForItems(t, store, 1, "In Discovery")
+	select {
+	case <-changes:
+	default:
+		t.Error("Save() did not notify watchers")
+	}
+
+	dirStore := newFileStore(filepath.Dir(path))
+	defer dirStore.Close()
+	if err := dirStore.Save(context.Background(), data); !errors.Is(err, errReadOnly) {
+		t.Errorf("Save() to a directory error = %v, want errReadOnly", err)
+	}
+}
+
+func TestStartStoreSwapsStores(t *testing.T) {
+	cfg := defaultConfig()
+	cfg.Data.Path = writeFile(t, "radar.yaml", radarWith("Go", "Adopted"))
+	useConfig(t, cfg)
+	first, ok := currentStore().(*fileStore)
+	if !ok || first.path != cfg.Data.Path {
+		t.Fatalf("currentStore() = %#v, want a file store of %s", currentStore(), cfg.Data.Path)
+	}
+	changes := first.Watch(context.Background())
+
+	// A reload re-reads the files even if the path is unchanged.
+	startStore(cfg)
+	if second := currentStore(); second == Store(first) {
+		t.Error("startStore() kept the previous file store")
+	}
+	waitClosed(t, changes)
+
+	cfg.Data.Path = "https://127.0.0.1:1/radar.yaml"
+	startStore(cfg)
+	remote, ok := currentStore().(*remoteStore)
+	if !ok {
+		t.Fatalf("currentStore() = %#v for a URL, want a remote store", currentStore())
+	}
+	startStore(cfg)
+	if currentStore() != Store(remote) {
+		t.Error("startStore() replaced the remote store of an unchanged URL")
+	}
+}
@@ -0,0 +1,55 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	_ "github.com/jackc/pgx/v5/stdlib"
+)
+
+// Advisory lock keys. The migration lock is held while migrating, so
+// instances starting together don't apply the same migration twice; the
+// save lock serializes saves, so each records its edits against the data it
+// replaced.
+const (
+	postgresMigrationLock = 0x7261646172 // "radar"
+	postgresSaveLock      = postgresMigrationLock + 1
+)
+
+// openPostgresStore connects to the PostgreSQL database at cfg.DSN, a
+// postgres:// URL or key=value connection string, and brings its schema up
+// to date. Several instances can share the database.
+func openPostgresStore(ctx context.Context, cfg StoreConfig) (Database, error) {
+	db, err := sql.Open("pgx", cfg.DSN)
+	if err != nil {
+		return nil, err
+	}
+	db.SetMaxOpenConns(cfg.MaxOpenConns)
+	db.SetMaxIdleConns(cfg.MaxIdleConns)
+	db.SetConnMaxLifetime(cfg.ConnMaxLifetime)
+	if err := migratePostgres(ctx, db); err != nil {
+		db.Close()
+		return nil, err
+	}
+	return &sqlStore{db: db, saveLock: fmt.Sprintf(`SELECT pg_advisory_xact_lock(%d)`, postgresSaveLock)}, nil
+}
+
+// migratePostgres runs the migrations while holding an advisory lock on a
+// dedicated connection.
+func migratePostgres(ctx context.Context, db *sql.DB) error {
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	if _, err := conn.ExecContext(ctx, `SELECT pg_advisory_lock($1)`, postgresMigrationLock); err != nil {
+		return err
+	}
+	err = runMigrations(ctx, conn, "postgres")
+	// Unlock with a fresh context so a cancelled migration still releases
+	// the lock before the connection returns to the pool.
+	_, unlockErr := conn.ExecContext(context.Background(), `SELECT pg_advisory_unlock($1)`, postgresMigrationLock)
+	return errors.Join(err, unlockErr)
+}
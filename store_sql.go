@@ -0,0 +1,683 @@
+package main
+
+import (
+	"cmp"
+	"context"
+	"database/sql"
+	"embed"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"log"
+	"path"
+	"strings"
+	"time"
+)
+
+// migrationsFS holds the schema migrations of each SQL store, one directory
+// per driver.
+//
+//go:embed migrations
+var migrationsFS embed.FS
+
+// migrationDB is implemented by both *sql.DB and *sql.Conn, so migrations
+// can run on a connection holding a lock.
+type migrationDB interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+	BeginTx(ctx context.Context, opts *sql.TxOptions) (*sql.Tx, error)
+}
+
+// migrationBackfills fill in the rows of the migrations they are of that SQL
+// alone can't, in the transaction of the migration.
+var migrationBackfills = map[string]func(ctx context.Context, tx *sql.Tx) error{
+	"0018_edit_item_ids": backfillEditItemIDs,
+}
+
+// backfillEditItemIDs sets the item_id of every edit to the ID of the item
+// it changed, as ItemEdit.itemID finds it.
+func backfillEditItemIDs(ctx context.Context, tx *sql.Tx) error {
+	edits, err := queryEdits(ctx, tx, `SELECT id, snapshot_id, label, action, before, after FROM edits`)
+	if err != nil {
+		return err
+	}
+	for _, edit := range edits {
+		if _, err := tx.ExecContext(ctx, `UPDATE edits SET item_id = $1 WHERE id = $2`, edit.itemID(), edit.id); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// runMigrations applies the migrations in migrations/<driver> that have not
+// been applied yet, in file name order, each in its own transaction with its
+// migrationBackfills. Applied migrations are recorded in the
+// schema_migrations table.
+func runMigrations(ctx context.Context, db migrationDB, driver string) error {
+	if _, err := db.ExecContext(ctx, `CREATE TABLE IF NOT EXISTS schema_migrations (version TEXT PRIMARY KEY, applied_at TEXT NOT NULL)`); err != nil {
+		return fmt.Errorf("creating schema_migrations: %w", err)
+	}
+	files, err := fs.Glob(migrationsFS, "migrations/"+driver+"/*.sql")
+	if err != nil {
+		return err
+	}
+
+	for _, file := range files {
+		version := strings.TrimSuffix(path.Base(file), ".sql")
+		var applied int
+		if err := db.QueryRowContext(ctx, `SELECT COUNT(*) FROM schema_migrations WHERE version = $1`, version).Scan(&applied); err != nil {
+			return err
+		}
+		if applied > 0 {
+			continue
+		}
+
+		script, err := migrationsFS.ReadFile(file)
+		if err != nil {
+			return err
+		}
+		tx, err := db.BeginTx(ctx, nil)
+		if err != nil {
+			return err
+		}
+		if _, err := tx.ExecContext(ctx, string(script)); err != nil {
+			tx.Rollback()
+			return fmt.Errorf("migration %s: %w", version, err)
+		}
+		if backfill := migrationBackfills[version]; backfill != nil {
+			if err := backfill(ctx, tx); err != nil {
+				tx.Rollback()
+				return fmt.Errorf("migration %s: %w", version, err)
+			}
+		}
+		if _, err := tx.ExecContext(ctx, `INSERT INTO schema_migrations (version, applied_at) VALUES ($1, $2)`, version, formatTime(time.Now())); err != nil {
+			tx.Rollback()
+			return err
+		}
+		if err := tx.Commit(); err != nil {
+			return err
+		}
+		log.Printf("Applied %s migration %s", driver, version)
+	}
+	return nil
+}
+
+// formatTime formats a timestamp for a database column.
+func formatTime(t time.Time) string {
+	return t.UTC().Format(time.RFC3339Nano)
+}
+
+// formatItemTime formats a timestamp of an item, leaving it empty if unset.
+func formatItemTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return formatTime(t)
+}
+
+// timeColumn scans a timestamp stored either as a native time or as text.
+type timeColumn struct {
+	time.Time
+}
+
+func (t *timeColumn) Scan(src any) error {
+	switch v := src.(type) {
+	case time.Time:
+		t.Time = v
+		return nil
+	case string:
+		return t.parse(v)
+	case []byte:
+		return t.parse(string(v))
+	default:
+		return fmt.Errorf("cannot scan %T into a timestamp", src)
+	}
+}
+
+func (t *timeColumn) parse(s string) error {
+	if s == "" {
+		t.Time = time.Time{}
+		return nil
+	}
+	parsed, err := time.Parse(time.RFC3339Nano, s)
+	t.Time = parsed
+	return err
+}
+
+// sqlStore is a Database on a database/sql database whose schema was
+// created by runMigrations.
+type sqlStore struct {
+	db *sql.DB
+	// saveLock, if set, is run at the start of every Save transaction to
+	// serialize saves from several instances sharing the database.
+	saveLock string
+}
+
+// queryer is implemented by both *sql.DB and *sql.Tx.
+type queryer interface {
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+}
+
+func (s *sqlStore) Load(ctx context.Context) (RadarData, error) {
+	return loadSQLData(ctx, s.db)
+}
+
+// loadSQLData reads the current radar data through q.
+func loadSQLData(ctx context.Context, q queryer) (RadarData, error) {
+	var data RadarData
+	meta, err := q.QueryContext(ctx, `SELECT key, value FROM meta`)
+	if err != nil {
+		return RadarData{}, err
+	}
+	defer meta.Close()
+	for meta.Next() {
+		var key, value string
+		if err := meta.Scan(&key, &value); err != nil {
+			return RadarData{}, err
+		}
+		switch {
+		case key == "last_modified":
+			data.LastModified = value
+		case key == "review_date":
+			data.ReviewDate = value
+		case key == "quadrants" && value != "":
+			err = json.Unmarshal([]byte(value), &data.Quadrants)
+		case key == "rings" && value != "":
+			err = json.Unmarshal([]byte(value), &data.Rings)
+		}
+		if err != nil {
+			return RadarData{}, fmt.Errorf("reading %s: %w", key, err)
+		}
+	}
+	if err := meta.Err(); err != nil {
+		return RadarData{}, err
+	}
+
+	rows, err := q.QueryContext(ctx, `SELECT id, label, quadrant, ring, moved, description, owners, tags, links, last_updated, archived, merged_from, review_date, direction, previous_ring, sunset_date, relations FROM items ORDER BY position`)
+	if err != nil {
+		return RadarData{}, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var item RadarItem
+		var updated timeColumn
+		if err := rows.Scan(&item.ID, &item.Label, &item.Quadrant, &item.Ring, &item.Moved, &item.Description, &item.Owners, &item.Tags, &item.Links, &updated, &item.Archived, &item.MergedFrom, &item.ReviewDate, &item.Direction, &item.PreviousRing, &item.SunsetDate, &item.Relations); err != nil {
+			return RadarData{}, err
+		}
+		item.LastUpdated = updated.Time
+		data.Items = append(data.Items, item)
+	}
+	return data, rows.Err()
+}
+
+func (s *sqlStore) Save(ctx context.Context, data RadarData, event AuditEvent) error {
+	data = storedData(data)
+	items := data.Items
+	if event.Time.IsZero() {
+		event.Time = time.Now()
+	}
+	snapshot, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	if s.saveLock != "" {
+		if _, err := tx.ExecContext(ctx, s.saveLock); err != nil {
+			return err
+		}
+	}
+
+	old, err := loadSQLData(ctx, tx)
+	if err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, `DELETE FROM items`); err != nil {
+		return err
+	}
+	for i, item := range items {
+		if _, err := tx.ExecContext(ctx,
+			`INSERT INTO items (position, id, label, quadrant, ring, moved, description, owners, tags, links, last_updated, archived, merged_from, review_date, direction, previous_ring, sunset_date, relations) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18)`,
+			i, item.ID, item.Label, item.Quadrant, item.Ring, item.Moved, item.Description, item.Owners, item.Tags, item.Links, formatItemTime(item.LastUpdated), item.Archived, item.MergedFrom, item.ReviewDate, item.Direction, item.PreviousRing, item.SunsetDate, item.Relations); err != nil {
+			return fmt.Errorf("saving item %q: %w", item.Label, err)
+		}
+	}
+	quadrants, err := marshalSegments(data.Quadrants)
+	if err != nil {
+		return err
+	}
+	rings, err := marshalSegments(data.Rings)
+	if err != nil {
+		return err
+	}
+	for _, kv := range [][2]string{{"last_modified", data.LastModified}, {"review_date", data.ReviewDate}, {"quadrants", quadrants}, {"rings", rings}} {
+		if _, err := tx.ExecContext(ctx,
+			`INSERT INTO meta (key, value) VALUES ($1, $2) ON CONFLICT (key) DO UPDATE SET value = excluded.value`,
+			kv[0], kv[1]); err != nil {
+			return err
+		}
+	}
+
+	var snapshotID int64
+	if err := tx.QueryRowContext(ctx,
+		`INSERT INTO snapshots (created_at, data) VALUES ($1, $2) RETURNING id`,
+		formatTime(event.Time), string(snapshot)).Scan(&snapshotID); err != nil {
+		return err
+	}
+	for _, edit := range diffItems(old.Items, items) {
+		before, after, err := marshalEdit(edit)
+		if err != nil {
+			return err
+		}
+		if _, err := tx.ExecContext(ctx,
+			`INSERT INTO edits (snapshot_id, label, action, before, after, item_id) VALUES ($1, $2, $3, $4, $5, $6)`,
+			snapshotID, edit.Label, edit.Action, before, after, edit.itemID()); err != nil {
+			return err
+		}
+	}
+	if _, err := tx.ExecContext(ctx,
+		`INSERT INTO audit_events (created_at, actor, action, detail, source_ip, snapshot_id, justification) VALUES ($1, $2, $3, $4, $5, $6, $7)`,
+		formatTime(event.Time), event.Actor, event.Action, event.Detail, event.SourceIP, snapshotID, event.Justification); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// marshalSegments encodes the quadrants or rings stored in the meta table
+// as JSON, or "" when data declares none.
+func marshalSegments(segments []Segment) (string, error) {
+	if segments == nil {
+		return "", nil
+	}
+	b, err := json.Marshal(segments)
+	return string(b), err
+}
+
+// marshalEdit encodes the items before and after an edit as JSON, or NULL
+// when absent.
+func marshalEdit(edit ItemEdit) (before, after sql.NullString, err error) {
+	encode := func(item *RadarItem) (sql.NullString, error) {
+		if item == nil {
+			return sql.NullString{}, nil
+		}
+		b, err := json.Marshal(item)
+		return sql.NullString{String: string(b), Valid: true}, err
+	}
+	if before, err = encode(edit.Before); err != nil {
+		return
+	}
+	after, err = encode(edit.After)
+	return
+}
+
+// querySnapshots returns the snapshots query selects, as the columns id,
+// created_at and data.
+func (s *sqlStore) querySnapshots(ctx context.Context, query string, args ...any) ([]Snapshot, error) {
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var snapshots []Snapshot
+	for rows.Next() {
+		var snapshot Snapshot
+		var created timeColumn
+		var data string
+		if err := rows.Scan(&snapshot.ID, &created, &data); err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal([]byte(data), &snapshot.Data); err != nil {
+			return nil, fmt.Errorf("snapshot %d: %w", snapshot.ID, err)
+		}
+		snapshot.Time = created.Time
+		snapshots = append(snapshots, snapshot)
+	}
+	return snapshots, rows.Err()
+}
+
+func (s *sqlStore) Snapshots(ctx context.Context, limit int) ([]Snapshot, error) {
+	return s.querySnapshots(ctx, `SELECT id, created_at, data FROM snapshots ORDER BY id DESC LIMIT $1`, limit)
+}
+
+func (s *sqlStore) Snapshot(ctx context.Context, id int64) (Snapshot, bool, error) {
+	snapshots, err := s.querySnapshots(ctx, `SELECT id, created_at, data FROM snapshots WHERE id = $1`, id)
+	if err != nil || len(snapshots) == 0 {
+		return Snapshot{}, false, err
+	}
+	return snapshots[0], true, nil
+}
+
+// sqlQuerier is implemented by *sql.DB and *sql.Tx.
+type sqlQuerier interface {
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+}
+
+// storedEdit is an ItemEdit with the ID of its row.
+type storedEdit struct {
+	ItemEdit
+	id int64
+}
+
+// queryEdits returns the edits query selects, as the columns id,
+// snapshot_id, label, action, before and after.
+func queryEdits(ctx context.Context, db sqlQuerier, query string, args ...any) ([]storedEdit, error) {
+	rows, err := db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var edits []storedEdit
+	for rows.Next() {
+		var edit storedEdit
+		var before, after sql.NullString
+		if err := rows.Scan(&edit.id, &edit.SnapshotID, &edit.Label, &edit.Action, &before, &after); err != nil {
+			return nil, err
+		}
+		for _, col := range []struct {
+			value sql.NullString
+			item  **RadarItem
+		}{{before, &edit.Before}, {after, &edit.After}} {
+			if col.value.Valid {
+				*col.item = new(RadarItem)
+				if err := json.Unmarshal([]byte(col.value.String), *col.item); err != nil {
+					return nil, err
+				}
+			}
+		}
+		edits = append(edits, edit)
+	}
+	return edits, rows.Err()
+}
+
+func (s *sqlStore) Edits(ctx context.Context, limit int) ([]ItemEdit, error) {
+	stored, err := queryEdits(ctx, s.db, `SELECT id, snapshot_id, label, action, before, after FROM edits ORDER BY id DESC LIMIT $1`, limit)
+	edits := make([]ItemEdit, len(stored))
+	for i, edit := range stored {
+		edits[i] = edit.ItemEdit
+	}
+	return edits, err
+}
+
+// queryAuditEvents returns the audit events query selects, as the columns
+// created_at, actor, action, detail, source_ip, snapshot_id and
+// justification.
+func (s *sqlStore) queryAuditEvents(ctx context.Context, query string, args ...any) ([]AuditEvent, error) {
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var events []AuditEvent
+	for rows.Next() {
+		var event AuditEvent
+		var created timeColumn
+		var snapshotID sql.NullInt64
+		if err := rows.Scan(&created, &event.Actor, &event.Action, &event.Detail, &event.SourceIP, &snapshotID, &event.Justification); err != nil {
+			return nil, err
+		}
+		event.Time, event.SnapshotID = created.Time, snapshotID.Int64
+		events = append(events, event)
+	}
+	return events, rows.Err()
+}
+
+func (s *sqlStore) AuditEvents(ctx context.Context, limit int) ([]AuditEvent, error) {
+	return s.queryAuditEvents(ctx, `SELECT created_at, actor, action, detail, source_ip, snapshot_id, justification FROM audit_events ORDER BY id DESC LIMIT $1`, limit)
+}
+
+// auditWhere returns the WHERE clause selecting the audit events of filter,
+// and its arguments. Times are compared to the second, as the RFC 3339 text
+// of stored times only sorts by time to the second.
+func auditWhere(filter auditFilter) (string, []any) {
+	var conditions []string
+	var args []any
+	add := func(condition string, arg any) {
+		args = append(args, arg)
+		conditions = append(conditions, fmt.Sprintf(condition, len(args)))
+	}
+	if filter.actor != "" {
+		add(`LOWER(actor) = $%d`, strings.ToLower(filter.actor))
+	}
+	if !filter.since.IsZero() {
+		add(`SUBSTR(created_at, 1, 19) >= $%d`, auditSecond(filter.since))
+	}
+	if !filter.until.IsZero() {
+		add(`SUBSTR(created_at, 1, 19) <= $%d`, auditSecond(filter.until))
+	}
+	if filter.item != "" {
+		add(`snapshot_id IN (SELECT snapshot_id FROM edits WHERE item_id = $%d)`, filter.item)
+	}
+	if len(conditions) == 0 {
+		return "", nil
+	}
+	return " WHERE " + strings.Join(conditions, " AND "), args
+}
+
+func (s *sqlStore) AuditLog(ctx context.Context, filter auditFilter, limit, offset int) ([]AuditEntry, int, error) {
+	where, args := auditWhere(filter)
+	var total int
+	if err := s.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM audit_events`+where, args...).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+	events, err := s.queryAuditEvents(ctx,
+		`SELECT created_at, actor, action, detail, source_ip, snapshot_id, justification FROM audit_events`+where+
+			fmt.Sprintf(` ORDER BY id DESC LIMIT $%d OFFSET $%d`, len(args)+1, len(args)+2),
+		append(args, limit, offset)...)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	var snapshots []string
+	var snapshotArgs []any
+	for _, event := range events {
+		if event.SnapshotID != 0 {
+			snapshotArgs = append(snapshotArgs, event.SnapshotID)
+			snapshots = append(snapshots, fmt.Sprintf("$%d", len(snapshotArgs)))
+		}
+	}
+	var edits []ItemEdit
+	if len(snapshots) > 0 {
+		stored, err := queryEdits(ctx, s.db,
+			`SELECT id, snapshot_id, label, action, before, after FROM edits WHERE snapshot_id IN (`+strings.Join(snapshots, ", ")+`) ORDER BY id DESC`,
+			snapshotArgs...)
+		if err != nil {
+			return nil, 0, err
+		}
+		for _, edit := range stored {
+			edits = append(edits, edit.ItemEdit)
+		}
+	}
+	return withChanges(events, edits), total, nil
+}
+
+func (s *sqlStore) SaveProposal(ctx context.Context, proposal Proposal) (Proposal, error) {
+	data, err := json.Marshal(proposal)
+	if err != nil {
+		return Proposal{}, err
+	}
+	if proposal.ID == 0 {
+		err = s.db.QueryRowContext(ctx,
+			`INSERT INTO proposals (created_at, status, token_hash, data) VALUES ($1, $2, $3, $4) RETURNING id`,
+			formatTime(proposal.Created), proposal.Status, proposal.TokenHash, string(data)).Scan(&proposal.ID)
+		return proposal, err
+	}
+	res, err := s.db.ExecContext(ctx,
+		`UPDATE proposals SET status = $1, token_hash = $2, data = $3 WHERE id = $4`,
+		proposal.Status, proposal.TokenHash, string(data), proposal.ID)
+	if err != nil {
+		return Proposal{}, err
+	}
+	if n, err := res.RowsAffected(); err != nil || n == 0 {
+		return Proposal{}, cmp.Or(err, fmt.Errorf("no proposal %d", proposal.ID))
+	}
+	return proposal, nil
+}
+
+func (s *sqlStore) Proposals(ctx context.Context) ([]Proposal, error) {
+	rows, err := s.db.QueryContext(ctx, `SELECT id, token_hash, data FROM proposals ORDER BY id`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var proposals []Proposal
+	for rows.Next() {
+		var proposal Proposal
+		var id int64
+		var tokenHash, data string
+		if err := rows.Scan(&id, &tokenHash, &data); err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal([]byte(data), &proposal); err != nil {
+			return nil, fmt.Errorf("proposal %d: %w", id, err)
+		}
+		proposal.ID, proposal.TokenHash = id, tokenHash
+		proposals = append(proposals, proposal)
+	}
+	return proposals, rows.Err()
+}
+
+func (s *sqlStore) SaveComment(ctx context.Context, comment Comment) (Comment, error) {
+	data, err := json.Marshal(comment)
+	if err != nil {
+		return Comment{}, err
+	}
+	if comment.ID == 0 {
+		err = s.db.QueryRowContext(ctx,
+			`INSERT INTO comments (item_id, created_at, token_hash, data) VALUES ($1, $2, $3, $4) RETURNING id`,
+			comment.ItemID, formatTime(comment.Created), comment.TokenHash, string(data)).Scan(&comment.ID)
+		return comment, err
+	}
+	res, err := s.db.ExecContext(ctx,
+		`UPDATE comments SET item_id = $1, token_hash = $2, data = $3 WHERE id = $4`,
+		comment.ItemID, comment.TokenHash, string(data), comment.ID)
+	if err != nil {
+		return Comment{}, err
+	}
+	if n, err := res.RowsAffected(); err != nil || n == 0 {
+		return Comment{}, cmp.Or(err, fmt.Errorf("no comment %d", comment.ID))
+	}
+	return comment, nil
+}
+
+func (s *sqlStore) Comments(ctx context.Context, itemID string) ([]Comment, error) {
+	query, args := `SELECT id, token_hash, data FROM comments ORDER BY id`, []any{}
+	if itemID != "" {
+		query, args = `SELECT id, token_hash, data FROM comments WHERE item_id = $1 ORDER BY id`, []any{itemID}
+	}
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var comments []Comment
+	for rows.Next() {
+		var comment Comment
+		var id int64
+		var tokenHash, data string
+		if err := rows.Scan(&id, &tokenHash, &data); err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal([]byte(data), &comment); err != nil {
+			return nil, fmt.Errorf("comment %d: %w", id, err)
+		}
+		comment.ID, comment.TokenHash = id, tokenHash
+		comments = append(comments, comment)
+	}
+	return comments, rows.Err()
+}
+
+func (s *sqlStore) SaveVote(ctx context.Context, vote Vote) (bool, error) {
+	res, err := s.db.ExecContext(ctx,
+		`INSERT INTO votes (item_id, voter, created_at) VALUES ($1, $2, $3) ON CONFLICT DO NOTHING`,
+		vote.ItemID, vote.Voter, formatTime(vote.Created))
+	if err != nil {
+		return false, err
+	}
+	n, err := res.RowsAffected()
+	return n > 0, err
+}
+
+func (s *sqlStore) DeleteVote(ctx context.Context, itemID, voter string) (bool, error) {
+	res, err := s.db.ExecContext(ctx, `DELETE FROM votes WHERE item_id = $1 AND voter = $2`, itemID, voter)
+	if err != nil {
+		return false, err
+	}
+	n, err := res.RowsAffected()
+	return n > 0, err
+}
+
+func (s *sqlStore) Votes(ctx context.Context) ([]Vote, error) {
+	rows, err := s.db.QueryContext(ctx, `SELECT item_id, voter, created_at FROM votes`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var votes []Vote
+	for rows.Next() {
+		var vote Vote
+		var created timeColumn
+		if err := rows.Scan(&vote.ItemID, &vote.Voter, &created); err != nil {
+			return nil, err
+		}
+		vote.Created = created.Time
+		votes = append(votes, vote)
+	}
+	return votes, rows.Err()
+}
+
+func (s *sqlStore) SaveReaction(ctx context.Context, reaction Reaction) (bool, error) {
+	res, err := s.db.ExecContext(ctx,
+		`INSERT INTO reactions (item_id, reaction, user_name, created_at) VALUES ($1, $2, $3, $4) ON CONFLICT DO NOTHING`,
+		reaction.ItemID, reaction.Reaction, reaction.User, formatTime(reaction.Created))
+	if err != nil {
+		return false, err
+	}
+	n, err := res.RowsAffected()
+	return n > 0, err
+}
+
+func (s *sqlStore) DeleteReaction(ctx context.Context, reaction Reaction) (bool, error) {
+	res, err := s.db.ExecContext(ctx,
+		`DELETE FROM reactions WHERE item_id = $1 AND reaction = $2 AND user_name = $3`,
+		reaction.ItemID, reaction.Reaction, reaction.User)
+	if err != nil {
+		return false, err
+	}
+	n, err := res.RowsAffected()
+	return n > 0, err
+}
+
+func (s *sqlStore) Reactions(ctx context.Context, itemID string) ([]Reaction, error) {
+	rows, err := s.db.QueryContext(ctx, `SELECT item_id, reaction, user_name, created_at FROM reactions WHERE item_id = $1`, itemID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var reactions []Reaction
+	for rows.Next() {
+		var reaction Reaction
+		var created timeColumn
+		if err := rows.Scan(&reaction.ItemID, &reaction.Reaction, &reaction.User, &created); err != nil {
+			return nil, err
+		}
+		reaction.Created = created.Time
+		reactions = append(reactions, reaction)
+	}
+	return reactions, rows.Err()
+}
+
+func (s *sqlStore) Close() error {
+	return s.db.Close()
+}
@@ -0,0 +1,30 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+
+	_ "modernc.org/sqlite"
+)
+
+// openSQLiteStore opens the SQLite database file at cfg.DSN, creating it if
+// needed, and brings its schema up to date. The pure-Go driver needs no cgo,
+// so the binary stays statically linked.
+func openSQLiteStore(ctx context.Context, cfg StoreConfig) (Database, error) {
+	db, err := sql.Open("sqlite", cfg.DSN)
+	if err != nil {
+		return nil, err
+	}
+	// SQLite allows a single writer; one connection avoids busy errors and
+	// keeps the pragmas below in effect for every query.
+	db.SetMaxOpenConns(1)
+	if _, err := db.ExecContext(ctx, `PRAGMA foreign_keys = ON; PRAGMA journal_mode = WAL; PRAGMA busy_timeout = 5000`); err != nil {
+		db.Close()
+		return nil, err
+	}
+	if err := runMigrations(ctx, db, "sqlite"); err != nil {
+		db.Close()
+		return nil, err
+	}
+	return &sqlStore{db: db}, nil
+}
@@ -0,0 +1,347 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// useConfig makes cfg the active configuration, with the Store for its data
+// source, for the duration of the test.
+func useConfig(t *testing.T, cfg Config) {
+	t.Helper()
+	startStore(cfg)
+	activeConfig.Store(&cfg)
+	t.Cleanup(func() {
+		if s := activeStore.Swap(nil); s != nil {
+			(*s).Close()
+		}
+		activeConfig.Store(nil)
+	})
+}
+
+// useStore makes store the active Store for the duration of the test. It is
+// not closed afterwards.
+func useStore(t *testing.T, store Store) {
+	t.Helper()
+	activeStore.Store(&store)
+	t.Cleanup(func() { activeStore.Store(nil) })
+}
+
+// openTestStore opens a store with the given driver for the duration of the
+// test.
+func openTestStore(t *testing.T, driver, dsn string) Database {
+	t.Helper()
+	cfg := defaultConfig().Store
+	cfg.Driver, cfg.DSN = driver, dsn
+	store, err := storeDrivers[driver](context.Background(), cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { store.Close() })
+	return store
+}
+
+// testStore saves twice to store and checks what it recorded.
+func testStore(t *testing.T, store Database) {
+	ctx := context.Background()
+	first := RadarData{LastModified: "May 2024", Items: []RadarItem{
+		{Label: "Go", Quadrant: "Tools", Ring: "Adopted", Moved: true, Description: "Fast.", Owners: Owners{{Name: "Team A", Email: "a@example.com"}},
+			Tags: Tags{"backend"}, Links: Links{{Title: "ADR 1", URL: "https://example.com/adr/1"}}},
+		{Label: "Perl", Quadrant: "Tools", Ring: "Not Recommended"},
+	}}
+	second := RadarData{LastModified: "June 2024", ReviewDate: "2024-09-01", Rings: []Segment{{Name: "Adopted", Color: "#00c000", Description: "Use it."}, {Name: "In Discovery"}}, Items: []RadarItem{
+		{Label: "Go", Quadrant: "Tools", Ring: "In Discovery", Relations: Relations{{relationRelatedTo, "rust-lang"}}, Source: "radar.yaml"},
+		{ID: "rust-lang", Label: "Rust", Quadrant: "Tools", Ring: "Adopted", Archived: true, MergedFrom: ItemIDs{"rust", "rustlang"}, ReviewDate: "2024-12-01"},
+	}}
+	if err := store.Save(ctx, first, AuditEvent{Actor: "system", Action: "seed"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.Save(ctx, second, AuditEvent{Actor: "admin", Action: "import", Detail: "radar.csv"}); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := store.Load(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	second.Items[0].Source = ""
+	if !reflect.DeepEqual(data, second) {
+		t.Errorf("Load() = %+v, want %+v", data, second)
+	}
+
+	snapshots, err := store.Snapshots(ctx, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(snapshots) != 2 || !reflect.DeepEqual(snapshots[1].Data, first) || snapshots[0].Time.IsZero() {
+		t.Errorf("Snapshots() = %+v", snapshots)
+	}
+	if snapshot, ok, err := store.Snapshot(ctx, snapshots[1].ID); err != nil || !ok || !reflect.DeepEqual(snapshot, snapshots[1]) {
+		t.Errorf("Snapshot(%d) = %+v, %t, %v, want %+v", snapshots[1].ID, snapshot, ok, err, snapshots[1])
+	}
+	if _, ok, err := store.Snapshot(ctx, snapshots[0].ID+1); err != nil || ok {
+		t.Errorf("Snapshot of an unknown ID = %t, %v", ok, err)
+	}
+
+	edits, err := store.Edits(ctx, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, e := range edits {
+		got = append(got, e.Action+" "+e.Label)
+	}
+	want := []string{"removed Perl", "added Rust", "updated Go", "added Perl", "added Go"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Edits() = %q, want %q", got, want)
+	}
+	if updated := edits[2]; updated.Before.Ring != "Adopted" || updated.After.Ring != "In Discovery" {
+		t.Errorf("updated edit = %+v -> %+v", updated.Before, updated.After)
+	}
+
+	events, err := store.AuditEvents(ctx, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(events) != 1 || events[0].Action != "import" || events[0].SnapshotID != snapshots[0].ID || events[0].Time.IsZero() {
+		t.Errorf("AuditEvents() = %+v", events)
+	}
+
+	// Perl was added by the seed and removed by the import.
+	entries, total, err := store.AuditLog(ctx, auditFilter{item: "perl", actor: "SYSTEM"}, 10, 0)
+	if err != nil || total != 1 || len(entries) != 1 || entries[0].Action != "seed" || len(entries[0].Changes) != 2 || entries[0].Changes[0].Label != "Go" {
+		t.Errorf("AuditLog(perl by system) = %+v, %d, %v", entries, total, err)
+	}
+	entries, total, err = store.AuditLog(ctx, auditFilter{item: "perl"}, 1, 1)
+	if err != nil || total != 2 || len(entries) != 1 || entries[0].Action != "seed" {
+		t.Errorf("second page of AuditLog(perl) = %+v, %d, %v", entries, total, err)
+	}
+}
+
+func TestSQLiteStore(t *testing.T) {
+	testStore(t, openTestStore(t, "sqlite", filepath.Join(t.TempDir(), "radar.db")))
+}
+
+func TestSQLiteStoreMigratesOnce(t *testing.T) {
+	dsn := filepath.Join(t.TempDir(), "radar.db")
+	ctx := context.Background()
+	store := openTestStore(t, "sqlite", dsn)
+	if err := store.Save(ctx, RadarData{Items: []RadarItem{{Label: "Go", Quadrant: "Tools", Ring: "Adopted"}}}, AuditEvent{Actor: "test"}); err != nil {
+		t.Fatal(err)
+	}
+	store.Close()
+
+	data, err := openTestStore(t, "sqlite", dsn).Load(ctx)
+	if err != nil || len(data.Items) != 1 {
+		t.Errorf("after reopening Load() = %+v, %v", data, err)
+	}
+}
+
+func TestBackfillEditItemIDs(t *testing.T) {
+	dsn := filepath.Join(t.TempDir(), "radar.db")
+	ctx := context.Background()
+	store := openTestStore(t, "sqlite", dsn)
+	data := RadarData{Items: []RadarItem{{Label: "Go", Quadrant: "Tools", Ring: "Adopted"}, {ID: "rust-lang", Label: "Rust", Quadrant: "Tools", Ring: "Adopted"}}}
+	if err := store.Save(ctx, data, AuditEvent{Actor: "test"}); err != nil {
+		t.Fatal(err)
+	}
+	store.Close()
+
+	// Edits saved before the migration have no item ID.
+	db, err := sql.Open("sqlite", dsn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer tx.Rollback()
+	if _, err := tx.ExecContext(ctx, `UPDATE edits SET item_id = ''`); err != nil {
+		t.Fatal(err)
+	}
+	if err := backfillEditItemIDs(ctx, tx); err != nil {
+		t.Fatal(err)
+	}
+	rows, err := tx.QueryContext(ctx, `SELECT item_id FROM edits ORDER BY id`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rows.Close()
+	var ids []string
+	for rows.Next() {
+		var id string
+		rows.Scan(&id)
+		ids = append(ids, id)
+	}
+	if want := []string{"go", "rust-lang"}; !reflect.DeepEqual(ids, want) {
+		t.Errorf("item IDs = %q, want %q", ids, want)
+	}
+}
+
+func TestBoltStore(t *testing.T) {
+	testStore(t, openTestStore(t, "bbolt", filepath.Join(t.TempDir(), "radar.bolt")))
+}
+
+func TestBoltStoreReopens(t *testing.T) {
+	dsn := filepath.Join(t.TempDir(), "radar.bolt")
+	ctx := context.Background()
+	store := openTestStore(t, "bbolt", dsn)
+	if err := store.Save(ctx, RadarData{Items: []RadarItem{{Label: "Go", Quadrant: "Tools", Ring: "Adopted"}}}, AuditEvent{Actor: "test"}); err != nil {
+		t.Fatal(err)
+	}
+	store.Close()
+
+	store = openTestStore(t, "bbolt", dsn)
+	data, err := store.Load(ctx)
+	if err != nil || len(data.Items) != 1 {
+		t.Errorf("after reopening Load() = %+v, %v", data, err)
+	}
+	snapshots, err := store.Snapshots(ctx, 10)
+	if err != nil || len(snapshots) != 1 || snapshots[0].ID != 1 {
+		t.Errorf("after reopening Snapshots() = %+v, %v", snapshots, err)
+	}
+}
+
+// TestPostgresStore runs against the database in RADAR_TEST_POSTGRES_DSN,
+// which must be a throwaway database: its radar tables are dropped first.
+func TestPostgresStore(t *testing.T) {
+	dsn := os.Getenv("RADAR_TEST_POSTGRES_DSN")
+	if dsn == "" {
+		t.Skip("RADAR_TEST_POSTGRES_DSN not set")
+	}
+	db, err := sql.Open("pgx", dsn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = db.Exec(`DROP TABLE IF EXISTS audit_events, edits, snapshots, items, meta, schema_migrations`)
+	db.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	testStore(t, openTestStore(t, "postgres", dsn))
+	// Reopening finds the schema up to date.
+	if _, err := openTestStore(t, "postgres", dsn).Load(context.Background()); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestOpenStoreSeedsFromDataFiles(t *testing.T) {
+	cfg := defaultConfig()
+	cfg.Store = StoreConfig{Driver: "sqlite", DSN: filepath.Join(t.TempDir(), "radar.db")}
+	cfg.Data.Path = writeFile(t, "radar.yaml", radarWith("Go", "Adopted"))
+	ctx := context.Background()
+
+	for range 2 {
+		store, err := openStore(ctx, cfg)
+		if err != nil {
+			t.Fatal(err)
+		}
+		snapshots, err := store.Snapshots(ctx, 10)
+		store.Close()
+		if err != nil || len(snapshots) != 1 || snapshots[0].Data.Items[0].Label != "Go" {
+			t.Fatalf("Snapshots() = %+v, %v, want a single seed snapshot", snapshots, err)
+		}
+	}
+
+	cfg.Store.DSN = filepath.Join(t.TempDir(), "invalid.db")
+	cfg.Data.Path = writeFile(t, "radar.yaml", radarWith("Go", "Someday"))
+	if _, err := openStore(ctx, cfg); err == nil {
+		t.Error("openStore() seeded a store from invalid data")
+	}
+}
+
+func TestRunImport(t *testing.T) {
+	clearRadarEnv(t)
+	dsn := filepath.Join(t.TempDir(), "radar.db")
+	t.Setenv("RADAR_STORE_DRIVER", "sqlite")
+	t.Setenv("RADAR_STORE_DSN", dsn)
+	ctx := context.Background()
+
+	// The first import goes into an empty store, the second replaces it.
+	for _, ring := range []string{"Adopted", "In Discovery"} {
+		if err := runImport([]string{"-data", writeFile(t, "radar.yaml", radarWith("Go", ring))}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	store := openTestStore(t, "sqlite", dsn)
+	data, err := store.Load(ctx)
+	if err != nil || len(data.Items) != 1 || data.Items[0].Ring != "In Discovery" {
+		t.Errorf("Load() = %+v, %v", data, err)
+	}
+	events, err := store.AuditEvents(ctx, 10)
+	if err != nil || len(events) != 2 || events[0].Actor != "cli" || events[0].Action != "import" {
+		t.Errorf("AuditEvents() = %+v, %v", events, err)
+	}
+
+	if err := runImport([]string{"-data", writeFile(t, "radar.yaml", radarWith("Go", "Someday"))}); err == nil {
+		t.Error("runImport() imported invalid data")
+	}
+	t.Setenv("RADAR_STORE_DRIVER", "")
+	if err := runImport(nil); err == nil {
+		t.Error("runImport() succeeded without a store")
+	}
+}
+
+func TestDiffItems(t *testing.T) {
+	old := []RadarItem{{Label: "Go", Ring: "Adopted"}, {Label: "Perl"}}
+	new := []RadarItem{{Label: "go", Ring: "Adopted"}, {Label: "Rust"}}
+	var got []string
+	for _, e := range diffItems(old, new) {
+		got = append(got, e.Action+" "+e.Label)
+	}
+	want := []string{"updated go", "added Rust", "removed Perl"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("diffItems() = %q, want %q", got, want)
+	}
+}
+
+func TestDatabaseStoreSave(t *testing.T) {
+	store := &databaseStore{db: openTestStore(t, "sqlite", filepath.Join(t.TempDir(), "radar.db"))}
+	changes := store.Watch(context.Background())
+	ctx := withAuditEvent(context.Background(), AuditEvent{Actor: "admin", Action: "import", Detail: "radar.csv"})
+	if err := store.Save(ctx, RadarData{Items: []RadarItem{{Label: "Go", Quadrant: "Tools", Ring: "Adopted"}}}); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.Save(context.Background(), RadarData{}); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case <-changes:
+	default:
+		t.Error("Save() did not notify watchers")
+	}
+
+	events, err := store.db.AuditEvents(context.Background(), 10)
+	if err != nil || len(events) != 2 {
+		t.Fatalf("AuditEvents() = %+v, %v", events, err)
+	}
+	if got := events[1]; got.Actor != "admin" || got.Action != "import" || got.Detail != "radar.csv" {
+		t.Errorf("audit event = %+v, want the one carried by the context", got)
+	}
+	if got := events[0]; got.Actor != "system" || got.Action != "save" {
+		t.Errorf("audit event without context = %+v, want system save", got)
+	}
+}
+
+func TestLoadRadarDataFromStore(t *testing.T) {
+	store := openTestStore(t, "sqlite", filepath.Join(t.TempDir(), "radar.db"))
+	want := RadarData{LastModified: "May 2024", Items: []RadarItem{{Label: "Go", Quadrant: "Tools", Ring: "Adopted"}}}
+	if err := store.Save(context.Background(), want, AuditEvent{Actor: "test"}); err != nil {
+		t.Fatal(err)
+	}
+	useStore(t, &databaseStore{db: store})
+
+	// Items saved without an ID get the slug of their label.
+	want.Items[0].ID = "go"
+	data, err := loadRadarData()
+	if err != nil || !reflect.DeepEqual(data, want) {
+		t.Errorf("loadRadarData() = %+v, %v, want %+v", data, err, want)
+	}
+}
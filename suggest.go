@@ -0,0 +1,172 @@
+package main
+
+import (
+	"cmp"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Limits of GET /api/suggest.
+const (
+	defaultSuggestLimit = 8
+	maxSuggestLimit     = 20
+)
+
+// Kinds of Suggestion.
+const (
+	suggestItem = "item"
+	suggestTag  = "tag"
+)
+
+// Suggestion is a label or tag completing the text typed so far. Item
+// suggestions come with the quadrant and ring of the item, tag suggestions
+// with the number of items that have the tag.
+type Suggestion struct {
+	Type     string `json:"type"`
+	Label    string `json:"label,omitempty"`
+	ID       string `json:"id,omitempty"`
+	Quadrant string `json:"quadrant,omitempty"`
+	Ring     string `json:"ring,omitempty"`
+	Tag      string `json:"tag,omitempty"`
+	Count    int    `json:"count,omitempty"`
+}
+
+// suggestKey is a word a suggestion can be found by.
+type suggestKey struct {
+	key string
+	// rank orders suggestions matching the same prefix: whole labels,
+	// then other words of labels, then tags.
+	rank       int
+	suggestion int
+}
+
+// suggestIndex finds suggestions by the prefix of one of their words with
+// a binary search, so typing doesn't scan every item.
+type suggestIndex struct {
+	keys        []suggestKey
+	suggestions []Suggestion
+}
+
+// newSuggestIndex indexes the labels and tags of the items that aren't
+// archived.
+func newSuggestIndex(items []RadarItem) *suggestIndex {
+	items = visibleItems(items)
+	idx := &suggestIndex{}
+	add := func(s Suggestion, text string) {
+		n := len(idx.suggestions)
+		idx.suggestions = append(idx.suggestions, s)
+		idx.keys = append(idx.keys, suggestKey{strings.ToLower(text), 0, n})
+		for i, word := range searchTerms(text) {
+			if i > 0 {
+				idx.keys = append(idx.keys, suggestKey{word, 1, n})
+			}
+		}
+	}
+	for _, item := range items {
+		add(Suggestion{Type: suggestItem, Label: item.Label, ID: item.ID, Quadrant: item.Quadrant, Ring: item.Ring}, item.Label)
+	}
+	for _, tag := range countTags(items) {
+		n := len(idx.suggestions)
+		idx.suggestions = append(idx.suggestions, Suggestion{Type: suggestTag, Tag: tag.Tag, Count: tag.Count})
+		idx.keys = append(idx.keys, suggestKey{tag.Tag, 2, n})
+	}
+	slices.SortFunc(idx.keys, func(a, b suggestKey) int { return strings.Compare(a.key, b.key) })
+	return idx
+}
+
+// suggest returns at most limit suggestions with a word starting with
+// prefix: whole labels first, then labels by another word, then tags,
+// shortest first.
+func (idx *suggestIndex) suggest(prefix string, limit int) []Suggestion {
+	prefix = strings.ToLower(strings.TrimSpace(prefix))
+	better := func(a, b suggestKey) int {
+		return cmp.Or(a.rank-b.rank, len(a.key)-len(b.key), strings.Compare(a.key, b.key), a.suggestion-b.suggestion)
+	}
+	start, _ := slices.BinarySearchFunc(idx.keys, prefix, func(k suggestKey, p string) int { return strings.Compare(k.key, p) })
+	// best holds the best key of each of the top suggestions so far, in
+	// order, so a short prefix matching every item doesn't sort them all.
+	var best []suggestKey
+	for _, k := range idx.keys[start:] {
+		if !strings.HasPrefix(k.key, prefix) {
+			break
+		}
+		if len(best) == limit && better(k, best[limit-1]) >= 0 {
+			continue
+		}
+		if i := slices.IndexFunc(best, func(b suggestKey) bool { return b.suggestion == k.suggestion }); i >= 0 {
+			if better(k, best[i]) >= 0 {
+				continue
+			}
+			best = slices.Delete(best, i, i+1)
+		}
+		i, _ := slices.BinarySearchFunc(best, k, better)
+		best = slices.Insert(best, i, k)
+		if len(best) > limit {
+			best = best[:limit]
+		}
+	}
+
+	var suggestions []Suggestion
+	for _, k := range best {
+		suggestions = append(suggestions, idx.suggestions[k.suggestion])
+	}
+	return suggestions
+}
+
+// suggestCache holds the index of the items it was built from, which the
+// stores keep in memory until the data changes.
+var suggestCache struct {
+	sync.Mutex
+	items []RadarItem
+	index *suggestIndex
+}
+
+// cachedSuggestIndex returns the index of items, reusing the last one if
+// it was built from the same slice.
+func cachedSuggestIndex(items []RadarItem) *suggestIndex {
+	suggestCache.Lock()
+	defer suggestCache.Unlock()
+	same := len(items) == len(suggestCache.items) && (len(items) == 0 || &items[0] == &suggestCache.items[0])
+	if suggestCache.index == nil || !same {
+		suggestCache.items, suggestCache.index = items, newSuggestIndex(items)
+	}
+	return suggestCache.index
+}
+
+// suggestHandler serves the labels and tags starting with ?q=, for
+// completing what is typed in a search box.
+func suggestHandler(w http.ResponseWriter, r *http.Request) {
+	query := strings.TrimSpace(r.URL.Query().Get("q"))
+	if query == "" {
+		handleError(w, &AppError{Code: http.StatusBadRequest, Message: "Missing query q"})
+		return
+	}
+	limit := defaultSuggestLimit
+	if value := r.URL.Query().Get("limit"); value != "" {
+		n, err := strconv.Atoi(value)
+		if err != nil || n < 1 || n > maxSuggestLimit {
+			handleError(w, &AppError{Code: http.StatusBadRequest, Message: fmt.Sprintf("Invalid limit %q, must be between 1 and %d", value, maxSuggestLimit)})
+			return
+		}
+		limit = n
+	}
+	data, err := loadRadarData()
+	if err != nil {
+		handleError(w, err)
+		return
+	}
+
+	suggestions := cachedSuggestIndex(data.Items).suggest(query, limit)
+	if suggestions == nil {
+		suggestions = []Suggestion{}
+	}
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(map[string]any{"query": query, "suggestions": suggestions}); err != nil {
+		handleError(w, &AppError{Code: http.StatusInternalServerError, Message: "Failed to encode response", Err: err})
+	}
+}
@@ -0,0 +1,90 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestSuggest(t *testing.T) {
+	idx := newSuggestIndex([]RadarItem{
+		{ID: "argo-cd", Label: "Argo CD", Quadrant: "Tools", Ring: "Adopted", Tags: Tags{"kubernetes"}},
+		{ID: "kubernetes", Label: "Kubernetes", Quadrant: "Platforms", Ring: "Adopted", Tags: Tags{"kubernetes"}},
+		{ID: "kustomize", Label: "Kustomize", Quadrant: "Tools", Ring: "In Discovery"},
+		{ID: "k3s", Label: "Lightweight Kubernetes", Quadrant: "Platforms", Ring: "Retired"},
+		{ID: "kudu", Label: "Kudu", Quadrant: "Platforms", Ring: "Retired", Archived: true},
+	})
+	describe := func(suggestions []Suggestion) []string {
+		var out []string
+		for _, s := range suggestions {
+			out = append(out, s.Type+":"+s.Label+s.Tag)
+		}
+		return out
+	}
+
+	for prefix, want := range map[string][]string{
+		"ku":          {"item:Kustomize", "item:Kubernetes", "item:Lightweight Kubernetes", "tag:kubernetes"},
+		"KUBE":        {"item:Kubernetes", "item:Lightweight Kubernetes", "tag:kubernetes"},
+		"cd":          {"item:Argo CD"},
+		"lightweight": {"item:Lightweight Kubernetes"},
+		"kud":         nil,
+		"x":           nil,
+	} {
+		if got := describe(idx.suggest(prefix, 10)); !reflect.DeepEqual(got, want) {
+			t.Errorf("suggest(%q) = %q, want %q", prefix, got, want)
+		}
+	}
+	if got := idx.suggest("ku", 2); len(got) != 2 {
+		t.Errorf("suggest(ku, 2) = %+v, want 2 suggestions", got)
+	}
+	if got := idx.suggest("kubernetes", 10); got[0] != (Suggestion{Type: suggestItem, Label: "Kubernetes", ID: "kubernetes", Quadrant: "Platforms", Ring: "Adopted"}) ||
+		got[2] != (Suggestion{Type: suggestTag, Tag: "kubernetes", Count: 2}) {
+		t.Errorf("suggest(kubernetes) = %+v", got)
+	}
+}
+
+func TestSuggestIsFast(t *testing.T) {
+	items := make([]RadarItem, 10000)
+	for i := range items {
+		items[i] = RadarItem{ID: fmt.Sprintf("item-%d", i), Label: fmt.Sprintf("Technology %d", i), Tags: Tags{fmt.Sprintf("tag-%d", i%100)}}
+	}
+	cachedSuggestIndex(items)
+	// Every item matches, the worst case. The fastest of a few runs is
+	// measured, so a busy machine doesn't fail the test.
+	fastest := time.Hour
+	for range 10 {
+		start := time.Now()
+		cachedSuggestIndex(items).suggest("tech", defaultSuggestLimit)
+		fastest = min(fastest, time.Since(start))
+	}
+	if fastest > 5*time.Millisecond {
+		t.Errorf("suggest took %v for 10000 items, want under 5ms", fastest)
+	}
+}
+
+func TestSuggestHandler(t *testing.T) {
+	cfg := defaultConfig()
+	cfg.Data.Path = writeFile(t, "radar.yaml", radarWith("Kubernetes", "Adopted"))
+	useConfig(t, cfg)
+	handler, err := setupRoutes(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rec := doRequest(t, handler, http.MethodGet, "/api/suggest?q=ku")
+	var body struct{ Suggestions []Suggestion }
+	if err := json.NewDecoder(rec.Body).Decode(&body); err != nil {
+		t.Fatalf("GET /api/suggest: status %d: %v", rec.Code, err)
+	}
+	if len(body.Suggestions) != 1 || body.Suggestions[0].Label != "Kubernetes" || body.Suggestions[0].Ring != "Adopted" {
+		t.Errorf("GET /api/suggest?q=ku = %+v", body)
+	}
+	for _, target := range []string{"/api/suggest", "/api/suggest?q=ku&limit=0", "/api/suggest?q=ku&limit=x"} {
+		if rec := doRequest(t, handler, http.MethodGet, target); rec.Code != http.StatusBadRequest {
+			t.Errorf("GET %s = %d, want 400", target, rec.Code)
+		}
+	}
+}
@@ -0,0 +1,223 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Sunset notification events.
+const (
+	sunsetUpcoming = "sunset-upcoming"
+	sunsetOverdue  = "sunset-overdue"
+)
+
+// SunsetItem is an item with a SunsetDate, as GET /api/v1/sunsets lists it.
+type SunsetItem struct {
+	RadarItem
+	// DaysLeft is the number of days until the sunset, zero on the day and
+	// negative after it, when the item is Overdue.
+	DaysLeft int  `json:"daysLeft"`
+	Overdue  bool `json:"overdue"`
+}
+
+// daysUntil returns the number of days from the day of now, in UTC, to the
+// YYYY-MM-DD date, negative if it is past, and false if there is no date.
+func daysUntil(date string, now time.Time) (int, bool) {
+	// Dates were validated with the data.
+	day, err := time.Parse(time.DateOnly, date)
+	if err != nil {
+		return 0, false
+	}
+	year, month, d := now.UTC().Date()
+	return int(day.Sub(time.Date(year, month, d, 0, 0, 0, 0, time.UTC)).Hours() / 24), true
+}
+
+// sunsetItems returns the visible items of items whose sunset is at most
+// days after the day of now, or past, soonest first.
+func sunsetItems(items []RadarItem, now time.Time, days int) []SunsetItem {
+	sunsets := []SunsetItem{}
+	for _, item := range visibleItems(items) {
+		if left, ok := daysUntil(item.SunsetDate, now); ok && left <= days {
+			sunsets = append(sunsets, SunsetItem{RadarItem: item, DaysLeft: left, Overdue: left <= 0})
+		}
+	}
+	slices.SortStableFunc(sunsets, func(a, b SunsetItem) int {
+		if a.DaysLeft != b.DaysLeft {
+			return a.DaysLeft - b.DaysLeft
+		}
+		return strings.Compare(labelKey(a.Label), labelKey(b.Label))
+	})
+	return sunsets
+}
+
+// noticeDays returns the sunset.notice of cfg in whole days.
+func noticeDays(cfg SunsetConfig) int {
+	return int(cfg.Notice / (24 * time.Hour))
+}
+
+// sunsetsHandler lists the items whose sunset is within ?days= days, by
+// default those of sunset.notice, or past, soonest first.
+func sunsetsHandler(w http.ResponseWriter, r *http.Request) {
+	days := noticeDays(currentConfig().Sunset)
+	if value := r.URL.Query().Get("days"); value != "" {
+		n, err := strconv.Atoi(value)
+		if err != nil || n < 0 {
+			handleError(w, &AppError{Code: http.StatusBadRequest, Message: fmt.Sprintf("Invalid days %q, must be a number of days", value)})
+			return
+		}
+		days = n
+	}
+	data, err := loadRadarData()
+	if err != nil {
+		handleError(w, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(map[string][]SunsetItem{"items": sunsetItems(data.Items, time.Now(), days)}); err != nil {
+		handleError(w, &AppError{Code: http.StatusInternalServerError, Message: "Failed to encode response", Err: err})
+	}
+}
+
+// sunsetList lists the labels and sunset dates of items for a message.
+func sunsetList(items []SunsetItem) string {
+	names := make([]string, len(items))
+	for i, item := range items {
+		names[i] = fmt.Sprintf("%s (%s)", item.Label, item.SunsetDate)
+	}
+	return strings.Join(names, ", ")
+}
+
+// moveSunsetItems moves the items of overdue to the ring moveTo, unless they
+// are in it, as a single save made by "sunset", and returns those moved,
+// with the *PullRequest of the save as the error if it opened one instead.
+func moveSunsetItems(ctx context.Context, overdue []SunsetItem, moveTo string) ([]SunsetItem, error) {
+	editMu.Lock()
+	defer editMu.Unlock()
+	store, data, err := editableData()
+	if err != nil {
+		return nil, err
+	}
+	var moved []SunsetItem
+	data.Items = slices.Clone(data.Items)
+	for i, item := range data.Items {
+		if item.Ring != moveTo && slices.ContainsFunc(overdue, func(s SunsetItem) bool { return s.ID == item.ID }) {
+			data.Items[i].Ring = moveTo
+			moved = append(moved, SunsetItem{RadarItem: data.Items[i]})
+		}
+	}
+	if len(moved) == 0 {
+		return nil, nil
+	}
+	ids := make([]string, len(moved))
+	for i, item := range moved {
+		ids[i] = item.ID
+	}
+	data.LastModified = time.Now().Format("January 2006")
+	event := AuditEvent{Actor: "sunset", Action: "sunset", Detail: strings.Join(ids, ", "), Justification: "Past its sunset date"}
+	if err := store.Save(withAuditEvent(ctx, event), data); err != nil {
+		return moved, err
+	}
+	invalidateRadarCache(ctx)
+	return moved, nil
+}
+
+// sunsetMoveKey identifies the move of an item past its sunset in the
+// notified map of checkSunsets.
+func sunsetMoveKey(item SunsetItem) string {
+	return "move\x00" + item.ID + "\x00" + item.SunsetDate
+}
+
+// checkSunsets flags the items whose sunset is within cfg.Notice of now, or
+// past: it logs them and notifies of them, once for each the first time
+// they are upcoming and the first time they are overdue, as recorded in
+// notified. Overdue items are moved to cfg.MoveTo if it is set, and tried
+// again at the next check if that fails. When the store opens a pull request
+// for the move instead, the items aren't moved again while it is pending.
+func checkSunsets(ctx context.Context, cfg SunsetConfig, notifications NotificationConfig, notified map[string]bool, now time.Time) error {
+	data, err := loadRadarData()
+	if err != nil {
+		return err
+	}
+	var upcoming, overdue, toMove []SunsetItem
+	for _, item := range sunsetItems(data.Items, now, noticeDays(cfg)) {
+		if cfg.MoveTo != "" && item.Overdue && item.Ring != cfg.MoveTo && !notified[sunsetMoveKey(item)] {
+			toMove = append(toMove, item)
+		}
+		key := item.ID + "\x00" + item.SunsetDate + "\x00" + strconv.FormatBool(item.Overdue)
+		switch {
+		case notified[key]:
+		case item.Overdue:
+			overdue = append(overdue, item)
+		default:
+			upcoming = append(upcoming, item)
+		}
+		notified[key] = true
+	}
+
+	var errs []error
+	overdueText := "Past their sunset: " + sunsetList(overdue)
+	if len(toMove) > 0 {
+		moved, err := moveSunsetItems(ctx, toMove, cfg.MoveTo)
+		var pr *PullRequest
+		switch {
+		case errors.As(err, &pr):
+			for _, item := range moved {
+				notified[sunsetMoveKey(item)] = true
+			}
+			slog.Info("Opened a pull request moving items past their sunset", "ring", cfg.MoveTo, "items", sunsetList(moved), "url", pr.URL)
+			overdueText += fmt.Sprintf(". Pull request moving to %s: %s", cfg.MoveTo, pr.URL)
+		case err != nil:
+			errs = append(errs, fmt.Errorf("moving items to %s: %w", cfg.MoveTo, err))
+		case len(moved) > 0:
+			slog.Info("Moved items past their sunset", "ring", cfg.MoveTo, "items", sunsetList(moved))
+			overdueText += fmt.Sprintf(". Moved to %s: %s", cfg.MoveTo, sunsetList(moved))
+		}
+	}
+	for _, n := range []struct {
+		event, text string
+		items       []SunsetItem
+	}{
+		{sunsetOverdue, overdueText, overdue},
+		{sunsetUpcoming, fmt.Sprintf("Sunset within %d days: %s", noticeDays(cfg), sunsetList(upcoming)), upcoming},
+	} {
+		if len(n.items) == 0 {
+			continue
+		}
+		slog.Warn(n.text, "event", n.event)
+		if !notifications.enabled() {
+			continue
+		}
+		items := make([]RadarItem, len(n.items))
+		for i, item := range n.items {
+			items[i] = item.RadarItem
+		}
+		if err := notify(ctx, notifications, Notification{Event: n.event, Text: n.text, Items: items}); err != nil {
+			errs = append(errs, fmt.Errorf("notifying: %w", err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// runSunsetChecks runs checkSunsets every cfg.Sunset.Interval, starting
+// now, until ctx is done. Items are notified of again after a restart.
+func runSunsetChecks(ctx context.Context, cfg Config) {
+	notified := make(map[string]bool)
+	for {
+		if err := checkSunsets(ctx, cfg.Sunset, cfg.Notifications, notified, time.Now()); err != nil {
+			slog.Error("Checking sunsets failed", "err", err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(cfg.Sunset.Interval):
+		}
+	}
+}
@@ -0,0 +1,182 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// sunsetRadar holds items with sunsets around 2024-05-10.
+const sunsetRadar = `Items:
+- Label: Go
+  Quadrant: Tools
+  Ring: Adopted
+- Label: Perl
+  Quadrant: Tools
+  Ring: Adopted
+  SunsetDate: "2024-05-01"
+- Label: Ruby
+  Quadrant: Tools
+  Ring: In Discovery
+  SunsetDate: "2024-05-25"
+- Label: Zig
+  Quadrant: Tools
+  Ring: In Discovery
+  SunsetDate: "2024-12-01"
+`
+
+func TestSunsetItems(t *testing.T) {
+	data, err := decodeRadarData("radar.yaml", []byte(sunsetRadar))
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Date(2024, 5, 10, 15, 0, 0, 0, time.UTC)
+	sunsets := sunsetItems(data.Items, now, 30)
+	var got []string
+	for _, s := range sunsets {
+		got = append(got, s.Label)
+	}
+	if !reflect.DeepEqual(got, []string{"Perl", "Ruby"}) {
+		t.Fatalf("sunsetItems() = %v", got)
+	}
+	if s := sunsets[0]; !s.Overdue || s.DaysLeft != -9 {
+		t.Errorf("overdue sunset = %+v", s)
+	}
+	if s := sunsets[1]; s.Overdue || s.DaysLeft != 15 {
+		t.Errorf("upcoming sunset = %+v", s)
+	}
+	if all := sunsetItems(data.Items, now, 365); len(all) != 3 {
+		t.Errorf("sunsetItems() within a year = %+v", all)
+	}
+}
+
+func TestCheckSunsets(t *testing.T) {
+	var mu sync.Mutex
+	var received []Notification
+	var slack []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		if r.URL.Path == "/slack" {
+			var body struct{ Text string }
+			json.NewDecoder(r.Body).Decode(&body)
+			slack = append(slack, body.Text)
+			return
+		}
+		var n Notification
+		json.NewDecoder(r.Body).Decode(&n)
+		received = append(received, n)
+	}))
+	t.Cleanup(server.Close)
+
+	cfg := defaultConfig()
+	cfg.Data.Path = writeFile(t, "radar.yaml", sunsetRadar)
+	useConfig(t, cfg)
+	useStore(t, newFileStore(cfg.Data.Path))
+	sunset := SunsetConfig{Interval: time.Hour, Notice: 30 * 24 * time.Hour, MoveTo: "Not Recommended"}
+	notifications := NotificationConfig{Webhook: server.URL + "/hook", Slack: server.URL + "/slack"}
+	notified := make(map[string]bool)
+	now := time.Date(2024, 5, 10, 0, 0, 0, 0, time.UTC)
+	if err := checkSunsets(context.Background(), sunset, notifications, notified, now); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := loadRadarData()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if perl, _ := findItem(data.Items, "perl"); perl.Ring != "Not Recommended" {
+		t.Errorf("overdue item = %+v, want it moved", perl)
+	}
+	if ruby, _ := findItem(data.Items, "ruby"); ruby.Ring != "In Discovery" {
+		t.Errorf("upcoming item = %+v, want it left alone", ruby)
+	}
+	if len(received) != 2 || received[0].Event != sunsetOverdue || received[1].Event != sunsetUpcoming {
+		t.Fatalf("notifications = %+v", received)
+	}
+	if n := received[0]; len(n.Items) != 1 || n.Items[0].ID != "perl" || n.Text != "Past their sunset: Perl (2024-05-01). Moved to Not Recommended: Perl (2024-05-01)" {
+		t.Errorf("overdue notification = %+v", n)
+	}
+	if len(slack) != 2 || slack[1] != "Sunset within 30 days: Ruby (2024-05-25)" {
+		t.Errorf("Slack messages = %q", slack)
+	}
+
+	// Items are notified of once, and again when they become overdue.
+	if err := checkSunsets(context.Background(), sunset, notifications, notified, now.AddDate(0, 0, 1)); err != nil {
+		t.Fatal(err)
+	}
+	if len(received) != 2 {
+		t.Errorf("notifications after the next check = %+v", received[2:])
+	}
+	if err := checkSunsets(context.Background(), sunset, notifications, notified, now.AddDate(0, 0, 15)); err != nil {
+		t.Fatal(err)
+	}
+	if len(received) != 3 || received[2].Event != sunsetOverdue || received[2].Items[0].ID != "ruby" {
+		t.Errorf("notifications once Ruby is overdue = %+v", received)
+	}
+}
+
+func TestCheckSunsetsPullRequest(t *testing.T) {
+	repo, commit := gitRadarRepo(t)
+	commit(sunsetRadar)
+	github := &fakeGitHub{}
+	server := httptest.NewServer(github)
+	t.Cleanup(server.Close)
+
+	cfg := defaultConfig()
+	cfg.Data.Git.URL = repo
+	cfg.Data.Git.Dir = filepath.Join(t.TempDir(), "checkout")
+	cfg.Data.Git.PullRequests = PullRequestConfig{Repo: "acme/radar", Token: "gh-token", APIURL: server.URL}
+	if err := prepareGitData(cfg.Data); err != nil {
+		t.Fatal(err)
+	}
+	useConfig(t, cfg)
+	useStore(t, newGitStore(cfg.Data))
+	sunset := SunsetConfig{Interval: time.Hour, Notice: 30 * 24 * time.Hour, MoveTo: "Not Recommended"}
+	notified := make(map[string]bool)
+	now := time.Date(2024, 5, 10, 0, 0, 0, 0, time.UTC)
+	if err := checkSunsets(context.Background(), sunset, NotificationConfig{}, notified, now); err != nil {
+		t.Fatalf("checkSunsets() opening a pull request = %v, want success", err)
+	}
+	if github.pull == nil || github.pull["title"] != "Sunset perl" {
+		t.Fatalf("pull request opened = %+v", github.pull)
+	}
+
+	// Perl is still overdue until the pull request is merged, but isn't
+	// moved again meanwhile.
+	github.pull = nil
+	if err := checkSunsets(context.Background(), sunset, NotificationConfig{}, notified, now.AddDate(0, 0, 1)); err != nil {
+		t.Fatal(err)
+	}
+	if github.pull != nil {
+		t.Errorf("pull request opened at the next check = %+v", github.pull)
+	}
+}
+
+func TestSunsetsAPI(t *testing.T) {
+	cfg := defaultConfig()
+	cfg.Data.Path = writeFile(t, "radar.yaml", strings.ReplaceAll(sunsetRadar, "2024-", "2999-"))
+	useConfig(t, cfg)
+	handler, err := setupRoutes(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rec := doRequest(t, handler, http.MethodGet, "/api/v1/sunsets?days=1000000")
+	var body struct{ Items []SunsetItem }
+	if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil || rec.Code != http.StatusOK || len(body.Items) != 3 || body.Items[0].Label != "Perl" {
+		t.Errorf("GET /api/v1/sunsets?days=1000000 = %d %s", rec.Code, rec.Body)
+	}
+	if rec := doRequest(t, handler, http.MethodGet, "/api/v1/sunsets"); rec.Code != http.StatusOK || strings.Contains(rec.Body.String(), "Perl") {
+		t.Errorf("GET /api/v1/sunsets = %d %s, want sunsets within the notice only", rec.Code, rec.Body)
+	}
+	if rec := doRequest(t, handler, http.MethodGet, "/api/v1/sunsets?days=soon"); rec.Code != http.StatusBadRequest {
+		t.Errorf("GET /api/v1/sunsets?days=soon = %d, want 400", rec.Code)
+	}
+}
@@ -0,0 +1,257 @@
+package main
+
+import (
+	"cmp"
+	"fmt"
+	"html"
+	"math"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+)
+
+// Layout of the SVG radar, in pixels, after the client-side radar.
+const (
+	svgRadius      = 320
+	svgMargin      = 70
+	svgBlipRadius  = 9
+	svgBlipSpacing = 24
+	svgLegendWidth = 230
+	svgLegendLine  = 15
+	svgLegendTop   = 30
+	svgRingStroke  = "#ddd"
+	svgQuadLine    = "#aaa"
+	svgRingLabel   = "#666"
+	svgText        = "#333"
+)
+
+// svgBlip is an item placed on the SVG radar.
+type svgBlip struct {
+	item   RadarItem
+	number int
+	color  string
+	moved  int
+	x, y   float64
+}
+
+// svgCellPositions returns the positions of n blips in the cell of the
+// radar between the radii inner and outer and the angles from start over
+// slice. Blips are dealt to rows from the outer edge inwards in turn, each
+// row taking as many as fit along its arc until every row is full, and the
+// rest overlapping.
+func svgCellPositions(n int, inner, outer, start, slice float64) [][2]float64 {
+	pad := float64(svgBlipRadius + 5)
+	band := outer - inner - 2*pad
+	rows := 1
+	if band > 0 {
+		rows = int(band/svgBlipSpacing) + 1
+	}
+	radii := make([]float64, rows)
+	capacity := make([]int, rows)
+	for i := range radii {
+		radii[i] = (inner + outer) / 2
+		if rows > 1 {
+			radii[i] = outer - pad - float64(i)*band/float64(rows-1)
+		}
+		capacity[i] = max(1, int(radii[i]*slice*0.84/svgBlipSpacing))
+	}
+	total := 0
+	for _, c := range capacity {
+		total += c
+	}
+	counts := make([]int, rows)
+	for i, left := 0, n; left > 0; i = (i + 1) % rows {
+		if counts[i] < capacity[i] || n > total {
+			counts[i]++
+			left--
+		}
+	}
+
+	var positions [][2]float64
+	for i, count := range counts {
+		for t := range count {
+			angle := start + slice*(0.08+0.84*(float64(t)+0.5)/float64(count))
+			positions = append(positions, [2]float64{math.Cos(angle) * radii[i], math.Sin(angle) * radii[i]})
+		}
+	}
+	return positions
+}
+
+// svgWrap splits s at spaces into lines of at most n characters, or longer
+// when a word is.
+func svgWrap(s string, n int) []string {
+	var lines []string
+	for _, word := range strings.Fields(s) {
+		if last := len(lines) - 1; last >= 0 && len(lines[last])+1+len(word) <= n {
+			lines[last] += " " + word
+		} else {
+			lines = append(lines, word)
+		}
+	}
+	return lines
+}
+
+// radarSVG returns the SVG radar chart of items in the quadrants and rings
+// of data: the rings from the innermost out, the quadrants clockwise from
+// the top, a numbered blip for each item, a triangle pointing to the center
+// for items moved inwards and away from it for those moved outwards, and a
+// legend listing the items by number. Blips link to page followed by the ID
+// of their item.
+func radarSVG(data RadarData, items []RadarItem, changesByID map[string][]ItemChange, page string) string {
+	rings, quadrants := data.Rings, data.Quadrants
+	ringIndex := func(name string) int { return slices.IndexFunc(rings, func(s Segment) bool { return s.Name == name }) }
+	quadrantIndex := func(name string) int {
+		return slices.IndexFunc(quadrants, func(s Segment) bool { return s.Name == name })
+	}
+	slice := 2 * math.Pi / float64(len(quadrants))
+	quadrantStart := func(i int) float64 { return float64(i)*slice - math.Pi/2 }
+	ringRadius := func(i int) float64 { return float64(i) * svgRadius / float64(len(rings)) }
+
+	// Blips are numbered by quadrant, then ring, in the order of items.
+	var blips []svgBlip
+	for q := range quadrants {
+		for r := range rings {
+			var cell []RadarItem
+			for _, item := range items {
+				if quadrantIndex(item.Quadrant) == q && ringIndex(item.Ring) == r {
+					cell = append(cell, item)
+				}
+			}
+			for i, position := range svgCellPositions(len(cell), ringRadius(r), ringRadius(r+1), quadrantStart(q), slice) {
+				blips = append(blips, svgBlip{
+					item:   cell[i],
+					number: len(blips) + 1,
+					color:  segmentColor(rings, r),
+					moved:  zalandoMoved(cell[i], changesByID[cell[i].ID], rings),
+					x:      position[0],
+					y:      position[1],
+				})
+			}
+		}
+	}
+
+	// The legend flows down columns as tall as the radar: a key to the
+	// rings and markers, then the items of each quadrant.
+	type legendLine struct {
+		text, color string
+		bold        bool
+		blip        *svgBlip
+	}
+	legend := []legendLine{{text: "Rings", bold: true}}
+	for i, ring := range rings {
+		legend = append(legend, legendLine{text: ring.Name, color: segmentColor(rings, i)})
+	}
+	legend = append(legend, legendLine{text: "▲ moved in, ▼ moved out"}, legendLine{})
+	for q, quadrant := range quadrants {
+		legend = append(legend, legendLine{text: quadrant.Name, bold: true})
+		for i := range blips {
+			if quadrantIndex(blips[i].item.Quadrant) == q {
+				legend = append(legend, legendLine{text: fmt.Sprintf("%d. %s", blips[i].number, blips[i].item.Label), blip: &blips[i]})
+			}
+		}
+		legend = append(legend, legendLine{})
+	}
+	size := 2 * (svgRadius + svgMargin)
+	perColumn := (size - svgLegendTop) / svgLegendLine
+	columns := (len(legend) + perColumn - 1) / perColumn
+	width := size + columns*svgLegendWidth + 20
+
+	var b strings.Builder
+	fmt.Fprintf(&b, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %d %d" font-family="sans-serif">`+"\n", width, size, width, size)
+	b.WriteString("<title>Clean Tech Radar</title>\n")
+	fmt.Fprintf(&b, `<rect width="%d" height="%d" fill="#fff"/>`+"\n", width, size)
+	fmt.Fprintf(&b, `<g transform="translate(%d,%d)">`+"\n", size/2, size/2)
+	for r := len(rings) - 1; r >= 0; r-- {
+		fmt.Fprintf(&b, `<circle r="%.1f" fill="none" stroke="%s"/>`+"\n", ringRadius(r+1), svgRingStroke)
+	}
+	for q, quadrant := range quadrants {
+		angle := quadrantStart(q)
+		fmt.Fprintf(&b, `<line x2="%.1f" y2="%.1f" stroke="%s"/>`+"\n", math.Cos(angle)*svgRadius, math.Sin(angle)*svgRadius, svgQuadLine)
+		mid := angle + slice/2
+		x, y := math.Cos(mid)*(svgRadius+svgMargin/2), math.Sin(mid)*(svgRadius+svgMargin/2)
+		lines := svgWrap(quadrant.Name, 22)
+		fmt.Fprintf(&b, `<text x="%.1f" y="%.1f" text-anchor="middle" font-size="16" font-weight="bold" fill="%s">`, x, y-float64(len(lines)-1)*9, cmp.Or(quadrant.Color, svgText))
+		for i, line := range lines {
+			dy := "0"
+			if i > 0 {
+				dy = "1.2em"
+			}
+			fmt.Fprintf(&b, `<tspan x="%.1f" dy="%s">%s</tspan>`, x, dy, html.EscapeString(line))
+		}
+		b.WriteString("</text>\n")
+	}
+	for r, ring := range rings {
+		fmt.Fprintf(&b, `<text y="%.1f" text-anchor="middle" font-size="14" font-weight="bold" fill="%s" stroke="#fff" stroke-width="3" paint-order="stroke">%s</text>`+"\n", -ringRadius(r+1)+18, svgRingLabel, html.EscapeString(ring.Name))
+	}
+	for _, blip := range blips {
+		fmt.Fprintf(&b, `<a href="%s"><title>%s</title>`, html.EscapeString(page+url.PathEscape(blip.item.ID)), html.EscapeString(blip.item.Label+" – "+blip.item.Ring))
+		if blip.moved == 0 {
+			fmt.Fprintf(&b, `<circle cx="%.1f" cy="%.1f" r="%d" fill="%s"/>`, blip.x, blip.y, svgBlipRadius, blip.color)
+		} else {
+			// The triangle points along the radius, to the center when the
+			// item moved inwards.
+			angle := math.Atan2(blip.y, blip.x)
+			if blip.moved > 0 {
+				angle += math.Pi
+			}
+			var points []string
+			for _, corner := range []float64{0, 2 * math.Pi / 3, 4 * math.Pi / 3} {
+				points = append(points, fmt.Sprintf("%.1f,%.1f", blip.x+math.Cos(angle+corner)*(svgBlipRadius+3), blip.y+math.Sin(angle+corner)*(svgBlipRadius+3)))
+			}
+			fmt.Fprintf(&b, `<polygon points="%s" fill="%s"/>`, strings.Join(points, " "), blip.color)
+		}
+		fmt.Fprintf(&b, `<text x="%.1f" y="%.1f" text-anchor="middle" dominant-baseline="central" font-size="9" fill="#fff">%d</text></a>`+"\n", blip.x, blip.y, blip.number)
+	}
+	b.WriteString("</g>\n")
+
+	for i, line := range legend {
+		x := size + (i/perColumn)*svgLegendWidth
+		y := svgLegendTop + (i%perColumn)*svgLegendLine
+		switch {
+		case line.color != "":
+			fmt.Fprintf(&b, `<rect x="%d" y="%d" width="11" height="11" fill="%s"/>`, x, y-10, line.color)
+			fmt.Fprintf(&b, `<text x="%d" y="%d" font-size="12" fill="%s">%s</text>`+"\n", x+16, y, svgText, html.EscapeString(line.text))
+		case line.blip != nil:
+			decoration := ""
+			if line.blip.moved != 0 {
+				decoration = ` text-decoration="underline"`
+			}
+			fmt.Fprintf(&b, `<a href="%s"><text x="%d" y="%d" font-size="11" fill="%s"%s>%s</text></a>`+"\n", html.EscapeString(page+url.PathEscape(line.blip.item.ID)), x, y, svgText, decoration, html.EscapeString(line.text))
+		case line.text != "":
+			weight := "normal"
+			if line.bold {
+				weight = "bold"
+			}
+			fmt.Fprintf(&b, `<text x="%d" y="%d" font-size="12" font-weight="%s" fill="%s">%s</text>`+"\n", x, y, weight, svgText, html.EscapeString(line.text))
+		}
+	}
+	b.WriteString("</svg>\n")
+	return b.String()
+}
+
+// svgHandler serves the radar chart of the items selectItems selects as an
+// SVG image drawn on the server, which works without JavaScript and can be
+// embedded in wikis and READMEs.
+func svgHandler(w http.ResponseWriter, r *http.Request) {
+	data, err := loadRadarData()
+	if err != nil {
+		handleError(w, err)
+		return
+	}
+	data = withSegments(data)
+	items, err := selectItems(r, data)
+	if err != nil {
+		handleError(w, err)
+		return
+	}
+	changesByID, err := itemChangesByID(r.Context())
+	if err != nil {
+		handleError(w, &AppError{Code: http.StatusInternalServerError, Message: "Failed to read radar changes", Err: err})
+		return
+	}
+
+	page := requestOrigin(r) + currentConfig().Server.BasePath + "/#"
+	w.Header().Set("Content-Type", "image/svg+xml")
+	serveConditional(w, r, []byte(radarSVG(data, items, changesByID, page)), dataModTime(r.Context()))
+}
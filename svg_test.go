@@ -0,0 +1,93 @@
+package main
+
+import (
+	"encoding/xml"
+	"errors"
+	"io"
+	"math"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestSVGCellPositions(t *testing.T) {
+	inner, outer, start, slice := 100.0, 200.0, -math.Pi/2, math.Pi/2
+	positions := svgCellPositions(12, inner, outer, start, slice)
+	if len(positions) != 12 {
+		t.Fatalf("svgCellPositions() placed %d blips, want 12", len(positions))
+	}
+	for i, p := range positions {
+		r, angle := math.Hypot(p[0], p[1]), math.Atan2(p[1], p[0])
+		if r < inner || r > outer || angle < start || angle > start+slice {
+			t.Errorf("blip %d at r=%.1f angle=%.2f is outside its cell", i, r, angle)
+		}
+		for j, q := range positions[:i] {
+			if d := math.Hypot(p[0]-q[0], p[1]-q[1]); d < 2*svgBlipRadius {
+				t.Errorf("blips %d and %d overlap, %.1f apart", j, i, d)
+			}
+		}
+	}
+	if positions := svgCellPositions(500, inner, outer, start, slice); len(positions) != 500 {
+		t.Errorf("svgCellPositions() placed %d of 500 blips", len(positions))
+	}
+}
+
+func TestRadarSVG(t *testing.T) {
+	repo, commit := gitRadarRepo(t)
+	commit(radarWith("Go", "In Discovery") + "- Label: Perl\n  Quadrant: Techniques\n  Ring: Adopted\n")
+	commit(radarWith("Go", "Adopted") + "  Moved: true\n- Label: Perl & <Raku>\n  ID: perl\n  Quadrant: Techniques\n  Ring: Not Recommended\n")
+
+	cfg := defaultConfig()
+	cfg.Data.Path = filepath.Join(repo, "data", "radar.yaml")
+	useConfig(t, cfg)
+	handler, err := setupRoutes(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rec := doRequest(t, handler, http.MethodGet, "http://radar.example.com/radar.svg")
+	if rec.Code != http.StatusOK || rec.Header().Get("Content-Type") != "image/svg+xml" {
+		t.Fatalf("GET /radar.svg = %d %q", rec.Code, rec.Header().Get("Content-Type"))
+	}
+	body := rec.Body.String()
+	dec := xml.NewDecoder(strings.NewReader(body))
+	circles, polygons := 0, 0
+	for {
+		token, err := dec.Token()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			t.Fatalf("invalid SVG: %v\n%s", err, body)
+		}
+		if start, ok := token.(xml.StartElement); ok {
+			switch start.Name.Local {
+			case "circle":
+				circles++
+			case "polygon":
+				polygons++
+			}
+		}
+	}
+	// Three rings and Perl, with Go moved in as a triangle.
+	if circles != 4 || polygons != 1 {
+		t.Errorf("SVG has %d circles and %d polygons, want 4 and 1", circles, polygons)
+	}
+	for _, want := range []string{
+		`<a href="http://radar.example.com/#go"><title>Go – Adopted</title><polygon`,
+		`<text x="780" y="150" font-size="12" font-weight="bold" fill="#333">Tools</text>`,
+		`font-size="11" fill="#333" text-decoration="underline">1. Go</text>`,
+		`>2. Perl &amp; &lt;Raku&gt;</text>`,
+		`<tspan x="-251.0" dy="1.2em">&amp; Frameworks</tspan>`,
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("SVG doesn't contain %s", want)
+		}
+	}
+
+	rec = doRequest(t, handler, http.MethodGet, "/radar.svg?quadrant=Tools")
+	if strings.Contains(rec.Body.String(), "Perl") {
+		t.Error("GET /radar.svg?quadrant=Tools shows Perl")
+	}
+}
@@ -0,0 +1,172 @@
+package main
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// tagPattern is the form of tags once normalized, such as "frontend" or
+// "deprecated-2025".
+var tagPattern = regexp.MustCompile(`^[a-z0-9]+(-[a-z0-9]+)*$`)
+
+// Tags group radar items across quadrants and rings. Data files may list
+// them or give them as a comma-separated string; either way they are
+// trimmed, lowercased and deduplicated. In JSON they are always an array.
+type Tags []string
+
+// parseTags normalizes tags, splitting each on commas.
+func parseTags(values ...string) Tags {
+	var tags Tags
+	for _, value := range values {
+		for _, tag := range strings.Split(value, ",") {
+			tag = strings.ToLower(strings.TrimSpace(tag))
+			if tag != "" && !slices.Contains(tags, tag) {
+				tags = append(tags, tag)
+			}
+		}
+	}
+	return tags
+}
+
+func (t *Tags) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind == yaml.ScalarNode {
+		*t = parseTags(node.Value)
+		return nil
+	}
+	var values []string
+	if err := node.Decode(&values); err != nil {
+		return err
+	}
+	*t = parseTags(values...)
+	return nil
+}
+
+func (t *Tags) UnmarshalTOML(v any) error {
+	switch v := v.(type) {
+	case string:
+		*t = parseTags(v)
+	case []any:
+		values := make([]string, len(v))
+		for i, value := range v {
+			s, ok := value.(string)
+			if !ok {
+				return fmt.Errorf("tag %v is not a string", value)
+			}
+			values[i] = s
+		}
+		*t = parseTags(values...)
+	default:
+		return fmt.Errorf("tags must be a string or an array of strings, not %T", v)
+	}
+	return nil
+}
+
+func (t Tags) MarshalJSON() ([]byte, error) {
+	if t == nil {
+		return []byte("[]"), nil
+	}
+	return json.Marshal([]string(t))
+}
+
+func (t *Tags) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err == nil {
+		*t = parseTags(s)
+		return nil
+	}
+	var values []string
+	if err := json.Unmarshal(data, &values); err != nil {
+		return err
+	}
+	*t = parseTags(values...)
+	return nil
+}
+
+// Value stores the tags in a SQL text column as a JSON array.
+func (t Tags) Value() (driver.Value, error) {
+	if len(t) == 0 {
+		return "", nil
+	}
+	data, err := json.Marshal([]string(t))
+	return string(data), err
+}
+
+// Scan reads tags stored by Value.
+func (t *Tags) Scan(src any) error {
+	var s string
+	switch v := src.(type) {
+	case nil:
+	case string:
+		s = v
+	case []byte:
+		s = string(v)
+	default:
+		return fmt.Errorf("cannot scan %T into tags", src)
+	}
+	*t = nil
+	if s == "" {
+		return nil
+	}
+	var values []string
+	if err := json.Unmarshal([]byte(s), &values); err != nil {
+		return err
+	}
+	*t = parseTags(values...)
+	return nil
+}
+
+// hasTags reports whether item has every one of tags.
+func hasTags(item RadarItem, tags Tags) bool {
+	for _, tag := range tags {
+		if !slices.Contains(item.Tags, tag) {
+			return false
+		}
+	}
+	return true
+}
+
+// TagCount is a tag and the number of items that have it.
+type TagCount struct {
+	Tag   string `json:"tag"`
+	Count int    `json:"count"`
+}
+
+// countTags returns every tag of items with its number of items, sorted by
+// tag.
+func countTags(items []RadarItem) []TagCount {
+	counts := make(map[string]int)
+	for _, item := range items {
+		for _, tag := range item.Tags {
+			counts[tag]++
+		}
+	}
+	tags := make([]TagCount, 0, len(counts))
+	for tag, n := range counts {
+		tags = append(tags, TagCount{Tag: tag, Count: n})
+	}
+	sort.Slice(tags, func(i, j int) bool { return tags[i].Tag < tags[j].Tag })
+	return tags
+}
+
+// tagsHandler serves the tags in use by items that aren't archived, with
+// their number of items.
+func tagsHandler(w http.ResponseWriter, r *http.Request) {
+	data, err := loadRadarData()
+	if err != nil {
+		handleError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(map[string][]TagCount{"tags": countTags(visibleItems(data.Items))}); err != nil {
+		handleError(w, &AppError{Code: http.StatusInternalServerError, Message: "Failed to encode response", Err: err})
+	}
+}
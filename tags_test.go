@@ -0,0 +1,118 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestDecodeTags(t *testing.T) {
+	want := Tags{"frontend", "security"}
+	tests := []struct{ file, data string }{
+		{"radar.yaml", "Items:\n- Label: Go\n  Tags: [Frontend, security, frontend]\n"},
+		{"radar.yaml", "Items:\n- Label: Go\n  Tags: frontend, Security\n"},
+		{"radar.json", `{"Items": [{"Label": "Go", "Tags": ["frontend", " security "]}]}`},
+		{"radar.toml", "[[Items]]\nLabel = \"Go\"\nTags = [\"frontend\", \"security\"]\n"},
+		{"radar.toml", "[[Items]]\nLabel = \"Go\"\nTags = \"frontend,security\"\n"},
+	}
+	for _, tt := range tests {
+		data, err := decodeRadarData(tt.file, []byte(tt.data))
+		if err != nil || len(data.Items) != 1 || !reflect.DeepEqual(data.Items[0].Tags, want) {
+			t.Errorf("decodeRadarData(%s, %q) = %+v, %v, want tags %q", tt.file, tt.data, data, err, want)
+		}
+	}
+
+	if _, err := decodeRadarData("radar.yaml", []byte("Items:\n- Label: Go\n  Tags: {a: b}\n")); err == nil {
+		t.Error("decodeRadarData() accepted a mapping of tags")
+	}
+}
+
+func TestTagsJSONAndSQL(t *testing.T) {
+	out, err := json.Marshal(RadarItem{Label: "Go"})
+	if err != nil || !strings.Contains(string(out), `"tags":[]`) {
+		t.Errorf("json.Marshal() = %s, %v, want an empty tags array", out, err)
+	}
+	var tags Tags
+	if err := json.Unmarshal([]byte(`[]`), &tags); err != nil || tags != nil {
+		t.Errorf("json.Unmarshal([]) = %q, %v, want nil", tags, err)
+	}
+
+	value, err := Tags{"frontend", "security"}.Value()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := tags.Scan(value); err != nil || !reflect.DeepEqual(tags, Tags{"frontend", "security"}) {
+		t.Errorf("Scan(%v) = %q, %v", value, tags, err)
+	}
+	if err := tags.Scan(""); err != nil || tags != nil {
+		t.Errorf("Scan(\"\") = %q, %v, want nil", tags, err)
+	}
+}
+
+func TestValidateTags(t *testing.T) {
+	content := "Items:\n- Label: Go\n  Quadrant: Tools\n  Ring: Adopted\n  Tags:\n  - Frontend\n  - deprecated 2025\n"
+	err := validateRadarContent("radar.yaml", []byte(content), newValidationScope())
+	want := `radar.yaml:7: item "Go".Tags: invalid tag "deprecated 2025", must be letters and digits separated by single dashes`
+	if err == nil || err.Error() != want {
+		t.Errorf("validateRadarContent() = %v, want %s", err, want)
+	}
+}
+
+func TestTagsAPI(t *testing.T) {
+	cfg := defaultConfig()
+	cfg.Data.Path = writeFile(t, "radar.yaml", `Items:
+- Label: Go
+  Quadrant: Tools
+  Ring: Adopted
+  Tags: [backend, security]
+- Label: React
+  Quadrant: Tools
+  Ring: Adopted
+  Tags: [frontend]
+- Label: Vault
+  Quadrant: Tools
+  Ring: Adopted
+  Tags: [security]
+`)
+	useConfig(t, cfg)
+	handler, err := setupRoutes(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rec := doRequest(t, handler, http.MethodGet, "/api/tags")
+	var tags struct{ Tags []TagCount }
+	if err := json.Unmarshal(rec.Body.Bytes(), &tags); err != nil {
+		t.Fatalf("GET /api/tags = %d %q", rec.Code, rec.Body.String())
+	}
+	want := []TagCount{{"backend", 1}, {"frontend", 1}, {"security", 2}}
+	if !reflect.DeepEqual(tags.Tags, want) {
+		t.Errorf("GET /api/tags = %+v, want %+v", tags.Tags, want)
+	}
+
+	for query, labels := range map[string][]string{
+		"tag=security":             {"Go", "Vault"},
+		"tag=Security&tag=backend": {"Go"},
+		"tag=mobile":               nil,
+	} {
+		rec := doRequest(t, handler, http.MethodGet, "/api/radar?"+query)
+		var data RadarData
+		if err := json.Unmarshal(rec.Body.Bytes(), &data); err != nil {
+			t.Fatalf("GET /api/radar?%s = %d %q", query, rec.Code, rec.Body.String())
+		}
+		var got []string
+		for _, item := range data.Items {
+			got = append(got, item.Label)
+		}
+		if !reflect.DeepEqual(got, labels) {
+			t.Errorf("GET /api/radar?%s items = %q, want %q", query, got, labels)
+		}
+	}
+
+	// Filtering doesn't change the cached data.
+	if data, _ := loadRadarData(); len(data.Items) != 3 {
+		t.Errorf("loadRadarData() = %d items after filtering, want 3", len(data.Items))
+	}
+}
@@ -0,0 +1,59 @@
+package main
+
+import (
+	"crypto/tls"
+	"fmt"
+	"sync/atomic"
+
+	"golang.org/x/crypto/acme/autocert"
+)
+
+// activeCertificate is the certificate served over HTTPS, if TLS is enabled.
+// It is reloaded from disk on SIGHUP so renewed certificates take effect
+// without a restart.
+var activeCertificate atomic.Pointer[certificateFiles]
+
+// certificateFiles serves a certificate loaded from a cert and key file and
+// can re-read them on demand.
+type certificateFiles struct {
+	certFile string
+	keyFile  string
+	cert     atomic.Pointer[tls.Certificate]
+}
+
+// loadCertificateFiles reads the key pair from certFile and keyFile.
+func loadCertificateFiles(certFile, keyFile string) (*certificateFiles, error) {
+	c := &certificateFiles{certFile: certFile, keyFile: keyFile}
+	if err := c.reload(); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// reload re-reads the key pair. The previous certificate keeps being served
+// if the files cannot be loaded.
+func (c *certificateFiles) reload() error {
+	cert, err := tls.LoadX509KeyPair(c.certFile, c.keyFile)
+	if err != nil {
+		return fmt.Errorf("loading TLS certificate: %w", err)
+	}
+	c.cert.Store(&cert)
+	return nil
+}
+
+// getCertificate implements tls.Config.GetCertificate.
+func (c *certificateFiles) getCertificate(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	return c.cert.Load(), nil
+}
+
+// newACMEManager returns an autocert manager that obtains and renews
+// certificates for the configured domains and caches them on disk. The
+// certificate is fetched on the first TLS handshake for each domain.
+func newACMEManager(cfg ACMEConfig) *autocert.Manager {
+	return &autocert.Manager{
+		Prompt:     autocert.AcceptTOS,
+		HostPolicy: autocert.HostWhitelist(cfg.Domains...),
+		Cache:      autocert.DirCache(cfg.CacheDir),
+		Email:      cfg.Email,
+	}
+}
@@ -0,0 +1,141 @@
+package main
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// blameTimeout bounds the git blame run for a data file.
+const blameTimeout = 10 * time.Second
+
+// stampLastUpdated returns a copy of data in which the items that are new
+// or differ from the item with the same ID in previous are last updated at
+// now, and the others keep their previous LastUpdated.
+func stampLastUpdated(data RadarData, previous []RadarItem, now time.Time) RadarData {
+	before := make(map[string]RadarItem, len(previous))
+	for _, item := range previous {
+		before[item.ID] = item
+	}
+	data.Items = slices.Clone(data.Items)
+	for i := range data.Items {
+		item := &data.Items[i]
+		if prev, ok := before[item.ID]; !ok || !sameItem(prev, *item) {
+			item.LastUpdated = now
+		} else if item.LastUpdated.Before(prev.LastUpdated) {
+			item.LastUpdated = prev.LastUpdated
+		}
+	}
+	return data
+}
+
+// sameItem reports whether a and b have the same content, regardless of
+// when they were last updated, whether they are marked as moved, which
+// stampMoved works out, and which file they were loaded from.
+func sameItem(a, b RadarItem) bool {
+	a.LastUpdated, b.LastUpdated = time.Time{}, time.Time{}
+	a.Moved, a.Direction, a.PreviousRing = false, "", ""
+	b.Moved, b.Direction, b.PreviousRing = false, "", ""
+	a.Source, b.Source = "", ""
+	return reflect.DeepEqual(a, b)
+}
+
+// blameLastUpdated sets the LastUpdated of the items decoded from file to
+// the time of the newest commit that changed one of their lines, if that is
+// later than the time recorded in the file. Lines not committed yet count as
+// changed when the file was last modified. Files outside a Git work tree,
+// untracked files and TOML files, whose items have no line numbers, are
+// left alone.
+func blameLastUpdated(file string, content []byte, items []RadarItem) {
+	if dataFormat(file) == formatTOML || !inGitWorkTree(filepath.Dir(file)) {
+		return
+	}
+	doc, err := parseRadarNode(file, content)
+	if err != nil || len(doc.Content) == 0 {
+		return
+	}
+	nodes := mappingValue(doc.Content[0], "Items")
+	if nodes == nil || nodes.Kind != yaml.SequenceNode || len(nodes.Content) != len(items) {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), blameTimeout)
+	defer cancel()
+	out, err := gitOutput(ctx, filepath.Dir(file), "blame", "--line-porcelain", "--", filepath.Base(file))
+	if err != nil {
+		return
+	}
+	var modified time.Time
+	if info, err := os.Stat(file); err == nil {
+		modified = info.ModTime().UTC().Truncate(time.Second)
+	}
+	lines := parseBlame(string(out), modified)
+
+	for i := range items {
+		first, last := nodes.Content[i].Line, len(lines)
+		if i+1 < len(nodes.Content) {
+			last = nodes.Content[i+1].Line - 1
+		}
+		for line := first; line <= last && line <= len(lines); line++ {
+			if t := lines[line-1]; t.After(items[i].LastUpdated) {
+				items[i].LastUpdated = t
+			}
+		}
+	}
+}
+
+// parseBlame returns the commit time of every line of git blame
+// --line-porcelain output, using uncommitted for lines not committed yet.
+func parseBlame(out string, uncommitted time.Time) []time.Time {
+	var times []time.Time
+	header := false
+	for _, line := range strings.Split(out, "\n") {
+		if strings.HasPrefix(line, "\t") {
+			header = false
+			continue
+		}
+		fields := strings.Fields(line)
+		switch {
+		case !header && len(fields) >= 3 && len(fields[0]) == 40:
+			// Every line's header starts with the commit that last changed
+			// it, all zeros if it isn't committed yet.
+			header = true
+			var t time.Time
+			if strings.Trim(fields[0], "0") == "" {
+				t = uncommitted
+			}
+			times = append(times, t)
+		case header && len(fields) == 2 && fields[0] == "committer-time" && times[len(times)-1].IsZero():
+			if sec, err := strconv.ParseInt(fields[1], 10, 64); err == nil {
+				times[len(times)-1] = time.Unix(sec, 0).UTC()
+			}
+		}
+	}
+	return times
+}
+
+// inGitWorkTree reports whether dir or one of its parents holds a .git
+// directory or file, so git isn't run for data outside a repository.
+func inGitWorkTree(dir string) bool {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return false
+	}
+	for {
+		if _, err := os.Stat(filepath.Join(dir, ".git")); err == nil {
+			return true
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return false
+		}
+		dir = parent
+	}
+}
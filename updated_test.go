@@ -0,0 +1,129 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestStampLastUpdated(t *testing.T) {
+	then := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+	now := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+	previous := []RadarItem{
+		{ID: "go", Label: "Go", Ring: "Adopted", LastUpdated: then, Source: "radar.yaml"},
+		{ID: "rust", Label: "Rust", Ring: "In Discovery", LastUpdated: then},
+	}
+	data := RadarData{Items: []RadarItem{
+		{ID: "go", Label: "Go", Ring: "Adopted"},
+		{ID: "rust", Label: "Rust", Ring: "Adopted", LastUpdated: then},
+		{ID: "zig", Label: "Zig", Ring: "In Discovery"},
+	}}
+
+	got := stampLastUpdated(data, previous, now)
+	for i, want := range []time.Time{then, now, now} {
+		if !got.Items[i].LastUpdated.Equal(want) {
+			t.Errorf("%s last updated %v, want %v", got.Items[i].Label, got.Items[i].LastUpdated, want)
+		}
+	}
+	if !data.Items[0].LastUpdated.IsZero() {
+		t.Error("stampLastUpdated() changed the items it was given")
+	}
+}
+
+func TestFileStoreSaveStampsLastUpdated(t *testing.T) {
+	path := writeFile(t, "radar.toml", "[[Items]]\nLabel = \"Go\"\nQuadrant = \"Tools\"\nRing = \"Adopted\"\n")
+	store := newFileStore(path)
+	defer store.Close()
+
+	data := RadarData{Items: []RadarItem{{Label: "Go", Quadrant: "Tools", Ring: "In Discovery"}}}
+	if err := store.Save(context.Background(), data); err != nil {
+		t.Fatal(err)
+	}
+	saved, err := readRadarData(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if at := saved.Items[0].LastUpdated; time.Since(at) > time.Minute {
+		t.Errorf("saved item last updated %v, want now", at)
+	}
+
+	// Saving again without changes keeps the time, and items that never
+	// changed through a save have none.
+	saved.Items = append(saved.Items, RadarItem{ID: "rust", Label: "Rust", Quadrant: "Tools", Ring: "Adopted"})
+	if err := writeRadarData(path, saved); err != nil {
+		t.Fatal(err)
+	}
+	content, _ := os.ReadFile(path)
+	if strings.Count(string(content), "LastUpdated") != 1 {
+		t.Errorf("data file =\n%s\nwant one LastUpdated", content)
+	}
+	out, _ := json.Marshal(RadarItem{Label: "Rust"})
+	if strings.Contains(string(out), "lastUpdated") {
+		t.Errorf("json.Marshal() = %s, want no lastUpdated", out)
+	}
+}
+
+func TestParseBlame(t *testing.T) {
+	out := strings.Join([]string{
+		"1111111111111111111111111111111111111111 1 1 2",
+		"author Radar",
+		"committer-time 1704067200",
+		"filename radar.yaml",
+		"\tItems:",
+		"1111111111111111111111111111111111111111 2 2",
+		"committer-time 1704067200",
+		"\t- Label: Go",
+		"0000000000000000000000000000000000000000 3 3 1",
+		"committer-time 1893456000",
+		"\t  Ring: Adopted",
+		"",
+	}, "\n")
+	uncommitted := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+	got := parseBlame(out, uncommitted)
+	committed := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	if len(got) != 3 || !got[0].Equal(committed) || !got[1].Equal(committed) || !got[2].Equal(uncommitted) {
+		t.Errorf("parseBlame() = %v", got)
+	}
+}
+
+func TestReadRadarDataBlamesLastUpdated(t *testing.T) {
+	repo, commit := gitRadarRepo(t)
+	t.Setenv("GIT_COMMITTER_DATE", "2024-01-01T00:00:00Z")
+	commit(radarWith("Go", "Adopted") + "- Label: Rust\n  Quadrant: Tools\n  Ring: In Discovery\n")
+	t.Setenv("GIT_COMMITTER_DATE", "2024-06-01T00:00:00Z")
+	commit(radarWith("Go", "Adopted") + "- Label: Rust\n  Quadrant: Tools\n  Ring: Adopted\n")
+
+	path := filepath.Join(repo, "data", "radar.yaml")
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	content = append(content, "- Label: Zig\n  Quadrant: Tools\n  Ring: In Discovery\n  LastUpdated: 2030-01-01T00:00:00Z\n"...)
+	if err := os.WriteFile(path, content, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	modified := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	if err := os.Chtimes(path, modified, modified); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := readRadarData(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]time.Time{
+		"Go":   time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+		"Rust": time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC),
+		// The recorded time is kept when it is newer than the file.
+		"Zig": time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC),
+	}
+	for _, item := range data.Items {
+		if !item.LastUpdated.Equal(want[item.Label]) {
+			t.Errorf("%s last updated %v, want %v", item.Label, item.LastUpdated, want[item.Label])
+		}
+	}
+}
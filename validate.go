@@ -0,0 +1,606 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net/mail"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v3"
+)
+
+// Rules a ValidationError may break, reported with it so tools can tell
+// problems apart without parsing messages.
+const (
+	ruleSyntax            = "syntax"
+	ruleSchema            = "schema"
+	ruleRequired          = "required"
+	ruleAllowedValue      = "allowed-value"
+	ruleUnique            = "unique"
+	ruleFormat            = "format"
+	ruleURL               = "url"
+	ruleOwnerFormat       = "owner-format"
+	ruleDescriptionLength = "description-length"
+	ruleRingMove          = "ring-move"
+	ruleJustification     = "justification"
+	ruleConsistent        = "consistent-segments"
+	ruleRelation          = "relation"
+)
+
+// maxDescriptionLength is the longest description an item may have, in
+// characters. Longer texts belong in a linked document.
+const maxDescriptionLength = 5000
+
+// ValidationError describes a single problem found in a radar data file:
+// where it is, the rule it breaks and what is wrong.
+type ValidationError struct {
+	File    string `json:"file,omitempty"`
+	Line    int    `json:"line,omitempty"`
+	Field   string `json:"field,omitempty"`
+	Rule    string `json:"rule"`
+	Message string `json:"message"`
+}
+
+func (e ValidationError) Error() string {
+	var b strings.Builder
+	b.WriteString(e.File)
+	if e.Line > 0 {
+		fmt.Fprintf(&b, ":%d", e.Line)
+	}
+	// Data saved through the API has no file.
+	if e.Field != "" {
+		if b.Len() > 0 {
+			b.WriteString(": ")
+		}
+		b.WriteString(e.Field)
+	}
+	if b.Len() > 0 {
+		b.WriteString(": ")
+	}
+	b.WriteString(e.Message)
+	return b.String()
+}
+
+// ValidationErrors collects every problem found in a radar data file.
+type ValidationErrors []ValidationError
+
+func (e ValidationErrors) Error() string {
+	lines := make([]string, len(e))
+	for i, err := range e {
+		lines[i] = err.Error()
+	}
+	return strings.Join(lines, "\n")
+}
+
+// labelLocation records where a label was first defined.
+type labelLocation struct {
+	file string
+	line int
+}
+
+// validationScope is shared by the data files validated together.
+type validationScope struct {
+	// seen records where each label and ID was first defined.
+	seen map[string]labelLocation
+	// quadrants and rings are those declared by any of the files, which
+	// apply to the items of files that don't declare their own.
+	quadrants, rings []Segment
+}
+
+// newValidationScope returns an empty validationScope.
+func newValidationScope() *validationScope {
+	return &validationScope{seen: make(map[string]labelLocation)}
+}
+
+// validateRadarData checks every data file the configured data path resolves
+// to, returning ValidationErrors listing all problems with their file and
+// line numbers, or nil if the data is valid. Labels must be unique across
+// all files, and the quadrants and rings declared by one file apply to all.
+func validateRadarData(path string) error {
+	if isRemoteDataPath(path) {
+		name := remoteDataName(path)
+		content, _, err := fetchRemoteData(context.Background(), path, "")
+		if err != nil {
+			return fmt.Errorf("%s: %w", name, err)
+		}
+		if err := validateRadarContent(name, content, newValidationScope()); err != nil {
+			return err
+		}
+		data, err := decodeRadarData(name, content)
+		if err != nil {
+			return err
+		}
+		return relationErrors(data.Items)
+	}
+
+	files, err := dataFiles(path)
+	if err != nil {
+		return err
+	}
+
+	var errs ValidationErrors
+	scope := newValidationScope()
+	var segments segmentDeclarations
+	var items []RadarItem
+	for _, file := range files {
+		// Files that fail to decode are reported below.
+		content, err := readDataFile(file)
+		if err != nil {
+			return err
+		}
+		if data, err := decodeRadarData(file, content); err == nil {
+			errs = append(errs, segments.add(file, data)...)
+			for _, item := range data.Items {
+				item.Source = file
+				items = append(items, item)
+			}
+		}
+	}
+	scope.quadrants, scope.rings = segments.quadrants, segments.rings
+	for _, file := range files {
+		err := validateRadarFile(file, scope)
+		var fileErrs ValidationErrors
+		if err != nil && !errors.As(err, &fileErrs) {
+			return err
+		}
+		errs = append(errs, fileErrs...)
+	}
+	if len(errs) > 0 {
+		return errs
+	}
+	return relationErrors(items)
+}
+
+// relationErrors returns the problems checkRelations finds with the
+// relations of items, which are given their IDs first, or nil if there are
+// none. Items relate to those of any data file.
+func relationErrors(items []RadarItem) error {
+	data, err := withItemIDs(RadarData{Items: items}, nil)
+	if err != nil {
+		return err
+	}
+	if errs := checkRelations(data.Items); len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+// validateSavedData checks data about to be saved by a Store with the same
+// rules as the data files. The data has no file, so the problems found have
+// no file or line.
+func validateSavedData(data RadarData) error {
+	var doc yaml.Node
+	if err := doc.Encode(storedData(data)); err != nil {
+		return err
+	}
+	scope := newValidationScope()
+	scope.quadrants, scope.rings = data.quadrants(), data.rings()
+	if errs := validateRadarNode("", &doc, scope); len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+// validateRadarFile parses a single radar data file and checks every item.
+// Labels are recorded in scope so duplicates across files are detected.
+func validateRadarFile(path string, scope *validationScope) error {
+	file, err := readDataFile(path)
+	if err != nil {
+		return err
+	}
+	return validateRadarContent(path, file, scope)
+}
+
+// validateRadarContent checks the content of a radar data file named path.
+func validateRadarContent(path string, file []byte, scope *validationScope) error {
+	doc, err := parseRadarNode(path, file)
+	if err != nil {
+		return ValidationErrors{syntaxError(path, err)}
+	}
+	if _, err := migrateRadarNode(path, doc); err != nil {
+		return err
+	}
+
+	var errs ValidationErrors
+	if _, err := decodeRadarData(path, file); err != nil && !errors.As(err, &errs) {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+	// The structural checks below report the same problems as the decoder
+	// with more context, so drop decoder errors they duplicate.
+	for _, nodeErr := range validateRadarNode(path, doc, scope) {
+		errs = slices.DeleteFunc(errs, func(e ValidationError) bool {
+			return e.Field == "" && e.Line == nodeErr.Line && e.Message == nodeErr.Message
+		})
+		errs = append(errs, nodeErr)
+	}
+	if len(errs) > 0 {
+		slices.SortStableFunc(errs, func(a, b ValidationError) int { return a.Line - b.Line })
+		return errs
+	}
+	return nil
+}
+
+// syntaxErrorLine matches the line number in the syntax errors of the YAML,
+// JSON and TOML parsers.
+var syntaxErrorLine = regexp.MustCompile(`^(?:yaml: |toml: )?line (\d+)(?:, column \d+)?: (.*)$`)
+
+// syntaxError converts the error parsing file into a ValidationError,
+// extracting its line number.
+func syntaxError(file string, err error) ValidationError {
+	verr := ValidationError{File: file, Rule: ruleSyntax, Message: err.Error()}
+	if m := syntaxErrorLine.FindStringSubmatch(err.Error()); m != nil {
+		verr.Line, _ = strconv.Atoi(m[1])
+		verr.Message = m[2]
+	}
+	return verr
+}
+
+// parseRadarNode parses a data file into a YAML node tree so every format
+// shares the same structural checks. JSON is parsed as YAML and keeps its
+// line numbers; TOML is converted and its nodes carry no line numbers.
+func parseRadarNode(file string, content []byte) (*yaml.Node, error) {
+	var doc yaml.Node
+	switch dataFormat(file) {
+	case formatTOML:
+		var raw map[string]any
+		if _, err := toml.Decode(string(content), &raw); err != nil {
+			return nil, err
+		}
+		if err := doc.Encode(raw); err != nil {
+			return nil, err
+		}
+		return &doc, nil
+	case formatJSON:
+		if err := checkJSONSyntax(content); err != nil {
+			return nil, err
+		}
+	}
+	if err := yaml.Unmarshal(content, &doc); err != nil && !errors.Is(err, io.EOF) {
+		return nil, err
+	}
+	return &doc, nil
+}
+
+// validateRadarNode checks a parsed radar document for invalid quadrant and
+// ring definitions, items in missing or undefined quadrants and rings,
+// missing labels, malformed IDs, tags, link URLs and review dates, owners
+// without a name or with an invalid email address, and labels and IDs
+// already recorded in scope.
+func validateRadarNode(file string, doc *yaml.Node, scope *validationScope) ValidationErrors {
+	seen := scope.seen
+	var errs ValidationErrors
+	report := func(node *yaml.Node, field, rule, format string, args ...any) {
+		errs = append(errs, ValidationError{File: file, Line: node.Line, Field: field, Rule: rule, Message: fmt.Sprintf(format, args...)})
+	}
+
+	root := doc
+	if root.Kind == yaml.DocumentNode && len(root.Content) > 0 {
+		root = root.Content[0]
+	}
+	if root.Kind != yaml.MappingNode {
+		report(root, "", ruleSchema, "expected a mapping with LastModified and Items")
+		return errs
+	}
+
+	checkDate := func(node *yaml.Node, key, field string) {
+		if date := mappingValue(node, key); date != nil && !validDate(date.Value) {
+			report(date, field, ruleFormat, "invalid date %q, must look like 2025-03-31", date.Value)
+		}
+	}
+	checkDate(root, "ReviewDate", "ReviewDate")
+
+	quadrants := validateSegmentsNode(root, "Quadrants", scope.quadrants, defaultQuadrants, report)
+	rings := validateSegmentsNode(root, "Rings", scope.rings, fallbackRings(), report)
+
+	items := mappingValue(root, "Items")
+	if items == nil {
+		// A file may only declare the quadrants and rings of the others.
+		if mappingValue(root, "Quadrants") == nil && mappingValue(root, "Rings") == nil {
+			report(root, "Items", ruleRequired, "missing")
+		}
+		return errs
+	}
+	if items.Kind != yaml.SequenceNode {
+		report(items, "Items", ruleSchema, "expected a list of items")
+		return errs
+	}
+
+	for i, item := range items.Content {
+		if item.Kind != yaml.MappingNode {
+			report(item, fmt.Sprintf("Items[%d]", i), ruleSchema, "expected a mapping")
+			continue
+		}
+
+		label := mappingValue(item, "Label")
+		name := fmt.Sprintf("Items[%d]", i)
+		if label == nil || strings.TrimSpace(label.Value) == "" {
+			report(item, name+".Label", ruleRequired, "missing")
+		} else {
+			name = fmt.Sprintf("item %q", label.Value)
+			key := labelKey(label.Value)
+			switch first, ok := seen[key]; {
+			case !ok:
+				seen[key] = labelLocation{file: file, line: label.Line}
+			case first.file == file:
+				report(label, name+".Label", ruleUnique, "duplicate label, first defined on line %d", first.line)
+			default:
+				report(label, name+".Label", ruleUnique, "duplicate label, first defined in %s:%d", first.file, first.line)
+			}
+		}
+
+		checkChoice := func(field string, allowed []string) {
+			node, value := item, ""
+			if v := mappingValue(item, field); v != nil && strings.TrimSpace(v.Value) != "" {
+				node, value = v, v.Value
+			}
+			if msg := segmentChoiceError(field, value, allowed); msg != "" {
+				report(node, name+"."+field, segmentChoiceRule(value), "%s", msg)
+			}
+		}
+		checkChoice("Quadrant", quadrants)
+		checkChoice("Ring", rings)
+
+		if id := mappingValue(item, "ID"); id != nil {
+			switch first, ok := seen[idSeenKey(id.Value)]; {
+			case !itemIDPattern.MatchString(id.Value):
+				report(id, name+".ID", ruleFormat, "must be lowercase letters and digits separated by single dashes")
+			case !ok:
+				seen[idSeenKey(id.Value)] = labelLocation{file: file, line: id.Line}
+			case first.file == file:
+				report(id, name+".ID", ruleUnique, "duplicate ID, first used on line %d", first.line)
+			default:
+				report(id, name+".ID", ruleUnique, "duplicate ID, first used in %s:%d", first.file, first.line)
+			}
+		}
+
+		checkDate(item, "ReviewDate", name+".ReviewDate")
+		checkDate(item, "SunsetDate", name+".SunsetDate")
+
+		if relations := mappingValue(item, "Relations"); relations != nil && relations.Kind == yaml.SequenceNode {
+			for j, relation := range relations.Content {
+				if relation.Kind != yaml.MappingNode {
+					continue
+				}
+				field := fmt.Sprintf("%s.Relations[%d]", name, j)
+				if t := mappingValue(relation, "Type"); t == nil || !slices.Contains(relationTypes, t.Value) {
+					node, value := relation, ""
+					if t != nil {
+						node, value = t, t.Value
+					}
+					report(node, field+".Type", ruleAllowedValue, "unknown relation type %q, must be one of %s", value, strings.Join(relationTypes, ", "))
+				}
+				switch id := mappingValue(relation, "Item"); {
+				case id == nil || id.Value == "":
+					report(relation, field+".Item", ruleRequired, "missing")
+				case !itemIDPattern.MatchString(id.Value):
+					report(id, field+".Item", ruleFormat, "must be the ID of an item")
+				}
+			}
+		}
+		if dir := mappingValue(item, "Direction"); dir != nil && dir.Value != movedUp && dir.Value != movedDown {
+			report(dir, name+".Direction", ruleAllowedValue, "unknown direction %q, must be %s or %s", dir.Value, movedUp, movedDown)
+		}
+
+		if desc := mappingValue(item, "Description"); desc != nil {
+			if n := utf8.RuneCountInString(desc.Value); n > maxDescriptionLength {
+				report(desc, name+".Description", ruleDescriptionLength, "%d characters long, must be at most %d", n, maxDescriptionLength)
+			}
+		}
+
+		if tags := mappingValue(item, "Tags"); tags != nil {
+			values := []*yaml.Node{tags}
+			if tags.Kind == yaml.SequenceNode {
+				values = tags.Content
+			}
+			for _, value := range values {
+				if value.Kind != yaml.ScalarNode {
+					continue
+				}
+				for _, tag := range strings.Split(value.Value, ",") {
+					if tag = strings.ToLower(strings.TrimSpace(tag)); tag != "" && !tagPattern.MatchString(tag) {
+						report(value, name+".Tags", ruleFormat, "invalid tag %q, must be letters and digits separated by single dashes", tag)
+					}
+				}
+			}
+		}
+
+		if links := mappingValue(item, "Links"); links != nil && links.Kind == yaml.SequenceNode {
+			for j, link := range links.Content {
+				if link.Kind != yaml.MappingNode {
+					continue
+				}
+				field := fmt.Sprintf("%s.Links[%d].URL", name, j)
+				node, raw := link, ""
+				if u := mappingValue(link, "URL"); u != nil {
+					node, raw = u, u.Value
+				}
+				if err := checkLinkURL(raw); err != nil {
+					report(node, field, ruleURL, "%s", err)
+				}
+			}
+		}
+
+		if owners := mappingValue(item, "Owners"); owners != nil && owners.Kind == yaml.SequenceNode {
+			for j, owner := range owners.Content {
+				if owner.Kind != yaml.MappingNode {
+					continue
+				}
+				field := fmt.Sprintf("%s.Owners[%d]", name, j)
+				if n := mappingValue(owner, "Name"); n == nil || strings.TrimSpace(n.Value) == "" {
+					report(owner, field+".Name", ruleOwnerFormat, "missing")
+				}
+				if email := mappingValue(owner, "Email"); email != nil && email.Value != "" {
+					if _, err := mail.ParseAddress(email.Value); err != nil {
+						report(email, field+".Email", ruleOwnerFormat, "invalid email address %q", email.Value)
+					}
+				}
+			}
+		}
+	}
+	return errs
+}
+
+// validDate reports whether value is a date such as 2025-03-31.
+func validDate(value string) bool {
+	_, err := time.Parse(time.DateOnly, value)
+	return err == nil
+}
+
+// validateSegmentsNode checks the quadrants or rings declared under key in
+// root: every one needs a unique name and may have a hex color, and rings
+// may only move to declared rings. It returns the names items may use:
+// those declared, else those in scope, else the defaults.
+func validateSegmentsNode(root *yaml.Node, key string, scope, defaults []Segment, report func(node *yaml.Node, field, rule, format string, args ...any)) []string {
+	node := mappingValue(root, key)
+	if node == nil || node.Kind != yaml.SequenceNode {
+		// A value of the wrong type is reported by the decoder.
+		if len(scope) > 0 {
+			return segmentNames(scope)
+		}
+		return segmentNames(defaults)
+	}
+	if len(node.Content) == 0 {
+		report(node, key, ruleRequired, "must list at least one %s", strings.ToLower(strings.TrimSuffix(key, "s")))
+	}
+
+	var names []string
+	lines := make(map[string]int)
+	for i, segment := range node.Content {
+		if segment.Kind != yaml.MappingNode {
+			continue
+		}
+		field := fmt.Sprintf("%s[%d]", key, i)
+		n := mappingValue(segment, "Name")
+		if n == nil || strings.TrimSpace(n.Value) == "" {
+			report(segment, field+".Name", ruleRequired, "missing")
+		} else if first, ok := lines[labelKey(n.Value)]; ok {
+			report(n, field+".Name", ruleUnique, "duplicate name %q, first defined on line %d", n.Value, first)
+		} else {
+			lines[labelKey(n.Value)] = n.Line
+			names = append(names, n.Value)
+		}
+		if color := mappingValue(segment, "Color"); color != nil && !segmentColorPattern.MatchString(color.Value) {
+			report(color, field+".Color", ruleFormat, "invalid color %q, must be a hex color such as #00c000", color.Value)
+		}
+	}
+
+	for i, segment := range node.Content {
+		if justify := mappingValue(segment, "RequiresJustification"); justify != nil && key != "Rings" {
+			report(justify, fmt.Sprintf("%s[%d].RequiresJustification", key, i), ruleSchema, "only rings can require a justification")
+		}
+		moves := mappingValue(segment, "MovesTo")
+		if segment.Kind != yaml.MappingNode || moves == nil || moves.Kind != yaml.SequenceNode {
+			continue
+		}
+		field := fmt.Sprintf("%s[%d].MovesTo", key, i)
+		if key != "Rings" {
+			report(moves, field, ruleSchema, "only rings can restrict moves")
+			continue
+		}
+		for _, to := range moves.Content {
+			if !slices.Contains(names, to.Value) {
+				report(to, field, ruleAllowedValue, "unknown ring %q", to.Value)
+			}
+		}
+	}
+	return names
+}
+
+// runValidate implements the validate command, which checks the data paths
+// given as arguments, or the configured one, and lists every problem found
+// on stdout, as text or with -json as a JSON report. It fails if any data
+// is invalid.
+func runValidate(args []string, stdout io.Writer) error {
+	fs := flag.NewFlagSet("validate", flag.ContinueOnError)
+	asJSON := fs.Bool("json", false, "write a JSON report of the problems found")
+	configPath := fs.String("config", "", "path to a YAML config file giving the rings, encryption key and default data path (env RADAR_CONFIG)")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: clean-tech-radar validate [-json] [-config file] [data path...]")
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	var configArgs []string
+	if *configPath != "" {
+		configArgs = []string{"-config", *configPath}
+	}
+	cfg, err := loadConfig(configArgs)
+	if err != nil {
+		return err
+	}
+	configuredRings = ringSegments(cfg.Radar.Rings)
+	if err := useEncryptionKey(cfg); err != nil {
+		return err
+	}
+	paths := fs.Args()
+	if len(paths) == 0 {
+		paths = []string{cfg.Data.dataPath()}
+	}
+
+	errs := ValidationErrors{}
+	for _, path := range paths {
+		var pathErrs ValidationErrors
+		if err := validateRadarData(path); errors.As(err, &pathErrs) {
+			errs = append(errs, pathErrs...)
+		} else if err != nil {
+			return err
+		}
+	}
+
+	if *asJSON {
+		report := validationReport{Violations: errs}
+		if len(errs) > 0 {
+			report.Error = "Invalid radar data"
+		}
+		enc := json.NewEncoder(stdout)
+		enc.SetIndent("", "  ")
+		enc.SetEscapeHTML(false)
+		if err := enc.Encode(report); err != nil {
+			return err
+		}
+	} else {
+		for _, e := range errs {
+			fmt.Fprintf(stdout, "%v [%s]\n", e, e.Rule)
+		}
+		if len(errs) == 0 {
+			fmt.Fprintf(stdout, "Radar data in %s is valid\n", strings.Join(paths, ", "))
+		}
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("%d problems found", len(errs))
+	}
+	return nil
+}
+
+// idSeenKey returns the key an item ID is recorded under in the map of seen
+// labels. Label keys never contain a NUL byte, so the two can't clash.
+func idSeenKey(id string) string {
+	return "\x00" + id
+}
+
+// labelKey normalizes a label for duplicate detection.
+func labelKey(label string) string {
+	return strings.ToLower(strings.TrimSpace(label))
+}
+
+// mappingValue returns the value node for key in a YAML mapping node, or nil
+// if the key is absent.
+func mappingValue(mapping *yaml.Node, key string) *yaml.Node {
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		if mapping.Content[i].Value == key {
+			return mapping.Content[i+1]
+		}
+	}
+	return nil
+}
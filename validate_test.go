@@ -0,0 +1,215 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestValidateRadarFile(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+		want []string
+	}{
+		{
+			name: "valid",
+			data: "LastModified: today\nItems:\n- Label: Go\n  Quadrant: Tools\n  Ring: Adopted\n",
+		},
+		{
+			name: "unknown ring and quadrant",
+			data: "Items:\n- Label: Go\n  Quadrant: Gadgets\n  Ring: Adopt\n",
+			want: []string{
+				`:3: item "Go".Quadrant: unknown quadrant "Gadgets"`,
+				`:4: item "Go".Ring: unknown ring "Adopt"`,
+			},
+		},
+		{
+			name: "missing fields",
+			data: "Items:\n- Ring: Adopted\n",
+			want: []string{`:2: Items[0].Label: missing`, `:2: Items[0].Quadrant: missing`},
+		},
+		{
+			name: "duplicate labels ignore case",
+			data: "Items:\n- Label: Go\n  Quadrant: Tools\n  Ring: Adopted\n- Label: go \n  Quadrant: Tools\n  Ring: Adopted\n",
+			want: []string{`:5: item "go".Label: duplicate label, first defined on line 2`},
+		},
+		{
+			name: "decode and semantic errors are merged by line",
+			data: "Items:\n- Label: Go\n  Quadrant: Tools\n  Ring: Adopt\n  Moved: yes please\n",
+			want: []string{
+				`:4: item "Go".Ring: unknown ring "Adopt"`,
+				":5: invalid value `yes please`, expected bool",
+			},
+		},
+		{
+			name: "description too long",
+			data: "Items:\n- Label: Go\n  Quadrant: Tools\n  Ring: Adopted\n  Description: " + strings.Repeat("é", maxDescriptionLength+1) + "\n",
+			want: []string{`:5: item "Go".Description: 5001 characters long, must be at most 5000`},
+		},
+		{name: "missing items", data: "LastModified: today\n", want: []string{`:1: Items: missing`}},
+		{name: "items not a list", data: "Items: nope\n", want: []string{`:1: Items: expected a list of items`}},
+		{name: "item not a mapping", data: "Items:\n- Go\n", want: []string{`:2: Items[0]: expected a mapping`}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := writeFile(t, "radar.yaml", tt.data)
+			err := validateRadarData(path)
+			if len(tt.want) == 0 {
+				if err != nil {
+					t.Fatalf("validateRadarData() error = %v, want nil", err)
+				}
+				return
+			}
+
+			var errs ValidationErrors
+			if !errors.As(err, &errs) {
+				t.Fatalf("validateRadarData() error = %v, want ValidationErrors", err)
+			}
+			if len(errs) != len(tt.want) {
+				t.Fatalf("got %d errors, want %d:\n%v", len(errs), len(tt.want), err)
+			}
+			for i, want := range tt.want {
+				if got := errs[i].Error(); !strings.HasPrefix(got, path+want) {
+					t.Errorf("error %d = %q, want prefix %q", i, got, path+want)
+				}
+			}
+		})
+	}
+}
+
+func TestValidateRadarFileSyntaxError(t *testing.T) {
+	err := validateRadarData(writeFile(t, "radar.yaml", "Items: [\n"))
+	var errs ValidationErrors
+	if !errors.As(err, &errs) || len(errs) != 1 || errs[0].Rule != ruleSyntax || errs[0].Line != 1 {
+		t.Fatalf("validateRadarData() error = %#v, want a syntax error on line 1", err)
+	}
+}
+
+func TestBundledRadarDataIsValid(t *testing.T) {
+	if err := validateRadarData("data/radar.yaml"); err != nil {
+		t.Fatalf("data/radar.yaml is invalid:\n%v", err)
+	}
+}
+
+func TestValidateRadarDataAcrossFiles(t *testing.T) {
+	item := "Items:\n- Label: Go\n  Quadrant: Tools\n  Ring: Adopted\n"
+	dir := writeDataDir(t, map[string]string{"a.yaml": item, "b.yaml": item})
+
+	err := validateRadarData(dir)
+	var errs ValidationErrors
+	if !errors.As(err, &errs) || len(errs) != 1 {
+		t.Fatalf("validateRadarData() error = %v, want one duplicate", err)
+	}
+	want := filepath.Join(dir, "b.yaml") + ":2: item \"Go\".Label: duplicate label, first defined in " + filepath.Join(dir, "a.yaml") + ":2"
+	if got := errs[0].Error(); got != want {
+		t.Errorf("error = %q, want %q", got, want)
+	}
+}
+
+func TestValidateRadarDataFormats(t *testing.T) {
+	dir := writeDataDir(t, map[string]string{
+		"a.json": "{\n  \"Items\": [\n    {\"Label\": \"Go\", \"Quadrant\": \"Tools\", \"Ring\": \"Adopt\"}\n  ]\n}\n",
+		"b.toml": "[[Items]]\nLabel = \"go\"\nQuadrant = \"Tools\"\nRing = \"Adopted\"\n",
+	})
+
+	err := validateRadarData(dir)
+	var errs ValidationErrors
+	if !errors.As(err, &errs) {
+		t.Fatalf("validateRadarData() error = %v, want ValidationErrors", err)
+	}
+	want := []string{
+		filepath.Join(dir, "a.json") + `:3: item "Go".Ring: unknown ring "Adopt"`,
+		filepath.Join(dir, "b.toml") + `: item "go".Label: duplicate label, first defined in ` + filepath.Join(dir, "a.json") + ":3",
+	}
+	if len(errs) != len(want) {
+		t.Fatalf("got %d errors, want %d:\n%v", len(errs), len(want), err)
+	}
+	for i := range want {
+		if got := errs[i].Error(); !strings.HasPrefix(got, want[i]) {
+			t.Errorf("error %d = %q, want prefix %q", i, got, want[i])
+		}
+	}
+}
+
+func TestValidationRules(t *testing.T) {
+	err := validateRadarData(writeFile(t, "radar.yaml", "Items:\n- Label: Go\n  Quadrant: Gadgets\n  ID: Go!\n"+
+		"  Owners: [{Email: nobody}]\n  Links: [{URL: ftp://example.com}]\n  Moved: maybe\n- Label: go\n  Quadrant: Tools\n  Ring: Adopted\n"))
+	var errs ValidationErrors
+	if !errors.As(err, &errs) {
+		t.Fatalf("validateRadarData() error = %v, want ValidationErrors", err)
+	}
+	got := make(map[string]string)
+	for _, e := range errs {
+		got[e.Field] = e.Rule
+	}
+	want := map[string]string{
+		`item "Go".Quadrant`:        ruleAllowedValue,
+		`item "Go".Ring`:            ruleRequired,
+		`item "Go".ID`:              ruleFormat,
+		`item "Go".Owners[0].Name`:  ruleOwnerFormat,
+		`item "Go".Owners[0].Email`: ruleOwnerFormat,
+		`item "Go".Links[0].URL`:    ruleURL,
+		"":                          ruleSchema,
+		`item "go".Label`:           ruleUnique,
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("rules = %v, want %v", got, want)
+	}
+}
+
+func TestValidateSavedData(t *testing.T) {
+	cfg := defaultConfig()
+	cfg.Data.Path = writeFile(t, "radar.yaml", radarWith("Go", "Adopted"))
+	useConfig(t, cfg)
+	store := currentStore()
+
+	err := store.Save(context.Background(), RadarData{Items: []RadarItem{
+		{Label: "Go", Quadrant: "Tools", Ring: "Adopted", Links: Links{{URL: "javascript:alert(1)"}}},
+		{Label: "Rust", Quadrant: "Tools", Ring: "Adopt", Owners: Owners{{Name: "Ana", Email: "ana"}}},
+	}})
+	var errs ValidationErrors
+	if !errors.As(err, &errs) || len(errs) != 3 {
+		t.Fatalf("Save() error = %v, want 3 ValidationErrors", err)
+	}
+	for _, e := range errs {
+		if e.File != "" || e.Line != 0 || e.Rule == "" {
+			t.Errorf("error %#v, want a rule and no file or line", e)
+		}
+	}
+	if data, err := readRadarData(cfg.Data.Path); err != nil || len(data.Items) != 1 {
+		t.Errorf("data file items = %+v, %v, want them unchanged", data.Items, err)
+	}
+}
+
+func TestRunValidate(t *testing.T) {
+	clearRadarEnv(t)
+	valid := writeFile(t, "radar.yaml", radarWith("Go", "Adopted"))
+	invalid := writeFile(t, "bad.yaml", radarWith("Go", "Adopt"))
+
+	var out bytes.Buffer
+	if err := runValidate([]string{valid}, &out); err != nil || !strings.Contains(out.String(), "is valid") {
+		t.Errorf("runValidate(valid) = %v, output %q", err, out.String())
+	}
+
+	out.Reset()
+	err := runValidate([]string{valid, invalid}, &out)
+	if err == nil || !strings.Contains(out.String(), `item "Go".Ring: unknown ring "Adopt"`) || !strings.Contains(out.String(), "[allowed-value]") {
+		t.Errorf("runValidate(invalid) = %v, output %q", err, out.String())
+	}
+
+	out.Reset()
+	err = runValidate([]string{"-json", invalid}, &out)
+	var report validationReport
+	if jsonErr := json.Unmarshal(out.Bytes(), &report); jsonErr != nil || err == nil {
+		t.Fatalf("runValidate(-json) = %v, output %q", err, out.String())
+	}
+	if len(report.Violations) != 1 || report.Violations[0] != (ValidationError{File: invalid, Line: 4, Field: `item "Go".Ring`, Rule: ruleAllowedValue, Message: `unknown ring "Adopt", must be one of Adopted, In Discovery, Not Recommended`}) {
+		t.Errorf("runValidate(-json) report = %+v", report)
+	}
+}
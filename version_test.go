@@ -0,0 +1,45 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestVersionHandler(t *testing.T) {
+	oldVersion, oldCommit, oldDate := version, commit, buildDate
+	version, commit, buildDate = "1.2.3", "0123456789abcdef", "2024-01-02T03:04:05Z"
+	defer func() { version, commit, buildDate = oldVersion, oldCommit, oldDate }()
+
+	rec := httptest.NewRecorder()
+	versionHandler(rec, httptest.NewRequest(http.MethodGet, "/version", nil))
+
+	if ct := rec.Header().Get("Content-Type"); ct != "application/json" {
+		t.Errorf("Content-Type = %q, want application/json", ct)
+	}
+	var got BuildInfo
+	if err := json.NewDecoder(rec.Body).Decode(&got); err != nil {
+		t.Fatal(err)
+	}
+	if got.Version != "1.2.3" || got.Commit != "0123456789abcdef" || got.BuildDate != "2024-01-02T03:04:05Z" {
+		t.Errorf("versionHandler() = %+v", got)
+	}
+	if got.GoVersion == "" {
+		t.Error("GoVersion is empty")
+	}
+	if short := got.ShortCommit(); short != "0123456" {
+		t.Errorf("ShortCommit() = %q, want 0123456", short)
+	}
+}
+
+func TestBuildInfoDefaults(t *testing.T) {
+	oldCommit, oldDate := commit, buildDate
+	commit, buildDate = "", ""
+	defer func() { commit, buildDate = oldCommit, oldDate }()
+
+	info := buildInfo()
+	if info.Commit == "" || info.BuildDate == "" {
+		t.Errorf("buildInfo() left empty fields: %+v", info)
+	}
+}
@@ -0,0 +1,180 @@
+package main
+
+import (
+	"cmp"
+	"encoding/json"
+	"log"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Vote is the endorsement of a radar item by a voter, a signal of interest
+// in items being assessed or trialled.
+type Vote struct {
+	ItemID  string    `json:"itemId"`
+	Voter   string    `json:"voter"`
+	Created time.Time `json:"created"`
+}
+
+// VoteResult is the response of a vote cast or withdrawn: the votes the
+// item has and whether the voter is among them.
+type VoteResult struct {
+	ItemID string `json:"itemId"`
+	Votes  int    `json:"votes"`
+	Voted  bool   `json:"voted"`
+}
+
+// RankedItem is an item of the most wanted ranking with its votes.
+type RankedItem struct {
+	RadarItem
+	Votes int `json:"votes"`
+}
+
+// voteDB returns the Database keeping votes, see proposalDB.
+func voteDB() (Database, error) {
+	if s, ok := currentStore().(*databaseStore); ok {
+		return s.db, nil
+	}
+	return nil, &AppError{Code: http.StatusConflict, Message: "Votes require a database store, see store.driver"}
+}
+
+// userOf returns the user signed in with r: the one the reverse proxy
+// signed in, given by server.userHeader, or the reviewer signed in, see
+// reviewerOf. Votes and reactions are counted once per user, so anyone else
+// may not cast them.
+func userOf(r *http.Request) (string, bool) {
+	if header := currentConfig().Server.UserHeader; header != "" {
+		if user := strings.TrimSpace(r.Header.Get(header)); user != "" {
+			return user, true
+		}
+	}
+	if reviewer, ok := reviewerOf(r); ok {
+		return reviewer.Name, true
+	}
+	return "", false
+}
+
+// voteCounts returns the number of votes of each item ID among votes.
+func voteCounts(votes []Vote) map[string]int {
+	counts := map[string]int{}
+	for _, vote := range votes {
+		counts[vote.ItemID]++
+	}
+	return counts
+}
+
+// itemVotes returns the number of votes of the item with the ID id, or nil
+// if there are no votes to count, as the store is not a database.
+func itemVotes(r *http.Request, id string) *int {
+	db, err := voteDB()
+	if err != nil {
+		return nil
+	}
+	votes, err := db.Votes(r.Context())
+	if err != nil {
+		log.Printf("Failed to read votes: %v", err)
+		return nil
+	}
+	n := voteCounts(votes)[id]
+	return &n
+}
+
+// voteHandler casts the vote of the voter signed in, see userOf, for the
+// item with the ID given by the path, or withdraws it unless cast, and
+// responds with the item's VoteResult. Casting a vote again changes
+// nothing.
+func voteHandler(cast bool) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		db, err := voteDB()
+		if err != nil {
+			handleError(w, err)
+			return
+		}
+		voter, ok := userOf(r)
+		if !ok {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="clean-tech-radar"`)
+			handleError(w, &AppError{Code: http.StatusUnauthorized, Message: "Voting requires signing in"})
+			return
+		}
+		itemID, err := pathItemID(r)
+		if err != nil {
+			handleError(w, err)
+			return
+		}
+		var changed bool
+		if cast {
+			changed, err = db.SaveVote(r.Context(), Vote{ItemID: itemID, Voter: voter, Created: time.Now().UTC().Truncate(time.Second)})
+		} else {
+			changed, err = db.DeleteVote(r.Context(), itemID, voter)
+		}
+		if err != nil {
+			handleError(w, &AppError{Code: http.StatusInternalServerError, Message: "Failed to save vote", Err: err})
+			return
+		}
+		switch {
+		case changed && cast:
+			log.Printf("Vote for item %s cast by %s from %s", itemID, voter, r.RemoteAddr)
+		case changed:
+			log.Printf("Vote for item %s withdrawn by %s from %s", itemID, voter, r.RemoteAddr)
+		}
+		votes, err := db.Votes(r.Context())
+		if err != nil {
+			handleError(w, &AppError{Code: http.StatusInternalServerError, Message: "Failed to read votes", Err: err})
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(VoteResult{ItemID: itemID, Votes: voteCounts(votes)[itemID], Voted: cast}); err != nil {
+			log.Printf("Failed to encode vote: %v", err)
+		}
+	})
+}
+
+// mostWantedHandler ranks the items on the radar with votes, most voted for
+// first and then by label, only those in the ring given as ?ring if set,
+// and paged like GET /radar/items.
+func mostWantedHandler(w http.ResponseWriter, r *http.Request) {
+	p, err := parsePage(r)
+	if err != nil {
+		handleError(w, err)
+		return
+	}
+	db, err := voteDB()
+	if err != nil {
+		handleError(w, err)
+		return
+	}
+	data, err := loadRadarData()
+	if err != nil {
+		handleError(w, err)
+		return
+	}
+	votes, err := db.Votes(r.Context())
+	if err != nil {
+		handleError(w, &AppError{Code: http.StatusInternalServerError, Message: "Failed to read votes", Err: err})
+		return
+	}
+	counts := voteCounts(votes)
+	ring := r.URL.Query().Get("ring")
+	ranked := []RankedItem{}
+	for _, item := range visibleItems(data.Items) {
+		if n := counts[item.ID]; n > 0 && (ring == "" || strings.EqualFold(item.Ring, ring)) {
+			ranked = append(ranked, RankedItem{item, n})
+		}
+	}
+	slices.SortStableFunc(ranked, func(a, b RankedItem) int {
+		return cmp.Or(cmp.Compare(b.Votes, a.Votes), strings.Compare(a.Label, b.Label))
+	})
+
+	start, end := p.slice(len(ranked))
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Add("Link", p.links(requestURL(r), len(ranked)))
+	w.Header().Set("X-Total-Count", strconv.Itoa(len(ranked)))
+	if err := json.NewEncoder(w).Encode(struct {
+		Items []RankedItem `json:"items"`
+	}{ranked[start:end]}); err != nil {
+		handleError(w, &AppError{Code: http.StatusInternalServerError, Message: "Failed to encode response", Err: err})
+	}
+}
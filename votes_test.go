@@ -0,0 +1,152 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestVoteStores(t *testing.T) {
+	for _, driver := range []string{"sqlite", "bbolt"} {
+		t.Run(driver, func(t *testing.T) {
+			db := openTestStore(t, driver, filepath.Join(t.TempDir(), "radar.db"))
+			ctx := context.Background()
+			created := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+			for _, vote := range []Vote{{"go", "Jane", created}, {"go", "Joe", created}, {"rust", "Jane", created}} {
+				if added, err := db.SaveVote(ctx, vote); err != nil || !added {
+					t.Fatalf("SaveVote(%+v) = %v, %v", vote, added, err)
+				}
+			}
+			if added, err := db.SaveVote(ctx, Vote{"go", "Jane", created}); err != nil || added {
+				t.Errorf("voting again = %v, %v", added, err)
+			}
+			if deleted, err := db.DeleteVote(ctx, "rust", "Jane"); err != nil || !deleted {
+				t.Errorf("DeleteVote = %v, %v", deleted, err)
+			}
+			if deleted, err := db.DeleteVote(ctx, "rust", "Jane"); err != nil || deleted {
+				t.Errorf("deleting again = %v, %v", deleted, err)
+			}
+
+			votes, err := db.Votes(ctx)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if counts := voteCounts(votes); len(votes) != 2 || counts["go"] != 2 || counts["rust"] != 0 {
+				t.Errorf("votes = %+v", votes)
+			}
+			if !votes[0].Created.Equal(created) {
+				t.Errorf("created = %v", votes[0].Created)
+			}
+		})
+	}
+}
+
+func TestVotes(t *testing.T) {
+	db := openTestStore(t, "sqlite", "file::memory:")
+	seed := RadarData{Items: []RadarItem{
+		{ID: "go", Label: "Go", Quadrant: "Tools", Ring: "In Discovery"},
+		{ID: "rust", Label: "Rust", Quadrant: "Tools", Ring: "In Discovery"},
+		{ID: "zig", Label: "Zig", Quadrant: "Tools", Ring: "Hold"},
+	}}
+	if err := db.Save(context.Background(), seed, AuditEvent{Actor: "test", Action: "seed"}); err != nil {
+		t.Fatal(err)
+	}
+	cfg := defaultConfig()
+	cfg.Server.TrustProxy, cfg.Server.UserHeader = true, "X-Forwarded-User"
+	cfg.Review.Reviewers = []ReviewerConfig{{Name: "Jane", Token: "jane-token"}}
+	useConfig(t, cfg)
+	useStore(t, &databaseStore{db: db})
+	handler, err := setupRoutes(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	send := func(method, target string, header http.Header) *httptest.ResponseRecorder {
+		t.Helper()
+		req := httptest.NewRequest(method, target, nil)
+		for key, values := range header {
+			req.Header[key] = values
+		}
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		return rec
+	}
+	jane := http.Header{"Authorization": {"Bearer jane-token"}}
+	vote := func(method, id string, header http.Header) VoteResult {
+		t.Helper()
+		rec := send(method, "/api/v1/items/"+id+"/vote", header)
+		var result VoteResult
+		if err := json.Unmarshal(rec.Body.Bytes(), &result); err != nil || rec.Code != http.StatusOK {
+			t.Fatalf("%s /api/v1/items/%s/vote = %d %s", method, id, rec.Code, rec.Body)
+		}
+		return result
+	}
+
+	if rec := send(http.MethodPost, "/api/v1/items/go/vote", nil); rec.Code != http.StatusUnauthorized {
+		t.Errorf("anonymous vote = %d", rec.Code)
+	}
+	if got := vote(http.MethodPost, "go", jane); got != (VoteResult{ItemID: "go", Votes: 1, Voted: true}) {
+		t.Errorf("vote = %+v", got)
+	}
+	// Voting twice counts once.
+	if got := vote(http.MethodPost, "go", jane); got.Votes != 1 {
+		t.Errorf("second vote = %+v", got)
+	}
+	if got := vote(http.MethodPost, "go", http.Header{"X-Forwarded-User": {"joe"}}); got.Votes != 2 {
+		t.Errorf("vote of a proxy user = %+v", got)
+	}
+	vote(http.MethodPost, "rust", jane)
+	vote(http.MethodPost, "zig", jane)
+	vote(http.MethodPost, "zig", http.Header{"X-Forwarded-User": {"ann"}})
+	if got := vote(http.MethodDelete, "zig", jane); got != (VoteResult{ItemID: "zig", Votes: 1}) {
+		t.Errorf("withdrawn vote = %+v", got)
+	}
+	if rec := send(http.MethodPost, "/api/v1/items/java/vote", jane); rec.Code != http.StatusNotFound {
+		t.Errorf("vote for an unknown item = %d", rec.Code)
+	}
+
+	rec := send(http.MethodGet, "/api/v1/radar/items/go", nil)
+	var detail ItemDetail
+	if err := json.Unmarshal(rec.Body.Bytes(), &detail); err != nil || detail.Votes == nil || *detail.Votes != 2 {
+		t.Errorf("GET /api/v1/radar/items/go = %d %s", rec.Code, rec.Body)
+	}
+
+	ranking := func(query string) []RankedItem {
+		t.Helper()
+		rec := send(http.MethodGet, "/api/v1/most-wanted"+query, nil)
+		var body struct {
+			Items []RankedItem `json:"items"`
+		}
+		if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil || rec.Code != http.StatusOK {
+			t.Fatalf("GET /api/v1/most-wanted%s = %d %s", query, rec.Code, rec.Body)
+		}
+		return body.Items
+	}
+	if got := ranking(""); len(got) != 3 || got[0].ID != "go" || got[0].Votes != 2 || got[1].ID != "rust" || got[2].ID != "zig" {
+		t.Errorf("most wanted = %+v", got)
+	}
+	if got := ranking("?ring=in+discovery&limit=1&offset=1"); len(got) != 1 || got[0].ID != "rust" {
+		t.Errorf("most wanted in discovery, second = %+v", got)
+	}
+}
+
+func TestVotesRequireDatabase(t *testing.T) {
+	cfg := defaultConfig()
+	useConfig(t, cfg)
+	useStore(t, newFileStore(writeFile(t, "radar.yaml", radarWith("Go", "Hold"))))
+	handler, err := setupRoutes(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rec := doRequest(t, handler, http.MethodGet, "/api/v1/most-wanted"); rec.Code != http.StatusConflict {
+		t.Errorf("GET /api/v1/most-wanted = %d, want 409", rec.Code)
+	}
+	rec := doRequest(t, handler, http.MethodGet, "/api/v1/radar/items/go")
+	var detail map[string]any
+	if err := json.Unmarshal(rec.Body.Bytes(), &detail); err != nil || detail["votes"] != nil {
+		t.Errorf("GET /api/v1/radar/items/go = %d %s", rec.Code, rec.Body)
+	}
+}
@@ -0,0 +1,280 @@
+package main
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// xlsxContentType is the media type of Excel workbooks.
+const xlsxContentType = "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+
+// xlsxColumns are the header and width, in characters, of the columns of
+// each sheet of the workbook.
+var xlsxColumns = []struct {
+	header string
+	width  int
+}{
+	{"Name", 28},
+	{"Ring", 18},
+	{"Moved", 8},
+	{"Description", 60},
+	{"Owners", 24},
+	{"Tags", 24},
+	{"Review Date", 12},
+	{"Last Updated", 12},
+	{"Link", 40},
+}
+
+// Styles of the workbook, as indexes of the cellXfs of xlsxStyles. Rings
+// are styled from xlsxRingStyle on, in the order of the radar's rings.
+const (
+	xlsxHeaderStyle = 1
+	xlsxWrapStyle   = 2
+	xlsxRingStyle   = 3
+)
+
+// xlsxCell is a cell of a sheet, with text and the index of its style.
+type xlsxCell struct {
+	text  string
+	style int
+}
+
+// xlsxSheet is a worksheet of the workbook.
+type xlsxSheet struct {
+	name string
+	rows [][]xlsxCell
+}
+
+// xlsxEscape escapes s as XML text, replacing characters XML can't hold.
+func xlsxEscape(s string) string {
+	var b strings.Builder
+	xml.EscapeText(&b, []byte(s))
+	return b.String()
+}
+
+// xlsxARGB converts a hex color such as #0a0 or #00c000 into the opaque
+// ARGB value of SpreadsheetML.
+func xlsxARGB(color string) string {
+	hex := strings.TrimPrefix(color, "#")
+	if len(hex) == 3 {
+		hex = string([]byte{hex[0], hex[0], hex[1], hex[1], hex[2], hex[2]})
+	}
+	return "FF" + strings.ToUpper(hex)
+}
+
+// xlsxCellRef returns the A1 reference of the cell in the zero-based column
+// and row.
+func xlsxCellRef(column, row int) string {
+	name := ""
+	for column++; column > 0; column = (column - 1) / 26 {
+		name = string(rune('A'+(column-1)%26)) + name
+	}
+	return fmt.Sprintf("%s%d", name, row+1)
+}
+
+// xlsxSheetName returns name as a sheet name Excel accepts, at most 31
+// characters without []:*?/\, that isn't one of used, case-insensitively.
+func xlsxSheetName(name string, used []string) string {
+	name = strings.Trim(strings.Map(func(r rune) rune {
+		if strings.ContainsRune(`[]:*?/\`, r) {
+			return -1
+		}
+		return r
+	}, name), "' ")
+	if name == "" {
+		name = "Sheet"
+	}
+	taken := func(name string) bool {
+		return slices.ContainsFunc(used, func(u string) bool { return strings.EqualFold(u, name) })
+	}
+	truncate := func(name string, n int) string {
+		for utf8.RuneCountInString(name) > n {
+			_, size := utf8.DecodeLastRuneInString(name)
+			name = name[:len(name)-size]
+		}
+		return strings.TrimRight(name, "' ")
+	}
+	candidate := truncate(name, 31)
+	for i := 2; taken(candidate); i++ {
+		suffix := fmt.Sprintf(" (%d)", i)
+		candidate = truncate(name, 31-len(suffix)) + suffix
+	}
+	return candidate
+}
+
+// xlsxStyles returns the styles part of a workbook with the color of each of
+// rings.
+func xlsxStyles(rings []Segment) string {
+	var fills, xfs strings.Builder
+	for i := range rings {
+		fmt.Fprintf(&fills, `<fill><patternFill patternType="solid"><fgColor rgb="%s"/><bgColor indexed="64"/></patternFill></fill>`, xlsxARGB(segmentColor(rings, i)))
+		fmt.Fprintf(&xfs, `<xf numFmtId="0" fontId="2" fillId="%d" borderId="0" xfId="0" applyFont="1" applyFill="1"/>`, 3+i)
+	}
+	return xml.Header + `<styleSheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main">` +
+		`<fonts count="3"><font><sz val="11"/><name val="Calibri"/></font><font><b/><sz val="11"/><name val="Calibri"/></font><font><b/><sz val="11"/><color rgb="FFFFFFFF"/><name val="Calibri"/></font></fonts>` +
+		fmt.Sprintf(`<fills count="%d"><fill><patternFill patternType="none"/></fill><fill><patternFill patternType="gray125"/></fill>`, 3+len(rings)) +
+		`<fill><patternFill patternType="solid"><fgColor rgb="FFD9D9D9"/><bgColor indexed="64"/></patternFill></fill>` + fills.String() + `</fills>` +
+		`<borders count="1"><border><left/><right/><top/><bottom/><diagonal/></border></borders>` +
+		`<cellStyleXfs count="1"><xf numFmtId="0" fontId="0" fillId="0" borderId="0"/></cellStyleXfs>` +
+		fmt.Sprintf(`<cellXfs count="%d">`, xlsxRingStyle+len(rings)) +
+		`<xf numFmtId="0" fontId="0" fillId="0" borderId="0" xfId="0"/>` +
+		`<xf numFmtId="0" fontId="1" fillId="2" borderId="0" xfId="0" applyFont="1" applyFill="1"/>` +
+		`<xf numFmtId="0" fontId="0" fillId="0" borderId="0" xfId="0" applyAlignment="1"><alignment vertical="top" wrapText="1"/></xf>` +
+		xfs.String() + `</cellXfs>` +
+		`<cellStyles count="1"><cellStyle name="Normal" xfId="0" builtinId="0"/></cellStyles>` +
+		`</styleSheet>`
+}
+
+// xlsxWorksheet returns the worksheet part of sheet, with its first row
+// frozen as the header.
+func xlsxWorksheet(sheet xlsxSheet) string {
+	var b strings.Builder
+	b.WriteString(xml.Header + `<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main">`)
+	b.WriteString(`<sheetViews><sheetView workbookViewId="0"><pane ySplit="1" topLeftCell="A2" activePane="bottomLeft" state="frozen"/></sheetView></sheetViews>`)
+	b.WriteString(`<cols>`)
+	for i, column := range xlsxColumns {
+		fmt.Fprintf(&b, `<col min="%d" max="%d" width="%d" customWidth="1"/>`, i+1, i+1, column.width)
+	}
+	b.WriteString(`</cols><sheetData>`)
+	for i, row := range sheet.rows {
+		fmt.Fprintf(&b, `<row r="%d">`, i+1)
+		for j, cell := range row {
+			if cell.text == "" && cell.style == 0 {
+				continue
+			}
+			fmt.Fprintf(&b, `<c r="%s" s="%d" t="inlineStr"><is><t xml:space="preserve">%s</t></is></c>`, xlsxCellRef(j, i), cell.style, xlsxEscape(cell.text))
+		}
+		b.WriteString(`</row>`)
+	}
+	b.WriteString(`</sheetData></worksheet>`)
+	return b.String()
+}
+
+// writeXLSX writes sheets as an Excel workbook styled with the colors of
+// rings.
+func writeXLSX(w io.Writer, sheets []xlsxSheet, rings []Segment) error {
+	var types, sheetList, rels strings.Builder
+	for i, sheet := range sheets {
+		fmt.Fprintf(&types, `<Override PartName="/xl/worksheets/sheet%d.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/>`, i+1)
+		fmt.Fprintf(&sheetList, `<sheet name="%s" sheetId="%d" r:id="rId%d"/>`, xlsxEscape(sheet.name), i+1, i+1)
+		fmt.Fprintf(&rels, `<Relationship Id="rId%d" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet%d.xml"/>`, i+1, i+1)
+	}
+	parts := []struct{ name, content string }{
+		{"[Content_Types].xml", xml.Header + `<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types">` +
+			`<Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/><Default Extension="xml" ContentType="application/xml"/>` +
+			`<Override PartName="/xl/workbook.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/>` +
+			`<Override PartName="/xl/styles.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.styles+xml"/>` +
+			types.String() + `</Types>`},
+		{"_rels/.rels", xml.Header + `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+			`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="xl/workbook.xml"/></Relationships>`},
+		{"xl/workbook.xml", xml.Header + `<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships">` +
+			`<sheets>` + sheetList.String() + `</sheets></workbook>`},
+		{"xl/_rels/workbook.xml.rels", xml.Header + `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` + rels.String() +
+			fmt.Sprintf(`<Relationship Id="rId%d" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/styles" Target="styles.xml"/>`, len(sheets)+1) +
+			`</Relationships>`},
+		{"xl/styles.xml", xlsxStyles(rings)},
+	}
+	for i, sheet := range sheets {
+		parts = append(parts, struct{ name, content string }{fmt.Sprintf("xl/worksheets/sheet%d.xml", i+1), xlsxWorksheet(sheet)})
+	}
+
+	zw := zip.NewWriter(w)
+	for _, part := range parts {
+		f, err := zw.Create(part.name)
+		if err != nil {
+			return err
+		}
+		if _, err := f.Write([]byte(part.content)); err != nil {
+			return err
+		}
+	}
+	return zw.Close()
+}
+
+// radarSheets returns a sheet for each of the quadrants of data listing its
+// items, by ring from the innermost, with the ring cells in the ring's
+// color. page is the URL items are linked to by their ID.
+func radarSheets(data RadarData, items []RadarItem, page string) []xlsxSheet {
+	header := make([]xlsxCell, len(xlsxColumns))
+	for i, column := range xlsxColumns {
+		header[i] = xlsxCell{text: column.header, style: xlsxHeaderStyle}
+	}
+	ringIndex := func(name string) int {
+		return slices.IndexFunc(data.Rings, func(s Segment) bool { return s.Name == name })
+	}
+	var sheets []xlsxSheet
+	var names []string
+	for _, quadrant := range data.Quadrants {
+		name := xlsxSheetName(quadrant.Name, names)
+		names = append(names, name)
+		sheet := xlsxSheet{name: name, rows: [][]xlsxCell{header}}
+		var quadrantItems []RadarItem
+		for _, item := range items {
+			if item.Quadrant == quadrant.Name {
+				quadrantItems = append(quadrantItems, item)
+			}
+		}
+		slices.SortStableFunc(quadrantItems, func(a, b RadarItem) int { return ringIndex(a.Ring) - ringIndex(b.Ring) })
+		for _, item := range quadrantItems {
+			ringStyle := 0
+			if i := ringIndex(item.Ring); i >= 0 {
+				ringStyle = xlsxRingStyle + i
+			}
+			moved, updated := "", ""
+			if item.Moved {
+				moved = "Yes"
+			}
+			if !item.LastUpdated.IsZero() {
+				updated = item.LastUpdated.UTC().Format(time.DateOnly)
+			}
+			sheet.rows = append(sheet.rows, []xlsxCell{
+				{text: item.Label},
+				{text: item.Ring, style: ringStyle},
+				{text: moved},
+				{text: item.Description, style: xlsxWrapStyle},
+				{text: item.Owners.names()},
+				{text: strings.Join(item.Tags, ", ")},
+				{text: item.ReviewDate},
+				{text: updated},
+				{text: page + url.PathEscape(item.ID)},
+			})
+		}
+		sheets = append(sheets, sheet)
+	}
+	return sheets
+}
+
+// xlsxHandler serves the items selectItems selects as an Excel workbook
+// with a sheet per quadrant, for stakeholders who work in spreadsheets.
+func xlsxHandler(w http.ResponseWriter, r *http.Request) {
+	data, err := loadRadarData()
+	if err != nil {
+		handleError(w, err)
+		return
+	}
+	data = withSegments(data)
+	items, err := selectItems(r, data)
+	if err != nil {
+		handleError(w, err)
+		return
+	}
+
+	var buf bytes.Buffer
+	page := requestOrigin(r) + currentConfig().Server.BasePath + "/#"
+	if err := writeXLSX(&buf, radarSheets(data, items, page), data.Rings); err != nil {
+		handleError(w, &AppError{Code: http.StatusInternalServerError, Message: "Failed to encode workbook", Err: err})
+		return
+	}
+	w.Header().Set("Content-Type", xlsxContentType)
+	w.Header().Set("Content-Disposition", `attachment; filename="tech-radar.xlsx"`)
+	w.Write(buf.Bytes())
+}
@@ -0,0 +1,93 @@
+package main
+
+import (
+	"archive/zip"
+	"bytes"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestXLSXSheetName(t *testing.T) {
+	for _, tt := range []struct {
+		name string
+		used []string
+		want string
+	}{
+		{"Tools", nil, "Tools"},
+		{"Programming Languages & Frameworks", nil, "Programming Languages & Framewo"},
+		{"CI/CD [build]", nil, "CICD build"},
+		{"tools", []string{"Tools"}, "tools (2)"},
+		{"???", nil, "Sheet"},
+	} {
+		if got := xlsxSheetName(tt.name, tt.used); got != tt.want {
+			t.Errorf("xlsxSheetName(%q, %q) = %q, want %q", tt.name, tt.used, got, tt.want)
+		}
+	}
+}
+
+func TestXLSXExport(t *testing.T) {
+	cfg := defaultConfig()
+	cfg.Data.Path = writeFile(t, "radar.yaml", `Items:
+- Label: Perl
+  Quadrant: Tools
+  Ring: Not Recommended
+- Label: Go & <Rust>
+  Quadrant: Tools
+  Ring: Adopted
+  Moved: true
+  Owners: Platform Team
+`)
+	useConfig(t, cfg)
+	handler, err := setupRoutes(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rec := doRequest(t, handler, http.MethodGet, "http://radar.example.com/api/v1/export.xlsx")
+	if rec.Code != http.StatusOK || rec.Header().Get("Content-Type") != xlsxContentType {
+		t.Fatalf("GET /api/v1/export.xlsx = %d %q", rec.Code, rec.Header().Get("Content-Type"))
+	}
+	zr, err := zip.NewReader(bytes.NewReader(rec.Body.Bytes()), int64(rec.Body.Len()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	parts := map[string]string{}
+	for _, f := range zr.File {
+		r, err := f.Open()
+		if err != nil {
+			t.Fatal(err)
+		}
+		content, _ := io.ReadAll(r)
+		r.Close()
+		parts[f.Name] = string(content)
+	}
+	for _, name := range []string{"[Content_Types].xml", "_rels/.rels", "xl/workbook.xml", "xl/_rels/workbook.xml.rels", "xl/styles.xml", "xl/worksheets/sheet4.xml"} {
+		if _, ok := parts[name]; !ok {
+			t.Errorf("workbook has no part %s", name)
+		}
+	}
+	if workbook := parts["xl/workbook.xml"]; !strings.Contains(workbook, `<sheet name="Tools" sheetId="2" r:id="rId2"/>`) ||
+		!strings.Contains(workbook, `<sheet name="Programming Languages &amp; Framewo"`) {
+		t.Errorf("workbook = %s", workbook)
+	}
+	if !strings.Contains(parts["xl/styles.xml"], `<fgColor rgb="FFFF0000"/>`) {
+		t.Errorf("styles don't color Not Recommended: %s", parts["xl/styles.xml"])
+	}
+
+	// Tools lists Go first, in the innermost ring, under a frozen header.
+	sheet := parts["xl/worksheets/sheet2.xml"]
+	for _, want := range []string{
+		`<pane ySplit="1" topLeftCell="A2" activePane="bottomLeft" state="frozen"/>`,
+		`<c r="A1" s="1" t="inlineStr"><is><t xml:space="preserve">Name</t></is></c>`,
+		`<c r="A2" s="0" t="inlineStr"><is><t xml:space="preserve">Go &amp; &lt;Rust&gt;</t></is></c><c r="B2" s="3" t="inlineStr"><is><t xml:space="preserve">Adopted</t></is></c><c r="C2" s="0" t="inlineStr"><is><t xml:space="preserve">Yes</t></is></c>`,
+		`<t xml:space="preserve">Platform Team</t>`,
+		`<t xml:space="preserve">http://radar.example.com/#go-rust</t>`,
+		`<c r="B3" s="5" t="inlineStr"><is><t xml:space="preserve">Not Recommended</t></is></c>`,
+	} {
+		if !strings.Contains(sheet, want) {
+			t.Errorf("sheet doesn't contain %s:\n%s", want, sheet)
+		}
+	}
+}
@@ -0,0 +1,183 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"slices"
+
+	"gopkg.in/yaml.v3"
+)
+
+// mergeYAMLRadarData encodes data as YAML on top of the existing YAML
+// document, so that saving keeps its comments, key order and quoting for
+// everything that still exists: values are replaced in the existing
+// nodes, items are matched by label, new keys are added next to the keys
+// they follow, new items after the existing ones, and removed items are
+// dropped with their comments.
+func mergeYAMLRadarData(existing []byte, data RadarData) ([]byte, error) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(existing, &doc); err != nil {
+		return nil, err
+	}
+	if doc.Kind != yaml.DocumentNode || len(doc.Content) != 1 || doc.Content[0].Kind != yaml.MappingNode {
+		return nil, errors.New("existing data file is not a YAML mapping")
+	}
+	// Data in an older format is merged into its migrated document, so that
+	// fields it renamed aren't kept under their old names.
+	if _, err := migrateRadarNode("", &doc); err != nil {
+		return nil, err
+	}
+	var fresh yaml.Node
+	if err := fresh.Encode(data); err != nil {
+		return nil, err
+	}
+	doc.Content[0] = mergeYAMLNode(doc.Content[0], &fresh)
+
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(&doc); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), enc.Close()
+}
+
+// mergeYAMLNode returns the node for the value of new, reusing old, with its
+// comments and style, when both are the same kind of node.
+func mergeYAMLNode(old, new *yaml.Node) *yaml.Node {
+	if old.Kind != new.Kind {
+		new.HeadComment, new.LineComment, new.FootComment = old.HeadComment, old.LineComment, old.FootComment
+		return new
+	}
+	switch old.Kind {
+	case yaml.MappingNode:
+		mergeYAMLMapping(old, new)
+	case yaml.SequenceNode:
+		mergeYAMLSequence(old, new)
+	case yaml.ScalarNode:
+		// A plain value may need quoting now, and a value of another type
+		// can't keep the old quoting.
+		if old.Style == 0 || old.Tag != new.Tag {
+			old.Style = new.Style
+		}
+		old.Tag, old.Value = new.Tag, new.Value
+	default:
+		return new
+	}
+	return old
+}
+
+// mergeYAMLMapping updates the values of old from new in place. Keys missing
+// from old are inserted after the key preceding them in new, unless their
+// value is empty, so saving doesn't spell out every optional field.
+func mergeYAMLMapping(old, new *yaml.Node) {
+	pos := 0
+	for i := 0; i+1 < len(new.Content); i += 2 {
+		key, value := new.Content[i], new.Content[i+1]
+		if j := yamlMappingIndex(old, key.Value); j >= 0 {
+			old.Content[j+1] = mergeYAMLNode(old.Content[j+1], value)
+			pos = j + 2
+		} else if !isEmptyYAMLScalar(value) {
+			old.Content = slices.Insert(old.Content, pos, key, value)
+			pos += 2
+		}
+	}
+}
+
+// mergeYAMLSequence replaces the elements of old with those of new, reusing
+// the old element with the same ID or label, or at the same position for
+// elements without one.
+func mergeYAMLSequence(old, new *yaml.Node) {
+	byID := make(map[string]*yaml.Node)
+	byLabel := make(map[string]*yaml.Node)
+	for _, elem := range old.Content {
+		if id, ok := yamlItemField(elem, "ID"); ok {
+			byID[id] = elem
+		}
+		if label, ok := yamlLabel(elem); ok {
+			byLabel[labelKey(label)] = elem
+		}
+	}
+
+	content := make([]*yaml.Node, len(new.Content))
+	for i, elem := range new.Content {
+		var prev *yaml.Node
+		if id, ok := yamlItemField(elem, "ID"); ok && byID[id] != nil {
+			prev = byID[id]
+		} else if label, ok := yamlLabel(elem); ok {
+			prev = byLabel[labelKey(label)]
+		} else if i < len(old.Content) {
+			if _, ok := yamlLabel(old.Content[i]); !ok {
+				prev = old.Content[i]
+			}
+		}
+		if prev != nil {
+			content[i] = mergeYAMLNode(prev, elem)
+			pruneYAMLMapping(content[i], elem)
+		} else {
+			content[i] = elem
+		}
+	}
+	old.Content = content
+}
+
+// pruneYAMLMapping drops the keys of the merged mapping node that new
+// doesn't have, so clearing a field that is omitted when empty removes it
+// rather than keeping its old value. Elements of sequences, such as items,
+// are always encoded in full, unlike the top-level mapping, which keeps
+// keys such as Version.
+func pruneYAMLMapping(merged, new *yaml.Node) {
+	if merged.Kind != yaml.MappingNode || new.Kind != yaml.MappingNode {
+		return
+	}
+	for i := 0; i+1 < len(merged.Content); {
+		if yamlMappingIndex(new, merged.Content[i].Value) < 0 {
+			merged.Content = slices.Delete(merged.Content, i, i+2)
+			continue
+		}
+		i += 2
+	}
+}
+
+// yamlMappingIndex returns the index of key in the mapping node, or -1.
+func yamlMappingIndex(node *yaml.Node, key string) int {
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			return i
+		}
+	}
+	return -1
+}
+
+// yamlLabel returns the Label of an item mapping node.
+func yamlLabel(node *yaml.Node) (string, bool) {
+	return yamlItemField(node, "Label")
+}
+
+// yamlItemField returns the value of a field of an item mapping node.
+func yamlItemField(node *yaml.Node, field string) (string, bool) {
+	if node.Kind != yaml.MappingNode {
+		return "", false
+	}
+	i := yamlMappingIndex(node, field)
+	if i < 0 {
+		return "", false
+	}
+	return node.Content[i+1].Value, true
+}
+
+// isEmptyYAMLScalar reports whether node is an empty string, false or null.
+func isEmptyYAMLScalar(node *yaml.Node) bool {
+	if node.Kind != yaml.ScalarNode {
+		return false
+	}
+	switch node.Tag {
+	case "!!str":
+		return node.Value == ""
+	case "!!bool":
+		return node.Value == "false"
+	case "!!null":
+		return true
+	}
+	return false
+}
@@ -0,0 +1,118 @@
+package main
+
+import (
+	"os"
+	"testing"
+)
+
+func TestWriteRadarDataKeepsYAMLLayout(t *testing.T) {
+	path := writeFile(t, "radar.yaml", `# Reviewed every quarter.
+LastModified: May 2024
+Items:
+  # Our main language.
+  - Ring: Adopted # since 2019
+    Label: Go
+    Quadrant: Tools
+    Description: "Fast."
+  - Label: Perl
+    Quadrant: Tools
+    Ring: Not Recommended
+`)
+	data := RadarData{LastModified: "June 2024", Items: []RadarItem{
+		{Label: "Go", Quadrant: "Tools", Ring: "In Discovery", Description: "Fast: really."},
+		{Label: "Rust", Quadrant: "Tools", Ring: "Adopted", Moved: true},
+	}}
+	if err := writeRadarData(path, data); err != nil {
+		t.Fatal(err)
+	}
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `# Reviewed every quarter.
+Version: 2
+LastModified: June 2024
+Items:
+  # Our main language.
+  - Ring: In Discovery # since 2019
+    Label: Go
+    Quadrant: Tools
+    Description: "Fast: really."
+  - Label: Rust
+    Quadrant: Tools
+    Ring: Adopted
+    Moved: true
+    Description: ""
+`
+	if string(content) != want {
+		t.Errorf("data file =\n%s\nwant\n%s", content, want)
+	}
+	if got, err := readRadarData(path); err != nil || got.Items[0].Description != "Fast: really." {
+		t.Errorf("readRadarData() = %+v, %v", got, err)
+	}
+}
+
+func TestMergeYAMLRadarDataQuotesWhenNeeded(t *testing.T) {
+	existing := []byte("LastModified: May 2024\nItems:\n- Label: Go\n  Quadrant: Tools\n  Ring: Adopted\n  Owners: Team A\n")
+	data := RadarData{LastModified: "true", Items: []RadarItem{
+		{Label: "Go", Quadrant: "Tools", Ring: "Adopted", Owners: Owners{{Name: "Team A", Slack: "#platform"}}},
+	}}
+	content, err := mergeYAMLRadarData(existing, data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := decodeRadarData("radar.yaml", content)
+	if err != nil || got.LastModified != "true" || got.Items[0].Owners[0].Slack != "#platform" {
+		t.Errorf("merged data = %+v, %v, from\n%s", got, err, content)
+	}
+
+	if _, err := mergeYAMLRadarData([]byte("- not a mapping\n"), data); err == nil {
+		t.Error("mergeYAMLRadarData() merged into a sequence")
+	}
+}
+
+func TestMergeYAMLRadarDataMatchesIDs(t *testing.T) {
+	existing := []byte("Version: 2\nItems:\n# The language.\n- ID: go\n  Label: Go\n  Quadrant: Tools\n  Ring: Adopted\n- Label: Rust\n  Quadrant: Tools\n  Ring: Adopted\n")
+	data := RadarData{Items: []RadarItem{
+		{ID: "go", Label: "Golang", Quadrant: "Tools", Ring: "Adopted"},
+		{ID: "rust", Label: "Rust", Quadrant: "Tools", Ring: "Adopted"},
+	}}
+	content, err := mergeYAMLRadarData(existing, data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `Version: 2
+Items:
+  # The language.
+  - ID: go
+    Label: Golang
+    Quadrant: Tools
+    Ring: Adopted
+  - ID: rust
+    Label: Rust
+    Quadrant: Tools
+    Ring: Adopted
+`
+	if string(content) != want {
+		t.Errorf("merged data =\n%s\nwant\n%s", content, want)
+	}
+}
+
+func TestMergeYAMLRadarDataClearsFields(t *testing.T) {
+	existing := []byte("Version: 2\nItems:\n- Label: Perl # legacy\n  Quadrant: Tools\n  Ring: Adopted\n  Tags: [legacy]\n  Archived: true\n")
+	data := RadarData{Items: []RadarItem{{Label: "Perl", Quadrant: "Tools", Ring: "Adopted"}}}
+	content, err := mergeYAMLRadarData(existing, data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `Version: 2
+Items:
+  - Label: Perl # legacy
+    Quadrant: Tools
+    Ring: Adopted
+`
+	if string(content) != want {
+		t.Errorf("merged data =\n%s\nwant\n%s", content, want)
+	}
+}
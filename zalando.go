@@ -0,0 +1,99 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"slices"
+)
+
+// zalandoRadar is the radar as the configuration of radar_visualization,
+// the D3 visualization of the Zalando tech radar, with its entries placed
+// in quadrants and rings by index.
+type zalandoRadar struct {
+	Title     string           `json:"title"`
+	Date      string           `json:"date,omitempty"`
+	Quadrants []zalandoSegment `json:"quadrants"`
+	Rings     []zalandoSegment `json:"rings"`
+	Entries   []zalandoEntry   `json:"entries"`
+}
+
+// zalandoSegment is a quadrant or ring of a zalandoRadar; only rings have
+// a color.
+type zalandoSegment struct {
+	Name  string `json:"name"`
+	Color string `json:"color,omitempty"`
+}
+
+// zalandoEntry is an item of a zalandoRadar. Moved is 1 if it moved to an
+// inner ring, -1 to an outer one, and 0 if it didn't move.
+type zalandoEntry struct {
+	Label    string `json:"label"`
+	Quadrant int    `json:"quadrant"`
+	Ring     int    `json:"ring"`
+	Moved    int    `json:"moved"`
+	Active   bool   `json:"active"`
+	Link     string `json:"link"`
+}
+
+// zalandoMoved returns the moved flag of item: the direction of its latest
+// move in changes if it is marked as moved, inwards when that isn't known.
+func zalandoMoved(item RadarItem, changes []ItemChange, rings []Segment) int {
+	if !item.Moved {
+		return 0
+	}
+	for _, change := range slices.Backward(changes) {
+		if change.Event == changeMoved {
+			if moved := ringMove(rings, change.PreviousRing, change.Item.Ring); moved != 0 {
+				return moved
+			}
+		}
+	}
+	return 1
+}
+
+// zalandoHandler serves the items selectItems selects in the format of the
+// Zalando tech radar. Its visualization draws exactly four quadrants and at
+// most four rings.
+func zalandoHandler(w http.ResponseWriter, r *http.Request) {
+	data, err := loadRadarData()
+	if err != nil {
+		handleError(w, err)
+		return
+	}
+	data = withSegments(data)
+	items, err := selectItems(r, data)
+	if err != nil {
+		handleError(w, err)
+		return
+	}
+	changesByID, err := itemChangesByID(r.Context())
+	if err != nil {
+		handleError(w, &AppError{Code: http.StatusInternalServerError, Message: "Failed to read radar changes", Err: err})
+		return
+	}
+
+	radar := zalandoRadar{Title: "Clean Tech Radar", Date: data.LastModified, Entries: []zalandoEntry{}}
+	for _, quadrant := range data.Quadrants {
+		radar.Quadrants = append(radar.Quadrants, zalandoSegment{Name: quadrant.Name})
+	}
+	for i, ring := range data.Rings {
+		radar.Rings = append(radar.Rings, zalandoSegment{Name: ring.Name, Color: segmentColor(data.Rings, i)})
+	}
+	page := requestOrigin(r) + currentConfig().Server.BasePath + "/#"
+	for _, item := range items {
+		radar.Entries = append(radar.Entries, zalandoEntry{
+			Label:    item.Label,
+			Quadrant: slices.IndexFunc(data.Quadrants, func(s Segment) bool { return s.Name == item.Quadrant }),
+			Ring:     slices.IndexFunc(data.Rings, func(s Segment) bool { return s.Name == item.Ring }),
+			Moved:    zalandoMoved(item, changesByID[item.ID], data.Rings),
+			Active:   true,
+			Link:     page + url.PathEscape(item.ID),
+		})
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(radar); err != nil {
+		handleError(w, &AppError{Code: http.StatusInternalServerError, Message: "Failed to encode response", Err: err})
+	}
+}
@@ -0,0 +1,41 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestZalando(t *testing.T) {
+	repo, commit := gitRadarRepo(t)
+	commit(radarWith("Go", "Adopted") + "- Label: Perl\n  Quadrant: Techniques\n  Ring: In Discovery\n")
+	commit(radarWith("Go", "Adopted") + "- Label: Perl\n  Quadrant: Techniques\n  Ring: Not Recommended\n  Moved: true\n- Label: Rust\n  Quadrant: Platforms\n  Ring: In Discovery\n  Moved: true\n")
+
+	cfg := defaultConfig()
+	cfg.Data.Path = filepath.Join(repo, "data", "radar.yaml")
+	useConfig(t, cfg)
+	handler, err := setupRoutes(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rec := doRequest(t, handler, http.MethodGet, "http://radar.example.com/api/v1/export/zalando.json")
+	var radar zalandoRadar
+	if err := json.Unmarshal(rec.Body.Bytes(), &radar); rec.Code != http.StatusOK || err != nil {
+		t.Fatalf("GET /api/v1/export/zalando.json = %d %q", rec.Code, rec.Body.String())
+	}
+	if len(radar.Quadrants) != 4 || radar.Rings[2] != (zalandoSegment{Name: "Not Recommended", Color: "#FF0000"}) {
+		t.Errorf("segments = %+v %+v", radar.Quadrants, radar.Rings)
+	}
+	// Perl moved out, and Rust is marked as moved without a known move.
+	want := []zalandoEntry{
+		{Label: "Go", Quadrant: 1, Ring: 0, Moved: 0, Active: true, Link: "http://radar.example.com/#go"},
+		{Label: "Perl", Quadrant: 3, Ring: 2, Moved: -1, Active: true, Link: "http://radar.example.com/#perl"},
+		{Label: "Rust", Quadrant: 0, Ring: 1, Moved: 1, Active: true, Link: "http://radar.example.com/#rust"},
+	}
+	if !reflect.DeepEqual(radar.Entries, want) {
+		t.Errorf("entries = %+v, want %+v", radar.Entries, want)
+	}
+}